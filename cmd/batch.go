@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/batch"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/config"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/logger"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/source"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	batchIgnoreErrors bool
+	batchMaxFailures  int
+	batchParallel     int
+	batchHostLimit    int
+	batchHostInterval time.Duration
+)
+
+var batchCmd = &cobra.Command{
+	Use:   "batch <manifest>",
+	Short: "Parse every source listed in a source manifest",
+	Long: `Parse every source listed in a YAML source manifest and print one report with the
+configuration or the error of each source:
+
+  sources:
+    - name: vpc
+      target: https://github.com/owner/terraform-aws-vpc
+      ref: v5.1.0
+    - target: ./stacks/network
+    - name: sandbox
+      target: https://github.com/owner/sandbox
+      optional: true
+
+A failed source fails the run unless it is marked optional or --ignore-errors is set;
+failures are recorded in the report either way. --max-failures fails the run when more
+sources fail, optional ones included.
+
+--parallel parses several sources at a time. Fetches from each git host are limited to
+--host-concurrency at a time and started at least --host-interval apart, so that large
+manifests do not trip the abuse detection of GitHub or GitLab. Budgets of individual
+hosts are set in the config file:
+
+  fetch:
+    concurrency: 4
+    interval: 500ms
+    hosts:
+      github.com:
+        concurrency: 2
+        interval: 1s`,
+	Example: `  # Parse all sources, tolerating up to 3 failures of optional sources
+  terraform-config-parser batch sources.yaml --max-failures 3
+
+  # Parse 8 sources at a time, with at most 2 clones per host one second apart
+  terraform-config-parser batch sources.yaml --parallel 8 --host-concurrency 2 --host-interval 1s`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		manifestPath := args[0]
+
+		manifest, err := batch.Load(manifestPath)
+		if err != nil {
+			logger.ErrorKV("Failed to load source manifest", "path", manifestPath, "error", err)
+			log.Fatal(err)
+		}
+
+		cfg, err := config.Load(configPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		limiter := newLimiter(cmd, cfg.FetchPolicy())
+
+		logger.InfoKV("Parsing sources", "manifest", manifestPath, "sources", len(manifest.Sources), "parallel", batchParallel, "ignore_errors", batchIgnoreErrors, "max_failures", batchMaxFailures)
+
+		tracker, err := newTracker("sources fetched")
+		if err != nil {
+			log.Fatal(err)
+		}
+		opts := batch.Options{Parallel: batchParallel, IgnoreErrors: batchIgnoreErrors, MaxFailures: batchMaxFailures, Tracker: tracker}
+		if dryRun {
+			// One plan for the whole manifest, rather than fetchSource's exit after the first source
+			plan := batch.DryRun(manifest, opts, func(entry *batch.Entry) (*source.Plan, error) {
+				return source.DryRun(runCtx, source.New(entry.Target, source.SourceConfig{Ref: entry.Ref, SubDir: entry.SubDir, Limiter: limiter}), false, nil)
+			})
+			if err := printJSON(plan); err != nil {
+				log.Fatal(err)
+			}
+			if plan.Failed {
+				if err := flushOutput(); err != nil {
+					log.Fatal(err)
+				}
+				log.Fatalf("batch dry run failed with %d source(s) that cannot be fetched, %d of them ignored", plan.Failures, plan.IgnoredFailures)
+			}
+			return
+		}
+
+		report := batch.Run(manifest, opts, func(entry *batch.Entry) (*parser.TerraformConfig, error) {
+			return loadWorkspace(source.New(entry.Target, source.SourceConfig{Ref: entry.Ref, SubDir: entry.SubDir, Limiter: limiter}), parser.Detail)
+		})
+
+		if err := printJSON(report); err != nil {
+			log.Fatal(err)
+		}
+
+		if report.Failed {
+			if err := flushOutput(); err != nil {
+				log.Fatal(err)
+			}
+			log.Fatalf("batch failed with %d failed source(s), %d of them ignored", report.Failures, report.IgnoredFailures)
+		}
+	},
+}
+
+// newLimiter builds the per-host fetch limiter from the config file, with the --host-* flags
+// overriding the default budget when set
+func newLimiter(cmd *cobra.Command, policy *config.FetchPolicy) *source.Limiter {
+	defaults := source.HostLimit{Concurrency: policy.Concurrency, Interval: policy.Interval}
+	if cmd.Flags().Changed("host-concurrency") {
+		defaults.Concurrency = batchHostLimit
+	}
+	if cmd.Flags().Changed("host-interval") {
+		defaults.Interval = batchHostInterval
+	}
+
+	limits := map[string]source.HostLimit{}
+	for host, budget := range policy.Hosts {
+		limits[strings.ToLower(host)] = source.HostLimit{Concurrency: budget.Concurrency, Interval: budget.Interval}
+	}
+	return source.NewLimiter(defaults, limits)
+}
+
+func init() {
+	rootCmd.AddCommand(batchCmd)
+
+	batchCmd.Flags().BoolVar(&batchIgnoreErrors, "ignore-errors", false, "Treat every source as optional")
+	batchCmd.Flags().IntVar(&batchParallel, "parallel", 1, "Number of sources parsed at the same time")
+	batchCmd.Flags().IntVar(&batchHostLimit, "host-concurrency", 0, "Maximum simultaneous fetches per git host (0 for no limit)")
+	batchCmd.Flags().DurationVar(&batchHostInterval, "host-interval", 0, "Minimum delay between two fetches from a git host")
+	batchCmd.Flags().IntVar(&batchMaxFailures, "max-failures", -1, "Fail when more sources fail, optional ones included (negative for no limit)")
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/logger"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/output"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/source"
+	"github.com/Yunsang-Jeong/terraform-config-parser/version"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	bundleRef    string
+	bundleSubDir string
+)
+
+var bundleCmd = &cobra.Command{
+	Use:   "bundle <path|url> <archive" + source.BundleExtension + ">",
+	Short: "Fetch a source once and save it as a self-contained bundle for offline use",
+	Long: `Fetch a source and write a bundle: a gzipped tar archive with the fetched files, a
+manifest recording where they come from (location, ref, commit and the SHA-256 of every file)
+and the parse result of the configuration in Detail mode.
+
+Every command accepts a path ending with ` + source.BundleExtension + ` as its target and reads the
+bundle without network access, checking the files against the manifest. This lets
+environments without outbound network parse and compare configurations fetched elsewhere.
+The manifest is printed once the bundle is written.`,
+	Example: `  # Bundle a release of a module
+  terraform-config-parser bundle https://github.com/owner/terraform-aws-vpc --ref v5.1.0 vpc-v5.1.0.tfbundle
+
+  # Later, without network access
+  terraform-config-parser local vpc-v5.1.0.tfbundle
+  terraform-config-parser diff vpc-v5.1.0.tfbundle vpc-v5.2.0.tfbundle`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		target, archive := args[0], args[1]
+		if !source.IsBundle(archive) {
+			log.Fatalf("bundle file %s must end with %s", archive, source.BundleExtension)
+		}
+
+		logger.InfoKV("Bundling source", "target", target, "ref", bundleRef, "subdir", bundleSubDir, "archive", archive)
+
+		src := source.New(target, source.SourceConfig{
+			Ref:    bundleRef,
+			SubDir: bundleSubDir,
+		})
+
+		manifest, err := writeBundle(src, archive)
+		if err != nil {
+			logger.ErrorKV("Failed to bundle source", "target", target, "error", err)
+			log.Fatal(err)
+		}
+
+		if err := printJSON(manifest); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(bundleCmd)
+
+	bundleCmd.Flags().StringVarP(&bundleRef, "ref", "r", "", "Git reference to use when the target is a Git repository")
+	bundleCmd.Flags().StringVar(&bundleSubDir, "subdir", "", "Subdirectory of the configuration within the target")
+}
+
+// writeBundle fetches and parses src, then writes its files and summary to archive
+func writeBundle(src source.Source, archive string) (*source.BundleManifest, error) {
+	fs, rootPath, err := fetchSource(src, false)
+	if err != nil {
+		return nil, err
+	}
+	defer src.Cleanup()
+
+	tfconfig, err := parser.NewParser(fs, parser.Detail).WithContext(runCtx).ParseTerraformWorkspace(rootPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Terraform workspace: %w", err)
+	}
+	summary, err := tfconfig.Summary(true)
+	if err != nil {
+		return nil, err
+	}
+
+	bundle, err := source.NewBundle(src, fs, rootPath, summary)
+	if err != nil {
+		return nil, err
+	}
+	bundle.Manifest.Tool = version.GetVersion()
+
+	var buf bytes.Buffer
+	if err := bundle.Write(&buf); err != nil {
+		return nil, err
+	}
+	if err := output.WriteFile(archive, buf.Bytes()); err != nil {
+		return nil, err
+	}
+
+	logger.InfoKV("Wrote bundle", "archive", archive, "files", len(bundle.Manifest.Files))
+	return bundle.Manifest, nil
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/consistency"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/logger"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/source"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	consistencyRef          string
+	consistencySubDir       string
+	consistencyRoots        []string
+	consistencyFailOnIssues bool
+)
+
+var consistencyCmd = &cobra.Command{
+	Use:   "consistency <path|url>",
+	Short: "Compare variables and module calls across environment roots",
+	Long: `Compare the root configurations of an environment-per-folder repository (dev, stage,
+prod, ...) and report where they drifted apart: variables declared by some roots only or with
+different types, module calls made by some roots only, to different modules or versions, and
+module arguments set by some roots only.
+The target is treated as a Git repository when it is a URL and as a local directory otherwise.
+
+--roots lists the root directories relative to the target. By default every directory with a
+backend or cloud block is a root.`,
+	Example: `  # Compare the environments of a repository
+  terraform-config-parser consistency ./live --roots envs/dev,envs/stage,envs/prod
+
+  # Fail a CI job when the environments drifted
+  terraform-config-parser consistency ./live --fail-on-issues`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		target := args[0]
+
+		logger.InfoKV("Checking root consistency", "target", target, "ref", consistencyRef, "subdir", consistencySubDir, "roots", consistencyRoots)
+
+		src := source.New(target, source.SourceConfig{
+			Ref:    consistencyRef,
+			SubDir: consistencySubDir,
+		})
+
+		roots, err := loadRoots(src, consistencyRoots)
+		if err != nil {
+			logger.ErrorKV("Failed to load roots", "target", target, "error", err)
+			log.Fatal(err)
+		}
+
+		report := consistency.Check(roots)
+		if err := printJSON(report); err != nil {
+			log.Fatal(err)
+		}
+
+		if consistencyFailOnIssues && len(report.Issues) > 0 {
+			logger.ErrorKV("Roots are inconsistent", "issues", len(report.Issues))
+			exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(consistencyCmd)
+
+	consistencyCmd.Flags().StringVarP(&consistencyRef, "ref", "r", "", "Git reference to use when the target is a Git repository")
+	consistencyCmd.Flags().StringVar(&consistencySubDir, "subdir", "", "Subdirectory within the target")
+	consistencyCmd.Flags().StringSliceVar(&consistencyRoots, "roots", nil, "Root directories to compare, relative to the target (default: directories with a backend or cloud block)")
+	consistencyCmd.Flags().BoolVar(&consistencyFailOnIssues, "fail-on-issues", false, "Exit with status 1 when inconsistencies are found")
+}
+
+// loadRoots parses the given root directories, or every directory with a backend or cloud block
+func loadRoots(src source.Source, dirs []string) ([]*consistency.Root, error) {
+	fs, rootPath, err := fetchSource(src, true)
+	if err != nil {
+		return nil, err
+	}
+	defer src.Cleanup()
+
+	p := parser.NewParser(fs, parser.Detail).WithContext(runCtx)
+	roots := []*consistency.Root{}
+
+	if len(dirs) > 0 {
+		for _, dir := range dirs {
+			tfconfig, err := p.ParseTerraformWorkspace(filepath.Join(rootPath, dir))
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse root %s: %w", dir, err)
+			}
+			roots = append(roots, &consistency.Root{Name: filepath.ToSlash(dir), Config: tfconfig})
+		}
+		return roots, nil
+	}
+
+	configDirs, err := source.ConfigDirs(fs, rootPath)
+	if err != nil {
+		return nil, err
+	}
+	for _, dir := range configDirs {
+		tfconfig, err := p.ParseTerraformWorkspace(dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", dir, err)
+		}
+		if !hasBackend(tfconfig) {
+			continue
+		}
+		name, err := filepath.Rel(rootPath, dir)
+		if err != nil {
+			name = dir
+		}
+		roots = append(roots, &consistency.Root{Name: filepath.ToSlash(name), Config: tfconfig})
+	}
+	if len(roots) < 2 {
+		return nil, fmt.Errorf("found %d directories with a backend or cloud block, need at least 2: list them with --roots", len(roots))
+	}
+	return roots, nil
+}
+
+func hasBackend(tfconfig *parser.TerraformConfig) bool {
+	for _, terraform := range tfconfig.Terraform {
+		if terraform.Backend != nil || terraform.Cloud != nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/convert"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/logger"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/source"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	convertRef    string
+	convertSubDir string
+	convertTo     string
+	convertOutDir string
+)
+
+var convertCmd = &cobra.Command{
+	Use:   "convert <path|url>",
+	Short: "Convert Terraform configurations between native and JSON syntax",
+	Long: `Convert every configuration file of a workspace between the native syntax (.tf)
+and the JSON syntax (.tf.json). The target is treated as a Git repository when it is a URL
+and as a local directory otherwise.
+
+The converted files are parsed again and compared with the source workspace before anything
+is written, so a conversion that would change the configuration fails instead.
+Comments are not preserved.`,
+	Example: `  # Convert native syntax to JSON
+  terraform-config-parser convert ./terraform --to json --out-dir ./terraform-json
+
+  # Convert JSON syntax back to native syntax
+  terraform-config-parser convert ./terraform-json --to hcl --out-dir ./terraform`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		target := args[0]
+
+		logger.InfoKV("Converting terraform configuration", "target", target, "ref", convertRef, "subdir", convertSubDir, "to", convertTo)
+
+		src := source.New(target, source.SourceConfig{
+			Ref:    convertRef,
+			SubDir: convertSubDir,
+		})
+
+		if err := convertWorkspace(src, convert.Format(convertTo), convertOutDir); err != nil {
+			logger.ErrorKV("Failed to convert terraform configuration", "target", target, "error", err)
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(convertCmd)
+
+	convertCmd.Flags().StringVarP(&convertRef, "ref", "r", "", "Git reference to use when the target is a Git repository")
+	convertCmd.Flags().StringVar(&convertSubDir, "subdir", "", "Subdirectory within the target")
+	convertCmd.Flags().StringVar(&convertTo, "to", "", "Target syntax (json, hcl)")
+	convertCmd.Flags().StringVar(&convertOutDir, "out-dir", "", "Directory to write the converted files to")
+	convertCmd.MarkFlagRequired("to")
+	convertCmd.MarkFlagRequired("out-dir")
+}
+
+func convertWorkspace(src source.Source, to convert.Format, outDir string) error {
+	fs, rootPath, err := fetchSource(src, false)
+	if err != nil {
+		return err
+	}
+	defer src.Cleanup()
+
+	converted, err := convert.Workspace(fs, rootPath, to)
+	if err != nil {
+		return err
+	}
+	if len(converted) == 0 {
+		return fmt.Errorf("no configuration files to convert in %s", rootPath)
+	}
+
+	if err := convert.Verify(fs, rootPath, converted, to); err != nil {
+		return fmt.Errorf("verification failed: %w", err)
+	}
+
+	if err := os.MkdirAll(outDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	for _, name := range slices.Sorted(maps.Keys(converted)) {
+		filename := filepath.Join(outDir, name)
+		if err := os.WriteFile(filename, converted[name], 0o644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", filename, err)
+		}
+		logger.InfoKV("Wrote converted file", "file", filename)
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"log"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/logger"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/source"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/tfstate"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	crossrefRef    string
+	crossrefSubDir string
+	crossrefPlan   string
+)
+
+var crossrefCmd = &cobra.Command{
+	Use:   "crossref <path|url> --plan <plan.json>",
+	Short: "Cross-reference Terraform configurations with a plan or state file",
+	Long: `Cross-reference the resources, data sources and module calls of a configuration with
+a JSON plan or state file produced by "terraform show -json".
+The target is treated as a Git repository when it is a URL and as a local directory otherwise.
+
+For plan files the prior state is used. The report lists configured blocks that are in state,
+blocks that are not in state yet (to create), and state instances without configuration (orphaned).`,
+	Example: `  # Cross-reference with a plan
+  terraform plan -out plan.tfplan && terraform show -json plan.tfplan > plan.json
+  terraform-config-parser crossref . --plan plan.json
+
+  # Cross-reference with the current state
+  terraform show -json > state.json
+  terraform-config-parser crossref . --plan state.json`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		target := args[0]
+
+		logger.InfoKV("Cross-referencing terraform configuration", "target", target, "ref", crossrefRef, "subdir", crossrefSubDir, "plan", crossrefPlan)
+
+		doc, err := tfstate.Load(crossrefPlan)
+		if err != nil {
+			logger.ErrorKV("Failed to load plan file", "plan", crossrefPlan, "error", err)
+			log.Fatal(err)
+		}
+
+		src := source.New(target, source.SourceConfig{
+			Ref:    crossrefRef,
+			SubDir: crossrefSubDir,
+		})
+
+		tfconfig, err := loadWorkspace(src, parser.Detail)
+		if err != nil {
+			logger.ErrorKV("Failed to cross-reference terraform configuration", "target", target, "error", err)
+			log.Fatal(err)
+		}
+
+		if err := printJSON(tfstate.CrossReference(tfconfig, doc)); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(crossrefCmd)
+
+	crossrefCmd.Flags().StringVarP(&crossrefRef, "ref", "r", "", "Git reference to use when the target is a Git repository")
+	crossrefCmd.Flags().StringVar(&crossrefSubDir, "subdir", "", "Subdirectory within the target")
+	crossrefCmd.Flags().StringVar(&crossrefPlan, "plan", "", "JSON plan or state file produced by terraform show -json")
+	crossrefCmd.MarkFlagRequired("plan")
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"log"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/catalog"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/logger"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	dedupeThreshold float64
+)
+
+var dedupeCmd = &cobra.Command{
+	Use:   "dedupe-report <index.json>",
+	Short: "Report duplicated modules in a catalog index",
+	Long: `Cluster the modules of an index built with the index command.
+
+Modules with the same fingerprint are reported as "identical" copies. Modules whose interfaces
+(variable names and types, output names) and resources overlap by at least --threshold are
+reported as "similar", linking modules transitively.`,
+	Example: `  # Report copies across a catalog
+  terraform-config-parser index ./modules > index.json
+  terraform-config-parser dedupe-report index.json --threshold 0.9`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		indexPath := args[0]
+
+		logger.InfoKV("Building dedupe report", "index", indexPath, "threshold", dedupeThreshold)
+
+		index, err := catalog.Load(indexPath)
+		if err != nil {
+			logger.ErrorKV("Failed to load module index", "index", indexPath, "error", err)
+			log.Fatal(err)
+		}
+
+		if err := printJSON(catalog.Dedupe(index, dedupeThreshold)); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(dedupeCmd)
+
+	dedupeCmd.Flags().Float64Var(&dedupeThreshold, "threshold", 0.8, "Minimum similarity (0 to 1) of near-identical modules")
+}
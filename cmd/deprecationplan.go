@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"log"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/deprecation"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/logger"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/source"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	deprecationPlanRef    string
+	deprecationPlanSubDir string
+	deprecationPlanSpec   string
+)
+
+var deprecationPlanCmd = &cobra.Command{
+	Use:   "deprecation-plan <path|url>",
+	Short: "Plan the releases that move a module to a target interface",
+	Long: `Compare the variables and outputs of a module with a target interface spec and print the
+sequence of releases that gets there without breaking callers before a final major release:
+
+  expand     (minor) add new variables as optional and new outputs
+  deprecate  (minor) mark variables and outputs that go away, with their replacement
+  contract   (major) remove them, make variables required and change types
+
+The spec is a YAML file listing the target variables (name, type, required, default,
+renamed_from) and outputs (name, renamed_from). Current variables and outputs missing from
+the spec are removed. Phases without steps are left out.`,
+	Example: `  # Plan the move of a module to the interface in target.yaml
+  terraform-config-parser deprecation-plan ./modules/vpc --spec target.yaml`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		target := args[0]
+
+		spec, err := deprecation.LoadSpec(deprecationPlanSpec)
+		if err != nil {
+			logger.ErrorKV("Failed to load interface spec", "path", deprecationPlanSpec, "error", err)
+			log.Fatal(err)
+		}
+
+		logger.InfoKV("Planning deprecations", "target", target, "ref", deprecationPlanRef, "subdir", deprecationPlanSubDir, "spec", deprecationPlanSpec)
+
+		src := source.New(target, source.SourceConfig{
+			Ref:    deprecationPlanRef,
+			SubDir: deprecationPlanSubDir,
+		})
+
+		current, err := loadWorkspace(src, parser.Simple)
+		if err != nil {
+			logger.ErrorKV("Failed to load module", "target", target, "error", err)
+			log.Fatal(err)
+		}
+
+		if err := printJSON(deprecation.Compute(current, spec)); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(deprecationPlanCmd)
+
+	deprecationPlanCmd.Flags().StringVarP(&deprecationPlanRef, "ref", "r", "", "Git reference to use when the target is a Git repository")
+	deprecationPlanCmd.Flags().StringVar(&deprecationPlanSubDir, "subdir", "", "Subdirectory within the target")
+	deprecationPlanCmd.Flags().StringVar(&deprecationPlanSpec, "spec", "", "Path to the target interface spec (YAML)")
+	deprecationPlanCmd.MarkFlagRequired("spec")
+}
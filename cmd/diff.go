@@ -0,0 +1,241 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"slices"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/config"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/diff"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/diffview"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/logger"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/notify"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/source"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	diffFrom           string
+	diffTo             string
+	diffSubDir         string
+	diffFormat         string
+	diffFailOnBreaking bool
+	diffInteractive    bool
+	diffRefs           []string
+	diffSequential     bool
+	diffIgnoreAttrs    []string
+	diffIgnoreAddrs    []string
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff <before> [after]",
+	Short: "Compare the interface of two versions of a module",
+	Long: `Compare two versions of a module and report added, removed and modified variables and
+outputs, classified as breaking or not, together with resources that are added or removed.
+Each target is treated as a Git repository when it is a URL and as a local directory otherwise.
+
+With a single target, --from and --to select the two Git references to compare.
+With two targets, the first is the old version and the second the new one. The old version
+can also be a summary saved by "local --detail" or "git --detail", so that comparisons do not
+fetch and parse it again.
+
+--interactive opens a terminal viewer with the variables and outputs of both versions side by
+side instead of printing the report; rows expand to show types, defaults, descriptions and the
+reason of each change.
+
+--refs compares several references of a single target instead, listed oldest first, and prints
+the compatibility matrix of the upgrades between them: whether each upgrade is safe, and the
+breaking changes of those that are not. Every version is compared with each later one, or with
+the next one only with --sequential. --format markdown renders the matrix as a table.
+
+--ignore-attribute and --ignore-address leave changes out of the report, e.g. description edits
+or debug_* variables, so that cosmetic edits do not fail --fail-on-breaking. They add to the
+ignore list of the config file:
+
+  diff:
+    ignore:
+      attributes: [description]
+      addresses: ["var.debug_*"]`,
+	Example: `  # Compare two tags of a repository
+  terraform-config-parser diff https://github.com/owner/repo --from v1.0.0 --to v2.0.0 --subdir modules/vpc
+
+  # Compare two local directories
+  terraform-config-parser diff ./vpc-old ./vpc-new
+
+  # Compare a saved summary of the last release with the working copy
+  terraform-config-parser local ./vpc --detail -o vpc-v1.json
+  terraform-config-parser diff vpc-v1.json ./vpc
+
+  # Review a release in the terminal
+  terraform-config-parser diff https://github.com/owner/repo --from v1.0.0 --to v2.0.0 --interactive
+
+  # Find the safe upgrade paths between releases
+  terraform-config-parser diff https://github.com/owner/repo --refs v1.0.0,v1.1.0,v2.0.0 --format markdown
+
+  # Gate on breaking changes, disregarding description edits and debug variables
+  terraform-config-parser diff ./vpc-old ./vpc-new --fail-on-breaking --ignore-attribute description --ignore-address 'var.debug_*'
+
+  # Post the result to Slack and fail on breaking changes
+  terraform-config-parser diff ./vpc-old ./vpc-new --format slack --fail-on-breaking > payload.json`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(diffRefs) > 0 {
+			runDiffMatrix(args)
+			return
+		}
+
+		beforeTarget, afterTarget := args[0], args[0]
+		if len(args) == 2 {
+			afterTarget = args[1]
+		} else if diffFrom == "" || diffTo == "" {
+			log.Fatal("--from and --to are required when comparing references of a single target")
+		}
+
+		logger.InfoKV("Comparing module versions", "before", beforeTarget, "after", afterTarget, "from", diffFrom, "to", diffTo, "subdir", diffSubDir)
+
+		before, after, err := loadVersions(beforeTarget, afterTarget)
+		if err != nil {
+			logger.ErrorKV("Failed to compare module versions", "before", beforeTarget, "after", afterTarget, "error", err)
+			log.Fatal(err)
+		}
+
+		if diffInteractive {
+			if err := diffview.Run(diffview.New(diffTitle(beforeTarget, afterTarget), before, after), os.Stdin, os.Stdout); err != nil {
+				log.Fatal(err)
+			}
+			return
+		}
+
+		report := diff.CompareIgnoring(before, after, diffIgnore())
+
+		if err := printReport(report, notify.DiffMessage(diffTitle(beforeTarget, afterTarget), report), diffFormat); err != nil {
+			log.Fatal(err)
+		}
+
+		if diffFailOnBreaking && report.HasBreaking() {
+			logger.ErrorKV("Breaking changes found", "count", report.Breaking)
+			exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+
+	diffCmd.Flags().StringVar(&diffFrom, "from", "", "Git reference of the old version")
+	diffCmd.Flags().StringVar(&diffTo, "to", "", "Git reference of the new version")
+	diffCmd.Flags().StringVar(&diffSubDir, "subdir", "", "Subdirectory within the targets")
+	diffCmd.Flags().StringVar(&diffFormat, "format", "json", "Output format (json, slack, teams; json or markdown with --refs)")
+	diffCmd.Flags().BoolVar(&diffFailOnBreaking, "fail-on-breaking", false, "Exit with status 1 when breaking changes are found")
+	diffCmd.Flags().BoolVarP(&diffInteractive, "interactive", "i", false, "Browse the variables and outputs of both versions in a terminal viewer")
+	diffCmd.Flags().StringSliceVar(&diffRefs, "refs", nil, "Git references to compare pairwise, oldest first, e.g. v1.0.0,v2.0.0,v3.0.0")
+	diffCmd.Flags().BoolVar(&diffSequential, "sequential", false, "With --refs, compare each reference with the next one only")
+	diffCmd.Flags().StringSliceVar(&diffIgnoreAttrs, "ignore-attribute", nil, "Disregard changes of this attribute, e.g. description (repeatable)")
+	diffCmd.Flags().StringSliceVar(&diffIgnoreAddrs, "ignore-address", nil, "Leave out the changes of blocks matching this glob pattern, e.g. 'var.debug_*' (repeatable)")
+}
+
+// diffIgnore is the ignore list of the config file extended with the --ignore-* flags
+func diffIgnore() *diff.Ignore {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	configured := cfg.DiffIgnore()
+	return &diff.Ignore{
+		Attributes: slices.Concat(configured.Attributes, diffIgnoreAttrs),
+		Addresses:  slices.Concat(configured.Addresses, diffIgnoreAddrs),
+	}
+}
+
+// runDiffMatrix prints the compatibility matrix of the --refs of a single target
+func runDiffMatrix(args []string) {
+	if len(args) != 1 || diffFrom != "" || diffTo != "" || diffInteractive {
+		log.Fatal("--refs compares the references of a single target and cannot be combined with --from, --to or --interactive")
+	}
+	if len(diffRefs) < 2 {
+		log.Fatal("--refs needs at least two references")
+	}
+	if diffFormat != "json" && diffFormat != "markdown" {
+		log.Fatalf("unknown format %q for --refs (expected json or markdown)", diffFormat)
+	}
+	target := args[0]
+
+	configs := make([]*parser.TerraformConfig, 0, len(diffRefs))
+	for _, ref := range diffRefs {
+		logger.InfoKV("Loading module version", "target", target, "ref", ref, "subdir", diffSubDir)
+		tfconfig, err := loadWorkspace(source.New(target, source.SourceConfig{Ref: ref, SubDir: diffSubDir}), parser.Detail)
+		if err != nil {
+			logger.ErrorKV("Failed to load module version", "target", target, "ref", ref, "error", err)
+			log.Fatal(fmt.Errorf("failed to load %s: %w", ref, err))
+		}
+		configs = append(configs, tfconfig)
+	}
+
+	matrix, err := diff.NewMatrix(diffRefs, configs, diffSequential, diffIgnore())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if diffFormat == "markdown" {
+		fmt.Fprint(stdout, matrix.Markdown())
+	} else if err := printJSON(matrix); err != nil {
+		log.Fatal(err)
+	}
+
+	if diffFailOnBreaking && slices.ContainsFunc(matrix.Upgrades, func(u *diff.Upgrade) bool { return !u.Safe }) {
+		logger.ErrorKV("Breaking upgrades found", "refs", diffRefs)
+		exit(1)
+	}
+}
+
+func loadVersions(beforeTarget, afterTarget string) (*parser.TerraformConfig, *parser.TerraformConfig, error) {
+	before, err := loadBefore(beforeTarget)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load old version: %w", err)
+	}
+
+	after, err := loadWorkspace(source.New(afterTarget, source.SourceConfig{Ref: diffTo, SubDir: diffSubDir}), parser.Detail)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load new version: %w", err)
+	}
+
+	return before, after, nil
+}
+
+// loadBefore loads the old version from a saved summary when beforeTarget is a file other than
+// a bundle, and parses it otherwise
+func loadBefore(beforeTarget string) (*parser.TerraformConfig, error) {
+	if info, err := os.Stat(beforeTarget); err != nil || !info.Mode().IsRegular() || source.IsBundle(beforeTarget) {
+		return loadWorkspace(source.New(beforeTarget, source.SourceConfig{Ref: diffFrom, SubDir: diffSubDir}), parser.Detail)
+	}
+
+	logger.InfoKV("Loading saved summary", "path", beforeTarget)
+	content, err := os.ReadFile(beforeTarget)
+	if err != nil {
+		return nil, err
+	}
+	return parser.LoadSummary(content)
+}
+
+func diffTitle(beforeTarget, afterTarget string) string {
+	if beforeTarget == afterTarget {
+		return fmt.Sprintf("%s: %s → %s", beforeTarget, diffFrom, diffTo)
+	}
+	return fmt.Sprintf("%s → %s", beforeTarget, afterTarget)
+}
+
+// printReport prints report as JSON, or msg as a chat payload for the slack and teams formats
+func printReport(report any, msg *notify.Message, format string) error {
+	if format == "json" {
+		return printJSON(report)
+	}
+
+	notifyFormat, err := notify.ParseFormat(format)
+	if err != nil {
+		return err
+	}
+	return printJSON(msg.Payload(notifyFormat))
+}
@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"context"
+	"io"
+	"log"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/config"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/doctor"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/logger"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/source"
+	"github.com/Yunsang-Jeong/terraform-config-parser/version"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	doctorStrict  bool
+	doctorOffline bool
+	doctorHosts   []string
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the installation",
+	Long: `Run self-checks of the binary and its environment and report each problem with a remediation:
+
+  version         the binary carries a release version
+  commit          the commit it was built from is known and was clean
+  build_date      the build date is a valid RFC 3339 time
+  runtime         the Go version and platform of the build
+  default_config  the embedded default configuration parses
+  completions     the shell completion scripts can be generated
+  cache_dir       the cache directory ($TFPARSER_CACHE_DIR) is writable
+  proxy           HTTP_PROXY, HTTPS_PROXY and NO_PROXY are valid, and how each host is reached
+  git_host:<host> the git host answers HTTPS requests
+  token:<host>    the token used for the host (from the config file, GITHUB_TOKEN, GITLAB_TOKEN
+                  or GIT_TOKEN) is accepted by its API; GitHub and GitLab hosts only
+
+The hosts are those given with --host, or else those with a token, or else github.com.
+--offline skips the git host and token checks. Failed checks exit with status 1; --strict
+fails on warnings too, as release self-tests do.`,
+	Example: `  # Check an installed binary and the access to github.com
+  terraform-config-parser doctor
+
+  # Check the access to a self-hosted GitLab, given as a host or repository URL
+  terraform-config-parser doctor --host https://gitlab.example.com/platform/modules.git
+
+  # Check the binary only
+  terraform-config-parser doctor --offline`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		report, err := runDoctor(cmd.Context())
+		if err != nil {
+			logger.ErrorKV("Failed to run self-checks", "error", err)
+			log.Fatal(err)
+		}
+
+		if err := printJSON(report); err != nil {
+			log.Fatal(err)
+		}
+
+		if report.Failed(doctorStrict) {
+			logger.ErrorKV("Self-checks failed", "errors", report.Errors, "warnings", report.Warnings)
+			exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+
+	doctorCmd.Flags().BoolVar(&doctorStrict, "strict", false, "Fail on warnings too")
+	doctorCmd.Flags().BoolVar(&doctorOffline, "offline", false, "Skip the checks needing network access")
+	doctorCmd.Flags().StringSliceVar(&doctorHosts, "host", nil, "Git host or repository URL to check the access to (repeatable)")
+}
+
+func runDoctor(ctx context.Context) (*doctor.Report, error) {
+	report := &doctor.Report{Checks: []*doctor.Check{}}
+	for _, check := range doctor.CheckBuild(version.GetInfo()) {
+		report.Add(check)
+	}
+	report.Add(doctor.CheckDefaultConfig())
+	report.Add(doctor.CheckCompletions(completionGenerators(rootCmd)))
+
+	cacheDir, err := source.CacheDir()
+	if err != nil {
+		report.Add(&doctor.Check{Name: "cache_dir", Status: doctor.StatusError, Detail: err.Error(),
+			Remediation: "set " + source.CacheDirEnv + " to a writable directory"})
+	} else {
+		report.Add(doctor.CheckCacheDir(cacheDir))
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return nil, err
+	}
+	hosts, tokens := doctorTargets(cfg)
+	report.Add(doctor.CheckProxy(hosts))
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	for _, host := range hosts {
+		if doctorOffline {
+			report.Add(&doctor.Check{Name: "git_host:" + host, Status: doctor.StatusSkipped, Detail: "offline"})
+			continue
+		}
+		report.Add(doctor.CheckGitHost(ctx, client, host))
+	}
+	for _, token := range tokens {
+		if doctorOffline {
+			report.Add(&doctor.Check{Name: "token:" + token.Host, Status: doctor.StatusSkipped, Detail: "offline"})
+			continue
+		}
+		if token.Host == "" {
+			report.Add(&doctor.Check{Name: "token", Status: doctor.StatusSkipped, Detail: token.Origin + " is set; pass --host to verify it against a host"})
+			continue
+		}
+		report.Add(doctor.CheckToken(ctx, client, token))
+	}
+	return report, nil
+}
+
+// doctorTargets returns the hosts to check and the token git clones would use for each, the
+// way GitSource picks them: the config file first, then GITHUB_TOKEN or GITLAB_TOKEN for their
+// hosts, then GIT_TOKEN. A GIT_TOKEN without host to check is returned with an empty host.
+func doctorTargets(cfg *config.Config) ([]string, []doctor.Token) {
+	hosts := []string{}
+	for _, value := range doctorHosts {
+		host := value
+		if u, err := url.Parse(value); err == nil && u.Hostname() != "" {
+			host = u.Hostname()
+		}
+		hosts = append(hosts, strings.ToLower(host))
+	}
+
+	tokens := []doctor.Token{}
+	covered := map[string]bool{}
+	for _, host := range slices.Sorted(maps.Keys(cfg.Credentials)) {
+		if cfg.Credentials[host].Token != "" {
+			host = strings.ToLower(host)
+			tokens = append(tokens, doctor.Token{Host: host, Origin: "the token of " + host + " in the config file", Value: cfg.Credentials[host].Token})
+			covered[host] = true
+		}
+	}
+	for host, env := range map[string]string{"github.com": "GITHUB_TOKEN", "gitlab.com": "GITLAB_TOKEN"} {
+		if value := os.Getenv(env); value != "" && !covered[host] && (len(hosts) == 0 || slices.Contains(hosts, host)) {
+			tokens = append(tokens, doctor.Token{Host: host, Origin: env, Value: value})
+			covered[host] = true
+		}
+	}
+	if len(hosts) == 0 {
+		for _, token := range tokens {
+			hosts = append(hosts, token.Host)
+		}
+		if len(hosts) == 0 {
+			hosts = append(hosts, "github.com")
+		}
+	}
+
+	if value := os.Getenv("GIT_TOKEN"); value != "" {
+		if len(doctorHosts) == 0 {
+			tokens = append(tokens, doctor.Token{Origin: "GIT_TOKEN", Value: value})
+		}
+		for _, host := range hosts {
+			if !covered[host] && len(doctorHosts) > 0 {
+				tokens = append(tokens, doctor.Token{Host: host, Origin: "GIT_TOKEN", Value: value})
+			}
+		}
+	}
+
+	slices.SortFunc(tokens, func(a, b doctor.Token) int { return strings.Compare(a.Host, b.Host) })
+	slices.Sort(hosts)
+	return slices.Compact(hosts), tokens
+}
+
+// completionGenerators write the completion script of each shell for root
+func completionGenerators(root *cobra.Command) map[string]func(w io.Writer) error {
+	return map[string]func(w io.Writer) error{
+		"bash":       func(w io.Writer) error { return root.GenBashCompletionV2(w, true) },
+		"zsh":        root.GenZshCompletion,
+		"fish":       func(w io.Writer) error { return root.GenFishCompletion(w, true) },
+		"powershell": root.GenPowerShellCompletionWithDesc,
+	}
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/lint"
+
+	"github.com/spf13/cobra"
+)
+
+var explainFormat string
+
+var explainCmd = &cobra.Command{
+	Use:   "explain [rule-id]",
+	Short: "Explain a lint or policy rule",
+	Long: `Print the rationale of a built-in lint or policy rule, an example violating it, an example
+following it and how to fix its findings. Without rule ID, list the rules.
+
+The rule ID is the rule_id of findings and the ID used by "# tfparser:ignore <rule-id>"
+comments, the rules section of the config file and --severity.`,
+	Example: `  # List the rules
+  terraform-config-parser explain
+
+  # Explain a rule
+  terraform-config-parser explain module-git-pinned`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		explanations := []*lint.Explanation{}
+		if len(args) == 0 {
+			for _, rule := range lint.Rules() {
+				explanation, err := lint.Explain(rule.ID)
+				if err != nil {
+					log.Fatal(err)
+				}
+				explanations = append(explanations, explanation)
+			}
+		} else {
+			explanation, err := lint.Explain(args[0])
+			if err != nil {
+				log.Fatal(err)
+			}
+			explanations = append(explanations, explanation)
+		}
+
+		switch explainFormat {
+		case "json":
+			var v any = explanations
+			if len(args) == 1 {
+				v = explanations[0]
+			}
+			if err := printJSON(v); err != nil {
+				log.Fatal(err)
+			}
+		case "text":
+			if len(args) == 1 {
+				fmt.Fprint(stdout, formatExplanation(explanations[0]))
+				return
+			}
+			for _, explanation := range explanations {
+				fmt.Fprintf(stdout, "%-30s %-8s %-8s %s\n", explanation.ID, explanation.Category, explanation.DefaultSeverity, explanation.Description)
+			}
+		default:
+			log.Fatalf("unknown format %q", explainFormat)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(explainCmd)
+
+	explainCmd.Flags().StringVar(&explainFormat, "format", "text", "Output format (text, json)")
+}
+
+func formatExplanation(explanation *lint.Explanation) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s (%s, default severity %s)\n", explanation.ID, explanation.Category, explanation.DefaultSeverity)
+	fmt.Fprintf(&b, "\n%s\n", explanation.Description)
+	fmt.Fprintf(&b, "\nWhy:\n%s\n", indent(explanation.Rationale))
+	fmt.Fprintf(&b, "\nBad:\n%s", indent(explanation.Bad))
+	fmt.Fprintf(&b, "\nGood:\n%s", indent(explanation.Good))
+	fmt.Fprintf(&b, "\nFix:\n%s\n", indent(explanation.Fix))
+	fmt.Fprintf(&b, "\nSuppress a finding with a comment above the block:\n  # tfparser:ignore %s <reason>\n", explanation.ID)
+	return b.String()
+}
+
+func indent(text string) string {
+	lines := strings.SplitAfter(text, "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			lines[i] = "  " + line
+		}
+	}
+	return strings.Join(lines, "")
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"log"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/logger"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/source"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	filesRef       string
+	filesSubDir    string
+	filesRecursive bool
+)
+
+var filesCmd = &cobra.Command{
+	Use:   "files <path|url>",
+	Short: "List the files a run reads, with their size and SHA-256",
+	Long: `List every file of the configuration directory of the target, with --recursive of every
+configuration directory under it, as considered (configuration, lock and ignore files, which a
+run reads), skipped (other files) or excluded (hidden by .tfparserignore).
+The target is treated as a Git repository when it is a URL and as a local directory otherwise.
+
+Files that are not excluded have their size and SHA-256. The digest hashes the considered files
+and their paths, so that two runs with the same digest had the same inputs; for git sources the
+commit is recorded as well.`,
+	Example: `  # Record the inputs of a summary
+  terraform-config-parser files ./infra > inputs.json
+
+  # List the files of every configuration directory of a repository
+  terraform-config-parser files https://github.com/owner/repo --ref v1.2.0 --recursive`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		target := args[0]
+
+		logger.InfoKV("Listing workspace files", "target", target, "ref", filesRef, "subdir", filesSubDir, "recursive", filesRecursive)
+
+		src := source.New(target, source.SourceConfig{
+			Ref:    filesRef,
+			SubDir: filesSubDir,
+		})
+
+		list, err := listFiles(src, filesRecursive)
+		if err != nil {
+			logger.ErrorKV("Failed to list workspace files", "target", target, "error", err)
+			log.Fatal(err)
+		}
+
+		if err := printJSON(list); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(filesCmd)
+
+	filesCmd.Flags().StringVarP(&filesRef, "ref", "r", "", "Git reference to use when the target is a Git repository")
+	filesCmd.Flags().StringVar(&filesSubDir, "subdir", "", "Subdirectory within the target")
+	filesCmd.Flags().BoolVar(&filesRecursive, "recursive", false, "List the files of every directory with .tf files")
+}
+
+func listFiles(src source.Source, recursive bool) (*source.FileList, error) {
+	fs, rootPath, err := fetchSource(src, recursive)
+	if err != nil {
+		return nil, err
+	}
+	defer src.Cleanup()
+
+	list, err := source.ListFiles(fs, rootPath, recursive)
+	if err != nil {
+		return nil, err
+	}
+	switch s := src.(type) {
+	case *source.GitSource:
+		list.Commit = s.Commit
+	case *source.BundleSource:
+		list.Commit = s.Manifest().Commit
+	}
+	return list, nil
+}
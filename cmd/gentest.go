@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/logger"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/source"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/testgen"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	genTestRef           string
+	genTestSubDir        string
+	genTestMockProviders bool
+)
+
+var genTestCmd = &cobra.Command{
+	Use:   "gen-test <path|url>",
+	Short: "Scaffold a Terraform test file for a module",
+	Long: `Scaffold a native Terraform test file (.tftest.hcl) exercising the module.
+The target is treated as a Git repository when it is a URL and as a local directory otherwise.
+
+Required variables are filled with placeholders derived from their type constraints,
+optional variables keep their defaults. With --mock-providers, every required provider
+is mocked and an apply run asserts that each output is set.`,
+	Example: `  # Scaffold a plan-only test
+  terraform-config-parser gen-test ./modules/vpc > ./modules/vpc/tests/main.tftest.hcl
+
+  # Scaffold a test using mocked providers
+  terraform-config-parser gen-test ./modules/vpc --mock-providers`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		target := args[0]
+
+		logger.InfoKV("Generating terraform test", "target", target, "ref", genTestRef, "subdir", genTestSubDir)
+
+		src := source.New(target, source.SourceConfig{
+			Ref:    genTestRef,
+			SubDir: genTestSubDir,
+		})
+
+		tfconfig, err := loadWorkspace(src, parser.Simple)
+		if err != nil {
+			logger.ErrorKV("Failed to generate terraform test", "target", target, "error", err)
+			log.Fatal(err)
+		}
+
+		fmt.Fprint(stdout, string(testgen.Generate(tfconfig, testgen.Options{
+			MockProviders: genTestMockProviders,
+		})))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(genTestCmd)
+
+	genTestCmd.Flags().StringVarP(&genTestRef, "ref", "r", "", "Git reference to use when the target is a Git repository")
+	genTestCmd.Flags().StringVar(&genTestSubDir, "subdir", "", "Subdirectory within the target")
+	genTestCmd.Flags().BoolVar(&genTestMockProviders, "mock-providers", false, "Mock required providers and assert outputs in an apply run")
+}
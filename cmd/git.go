@@ -10,8 +10,11 @@ import (
 )
 
 var (
-	gitRef    string
-	gitSubDir string
+	gitRef       string
+	gitSubDir    string
+	gitLang      string
+	gitWithAST   bool
+	gitRecursive bool
 )
 
 var gitCmd = &cobra.Command{
@@ -44,8 +47,15 @@ The --ref parameter accepts:
   terraform-config-parser git git@github.com:owner/repo.git
   
   # Private repositories work with your existing Git credentials`,
-	Args: cobra.ExactArgs(1),
+	Args: argsOrPrompt(cobra.ExactArgs(1)),
 	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			_, target, err := promptTarget(cmd, "git")
+			if err != nil {
+				log.Fatal(err)
+			}
+			args = []string{target}
+		}
 		url := args[0]
 
 		logger.InfoKV("Processing git repository", "url", url, "ref", gitRef, "subdir", gitSubDir)
@@ -55,7 +65,7 @@ The --ref parameter accepts:
 			SubDir: gitSubDir,
 		})
 
-		if err := parseAndOutput(src); err != nil {
+		if err := parseAndOutput(src, gitLang, gitWithAST, gitRecursive); err != nil {
 			logger.ErrorKV("Failed to parse and output git source", "url", url, "ref", gitRef, "subdir", gitSubDir, "error", err)
 			log.Fatal(err)
 		}
@@ -67,4 +77,20 @@ func init() {
 
 	gitCmd.Flags().StringVarP(&gitRef, "ref", "r", "", "Git reference to use: branch name, tag name, or commit hash (default: repository default branch)")
 	gitCmd.Flags().StringVar(&gitSubDir, "subdir", "", "Subdirectory within the repository")
+	gitCmd.Flags().StringVar(&gitLang, "lang", "", "Replace descriptions with translations from descriptions.<lang>.yaml")
+	gitCmd.Flags().BoolVar(&gitWithAST, "with-ast", false, "Include the expression AST of every attribute")
+	gitCmd.Flags().IntVar(&astDeferSize, "ast-defer-size", 0, "With --with-ast, replace the AST of expressions longer than this many bytes with their location (0 keeps every AST)")
+	gitCmd.Flags().BoolVar(&validateBlocks, "validate-blocks", false, "Check blocks against Terraform's block schemas and report unknown arguments and misplaced blocks as diagnostics")
+	gitCmd.Flags().BoolVar(&withKinds, "with-kinds", false, "Tag the attributes of every block with the kind of their value (literal, reference, function, conditional, complex)")
+	gitCmd.Flags().BoolVar(&lenient, "lenient", false, "Report syntax errors and blocks that fail to parse as diagnostics and keep the blocks that can be read, instead of failing")
+	gitCmd.Flags().BoolVar(&withPositions, "with-positions", false, "Add the file, start_line and end_line of every block")
+	gitCmd.Flags().BoolVar(&flattenValues, "flatten", false, "Add flat_default and flat_config fields mapping dotted key paths of nested values to their leaves")
+	gitCmd.Flags().BoolVar(&summaryDetail, "detail", false, "Include module calls, resources, data sources, providers, imports and locals in JSON output")
+	gitCmd.Flags().StringVar(&summaryFormat, "format", "json", "Output format (json, markdown, template)")
+	gitCmd.Flags().StringVar(&templateFile, "template-file", "", "Go text/template file rendering the configuration, with --format template")
+	gitCmd.Flags().StringVar(&outputDir, "output-dir", "", "With --recursive, write the result of each directory to <dir>/<path>/summary.<ext> instead")
+	gitCmd.Flags().BoolVar(&ndjson, "ndjson", false, "With --recursive, write one compact JSON line per directory: {\"path\": ..., \"config\": ...}")
+	gitCmd.Flags().BoolVar(&gitRecursive, "recursive", false, "Parse every directory with .tf files and print the configurations keyed by path")
+	gitCmd.Flags().IntVar(&maxDepth, "max-depth", 0, "With --recursive, parse directories at most this many levels below the target (0: no limit)")
+	gitCmd.Flags().StringSliceVar(&prunePaths, "prune", nil, "With --recursive, skip directories matching this glob of the path relative to the target, or of the directory name when it has no slash (repeatable)")
 }
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/graph"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/logger"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/source"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	graphRef    string
+	graphSubDir string
+	graphFormat string
+)
+
+var graphCmd = &cobra.Command{
+	Use:   "graph <path|url>",
+	Short: "Print the dependency graph of Terraform configurations",
+	Long: `Print the dependency graph between variables, resources, data sources, modules and outputs.
+The target is treated as a Git repository when it is a URL and as a local directory otherwise.
+
+Edges point from the dependent object to its dependency. Dependencies declared with
+depends_on are reported as "explicit" edges, references in expressions as "implicit" edges.`,
+	Example: `  # Print graph as JSON
+  terraform-config-parser graph ./terraform
+
+  # Render graph with Graphviz
+  terraform-config-parser graph ./terraform --format dot | dot -Tpng -o graph.png`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		target := args[0]
+
+		logger.InfoKV("Building dependency graph", "target", target, "ref", graphRef, "subdir", graphSubDir)
+
+		src := source.New(target, source.SourceConfig{
+			Ref:    graphRef,
+			SubDir: graphSubDir,
+		})
+
+		if err := printGraph(src); err != nil {
+			logger.ErrorKV("Failed to build dependency graph", "target", target, "error", err)
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(graphCmd)
+
+	graphCmd.Flags().StringVarP(&graphRef, "ref", "r", "", "Git reference to use when the target is a Git repository")
+	graphCmd.Flags().StringVar(&graphSubDir, "subdir", "", "Subdirectory within the target")
+	graphCmd.Flags().StringVar(&graphFormat, "format", "json", "Output format (json, dot)")
+}
+
+func printGraph(src source.Source) error {
+	tfconfig, err := loadWorkspace(src, parser.Detail)
+	if err != nil {
+		return err
+	}
+
+	g := graph.Build(tfconfig)
+
+	switch graphFormat {
+	case "json":
+		return printJSON(g)
+	case "dot":
+		fmt.Fprintln(stdout, g.DOT())
+		return nil
+	default:
+		return fmt.Errorf("unsupported graph format: %s", graphFormat)
+	}
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/batch"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/logger"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/source"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/usage"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	impactModule    string
+	impactFrom      string
+	impactTo        string
+	impactRecursive bool
+)
+
+var impactCmd = &cobra.Command{
+	Use:   "impact <manifest>",
+	Short: "List the roots affected by upgrading a shared module",
+	Long: `Compare two refs of a git module (see diff) and check every root configuration of a
+source manifest (see usage) that calls the old ref. Consumers are grouped by whether a breaking
+interface change applies to their module call:
+
+  - removed variables and type changes only break calls that set the variable
+  - variables that lose their default only break calls that do not set it
+  - removed outputs and outputs that become sensitive break every consumer
+
+Consumers pinned to other refs are not listed.`,
+	Example: `  # Who has to change their code when moving from v1 to v2 of the VPC module
+  terraform-config-parser impact roots.yaml --module git::https://github.com/owner/modules.git//vpc --from v1 --to v2`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		manifestPath := args[0]
+
+		module := source.ParseModuleSource(impactModule)
+		if module.Kind != source.ModuleSourceGit {
+			log.Fatalf("--module must be a git module source, got %q", impactModule)
+		}
+
+		manifest, err := batch.Load(manifestPath)
+		if err != nil {
+			logger.ErrorKV("Failed to load source manifest", "path", manifestPath, "error", err)
+			log.Fatal(err)
+		}
+
+		logger.InfoKV("Analyzing upgrade impact", "module", impactModule, "from", impactFrom, "to", impactTo, "roots", len(manifest.Sources))
+
+		builder := usage.NewBuilder()
+		for _, entry := range manifest.Sources {
+			src := source.New(entry.Target, source.SourceConfig{Ref: entry.Ref, SubDir: entry.SubDir})
+			if err := collectUsage(builder, entry.Name, src, impactRecursive); err != nil {
+				logger.ErrorKV("Failed to parse root", "root", entry.Name, "error", err)
+				builder.AddError(entry.Name, ".", err)
+			}
+		}
+
+		address := usage.NormalizeSource(module)
+		consumers := builder.Report().Find(address)
+		if consumers == nil {
+			consumers = &usage.Module{Source: address, Kind: module.Kind.String()}
+		}
+
+		before, err := loadWorkspace(source.New(module.RepositoryURL(), source.SourceConfig{Ref: impactFrom, SubDir: module.SubDir}), parser.Detail)
+		if err != nil {
+			log.Fatal(fmt.Errorf("failed to load %s: %w", impactFrom, err))
+		}
+		after, err := loadWorkspace(source.New(module.RepositoryURL(), source.SourceConfig{Ref: impactTo, SubDir: module.SubDir}), parser.Detail)
+		if err != nil {
+			log.Fatal(fmt.Errorf("failed to load %s: %w", impactTo, err))
+		}
+
+		impact := usage.Analyze(consumers, impactFrom, impactTo, before, after)
+		logger.InfoKV("Upgrade impact", "breaking", len(impact.Breaking), "non_breaking", len(impact.NonBreaking))
+
+		if err := printJSON(impact); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(impactCmd)
+
+	impactCmd.Flags().StringVar(&impactModule, "module", "", "Source of the git module, e.g. git::https://github.com/owner/modules.git//vpc")
+	impactCmd.Flags().StringVar(&impactFrom, "from", "", "Ref the consumers are pinned to")
+	impactCmd.Flags().StringVar(&impactTo, "to", "", "Ref to upgrade to")
+	impactCmd.Flags().BoolVar(&impactRecursive, "recursive", false, "Also check module calls of every configuration directory under each root")
+	impactCmd.MarkFlagRequired("module")
+	impactCmd.MarkFlagRequired("from")
+	impactCmd.MarkFlagRequired("to")
+}
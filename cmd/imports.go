@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/imports"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/logger"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/source"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	importsRef      string
+	importsSubDir   string
+	importsCommands bool
+)
+
+var importsCmd = &cobra.Command{
+	Use:   "imports <path|url>",
+	Short: "Report import block mappings and generate terraform import commands",
+	Long: `Report how import blocks map resource addresses to import IDs.
+The target is treated as a Git repository when it is a URL and as a local directory otherwise.
+
+The report also lists resources that no import block targets. With --commands, the mappings
+are printed as "terraform import" CLI invocations for migration runbooks instead.
+Import IDs computed from expressions are emitted as comments.`,
+	Example: `  # Print mapping report
+  terraform-config-parser imports ./terraform
+
+  # Print terraform import commands
+  terraform-config-parser imports ./terraform --commands > import.sh`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		target := args[0]
+
+		logger.InfoKV("Building import report", "target", target, "ref", importsRef, "subdir", importsSubDir)
+
+		src := source.New(target, source.SourceConfig{
+			Ref:    importsRef,
+			SubDir: importsSubDir,
+		})
+
+		tfconfig, err := loadWorkspace(src, parser.Detail)
+		if err != nil {
+			logger.ErrorKV("Failed to build import report", "target", target, "error", err)
+			log.Fatal(err)
+		}
+
+		report := imports.BuildReport(tfconfig)
+
+		if importsCommands {
+			for _, command := range report.Commands() {
+				fmt.Fprintln(stdout, command)
+			}
+			return
+		}
+
+		if err := printJSON(report); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(importsCmd)
+
+	importsCmd.Flags().StringVarP(&importsRef, "ref", "r", "", "Git reference to use when the target is a Git repository")
+	importsCmd.Flags().StringVar(&importsSubDir, "subdir", "", "Subdirectory within the target")
+	importsCmd.Flags().BoolVar(&importsCommands, "commands", false, "Print terraform import commands instead of the mapping report")
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"log"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/catalog"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/logger"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/source"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	indexRef    string
+	indexSubDir string
+)
+
+var indexCmd = &cobra.Command{
+	Use:   "index <path|url>",
+	Short: "Build a catalog index of all modules under a directory",
+	Long: `Build a catalog index of every directory containing Terraform configurations under the target.
+The target is treated as a Git repository when it is a URL and as a local directory otherwise.
+
+Each entry records the module path, its content fingerprint, variables, outputs and resources.
+Modules that fail to parse are listed under "errors". The index is the input of dedupe-report.`,
+	Example: `  # Index a monorepo of modules
+  terraform-config-parser index ./modules > index.json`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		target := args[0]
+
+		logger.InfoKV("Building module index", "target", target, "ref", indexRef, "subdir", indexSubDir)
+
+		src := source.New(target, source.SourceConfig{
+			Ref:    indexRef,
+			SubDir: indexSubDir,
+		})
+
+		index, err := buildIndex(src)
+		if err != nil {
+			logger.ErrorKV("Failed to build module index", "target", target, "error", err)
+			log.Fatal(err)
+		}
+
+		if err := printJSON(index); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(indexCmd)
+
+	indexCmd.Flags().StringVarP(&indexRef, "ref", "r", "", "Git reference to use when the target is a Git repository")
+	indexCmd.Flags().StringVar(&indexSubDir, "subdir", "", "Subdirectory within the target")
+}
+
+func buildIndex(src source.Source) (*catalog.Index, error) {
+	fs, rootPath, err := fetchSource(src, true)
+	if err != nil {
+		return nil, err
+	}
+	defer src.Cleanup()
+
+	tracker, err := newTracker("modules parsed")
+	if err != nil {
+		return nil, err
+	}
+
+	return catalog.Build(fs, rootPath, tracker)
+}
@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/source"
+
+	"github.com/charmbracelet/huh"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var (
+	noInput bool
+	// promptInput and promptOutput are where the prompts read answers and are drawn
+	promptInput  io.Reader = os.Stdin
+	promptOutput io.Writer = os.Stderr
+)
+
+func init() {
+	rootCmd.PersistentFlags().BoolVar(&noInput, "no-input", false, "Never prompt for missing arguments, even in a terminal")
+
+	// Without subcommand, a terminal session is walked through a local or git run
+	rootCmd.Run = func(cmd *cobra.Command, args []string) {
+		if !interactive() {
+			cmd.Help()
+			return
+		}
+
+		kind, target, err := promptTarget(cmd, "")
+		if err != nil {
+			log.Fatal(err)
+		}
+		sub := localCmd
+		if kind == "git" {
+			sub = gitCmd
+		}
+		sub.Run(sub, []string{target})
+	}
+}
+
+// interactive reports whether missing arguments can be prompted for: standard input and
+// standard error, where the prompts are drawn, are terminals and --no-input is not set
+func interactive() bool {
+	return !noInput && term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stderr.Fd()))
+}
+
+// argsOrPrompt accepts no arguments when the command can prompt for them and checks the
+// arguments with validate otherwise
+func argsOrPrompt(validate cobra.PositionalArgs) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 && interactive() {
+			return nil
+		}
+		return validate(cmd, args)
+	}
+}
+
+// promptTarget asks for the source to parse and stores the answers in the flags of the local or
+// git command: the source type unless kind sets it, the path or URL, the ref, subdirectory and
+// format. Settings given as flags of cmd are not asked.
+func promptTarget(cmd *cobra.Command, kind string) (string, string, error) {
+	if kind == "" {
+		kind = "local"
+		// The source type decides the next questions, so it is asked first: the next form only
+		// holds the questions to ask, since accessible mode asks every field of a form
+		sourceType := huh.NewSelect[string]().
+			Title("Source").
+			Options(huh.NewOption("Local directory", "local"), huh.NewOption("Git repository", "git")).
+			Value(&kind)
+		if err := runForm(cmd, huh.NewGroup(sourceType)); err != nil {
+			return "", "", err
+		}
+	}
+
+	var target string
+	ref, subDir, format := gitRef, localSubDir, summaryFormat
+	if kind == "git" {
+		subDir = gitSubDir
+	}
+	if format == "template" {
+		format = "json"
+	}
+
+	var groups []*huh.Group
+	if kind == "local" {
+		groups = append(groups, huh.NewGroup(
+			huh.NewInput().
+				Title("Path").
+				Description("Directory with .tf files, or a "+source.BundleExtension+" bundle").
+				Placeholder(".").
+				Value(&target).
+				Validate(validateLocalTarget),
+		))
+	} else {
+		fields := []huh.Field{
+			huh.NewInput().
+				Title("Repository URL").
+				Placeholder("https://github.com/owner/repo").
+				Value(&target).
+				Validate(validateGitTarget),
+		}
+		if !flagChanged(cmd, "ref") {
+			fields = append(fields, huh.NewInput().
+				Title("Ref").
+				Description("Branch, tag or commit; empty for the default branch").
+				Value(&ref))
+		}
+		groups = append(groups, huh.NewGroup(fields...))
+	}
+	if !flagChanged(cmd, "subdir") {
+		groups = append(groups, huh.NewGroup(
+			huh.NewInput().
+				Title("Subdirectory").
+				Description("Directory of the configuration within the source; empty for the root").
+				Value(&subDir),
+		))
+	}
+	if !flagChanged(cmd, "format") {
+		groups = append(groups, huh.NewGroup(
+			huh.NewSelect[string]().
+				Title("Format").
+				Options(huh.NewOption("JSON", "json"), huh.NewOption("Markdown", "markdown")).
+				Value(&format),
+		))
+	}
+
+	if err := runForm(cmd, groups...); err != nil {
+		return "", "", err
+	}
+
+	if kind == "local" && target == "" {
+		target = "."
+	}
+	if kind == "git" {
+		gitRef, gitSubDir = strings.TrimSpace(ref), strings.TrimSpace(subDir)
+	} else {
+		localSubDir = strings.TrimSpace(subDir)
+	}
+	if !flagChanged(cmd, "format") {
+		summaryFormat = format
+	}
+	return kind, strings.TrimSpace(target), nil
+}
+
+// runForm asks the questions of groups on promptInput, drawing them on promptOutput. With the
+// ACCESSIBLE environment variable set, questions are asked line by line for screen readers.
+func runForm(cmd *cobra.Command, groups ...*huh.Group) error {
+	form := huh.NewForm(groups...).
+		WithInput(promptInput).
+		WithOutput(promptOutput).
+		WithAccessible(os.Getenv("ACCESSIBLE") != "")
+
+	if err := form.RunWithContext(cmd.Context()); err != nil {
+		if errors.Is(err, huh.ErrUserAborted) {
+			exit(130)
+		}
+		return fmt.Errorf("failed to prompt for arguments: %w", err)
+	}
+	return nil
+}
+
+func validateLocalTarget(value string) error {
+	if value = strings.TrimSpace(value); value == "" {
+		return nil
+	}
+	if source.IsGitURL(value) {
+		return errors.New("this is a git URL; choose Git repository as source")
+	}
+	if _, err := os.Stat(value); err != nil {
+		return fmt.Errorf("cannot read %s", value)
+	}
+	return nil
+}
+
+func validateGitTarget(value string) error {
+	if !source.IsGitURL(strings.TrimSpace(value)) {
+		return errors.New("expected an https://, ssh://, git:// or git@ URL")
+	}
+	return nil
+}
+
+func flagChanged(cmd *cobra.Command, name string) bool {
+	flag := cmd.Flags().Lookup(name)
+	return flag != nil && flag.Changed
+}
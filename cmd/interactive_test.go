@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"context"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/spf13/cobra"
+)
+
+func TestPromptTarget(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		kind    string
+		flags   map[string]string
+		answers []string
+		// expected answers stored in the flags of the local or git command
+		expectedKind   string
+		expectedTarget string
+		expectedRef    string
+		expectedSubDir string
+		expectedFormat string
+	}{
+		{
+			name:           "local defaults",
+			answers:        []string{"", "", "", ""},
+			expectedKind:   "local",
+			expectedTarget: ".",
+			expectedFormat: "json",
+		},
+		{
+			name:           "local with every answer",
+			answers:        []string{"1", dir, "modules/vpc", "2"},
+			expectedKind:   "local",
+			expectedTarget: dir,
+			expectedSubDir: "modules/vpc",
+			expectedFormat: "markdown",
+		},
+		{
+			name:           "git URL rejected as a local path",
+			kind:           "local",
+			answers:        []string{"https://github.com/owner/repo", dir, "", ""},
+			expectedKind:   "local",
+			expectedTarget: dir,
+			expectedFormat: "json",
+		},
+		{
+			name:           "git source chosen",
+			answers:        []string{"2", " https://github.com/owner/repo ", "v1.2.0", "stacks/prod", "1"},
+			expectedKind:   "git",
+			expectedTarget: "https://github.com/owner/repo",
+			expectedRef:    "v1.2.0",
+			expectedSubDir: "stacks/prod",
+			expectedFormat: "json",
+		},
+		{
+			name:           "invalid repository URL asked again",
+			kind:           "git",
+			answers:        []string{"owner/repo", "git@github.com:owner/repo.git", "", "", ""},
+			expectedKind:   "git",
+			expectedTarget: "git@github.com:owner/repo.git",
+			expectedFormat: "json",
+		},
+		{
+			name:           "flags are not asked",
+			kind:           "git",
+			flags:          map[string]string{"ref": "main", "subdir": "live", "format": "markdown"},
+			answers:        []string{"https://github.com/owner/repo"},
+			expectedKind:   "git",
+			expectedTarget: "https://github.com/owner/repo",
+			expectedRef:    "main",
+			expectedSubDir: "live",
+			expectedFormat: "markdown",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("ACCESSIBLE", "1")
+			t.Cleanup(resetPromptFlags)
+			// Accessible mode reads each answer with a new scanner, which must not read ahead
+			promptInput = iotest.OneByteReader(strings.NewReader(strings.Join(tt.answers, "\n") + "\n"))
+			promptOutput = io.Discard
+
+			cmd := &cobra.Command{}
+			cmd.SetContext(context.Background())
+			cmd.Flags().StringVar(&gitRef, "ref", "", "")
+			cmd.Flags().StringVar(&gitSubDir, "subdir", "", "")
+			cmd.Flags().StringVar(&summaryFormat, "format", "json", "")
+			for name, value := range tt.flags {
+				if err := cmd.Flags().Set(name, value); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			kind, target, err := promptTarget(cmd, tt.kind)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			subDir := localSubDir
+			if kind == "git" {
+				subDir = gitSubDir
+			}
+			if kind != tt.expectedKind || target != tt.expectedTarget {
+				t.Errorf("Expected %s %q, got %s %q", tt.expectedKind, tt.expectedTarget, kind, target)
+			}
+			if gitRef != tt.expectedRef || subDir != tt.expectedSubDir || summaryFormat != tt.expectedFormat {
+				t.Errorf("Expected ref %q, subdir %q and format %s, got %q, %q and %s", tt.expectedRef, tt.expectedSubDir, tt.expectedFormat, gitRef, subDir, summaryFormat)
+			}
+		})
+	}
+}
+
+func TestArgsOrPrompt(t *testing.T) {
+	noInput = true
+	t.Cleanup(func() { noInput = false })
+
+	validate := argsOrPrompt(cobra.ExactArgs(1))
+	for _, args := range [][]string{nil, {"a", "b"}} {
+		if err := validate(&cobra.Command{}, args); err == nil {
+			t.Errorf("Expected %q to be rejected without prompts", args)
+		}
+	}
+	if err := validate(&cobra.Command{}, []string{"."}); err != nil {
+		t.Errorf("Expected a single argument to be accepted, got %v", err)
+	}
+}
+
+// resetPromptFlags restores the prompt IO and the flags the prompts store answers in
+func resetPromptFlags() {
+	promptInput, promptOutput = os.Stdin, os.Stderr
+	gitRef, gitSubDir, localSubDir, summaryFormat = "", "", "", "json"
+}
@@ -0,0 +1,267 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"slices"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/compat"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/config"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/lint"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/logger"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/notify"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/source"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	lintRef           string
+	lintSubDir        string
+	lintUseManifest   bool
+	lintFormat        string
+	lintNamingProfile string
+	lintBaseline      string
+	lintWriteBaseline string
+	lintSeverities    map[string]string
+)
+
+var lintCmd = &cobra.Command{
+	Use:   "lint <path|url>",
+	Short: "Check Terraform configurations against lint and policy rules",
+	Long: `Check Terraform configurations against the built-in lint and policy rules.
+The target is treated as a Git repository when it is a URL and as a local directory otherwise.
+
+Policy rules include:
+- module-git-pinned: git module sources must use a tag or commit, not a branch
+- module-registry-version: registry modules must use exact or ~> version constraints
+- provider-version-upper-bound: required providers must have an upper version bound
+
+Lint rules include:
+- provider-undeclared: providers used by resources must be declared in required_providers
+- provider-unused: providers in required_providers should be used by a resource
+- provider-lock-drift: required providers must be locked with the constraints of required_providers
+- output-sensitive-exposure: outputs depending on sensitive values must set sensitive = true
+- required-version-too-loose: required_version must not allow versions older than the features used
+- description-min-length, description-capitalized, description-trailing-period,
+  description-denylist: description quality checks, off unless a severity is configured
+
+Rule severities and exemptions are configured in the config file:
+
+  rules:
+    provider-version-upper-bound:
+      severity: error
+    module-git-pinned:
+      exemptions: ["module.legacy_*"]
+
+--severity rule-id=severity overrides them for a run.
+
+A comment "# tfparser:ignore <rule-id> <reason>" on the line above a block, or at the end of its
+first line, suppresses the findings of the rule for that block. To adopt lint on an existing
+codebase, record the current findings with --write-baseline and pass the file with --baseline:
+only findings missing from the baseline count. Suppressed findings are listed apart.`,
+	Example: `  # Lint local directory
+  terraform-config-parser lint ./terraform
+
+  # Lint a tag of a Git repository
+  terraform-config-parser lint https://github.com/owner/repo --ref v1.0.0 --subdir modules/vpc
+
+  # Write JUnit XML for the CI test report
+  terraform-config-parser lint . --format junit > lint-report.xml
+
+  # Comment on a pull request through reviewdog
+  terraform-config-parser lint . --format rdjson | reviewdog -f=rdjson -reporter=github-pr-review
+
+  # Record the existing findings, then fail only on new ones
+  terraform-config-parser lint . --write-baseline .tfparser-baseline.json
+  terraform-config-parser lint . --baseline .tfparser-baseline.json
+
+  # Use a specific config file
+  terraform-config-parser lint . --config policy.yaml`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		target := args[0]
+
+		logger.InfoKV("Linting terraform configuration", "target", target, "ref", lintRef, "subdir", lintSubDir)
+
+		src := source.New(target, source.SourceConfig{
+			Ref:    lintRef,
+			SubDir: lintSubDir,
+		})
+
+		report, err := lintSource(src, target)
+		if err != nil {
+			logger.ErrorKV("Failed to lint source", "target", target, "error", err)
+			log.Fatal(err)
+		}
+
+		// Writing a baseline accepts the current findings
+		if report.Errors > 0 && lintWriteBaseline == "" {
+			if err := flushOutput(); err != nil {
+				log.Fatal(err)
+			}
+			log.Fatalf("lint failed with %d error(s)", report.Errors)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(lintCmd)
+
+	lintCmd.Flags().StringVarP(&lintRef, "ref", "r", "", "Git reference to use when the target is a Git repository")
+	lintCmd.Flags().StringVar(&lintSubDir, "subdir", "", "Subdirectory within the target")
+	lintCmd.Flags().StringVar(&lintFormat, "format", "json", "Output format (json, junit, checkstyle, rdjson, slack, teams)")
+	lintCmd.Flags().StringVar(&lintNamingProfile, "naming-profile", "", "Naming profile to check, overriding naming.profile of the config file (snake_case is built in)")
+	lintCmd.Flags().StringVar(&lintBaseline, "baseline", "", "Suppress the findings recorded in this baseline file")
+	lintCmd.Flags().StringVar(&lintWriteBaseline, "write-baseline", "", "Record the findings in this baseline file")
+	lintCmd.Flags().StringToStringVar(&lintSeverities, "severity", nil, "Override rule severities, e.g. provider-unused=off (repeatable)")
+	lintCmd.Flags().BoolVar(&lintUseManifest, "use-manifest", false, "Also check child modules installed by terraform init (.terraform/modules/modules.json)")
+}
+
+func lintSource(src source.Source, target string) (*lint.Report, error) {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	ws, err := loadLintWorkspace(src)
+	if err != nil {
+		return nil, err
+	}
+
+	if lintNamingProfile != "" {
+		if cfg.Naming == nil {
+			cfg.Naming = &config.NamingPolicy{}
+		}
+		cfg.Naming.Profile = lintNamingProfile
+	}
+
+	if err := overrideSeverities(cfg, lintSeverities); err != nil {
+		return nil, err
+	}
+
+	linter := lint.NewLinter(cfg)
+	if lintBaseline != "" && lintWriteBaseline == "" {
+		baseline, err := lint.LoadBaseline(lintBaseline)
+		if err != nil {
+			return nil, err
+		}
+		linter.WithBaseline(baseline)
+	}
+
+	report, err := linter.Run(ws)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run lint rules: %w", err)
+	}
+
+	if lintWriteBaseline != "" {
+		if err := lint.NewBaseline(report).Write(lintWriteBaseline); err != nil {
+			return nil, err
+		}
+		logger.InfoKV("Wrote lint baseline", "path", lintWriteBaseline, "findings", len(report.Findings))
+	}
+
+	if err := printLintReport(report, target); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+func printLintReport(report *lint.Report, target string) error {
+	var content []byte
+	var err error
+
+	switch lintFormat {
+	case "junit":
+		content, err = report.JUnit("terraform-config-parser lint " + target)
+	case "checkstyle":
+		content, err = report.Checkstyle()
+	case "rdjson":
+		return printJSON(report.RDJSON())
+	default:
+		if err := printReport(report, notify.LintMessage("Lint "+target, report), lintFormat); err != nil {
+			return fmt.Errorf("failed to encode lint report: %w", err)
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprint(stdout, string(content))
+	return nil
+}
+
+// loadLintWorkspace parses the workspace in detail mode together with its local child modules,
+// or with every installed child module when --use-manifest is set
+func loadLintWorkspace(src source.Source) (*lint.Workspace, error) {
+	fs, rootPath, err := fetchSource(src, false)
+	if err != nil {
+		return nil, err
+	}
+	defer src.Cleanup()
+
+	p := parser.NewParser(fs, parser.Detail).WithContext(runCtx)
+	tfconfig, err := p.ParseTerraformWorkspace(rootPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Terraform workspace: %w", err)
+	}
+
+	locations, err := p.LocateBlocks(rootPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate blocks: %w", err)
+	}
+	files, err := p.Files(rootPath)
+	if err != nil {
+		return nil, err
+	}
+	ws := &lint.Workspace{Config: tfconfig, Dir: rootPath, Locations: locations, Compat: compat.Analyze(files, tfconfig), Suppressions: lint.ParseSuppressions(files)}
+
+	if lintUseManifest {
+		manifest, err := p.LoadModuleManifest(rootPath)
+		if err != nil {
+			return nil, err
+		}
+		tree, err := p.ResolveModuleTree(rootPath, tfconfig, manifest)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve modules: %w", err)
+		}
+
+		ws.Modules = map[string]*parser.TerraformConfig{}
+		for _, module := range tree {
+			if module.Config != nil {
+				ws.Modules[module.Key] = module.Config
+			}
+		}
+		return ws, nil
+	}
+
+	if ws.Modules, err = p.ParseLocalModules(rootPath, tfconfig); err != nil {
+		return nil, fmt.Errorf("failed to parse local modules: %w", err)
+	}
+
+	return ws, nil
+}
+
+// overrideSeverities applies the --severity overrides on top of the rules of the config file
+func overrideSeverities(cfg *config.Config, severities map[string]string) error {
+	for ruleID, severity := range severities {
+		if !slices.ContainsFunc(lint.Rules(), func(rule *lint.Rule) bool { return rule.ID == ruleID }) {
+			return fmt.Errorf("unknown lint rule %q", ruleID)
+		}
+		if _, err := lint.ParseSeverity(severity); err != nil {
+			return fmt.Errorf("invalid severity for rule %s: %w", ruleID, err)
+		}
+
+		if cfg.Rules == nil {
+			cfg.Rules = map[string]*config.RuleConfig{}
+		}
+		if cfg.Rules[ruleID] == nil {
+			cfg.Rules[ruleID] = &config.RuleConfig{}
+		}
+		cfg.Rules[ruleID].Severity = severity
+	}
+	return nil
+}
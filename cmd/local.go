@@ -1,25 +1,62 @@
 package cmd
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/doctemplate"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/events"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/filesystem"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/localize"
 	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/logger"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/output"
 	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/progress"
 	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/source"
 
 	"github.com/spf13/cobra"
 )
 
 var (
-	localSubDir string
+	localSubDir    string
+	localLang      string
+	localWithAST   bool
+	localRecursive bool
+	localStdinName string
+	// astDeferSize, summaryFormat, summaryDetail, templateFile, outputDir, ndjson, flattenValues,
+	// withKinds, withPositions, validateBlocks, lenient, maxDepth and prunePaths are shared by the
+	// local and git commands
+	astDeferSize   int
+	summaryFormat  string
+	summaryDetail  bool
+	templateFile   string
+	outputDir      string
+	ndjson         bool
+	flattenValues  bool
+	withKinds      bool
+	withPositions  bool
+	validateBlocks bool
+	lenient        bool
+	maxDepth       int
+	prunePaths     []string
 )
 
 var localCmd = &cobra.Command{
 	Use:   "local <path>",
 	Short: "Parse Terraform configurations from local filesystem",
 	Long: `Parse Terraform configurations from a local directory.
-You can specify a subdirectory within the target path.`,
+You can specify a subdirectory within the target path.
+
+With "-" as path, a single configuration file is read from standard input. A path ending
+with .tfbundle is read as a bundle written by the bundle command.`,
 	Example: `  # Parse current directory
   terraform-config-parser local .
   
@@ -27,18 +64,78 @@ You can specify a subdirectory within the target path.`,
   terraform-config-parser local /path/to/terraform
   
   # Parse subdirectory
-  terraform-config-parser local ./terraform --subdir modules/vpc`,
-	Args: cobra.ExactArgs(1),
+  terraform-config-parser local ./terraform --subdir modules/vpc
+
+  # Use Korean descriptions from descriptions.ko.yaml
+  terraform-config-parser local ./terraform --lang ko
+
+  # Include the expression AST of every attribute
+  terraform-config-parser local ./terraform --with-ast
+
+  # Leave the AST of expressions over 64 KiB out, with their location only
+  terraform-config-parser local ./terraform --with-ast --ast-defer-size 65536
+
+  # Tell literal attribute values from references, function calls and conditionals
+  terraform-config-parser local ./terraform --with-kinds
+
+  # Add the file and lines of every block, e.g. to annotate pull requests
+  terraform-config-parser local ./terraform --with-positions
+
+  # Report typos such as "sensative = true" and misplaced blocks as diagnostics
+  terraform-config-parser local ./terraform --validate-blocks
+
+  # Parse what can be read from broken files and list their problems as diagnostics
+  terraform-config-parser local ./terraform --lenient
+
+  # Add dotted key paths of object defaults and backend settings (tags.Name = "test")
+  terraform-config-parser local ./terraform --flatten
+
+  # Parse every root and module of a repository, keyed by path
+  terraform-config-parser local . --recursive
+
+  # Document the module's inputs and outputs in its README
+  terraform-config-parser local ./modules/vpc --format markdown > ./modules/vpc/README.md
+
+  # Stream one JSON line per directory into jq
+  terraform-config-parser local . --recursive --ndjson | jq -c 'select(.config.variables)'
+
+  # Write the README of every module next to the others, under docs/<path>/summary.md
+  terraform-config-parser local . --recursive --format markdown --output-dir docs
+
+  # Generate documentation from a Go template
+  terraform-config-parser local ./modules/vpc --format template --template-file doc.tmpl
+
+  # Parse a file from standard input
+  cat main.tf.json | terraform-config-parser local - --stdin-filename main.tf.json`,
+	Args: argsOrPrompt(cobra.ExactArgs(1)),
 	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			_, target, err := promptTarget(cmd, "local")
+			if err != nil {
+				log.Fatal(err)
+			}
+			args = []string{target}
+		}
 		path := args[0]
 
+		if path == "-" {
+			if err := parseStdinAndOutput(localStdinName, localWithAST); err != nil {
+				logger.ErrorKV("Failed to parse standard input", "filename", localStdinName, "error", err)
+				log.Fatal(err)
+			}
+			return
+		}
+
 		logger.InfoKV("Processing local directory", "path", path, "subdir", localSubDir)
 
-		src := source.NewLocalSource(path, source.SourceConfig{
+		var src source.Source = source.NewLocalSource(path, source.SourceConfig{
 			SubDir: localSubDir,
 		})
+		if source.IsBundle(path) {
+			src = source.NewBundleSource(path, source.SourceConfig{SubDir: localSubDir})
+		}
 
-		if err := parseAndOutput(src); err != nil {
+		if err := parseAndOutput(src, localLang, localWithAST, localRecursive); err != nil {
 			logger.ErrorKV("Failed to parse and output local source", "path", path, "subdir", localSubDir, "error", err)
 			log.Fatal(err)
 		}
@@ -49,33 +146,308 @@ func init() {
 	rootCmd.AddCommand(localCmd)
 
 	localCmd.Flags().StringVar(&localSubDir, "subdir", "", "Subdirectory within the target path")
+	localCmd.Flags().StringVar(&localLang, "lang", "", "Replace descriptions with translations from descriptions.<lang>.yaml")
+	localCmd.Flags().BoolVar(&localWithAST, "with-ast", false, "Include the expression AST of every attribute")
+	localCmd.Flags().BoolVar(&localRecursive, "recursive", false, "Parse every directory with .tf files and print the configurations keyed by path")
+	localCmd.Flags().IntVar(&maxDepth, "max-depth", 0, "With --recursive, parse directories at most this many levels below the target (0: no limit)")
+	localCmd.Flags().StringSliceVar(&prunePaths, "prune", nil, "With --recursive, skip directories matching this glob of the path relative to the target, or of the directory name when it has no slash (repeatable)")
+	localCmd.Flags().IntVar(&astDeferSize, "ast-defer-size", 0, "With --with-ast, replace the AST of expressions longer than this many bytes with their location (0 keeps every AST)")
+	localCmd.Flags().BoolVar(&validateBlocks, "validate-blocks", false, "Check blocks against Terraform's block schemas and report unknown arguments and misplaced blocks as diagnostics")
+	localCmd.Flags().BoolVar(&lenient, "lenient", false, "Report syntax errors and blocks that fail to parse as diagnostics and keep the blocks that can be read, instead of failing")
+	localCmd.Flags().BoolVar(&withKinds, "with-kinds", false, "Tag the attributes of every block with the kind of their value (literal, reference, function, conditional, complex)")
+	localCmd.Flags().BoolVar(&withPositions, "with-positions", false, "Add the file, start_line and end_line of every block")
+	localCmd.Flags().BoolVar(&flattenValues, "flatten", false, "Add flat_default and flat_config fields mapping dotted key paths of nested values to their leaves")
+	localCmd.Flags().BoolVar(&summaryDetail, "detail", false, "Include module calls, resources, data sources, providers, imports and locals in JSON output")
+	localCmd.Flags().StringVar(&summaryFormat, "format", "json", "Output format (json, markdown, template)")
+	localCmd.Flags().StringVar(&templateFile, "template-file", "", "Go text/template file rendering the configuration, with --format template")
+	localCmd.Flags().StringVar(&outputDir, "output-dir", "", "With --recursive, write the result of each directory to <dir>/<path>/summary.<ext> instead")
+	localCmd.Flags().BoolVar(&ndjson, "ndjson", false, "With --recursive, write one compact JSON line per directory: {\"path\": ..., \"config\": ...}")
+	localCmd.Flags().StringVar(&localStdinName, "stdin-filename", "stdin.tf", "File name of the configuration read from standard input; .tf.json selects the JSON syntax")
+}
+
+// parseAndOutput prints the configuration of src or, when recursive, the configurations of
+// every directory under it keyed by path
+func parseAndOutput(src source.Source, lang string, withAST, recursive bool) error {
+	logger.InfoKV("Starting terraform configuration parsing", "recursive", recursive)
+
+	var result any
+	var err error
+	if recursive {
+		result, err = loadLocalizedWorkspaces(src, summaryMode(), lang, withAST)
+	} else {
+		result, err = loadLocalizedWorkspace(src, summaryMode(), lang, withAST)
+	}
+	if err != nil {
+		return err
+	}
+
+	logger.DebugKV("Generating terraform configuration summary", "format", summaryFormat)
+	if err := printSummary(result); err != nil {
+		return fmt.Errorf("failed to generate summary: %w", err)
+	}
+
+	logger.InfoKV("Successfully completed terraform configuration parsing")
+	return nil
+}
+
+// parseStdinAndOutput prints the configuration of a single file read from standard input
+func parseStdinAndOutput(filename string, withAST bool) error {
+	content, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return fmt.Errorf("failed to read standard input: %w", err)
+	}
+
+	tfconfig, err := parser.NewParser(nil, summaryMode()).WithAST(withAST).WithLazyThreshold(astDeferSize).WithFlatten(flattenValues).WithKinds(withKinds).WithPositions(withPositions).WithValidateBlocks(validateBlocks).WithLenient(lenient).ParseHCLBytes(filename, content)
+	if err != nil {
+		return err
+	}
+
+	if err := printSummary(tfconfig); err != nil {
+		return fmt.Errorf("failed to generate summary: %w", err)
+	}
+	return nil
+}
+
+// summaryMode is the parsing mode of summaryFormat; markdown, templates and --detail get modules,
+// resources and provider configurations too
+func summaryMode() parser.Mode {
+	if summaryDetail || summaryFormat == "markdown" || summaryFormat == "template" {
+		return parser.Detail
+	}
+	return parser.Simple
+}
+
+// printSummary prints a configuration, or configurations keyed by path, in summaryFormat. With
+// --output-dir, the configurations keyed by path are written to a file per directory instead.
+func printSummary(result any) error {
+	if ndjson && summaryFormat != "json" {
+		return fmt.Errorf("--ndjson requires --format json")
+	}
+	if outputDir == "" {
+		return writeSummary(stdout, result)
+	}
+
+	configs, ok := result.(map[string]*parser.TerraformConfig)
+	if !ok {
+		return fmt.Errorf("--output-dir requires --recursive")
+	}
+	name, err := summaryFileName()
+	if err != nil {
+		return err
+	}
+	for _, path := range slices.Sorted(maps.Keys(configs)) {
+		var buf bytes.Buffer
+		if err := writeSummary(&buf, configs[path]); err != nil {
+			return err
+		}
+		target := filepath.Join(outputDir, filepath.FromSlash(path), name)
+		if err := output.WriteFile(target, buf.Bytes()); err != nil {
+			return err
+		}
+		logger.InfoKV("Wrote configuration summary", "path", path, "file", target)
+	}
+	return nil
+}
+
+// pruning is the pruning selected with --max-depth and --prune, or nil
+func pruning() *source.Pruning {
+	if maxDepth <= 0 && len(prunePaths) == 0 {
+		return nil
+	}
+	return &source.Pruning{MaxDepth: maxDepth, Paths: prunePaths}
 }
 
-func parseAndOutput(src source.Source) error {
-	logger.InfoKV("Starting terraform configuration parsing")
+// summaryFileName is the name of the files written to --output-dir: summary.json, summary.md, or
+// the template file name without its .tmpl or .gotmpl extension
+func summaryFileName() (string, error) {
+	switch summaryFormat {
+	case "json":
+		return "summary.json", nil
+	case "markdown":
+		return "summary.md", nil
+	case "template":
+		name := filepath.Base(templateFile)
+		if trimmed := strings.TrimSuffix(strings.TrimSuffix(name, ".tmpl"), ".gotmpl"); trimmed != name {
+			return trimmed, nil
+		}
+		return "summary.txt", nil
+	default:
+		return "", fmt.Errorf("unknown format %q (expected json, markdown or template)", summaryFormat)
+	}
+}
 
-	logger.DebugKV("Fetching source")
-	fs, rootPath, err := src.Fetch()
+// writeSummary writes a configuration, or configurations keyed by path, to w in summaryFormat.
+// Markdown renders the tables of each configuration under a heading with its path, while
+// templates receive the configuration or the map of configurations as they are.
+func writeSummary(w io.Writer, result any) error {
+	switch summaryFormat {
+	case "json":
+		if ndjson {
+			return writeNDJSON(w, result)
+		}
+		return writeJSON(w, result)
+	case "markdown":
+		switch v := result.(type) {
+		case *parser.TerraformConfig:
+			fmt.Fprint(w, v.Markdown())
+		case map[string]*parser.TerraformConfig:
+			paths := slices.Sorted(maps.Keys(v))
+			for i, path := range paths {
+				if i > 0 {
+					fmt.Fprintln(w)
+				}
+				fmt.Fprintf(w, "# %s\n\n%s", path, v[path].Markdown())
+			}
+		}
+		return nil
+	case "template":
+		if templateFile == "" {
+			return fmt.Errorf("--format template requires --template-file")
+		}
+		text, err := os.ReadFile(templateFile)
+		if err != nil {
+			return fmt.Errorf("failed to read template: %w", err)
+		}
+		return doctemplate.Render(w, filepath.Base(templateFile), string(text), result)
+	default:
+		return fmt.Errorf("unknown format %q (expected json, markdown or template)", summaryFormat)
+	}
+}
+
+// ndjsonLine is a line of --ndjson output
+type ndjsonLine struct {
+	Path   string                  `json:"path"`
+	Config *parser.TerraformConfig `json:"config"`
+}
+
+// writeNDJSON writes configurations keyed by path as newline-delimited JSON, one directory per
+// line in path order, or a single configuration as one compact line
+func writeNDJSON(w io.Writer, result any) error {
+	configs, ok := result.(map[string]*parser.TerraformConfig)
+	if !ok {
+		return encodeJSON(w, result, false)
+	}
+	for _, path := range slices.Sorted(maps.Keys(configs)) {
+		if err := encodeJSON(w, ndjsonLine{Path: path, Config: configs[path]}, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func loadWorkspace(src source.Source, mode parser.Mode) (*parser.TerraformConfig, error) {
+	return loadLocalizedWorkspace(src, mode, "", false)
+}
+
+// loadLocalizedWorkspace parses the workspace and, unless lang is empty, replaces descriptions
+// with the translations of the workspace's descriptions.<lang>.yaml. withAST attaches expression ASTs to the blocks.
+func loadLocalizedWorkspace(src source.Source, mode parser.Mode, lang string, withAST bool) (*parser.TerraformConfig, error) {
+	fs, rootPath, err := fetchSource(src, false)
 	if err != nil {
-		return fmt.Errorf("failed to fetch source: %w", err)
+		return nil, err
 	}
-	logger.DebugKV("Successfully fetched source", "root_path", rootPath)
 	defer src.Cleanup()
 
 	logger.DebugKV("Creating parser and parsing terraform workspace")
-	p := parser.NewParser(fs, parser.Simple)
+	p := parser.NewParser(fs, mode).WithContext(runCtx).WithAST(withAST).WithLazyThreshold(astDeferSize).WithFlatten(flattenValues).WithKinds(withKinds).WithPositions(withPositions).WithValidateBlocks(validateBlocks).WithLenient(lenient)
 	tfconfig, err := p.ParseTerraformWorkspace(rootPath)
 	if err != nil {
-		return fmt.Errorf("failed to parse Terraform workspace: %w", err)
+		return nil, fmt.Errorf("failed to parse Terraform workspace: %w", err)
 	}
 
-	logger.DebugKV("Generating terraform configuration summary")
-	summary, err := tfconfig.Summary(true)
+	if err := localizeWorkspace(fs, rootPath, lang, tfconfig); err != nil {
+		return nil, err
+	}
+
+	return tfconfig, nil
+}
+
+// loadLocalizedWorkspaces parses every configuration directory of src like loadLocalizedWorkspace,
+// with the translations of each directory
+func loadLocalizedWorkspaces(src source.Source, mode parser.Mode, lang string, withAST bool) (map[string]*parser.TerraformConfig, error) {
+	fs, rootPath, err := fetchSource(src, true)
 	if err != nil {
-		return fmt.Errorf("failed to generate summary: %w", err)
+		return nil, err
 	}
+	defer src.Cleanup()
 
-	logger.InfoKV("Successfully completed terraform configuration parsing")
-	fmt.Println(string(summary))
+	tracker, err := newFileTracker(fs, rootPath)
+	if err != nil {
+		return nil, err
+	}
+	reporter := func(e events.Event) {
+		if e.Kind == events.FileParsed {
+			tracker.Increment(filepath.Base(e.File))
+		}
+	}
+
+	logger.DebugKV("Creating parser and parsing terraform workspaces")
+	workspaces, err := parser.NewParser(fs, mode).WithContext(runCtx).WithAST(withAST).WithLazyThreshold(astDeferSize).WithFlatten(flattenValues).WithKinds(withKinds).WithPositions(withPositions).WithValidateBlocks(validateBlocks).WithLenient(lenient).WithPruning(pruning()).WithReporter(reporter).ParseTerraformWorkspaces(rootPath)
+	tracker.Finish()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Terraform workspaces: %w", err)
+	}
+
+	// Directories without translations keep their descriptions, as long as one directory has them
+	translated := 0
+	for path, tfconfig := range workspaces {
+		err := localizeWorkspace(fs, filepath.Join(rootPath, path), lang, tfconfig)
+		if errors.Is(err, localize.ErrNoTranslations) {
+			logger.DebugKV("Descriptions without translation", "lang", lang, "path", path)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		translated++
+	}
+	if lang != "" && translated == 0 && len(workspaces) > 0 {
+		return nil, fmt.Errorf("%w for language %q: no %s found under %s", localize.ErrNoTranslations, lang, localize.FileName(lang), rootPath)
+	}
+
+	return workspaces, nil
+}
+
+// newFileTracker creates a progress tracker counting the configuration files parsed under root,
+// with the directories that --max-depth and --prune leave out not counted
+func newFileTracker(fs filesystem.FileReader, root string) (*progress.Tracker, error) {
+	tracker, err := newTracker("files parsed")
+	if tracker == nil || err != nil {
+		return tracker, err
+	}
+
+	dirs, err := source.PrunedConfigDirs(fs, root, pruning())
+	if err != nil {
+		return nil, err
+	}
+	total := 0
+	for _, dir := range dirs {
+		entries, err := fs.ReadDir(dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read directory %s: %w", dir, err)
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() && source.IsConfigFile(entry.Name()) {
+				total++
+			}
+		}
+	}
+	tracker.SetTotal(total)
+	return tracker, nil
+}
+
+// localizeWorkspace replaces the descriptions of tfconfig with the translations of dir, unless lang is empty
+func localizeWorkspace(fs filesystem.FileReader, dir, lang string, tfconfig *parser.TerraformConfig) error {
+	if lang == "" {
+		return nil
+	}
+
+	translations, err := localize.Load(fs, dir, lang)
+	if err != nil {
+		return err
+	}
+	if untranslated := localize.Apply(tfconfig, translations); len(untranslated) > 0 {
+		logger.InfoKV("Descriptions without translation", "lang", lang, "file", filepath.Join(dir, localize.FileName(lang)), "addresses", untranslated)
+	}
 	return nil
 }
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/compat"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/logger"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/source"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	minVersionRef    string
+	minVersionSubDir string
+)
+
+var minVersionCmd = &cobra.Command{
+	Use:   "min-version <path|url>",
+	Short: "Infer the minimum Terraform version a workspace needs",
+	Long: `Infer the minimum Terraform version from the language features the configuration uses,
+such as moved, check and removed blocks, optional object attributes, newer functions,
+ephemeral resources and write-only arguments, and compare it with required_version.
+The target is treated as a Git repository when it is a URL and as a local directory otherwise.
+
+too_loose is true when required_version allows versions older than the inferred minimum.`,
+	Example: `  # Infer the minimum version of a module
+  terraform-config-parser min-version ./modules/vpc`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		target := args[0]
+
+		logger.InfoKV("Inferring minimum Terraform version", "target", target, "ref", minVersionRef, "subdir", minVersionSubDir)
+
+		src := source.New(target, source.SourceConfig{
+			Ref:    minVersionRef,
+			SubDir: minVersionSubDir,
+		})
+
+		report, err := analyzeCompat(src)
+		if err != nil {
+			logger.ErrorKV("Failed to infer minimum Terraform version", "target", target, "error", err)
+			log.Fatal(err)
+		}
+
+		if err := printJSON(report); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(minVersionCmd)
+
+	minVersionCmd.Flags().StringVarP(&minVersionRef, "ref", "r", "", "Git reference to use when the target is a Git repository")
+	minVersionCmd.Flags().StringVar(&minVersionSubDir, "subdir", "", "Subdirectory within the target")
+}
+
+func analyzeCompat(src source.Source) (*compat.Report, error) {
+	fs, rootPath, err := fetchSource(src, false)
+	if err != nil {
+		return nil, err
+	}
+	defer src.Cleanup()
+
+	p := parser.NewParser(fs, parser.Simple).WithContext(runCtx)
+	tfconfig, err := p.ParseTerraformWorkspace(rootPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Terraform workspace: %w", err)
+	}
+
+	files, err := p.Files(rootPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return compat.Analyze(files, tfconfig), nil
+}
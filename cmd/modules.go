@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/logger"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/source"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	modulesRef         string
+	modulesSubDir      string
+	modulesUseManifest bool
+	modulesPassThrough bool
+	modulesMaxDepth    int
+	modulesPrune       []string
+)
+
+var modulesCmd = &cobra.Command{
+	Use:   "modules <path|url>",
+	Short: "Parse the tree of module calls",
+	Long: `Parse the workspace and every module it calls, recursively, and print the module tree.
+The target is treated as a Git repository when it is a URL and as a local directory otherwise.
+
+Local module sources are always followed. With --use-manifest, calls are first resolved through
+.terraform/modules/modules.json written by terraform init, so registry and git modules that are
+already downloaded are parsed too, without network access. Calls that cannot be resolved are
+listed without dir and config.
+
+--max-depth and --prune keep large trees small: calls deeper than --max-depth, or whose key (with
+dots as slashes, e.g. network/subnets) or directory matches a --prune glob, are listed with their
+dir but not parsed, and "skipped" tells why. A call leading back to the directory of an enclosing
+module is listed as skipped "cycle" with the key of that module in "cycle_of", instead of being
+followed forever.
+
+With --pass-through, the interface map of the tree is printed instead: the root variables
+passed unchanged to child module inputs, at any depth, and the root outputs that re-export
+a child module output unchanged, traced to the module that produces the value.`,
+	Example: `  # Module tree of local modules
+  terraform-config-parser modules ./terraform
+
+  # Fully resolved tree of an initialized workspace
+  terraform -chdir=./terraform init
+  terraform-config-parser modules ./terraform --use-manifest
+
+  # Explore a large tree one level at a time
+  terraform-config-parser modules ./terraform --use-manifest --max-depth 1
+
+  # End-to-end interface of a nested module stack
+  terraform-config-parser modules ./stack --pass-through`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		target := args[0]
+
+		logger.InfoKV("Resolving module tree", "target", target, "ref", modulesRef, "subdir", modulesSubDir, "use_manifest", modulesUseManifest)
+
+		src := source.New(target, source.SourceConfig{
+			Ref:    modulesRef,
+			SubDir: modulesSubDir,
+		})
+
+		result, err := resolveModuleTree(src, modulesUseManifest, modulesPassThrough)
+		if err != nil {
+			logger.ErrorKV("Failed to resolve module tree", "target", target, "error", err)
+			log.Fatal(err)
+		}
+
+		if err := printJSON(result); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(modulesCmd)
+
+	modulesCmd.Flags().StringVarP(&modulesRef, "ref", "r", "", "Git reference to use when the target is a Git repository")
+	modulesCmd.Flags().StringVar(&modulesSubDir, "subdir", "", "Subdirectory within the target")
+	modulesCmd.Flags().BoolVar(&modulesUseManifest, "use-manifest", false, "Resolve module calls through .terraform/modules/modules.json")
+	modulesCmd.Flags().IntVar(&modulesMaxDepth, "max-depth", 0, "Parse module calls at most this many levels below the root module (0: no limit)")
+	modulesCmd.Flags().StringSliceVar(&modulesPrune, "prune", nil, "Do not parse module calls whose key, with dots as slashes, or directory matches this glob (repeatable)")
+	modulesCmd.Flags().BoolVar(&modulesPassThrough, "pass-through", false, "Print the variables and outputs passed through the module tree")
+}
+
+// resolveModuleTree returns the module tree, or its interface map when passThrough is set
+func resolveModuleTree(src source.Source, useManifest, passThrough bool) (any, error) {
+	fs, rootPath, err := fetchSource(src, false)
+	if err != nil {
+		return nil, err
+	}
+	defer src.Cleanup()
+
+	p := parser.NewParser(fs, parser.Detail).WithContext(runCtx)
+	if modulesMaxDepth > 0 || len(modulesPrune) > 0 {
+		p.WithPruning(&source.Pruning{MaxDepth: modulesMaxDepth, Paths: modulesPrune})
+	}
+	tfconfig, err := p.ParseTerraformWorkspace(rootPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Terraform workspace: %w", err)
+	}
+
+	var manifest *parser.ModuleManifest
+	if useManifest {
+		if manifest, err = p.LoadModuleManifest(rootPath); err != nil {
+			return nil, err
+		}
+		if manifest == nil {
+			logger.InfoKV("No module manifest found, following local sources only", "path", parser.ModuleManifestPath)
+		}
+	}
+
+	tree, err := p.ResolveModuleTree(rootPath, tfconfig, manifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve modules: %w", err)
+	}
+
+	if passThrough {
+		m, err := p.TracePassThrough(rootPath, tree)
+		if err != nil {
+			return nil, fmt.Errorf("failed to trace pass-through values: %w", err)
+		}
+		return m, nil
+	}
+
+	return tree, nil
+}
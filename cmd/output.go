@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/output"
+)
+
+var (
+	jsonCasing       string
+	emptyCollections string
+	outputPath       string
+	compactJSON      bool
+	// stdout receives the results of commands: standard output, or with --output a buffer written
+	// to the file once the command completes
+	stdout io.Writer = os.Stdout
+)
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&jsonCasing, "json-casing", string(output.CasingSnake), "Casing of JSON field names (snake, camel)")
+	rootCmd.PersistentFlags().BoolVar(&compactJSON, "compact", false, "Write JSON output on a single line")
+	rootCmd.PersistentFlags().StringVarP(&outputPath, "output", "o", "-", "Write the result to this file, replaced atomically once complete (- for stdout)")
+	rootCmd.PersistentFlags().StringVar(&emptyCollections, "empty-collections", "", "Empty list and map fields in JSON output: omit or emit (default: as declared per field)")
+}
+
+// printJSON writes v to stdout as JSON without HTML escaping, indented unless --compact is set,
+// applying the output flags
+func printJSON(v any) error {
+	return writeJSON(stdout, v)
+}
+
+// writeJSON writes v to w like printJSON
+func writeJSON(w io.Writer, v any) error {
+	return encodeJSON(w, v, !compactJSON)
+}
+
+// encodeJSON writes v to w as JSON followed by a newline, applying the output flags
+func encodeJSON(w io.Writer, v any, pretty bool) error {
+	opts, err := output.ParseOptions(jsonCasing, emptyCollections)
+	if err != nil {
+		return err
+	}
+
+	content, err := output.Marshal(v, opts, pretty)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(w, string(content))
+	return err
+}
+
+// openOutput buffers the results of the command when --output names a file
+func openOutput() {
+	if outputPath != "" && outputPath != "-" {
+		stdout = &bytes.Buffer{}
+	}
+}
+
+// flushOutput writes the buffered results to the --output file. Commands exiting before they
+// return call it first, so that the results they printed are kept.
+func flushOutput() error {
+	buf, ok := stdout.(*bytes.Buffer)
+	if !ok {
+		return nil
+	}
+	stdout = os.Stdout
+	return output.WriteFile(outputPath, buf.Bytes())
+}
+
+// exit writes the results, then exits with code
+func exit(code int) {
+	if err := flushOutput(); err != nil {
+		log.Fatal(err)
+	}
+	finishTelemetry(nil, code)
+	os.Exit(code)
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser/schema"
+)
+
+func TestPrintSummaryJSONModes(t *testing.T) {
+	network := &parser.TerraformConfig{Variables: []*schema.Variable{{Name: "cidr", Required: true}}}
+	app := &parser.TerraformConfig{Annotations: map[string]string{"owner": "team-app"}}
+	recursive := map[string]*parser.TerraformConfig{"network": network, "app": app}
+
+	tests := []struct {
+		name     string
+		result   any
+		format   string
+		compact  bool
+		ndjson   bool
+		casing   string
+		expected string
+		err      bool
+	}{
+		{
+			name:     "indented by default",
+			result:   app,
+			format:   "json",
+			expected: "{\n  \"annotations\": {\n    \"owner\": \"team-app\"\n  }\n}\n",
+		},
+		{
+			name:     "compact",
+			result:   network,
+			format:   "json",
+			compact:  true,
+			expected: `{"variables":[{"name":"cidr","required":true,"sensitive":false}]}` + "\n",
+		},
+		{
+			name:     "compact keeps the casing",
+			result:   recursive,
+			format:   "json",
+			compact:  true,
+			casing:   "camel",
+			expected: `{"app":{"annotations":{"owner":"team-app"}},"network":{"variables":[{"name":"cidr","required":true,"sensitive":false}]}}` + "\n",
+		},
+		{
+			name:   "ndjson writes a line per path in path order",
+			result: recursive,
+			format: "json",
+			ndjson: true,
+			expected: `{"path":"app","config":{"annotations":{"owner":"team-app"}}}` + "\n" +
+				`{"path":"network","config":{"variables":[{"name":"cidr","required":true,"sensitive":false}]}}` + "\n",
+		},
+		{
+			name:     "ndjson writes a single configuration on one line",
+			result:   app,
+			format:   "json",
+			ndjson:   true,
+			expected: `{"annotations":{"owner":"team-app"}}` + "\n",
+		},
+		{
+			name:   "ndjson requires json",
+			result: recursive,
+			format: "markdown",
+			ndjson: true,
+			err:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			stdout, summaryFormat, compactJSON, ndjson, jsonCasing = &buf, tt.format, tt.compact, tt.ndjson, tt.casing
+			if jsonCasing == "" {
+				jsonCasing = "snake"
+			}
+			t.Cleanup(resetOutputFlags)
+
+			err := printSummary(tt.result)
+			if (err != nil) != tt.err {
+				t.Fatalf("Expected error %v, got %v", tt.err, err)
+			}
+			if buf.String() != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, buf.String())
+			}
+		})
+	}
+}
+
+// resetOutputFlags restores the flag defaults changed by the tests
+func resetOutputFlags() {
+	stdout = os.Stdout
+	summaryFormat, compactJSON, ndjson, jsonCasing, outputDir = "json", false, false, "snake", ""
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"log"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/config"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/logger"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/source"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	profileLang      string
+	profileRecursive bool
+)
+
+// profileEntry is a profile as listed by the profile command without name
+type profileEntry struct {
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	Target string `json:"target"`
+	Ref    string `json:"ref,omitempty"`
+	SubDir string `json:"subdir,omitempty"`
+	Format string `json:"format,omitempty"`
+}
+
+var profileCmd = &cobra.Command{
+	Use:   "profile [name]",
+	Short: "Parse a source saved as a profile in the config file",
+	Long: `Parse a source saved under a name in the profiles section of the config file, like the
+local or git command would with the same settings:
+
+  profiles:
+    vpc-module:
+      type: git
+      url: https://github.com/terraform-aws-modules/terraform-aws-vpc
+      ref: v3.0.0
+      format: markdown
+    network:
+      type: local
+      path: ./stacks/network
+      subdir: prod
+
+The format of a profile is used unless --format is given. Without name, the configured
+profiles are listed.`,
+	Example: `  # List the configured profiles
+  terraform-config-parser profile
+
+  # Parse the source of the vpc-module profile
+  terraform-config-parser profile vpc-module --format markdown`,
+	Args: cobra.MaximumNArgs(1),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		cfg, err := config.Load(configPath)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		return cfg.ProfileNames(), cobra.ShellCompDirectiveNoFileComp
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := config.Load(configPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if len(args) == 0 {
+			entries := []*profileEntry{}
+			for _, name := range cfg.ProfileNames() {
+				p := cfg.Profiles[name]
+				entries = append(entries, &profileEntry{Name: name, Type: p.Type, Target: p.Target(), Ref: p.Ref, SubDir: p.SubDir, Format: p.Format})
+			}
+			if err := printJSON(entries); err != nil {
+				log.Fatal(err)
+			}
+			return
+		}
+
+		name := args[0]
+		profile, err := cfg.Profile(name)
+		if err != nil {
+			logger.ErrorKV("Failed to load profile", "profile", name, "error", err)
+			log.Fatal(err)
+		}
+		if profile.Format != "" && !cmd.Flags().Changed("format") {
+			summaryFormat = profile.Format
+		}
+
+		logger.InfoKV("Processing profile", "profile", name, "type", profile.Type, "target", profile.Target(), "ref", profile.Ref, "subdir", profile.SubDir)
+
+		if err := parseAndOutput(profileSource(profile), profileLang, false, profileRecursive); err != nil {
+			logger.ErrorKV("Failed to parse and output profile", "profile", name, "error", err)
+			log.Fatal(err)
+		}
+	},
+}
+
+// profileSource returns the source of a profile: a repository, a bundle or a directory
+func profileSource(profile *config.Profile) source.Source {
+	sourceConfig := source.SourceConfig{Ref: profile.Ref, SubDir: profile.SubDir}
+	switch {
+	case profile.Type == "git":
+		return source.NewGitSource(profile.URL, sourceConfig)
+	case source.IsBundle(profile.Path):
+		return source.NewBundleSource(profile.Path, sourceConfig)
+	default:
+		return source.NewLocalSource(profile.Path, sourceConfig)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(profileCmd)
+
+	profileCmd.Flags().StringVar(&profileLang, "lang", "", "Replace descriptions with translations from descriptions.<lang>.yaml")
+	profileCmd.Flags().BoolVar(&profileRecursive, "recursive", false, "Parse every directory with .tf files and print the configurations keyed by path")
+	profileCmd.Flags().IntVar(&maxDepth, "max-depth", 0, "With --recursive, parse directories at most this many levels below the target (0: no limit)")
+	profileCmd.Flags().StringSliceVar(&prunePaths, "prune", nil, "With --recursive, skip directories matching this glob of the path relative to the target, or of the directory name when it has no slash (repeatable)")
+	profileCmd.Flags().BoolVar(&validateBlocks, "validate-blocks", false, "Check blocks against Terraform's block schemas and report unknown arguments and misplaced blocks as diagnostics")
+	profileCmd.Flags().BoolVar(&lenient, "lenient", false, "Report syntax errors and blocks that fail to parse as diagnostics and keep the blocks that can be read, instead of failing")
+	profileCmd.Flags().BoolVar(&withPositions, "with-positions", false, "Add the file, start_line and end_line of every block")
+	profileCmd.Flags().BoolVar(&flattenValues, "flatten", false, "Add flat_default and flat_config fields mapping dotted key paths of nested values to their leaves")
+	profileCmd.Flags().BoolVar(&summaryDetail, "detail", false, "Include module calls, resources, data sources, providers, imports and locals in JSON output")
+	profileCmd.Flags().StringVar(&summaryFormat, "format", "json", "Output format (json, markdown, template); the format of the profile by default")
+	profileCmd.Flags().StringVar(&templateFile, "template-file", "", "Go text/template file rendering the configuration, with --format template")
+	profileCmd.Flags().StringVar(&outputDir, "output-dir", "", "With --recursive, write the result of each directory to <dir>/<path>/summary.<ext> instead")
+	profileCmd.Flags().BoolVar(&ndjson, "ndjson", false, "With --recursive, write one compact JSON line per directory: {\"path\": ..., \"config\": ...}")
+}
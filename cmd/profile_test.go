@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/config"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/source"
+)
+
+func TestProfileSource(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "prod"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		profile  *config.Profile
+		expected string
+	}{
+		{"git", &config.Profile{Type: "git", URL: "https://github.com/owner/repo", Ref: "v3.0.0"}, "*source.GitSource"},
+		{"bundle", &config.Profile{Type: "local", Path: "vpc" + source.BundleExtension}, "*source.BundleSource"},
+		{"directory", &config.Profile{Type: "local", Path: dir, SubDir: "prod"}, "*source.LocalSource"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := profileSource(tt.profile)
+			if kind := fmt.Sprintf("%T", src); kind != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, kind)
+			}
+		})
+	}
+
+	// The subdirectory of the profile is the root of the configuration
+	_, root, err := profileSource(tests[2].profile).Fetch()
+	if err != nil {
+		t.Fatalf("Failed to fetch: %v", err)
+	}
+	if filepath.Base(root) != "prod" {
+		t.Errorf("Expected the prod subdirectory as root, got %s", root)
+	}
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/logger"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/readme"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/source"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	readmeRef         string
+	readmeSubDir      string
+	readmeFailOnStale bool
+	readmeWrite       bool
+)
+
+var readmeCheckCmd = &cobra.Command{
+	Use:   "readme-check <path|url>",
+	Short: "Check that the docs embedded in module READMEs are up to date",
+	Long: `Check the README.md of every directory containing Terraform configurations under the target.
+The target is treated as a Git repository when it is a URL and as a local directory otherwise.
+
+READMEs with the markers terraform-docs uses, <!-- BEGIN_TF_DOCS --> and <!-- END_TF_DOCS -->, are
+compared with the Markdown tables of the module as --format markdown renders them. The report lists
+each checked module as fresh, stale (with the first differing line) or error (broken markers or a
+module that fails to parse), and the modules without markers as unchecked.`,
+	Example: `  # Report stale READMEs of a monorepo of modules
+  terraform-config-parser readme-check ./modules
+
+  # Fail a CI job when a README is out of date
+  terraform-config-parser readme-check ./modules --fail-on-stale
+
+  # Refresh the stale READMEs in place
+  terraform-config-parser readme-check ./modules --write`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		target := args[0]
+
+		logger.InfoKV("Checking module READMEs", "target", target, "ref", readmeRef, "subdir", readmeSubDir)
+
+		src := source.New(target, source.SourceConfig{
+			Ref:    readmeRef,
+			SubDir: readmeSubDir,
+		})
+		if _, ok := src.(*source.LocalSource); readmeWrite && !ok {
+			log.Fatal(fmt.Errorf("--write needs a local directory"))
+		}
+
+		report, err := checkREADMEs(src)
+		if err != nil {
+			logger.ErrorKV("Failed to check module READMEs", "target", target, "error", err)
+			log.Fatal(err)
+		}
+
+		if readmeWrite {
+			for _, module := range report.Modules {
+				if module.Status != readme.StatusStale {
+					continue
+				}
+				if err := os.WriteFile(module.README, module.Updated, 0o644); err != nil {
+					log.Fatal(err)
+				}
+				logger.InfoKV("Updated README", "path", module.README)
+			}
+		}
+
+		if err := printJSON(report); err != nil {
+			log.Fatal(err)
+		}
+
+		// Written READMEs are up to date, but modules that could not be checked still fail
+		if readmeFailOnStale && (report.Errors > 0 || report.Stale > 0 && !readmeWrite) {
+			logger.ErrorKV("Module READMEs are out of date", "stale", report.Stale, "errors", report.Errors)
+			exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(readmeCheckCmd)
+
+	readmeCheckCmd.Flags().StringVarP(&readmeRef, "ref", "r", "", "Git reference to use when the target is a Git repository")
+	readmeCheckCmd.Flags().StringVar(&readmeSubDir, "subdir", "", "Subdirectory within the target")
+	readmeCheckCmd.Flags().BoolVar(&readmeFailOnStale, "fail-on-stale", false, "Exit with status 1 when a README is stale or cannot be checked")
+	readmeCheckCmd.Flags().BoolVar(&readmeWrite, "write", false, "Replace the docs of stale READMEs with the current ones (local directories only)")
+}
+
+func checkREADMEs(src source.Source) (*readme.Report, error) {
+	fs, rootPath, err := fetchSource(src, true)
+	if err != nil {
+		return nil, err
+	}
+	defer src.Cleanup()
+
+	tracker, err := newTracker("READMEs checked")
+	if err != nil {
+		return nil, err
+	}
+
+	return readme.Check(fs, rootPath, tracker)
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/logger"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/source"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	refsRef     string
+	refsSubDir  string
+	refsAddress string
+)
+
+var refsCmd = &cobra.Command{
+	Use:   "refs <path|url>",
+	Short: "Index every reference in a workspace with its location",
+	Long: `List every traversal (var.x, local.y, module.z.out, data.a.b.attr, ...) used in the
+configuration with its file, line, column and the block and attribute it appears in.
+The target is treated as a Git repository when it is a URL and as a local directory otherwise.
+
+Usages are grouped by root name and then by the address of the referenced object, so
+.roots.var["var.region"] lists every usage of var.region. --address prints only that list.`,
+	Example: `  # Index all references
+  terraform-config-parser refs ./terraform
+
+  # Find all usages of a module
+  terraform-config-parser refs ./terraform --address module.vpc`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		target := args[0]
+
+		logger.InfoKV("Indexing references", "target", target, "ref", refsRef, "subdir", refsSubDir)
+
+		src := source.New(target, source.SourceConfig{
+			Ref:    refsRef,
+			SubDir: refsSubDir,
+		})
+
+		idx, err := indexTraversals(src)
+		if err != nil {
+			logger.ErrorKV("Failed to index references", "target", target, "error", err)
+			log.Fatal(err)
+		}
+
+		var result any = idx
+		if refsAddress != "" {
+			result = idx.Lookup(refsAddress)
+		}
+
+		if err := printJSON(result); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(refsCmd)
+
+	refsCmd.Flags().StringVarP(&refsRef, "ref", "r", "", "Git reference to use when the target is a Git repository")
+	refsCmd.Flags().StringVar(&refsSubDir, "subdir", "", "Subdirectory within the target")
+	refsCmd.Flags().StringVar(&refsAddress, "address", "", "Print only the usages of this address, e.g. var.region")
+}
+
+func indexTraversals(src source.Source) (*parser.TraversalIndex, error) {
+	fs, rootPath, err := fetchSource(src, false)
+	if err != nil {
+		return nil, err
+	}
+	defer src.Cleanup()
+
+	idx, err := parser.NewParser(fs, parser.Detail).WithContext(runCtx).IndexTraversals(rootPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to index references: %w", err)
+	}
+
+	return idx, nil
+}
@@ -0,0 +1,184 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/config"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/logger"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/release"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	releaseVersion    string
+	releaseTargets    []string
+	releaseDist       string
+	releaseBinary     string
+	releaseAllowDirty bool
+	releaseSkipTest   bool
+)
+
+var releaseCmd = &cobra.Command{
+	Use:    "release",
+	Short:  "Build the release archives",
+	Hidden: true,
+	Long: `Build the release archives from the repository in the current directory, one per target:
+
+  <binary>_<version>_<os>_<arch>.tar.gz  the binary, its shell completions under completions/,
+                                         the default configuration and the README
+  checksums.txt                          the SHA-256 of every archive
+
+Binaries are built without cgo, with trimmed paths, without build IDs and with the version,
+commit and build date set; the build date is the commit time, or SOURCE_DATE_EPOCH when set,
+and is used as the time of every archive entry. Building the same commit with the same Go
+toolchain therefore gives identical archives. The binary for the host platform is checked
+with "doctor --strict --offline" before it is packaged.`,
+	Example: `  # Build the release of the version in .version
+  terraform-config-parser release
+
+  # Build a single target
+  terraform-config-parser release --targets linux/amd64 --dist /tmp/dist`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		opts, err := releaseOptions(cmd.Context())
+		if err != nil {
+			logger.ErrorKV("Failed to prepare release", "error", err)
+			log.Fatal(err)
+		}
+
+		result, err := release.Build(cmd.Context(), opts)
+		if err != nil {
+			logger.ErrorKV("Failed to build release", "version", opts.Version, "error", err)
+			log.Fatal(err)
+		}
+
+		if err := printJSON(result); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(releaseCmd)
+
+	targets := make([]string, 0, len(release.DefaultTargets))
+	for _, target := range release.DefaultTargets {
+		targets = append(targets, target.String())
+	}
+
+	releaseCmd.Flags().StringVar(&releaseVersion, "tag", "", "Version of the release (default: the .version file)")
+	releaseCmd.Flags().StringSliceVar(&releaseTargets, "targets", targets, "Platforms to build, as os/arch")
+	releaseCmd.Flags().StringVar(&releaseDist, "dist", "dist", "Directory to write the archives to")
+	releaseCmd.Flags().StringVar(&releaseBinary, "binary", "tfcp", "Name of the executable")
+	releaseCmd.Flags().BoolVar(&releaseAllowDirty, "allow-dirty", false, "Build from a working tree with uncommitted changes")
+	releaseCmd.Flags().BoolVar(&releaseSkipTest, "skip-self-test", false, "Do not run doctor on the host binary")
+}
+
+func releaseOptions(ctx context.Context) (release.Options, error) {
+	opts := release.Options{
+		Version: releaseVersion,
+		Dist:    releaseDist,
+		Binary:  releaseBinary,
+		Package: ".",
+	}
+	if opts.Version == "" {
+		content, err := os.ReadFile(".version")
+		if err != nil {
+			return opts, fmt.Errorf("no --tag and no .version file: %w", err)
+		}
+		opts.Version = strings.TrimSpace(string(content))
+	}
+
+	for _, value := range releaseTargets {
+		target, err := release.ParseTarget(value)
+		if err != nil {
+			return opts, err
+		}
+		opts.Targets = append(opts.Targets, target)
+	}
+
+	status, err := gitOutput(ctx, "status", "--porcelain")
+	if err != nil {
+		return opts, err
+	}
+	if status != "" && !releaseAllowDirty {
+		return opts, fmt.Errorf("the working tree has uncommitted changes; commit them or pass --allow-dirty")
+	}
+	if opts.Commit, err = gitOutput(ctx, "rev-parse", "HEAD"); err != nil {
+		return opts, err
+	}
+
+	epoch := os.Getenv("SOURCE_DATE_EPOCH")
+	if epoch == "" {
+		if epoch, err = gitOutput(ctx, "log", "-1", "--format=%ct"); err != nil {
+			return opts, err
+		}
+	}
+	seconds, err := strconv.ParseInt(epoch, 10, 64)
+	if err != nil {
+		return opts, fmt.Errorf("invalid build date %q: %w", epoch, err)
+	}
+	opts.BuildDate = time.Unix(seconds, 0).UTC()
+
+	if opts.Files, err = releaseFiles(opts.Binary); err != nil {
+		return opts, err
+	}
+
+	if !releaseSkipTest {
+		opts.SelfTest = func(ctx context.Context, binary string) error {
+			out, err := exec.CommandContext(ctx, binary, "doctor", "--strict", "--offline").CombinedOutput()
+			if err != nil {
+				return fmt.Errorf("%w\n%s", err, out)
+			}
+			info, err := exec.CommandContext(ctx, binary, "version").Output()
+			if err != nil {
+				return err
+			}
+			if got := strings.TrimSpace(string(info)); got != opts.Version {
+				return fmt.Errorf("binary reports version %q, expected %q", got, opts.Version)
+			}
+			return nil
+		}
+	}
+	return opts, nil
+}
+
+// releaseFiles are the files packaged next to the binary: completions for every shell, named
+// after the binary, the default configuration and the README
+func releaseFiles(binary string) (map[string][]byte, error) {
+	use := rootCmd.Use
+	rootCmd.Use = binary
+	defer func() { rootCmd.Use = use }()
+
+	extensions := map[string]string{"bash": "bash", "zsh": "zsh", "fish": "fish", "powershell": "ps1"}
+	files := map[string][]byte{"tfparser.example.yaml": config.DefaultYAML}
+	for shell, generate := range completionGenerators(rootCmd) {
+		var buf bytes.Buffer
+		if err := generate(&buf); err != nil {
+			return nil, fmt.Errorf("failed to generate %s completion: %w", shell, err)
+		}
+		files["completions/"+binary+"."+extensions[shell]] = buf.Bytes()
+	}
+
+	if readme, err := os.ReadFile("README.md"); err == nil {
+		files["README.md"] = readme
+	}
+	return files, nil
+}
+
+func gitOutput(ctx context.Context, args ...string) (string, error) {
+	out, err := exec.CommandContext(ctx, "git", args...).Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w", strings.Join(args, " "), err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
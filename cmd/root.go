@@ -2,15 +2,39 @@ package cmd
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
 
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/config"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/filesystem"
 	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/logger"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/progress"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/source"
 	"github.com/Yunsang-Jeong/terraform-config-parser/version"
 	"github.com/charmbracelet/fang"
 	"github.com/spf13/cobra"
 )
 
 var (
-	logLevel string
+	logLevel       string
+	configPath     string
+	progressMode   string
+	dryRun         bool
+	runTimeout     time.Duration
+	gitCacheDir    string
+	noGitCache     bool
+	sparseCheckout bool
+)
+
+// runCtx is the context of the running command: cancelled by an interrupt or once --timeout
+// has passed
+var (
+	runCtx    context.Context    = context.Background()
+	cancelRun context.CancelFunc = func() {}
 )
 
 var rootCmd = &cobra.Command{
@@ -30,6 +54,37 @@ or remote Git repositories (GitHub/GitLab).`,
   
   # Enable debug logging
   terraform-config-parser local . --log-level debug`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if ctx := cmd.Context(); ctx != nil {
+			runCtx = ctx
+		}
+		if runTimeout > 0 {
+			runCtx, cancelRun = context.WithTimeoutCause(runCtx, runTimeout, fmt.Errorf("run exceeded --timeout of %s: %w", runTimeout, context.DeadlineExceeded))
+		}
+
+		cfg, err := config.Load(configPath)
+		if err != nil {
+			return err
+		}
+		credentials := map[string]source.Credential{}
+		for host, cred := range cfg.Credentials {
+			credentials[host] = source.Credential{Username: cred.Username, Token: cred.Token}
+		}
+		source.SetCredentials(credentials)
+		setGitCache()
+		source.SetSparseCheckout(sparseCheckout)
+		if err := startTelemetry(cmd, cfg); err != nil {
+			return err
+		}
+		openOutput()
+		return nil
+	},
+	PersistentPostRunE: func(cmd *cobra.Command, args []string) error {
+		cancelRun()
+		err := flushOutput()
+		finishTelemetry(err, 0)
+		return err
+	},
 }
 
 func Execute(ctx context.Context) error {
@@ -50,11 +105,81 @@ func Execute(ctx context.Context) error {
 		HiddenDefaultCmd:    true,
 	}
 
-	return fang.Execute(ctx, rootCmd)
+	// The first interrupt cancels the run; a second one ends the process right away
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	context.AfterFunc(ctx, stop)
+
+	err := fang.Execute(ctx, rootCmd)
+	if err != nil {
+		finishTelemetry(err, 1)
+	}
+	return err
 }
 
 func init() {
 	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", logger.ErrorLevel, "Log level (debug, info, error)")
+	rootCmd.PersistentFlags().StringVar(&progressMode, "progress", string(progress.ModeBar), "Progress reporting of long runs on stderr (plain, bar, none)")
+	rootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "Print the resolved source and the files that would be parsed, then exit")
+	rootCmd.PersistentFlags().DurationVar(&runTimeout, "timeout", 0, "Cancel fetching and parsing after this duration, e.g. 5m (default: no limit)")
+	rootCmd.PersistentFlags().StringVar(&gitCacheDir, "cache-dir", "", "Directory of the cached clones of git sources (default: git in the cache directory, see "+source.CacheDirEnv+")")
+	rootCmd.PersistentFlags().BoolVar(&noGitCache, "no-cache", false, "Clone git sources in memory on every run instead of using the cache")
+	rootCmd.PersistentFlags().BoolVar(&sparseCheckout, "sparse-checkout", false, "Check out only the --subdir of git sources; the whole commit is still fetched")
+	rootCmd.PersistentFlags().StringVar(&configPath, "config", "", "Path to config file (default: "+config.DefaultFileName+" in the working directory)")
 
 	rootCmd.SetVersionTemplate(`{{printf "%s\n" .Version}}`)
 }
+
+// setGitCache selects where git sources are cached, as set with --cache-dir and --no-cache.
+// Without a usable cache directory, git sources are cloned in memory.
+func setGitCache() {
+	if noGitCache {
+		source.SetGitCache("")
+		return
+	}
+	dir := gitCacheDir
+	if dir == "" {
+		base, err := source.CacheDir()
+		if err != nil {
+			logger.InfoKV("No cache directory, cloning git sources in memory", "error", err)
+			source.SetGitCache("")
+			return
+		}
+		dir = filepath.Join(base, "git")
+	}
+	source.SetGitCache(dir)
+}
+
+// newTracker creates a progress tracker for the mode selected with --progress
+func newTracker(label string) (*progress.Tracker, error) {
+	mode, err := progress.ParseMode(progressMode)
+	if err != nil {
+		return nil, err
+	}
+	return progress.New(mode, label), nil
+}
+
+// fetchSource fetches src for a run. With --dry-run it prints what the run would parse
+// (every configuration directory that --max-depth and --prune keep when recursive) and exits instead.
+func fetchSource(src source.Source, recursive bool) (filesystem.FileReader, string, error) {
+	if dryRun {
+		plan, err := source.DryRun(runCtx, src, recursive, pruning())
+		if err != nil {
+			return nil, "", err
+		}
+		if err := printJSON(plan); err != nil {
+			return nil, "", err
+		}
+		exit(0)
+	}
+
+	logger.DebugKV("Fetching source")
+	fs, rootPath, err := source.Fetch(runCtx, src)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch source: %w", err)
+	}
+	logger.DebugKV("Successfully fetched source", "root_path", rootPath)
+	recordWorkspace(src, fs, rootPath)
+
+	return fs, rootPath, nil
+}
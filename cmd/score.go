@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/config"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/logger"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/score"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/source"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/trend"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	scoreRef     string
+	scoreSubDir  string
+	scoreFormat  string
+	scoreLabel   string
+	scoreHistory string
+	scoreModule  string
+)
+
+var scoreCmd = &cobra.Command{
+	Use:   "score <path|url>",
+	Short: "Compute a weighted health score of a workspace",
+	Long: `Combine quality metrics of a workspace into a score from 0 to 100.
+The target is treated as a Git repository when it is a URL and as a local directory otherwise.
+
+Metrics (value from 0 to 1, default weight):
+- descriptions: variables and outputs with a description (25)
+- pinned_versions: remote modules and providers passing the policy lint rules (25)
+- validations: variables with a validation block (10)
+- backend: a backend is configured (10)
+- formatted: .tf files in canonical format, as terraform fmt writes them (15)
+- tests: terraform test files are present (15)
+
+Metrics with nothing to measure (e.g. validations without variables) are left out.
+Weights are configured in the config file:
+
+  score:
+    weights:
+      backend: 0
+      tests: 30`,
+	Example: `  # Print the score report
+  terraform-config-parser score ./terraform
+
+  # Write a badge for the README
+  terraform-config-parser score ./terraform --format badge > score.svg
+
+  # Record the score for "trend" reports
+  terraform-config-parser score ./terraform --history history.jsonl`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		target := args[0]
+
+		logger.InfoKV("Computing health score", "target", target, "ref", scoreRef, "subdir", scoreSubDir)
+
+		src := source.New(target, source.SourceConfig{
+			Ref:    scoreRef,
+			SubDir: scoreSubDir,
+		})
+
+		report, tfconfig, err := computeScore(src)
+		if err != nil {
+			logger.ErrorKV("Failed to compute health score", "target", target, "error", err)
+			log.Fatal(err)
+		}
+
+		if scoreHistory != "" {
+			module := scoreModule
+			if module == "" {
+				// Named like batch sources, since path.Join would collapse the // of URLs
+				module = target
+				if scoreSubDir != "" {
+					module += "//" + scoreSubDir
+				}
+			}
+			snapshot := &trend.Snapshot{Time: time.Now().UTC().Truncate(time.Second), Module: module, Score: report.Score, Stats: trend.NewStats(tfconfig)}
+			if err := trend.Append(scoreHistory, snapshot); err != nil {
+				log.Fatal(err)
+			}
+			logger.InfoKV("Recorded score snapshot", "history", scoreHistory, "module", module)
+		}
+
+		switch scoreFormat {
+		case "json":
+			if err := printJSON(report); err != nil {
+				log.Fatal(err)
+			}
+		case "badge":
+			fmt.Fprintln(stdout, report.Badge(scoreLabel))
+		default:
+			log.Fatalf("unknown format %q (expected json or badge)", scoreFormat)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(scoreCmd)
+
+	scoreCmd.Flags().StringVarP(&scoreRef, "ref", "r", "", "Git reference to use when the target is a Git repository")
+	scoreCmd.Flags().StringVar(&scoreSubDir, "subdir", "", "Subdirectory within the target")
+	scoreCmd.Flags().StringVar(&scoreFormat, "format", "json", "Output format (json, badge)")
+	scoreCmd.Flags().StringVar(&scoreLabel, "label", "health", "Label of the badge")
+	scoreCmd.Flags().StringVar(&scoreHistory, "history", "", "Append a dated score and stats snapshot to this history file")
+	scoreCmd.Flags().StringVar(&scoreModule, "module-name", "", "Name of the module in the history file (default: target and subdir)")
+}
+
+func computeScore(src source.Source) (*score.Report, *parser.TerraformConfig, error) {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fs, rootPath, err := fetchSource(src, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer src.Cleanup()
+
+	tfconfig, err := parser.NewParser(fs, parser.Detail).WithContext(runCtx).ParseTerraformWorkspace(rootPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse Terraform workspace: %w", err)
+	}
+
+	report, err := score.Compute(fs, rootPath, tfconfig, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	return report, tfconfig, nil
+}
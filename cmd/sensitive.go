@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"log"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/logger"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/sensitive"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/source"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	sensitiveRef      string
+	sensitiveSubDir   string
+	sensitiveUnmarked bool
+)
+
+var sensitiveCmd = &cobra.Command{
+	Use:   "sensitive <path|url>",
+	Short: "Report outputs that expose sensitive values",
+	Long: `Trace every output value through local values and report the outputs that depend on
+sensitive variables or on resources and data sources that produce secrets
+(random_password, tls_private_key, aws_secretsmanager_secret_version, ...).
+The target is treated as a Git repository when it is a URL and as a local directory otherwise.
+
+Each output is reported with the sensitive sources behind it and whether it sets
+sensitive = true. The lint rule output-sensitive-exposure runs the same check.`,
+	Example: `  # Report outputs depending on sensitive values
+  terraform-config-parser sensitive ./terraform
+
+  # Only outputs missing sensitive = true
+  terraform-config-parser sensitive ./terraform --unmarked`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		target := args[0]
+
+		logger.InfoKV("Analyzing sensitive outputs", "target", target, "ref", sensitiveRef, "subdir", sensitiveSubDir)
+
+		src := source.New(target, source.SourceConfig{
+			Ref:    sensitiveRef,
+			SubDir: sensitiveSubDir,
+		})
+
+		tfconfig, err := loadWorkspace(src, parser.Detail)
+		if err != nil {
+			logger.ErrorKV("Failed to analyze sensitive outputs", "target", target, "error", err)
+			log.Fatal(err)
+		}
+
+		report := sensitive.Analyze(tfconfig)
+		if sensitiveUnmarked {
+			report.Outputs = report.Unmarked()
+		}
+
+		if err := printJSON(report); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(sensitiveCmd)
+
+	sensitiveCmd.Flags().StringVarP(&sensitiveRef, "ref", "r", "", "Git reference to use when the target is a Git repository")
+	sensitiveCmd.Flags().StringVar(&sensitiveSubDir, "subdir", "", "Subdirectory within the target")
+	sensitiveCmd.Flags().BoolVar(&sensitiveUnmarked, "unmarked", false, "Only report outputs that do not set sensitive = true")
+}
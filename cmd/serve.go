@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/logger"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/notify"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/output"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/server"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/source"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	serveAddr      string
+	serveWorkers   int
+	serveQueueSize int
+	serveRedisURL  string
+	serveStore     string
+
+	serveAllowLocal bool
+	serveLocalRoots []string
+	serveGitHosts   []string
+	serveToken      string
+
+	serveWebhookURLs         []string
+	serveWebhookFormat       string
+	serveWebhookSecret       string
+	serveWebhookBreakingOnly bool
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve parse results over HTTP",
+	Long: `Run an HTTP server that parses sources on request and serves the results.
+
+Endpoints:
+  POST /workspaces                          parse {"target", "ref", "subdir", "recursive"}
+  GET  /workspaces/{id}?cursor=&limit=      summary with a page of module entries
+  GET  /workspaces/{id}/modules/{path}      full configuration of a module ("." for the root)
+  POST /jobs                                queue a parse with the same body as POST /workspaces
+  GET  /jobs/{id}                           job status and, once succeeded, its workspace_id
+  POST /repositories                        register {"target", ..., "schedule"} to re-parse on a schedule
+  GET  /repositories[/{id}]                 registered repositories and their latest workspace_id
+  DELETE /repositories/{id}                 stop re-parsing a repository
+  POST /repositories/{id}/refresh           re-parse a repository now
+  GET  /events?since=&repository=&breaking= interface changes found by re-parsing repositories
+  GET  /events/stream                       the same events as server-sent events, resuming from Last-Event-ID
+
+The server listens on 127.0.0.1 unless --addr says otherwise. Git targets must use https or ssh
+and a host given with --git-host, the only hosts GIT_TOKEN is sent to. Paths on the server,
+bundles included, are refused unless --allow-local-targets allows any of them or --local-root
+allows those under a directory. With --token, or $TFPARSER_SERVER_TOKEN, every
+request needs an "Authorization: Bearer <token>" header.
+
+Responses carry an ETag for conditional requests and are gzip-compressed for clients
+that accept it. Pages hold 100 modules by default (limit up to 1000); next_cursor is
+empty on the last page.
+
+Jobs run on a pool of --workers workers, for sources too large to parse within an HTTP
+timeout. The queue holds --queue-size pending jobs in memory, or lives in Redis with
+--redis-url so that several servers share it.
+
+Workspaces are kept in memory unless --store selects a backend that survives restarts
+and can be read by other services:
+  file:///var/lib/tfparser (or a path)    one JSON document per workspace
+  s3://bucket/prefix?region=&endpoint=    one object per workspace, AWS credentials from the environment
+                                          (static keys, or AWS_ROLE_ARN with AWS_WEB_IDENTITY_TOKEN_FILE)
+
+Registered repositories are parsed right away and then on their schedule, a cron expression
+("*/30 * * * *", "@daily") or "@every 2h". Each parse is compared with the previous one and
+every module that was added, removed or whose variables and outputs changed becomes an event.
+Registrations and events are kept in memory.
+
+Events are also POSTed to every --webhook-url, as JSON or as a Slack or Teams message
+(--webhook-format), signed with an X-Tfparser-Signature: sha256=<HMAC> header when
+--webhook-secret is set.`,
+	Example: `  # Serve on port 8080 of the local host, parsing GitHub repositories
+  terraform-config-parser serve --addr 127.0.0.1:8080 --git-host github.com
+
+  # Serve on every interface, behind a token, parsing local paths under /srv/terraform as well
+  TFPARSER_SERVER_TOKEN=... terraform-config-parser serve --addr :8080 --local-root /srv/terraform
+
+  # Parse a monorepo and list its modules
+  curl -X POST localhost:8080/workspaces -d '{"target": "https://github.com/owner/repo", "recursive": true}'
+  curl 'localhost:8080/workspaces/<id>?limit=50'
+
+  # Re-parse the main branch every hour and poll for interface changes
+  curl -X POST localhost:8080/repositories -d '{"target": "https://github.com/owner/repo", "recursive": true, "schedule": "@hourly"}'
+  curl 'localhost:8080/events?since=0'
+  curl -N 'localhost:8080/events/stream?breaking=true'
+
+  # Post breaking changes to Slack
+  terraform-config-parser serve --webhook-url https://hooks.slack.com/services/... --webhook-format slack --webhook-breaking-only
+
+  # Queue parses in Redis, shared by every replica
+  terraform-config-parser serve --workers 8 --redis-url redis://:secret@redis:6379/0`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		opts, err := output.ParseOptions(jsonCasing, emptyCollections)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+
+		store, err := server.OpenStore(ctx, serveStore)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		srv := server.New(opts).WithStore(store).WithGitHosts(serveGitHosts...)
+		source.SetTokenHosts(serveGitHosts)
+		if serveAllowLocal || len(serveLocalRoots) > 0 {
+			srv.WithLocalTargets(serveLocalRoots...)
+		}
+		if token := cmp.Or(serveToken, os.Getenv("TFPARSER_SERVER_TOKEN")); token != "" {
+			srv.WithToken(token)
+		}
+		if serveRedisURL != "" {
+			queue, err := server.NewRedisQueue(serveRedisURL, serveQueueSize)
+			if err != nil {
+				log.Fatal(err)
+			}
+			srv.WithQueue(queue)
+		} else {
+			srv.WithQueue(server.NewMemoryQueue(serveQueueSize))
+		}
+		if _, err := notify.ParseFormat(serveWebhookFormat); err != nil && serveWebhookFormat != "json" {
+			log.Fatal(err)
+		}
+		for _, url := range serveWebhookURLs {
+			srv.WithWebhook(&server.Webhook{
+				URL:         url,
+				Format:      serveWebhookFormat,
+				Secret:      serveWebhookSecret,
+				EventFilter: server.EventFilter{BreakingOnly: serveWebhookBreakingOnly},
+			})
+		}
+
+		go srv.RunWorkers(ctx, serveWorkers)
+		go srv.RunScheduler(ctx, 30*time.Second)
+		go srv.RunWebhooks(ctx)
+
+		httpServer := &http.Server{Addr: serveAddr, Handler: srv.Handler()}
+		go func() {
+			<-ctx.Done()
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			httpServer.Shutdown(shutdownCtx)
+		}()
+
+		logger.InfoKV("Starting server", "addr", serveAddr, "workers", serveWorkers, "queue_size", serveQueueSize, "redis", serveRedisURL != "", "store", serveStore, "git_hosts", serveGitHosts, "local_targets", serveAllowLocal || len(serveLocalRoots) > 0, "token", serveToken != "" || os.Getenv("TFPARSER_SERVER_TOKEN") != "")
+		if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.ErrorKV("Server failed", "addr", serveAddr, "error", err)
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().StringVar(&serveAddr, "addr", "127.0.0.1:8080", "Address to listen on, e.g. :8080 for every interface")
+	serveCmd.Flags().BoolVar(&serveAllowLocal, "allow-local-targets", false, "Allow requests to parse any path on the server")
+	serveCmd.Flags().StringSliceVar(&serveLocalRoots, "local-root", nil, "Allow requests to parse paths under this directory (repeatable)")
+	serveCmd.Flags().StringSliceVar(&serveGitHosts, "git-host", nil, "Allow requests to clone from this git host, e.g. github.com (repeatable)")
+	serveCmd.Flags().StringVar(&serveToken, "token", "", "Require this bearer token on every request (default: $TFPARSER_SERVER_TOKEN)")
+	serveCmd.Flags().IntVar(&serveWorkers, "workers", 4, "Number of workers running queued jobs")
+	serveCmd.Flags().IntVar(&serveQueueSize, "queue-size", server.DefaultQueueSize, "Maximum number of pending jobs")
+	serveCmd.Flags().StringVar(&serveStore, "store", "memory", "Workspace store: memory, a directory or s3://bucket/prefix")
+	serveCmd.Flags().StringSliceVar(&serveWebhookURLs, "webhook-url", nil, "POST interface change events to this URL (repeatable)")
+	serveCmd.Flags().StringVar(&serveWebhookFormat, "webhook-format", "json", "Webhook payload format: json, slack or teams")
+	serveCmd.Flags().StringVar(&serveWebhookSecret, "webhook-secret", "", "Sign webhook payloads with HMAC-SHA256 using this secret")
+	serveCmd.Flags().BoolVar(&serveWebhookBreakingOnly, "webhook-breaking-only", false, "Only POST events with breaking changes")
+	serveCmd.Flags().StringVar(&serveRedisURL, "redis-url", "", "Keep the job queue in Redis, e.g. redis://:password@localhost:6379/0")
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"log"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/catalog"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/logger"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/source"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	similarRef           string
+	similarSubDir        string
+	similarIndex         string
+	similarMinSimilarity float64
+	similarLimit         int
+)
+
+var similarCmd = &cobra.Command{
+	Use:   "similar <path|url> --index <index.json>",
+	Short: "Find modules with a similar interface in a catalog index",
+	Long: `Compare the interface of a module with every module of an index built with the index command.
+The target is treated as a Git repository when it is a URL and as a local directory otherwise.
+
+The similarity is the Jaccard index over variable names with their types and output names.
+Matches list the variables and outputs both modules have in common, most similar first.
+Identical copies of the module, including the module itself, are left to dedupe-report.`,
+	Example: `  # Find consolidation candidates for a module
+  terraform-config-parser index ./modules > index.json
+  terraform-config-parser similar ./modules/vpc --index index.json --min-similarity 0.5`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		target := args[0]
+
+		logger.InfoKV("Searching similar modules", "target", target, "ref", similarRef, "subdir", similarSubDir, "index", similarIndex)
+
+		index, err := catalog.Load(similarIndex)
+		if err != nil {
+			logger.ErrorKV("Failed to load module index", "index", similarIndex, "error", err)
+			log.Fatal(err)
+		}
+
+		src := source.New(target, source.SourceConfig{
+			Ref:    similarRef,
+			SubDir: similarSubDir,
+		})
+
+		tfconfig, err := loadWorkspace(src, parser.Detail)
+		if err != nil {
+			logger.ErrorKV("Failed to search similar modules", "target", target, "error", err)
+			log.Fatal(err)
+		}
+
+		module := catalog.NewModule(target, tfconfig)
+		matches := catalog.FindSimilar(module, index, similarMinSimilarity)
+		if similarLimit > 0 && len(matches) > similarLimit {
+			matches = matches[:similarLimit]
+		}
+
+		if err := printJSON(matches); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(similarCmd)
+
+	similarCmd.Flags().StringVarP(&similarRef, "ref", "r", "", "Git reference to use when the target is a Git repository")
+	similarCmd.Flags().StringVar(&similarSubDir, "subdir", "", "Subdirectory within the target")
+	similarCmd.Flags().StringVar(&similarIndex, "index", "", "Module index produced by the index command")
+	similarCmd.Flags().Float64Var(&similarMinSimilarity, "min-similarity", 0.3, "Minimum similarity (0 to 1) of reported modules")
+	similarCmd.Flags().IntVar(&similarLimit, "limit", 10, "Maximum number of reported modules (0 for all)")
+	similarCmd.MarkFlagRequired("index")
+}
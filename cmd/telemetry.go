@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/config"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/filesystem"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/logger"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/source"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/telemetry"
+	"github.com/Yunsang-Jeong/terraform-config-parser/version"
+
+	"github.com/spf13/cobra"
+)
+
+var telemetryOptIn bool
+
+// telemetryRun is the run of the current command while telemetry is enabled, nil otherwise
+var telemetryRun *telemetryState
+
+type telemetryState struct {
+	client *telemetry.Client
+	event  *telemetry.Event
+	start  time.Time
+	sent   bool
+}
+
+func init() {
+	rootCmd.PersistentFlags().BoolVar(&telemetryOptIn, "telemetry", false, "Send anonymous usage metrics of this run to the endpoint of the config file")
+}
+
+// startTelemetry starts recording the run of cmd when the user opted in with --telemetry or the
+// config file and set an endpoint
+func startTelemetry(cmd *cobra.Command, cfg *config.Config) error {
+	policy := cfg.TelemetryPolicy()
+	optedIn := policy.Enabled || telemetryOptIn
+	if !telemetry.Enabled(optedIn, policy.Endpoint) {
+		if optedIn && policy.Endpoint == "" {
+			logger.InfoKV("Telemetry is enabled but no endpoint is configured; nothing is sent")
+		}
+		return nil
+	}
+	if err := telemetry.ValidateEndpoint(policy.Endpoint); err != nil {
+		return err
+	}
+
+	info := version.GetInfo()
+	telemetryRun = &telemetryState{
+		client: telemetry.NewClient(policy.Endpoint, 2*time.Second),
+		event: &telemetry.Event{
+			Command:  strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" "),
+			Version:  info.Version,
+			Platform: info.Platform,
+		},
+		start: time.Now(),
+	}
+
+	// Commands fail with log.Fatal, which exits right after writing the message
+	log.SetOutput(io.MultiWriter(os.Stderr, fatalRecorder{}))
+	return nil
+}
+
+// fatalRecorder sends the event of a command failing with log.Fatal
+type fatalRecorder struct{}
+
+func (fatalRecorder) Write(p []byte) (int, error) {
+	finishTelemetry(errors.New(string(p)), 1)
+	return len(p), nil
+}
+
+// recordWorkspace records the kind of src and the size of the workspace fetched from it
+func recordWorkspace(src source.Source, fs filesystem.FileReader, rootPath string) {
+	if telemetryRun == nil {
+		return
+	}
+	telemetryRun.event.Source = source.Kind(src)
+
+	dirs, err := source.ConfigDirs(fs, rootPath)
+	if err != nil {
+		return
+	}
+	files := 0
+	for _, dir := range dirs {
+		entries, err := fs.ReadDir(dir)
+		if err != nil {
+			return
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() && source.IsConfigFile(entry.Name()) {
+				files++
+			}
+		}
+	}
+	telemetryRun.event.Dirs, telemetryRun.event.Files = telemetry.Bucket(len(dirs)), telemetry.Bucket(files)
+}
+
+// finishTelemetry sends the event of the run once, with the class of err and the exit code
+func finishTelemetry(err error, code int) {
+	if telemetryRun == nil || telemetryRun.sent {
+		return
+	}
+	telemetryRun.sent = true
+
+	event := telemetryRun.event
+	event.DurationMS = time.Since(telemetryRun.start).Milliseconds()
+	event.Error, event.ExitCode = telemetry.Classify(err), code
+	if err := telemetryRun.client.Send(context.Background(), event); err != nil {
+		logger.DebugKV("Failed to send telemetry", "error", err)
+	}
+}
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/logger"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/source"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/tfvars"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	tfvarsRef           string
+	tfvarsSubDir        string
+	tfvarsVarFiles      []string
+	tfvarsFailOnMissing bool
+	tfvarsFailRedundant bool
+)
+
+var tfvarsCmd = &cobra.Command{
+	Use:   "tfvars <path|url>",
+	Short: "Report the variable values set by a workspace's tfvars files",
+	Long: `Read the tfvars files Terraform loads automatically (terraform.tfvars, terraform.tfvars.json,
+*.auto.tfvars and *.auto.tfvars.json) and the files given with --var-file, in the order Terraform
+applies them, and associate their values with the declared variables.
+The target is treated as a Git repository when it is a URL and as a local directory otherwise.
+
+The report lists the effective value of every assigned variable with the file that sets it and
+the files it overrides, the assigned variables that are not declared, and whether each required
+variable is set. Values of sensitive variables are left out. Assignments that set an optional
+variable to its default are listed as redundant, to keep the tfvars files of environments tidy.`,
+	Example: `  # Check which required variables the tfvars files of a root set
+  terraform-config-parser tfvars ./envs/prod
+
+  # Include a -var-file, relative to the target
+  terraform-config-parser tfvars ./envs/prod --var-file secrets.tfvars
+
+  # Fail a CI job when a required variable has no value
+  terraform-config-parser tfvars ./envs/prod --fail-on-missing
+
+  # Fail when a tfvars file repeats a default value
+  terraform-config-parser tfvars ./envs/prod --fail-on-redundant`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		target := args[0]
+
+		logger.InfoKV("Reading tfvars files", "target", target, "ref", tfvarsRef, "subdir", tfvarsSubDir, "var_files", tfvarsVarFiles)
+
+		src := source.New(target, source.SourceConfig{
+			Ref:    tfvarsRef,
+			SubDir: tfvarsSubDir,
+		})
+
+		report, err := analyzeTfvars(src, tfvarsVarFiles)
+		if err != nil {
+			logger.ErrorKV("Failed to read tfvars files", "target", target, "error", err)
+			log.Fatal(err)
+		}
+
+		if err := printJSON(report); err != nil {
+			log.Fatal(err)
+		}
+
+		if tfvarsFailOnMissing && len(report.Missing) > 0 {
+			logger.ErrorKV("Required variables without value", "variables", report.Missing)
+			exit(1)
+		}
+		if tfvarsFailRedundant && len(report.Redundant) > 0 {
+			logger.ErrorKV("Assignments of default values", "count", len(report.Redundant))
+			exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(tfvarsCmd)
+
+	tfvarsCmd.Flags().StringVarP(&tfvarsRef, "ref", "r", "", "Git reference to use when the target is a Git repository")
+	tfvarsCmd.Flags().StringVar(&tfvarsSubDir, "subdir", "", "Subdirectory within the target")
+	tfvarsCmd.Flags().StringArrayVar(&tfvarsVarFiles, "var-file", nil, "Additional tfvars file relative to the target, applied after the automatically loaded ones (repeatable)")
+	tfvarsCmd.Flags().BoolVar(&tfvarsFailOnMissing, "fail-on-missing", false, "Exit with status 1 when a required variable is not set by any tfvars file")
+	tfvarsCmd.Flags().BoolVar(&tfvarsFailRedundant, "fail-on-redundant", false, "Exit with status 1 when a tfvars file sets an optional variable to its default")
+}
+
+func analyzeTfvars(src source.Source, varFiles []string) (*tfvars.Report, error) {
+	fs, rootPath, err := fetchSource(src, false)
+	if err != nil {
+		return nil, err
+	}
+	defer src.Cleanup()
+
+	tfconfig, err := parser.NewParser(fs, parser.Simple).WithContext(runCtx).ParseTerraformWorkspace(rootPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Terraform workspace: %w", err)
+	}
+
+	extra := make([]string, 0, len(varFiles))
+	for _, file := range varFiles {
+		extra = append(extra, filepath.Join(rootPath, file))
+	}
+
+	files, err := tfvars.Load(fs, rootPath, extra...)
+	if err != nil {
+		return nil, err
+	}
+
+	return tfvars.Analyze(tfconfig, files), nil
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"log"
+	"time"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/logger"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/trend"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	trendSince  string
+	trendModule string
+)
+
+var trendCmd = &cobra.Command{
+	Use:   "trend <history>",
+	Short: "Report score and stats changes over time",
+	Long: `Report how the score and object counts of every module changed over the snapshots
+of a history file written by "score --history". For each module the latest values are shown
+together with the change since the first snapshot of the period.`,
+	Example: `  # Changes over the whole history
+  terraform-config-parser trend history.jsonl
+
+  # Changes since the start of the quarter for one module
+  terraform-config-parser trend history.jsonl --since 2025-07-01 --module modules/vpc`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		historyPath := args[0]
+
+		logger.InfoKV("Reporting trend", "history", historyPath, "since", trendSince, "module", trendModule)
+
+		var since time.Time
+		if trendSince != "" {
+			parsed, err := time.Parse(time.DateOnly, trendSince)
+			if err != nil {
+				log.Fatalf("invalid --since %q (expected YYYY-MM-DD): %v", trendSince, err)
+			}
+			since = parsed
+		}
+
+		snapshots, err := trend.Load(historyPath)
+		if err != nil {
+			logger.ErrorKV("Failed to load history", "history", historyPath, "error", err)
+			log.Fatal(err)
+		}
+
+		report := trend.Analyze(snapshots, since)
+		if trendModule != "" {
+			filtered := []*trend.ModuleTrend{}
+			for _, module := range report.Modules {
+				if module.Module == trendModule {
+					filtered = append(filtered, module)
+				}
+			}
+			report.Modules = filtered
+		}
+
+		if err := printJSON(report); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(trendCmd)
+
+	trendCmd.Flags().StringVar(&trendSince, "since", "", "Only use snapshots taken on or after this date (YYYY-MM-DD)")
+	trendCmd.Flags().StringVar(&trendModule, "module", "", "Only report this module")
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"log"
+	"path/filepath"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/batch"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/logger"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/source"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/usage"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	usageModule    string
+	usageRecursive bool
+)
+
+var usageCmd = &cobra.Command{
+	Use:   "usage <manifest>",
+	Short: "Report which roots consume which shared modules and versions",
+	Long: `Parse the root configurations listed in a source manifest (see batch) and report, for
+every shared module they call, each version in use and its consumers: the root, the
+directory and the module call. Use it to answer "who uses module X v1" before making
+breaking changes.
+
+Registry modules are grouped by version constraint and git modules by ref. Spellings of
+the same git repository (git::https://..., git@..., github.com/...) are merged; local
+modules are skipped. Roots that fail to parse are listed under errors.`,
+	Example: `  # Consumers of the VPC module across all roots, including nested stacks
+  terraform-config-parser usage roots.yaml --recursive --module terraform-aws-modules/vpc`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		manifestPath := args[0]
+
+		manifest, err := batch.Load(manifestPath)
+		if err != nil {
+			logger.ErrorKV("Failed to load source manifest", "path", manifestPath, "error", err)
+			log.Fatal(err)
+		}
+
+		logger.InfoKV("Collecting module usage", "manifest", manifestPath, "roots", len(manifest.Sources), "recursive", usageRecursive)
+
+		builder := usage.NewBuilder()
+		for _, entry := range manifest.Sources {
+			src := source.New(entry.Target, source.SourceConfig{Ref: entry.Ref, SubDir: entry.SubDir})
+			if err := collectUsage(builder, entry.Name, src, usageRecursive); err != nil {
+				logger.ErrorKV("Failed to parse root", "root", entry.Name, "error", err)
+				builder.AddError(entry.Name, ".", err)
+			}
+		}
+
+		report := builder.Report()
+		if usageModule != "" {
+			report.Filter(usageModule)
+		}
+
+		if err := printJSON(report); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(usageCmd)
+
+	usageCmd.Flags().StringVar(&usageModule, "module", "", "Only report modules whose source contains this text")
+	usageCmd.Flags().BoolVar(&usageRecursive, "recursive", false, "Also collect module calls of every configuration directory under each root")
+}
+
+// collectUsage records the module calls of a root, or of every configuration directory under it
+func collectUsage(builder *usage.Builder, root string, src source.Source, recursive bool) error {
+	fs, rootPath, err := fetchSource(src, recursive)
+	if err != nil {
+		return err
+	}
+	defer src.Cleanup()
+
+	dirs := []string{rootPath}
+	if recursive {
+		if dirs, err = source.ConfigDirs(fs, rootPath); err != nil {
+			return err
+		}
+	}
+
+	p := parser.NewParser(fs, parser.Detail).WithContext(runCtx)
+	for _, dir := range dirs {
+		rel, err := filepath.Rel(rootPath, dir)
+		if err != nil {
+			rel = dir
+		}
+		rel = filepath.ToSlash(rel)
+
+		tfconfig, err := p.ParseTerraformWorkspace(dir)
+		if err != nil {
+			if !recursive {
+				return err
+			}
+			builder.AddError(root, rel, err)
+			continue
+		}
+		builder.Add(root, rel, tfconfig)
+	}
+	return nil
+}
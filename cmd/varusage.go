@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"log"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/logger"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/source"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/tfstate"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	varUsageRef    string
+	varUsageSubDir string
+	varUsageState  string
+)
+
+var varUsageCmd = &cobra.Command{
+	Use:   "var-usage <path|url> --state <state.json>",
+	Short: "Report which variables influence resources persisted in state",
+	Long: `Trace every variable through local values into resources, data sources, module calls
+and outputs, and report which of them influence objects recorded in a JSON state or plan file
+produced by "terraform show -json".
+The target is treated as a Git repository when it is a URL and as a local directory otherwise.
+
+Each variable is reported as:
+- used: influences at least one persisted object
+- unpersisted: only referenced by blocks that have nothing in state
+- unreferenced: never referenced, a candidate for removal in the next major version`,
+	Example: `  # Report variable usage against the current state
+  terraform show -json > state.json
+  terraform-config-parser var-usage . --state state.json`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		target := args[0]
+
+		logger.InfoKV("Analyzing variable usage", "target", target, "ref", varUsageRef, "subdir", varUsageSubDir, "state", varUsageState)
+
+		doc, err := tfstate.Load(varUsageState)
+		if err != nil {
+			logger.ErrorKV("Failed to load state file", "state", varUsageState, "error", err)
+			log.Fatal(err)
+		}
+
+		src := source.New(target, source.SourceConfig{
+			Ref:    varUsageRef,
+			SubDir: varUsageSubDir,
+		})
+
+		tfconfig, err := loadWorkspace(src, parser.Detail)
+		if err != nil {
+			logger.ErrorKV("Failed to analyze variable usage", "target", target, "error", err)
+			log.Fatal(err)
+		}
+
+		if err := printJSON(tfstate.AnalyzeVariableUsage(tfconfig, doc)); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(varUsageCmd)
+
+	varUsageCmd.Flags().StringVarP(&varUsageRef, "ref", "r", "", "Git reference to use when the target is a Git repository")
+	varUsageCmd.Flags().StringVar(&varUsageSubDir, "subdir", "", "Subdirectory within the target")
+	varUsageCmd.Flags().StringVar(&varUsageState, "state", "", "JSON state or plan file produced by terraform show -json")
+	varUsageCmd.MarkFlagRequired("state")
+}
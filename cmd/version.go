@@ -24,9 +24,9 @@ The --long flag shows additional build information including commit hash, build
   terraform-config-parser version --long`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if versionLong {
-			fmt.Println(version.GetFullVersion())
+			fmt.Fprintln(stdout, version.GetFullVersion())
 		} else {
-			fmt.Println(version.GetVersion())
+			fmt.Fprintln(stdout, version.GetVersion())
 		}
 	},
 }
@@ -0,0 +1,200 @@
+package awsv4
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Provider supplies the credentials requests are signed with
+type Provider interface {
+	// Retrieve returns valid credentials, refreshing temporary ones before they expire
+	Retrieve(ctx context.Context) (*Credentials, error)
+}
+
+type staticProvider struct {
+	creds *Credentials
+}
+
+func (p *staticProvider) Retrieve(context.Context) (*Credentials, error) {
+	return p.creds, nil
+}
+
+// StaticProvider always supplies creds
+func StaticProvider(creds *Credentials) Provider {
+	return &staticProvider{creds: creds}
+}
+
+// ProviderFromEnv picks credentials like the AWS SDKs do: static keys from AWS_ACCESS_KEY_ID and
+// AWS_SECRET_ACCESS_KEY, otherwise a web identity from AWS_ROLE_ARN with the OIDC token of
+// AWS_WEB_IDENTITY_TOKEN_FILE or, in GitHub Actions jobs with id-token permission, of the job
+func ProviderFromEnv() (Provider, error) {
+	if creds, err := CredentialsFromEnv(); err == nil {
+		return StaticProvider(creds), nil
+	}
+
+	roleARN := os.Getenv("AWS_ROLE_ARN")
+	if roleARN == "" {
+		return nil, errors.New("AWS credentials not found: set AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY, or AWS_ROLE_ARN with AWS_WEB_IDENTITY_TOKEN_FILE")
+	}
+
+	provider := &WebIdentityProvider{
+		RoleARN:     roleARN,
+		SessionName: os.Getenv("AWS_ROLE_SESSION_NAME"),
+		Endpoint:    fmt.Sprintf("https://sts.%s.amazonaws.com/", RegionFromEnv()),
+	}
+	switch {
+	case os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE") != "":
+		provider.Token = TokenFromFile(os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE"))
+	case os.Getenv("ACTIONS_ID_TOKEN_REQUEST_URL") != "":
+		provider.Token = GitHubActionsToken("sts.amazonaws.com")
+	default:
+		return nil, errors.New("AWS_ROLE_ARN is set without an OIDC token: set AWS_WEB_IDENTITY_TOKEN_FILE")
+	}
+	return provider, nil
+}
+
+// TokenFromFile reads an OIDC token from path on every call, as token files are rotated,
+// e.g. Kubernetes projected service account tokens
+func TokenFromFile(path string) func(ctx context.Context) (string, error) {
+	return func(context.Context) (string, error) {
+		token, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read web identity token: %w", err)
+		}
+		return strings.TrimSpace(string(token)), nil
+	}
+}
+
+// GitHubActionsToken requests an OIDC token for audience from the GitHub Actions runtime,
+// through ACTIONS_ID_TOKEN_REQUEST_URL and ACTIONS_ID_TOKEN_REQUEST_TOKEN
+func GitHubActionsToken(audience string) func(ctx context.Context) (string, error) {
+	return func(ctx context.Context) (string, error) {
+		requestURL, err := url.Parse(os.Getenv("ACTIONS_ID_TOKEN_REQUEST_URL"))
+		if err != nil {
+			return "", fmt.Errorf("invalid ACTIONS_ID_TOKEN_REQUEST_URL: %w", err)
+		}
+		query := requestURL.Query()
+		query.Set("audience", audience)
+		requestURL.RawQuery = query.Encode()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL.String(), nil)
+		if err != nil {
+			return "", err
+		}
+		req.Header.Set("Authorization", "Bearer "+os.Getenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN"))
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return "", fmt.Errorf("failed to request GitHub Actions OIDC token: %w", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("failed to request GitHub Actions OIDC token: %s", resp.Status)
+		}
+
+		body := struct {
+			Value string `json:"value"`
+		}{}
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			return "", fmt.Errorf("failed to decode GitHub Actions OIDC token: %w", err)
+		}
+		return body.Value, nil
+	}
+}
+
+// refreshWindow is how long before their expiration temporary credentials are replaced
+const refreshWindow = 5 * time.Minute
+
+// WebIdentityProvider exchanges an OIDC token for temporary credentials of a role with STS
+// AssumeRoleWithWebIdentity, so that CI jobs and workloads need no long-lived keys
+type WebIdentityProvider struct {
+	RoleARN string
+	// SessionName identifies the session in CloudTrail; it defaults to terraform-config-parser
+	SessionName string
+	// Token returns the OIDC token to exchange
+	Token func(ctx context.Context) (string, error)
+	// Endpoint is the STS endpoint, e.g. https://sts.eu-west-1.amazonaws.com/
+	Endpoint string
+
+	mu      sync.Mutex
+	creds   *Credentials
+	expires time.Time
+}
+
+func (p *WebIdentityProvider) Retrieve(ctx context.Context) (*Credentials, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.creds != nil && time.Until(p.expires) > refreshWindow {
+		return p.creds, nil
+	}
+
+	token, err := p.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sessionName := p.SessionName
+	if sessionName == "" {
+		sessionName = "terraform-config-parser"
+	}
+
+	form := url.Values{
+		"Action":           {"AssumeRoleWithWebIdentity"},
+		"Version":          {"2011-06-15"},
+		"RoleArn":          {p.RoleARN},
+		"RoleSessionName":  {sessionName},
+		"WebIdentityToken": {token},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.Endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to assume role %s: %w", p.RoleARN, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to assume role %s: %w", p.RoleARN, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to assume role %s: STS returned %s: %s", p.RoleARN, resp.Status, body)
+	}
+
+	result := struct {
+		Credentials struct {
+			AccessKeyID     string    `xml:"AccessKeyId"`
+			SecretAccessKey string    `xml:"SecretAccessKey"`
+			SessionToken    string    `xml:"SessionToken"`
+			Expiration      time.Time `xml:"Expiration"`
+		} `xml:"AssumeRoleWithWebIdentityResult>Credentials"`
+	}{}
+	if err := xml.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode STS response: %w", err)
+	}
+	if result.Credentials.AccessKeyID == "" {
+		return nil, fmt.Errorf("failed to assume role %s: STS returned no credentials", p.RoleARN)
+	}
+
+	p.creds = &Credentials{
+		AccessKeyID:     result.Credentials.AccessKeyID,
+		SecretAccessKey: result.Credentials.SecretAccessKey,
+		SessionToken:    result.Credentials.SessionToken,
+	}
+	p.expires = result.Credentials.Expiration
+	return p.creds, nil
+}
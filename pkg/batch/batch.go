@@ -0,0 +1,200 @@
+// Package batch parses the sources listed in a source manifest in a single run.
+package batch
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/logger"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/progress"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/source"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Manifest lists the sources of a batch run
+type Manifest struct {
+	Sources []*Entry `yaml:"sources"`
+}
+
+// Entry is a source of the manifest
+type Entry struct {
+	// Name identifies the source in the report; it defaults to the target and subdir
+	Name   string `yaml:"name" json:"name"`
+	Target string `yaml:"target" json:"target"`
+	Ref    string `yaml:"ref" json:"ref,omitempty"`
+	SubDir string `yaml:"subdir" json:"subdir,omitempty"`
+	// Optional sources are recorded in the report when they fail, without failing the run
+	Optional bool `yaml:"optional" json:"optional,omitempty"`
+}
+
+// Load reads the source manifest at path
+func Load(path string) (*Manifest, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read source manifest %s: %w", path, err)
+	}
+
+	manifest := &Manifest{}
+	if err := yaml.Unmarshal(content, manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse source manifest %s: %w", path, err)
+	}
+
+	names := map[string]bool{}
+	for i, entry := range manifest.Sources {
+		if entry.Target == "" {
+			return nil, fmt.Errorf("source %d of %s has no target", i+1, path)
+		}
+		if entry.Name == "" {
+			entry.Name = entry.Target
+			if entry.SubDir != "" {
+				entry.Name += "//" + entry.SubDir
+			}
+		}
+		if names[entry.Name] {
+			return nil, fmt.Errorf("source %s is listed twice in %s", entry.Name, path)
+		}
+		names[entry.Name] = true
+	}
+
+	return manifest, nil
+}
+
+// Options controls how sources are parsed and when failures fail the whole run
+type Options struct {
+	// Parallel is the number of sources parsed at the same time; 0 or 1 parses them one by one
+	Parallel int
+	// IgnoreErrors treats every source as optional
+	IgnoreErrors bool
+	// MaxFailures fails the run when more sources fail, optional ones included; negative means no limit
+	MaxFailures int
+	// Tracker reports each finished source; nil reports nothing
+	Tracker *progress.Tracker
+}
+
+// Result is the outcome of a single source
+type Result struct {
+	*Entry
+	Config *parser.TerraformConfig `json:"config,omitempty"`
+	Error  string                  `json:"error,omitempty"`
+}
+
+type Report struct {
+	Results []*Result `json:"results"`
+	Tally
+}
+
+// Tally counts the failed sources of a run
+type Tally struct {
+	// Failures counts every failed source and IgnoredFailures the optional ones among them
+	Failures        int `json:"failures"`
+	IgnoredFailures int `json:"ignored_failures"`
+	// Failed is true when a required source failed or Failures exceeds the threshold
+	Failed bool `json:"failed"`
+}
+
+// record counts the failure of entry, if err is not nil
+func (t *Tally) record(entry *Entry, err error, opts Options) {
+	if err == nil {
+		return
+	}
+	t.Failures++
+	if entry.Optional || opts.IgnoreErrors {
+		logger.InfoKV("Ignoring failure of optional source", "source", entry.Name, "error", err)
+		t.IgnoredFailures++
+	} else {
+		logger.ErrorKV("Failed to parse source", "source", entry.Name, "error", err)
+		t.Failed = true
+	}
+}
+
+// finish fails the run when there are more failures than opts allows
+func (t *Tally) finish(opts Options) {
+	if opts.MaxFailures >= 0 && t.Failures > opts.MaxFailures {
+		logger.ErrorKV("Too many failed sources", "failures", t.Failures, "max_failures", opts.MaxFailures)
+		t.Failed = true
+	}
+}
+
+// each calls fn for every source of manifest, up to opts.Parallel at a time, and waits for them
+func each(manifest *Manifest, opts Options, fn func(i int, entry *Entry)) {
+	opts.Tracker.SetTotal(len(manifest.Sources))
+	defer opts.Tracker.Finish()
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, max(opts.Parallel, 1))
+	for i, entry := range manifest.Sources {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func() {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			fn(i, entry)
+			opts.Tracker.Increment(entry.Name)
+		}()
+	}
+	wg.Wait()
+}
+
+// Run parses every source of manifest with parse, up to opts.Parallel at a time, and records
+// each outcome in manifest order
+func Run(manifest *Manifest, opts Options, parse func(entry *Entry) (*parser.TerraformConfig, error)) *Report {
+	report := &Report{Results: make([]*Result, len(manifest.Sources))}
+	errs := make([]error, len(manifest.Sources))
+
+	each(manifest, opts, func(i int, entry *Entry) {
+		report.Results[i] = &Result{Entry: entry}
+		report.Results[i].Config, errs[i] = parse(entry)
+	})
+
+	for i, result := range report.Results {
+		if errs[i] != nil {
+			result.Config = nil
+			result.Error = errs[i].Error()
+		}
+		report.record(result.Entry, errs[i], opts)
+	}
+	report.finish(opts)
+
+	return report
+}
+
+// PlannedSource is the dry-run plan of a source, or why it could not be fetched
+type PlannedSource struct {
+	*Entry
+	Plan  *source.Plan `json:"plan,omitempty"`
+	Error string       `json:"error,omitempty"`
+}
+
+// Plan is what a batch run would parse, source by source, to check a manifest before running it
+type Plan struct {
+	Sources []*PlannedSource `json:"sources"`
+	Tally
+}
+
+// DryRun plans every source of manifest with plan, e.g. source.DryRun, up to opts.Parallel at a
+// time. Sources that cannot be fetched count as failures like in Run.
+func DryRun(manifest *Manifest, opts Options, plan func(entry *Entry) (*source.Plan, error)) *Plan {
+	result := &Plan{Sources: make([]*PlannedSource, len(manifest.Sources))}
+	errs := make([]error, len(manifest.Sources))
+
+	each(manifest, opts, func(i int, entry *Entry) {
+		result.Sources[i] = &PlannedSource{Entry: entry}
+		result.Sources[i].Plan, errs[i] = plan(entry)
+	})
+
+	for i, planned := range result.Sources {
+		if errs[i] != nil {
+			planned.Plan = nil
+			planned.Error = errs[i].Error()
+		}
+		result.record(planned.Entry, errs[i], opts)
+	}
+	result.finish(opts)
+
+	return result
+}
@@ -0,0 +1,112 @@
+package batch
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/source"
+)
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sources.yaml")
+	content := `sources:
+  - target: https://example.com/vpc.git
+    subdir: modules/vpc
+  - name: sandbox
+    target: ./sandbox
+    optional: true
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	manifest, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(manifest.Sources) != 2 {
+		t.Fatalf("Expected 2 sources, got %d", len(manifest.Sources))
+	}
+	if name := manifest.Sources[0].Name; name != "https://example.com/vpc.git//modules/vpc" {
+		t.Errorf("Unexpected default name %q", name)
+	}
+	if !manifest.Sources[1].Optional {
+		t.Error("Expected sandbox to be optional")
+	}
+}
+
+func TestRun(t *testing.T) {
+	manifest := &Manifest{Sources: []*Entry{
+		{Name: "ok", Target: "ok"},
+		{Name: "optional", Target: "broken", Optional: true},
+		{Name: "required", Target: "broken"},
+	}}
+	parse := func(entry *Entry) (*parser.TerraformConfig, error) {
+		if entry.Target == "broken" {
+			return nil, errors.New("cannot fetch")
+		}
+		return &parser.TerraformConfig{}, nil
+	}
+
+	tests := []struct {
+		name    string
+		sources []*Entry
+		opts    Options
+		failed  bool
+		ignored int
+	}{
+		{name: "Required failure fails the run", sources: manifest.Sources, opts: Options{MaxFailures: -1}, failed: true, ignored: 1},
+		{name: "Optional failures are recorded only", sources: manifest.Sources[:2], opts: Options{MaxFailures: -1}, failed: false, ignored: 1},
+		{name: "Ignore errors makes every source optional", sources: manifest.Sources, opts: Options{IgnoreErrors: true, MaxFailures: -1}, failed: false, ignored: 2},
+		{name: "Max failures counts optional sources", sources: manifest.Sources, opts: Options{IgnoreErrors: true, MaxFailures: 1}, failed: true, ignored: 2},
+		{name: "Parallel runs keep the manifest order", sources: manifest.Sources, opts: Options{Parallel: 3, MaxFailures: -1}, failed: true, ignored: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := Run(&Manifest{Sources: tt.sources}, tt.opts, parse)
+			if report.Failed != tt.failed {
+				t.Errorf("Expected failed %v, got %v", tt.failed, report.Failed)
+			}
+			if report.IgnoredFailures != tt.ignored {
+				t.Errorf("Expected %d ignored failures, got %d", tt.ignored, report.IgnoredFailures)
+			}
+			if report.Results[0].Config == nil || report.Results[1].Error == "" {
+				t.Errorf("Expected results to record configs and errors: %+v", report.Results)
+			}
+		})
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	manifest := &Manifest{Sources: []*Entry{
+		{Name: "ok", Target: "ok"},
+		{Name: "optional", Target: "broken", Optional: true},
+		{Name: "required", Target: "broken"},
+	}}
+	plan := func(entry *Entry) (*source.Plan, error) {
+		if entry.Target == "broken" {
+			return &source.Plan{}, errors.New("cannot fetch")
+		}
+		return &source.Plan{Source: "local", Location: entry.Target}, nil
+	}
+
+	result := DryRun(manifest, Options{Parallel: 3, MaxFailures: -1}, plan)
+	if len(result.Sources) != 3 {
+		t.Fatalf("Expected a plan for every source, got %d", len(result.Sources))
+	}
+	if p := result.Sources[0]; p.Name != "ok" || p.Plan == nil || p.Plan.Location != "ok" || p.Error != "" {
+		t.Errorf("Unexpected plan of the first source: %+v", p)
+	}
+	for _, p := range result.Sources[1:] {
+		if p.Plan != nil || p.Error != "cannot fetch" {
+			t.Errorf("Expected %s to record its error only: %+v", p.Name, p)
+		}
+	}
+	if !result.Failed || result.Failures != 2 || result.IgnoredFailures != 1 {
+		t.Errorf("Unexpected tally: %+v", result.Tally)
+	}
+}
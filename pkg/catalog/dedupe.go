@@ -0,0 +1,164 @@
+package catalog
+
+import (
+	"slices"
+	"strings"
+)
+
+type ClusterKind string
+
+const (
+	// ClusterIdentical groups modules with the same fingerprint
+	ClusterIdentical ClusterKind = "identical"
+	// ClusterSimilar groups modules whose interface and resources mostly overlap
+	ClusterSimilar ClusterKind = "similar"
+)
+
+type Cluster struct {
+	Kind        ClusterKind `json:"kind"`
+	Fingerprint string      `json:"fingerprint,omitempty"`
+	// Similarity is the lowest similarity between linked modules of a similar cluster
+	Similarity float64  `json:"similarity"`
+	Modules    []string `json:"modules"`
+}
+
+type DedupeReport struct {
+	// ModuleCount is the number of indexed modules
+	ModuleCount int        `json:"module_count"`
+	Clusters    []*Cluster `json:"clusters"`
+}
+
+// Dedupe clusters modules of the index that are identical copies, and modules that
+// are near-identical with a similarity of at least threshold (0 to 1)
+func Dedupe(index *Index, threshold float64) *DedupeReport {
+	report := &DedupeReport{ModuleCount: len(index.Modules), Clusters: []*Cluster{}}
+
+	// Identical copies are grouped first; each group is represented by its first module
+	groups := map[string][]*Module{}
+	fingerprints := []string{}
+	for _, module := range index.Modules {
+		if _, ok := groups[module.Fingerprint]; !ok {
+			fingerprints = append(fingerprints, module.Fingerprint)
+		}
+		groups[module.Fingerprint] = append(groups[module.Fingerprint], module)
+	}
+
+	for _, fingerprint := range fingerprints {
+		if len(groups[fingerprint]) > 1 {
+			report.Clusters = append(report.Clusters, &Cluster{
+				Kind:        ClusterIdentical,
+				Fingerprint: fingerprint,
+				Similarity:  1,
+				Modules:     paths(groups[fingerprint]),
+			})
+		}
+	}
+
+	// Near-identical groups are linked transitively (single linkage)
+	parent := make([]int, len(fingerprints))
+	lowest := make([]float64, len(fingerprints))
+	for i := range parent {
+		parent[i] = i
+		lowest[i] = 1
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	for i := range fingerprints {
+		for j := i + 1; j < len(fingerprints); j++ {
+			similarity := Similarity(groups[fingerprints[i]][0], groups[fingerprints[j]][0])
+			if similarity < threshold {
+				continue
+			}
+			ri, rj := find(i), find(j)
+			if ri != rj {
+				parent[rj] = ri
+				lowest[ri] = min(lowest[ri], lowest[rj])
+			}
+			lowest[ri] = min(lowest[ri], similarity)
+		}
+	}
+
+	members := map[int][]*Module{}
+	roots := []int{}
+	for i, fingerprint := range fingerprints {
+		root := find(i)
+		if _, ok := members[root]; !ok {
+			roots = append(roots, root)
+		}
+		members[root] = append(members[root], groups[fingerprint]...)
+	}
+	for _, root := range roots {
+		if len(members[root]) == len(groups[fingerprints[root]]) {
+			continue
+		}
+		report.Clusters = append(report.Clusters, &Cluster{
+			Kind:       ClusterSimilar,
+			Similarity: lowest[root],
+			Modules:    paths(members[root]),
+		})
+	}
+
+	slices.SortStableFunc(report.Clusters, func(a, b *Cluster) int {
+		if len(a.Modules) != len(b.Modules) {
+			return len(b.Modules) - len(a.Modules)
+		}
+		return strings.Compare(a.Modules[0], b.Modules[0])
+	})
+
+	return report
+}
+
+// Similarity compares two modules by their interfaces (variable names and types, output names)
+// and their resources, weighting both equally
+func Similarity(a, b *Module) float64 {
+	return (jaccard(interfaceSet(a), interfaceSet(b)) + jaccard(resourceSet(a), resourceSet(b))) / 2
+}
+
+func interfaceSet(module *Module) map[string]bool {
+	set := map[string]bool{}
+	for _, variable := range module.Variables {
+		set["var:"+variable.Name+":"+variable.Type] = true
+	}
+	for _, output := range module.Outputs {
+		set["output:"+output] = true
+	}
+	return set
+}
+
+func resourceSet(module *Module) map[string]bool {
+	set := map[string]bool{}
+	for _, resource := range module.Resources {
+		set[resource] = true
+	}
+	return set
+}
+
+// jaccard returns |a ∩ b| / |a ∪ b|, where two empty sets are identical
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+
+	intersection := 0
+	for key := range a {
+		if b[key] {
+			intersection++
+		}
+	}
+	return float64(intersection) / float64(len(a)+len(b)-intersection)
+}
+
+func paths(modules []*Module) []string {
+	result := []string{}
+	for _, module := range modules {
+		result = append(result, module.Path)
+	}
+	slices.Sort(result)
+	return result
+}
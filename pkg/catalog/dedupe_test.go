@@ -0,0 +1,68 @@
+package catalog
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDedupe(t *testing.T) {
+	vpcVariables := []*Variable{{Name: "cidr", Type: "string"}, {Name: "name", Type: "string"}}
+
+	index := &Index{
+		Modules: []*Module{
+			{Path: "team-a/vpc", Fingerprint: "sha256:aaa", Variables: vpcVariables, Outputs: []string{"vpc_id"}, Resources: []string{"aws_vpc.this"}},
+			{Path: "team-b/vpc", Fingerprint: "sha256:aaa", Variables: vpcVariables, Outputs: []string{"vpc_id"}, Resources: []string{"aws_vpc.this"}},
+			{Path: "team-c/network", Fingerprint: "sha256:bbb", Variables: vpcVariables, Outputs: []string{"vpc_id"}, Resources: []string{"aws_vpc.this", "aws_flow_log.this"}},
+			{Path: "team-a/bucket", Fingerprint: "sha256:ccc", Variables: []*Variable{{Name: "bucket", Type: "string"}}, Resources: []string{"aws_s3_bucket.this"}},
+		},
+	}
+
+	report := Dedupe(index, 0.7)
+
+	if report.ModuleCount != 4 {
+		t.Errorf("Expected 4 modules, got %d", report.ModuleCount)
+	}
+	if len(report.Clusters) != 2 {
+		t.Fatalf("Expected 2 clusters, got %d", len(report.Clusters))
+	}
+
+	similar := report.Clusters[0]
+	if similar.Kind != ClusterSimilar || !reflect.DeepEqual(similar.Modules, []string{"team-a/vpc", "team-b/vpc", "team-c/network"}) {
+		t.Errorf("Unexpected similar cluster: %+v", similar)
+	}
+	if similar.Similarity != 0.75 {
+		t.Errorf("Expected similarity 0.75, got %v", similar.Similarity)
+	}
+
+	identical := report.Clusters[1]
+	if identical.Kind != ClusterIdentical || identical.Fingerprint != "sha256:aaa" || !reflect.DeepEqual(identical.Modules, []string{"team-a/vpc", "team-b/vpc"}) {
+		t.Errorf("Unexpected identical cluster: %+v", identical)
+	}
+
+	if clusters := Dedupe(index, 0.9).Clusters; len(clusters) != 1 || clusters[0].Kind != ClusterIdentical {
+		t.Errorf("Expected only the identical cluster above the threshold, got %+v", clusters)
+	}
+}
+
+func TestFindSimilar(t *testing.T) {
+	index := &Index{
+		Modules: []*Module{
+			{Path: "vpc", Fingerprint: "sha256:aaa", Variables: []*Variable{{Name: "cidr", Type: "string"}, {Name: "name", Type: "string"}}, Outputs: []string{"vpc_id"}},
+			{Path: "network", Fingerprint: "sha256:bbb", Variables: []*Variable{{Name: "cidr", Type: "string"}, {Name: "name", Type: "list(string)"}}, Outputs: []string{"vpc_id"}},
+			{Path: "bucket", Fingerprint: "sha256:ccc", Variables: []*Variable{{Name: "bucket", Type: "string"}}},
+		},
+	}
+
+	matches := FindSimilar(index.Modules[0], index, 0.3)
+	if len(matches) != 1 {
+		t.Fatalf("Expected 1 match, got %d", len(matches))
+	}
+
+	match := matches[0]
+	if match.Path != "network" || match.Similarity != 0.5 {
+		t.Errorf("Expected network with similarity 0.5, got %s with %v", match.Path, match.Similarity)
+	}
+	if !reflect.DeepEqual(match.Variables, []string{"cidr"}) || !reflect.DeepEqual(match.Outputs, []string{"vpc_id"}) {
+		t.Errorf("Unexpected shared interface: variables=%v outputs=%v", match.Variables, match.Outputs)
+	}
+}
@@ -0,0 +1,135 @@
+package catalog
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/filesystem"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/logger"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/progress"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/source"
+)
+
+// Index describes every module found under a root directory
+type Index struct {
+	Modules []*Module `json:"modules"`
+	// Errors maps module paths that could not be parsed to the parse error
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// Module is the index entry of a single module directory
+type Module struct {
+	// Path is relative to the indexed root
+	Path        string            `json:"path"`
+	Fingerprint string            `json:"fingerprint"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	Variables   []*Variable       `json:"variables,omitempty"`
+	Outputs     []string          `json:"outputs,omitempty"`
+	// Resources lists managed and data resource addresses, e.g. aws_vpc.this, data.aws_region.current
+	Resources []string `json:"resources,omitempty"`
+	// EphemeralResources lists ephemeral resource addresses, e.g. ephemeral.random_password.db
+	EphemeralResources []string `json:"ephemeral_resources,omitempty"`
+	// WriteOnly lists write-only arguments by resource, e.g. aws_db_instance.this.password_wo
+	WriteOnly []string `json:"write_only,omitempty"`
+}
+
+type Variable struct {
+	Name     string `json:"name"`
+	Type     string `json:"type,omitempty"`
+	Required bool   `json:"required"`
+}
+
+// Build parses every directory under root that contains .tf files (see source.ConfigDirs),
+// reporting each parsed directory to tracker (which may be nil).
+func Build(fs filesystem.FileReader, root string, tracker *progress.Tracker) (*Index, error) {
+	index := &Index{Modules: []*Module{}, Errors: map[string]string{}}
+	p := parser.NewParser(fs, parser.Detail)
+
+	dirs, err := source.ConfigDirs(fs, root)
+	if err != nil {
+		return nil, err
+	}
+	tracker.SetTotal(len(dirs))
+	defer tracker.Finish()
+
+	for _, dir := range dirs {
+		rel, err := filepath.Rel(root, dir)
+		if err != nil {
+			rel = dir
+		}
+
+		tfconfig, err := p.ParseTerraformWorkspace(dir)
+		tracker.Increment(rel)
+		if err != nil {
+			logger.InfoKV("Skipping module that failed to parse", "path", rel, "error", err)
+			index.Errors[rel] = err.Error()
+			continue
+		}
+		index.Modules = append(index.Modules, NewModule(rel, tfconfig))
+	}
+
+	slices.SortFunc(index.Modules, func(a, b *Module) int { return strings.Compare(a.Path, b.Path) })
+	return index, nil
+}
+
+// NewModule creates the index entry of a parsed module
+func NewModule(path string, tfconfig *parser.TerraformConfig) *Module {
+	module := &Module{
+		Path:        filepath.ToSlash(path),
+		Fingerprint: tfconfig.Fingerprint,
+		Annotations: tfconfig.Annotations,
+	}
+
+	for _, variable := range tfconfig.Variables {
+		module.Variables = append(module.Variables, &Variable{
+			Name:     variable.Name,
+			Type:     variable.Type,
+			Required: variable.Required,
+		})
+	}
+	for _, output := range tfconfig.Outputs {
+		module.Outputs = append(module.Outputs, output.Name)
+	}
+	for _, resource := range tfconfig.Resources {
+		module.Resources = append(module.Resources, resource.Address())
+	}
+	for _, data := range tfconfig.DataSources {
+		module.Resources = append(module.Resources, data.Address())
+	}
+	for _, ephemeral := range tfconfig.EphemeralResources {
+		module.EphemeralResources = append(module.EphemeralResources, ephemeral.Address())
+	}
+	for _, resource := range tfconfig.Resources {
+		for _, name := range resource.WriteOnly {
+			module.WriteOnly = append(module.WriteOnly, resource.Address()+"."+name)
+		}
+	}
+
+	slices.SortFunc(module.Variables, func(a, b *Variable) int { return strings.Compare(a.Name, b.Name) })
+	slices.Sort(module.Outputs)
+	slices.Sort(module.Resources)
+	slices.Sort(module.EphemeralResources)
+	slices.Sort(module.WriteOnly)
+
+	return module
+}
+
+// Load reads an index written as JSON
+func Load(path string) (*Index, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index %s: %w", path, err)
+	}
+
+	index := &Index{}
+	if err := json.Unmarshal(content, index); err != nil {
+		return nil, fmt.Errorf("failed to parse index %s: %w", path, err)
+	}
+
+	return index, nil
+}
@@ -0,0 +1,63 @@
+package catalog
+
+import (
+	"slices"
+	"strings"
+)
+
+// Match is an indexed module whose interface resembles the searched module
+type Match struct {
+	Path       string  `json:"path"`
+	Similarity float64 `json:"similarity"`
+	// Annotations of the matched module, e.g. its owner
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// Variables lists the variables declared by both modules with the same type
+	Variables []string `json:"variables,omitempty"`
+	// Outputs lists the outputs declared by both modules
+	Outputs []string `json:"outputs,omitempty"`
+}
+
+// FindSimilar compares the interface of module (variable names and types, output names)
+// with every module of the index and returns those with a Jaccard similarity of at least
+// minSimilarity, most similar first. Entries with the same fingerprint are skipped: they are
+// the module itself or identical copies, which Dedupe reports.
+func FindSimilar(module *Module, index *Index, minSimilarity float64) []*Match {
+	matches := []*Match{}
+	wanted := interfaceSet(module)
+
+	for _, candidate := range index.Modules {
+		if candidate.Fingerprint == module.Fingerprint {
+			continue
+		}
+
+		similarity := jaccard(wanted, interfaceSet(candidate))
+		if similarity < minSimilarity || similarity == 0 {
+			continue
+		}
+
+		match := &Match{Path: candidate.Path, Similarity: similarity, Annotations: candidate.Annotations}
+		for _, variable := range candidate.Variables {
+			if wanted["var:"+variable.Name+":"+variable.Type] {
+				match.Variables = append(match.Variables, variable.Name)
+			}
+		}
+		for _, output := range candidate.Outputs {
+			if wanted["output:"+output] {
+				match.Outputs = append(match.Outputs, output)
+			}
+		}
+		matches = append(matches, match)
+	}
+
+	slices.SortStableFunc(matches, func(a, b *Match) int {
+		if a.Similarity != b.Similarity {
+			if a.Similarity > b.Similarity {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.Path, b.Path)
+	})
+
+	return matches
+}
@@ -0,0 +1,294 @@
+// Package compat infers the minimum Terraform version a configuration needs from the language
+// features it uses, and compares it with the declared required_version.
+package compat
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser/schema"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// Feature is a language feature found in the configuration, at its first occurrence
+type Feature struct {
+	Name string `json:"name"`
+	// Since is the first Terraform version supporting the feature
+	Since string `json:"since"`
+	File  string `json:"file"`
+	Line  int    `json:"line"`
+}
+
+type Report struct {
+	// Minimum is the lowest Terraform version supporting every feature found; empty when none was found
+	Minimum string `json:"minimum,omitempty"`
+	// RequiredVersion is the combined required_version constraint of the terraform blocks
+	RequiredVersion string `json:"required_version,omitempty"`
+	// TooLoose is true when RequiredVersion allows versions older than Minimum
+	TooLoose bool       `json:"too_loose"`
+	Features []*Feature `json:"features"`
+}
+
+// functionVersions lists the built-in functions added after Terraform 0.12. The functions of the
+// built-in terraform provider only exist under their provider:: name; a bare encode_tfvars call is
+// an unknown function to Terraform, so it is not a feature either
+var functionVersions = map[string]string{
+	"sum":                                "0.13.0",
+	"alltrue":                            "0.14.0",
+	"anytrue":                            "0.14.0",
+	"textdecodebase64":                   "0.14.0",
+	"textencodebase64":                   "0.14.0",
+	"one":                                "0.15.0",
+	"sensitive":                          "0.15.0",
+	"nonsensitive":                       "0.15.0",
+	"optional":                           "1.3.0",
+	"startswith":                         "1.3.0",
+	"endswith":                           "1.3.0",
+	"timecmp":                            "1.3.0",
+	"strcontains":                        "1.5.0",
+	"plantimestamp":                      "1.5.0",
+	"issensitive":                        "1.8.0",
+	"provider::terraform::encode_tfvars": "1.8.0",
+	"provider::terraform::decode_tfvars": "1.8.0",
+	"provider::terraform::encode_expr":   "1.8.0",
+	"templatestring":                     "1.9.0",
+	"ephemeralasnull":                    "1.10.0",
+}
+
+type analyzer struct {
+	features map[string]*Feature
+}
+
+func (a *analyzer) add(name, since string, rng hcl.Range) {
+	if existing, ok := a.features[name]; ok && (existing.File < rng.Filename || existing.File == rng.Filename && existing.Line <= rng.Start.Line) {
+		return
+	}
+	a.features[name] = &Feature{Name: name, Since: since, File: rng.Filename, Line: rng.Start.Line}
+}
+
+// Analyze infers the minimum Terraform version of the configuration files of a workspace,
+// as returned by parser.Files, and checks it against the required_version of tfconfig
+func Analyze(files []*hcl.File, tfconfig *parser.TerraformConfig) *Report {
+	a := &analyzer{features: map[string]*Feature{}}
+	for _, file := range files {
+		for _, block := range file.Body.(*hclsyntax.Body).Blocks {
+			a.block(block)
+			a.functions(block.Body)
+		}
+	}
+
+	report := &Report{Features: []*Feature{}}
+	for _, feature := range a.features {
+		report.Features = append(report.Features, feature)
+		if report.Minimum == "" || compareVersions(feature.Since, report.Minimum) > 0 {
+			report.Minimum = feature.Since
+		}
+	}
+	slices.SortFunc(report.Features, func(x, y *Feature) int {
+		if c := compareVersions(y.Since, x.Since); c != 0 {
+			return c
+		}
+		return strings.Compare(x.Name, y.Name)
+	})
+
+	constraints := []string{}
+	for _, terraform := range tfconfig.Terraform {
+		if terraform.RequiredVersion != "" {
+			constraints = append(constraints, terraform.RequiredVersion)
+		}
+	}
+	report.RequiredVersion = strings.Join(constraints, ", ")
+
+	if report.Minimum != "" && report.RequiredVersion != "" {
+		if lowest, ok := lowestAllowed(report.RequiredVersion); ok {
+			report.TooLoose = compareVersions(lowest, report.Minimum) < 0
+		}
+	}
+
+	return report
+}
+
+func (a *analyzer) block(block *hclsyntax.Block) {
+	switch block.Type {
+	case "moved":
+		a.add("moved block", "1.1.0", block.TypeRange)
+	case "check":
+		a.add("check block", "1.5.0", block.TypeRange)
+	case "removed":
+		a.add("removed block", "1.7.0", block.TypeRange)
+	case "ephemeral":
+		a.add("ephemeral resource", "1.10.0", block.TypeRange)
+	case "import":
+		a.add("import block", "1.5.0", block.TypeRange)
+		if attr, ok := block.Body.Attributes["for_each"]; ok {
+			a.add("import for_each", "1.7.0", attr.NameRange)
+		}
+	case "terraform":
+		a.terraform(block)
+	case "variable":
+		a.variable(block)
+	case "output":
+		if attr, ok := block.Body.Attributes["ephemeral"]; ok {
+			a.add("ephemeral output", "1.10.0", attr.NameRange)
+		}
+		a.conditions(block.Body)
+	case "module":
+		for _, name := range []string{"count", "for_each", "depends_on"} {
+			if attr, ok := block.Body.Attributes[name]; ok {
+				a.add("module "+name, "0.13.0", attr.NameRange)
+			}
+		}
+	case "resource", "data":
+		a.writeOnly(block.Body)
+		for _, nested := range block.Body.Blocks {
+			if nested.Type == "lifecycle" {
+				a.conditions(nested.Body)
+				if attr, ok := nested.Body.Attributes["replace_triggered_by"]; ok {
+					a.add("replace_triggered_by", "1.2.0", attr.NameRange)
+				}
+			}
+		}
+	}
+}
+
+func (a *analyzer) terraform(block *hclsyntax.Block) {
+	for _, nested := range block.Body.Blocks {
+		switch nested.Type {
+		case "cloud":
+			a.add("cloud block", "1.1.0", nested.TypeRange)
+		case "required_providers":
+			for _, attr := range nested.Body.Attributes {
+				if _, ok := attr.Expr.(*hclsyntax.ObjectConsExpr); ok {
+					a.add("provider source addresses", "0.13.0", attr.NameRange)
+				}
+			}
+		}
+	}
+}
+
+func (a *analyzer) variable(block *hclsyntax.Block) {
+	for name, since := range map[string]string{"sensitive": "0.14.0", "nullable": "1.1.0", "ephemeral": "1.10.0"} {
+		if attr, ok := block.Body.Attributes[name]; ok {
+			a.add("variable "+name, since, attr.NameRange)
+		}
+	}
+
+	own := ""
+	if len(block.Labels) > 0 {
+		own = block.Labels[0]
+	}
+	for _, nested := range block.Body.Blocks {
+		if nested.Type != "validation" {
+			continue
+		}
+		a.add("variable validation", "0.13.0", nested.TypeRange)
+
+		condition, ok := nested.Body.Attributes["condition"]
+		if !ok {
+			continue
+		}
+		// Before 1.9 a condition could only refer to the variable itself
+		for _, traversal := range condition.Expr.Variables() {
+			if schema.ReferenceAddress(traversal) != "var."+own {
+				a.add("validation referencing other objects", "1.9.0", traversal.SourceRange())
+			}
+		}
+	}
+}
+
+// conditions finds custom condition blocks of outputs and lifecycle blocks
+func (a *analyzer) conditions(body *hclsyntax.Body) {
+	for _, nested := range body.Blocks {
+		if nested.Type == "precondition" || nested.Type == "postcondition" {
+			a.add(nested.Type+" block", "1.2.0", nested.TypeRange)
+		}
+	}
+}
+
+func (a *analyzer) writeOnly(body *hclsyntax.Body) {
+	for name, attr := range body.Attributes {
+		if strings.HasSuffix(name, "_wo") {
+			a.add("write-only argument", "1.11.0", attr.NameRange)
+		}
+	}
+	for _, nested := range body.Blocks {
+		a.writeOnly(nested.Body)
+	}
+}
+
+// functions finds the function calls of every expression in body
+func (a *analyzer) functions(body *hclsyntax.Body) {
+	hclsyntax.VisitAll(body, func(node hclsyntax.Node) hcl.Diagnostics {
+		call, ok := node.(*hclsyntax.FunctionCallExpr)
+		if !ok {
+			return nil
+		}
+
+		if since, ok := functionVersions[call.Name]; ok {
+			a.add(call.Name+" function", since, call.NameRange)
+		} else if strings.HasPrefix(call.Name, "provider::") {
+			a.add("provider-defined function", "1.8.0", call.NameRange)
+		}
+		return nil
+	})
+}
+
+var constraintPartRegex = regexp.MustCompile(`^(=|!=|>=|<=|>|<|~>)?\s*v?(\d+(?:\.\d+){0,2})(?:-[0-9A-Za-z.-]+)?$`)
+
+// lowestAllowed returns the lowest version a constraint allows, 0.0.0 when it has no lower bound
+func lowestAllowed(constraint string) (string, bool) {
+	lowest := "0.0.0"
+	for _, part := range strings.Split(constraint, ",") {
+		match := constraintPartRegex.FindStringSubmatch(strings.TrimSpace(part))
+		if match == nil {
+			return "", false
+		}
+
+		switch match[1] {
+		case "", "=", ">=", ">", "~>":
+			// > v allows the next patch release of v, which is older than any newer minimum
+			if compareVersions(match[2], lowest) > 0 {
+				lowest = match[2]
+			}
+		}
+	}
+	return lowest, true
+}
+
+// compareVersions compares two dotted versions, treating missing parts as zero
+func compareVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := range 3 {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+		if x != y {
+			return x - y
+		}
+	}
+	return 0
+}
+
+// String summarizes the report, e.g. for lint messages
+func (r *Report) String() string {
+	if r.Minimum == "" {
+		return "no version-specific features"
+	}
+	names := []string{}
+	for _, feature := range r.Features {
+		if feature.Since == r.Minimum {
+			names = append(names, feature.Name)
+		}
+	}
+	return fmt.Sprintf("Terraform %s (%s)", r.Minimum, strings.Join(names, ", "))
+}
@@ -0,0 +1,113 @@
+package compat
+
+import (
+	"testing"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/filesystem"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+	"github.com/spf13/afero"
+)
+
+func TestAnalyze(t *testing.T) {
+	memFs := afero.NewMemMapFs()
+	content := `terraform {
+  required_version = ">= 1.0"
+}
+
+variable "settings" {
+  type = object({
+    name = optional(string, "app")
+  })
+}
+
+variable "max" {
+  type = number
+
+  validation {
+    condition     = var.max > var.min
+    error_message = "max must be greater than min."
+  }
+}
+
+moved {
+  from = aws_s3_bucket.old
+  to   = aws_s3_bucket.this
+}
+
+resource "aws_s3_bucket" "this" {
+  bucket = startswith(var.settings.name, "x") ? "a" : "b"
+}
+
+locals {
+  tfvars = provider::terraform::encode_tfvars({ name = "app" })
+  bare   = encode_expr("app")
+}
+`
+	if err := afero.WriteFile(memFs, "main.tf", []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fs := filesystem.NewAferoAdapter(memFs)
+
+	p := parser.NewParser(fs, parser.Simple)
+	tfconfig, err := p.ParseTerraformWorkspace(".")
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	files, err := p.Files(".")
+	if err != nil {
+		t.Fatalf("Failed to load files: %v", err)
+	}
+
+	report := Analyze(files, tfconfig)
+
+	if report.Minimum != "1.9.0" {
+		t.Errorf("Expected minimum 1.9.0, got %s", report.Minimum)
+	}
+	if !report.TooLoose {
+		t.Errorf("Expected %q to be too loose for %s", report.RequiredVersion, report.Minimum)
+	}
+
+	since := map[string]string{}
+	for _, feature := range report.Features {
+		since[feature.Name] = feature.Since
+	}
+	expected := map[string]string{
+		"validation referencing other objects":        "1.9.0",
+		"optional function":                           "1.3.0",
+		"startswith function":                         "1.3.0",
+		"moved block":                                 "1.1.0",
+		"variable validation":                         "0.13.0",
+		"provider::terraform::encode_tfvars function": "1.8.0",
+	}
+	for name, version := range expected {
+		if since[name] != version {
+			t.Errorf("Expected feature %q since %s, got %q", name, version, since[name])
+		}
+	}
+	if _, ok := since["encode_expr function"]; ok {
+		t.Error("Expected a bare encode_expr call not to be a feature")
+	}
+	if report.Features[0].Name != "validation referencing other objects" || report.Features[0].Line != 15 {
+		t.Errorf("Expected the newest feature first with its line, got %+v", report.Features[0])
+	}
+}
+
+func TestLowestAllowed(t *testing.T) {
+	tests := []struct {
+		constraint string
+		lowest     string
+	}{
+		{">= 1.5.0", "1.5.0"},
+		{"~> 1.3", "1.3"},
+		{">= 1.2, < 2.0.0", "1.2"},
+		{"< 2.0", "0.0.0"},
+		{"1.6.2", "1.6.2"},
+	}
+
+	for _, tt := range tests {
+		lowest, ok := lowestAllowed(tt.constraint)
+		if !ok || compareVersions(lowest, tt.lowest) != 0 {
+			t.Errorf("%q: expected %s, got %s (ok %v)", tt.constraint, tt.lowest, lowest, ok)
+		}
+	}
+}
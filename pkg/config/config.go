@@ -0,0 +1,358 @@
+package config
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/sops"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultFileName is the config file looked up in the working directory when no path is given
+const DefaultFileName = ".tfparser.yaml"
+
+// DefaultYAML is the default configuration embedded in the binary: every setting, commented
+// out, with its default or an example. It is used when there is no config file.
+//
+//go:embed default.yaml
+var DefaultYAML []byte
+
+// Config is the user configuration shared by all subcommands
+type Config struct {
+	// Rules overrides lint and policy rules keyed by rule ID
+	Rules map[string]*RuleConfig `yaml:"rules"`
+	// Deprecations extends the built-in knowledge base of deprecated resource types
+	Deprecations []*Deprecation `yaml:"deprecations"`
+	// Descriptions tunes the description-* lint rules
+	Descriptions *DescriptionPolicy `yaml:"descriptions"`
+	// Naming sets the naming rules of the naming-convention lint rule
+	Naming *NamingPolicy `yaml:"naming"`
+	// Score sets the weights of the health score metrics
+	Score *ScorePolicy `yaml:"score"`
+	// Fetch sets the budgets of remote fetches
+	Fetch *FetchPolicy `yaml:"fetch"`
+	// Credentials maps git hosts, e.g. github.com, to the credentials used to clone from them.
+	// The config file may be encrypted with SOPS to commit them safely.
+	Credentials map[string]*Credential `yaml:"credentials"`
+	// Telemetry opts in to anonymous usage metrics; disabled by default
+	Telemetry *TelemetryPolicy `yaml:"telemetry"`
+	// Profiles are named sources run with the profile command, e.g. vpc-module
+	Profiles map[string]*Profile `yaml:"profiles"`
+	// Diff sets the changes the diff command ignores
+	Diff *DiffPolicy `yaml:"diff"`
+}
+
+// DiffPolicy configures the diff command
+type DiffPolicy struct {
+	Ignore *DiffIgnore `yaml:"ignore"`
+}
+
+// DiffIgnore lists changes that never appear in diff reports, e.g. cosmetic edits
+type DiffIgnore struct {
+	// Attributes are changed attributes to disregard, e.g. description
+	Attributes []string `yaml:"attributes"`
+	// Addresses are glob patterns of blocks to leave out, e.g. var.debug_*
+	Addresses []string `yaml:"addresses"`
+}
+
+// Profile is a saved source with the settings of its runs
+type Profile struct {
+	// Type is local or git
+	Type string `yaml:"type"`
+	// URL is the repository of git profiles
+	URL string `yaml:"url"`
+	// Path is the directory or bundle of local profiles
+	Path   string `yaml:"path"`
+	Ref    string `yaml:"ref"`
+	SubDir string `yaml:"subdir"`
+	// Format is the output format unless --format is given (json, markdown, template)
+	Format string `yaml:"format"`
+}
+
+// Target returns the URL of git profiles and the path of local ones
+func (p *Profile) Target() string {
+	if p.Type == "git" {
+		return p.URL
+	}
+	return p.Path
+}
+
+// TelemetryPolicy configures the anonymous usage metrics sent after each command, e.g. to the
+// collector of an internal deployment. Nothing is sent unless enabled and an endpoint is set.
+type TelemetryPolicy struct {
+	// Enabled opts in for every run; --telemetry opts in for a single run
+	Enabled bool `yaml:"enabled"`
+	// Endpoint receives each event as a JSON POST request
+	Endpoint string `yaml:"endpoint"`
+}
+
+// Credential authenticates clones from a git host
+type Credential struct {
+	// Username defaults to the one the host expects for tokens
+	Username string `yaml:"username"`
+	Token    string `yaml:"token"`
+}
+
+// RuleConfig customizes a single lint or policy rule
+type RuleConfig struct {
+	// Severity overrides the rule's default severity (error, warning, info, off)
+	Severity string `yaml:"severity"`
+	// Exemptions lists subject addresses (glob patterns) the rule does not apply to, e.g. module.legacy_*
+	Exemptions []string `yaml:"exemptions"`
+}
+
+// Deprecation describes a deprecated or renamed resource or data source type
+type Deprecation struct {
+	Type string `yaml:"type"`
+	// Kind is "resource" or "data"; empty applies to both
+	Kind        string `yaml:"kind"`
+	Replacement string `yaml:"replacement"`
+	Note        string `yaml:"note"`
+}
+
+// DescriptionPolicy configures the quality checks of variable and output descriptions
+type DescriptionPolicy struct {
+	// MinLength is the minimum number of characters of a description (default 10)
+	MinLength int `yaml:"min_length"`
+	// TrailingPeriod is "forbid" (default), "require" or "ignore"
+	TrailingPeriod string `yaml:"trailing_period"`
+	// Denylist lists words or phrases that must not appear, matched case-insensitively
+	Denylist []string `yaml:"denylist"`
+}
+
+// NamingPolicy groups naming rules in profiles, e.g. one per team or repository type
+type NamingPolicy struct {
+	// Profile selects the profile to check; snake_case is built in
+	Profile  string                   `yaml:"profile"`
+	Profiles map[string][]*NamingRule `yaml:"profiles"`
+}
+
+// NamingRule is a regular expression the names of a block type must match
+type NamingRule struct {
+	// Block is variable, output, resource, data, module or local
+	Block string `yaml:"block"`
+	// Pattern is matched against the block name. {key} is replaced by the module annotation
+	// key, e.g. {environment}; {component} defaults to the name of the module directory.
+	Pattern string `yaml:"pattern"`
+	// Severity of the findings (error, warning, info); the severity of the rule by default
+	Severity string `yaml:"severity"`
+	// Message replaces the default finding message
+	Message string `yaml:"message"`
+}
+
+// NamingBlocks are the block types naming rules apply to
+var NamingBlocks = []string{"variable", "output", "resource", "data", "module", "local"}
+
+// builtinNamingProfiles are available without configuration
+var builtinNamingProfiles = map[string][]*NamingRule{
+	"snake_case": {
+		{Block: "variable", Pattern: `^[a-z][a-z0-9_]*$`},
+		{Block: "output", Pattern: `^[a-z][a-z0-9_]*$`},
+		{Block: "resource", Pattern: `^[a-z][a-z0-9_]*$`},
+		{Block: "data", Pattern: `^[a-z][a-z0-9_]*$`},
+		{Block: "module", Pattern: `^[a-z][a-z0-9_]*$`},
+		{Block: "local", Pattern: `^[a-z][a-z0-9_]*$`},
+	},
+}
+
+// ScorePolicy configures the workspace health score
+type ScorePolicy struct {
+	// Weights maps metric names to their relative weight; a weight of 0 disables the metric
+	Weights map[string]float64 `yaml:"weights"`
+}
+
+// FetchPolicy limits the fetches of remote sources per host, e.g. to stay below the abuse
+// detection thresholds of GitHub or GitLab when parsing a manifest of many repositories
+type FetchPolicy struct {
+	// Concurrency is the maximum number of simultaneous fetches per host; 0 means no limit
+	Concurrency int `yaml:"concurrency"`
+	// Interval is the minimum delay between two fetches from a host, e.g. 500ms
+	Interval time.Duration `yaml:"interval"`
+	// Hosts overrides the budget of individual hosts, e.g. github.com
+	Hosts map[string]*HostBudget `yaml:"hosts"`
+}
+
+// HostBudget is the fetch budget of a single host
+type HostBudget struct {
+	Concurrency int           `yaml:"concurrency"`
+	Interval    time.Duration `yaml:"interval"`
+}
+
+// Load reads the config file at path. An empty path falls back to DefaultFileName
+// and a missing default file yields the embedded default configuration. Files encrypted with
+// SOPS are decrypted with the age keys of SOPS_AGE_KEY or SOPS_AGE_KEY_FILE.
+func Load(path string) (*Config, error) {
+	explicit := path != ""
+	if !explicit {
+		path = DefaultFileName
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if !explicit && errors.Is(err, os.ErrNotExist) {
+			return Parse(DefaultYAML, "embedded default")
+		}
+		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+	return Parse(content, path)
+}
+
+// Parse reads a configuration from content; name names it in errors
+func Parse(content []byte, name string) (*Config, error) {
+	doc := &yaml.Node{}
+	if err := yaml.Unmarshal(content, doc); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", name, err)
+	}
+
+	cfg := &Config{}
+	if doc.Kind == 0 {
+		// Empty file
+		return cfg, nil
+	}
+
+	if sops.IsEncrypted(doc) {
+		identities, err := sops.IdentitiesFromEnv()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt config file %s: %w", name, err)
+		}
+		if err := sops.Decrypt(doc, identities); err != nil {
+			return nil, fmt.Errorf("failed to decrypt config file %s: %w", name, err)
+		}
+	}
+
+	if err := doc.Decode(cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", name, err)
+	}
+
+	return cfg, nil
+}
+
+// Rule returns the override for ruleID, or nil when the rule is not configured
+func (c *Config) Rule(ruleID string) *RuleConfig {
+	if c == nil || c.Rules == nil {
+		return nil
+	}
+	return c.Rules[ruleID]
+}
+
+// DescriptionPolicy returns the configured description policy with defaults applied
+func (c *Config) DescriptionPolicy() *DescriptionPolicy {
+	policy := &DescriptionPolicy{}
+	if c != nil && c.Descriptions != nil {
+		*policy = *c.Descriptions
+	}
+
+	if policy.MinLength <= 0 {
+		policy.MinLength = 10
+	}
+	if policy.TrailingPeriod == "" {
+		policy.TrailingPeriod = "forbid"
+	}
+
+	return policy
+}
+
+// NamingRules returns the rules of the selected naming profile, or nil when no profile is selected
+func (c *Config) NamingRules() ([]*NamingRule, error) {
+	if c == nil || c.Naming == nil || c.Naming.Profile == "" {
+		return nil, nil
+	}
+
+	rules, ok := c.Naming.Profiles[c.Naming.Profile]
+	if !ok {
+		if rules, ok = builtinNamingProfiles[c.Naming.Profile]; !ok {
+			return nil, fmt.Errorf("unknown naming profile %q", c.Naming.Profile)
+		}
+	}
+
+	for i, rule := range rules {
+		if !slices.Contains(NamingBlocks, rule.Block) {
+			return nil, fmt.Errorf("naming rule %d of profile %s: unknown block %q (expected one of %s)", i+1, c.Naming.Profile, rule.Block, strings.Join(NamingBlocks, ", "))
+		}
+		if _, err := regexp.Compile(rule.Pattern); err != nil {
+			return nil, fmt.Errorf("naming rule %d of profile %s: %w", i+1, c.Naming.Profile, err)
+		}
+	}
+	return rules, nil
+}
+
+// ScoreWeights returns the configured weights of the score metrics, falling back to defaults
+// for metrics that are not configured
+func (c *Config) ScoreWeights(defaults map[string]float64) map[string]float64 {
+	weights := maps.Clone(defaults)
+	if c != nil && c.Score != nil {
+		maps.Copy(weights, c.Score.Weights)
+	}
+	return weights
+}
+
+// FetchPolicy returns the configured fetch budgets, or an empty policy without limits
+func (c *Config) FetchPolicy() *FetchPolicy {
+	if c == nil || c.Fetch == nil {
+		return &FetchPolicy{}
+	}
+	return c.Fetch
+}
+
+// TelemetryPolicy returns the configured telemetry, or a disabled policy
+func (c *Config) TelemetryPolicy() *TelemetryPolicy {
+	if c == nil || c.Telemetry == nil {
+		return &TelemetryPolicy{}
+	}
+	return c.Telemetry
+}
+
+// ProfileNames returns the names of the configured profiles in order
+func (c *Config) ProfileNames() []string {
+	if c == nil {
+		return nil
+	}
+	return slices.Sorted(maps.Keys(c.Profiles))
+}
+
+// Profile returns the profile named name after checking that it names a source
+func (c *Config) Profile(name string) (*Profile, error) {
+	var profile *Profile
+	if c != nil {
+		profile = c.Profiles[name]
+	}
+	if profile == nil {
+		if names := c.ProfileNames(); len(names) > 0 {
+			return nil, fmt.Errorf("unknown profile %q (configured: %s)", name, strings.Join(names, ", "))
+		}
+		return nil, fmt.Errorf("unknown profile %q (no profiles are configured)", name)
+	}
+
+	switch profile.Type {
+	case "git":
+		if profile.URL == "" {
+			return nil, fmt.Errorf("profile %s: git profiles need a url", name)
+		}
+	case "local":
+		if profile.Path == "" {
+			return nil, fmt.Errorf("profile %s: local profiles need a path", name)
+		}
+		if profile.Ref != "" {
+			return nil, fmt.Errorf("profile %s: ref is only supported by git profiles", name)
+		}
+	default:
+		return nil, fmt.Errorf("profile %s: unknown type %q (expected local or git)", name, profile.Type)
+	}
+	return profile, nil
+}
+
+// DiffIgnore returns the configured diff ignore list, or an empty one
+func (c *Config) DiffIgnore() *DiffIgnore {
+	if c == nil || c.Diff == nil || c.Diff.Ignore == nil {
+		return &DiffIgnore{}
+	}
+	return c.Diff.Ignore
+}
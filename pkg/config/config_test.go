@@ -0,0 +1,82 @@
+package config
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestProfiles(t *testing.T) {
+	cfg, err := Parse([]byte(`profiles:
+  vpc-module:
+    type: git
+    url: https://github.com/terraform-aws-modules/terraform-aws-vpc
+    ref: v3.0.0
+    format: markdown
+  network:
+    type: local
+    path: ./stacks/network
+    subdir: prod
+  no-url:
+    type: git
+  no-path:
+    type: local
+  local-ref:
+    type: local
+    path: .
+    ref: main
+  registry:
+    type: registry
+    path: hashicorp/consul/aws
+`), "test")
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	expectedNames := []string{"local-ref", "network", "no-path", "no-url", "registry", "vpc-module"}
+	if names := cfg.ProfileNames(); !slices.Equal(names, expectedNames) {
+		t.Errorf("Expected profile names %v, got %v", expectedNames, names)
+	}
+
+	tests := []struct {
+		name   string
+		target string
+		err    string
+	}{
+		{name: "vpc-module", target: "https://github.com/terraform-aws-modules/terraform-aws-vpc"},
+		{name: "network", target: "./stacks/network"},
+		{name: "no-url", err: "git profiles need a url"},
+		{name: "no-path", err: "local profiles need a path"},
+		{name: "local-ref", err: "ref is only supported by git profiles"},
+		{name: "registry", err: `unknown type "registry"`},
+		{name: "vpc", err: `unknown profile "vpc" (configured: local-ref, network,`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile, err := cfg.Profile(tt.name)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("Expected error containing %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if profile.Target() != tt.target {
+				t.Errorf("Expected target %s, got %s", tt.target, profile.Target())
+			}
+		})
+	}
+}
+
+func TestProfileWithoutProfiles(t *testing.T) {
+	var cfg *Config
+	if names := cfg.ProfileNames(); names != nil {
+		t.Errorf("Expected no profile names, got %v", names)
+	}
+	if _, err := (&Config{}).Profile("vpc"); err == nil || !strings.Contains(err.Error(), "no profiles are configured") {
+		t.Errorf("Expected an error naming the missing profiles, got %v", err)
+	}
+}
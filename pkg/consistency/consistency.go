@@ -0,0 +1,184 @@
+// Package consistency compares the variable declarations and module calls of root configurations
+// that should stay aligned, e.g. the dev, stage and prod folders of an environment-per-folder
+// repository.
+package consistency
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/source"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/usage"
+)
+
+type IssueKind string
+
+const (
+	// IssueMissingVariable is a variable declared by some roots only
+	IssueMissingVariable IssueKind = "missing_variable"
+	// IssueTypeMismatch is a variable declared with different types
+	IssueTypeMismatch IssueKind = "type_mismatch"
+	// IssueMissingModule is a module call made by some roots only
+	IssueMissingModule IssueKind = "missing_module"
+	// IssueSourceMismatch is a module call to different modules
+	IssueSourceMismatch IssueKind = "source_mismatch"
+	// IssueVersionMismatch is a module call to different versions of a module, which is expected
+	// while a change is promoted from one environment to the next
+	IssueVersionMismatch IssueKind = "version_mismatch"
+	// IssueMissingArgument is a module call argument set by some roots only
+	IssueMissingArgument IssueKind = "missing_argument"
+)
+
+// Root is a named root configuration
+type Root struct {
+	Name   string
+	Config *parser.TerraformConfig
+}
+
+// Issue is an inconsistency of one variable, module call or argument across the roots
+type Issue struct {
+	Kind    IssueKind `json:"kind"`
+	Address string    `json:"address"`
+	// Values maps the roots to their type, source or version when these differ
+	Values map[string]string `json:"values,omitempty"`
+	// Missing lists the roots lacking the variable, module call or argument
+	Missing []string `json:"missing,omitempty"`
+}
+
+type Report struct {
+	Roots  []string `json:"roots"`
+	Issues []*Issue `json:"issues"`
+}
+
+// Check compares the roots with each other
+func Check(roots []*Root) *Report {
+	report := &Report{Roots: []string{}, Issues: []*Issue{}}
+	for _, root := range roots {
+		report.Roots = append(report.Roots, root.Name)
+	}
+
+	checkVariables(report, roots)
+	checkModules(report, roots)
+
+	slices.SortStableFunc(report.Issues, func(a, b *Issue) int { return strings.Compare(a.Address, b.Address) })
+	return report
+}
+
+func checkVariables(report *Report, roots []*Root) {
+	types := map[string]map[string]string{}
+	for _, root := range roots {
+		for _, variable := range root.Config.Variables {
+			if types[variable.Name] == nil {
+				types[variable.Name] = map[string]string{}
+			}
+			typ := variable.Type
+			if typ == "" {
+				typ = "any"
+			}
+			types[variable.Name][root.Name] = typ
+		}
+	}
+
+	for name, byRoot := range types {
+		address := "var." + name
+		if missing := missingRoots(roots, byRoot); len(missing) > 0 {
+			report.Issues = append(report.Issues, &Issue{Kind: IssueMissingVariable, Address: address, Missing: missing})
+		}
+		if differ(byRoot, func(typ string) string { return strings.Join(strings.Fields(typ), "") }) {
+			report.Issues = append(report.Issues, &Issue{Kind: IssueTypeMismatch, Address: address, Values: byRoot})
+		}
+	}
+}
+
+func checkModules(report *Report, roots []*Root) {
+	sources := map[string]map[string]string{}
+	versions := map[string]map[string]string{}
+	arguments := map[string]map[string]map[string]bool{}
+	for _, root := range roots {
+		for _, call := range root.Config.Modules {
+			if sources[call.Name] == nil {
+				sources[call.Name] = map[string]string{}
+				versions[call.Name] = map[string]string{}
+				arguments[call.Name] = map[string]map[string]bool{}
+			}
+
+			src := source.ParseModuleSource(call.Source)
+			address, version := call.Source, call.Version
+			if src.Kind != source.ModuleSourceLocal {
+				address = usage.NormalizeSource(src)
+			}
+			if src.Kind == source.ModuleSourceGit {
+				version = src.Ref
+			}
+			if version == "" {
+				version = usage.Unversioned
+			}
+			sources[call.Name][root.Name] = address
+			versions[call.Name][root.Name] = version
+
+			for _, argument := range call.Arguments {
+				if arguments[call.Name][argument] == nil {
+					arguments[call.Name][argument] = map[string]bool{}
+				}
+				arguments[call.Name][argument][root.Name] = true
+			}
+		}
+	}
+
+	for name, byRoot := range sources {
+		address := "module." + name
+		if missing := missingRoots(roots, byRoot); len(missing) > 0 {
+			report.Issues = append(report.Issues, &Issue{Kind: IssueMissingModule, Address: address, Missing: missing})
+		}
+		if differ(byRoot, nil) {
+			// Different modules make version and argument differences meaningless
+			report.Issues = append(report.Issues, &Issue{Kind: IssueSourceMismatch, Address: address, Values: byRoot})
+			continue
+		}
+		if differ(versions[name], nil) {
+			report.Issues = append(report.Issues, &Issue{Kind: IssueVersionMismatch, Address: address, Values: versions[name]})
+		}
+
+		for argument, setBy := range arguments[name] {
+			missing := []string{}
+			for _, root := range roots {
+				if _, calls := byRoot[root.Name]; calls && !setBy[root.Name] {
+					missing = append(missing, root.Name)
+				}
+			}
+			if len(missing) > 0 {
+				report.Issues = append(report.Issues, &Issue{Kind: IssueMissingArgument, Address: fmt.Sprintf("%s.%s", address, argument), Missing: missing})
+			}
+		}
+	}
+}
+
+// missingRoots returns the roots, in order, that have no value in byRoot
+func missingRoots(roots []*Root, byRoot map[string]string) []string {
+	missing := []string{}
+	for _, root := range roots {
+		if _, ok := byRoot[root.Name]; !ok {
+			missing = append(missing, root.Name)
+		}
+	}
+	return missing
+}
+
+// differ reports whether the values of byRoot are not all the same, after normalize if given
+func differ(byRoot map[string]string, normalize func(string) string) bool {
+	seen := ""
+	first := true
+	for _, value := range byRoot {
+		if normalize != nil {
+			value = normalize(value)
+		}
+		if first {
+			seen, first = value, false
+		} else if value != seen {
+			return true
+		}
+	}
+	return false
+}
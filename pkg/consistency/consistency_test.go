@@ -0,0 +1,59 @@
+package consistency
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser/schema"
+)
+
+func TestCheck(t *testing.T) {
+	dev := &parser.TerraformConfig{
+		Variables: []*schema.Variable{{Name: "region", Type: "string"}, {Name: "azs", Type: "list(string)"}},
+		Modules: []*schema.Module{
+			{Name: "vpc", Source: "git::https://github.com/owner/modules.git//vpc?ref=v2", Arguments: []string{"cidr"}},
+			{Name: "app", Source: "../../modules/app", Arguments: []string{"name"}},
+		},
+	}
+	stage := &parser.TerraformConfig{
+		Variables: []*schema.Variable{{Name: "region", Type: "string"}, {Name: "azs", Type: "list( string )"}},
+		Modules: []*schema.Module{
+			{Name: "vpc", Source: "github.com/owner/modules//vpc?ref=v1", Arguments: []string{"cidr", "flow_logs"}},
+			{Name: "app", Source: "../../modules/app", Arguments: []string{"name"}},
+		},
+	}
+	prod := &parser.TerraformConfig{
+		Variables: []*schema.Variable{{Name: "region", Type: "string"}, {Name: "azs", Type: "number"}, {Name: "alerting", Type: "bool"}},
+		Modules: []*schema.Module{
+			{Name: "vpc", Source: "github.com/owner/modules//vpc?ref=v1", Arguments: []string{"cidr", "flow_logs"}},
+			{Name: "app", Source: "../../modules/app-ha", Arguments: []string{"name", "replicas"}},
+		},
+	}
+
+	report := Check([]*Root{{Name: "dev", Config: dev}, {Name: "stage", Config: stage}, {Name: "prod", Config: prod}})
+
+	expected := []struct {
+		kind    IssueKind
+		address string
+		missing []string
+	}{
+		{IssueSourceMismatch, "module.app", nil},
+		{IssueVersionMismatch, "module.vpc", nil},
+		{IssueMissingArgument, "module.vpc.flow_logs", []string{"dev"}},
+		{IssueMissingVariable, "var.alerting", []string{"dev", "stage"}},
+		{IssueTypeMismatch, "var.azs", nil},
+	}
+	if len(report.Issues) != len(expected) {
+		t.Fatalf("Expected %d issues, got %d: %+v", len(expected), len(report.Issues), report.Issues)
+	}
+	for i, want := range expected {
+		issue := report.Issues[i]
+		if issue.Kind != want.kind || issue.Address != want.address || !slices.Equal(issue.Missing, want.missing) {
+			t.Errorf("Issue %d: expected %s %s missing %v, got %+v", i, want.kind, want.address, want.missing, issue)
+		}
+	}
+	if report.Issues[4].Values["prod"] != "number" {
+		t.Errorf("Expected the type of prod, got %v", report.Issues[4].Values)
+	}
+}
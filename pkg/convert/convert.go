@@ -0,0 +1,273 @@
+package convert
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/filesystem"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/jsonsyntax"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/spf13/afero"
+)
+
+type Format string
+
+const (
+	// FormatJSON is the Terraform JSON syntax (.tf.json)
+	FormatJSON Format = "json"
+	// FormatHCL is the Terraform native syntax (.tf)
+	FormatHCL Format = "hcl"
+)
+
+const (
+	hclExt  = ".tf"
+	jsonExt = ".tf.json"
+)
+
+// Workspace converts every configuration file of dir into the requested format.
+// The result maps output file names to their content: *.tf files become *.tf.json
+// when converting to JSON, and *.tf.json files become *.tf when converting to HCL.
+func Workspace(fs filesystem.FileReader, dir string, to Format) (map[string][]byte, error) {
+	var fromExt, toExt string
+	switch to {
+	case FormatJSON:
+		fromExt, toExt = hclExt, jsonExt
+	case FormatHCL:
+		fromExt, toExt = jsonExt, hclExt
+	default:
+		return nil, fmt.Errorf("unsupported format %q (expected json or hcl)", to)
+	}
+
+	entries, err := fs.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory %s: %w", dir, err)
+	}
+
+	hclParser := hclparse.NewParser()
+	converted := map[string][]byte{}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !hasExt(name, fromExt) {
+			continue
+		}
+
+		filename := filepath.Join(dir, name)
+		content, err := fs.ReadFile(filename)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", filename, err)
+		}
+
+		var out []byte
+		if to == FormatJSON {
+			file, diags := hclParser.ParseHCL(content, filename)
+			if diags.HasErrors() {
+				return nil, fmt.Errorf("failed to parse %s: %w", filename, diags)
+			}
+			out, err = jsonsyntax.ToJSON(file)
+		} else {
+			out, err = jsonsyntax.ToHCL(content, filename)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert %s: %w", filename, err)
+		}
+
+		converted[strings.TrimSuffix(name, fromExt)+toExt] = out
+	}
+
+	return converted, nil
+}
+
+// hasExt matches the exact configuration extension, so that .tf does not match .tf.json
+func hasExt(name, ext string) bool {
+	if ext == hclExt {
+		return filepath.Ext(name) == hclExt
+	}
+	return strings.HasSuffix(name, ext)
+}
+
+// Verify checks that the converted files describe the same configuration as the files of dir
+// they were converted from: both are parsed, in either syntax, and compared field by field. Other
+// configuration files of dir are left out, so that mixed workspaces compare the converted files
+// only. Raw expressions compare equal when they only differ in layout, see sameExpression.
+func Verify(fs filesystem.FileReader, dir string, converted map[string][]byte, to Format) error {
+	fromExt := hclExt
+	switch to {
+	case FormatJSON:
+	case FormatHCL:
+		fromExt = jsonExt
+	default:
+		return fmt.Errorf("unsupported format %q (expected json or hcl)", to)
+	}
+
+	sources := map[string][]byte{}
+	entries, err := fs.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read directory %s: %w", dir, err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !hasExt(entry.Name(), fromExt) {
+			continue
+		}
+		if sources[entry.Name()], err = fs.ReadFile(filepath.Join(dir, entry.Name())); err != nil {
+			return fmt.Errorf("failed to read %s: %w", entry.Name(), err)
+		}
+	}
+
+	want, err := parseFiles(sources)
+	if err != nil {
+		return fmt.Errorf("failed to parse source workspace: %w", err)
+	}
+	got, err := parseFiles(converted)
+	if err != nil {
+		return fmt.Errorf("failed to parse converted workspace: %w", err)
+	}
+
+	wantTree, err := tree(want)
+	if err != nil {
+		return err
+	}
+	gotTree, err := tree(got)
+	if err != nil {
+		return err
+	}
+	if path, ok := equal(wantTree, gotTree, ""); !ok {
+		return fmt.Errorf("conversion changed the configuration at %s", path)
+	}
+
+	return nil
+}
+
+// parseFiles parses configuration files, in either syntax, from memory
+func parseFiles(files map[string][]byte) (*parser.TerraformConfig, error) {
+	memFs := afero.NewMemMapFs()
+	for name, content := range files {
+		if err := afero.WriteFile(memFs, filepath.Join("/", name), content, 0o644); err != nil {
+			return nil, err
+		}
+	}
+	return parser.NewParser(filesystem.NewAferoAdapter(memFs), parser.Detail).ParseTerraformWorkspace("/")
+}
+
+// tree returns the JSON form of a configuration as nested maps, slices and values
+func tree(config *parser.TerraformConfig) (any, error) {
+	summary, err := config.Summary(false)
+	if err != nil {
+		return nil, err
+	}
+	var v any
+	decoder := json.NewDecoder(bytes.NewReader(summary))
+	decoder.UseNumber()
+	if err := decoder.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// equal compares two JSON trees and returns the path of the first difference
+func equal(want, got any, path string) (string, bool) {
+	switch w := want.(type) {
+	case map[string]any:
+		g, ok := got.(map[string]any)
+		if !ok {
+			return path, false
+		}
+		for _, key := range slices.Sorted(maps.Keys(w)) {
+			if p, ok := equal(w[key], g[key], path+"."+key); !ok {
+				return p, false
+			}
+		}
+		for key := range g {
+			if _, ok := w[key]; !ok {
+				return path + "." + key, false
+			}
+		}
+		return "", true
+	case []any:
+		g, ok := got.([]any)
+		if !ok || len(w) != len(g) {
+			return path, false
+		}
+		for i := range w {
+			if p, ok := equal(w[i], g[i], fmt.Sprintf("%s[%d]", path, i)); !ok {
+				return p, false
+			}
+		}
+		return "", true
+	case string:
+		g, ok := got.(string)
+		if !ok || (w != g && !sameExpression(w, g)) {
+			return fmt.Sprintf("%s (before %q, after %q)", path, w, g), false
+		}
+		return "", true
+	default:
+		if want != got {
+			return fmt.Sprintf("%s (before %v, after %v)", path, want, got), false
+		}
+		return "", true
+	}
+}
+
+// sameExpression reports whether two raw expressions, as the parser keeps them for values that are
+// not literals, only differ in layout: whitespace, and line breaks or commas between items (e.g.
+// a one-line object and the same object with an item per line). Strings that are not both valid
+// expressions, such as literal descriptions, never compare equal this way.
+func sameExpression(a, b string) bool {
+	ta, ok := expressionTokens(a)
+	if !ok {
+		return false
+	}
+	tb, ok := expressionTokens(b)
+	return ok && slices.Equal(ta, tb)
+}
+
+// expressionTokens lexes a valid expression into its tokens, with every run of line breaks and
+// commas turned into one separator and separators next to brackets dropped
+func expressionTokens(src string) ([]string, bool) {
+	if _, diags := hclsyntax.ParseExpression([]byte(src), "", hcl.InitialPos); diags.HasErrors() {
+		return nil, false
+	}
+	tokens, diags := hclsyntax.LexExpression([]byte(src), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, false
+	}
+
+	// The separator is no token text
+	const separator = "\x00"
+	result := []string{}
+	for _, token := range tokens {
+		text := string(token.Bytes)
+		switch token.Type {
+		case hclsyntax.TokenEOF:
+			continue
+		case hclsyntax.TokenComment:
+			if !strings.HasSuffix(text, "\n") {
+				continue
+			}
+			text = separator
+		case hclsyntax.TokenNewline, hclsyntax.TokenComma:
+			text = separator
+		case hclsyntax.TokenCBrace, hclsyntax.TokenCBrack, hclsyntax.TokenCParen:
+			if len(result) > 0 && result[len(result)-1] == separator {
+				result = result[:len(result)-1]
+			}
+		}
+		if text == separator && (len(result) == 0 || slices.Contains([]string{separator, "{", "[", "("}, result[len(result)-1])) {
+			continue
+		}
+		result = append(result, text)
+	}
+	if len(result) > 0 && result[len(result)-1] == separator {
+		result = result[:len(result)-1]
+	}
+	return result, true
+}
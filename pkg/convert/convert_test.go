@@ -0,0 +1,237 @@
+package convert
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/filesystem"
+
+	"github.com/spf13/afero"
+)
+
+const roundTripConfig = `
+variable "name" {
+  type        = object({ a = string, b = list(number) })
+  description = "Name ${"$"}{x}"
+  default     = { a = "x", b = [1, 2.5] }
+
+  validation {
+    condition     = length(var.name.a) > 0
+    error_message = "Must be set."
+  }
+}
+
+variable "offset" {
+  type    = number
+  default = -1
+}
+
+locals {
+  prefix = "app-${var.name.a}"
+  steps  = [-2, 0.5, !false]
+  items  = [{ a = 1 }, { a = 2 }]
+  greet  = "%{ if var.name.a != "" }hello%{ endif }"
+  escape = "literal $${not} interpolated"
+}
+
+resource "aws_instance" "web" {
+  count = 2
+  ami   = data.aws_ami.x.id
+  tags = {
+    Name  = local.prefix
+    "x.y" = "z"
+  }
+
+  ebs_block_device {
+    device_name = "/dev/sda"
+  }
+  ebs_block_device {
+    device_name = "/dev/sdb"
+  }
+
+  dynamic "ingress" {
+    for_each = var.name.b
+    content {
+      port = ingress.value
+    }
+  }
+
+  lifecycle {
+    ignore_changes = [tags]
+  }
+
+  depends_on = [aws_s3_bucket.b]
+  provider   = aws.west
+}
+
+data "aws_ami" "x" {
+  most_recent = true
+}
+
+module "net" {
+  source    = "./net"
+  providers = { aws = aws.west }
+}
+
+output "ip" {
+  value = aws_instance.web[0].private_ip
+}
+
+import {
+  to = aws_instance.web[0]
+  id = "i-123"
+}
+`
+
+func TestRoundTrip(t *testing.T) {
+	memFs := afero.NewMemMapFs()
+	if err := afero.WriteFile(memFs, "/src/main.tf", []byte(roundTripConfig), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	fs := filesystem.NewAferoAdapter(memFs)
+
+	asJSON, err := Workspace(fs, "/src", FormatJSON)
+	if err != nil {
+		t.Fatalf("convert to json: %v", err)
+	}
+	if err := Verify(fs, "/src", asJSON, FormatJSON); err != nil {
+		t.Fatalf("verify json: %v", err)
+	}
+
+	content := string(asJSON["main.tf.json"])
+	for _, want := range []string{`"depends_on": [`, `"aws_s3_bucket.b"`, `"provider": "aws.west"`, `"ami": "${data.aws_ami.x.id}"`, `"escape": "literal $${not} interpolated"`, `"default": -1`, `-2,`} {
+		if !strings.Contains(content, want) {
+			t.Errorf("json output is missing %s:\n%s", want, content)
+		}
+	}
+
+	if err := afero.WriteFile(memFs, "/json/main.tf.json", asJSON["main.tf.json"], 0o644); err != nil {
+		t.Fatal(err)
+	}
+	asHCL, err := Workspace(fs, "/json", FormatHCL)
+	if err != nil {
+		t.Fatalf("convert to hcl: %v", err)
+	}
+	if err := Verify(fs, "/json", asHCL, FormatHCL); err != nil {
+		t.Fatalf("verify hcl: %v", err)
+	}
+
+	if err := afero.WriteFile(memFs, "/hcl/main.tf", asHCL["main.tf"], 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := Verify(fs, "/hcl", asJSON, FormatJSON); err != nil {
+		t.Errorf("converted hcl differs from source: %v", err)
+	}
+}
+
+func TestVerifyDetectsChanges(t *testing.T) {
+	memFs := afero.NewMemMapFs()
+	if err := afero.WriteFile(memFs, "/src/main.tf", []byte(roundTripConfig), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	fs := filesystem.NewAferoAdapter(memFs)
+
+	asJSON, err := Workspace(fs, "/src", FormatJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	asJSON["main.tf.json"] = []byte(strings.Replace(string(asJSON["main.tf.json"]), "aws_s3_bucket.b", "aws_s3_bucket.c", 1))
+
+	if err := Verify(fs, "/src", asJSON, FormatJSON); err == nil {
+		t.Error("expected verification to fail for a changed dependency")
+	}
+}
+
+func TestVerifyStrings(t *testing.T) {
+	memFs := afero.NewMemMapFs()
+	source := "variable \"name\" {\n  description = \"a, b\"\n  default     = { a = \"x\", b = [1, 2] }\n}\n"
+	if err := afero.WriteFile(memFs, "/src/main.tf", []byte(source), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	fs := filesystem.NewAferoAdapter(memFs)
+
+	// The layout of raw expressions may change, the content of strings may not
+	layout := map[string][]byte{"main.tf": []byte("variable \"name\" {\n  description = \"a, b\"\n  default = {\n    a = \"x\"\n    b = [1, 2,]\n  }\n}\n")}
+	if err := Verify(fs, "/src", layout, FormatHCL); err == nil {
+		t.Error("expected a conversion to hcl to be compared with the .tf.json sources only")
+	}
+	asJSON, err := Workspace(fs, "/src", FormatJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	layoutJSON, err := Workspace(filesystem.NewAferoAdapter(memMap(t, "/layout/main.tf", layout["main.tf"])), "/layout", FormatJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Verify(fs, "/src", layoutJSON, FormatJSON); err != nil {
+		t.Errorf("expected a layout change to verify: %v", err)
+	}
+
+	changed := map[string][]byte{"main.tf.json": []byte(strings.Replace(string(asJSON["main.tf.json"]), "a, b", "ab", 1))}
+	if err := Verify(fs, "/src", changed, FormatJSON); err == nil {
+		t.Error("expected a changed description to fail")
+	}
+	changed = map[string][]byte{"main.tf.json": []byte(strings.Replace(string(asJSON["main.tf.json"]), "1,\n", "12,\n", 1))}
+	if err := Verify(fs, "/src", changed, FormatJSON); err == nil {
+		t.Error("expected a changed default to fail")
+	}
+}
+
+func TestVerifyMixedWorkspace(t *testing.T) {
+	memFs := afero.NewMemMapFs()
+	for name, content := range map[string]string{
+		"/src/main.tf":           `variable "name" {}`,
+		"/src/generated.tf.json": `{"variable": {"region": {"default": "eu-west-1"}}}`,
+	} {
+		if err := afero.WriteFile(memFs, name, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fs := filesystem.NewAferoAdapter(memFs)
+
+	for _, to := range []Format{FormatJSON, FormatHCL} {
+		converted, err := Workspace(fs, "/src", to)
+		if err != nil {
+			t.Fatalf("convert to %s: %v", to, err)
+		}
+		if err := Verify(fs, "/src", converted, to); err != nil {
+			t.Errorf("verify %s in a mixed workspace: %v", to, err)
+		}
+	}
+
+	// Converting to hcl is checked against the .tf.json sources
+	if err := Verify(fs, "/src", map[string][]byte{"generated.tf": []byte(`variable "region" { default = "us-east-1" }`)}, FormatHCL); err == nil {
+		t.Error("expected a changed hcl conversion to fail")
+	}
+}
+
+func TestSameExpression(t *testing.T) {
+	tests := []struct {
+		a, b string
+		same bool
+	}{
+		{`{ a = "x", b = [1, 2] }`, "{\n  a = \"x\"\n  b = [1, 2,]\n}", true},
+		{"f(a,\n  b)", "f(a, b)", true},
+		{`"%{ if x }y%{ endif }"`, `"%{if x}y%{endif}"`, true},
+		{`"a, b"`, `"ab"`, false},
+		{`[a, -1]`, `[a -1]`, false},
+		// Literal strings are not expressions
+		{"a, b", "a b", false},
+		{"a, b", "ab", false},
+	}
+	for _, tt := range tests {
+		if got := sameExpression(tt.a, tt.b); got != tt.same {
+			t.Errorf("sameExpression(%q, %q) = %v, expected %v", tt.a, tt.b, got, tt.same)
+		}
+	}
+}
+
+func memMap(t *testing.T, name string, content []byte) afero.Fs {
+	t.Helper()
+
+	memFs := afero.NewMemMapFs()
+	if err := afero.WriteFile(memFs, name, content, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return memFs
+}
@@ -0,0 +1,245 @@
+// Package deprecation plans how a module moves to a target interface through releases that stay
+// backward compatible until a final major release removes the deprecated variables and outputs.
+package deprecation
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser/schema"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Spec is the target interface of a module
+type Spec struct {
+	Variables []*VariableSpec `yaml:"variables" json:"variables"`
+	Outputs   []*OutputSpec   `yaml:"outputs" json:"outputs"`
+}
+
+type VariableSpec struct {
+	Name string `yaml:"name" json:"name"`
+	// Type is the type constraint, e.g. list(string); empty keeps the current type
+	Type string `yaml:"type" json:"type,omitempty"`
+	// Required marks variables without default; Default is ignored for them
+	Required bool        `yaml:"required" json:"required,omitempty"`
+	Default  interface{} `yaml:"default" json:"default,omitempty"`
+	// RenamedFrom is the current name of a variable that is renamed
+	RenamedFrom string `yaml:"renamed_from" json:"renamed_from,omitempty"`
+}
+
+type OutputSpec struct {
+	Name        string `yaml:"name" json:"name"`
+	RenamedFrom string `yaml:"renamed_from" json:"renamed_from,omitempty"`
+}
+
+// LoadSpec reads a target interface spec from a YAML file
+func LoadSpec(path string) (*Spec, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read interface spec %s: %w", path, err)
+	}
+
+	spec := &Spec{}
+	if err := yaml.Unmarshal(content, spec); err != nil {
+		return nil, fmt.Errorf("failed to parse interface spec %s: %w", path, err)
+	}
+
+	names := map[string]bool{}
+	for i, variable := range spec.Variables {
+		if variable.Name == "" {
+			return nil, fmt.Errorf("variable %d of %s has no name", i+1, path)
+		}
+		if names["var."+variable.Name] {
+			return nil, fmt.Errorf("variable %s is listed twice in %s", variable.Name, path)
+		}
+		names["var."+variable.Name] = true
+	}
+	for i, output := range spec.Outputs {
+		if output.Name == "" {
+			return nil, fmt.Errorf("output %d of %s has no name", i+1, path)
+		}
+		if names["output."+output.Name] {
+			return nil, fmt.Errorf("output %s is listed twice in %s", output.Name, path)
+		}
+		names["output."+output.Name] = true
+	}
+	return spec, nil
+}
+
+type Action string
+
+const (
+	ActionAddVariable       Action = "add_variable"
+	ActionAddOutput         Action = "add_output"
+	ActionMakeOptional      Action = "make_optional"
+	ActionChangeDefault     Action = "change_default"
+	ActionDeprecateVariable Action = "deprecate_variable"
+	ActionDeprecateOutput   Action = "deprecate_output"
+	ActionRemoveVariable    Action = "remove_variable"
+	ActionRemoveOutput      Action = "remove_output"
+	ActionMakeRequired      Action = "make_required"
+	ActionChangeType        Action = "change_type"
+)
+
+// Step is a single change of a phase
+type Step struct {
+	Action  Action `json:"action"`
+	Address string `json:"address"`
+	// Replacement is the address that replaces a deprecated or removed one, if any
+	Replacement string `json:"replacement,omitempty"`
+	Detail      string `json:"detail"`
+	Breaking    bool   `json:"breaking"`
+}
+
+// Phase is a group of steps shipped in one release
+type Phase struct {
+	// Name is expand, deprecate or contract
+	Name string `json:"name"`
+	// Release is the semantic version increment of the phase: minor or major
+	Release string  `json:"release"`
+	Steps   []*Step `json:"steps"`
+}
+
+// Plan is the ordered list of releases moving the module to the target interface
+type Plan struct {
+	Phases []*Phase `json:"phases"`
+	// Breaking counts the breaking steps; they are all in the last phase
+	Breaking int `json:"breaking"`
+}
+
+// Compute plans the move from the interface of current to spec in up to three phases:
+// expand adds the new variables (optional at first) and outputs, deprecate marks what is
+// going away, and contract, a major release, removes it and applies the breaking changes.
+func Compute(current *parser.TerraformConfig, spec *Spec) *Plan {
+	expand := &Phase{Name: "expand", Release: "minor"}
+	deprecate := &Phase{Name: "deprecate", Release: "minor"}
+	contract := &Phase{Name: "contract", Release: "major"}
+
+	variables := map[string]*schema.Variable{}
+	for _, variable := range current.Variables {
+		variables[variable.Name] = variable
+	}
+	// replaced maps current names to the name replacing them
+	replaced := map[string]string{}
+
+	for _, target := range spec.Variables {
+		address := "var." + target.Name
+		existing, ok := variables[target.Name]
+		if !ok {
+			step := &Step{Action: ActionAddVariable, Address: address, Detail: "add as optional"}
+			if old, renamed := variables[target.RenamedFrom]; renamed {
+				replaced[old.Name] = target.Name
+				step.Detail = fmt.Sprintf("add as optional with default null and use coalesce(%s, %s) until %s is removed", address, old.Address(), old.Address())
+			} else if !target.Required {
+				step.Detail = "add with default " + render(target.Default)
+			} else {
+				step.Detail = "add as optional with default null until it becomes required"
+			}
+			expand.Steps = append(expand.Steps, step)
+
+			if target.Required {
+				contract.Steps = append(contract.Steps, &Step{Action: ActionMakeRequired, Address: address, Detail: "remove the default", Breaking: true})
+			}
+			continue
+		}
+
+		if target.Type != "" && normalizeType(target.Type) != normalizeType(existing.Type) {
+			contract.Steps = append(contract.Steps, &Step{Action: ActionChangeType, Address: address, Detail: fmt.Sprintf("change type from %q to %q", existing.Type, target.Type), Breaking: true})
+		}
+		switch {
+		case target.Required && !existing.Required:
+			contract.Steps = append(contract.Steps, &Step{Action: ActionMakeRequired, Address: address, Detail: "remove the default", Breaking: true})
+		case !target.Required && existing.Required:
+			expand.Steps = append(expand.Steps, &Step{Action: ActionMakeOptional, Address: address, Detail: "add default " + render(target.Default)})
+		case !target.Required && render(target.Default) != render(existing.Default):
+			expand.Steps = append(expand.Steps, &Step{Action: ActionChangeDefault, Address: address, Detail: fmt.Sprintf("change default from %s to %s", render(existing.Default), render(target.Default))})
+		}
+	}
+
+	kept := map[string]bool{}
+	for _, target := range spec.Variables {
+		kept[target.Name] = true
+	}
+	for _, variable := range current.Variables {
+		if kept[variable.Name] {
+			continue
+		}
+		replacement := ""
+		if name, ok := replaced[variable.Name]; ok {
+			replacement = "var." + name
+		}
+		deprecate.Steps = append(deprecate.Steps, &Step{Action: ActionDeprecateVariable, Address: variable.Address(), Replacement: replacement, Detail: deprecationDetail(replacement)})
+		contract.Steps = append(contract.Steps, &Step{Action: ActionRemoveVariable, Address: variable.Address(), Replacement: replacement, Detail: "remove the variable", Breaking: true})
+	}
+
+	outputs := map[string]*schema.Output{}
+	for _, output := range current.Outputs {
+		outputs[output.Name] = output
+	}
+	replacedOutputs := map[string]string{}
+	keptOutputs := map[string]bool{}
+	for _, target := range spec.Outputs {
+		keptOutputs[target.Name] = true
+		if _, ok := outputs[target.Name]; ok {
+			continue
+		}
+		step := &Step{Action: ActionAddOutput, Address: "output." + target.Name, Detail: "add the output"}
+		if old, renamed := outputs[target.RenamedFrom]; renamed {
+			replacedOutputs[old.Name] = target.Name
+			step.Detail = "add with the value of " + old.Address()
+		}
+		expand.Steps = append(expand.Steps, step)
+	}
+	for _, output := range current.Outputs {
+		if keptOutputs[output.Name] {
+			continue
+		}
+		replacement := ""
+		if name, ok := replacedOutputs[output.Name]; ok {
+			replacement = "output." + name
+		}
+		deprecate.Steps = append(deprecate.Steps, &Step{Action: ActionDeprecateOutput, Address: output.Address(), Replacement: replacement, Detail: deprecationDetail(replacement)})
+		contract.Steps = append(contract.Steps, &Step{Action: ActionRemoveOutput, Address: output.Address(), Replacement: replacement, Detail: "remove the output", Breaking: true})
+	}
+
+	plan := &Plan{Phases: []*Phase{}}
+	for _, phase := range []*Phase{expand, deprecate, contract} {
+		if len(phase.Steps) == 0 {
+			continue
+		}
+		slices.SortStableFunc(phase.Steps, func(a, b *Step) int { return strings.Compare(a.Address, b.Address) })
+		plan.Phases = append(plan.Phases, phase)
+	}
+	for _, step := range contract.Steps {
+		if step.Breaking {
+			plan.Breaking++
+		}
+	}
+	return plan
+}
+
+func deprecationDetail(replacement string) string {
+	if replacement == "" {
+		return `prefix the description with "Deprecated:"`
+	}
+	return fmt.Sprintf(`prefix the description with "Deprecated: use %s instead."`, replacement)
+}
+
+// normalizeType removes whitespace so that formatting does not count as a type change
+func normalizeType(typ string) string {
+	return strings.Join(strings.Fields(typ), "")
+}
+
+func render(v interface{}) string {
+	if v == nil {
+		return "null"
+	}
+	if s, ok := v.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,84 @@
+package deprecation
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser/schema"
+)
+
+func TestCompute(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spec.yaml")
+	spec := `
+variables:
+  - name: cidr_block
+    type: string
+    required: true
+    renamed_from: cidr
+  - name: name
+    type: string
+  - name: azs
+    type: list( string )
+    default: 3
+outputs:
+  - name: vpc_id
+    renamed_from: id
+`
+	if err := os.WriteFile(path, []byte(spec), 0644); err != nil {
+		t.Fatal(err)
+	}
+	target, err := LoadSpec(path)
+	if err != nil {
+		t.Fatalf("Failed to load spec: %v", err)
+	}
+
+	current := &parser.TerraformConfig{
+		Variables: []*schema.Variable{
+			{Name: "cidr", Type: "string", Required: true},
+			{Name: "name", Type: "string", Required: true},
+			{Name: "azs", Type: "list(string)", Default: int64(2)},
+			{Name: "legacy_dns", Type: "bool", Default: false},
+		},
+		Outputs: []*schema.Output{{Name: "id"}},
+	}
+
+	plan := Compute(current, target)
+	if len(plan.Phases) != 3 {
+		t.Fatalf("Expected expand, deprecate and contract phases, got %d", len(plan.Phases))
+	}
+
+	expected := [][]Action{
+		{ActionAddOutput, ActionChangeDefault, ActionAddVariable, ActionMakeOptional},
+		{ActionDeprecateOutput, ActionDeprecateVariable, ActionDeprecateVariable},
+		{ActionRemoveOutput, ActionRemoveVariable, ActionMakeRequired, ActionRemoveVariable},
+	}
+	for i, phase := range plan.Phases {
+		actions := []Action{}
+		for _, step := range phase.Steps {
+			actions = append(actions, step.Action)
+			if step.Breaking != (phase.Name == "contract") {
+				t.Errorf("%s: step %s %s has breaking %t", phase.Name, step.Action, step.Address, step.Breaking)
+			}
+		}
+		if len(actions) != len(expected[i]) {
+			t.Errorf("%s: expected %v, got %v", phase.Name, expected[i], actions)
+			continue
+		}
+		for j := range actions {
+			if actions[j] != expected[i][j] {
+				t.Errorf("%s: expected %v, got %v", phase.Name, expected[i], actions)
+				break
+			}
+		}
+	}
+
+	deprecated := plan.Phases[1].Steps[1]
+	if deprecated.Address != "var.cidr" || deprecated.Replacement != "var.cidr_block" {
+		t.Errorf("Expected var.cidr to be replaced by var.cidr_block, got %+v", deprecated)
+	}
+	if plan.Breaking != 4 {
+		t.Errorf("Expected 4 breaking steps, got %d", plan.Breaking)
+	}
+}
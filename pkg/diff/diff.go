@@ -0,0 +1,322 @@
+package diff
+
+import (
+	"fmt"
+	"path"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser/schema"
+)
+
+type ChangeKind string
+
+const (
+	ChangeAdded    ChangeKind = "added"
+	ChangeRemoved  ChangeKind = "removed"
+	ChangeModified ChangeKind = "modified"
+)
+
+// Change is a difference of a single block between two configurations
+type Change struct {
+	// Address of the changed block, e.g. var.region or output.vpc_id
+	Address string     `json:"address"`
+	Kind    ChangeKind `json:"kind"`
+	// Breaking is true when callers of the module have to change to keep working
+	Breaking bool   `json:"breaking"`
+	Reason   string `json:"reason"`
+	// Attributes are the changed attributes of a modified block
+	Attributes []*AttributeChange `json:"attributes,omitempty"`
+}
+
+// AttributeChange is an attribute of a block whose value changed, e.g. the default of a variable.
+// A nil value means the attribute is not set.
+type AttributeChange struct {
+	Name   string      `json:"name"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// attributes collects the changed attributes of a block
+type attributes []*AttributeChange
+
+func (a *attributes) compare(name string, before, after interface{}) {
+	if !reflect.DeepEqual(before, after) {
+		*a = append(*a, &AttributeChange{Name: name, Before: before, After: after})
+	}
+}
+
+func (a *attributes) compareList(name string, before, after []string) {
+	if !slices.Equal(before, after) {
+		*a = append(*a, &AttributeChange{Name: name, Before: before, After: after})
+	}
+}
+
+func (a attributes) changed(name string) bool {
+	return slices.ContainsFunc(a, func(change *AttributeChange) bool { return change.Name == name })
+}
+
+type Report struct {
+	Changes  []*Change `json:"changes"`
+	Breaking int       `json:"breaking"`
+	// Ignored counts the changes left out by the ignore list
+	Ignored int `json:"ignored,omitempty"`
+
+	ignore *Ignore
+}
+
+// Ignore lists the changes to leave out of a report, e.g. cosmetic edits that should not trip
+// breaking-change gates
+type Ignore struct {
+	// Attributes are the names of changed attributes to disregard, e.g. description. Modified
+	// blocks without other changed attributes are left out.
+	Attributes []string
+	// Addresses are glob patterns of the blocks to leave out, e.g. var.debug_*
+	Addresses []string
+}
+
+// ignoresAddress reports whether address matches an ignored pattern
+func (i *Ignore) ignoresAddress(address string) bool {
+	if i == nil {
+		return false
+	}
+	return slices.ContainsFunc(i.Addresses, func(pattern string) bool {
+		matched, err := path.Match(pattern, address)
+		return err == nil && matched
+	})
+}
+
+// filter drops the ignored attributes of changed
+func (i *Ignore) filter(changed attributes) attributes {
+	if i == nil {
+		return changed
+	}
+	return slices.DeleteFunc(changed, func(change *AttributeChange) bool { return slices.Contains(i.Attributes, change.Name) })
+}
+
+// HasBreaking reports whether any change is breaking
+func (r *Report) HasBreaking() bool {
+	return r.Breaking > 0
+}
+
+func (r *Report) add(change *Change) {
+	if r.ignore.ignoresAddress(change.Address) {
+		r.Ignored++
+		return
+	}
+	r.Changes = append(r.Changes, change)
+	if change.Breaking {
+		r.Breaking++
+	}
+}
+
+// Compare reports the interface changes (variables and outputs) and the resources
+// added or removed between the before and after configurations
+func Compare(before, after *parser.TerraformConfig) *Report {
+	return CompareIgnoring(before, after, nil)
+}
+
+// CompareIgnoring compares the configurations like Compare, leaving out the changes of ignore.
+// Reasons and breaking flags only consider the attributes that are not ignored.
+func CompareIgnoring(before, after *parser.TerraformConfig, ignore *Ignore) *Report {
+	report := &Report{Changes: []*Change{}, ignore: ignore}
+
+	compareVariables(report, before, after)
+	compareOutputs(report, before, after)
+	compareResources(report, before, after)
+
+	slices.SortStableFunc(report.Changes, func(a, b *Change) int { return strings.Compare(a.Address, b.Address) })
+	return report
+}
+
+func compareVariables(report *Report, before, after *parser.TerraformConfig) {
+	old := map[string]int{}
+	for i, variable := range before.Variables {
+		old[variable.Name] = i
+	}
+
+	for _, variable := range after.Variables {
+		i, existed := old[variable.Name]
+		if !existed {
+			if variable.Required {
+				report.add(&Change{Address: variable.Address(), Kind: ChangeAdded, Breaking: true, Reason: "new required variable"})
+			} else {
+				report.add(&Change{Address: variable.Address(), Kind: ChangeAdded, Reason: "new optional variable"})
+			}
+			continue
+		}
+		delete(old, variable.Name)
+
+		prev := before.Variables[i]
+		changed := attributes{}
+		changed.compare("type", prev.Type, variable.Type)
+		changed.compare("default", prev.Default, variable.Default)
+		changed.compare("required", prev.Required, variable.Required)
+		changed.compare("sensitive", prev.IsSensitive(), variable.IsSensitive())
+		changed.compare("nullable", prev.IsNullable(), variable.IsNullable())
+		changed.compare("ephemeral", prev.IsEphemeral(), variable.IsEphemeral())
+		changed.compare("description", prev.Description, variable.Description)
+		changed.compareList("validation", validationConditions(prev), validationConditions(variable))
+		if !report.significant(&changed) {
+			continue
+		}
+
+		// The reason names the most significant change; the attributes list all of them
+		change := &Change{Address: variable.Address(), Kind: ChangeModified, Attributes: changed}
+		switch {
+		case changed.changed("type"):
+			change.Breaking, change.Reason = true, fmt.Sprintf("type changed from %q to %q", prev.Type, variable.Type)
+		case changed.changed("required") && variable.Required:
+			change.Breaking, change.Reason = true, "default removed, variable is now required"
+		case changed.changed("required"):
+			change.Reason = "default added, variable is now optional"
+		case changed.changed("ephemeral") && !variable.IsEphemeral():
+			change.Breaking, change.Reason = true, "variable is no longer ephemeral; callers passing ephemeral values will fail"
+		case changed.changed("default"):
+			change.Reason = "default value changed"
+		case changed.changed("nullable") && !variable.IsNullable():
+			change.Reason = "variable is no longer nullable; null now selects the default"
+		case changed.changed("nullable"):
+			change.Reason = "variable is now nullable"
+		case changed.changed("ephemeral"):
+			change.Reason = "variable is now ephemeral"
+		case changed.changed("sensitive"):
+			change.Reason = "sensitive flag changed"
+		case changed.changed("description"):
+			change.Reason = "description changed"
+		default:
+			change.Reason = "validation changed"
+		}
+		report.add(change)
+	}
+
+	for _, variable := range before.Variables {
+		if _, removed := old[variable.Name]; removed {
+			report.add(&Change{Address: variable.Address(), Kind: ChangeRemoved, Breaking: true, Reason: "variable removed; callers setting it will fail"})
+		}
+	}
+}
+
+func compareOutputs(report *Report, before, after *parser.TerraformConfig) {
+	old := map[string]int{}
+	for i, output := range before.Outputs {
+		old[output.Name] = i
+	}
+
+	for _, output := range after.Outputs {
+		i, existed := old[output.Name]
+		if !existed {
+			report.add(&Change{Address: output.Address(), Kind: ChangeAdded, Reason: "new output"})
+			continue
+		}
+		delete(old, output.Name)
+
+		prev := before.Outputs[i]
+		changed := attributes{}
+		changed.compare("sensitive", prev.IsSensitive(), output.IsSensitive())
+		changed.compare("ephemeral", prev.IsEphemeral(), output.IsEphemeral())
+		changed.compare("value", prev.Value, output.Value)
+		changed.compareList("references", prev.References, output.References)
+		changed.compareList("depends_on", prev.DependsOn, output.DependsOn)
+		changed.compare("description", prev.Description, output.Description)
+		changed.compareList("precondition", preconditionConditions(prev), preconditionConditions(output))
+		if !report.significant(&changed) {
+			continue
+		}
+
+		change := &Change{Address: output.Address(), Kind: ChangeModified, Attributes: changed}
+		switch {
+		case changed.changed("sensitive") && output.IsSensitive():
+			change.Breaking, change.Reason = true, "output is now sensitive"
+		case changed.changed("sensitive"):
+			change.Reason = "output is no longer sensitive"
+		case changed.changed("ephemeral") && output.IsEphemeral():
+			change.Breaking, change.Reason = true, "output is now ephemeral; callers can only use it in ephemeral contexts"
+		case changed.changed("ephemeral"):
+			change.Reason = "output is no longer ephemeral"
+		case changed.changed("references"):
+			change.Reason = "value references changed"
+		case changed.changed("value"):
+			change.Reason = "value expression changed"
+		case changed.changed("depends_on"):
+			change.Reason = "dependencies changed"
+		case changed.changed("description"):
+			change.Reason = "description changed"
+		default:
+			change.Reason = "preconditions changed"
+		}
+		report.add(change)
+	}
+
+	for _, output := range before.Outputs {
+		if _, removed := old[output.Name]; removed {
+			report.add(&Change{Address: output.Address(), Kind: ChangeRemoved, Breaking: true, Reason: "output removed; callers reading it will fail"})
+		}
+	}
+}
+
+// significant drops the ignored attributes of a modified block and reports whether changes are
+// left; blocks whose changes are all ignored are counted as ignored
+func (r *Report) significant(changed *attributes) bool {
+	if len(*changed) == 0 {
+		return false
+	}
+	if *changed = r.ignore.filter(*changed); len(*changed) == 0 {
+		r.Ignored++
+		return false
+	}
+	return true
+}
+
+// validationConditions lists the conditions of the validation blocks of a variable
+func validationConditions(variable *schema.Variable) []string {
+	conditions := []string{}
+	for _, validation := range variable.Validation {
+		conditions = append(conditions, validation.Condition)
+	}
+	return conditions
+}
+
+// preconditionConditions lists the conditions of the precondition blocks of an output
+func preconditionConditions(output *schema.Output) []string {
+	conditions := []string{}
+	for _, precondition := range output.Preconditions {
+		conditions = append(conditions, precondition.Condition)
+	}
+	return conditions
+}
+
+// compareResources reports managed resources that would be created or destroyed, and those whose
+// provider, dependencies or references changed. They do not change the module interface and are
+// never breaking.
+func compareResources(report *Report, before, after *parser.TerraformConfig) {
+	old := map[string]*schema.Resource{}
+	for _, resource := range before.Resources {
+		old[resource.Address()] = resource
+	}
+
+	for _, resource := range after.Resources {
+		prev, existed := old[resource.Address()]
+		if !existed {
+			report.add(&Change{Address: resource.Address(), Kind: ChangeAdded, Reason: "new resource"})
+			continue
+		}
+		delete(old, resource.Address())
+
+		changed := attributes{}
+		changed.compare("provider", prev.Provider, resource.Provider)
+		changed.compareList("depends_on", prev.DependsOn, resource.DependsOn)
+		changed.compareList("references", prev.References, resource.References)
+		if report.significant(&changed) {
+			report.add(&Change{Address: resource.Address(), Kind: ChangeModified, Reason: "resource arguments changed", Attributes: changed})
+		}
+	}
+
+	for _, resource := range before.Resources {
+		if _, removed := old[resource.Address()]; removed {
+			report.add(&Change{Address: resource.Address(), Kind: ChangeRemoved, Reason: "resource removed; it will be destroyed unless moved"})
+		}
+	}
+}
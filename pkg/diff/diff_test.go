@@ -0,0 +1,428 @@
+package diff
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/filesystem"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+	"github.com/spf13/afero"
+)
+
+func parse(t *testing.T, content string) *parser.TerraformConfig {
+	t.Helper()
+
+	memFs := afero.NewMemMapFs()
+	if err := afero.WriteFile(memFs, "main.tf", []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tfconfig, err := parser.NewParser(filesystem.NewAferoAdapter(memFs), parser.Detail).ParseTerraformWorkspace(".")
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	return tfconfig
+}
+
+func TestCompare(t *testing.T) {
+	before := parse(t, `
+variable "name" {
+  type = string
+}
+
+variable "tags" {
+  type    = map(string)
+  default = {}
+}
+
+variable "legacy" {
+  default = "x"
+}
+
+output "id" {
+  value = aws_s3_bucket.this.id
+}
+
+output "arn" {
+  value = aws_s3_bucket.this.arn
+}
+
+resource "aws_s3_bucket" "this" {
+  bucket = var.name
+}
+`)
+	after := parse(t, `
+variable "name" {
+  type = list(string)
+}
+
+variable "tags" {
+  type = map(string)
+}
+
+variable "region" {
+  default = "us-east-1"
+}
+
+variable "account" {}
+
+output "id" {
+  value     = aws_s3_bucket.this.id
+  sensitive = true
+}
+
+resource "aws_s3_bucket" "this" {
+  bucket = var.name[0]
+}
+
+resource "aws_s3_bucket_versioning" "this" {
+  bucket = aws_s3_bucket.this.id
+}
+`)
+
+	report := Compare(before, after)
+
+	expected := map[string]struct {
+		kind     ChangeKind
+		breaking bool
+	}{
+		"var.name":                      {ChangeModified, true},
+		"var.tags":                      {ChangeModified, true},
+		"var.legacy":                    {ChangeRemoved, true},
+		"var.region":                    {ChangeAdded, false},
+		"var.account":                   {ChangeAdded, true},
+		"output.id":                     {ChangeModified, true},
+		"output.arn":                    {ChangeRemoved, true},
+		"aws_s3_bucket_versioning.this": {ChangeAdded, false},
+	}
+
+	if len(report.Changes) != len(expected) {
+		for _, change := range report.Changes {
+			t.Logf("%s %s: %s", change.Kind, change.Address, change.Reason)
+		}
+		t.Fatalf("Expected %d changes, got %d", len(expected), len(report.Changes))
+	}
+
+	breaking := 0
+	for _, change := range report.Changes {
+		want, ok := expected[change.Address]
+		if !ok {
+			t.Errorf("Unexpected change %s", change.Address)
+			continue
+		}
+		if change.Kind != want.kind || change.Breaking != want.breaking {
+			t.Errorf("%s: expected %s (breaking %v), got %s (breaking %v)", change.Address, want.kind, want.breaking, change.Kind, change.Breaking)
+		}
+		if want.breaking {
+			breaking++
+		}
+	}
+
+	if report.Breaking != breaking || !report.HasBreaking() {
+		t.Errorf("Expected %d breaking changes, got %d", breaking, report.Breaking)
+	}
+
+	if Compare(before, before).HasBreaking() {
+		t.Error("Expected no changes when comparing a configuration with itself")
+	}
+}
+
+func TestAttributeChanges(t *testing.T) {
+	before := parse(t, `
+variable "region" {
+  description = "Region"
+  default     = "us-east-1"
+}
+
+variable "size" {
+  default = 1
+
+  validation {
+    condition     = var.size > 0
+    error_message = "size must be positive."
+  }
+}
+
+output "id" {
+  value = aws_s3_bucket.this.id
+}
+
+resource "aws_s3_bucket" "this" {}
+`)
+	after := parse(t, `
+variable "region" {
+  description = "AWS region"
+  default     = "eu-west-1"
+}
+
+variable "size" {
+  default = 1
+
+  validation {
+    condition     = var.size > 1
+    error_message = "size must be greater than one."
+  }
+}
+
+output "id" {
+  value = aws_s3_bucket.logs.id
+}
+
+resource "aws_s3_bucket" "this" {
+  depends_on = [aws_iam_role.this]
+}
+`)
+
+	report := Compare(before, after)
+	changes := map[string]*Change{}
+	for _, change := range report.Changes {
+		changes[change.Address] = change
+	}
+
+	expected := map[string][]*AttributeChange{
+		"var.region": {
+			{Name: "default", Before: "us-east-1", After: "eu-west-1"},
+			{Name: "description", Before: "Region", After: "AWS region"},
+		},
+		"var.size": {
+			{Name: "validation", Before: []string{"var.size > 0"}, After: []string{"var.size > 1"}},
+		},
+		"output.id": {
+			{Name: "value", Before: "aws_s3_bucket.this.id", After: "aws_s3_bucket.logs.id"},
+			{Name: "references", Before: []string{"aws_s3_bucket.this"}, After: []string{"aws_s3_bucket.logs"}},
+		},
+		"aws_s3_bucket.this": {
+			{Name: "depends_on", Before: []string(nil), After: []string{"aws_iam_role.this"}},
+		},
+	}
+	if len(changes) != len(expected) {
+		t.Errorf("Expected %d changes, got %d", len(expected), len(changes))
+	}
+	for address, attributes := range expected {
+		change, ok := changes[address]
+		if !ok {
+			t.Errorf("Expected a change of %s", address)
+			continue
+		}
+		if change.Kind != ChangeModified || change.Breaking {
+			t.Errorf("%s: expected a non-breaking modification, got %s (breaking %v)", address, change.Kind, change.Breaking)
+		}
+		if !reflect.DeepEqual(change.Attributes, attributes) {
+			for _, attribute := range change.Attributes {
+				t.Logf("%s %s: %#v -> %#v", address, attribute.Name, attribute.Before, attribute.After)
+			}
+			t.Errorf("%s: unexpected attribute changes", address)
+		}
+	}
+
+	if reason := changes["var.region"].Reason; reason != "default value changed" {
+		t.Errorf("Expected the reason to name the default, got %q", reason)
+	}
+}
+
+func TestVariableFlagChanges(t *testing.T) {
+	before := parse(t, `
+variable "token" {
+  ephemeral = true
+}
+
+variable "name" {
+  default = "x"
+}
+`)
+	after := parse(t, `
+variable "token" {}
+
+variable "name" {
+  default  = "x"
+  nullable = false
+}
+`)
+
+	changes := map[string]*Change{}
+	for _, change := range Compare(before, after).Changes {
+		changes[change.Address] = change
+	}
+
+	if change := changes["var.token"]; change == nil || !change.Breaking || !attributes(change.Attributes).changed("ephemeral") {
+		t.Errorf("Expected a breaking ephemeral change of var.token, got %+v", change)
+	}
+	if change := changes["var.name"]; change == nil || change.Breaking || !attributes(change.Attributes).changed("nullable") {
+		t.Errorf("Expected a non-breaking nullable change of var.name, got %+v", change)
+	}
+}
+
+func TestOutputValueChanges(t *testing.T) {
+	before := parse(t, `
+output "name" {
+  value = var.name
+}
+
+output "token" {
+  value = var.token
+}
+
+output "id" {
+  value = var.id
+}
+`)
+	after := parse(t, `
+output "name" {
+  value = upper(var.name)
+}
+
+output "id" {
+  value = var.id
+
+  precondition {
+    condition     = var.id != ""
+    error_message = "id is empty."
+  }
+}
+
+output "token" {
+  value     = var.token
+  ephemeral = true
+}
+`)
+
+	changes := map[string]*Change{}
+	for _, change := range Compare(before, after).Changes {
+		changes[change.Address] = change
+	}
+
+	if change := changes["output.name"]; change == nil || change.Breaking || change.Reason != "value expression changed" {
+		t.Errorf("Expected a non-breaking value change of output.name, got %+v", change)
+	}
+	if change := changes["output.token"]; change == nil || !change.Breaking || !attributes(change.Attributes).changed("ephemeral") {
+		t.Errorf("Expected a breaking ephemeral change of output.token, got %+v", change)
+	}
+	if change := changes["output.id"]; change == nil || change.Breaking || change.Reason != "preconditions changed" {
+		t.Errorf("Expected a non-breaking precondition change of output.id, got %+v", change)
+	}
+}
+
+func TestMatrix(t *testing.T) {
+	v1 := parse(t, `
+variable "name" {
+  type = string
+}
+`)
+	v2 := parse(t, `
+variable "name" {
+  type = string
+}
+
+variable "tags" {
+  type    = map(string)
+  default = {}
+}
+`)
+	v3 := parse(t, `
+variable "name" {
+  type = list(string)
+}
+
+variable "tags" {
+  type    = map(string)
+  default = {}
+}
+`)
+	versions := []string{"v1", "v2", "v3"}
+	configs := []*parser.TerraformConfig{v1, v2, v3}
+
+	matrix, err := NewMatrix(versions, configs, false, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(matrix.Upgrades) != 3 {
+		t.Fatalf("Expected 3 upgrades, got %d", len(matrix.Upgrades))
+	}
+	if upgrade := matrix.Upgrade("v1", "v2"); upgrade == nil || !upgrade.Safe || upgrade.Changes != 1 {
+		t.Errorf("Expected a safe upgrade from v1 to v2, got %+v", upgrade)
+	}
+	for _, from := range []string{"v1", "v2"} {
+		upgrade := matrix.Upgrade(from, "v3")
+		if upgrade == nil || upgrade.Safe || upgrade.Breaking != 1 || len(upgrade.BreakingChanges) != 1 || upgrade.BreakingChanges[0].Address != "var.name" {
+			t.Errorf("Expected an unsafe upgrade from %s to v3, got %+v", from, upgrade)
+		}
+	}
+	if matrix.Upgrade("v3", "v1") != nil {
+		t.Error("Expected no downgrades")
+	}
+
+	expected := "| from \\ to | v2 | v3 |\n|---|---|---|\n| v1 | ✅ | ❌ 1 |\n| v2 |  | ❌ 1 |\n"
+	if got := matrix.Markdown(); got != expected {
+		t.Errorf("Unexpected markdown:\n%s", got)
+	}
+
+	sequential, err := NewMatrix(versions, configs, true, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(sequential.Upgrades) != 2 || sequential.Upgrade("v1", "v3") != nil {
+		t.Errorf("Expected the upgrades to the next version only, got %+v", sequential.Upgrades)
+	}
+
+	if _, err := NewMatrix(versions, configs[:2], false, nil); err == nil {
+		t.Error("Expected an error for missing configurations")
+	}
+}
+
+func TestCompareIgnoring(t *testing.T) {
+	before := parse(t, `
+variable "name" {
+  description = "Name"
+}
+
+variable "debug_level" {
+  default = 1
+}
+
+variable "tags" {
+  description = "Tags"
+  default     = {}
+}
+
+output "id" {
+  description = "ID"
+  value       = var.name
+}
+`)
+	after := parse(t, `
+variable "name" {
+  description = "Name of the bucket"
+}
+
+variable "tags" {
+  description = "Tags of every resource"
+}
+
+output "id" {
+  description = "Identifier"
+  value       = var.name
+  sensitive   = true
+}
+`)
+
+	ignore := &Ignore{Attributes: []string{"description"}, Addresses: []string{"var.debug_*"}}
+	report := CompareIgnoring(before, after, ignore)
+
+	changes := map[string]*Change{}
+	for _, change := range report.Changes {
+		changes[change.Address] = change
+	}
+	if len(changes) != 2 || report.Ignored != 2 {
+		t.Fatalf("Expected 2 changes and 2 ignored, got %+v and %d ignored", report.Changes, report.Ignored)
+	}
+	if change := changes["var.tags"]; change == nil || !change.Breaking || attributes(change.Attributes).changed("description") {
+		t.Errorf("Expected the breaking default removal of var.tags without description, got %+v", change)
+	}
+	if change := changes["output.id"]; change == nil || change.Reason != "output is now sensitive" || len(change.Attributes) != 1 {
+		t.Errorf("Expected the sensitive change of output.id only, got %+v", change)
+	}
+
+	if full := Compare(before, after); len(full.Changes) != 4 || full.Ignored != 0 {
+		t.Errorf("Expected 4 changes without ignore list, got %+v", full.Changes)
+	}
+}
@@ -0,0 +1,96 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+)
+
+// Matrix is the compatibility of the upgrades between versions of a module, e.g. its release tags
+type Matrix struct {
+	// Versions are in upgrade order, oldest first
+	Versions []string `json:"versions"`
+	// Sequential is set when only the upgrades from each version to the next were compared
+	Sequential bool       `json:"sequential"`
+	Upgrades   []*Upgrade `json:"upgrades"`
+}
+
+// Upgrade is the comparison of two versions
+type Upgrade struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Safe is set when the upgrade has no breaking change
+	Safe     bool `json:"safe"`
+	Changes  int  `json:"changes"`
+	Breaking int  `json:"breaking"`
+	// BreakingChanges lists the changes that make the upgrade unsafe
+	BreakingChanges []*Change `json:"breaking_changes,omitempty"`
+}
+
+// NewMatrix compares the configurations of versions, in upgrade order: every version with each
+// later one or, when sequential, with the next one only. Changes of ignore are left out.
+func NewMatrix(versions []string, configs []*parser.TerraformConfig, sequential bool, ignore *Ignore) (*Matrix, error) {
+	if len(versions) != len(configs) {
+		return nil, fmt.Errorf("%d versions but %d configurations", len(versions), len(configs))
+	}
+
+	matrix := &Matrix{Versions: versions, Sequential: sequential, Upgrades: []*Upgrade{}}
+	for i := range versions {
+		for j := i + 1; j < len(versions); j++ {
+			if sequential && j > i+1 {
+				break
+			}
+
+			report := CompareIgnoring(configs[i], configs[j], ignore)
+			upgrade := &Upgrade{From: versions[i], To: versions[j], Safe: !report.HasBreaking(), Changes: len(report.Changes), Breaking: report.Breaking}
+			for _, change := range report.Changes {
+				if change.Breaking {
+					upgrade.BreakingChanges = append(upgrade.BreakingChanges, change)
+				}
+			}
+			matrix.Upgrades = append(matrix.Upgrades, upgrade)
+		}
+	}
+	return matrix, nil
+}
+
+// Upgrade returns the comparison of from with to, or nil when they were not compared
+func (m *Matrix) Upgrade(from, to string) *Upgrade {
+	for _, upgrade := range m.Upgrades {
+		if upgrade.From == from && upgrade.To == to {
+			return upgrade
+		}
+	}
+	return nil
+}
+
+// Markdown renders the matrix as a table with a row per version upgraded from and a column per
+// version upgraded to. Safe upgrades are marked ✅ and the others ❌ with their count of
+// breaking changes; pairs that were not compared are left empty.
+func (m *Matrix) Markdown() string {
+	var sb strings.Builder
+	sb.WriteString("| from \\ to |")
+	for _, version := range m.Versions[min(1, len(m.Versions)):] {
+		fmt.Fprintf(&sb, " %s |", version)
+	}
+	sb.WriteString("\n|---|")
+	sb.WriteString(strings.Repeat("---|", max(len(m.Versions)-1, 0)))
+	sb.WriteString("\n")
+
+	for _, from := range m.Versions[:max(len(m.Versions)-1, 0)] {
+		fmt.Fprintf(&sb, "| %s |", from)
+		for _, to := range m.Versions[1:] {
+			switch upgrade := m.Upgrade(from, to); {
+			case upgrade == nil:
+				sb.WriteString("  |")
+			case upgrade.Safe:
+				sb.WriteString(" ✅ |")
+			default:
+				fmt.Fprintf(&sb, " ❌ %d |", upgrade.Breaking)
+			}
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,311 @@
+// Package diffview is an interactive terminal viewer showing the variables and outputs of two
+// versions of a module side by side.
+package diffview
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/diff"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"golang.org/x/term"
+)
+
+// Key is a key press the viewer reacts to
+type Key string
+
+const (
+	KeyUp       Key = "up"
+	KeyDown     Key = "down"
+	KeyPageUp   Key = "pgup"
+	KeyPageDown Key = "pgdown"
+	KeyTop      Key = "top"
+	KeyBottom   Key = "bottom"
+	// KeyToggle expands or collapses the details of the selected row
+	KeyToggle Key = "toggle"
+	// KeyFilter switches between all rows and changed rows only
+	KeyFilter Key = "filter"
+	KeyQuit   Key = "quit"
+)
+
+const (
+	styleReset    = "\033[0m"
+	styleBold     = "\033[1m"
+	styleReverse  = "\033[7m"
+	styleAdded    = "\033[32m"
+	styleRemoved  = "\033[31m"
+	styleModified = "\033[33m"
+	styleDim      = "\033[2m"
+)
+
+// row is a variable or output present in either version
+type row struct {
+	address string
+	// before and after are the summary followed by the details; nil when the block is absent
+	before, after []string
+	change        *diff.Change
+	expanded      bool
+}
+
+// Viewer holds the state of the viewer: the rows, the selection and the scroll position. It is
+// the bubbletea model of Run.
+type Viewer struct {
+	title       string
+	rows        []*row
+	changedOnly bool
+	cursor      int
+	offset      int
+	page        int
+	// width and height are the size of the terminal, zero until bubbletea reports it
+	width, height int
+}
+
+// New builds the rows of the variables and outputs of before and after, with their changes
+func New(title string, before, after *parser.TerraformConfig) *Viewer {
+	changes := map[string]*diff.Change{}
+	for _, change := range diff.Compare(before, after).Changes {
+		changes[change.Address] = change
+	}
+
+	rows := map[string]*row{}
+	get := func(address string) *row {
+		if rows[address] == nil {
+			rows[address] = &row{address: address, change: changes[address]}
+		}
+		return rows[address]
+	}
+	for _, variable := range before.Variables {
+		get(variable.Address()).before = variableLines(variable)
+	}
+	for _, variable := range after.Variables {
+		get(variable.Address()).after = variableLines(variable)
+	}
+	for _, output := range before.Outputs {
+		get(output.Address()).before = outputLines(output)
+	}
+	for _, output := range after.Outputs {
+		get(output.Address()).after = outputLines(output)
+	}
+
+	v := &Viewer{title: title}
+	for _, r := range rows {
+		v.rows = append(v.rows, r)
+	}
+	// Variables first, then outputs, by name
+	slices.SortFunc(v.rows, func(a, b *row) int {
+		if av, bv := strings.HasPrefix(a.address, "var."), strings.HasPrefix(b.address, "var."); av != bv {
+			if av {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.address, b.address)
+	})
+	return v
+}
+
+// visible returns the rows shown with the current filter
+func (v *Viewer) visible() []*row {
+	if !v.changedOnly {
+		return v.rows
+	}
+	return slices.DeleteFunc(slices.Clone(v.rows), func(r *row) bool { return r.change == nil })
+}
+
+// HandleKey updates the state for a key press and reports whether the viewer keeps running
+func (v *Viewer) HandleKey(key Key) bool {
+	rows := v.visible()
+	switch key {
+	case KeyQuit:
+		return false
+	case KeyUp:
+		v.cursor--
+	case KeyDown:
+		v.cursor++
+	case KeyPageUp:
+		v.cursor -= max(v.page, 1)
+	case KeyPageDown:
+		v.cursor += max(v.page, 1)
+	case KeyTop:
+		v.cursor = 0
+	case KeyBottom:
+		v.cursor = len(rows) - 1
+	case KeyToggle:
+		if v.cursor < len(rows) {
+			rows[v.cursor].expanded = !rows[v.cursor].expanded
+		}
+	case KeyFilter:
+		v.changedOnly = !v.changedOnly
+		v.cursor, v.offset = 0, 0
+		rows = v.visible()
+	}
+	v.cursor = min(max(v.cursor, 0), max(len(rows)-1, 0))
+	return true
+}
+
+// Render draws the screen for a terminal of width columns and height lines
+func (v *Viewer) Render(width, height int) string {
+	rows := v.visible()
+
+	addressWidth := 0
+	for _, r := range rows {
+		addressWidth = max(addressWidth, len(r.address))
+	}
+	addressWidth = min(addressWidth, width/4)
+	// Marker, status and the two separators take 10 columns
+	columnWidth := max((width-addressWidth-10)/2, 1)
+
+	body := []string{}
+	first := 0
+	for i, r := range rows {
+		if i == v.cursor {
+			first = len(body)
+		}
+		body = append(body, v.rowLines(r, i == v.cursor, addressWidth, columnWidth)...)
+	}
+
+	// Title, header and help take 3 lines; keep the selected row on screen
+	bodyHeight := max(height-3, 1)
+	v.page = bodyHeight
+	if first < v.offset {
+		v.offset = first
+	}
+	if first >= v.offset+bodyHeight {
+		v.offset = first - bodyHeight + 1
+	}
+	v.offset = min(v.offset, max(len(body)-bodyHeight, 0))
+
+	filter := "all"
+	if v.changedOnly {
+		filter = "changed only"
+	}
+	lines := []string{
+		styleBold + pad(fmt.Sprintf("%s (%d rows, %s)", v.title, len(rows), filter), width) + styleReset,
+		styleDim + "    " + pad("", addressWidth) + " │ " + pad("before", columnWidth) + " │ " + pad("after", columnWidth) + styleReset,
+	}
+	lines = append(lines, body[v.offset:min(v.offset+bodyHeight, len(body))]...)
+	for len(lines) < height-1 {
+		lines = append(lines, "")
+	}
+	lines = append(lines, styleDim+pad("↑/↓ move  enter expand  c changed only  g/G top/bottom  q quit", width)+styleReset)
+	return strings.Join(lines, "\n")
+}
+
+// rowLines renders the summary line of a row and, when expanded, its details and change reason
+func (v *Viewer) rowLines(r *row, selected bool, addressWidth, columnWidth int) []string {
+	marker, status, style := "▸", " ", ""
+	if r.expanded {
+		marker = "▾"
+	}
+	if r.change != nil {
+		switch r.change.Kind {
+		case diff.ChangeAdded:
+			status, style = "+", styleAdded
+		case diff.ChangeRemoved:
+			status, style = "-", styleRemoved
+		default:
+			status, style = "~", styleModified
+		}
+		if r.change.Breaking {
+			status = "!"
+		}
+	}
+
+	summary := marker + " " + status + " " + pad(r.address, addressWidth) + " │ " + pad(line(r.before, 0), columnWidth) + " │ " + pad(line(r.after, 0), columnWidth)
+	if selected {
+		style += styleReverse
+	}
+	lines := []string{style + summary + styleReset}
+	if !r.expanded {
+		return lines
+	}
+
+	for i := 1; i < max(len(r.before), len(r.after)); i++ {
+		lines = append(lines, "    "+pad("", addressWidth)+" │ "+pad(line(r.before, i), columnWidth)+" │ "+pad(line(r.after, i), columnWidth))
+	}
+	if r.change != nil {
+		kind := "non-breaking"
+		if r.change.Breaking {
+			kind = "breaking"
+		}
+		lines = append(lines, "    "+style+kind+": "+r.change.Reason+styleReset)
+	}
+	return lines
+}
+
+func line(lines []string, i int) string {
+	if lines == nil {
+		if i == 0 {
+			return "(absent)"
+		}
+		return ""
+	}
+	if i < len(lines) {
+		return lines[i]
+	}
+	return ""
+}
+
+// pad truncates or pads s to exactly width columns
+func pad(s string, width int) string {
+	s = ansi.Truncate(s, width, "…")
+	return s + strings.Repeat(" ", max(width-ansi.StringWidth(s), 0))
+}
+
+// keys maps the key presses of bubbletea to the keys the viewer reacts to
+var keys = map[string]Key{
+	"q": KeyQuit, "ctrl+c": KeyQuit, "esc": KeyQuit,
+	"k": KeyUp, "up": KeyUp,
+	"j": KeyDown, "down": KeyDown,
+	"b": KeyPageUp, "pgup": KeyPageUp,
+	" ": KeyPageDown, "pgdown": KeyPageDown,
+	"g": KeyTop, "home": KeyTop,
+	"G": KeyBottom, "end": KeyBottom,
+	"enter": KeyToggle, "l": KeyToggle, "h": KeyToggle,
+	"c": KeyFilter,
+}
+
+// Init implements tea.Model
+func (v *Viewer) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements tea.Model: key presses go through HandleKey, and resizes change the size the
+// next View renders for
+func (v *Viewer) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		v.width, v.height = msg.Width, msg.Height
+	case tea.KeyMsg:
+		if !v.HandleKey(keys[msg.String()]) {
+			return v, tea.Quit
+		}
+	}
+	return v, nil
+}
+
+// View implements tea.Model
+func (v *Viewer) View() string {
+	if v.width == 0 || v.height == 0 {
+		return ""
+	}
+	return v.Render(v.width, v.height)
+}
+
+// Run shows the viewer full screen on the terminal of in and out until the user quits
+func Run(v *Viewer, in, out *os.File) error {
+	if !term.IsTerminal(int(in.Fd())) || !term.IsTerminal(int(out.Fd())) {
+		return errors.New("the interactive viewer needs a terminal")
+	}
+
+	if _, err := tea.NewProgram(v, tea.WithAltScreen(), tea.WithInput(in), tea.WithOutput(out)).Run(); err != nil {
+		return fmt.Errorf("failed to run the viewer: %w", err)
+	}
+	return nil
+}
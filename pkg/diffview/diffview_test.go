@@ -0,0 +1,144 @@
+package diffview
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser/schema"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestViewer(t *testing.T) {
+	before := &parser.TerraformConfig{
+		Variables: []*schema.Variable{
+			{Name: "region", Type: "string", Default: "us-east-1"},
+			{Name: "legacy", Type: "bool", Default: false},
+			{Name: "name", Type: "string", Required: true},
+		},
+		Outputs: []*schema.Output{{Name: "vpc_id", References: []string{"aws_vpc.this"}}},
+	}
+	after := &parser.TerraformConfig{
+		Variables: []*schema.Variable{
+			{Name: "region", Type: "string", Required: true, Description: "AWS region"},
+			{Name: "name", Type: "string", Required: true},
+		},
+		Outputs: []*schema.Output{{Name: "vpc_id", References: []string{"aws_vpc.this"}}},
+	}
+
+	v := New("vpc: v1 → v2", before, after)
+	screen := ansi.Strip(v.Render(100, 20))
+	lines := strings.Split(screen, "\n")
+	if len(lines) != 20 {
+		t.Fatalf("Expected 20 lines, got %d", len(lines))
+	}
+	for _, line := range lines {
+		if ansi.StringWidth(line) > 100 {
+			t.Errorf("Line wider than the terminal: %q", line)
+		}
+	}
+	for _, want := range []string{"! var.legacy", "(absent)", "! var.region", "string = \"us-east-1\"", "output.vpc_id"} {
+		if !strings.Contains(screen, want) {
+			t.Errorf("Expected %q on screen:\n%s", want, screen)
+		}
+	}
+
+	// Rows are var.legacy, var.name, var.region, output.vpc_id; expand var.region
+	v.HandleKey(KeyDown)
+	v.HandleKey(KeyDown)
+	v.HandleKey(KeyToggle)
+	screen = ansi.Strip(v.Render(100, 20))
+	for _, want := range []string{"description: AWS region", "breaking: default removed"} {
+		if !strings.Contains(screen, want) {
+			t.Errorf("Expected %q in the expanded row:\n%s", want, screen)
+		}
+	}
+
+	v.HandleKey(KeyFilter)
+	if rows := v.visible(); len(rows) != 2 {
+		t.Errorf("Expected 2 changed rows, got %d", len(rows))
+	}
+	v.HandleKey(KeyBottom)
+	v.HandleKey(KeyDown)
+	if v.cursor != 1 {
+		t.Errorf("Expected the cursor on the last row, got %d", v.cursor)
+	}
+	if v.HandleKey(KeyQuit) {
+		t.Error("Expected quit to stop the viewer")
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	before := &parser.TerraformConfig{}
+	after := &parser.TerraformConfig{
+		Variables: []*schema.Variable{{Name: "a"}, {Name: "b"}, {Name: "c"}},
+	}
+
+	tests := []struct {
+		name   string
+		msgs   []tea.Msg
+		cursor int
+		quit   bool
+	}{
+		{"arrow down", []tea.Msg{tea.KeyMsg{Type: tea.KeyDown}}, 1, false},
+		{"vim keys", []tea.Msg{key("j"), key("j"), key("k")}, 1, false},
+		{"end then up", []tea.Msg{tea.KeyMsg{Type: tea.KeyEnd}, tea.KeyMsg{Type: tea.KeyUp}}, 1, false},
+		{"unknown key", []tea.Msg{key("x")}, 0, false},
+		{"quit", []tea.Msg{key("q")}, 0, true},
+		{"ctrl+c", []tea.Msg{tea.KeyMsg{Type: tea.KeyCtrlC}}, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := New("vars", before, after)
+			var cmd tea.Cmd
+			for _, msg := range tt.msgs {
+				_, cmd = v.Update(msg)
+			}
+			if v.cursor != tt.cursor {
+				t.Errorf("Expected the cursor on row %d, got %d", tt.cursor, v.cursor)
+			}
+			if quit := cmd != nil && cmd() == tea.Quit(); quit != tt.quit {
+				t.Errorf("Expected quit %v, got %v", tt.quit, quit)
+			}
+		})
+	}
+}
+
+func TestResize(t *testing.T) {
+	after := &parser.TerraformConfig{}
+	for i := range 30 {
+		after.Variables = append(after.Variables, &schema.Variable{Name: fmt.Sprintf("v%02d", i)})
+	}
+	v := New("vars", &parser.TerraformConfig{}, after)
+
+	if v.View() != "" {
+		t.Errorf("Expected nothing drawn before the terminal size is known, got %q", v.View())
+	}
+
+	v.Update(tea.WindowSizeMsg{Width: 80, Height: 40})
+	v.Update(tea.KeyMsg{Type: tea.KeyEnd})
+	for _, size := range []tea.WindowSizeMsg{{Width: 60, Height: 10}, {Width: 120, Height: 5}} {
+		v.Update(size)
+		screen := ansi.Strip(v.View())
+		lines := strings.Split(screen, "\n")
+		if len(lines) != size.Height {
+			t.Errorf("%dx%d: expected %d lines, got %d", size.Width, size.Height, size.Height, len(lines))
+		}
+		for _, line := range lines {
+			if ansi.StringWidth(line) > size.Width {
+				t.Errorf("%dx%d: line wider than the terminal: %q", size.Width, size.Height, line)
+			}
+		}
+		if !strings.Contains(screen, "var.v29") {
+			t.Errorf("%dx%d: expected the selected row on screen:\n%s", size.Width, size.Height, screen)
+		}
+	}
+}
+
+func key(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
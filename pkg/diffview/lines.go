@@ -0,0 +1,82 @@
+package diffview
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser/schema"
+)
+
+// variableLines returns the summary of a variable (type and default) followed by its details
+func variableLines(variable *schema.Variable) []string {
+	typ := variable.Type
+	if typ == "" {
+		typ = "any"
+	}
+	summary := typ + ", required"
+	if !variable.Required {
+		summary = fmt.Sprintf("%s = %s", typ, value(variable.Default))
+	}
+
+	lines := []string{summary, "type: " + typ}
+	if variable.Required {
+		lines = append(lines, "default: none (required)")
+	} else {
+		lines = append(lines, "default: "+value(variable.Default))
+	}
+	lines = append(lines, fmt.Sprintf("sensitive: %t", variable.IsSensitive()))
+	if !variable.IsNullable() {
+		lines = append(lines, "nullable: false")
+	}
+	if variable.IsEphemeral() {
+		lines = append(lines, "ephemeral: true")
+	}
+	if variable.Description != "" {
+		lines = append(lines, "description: "+variable.Description)
+	}
+	for _, validation := range variable.Validation {
+		lines = append(lines, "validation: "+validation.Condition)
+	}
+	return lines
+}
+
+// outputLines returns the summary of an output (its references) followed by its details
+func outputLines(output *schema.Output) []string {
+	summary := strings.Join(output.References, ", ")
+	if output.IsSensitive() {
+		summary = "(sensitive) " + summary
+	}
+
+	lines := []string{summary, fmt.Sprintf("sensitive: %t", output.IsSensitive())}
+	if output.IsEphemeral() {
+		lines = append(lines, "ephemeral: true")
+	}
+	if output.Value != "" {
+		lines = append(lines, "value: "+output.Value)
+	}
+	if output.Description != "" {
+		lines = append(lines, "description: "+output.Description)
+	}
+	if len(output.References) > 0 {
+		lines = append(lines, "references: "+strings.Join(output.References, ", "))
+	}
+	if len(output.DependsOn) > 0 {
+		lines = append(lines, "depends_on: "+strings.Join(output.DependsOn, ", "))
+	}
+	for _, precondition := range output.Preconditions {
+		lines = append(lines, "precondition: "+precondition.Condition)
+	}
+	return lines
+}
+
+// value renders a default value on one line
+func value(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return fmt.Sprintf("%q", v)
+	default:
+		return strings.Join(strings.Fields(fmt.Sprint(v)), " ")
+	}
+}
@@ -0,0 +1,153 @@
+// Package doctemplate renders parsed configurations with user-provided text/template files, to
+// generate documentation or code in any format.
+package doctemplate
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+)
+
+// Funcs are the helper functions available to templates in addition to the text/template builtins:
+//
+//	sortBy "Name" .Variables   copy of a list sorted by a field, or a map's keys sorted
+//	default "n/a" .Version     the fallback when the value is empty, nil or a nil pointer
+//	anchor "Inputs"            GitHub heading anchor, e.g. "#inputs"
+//	json .Default              compact JSON encoding
+//	join ", " .DependsOn       joined list of strings
+//	indent 2 .Description      indented lines
+//	escape .Description        text safe for a Markdown table cell
+//	lower, upper, trim, replace, hasPrefix, hasSuffix, contains
+var Funcs = template.FuncMap{
+	"sortBy":    sortBy,
+	"default":   defaultValue,
+	"anchor":    anchor,
+	"json":      toJSON,
+	"join":      func(sep string, elems []string) string { return strings.Join(elems, sep) },
+	"indent":    indent,
+	"escape":    escape,
+	"lower":     strings.ToLower,
+	"upper":     strings.ToUpper,
+	"trim":      strings.TrimSpace,
+	"replace":   func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+	"hasPrefix": func(prefix, s string) bool { return strings.HasPrefix(s, prefix) },
+	"hasSuffix": func(suffix, s string) bool { return strings.HasSuffix(s, suffix) },
+	"contains":  func(substr, s string) bool { return strings.Contains(s, substr) },
+}
+
+// Parse parses a template with the helper functions; name names it in errors
+func Parse(name, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Funcs(Funcs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template %s: %w", name, err)
+	}
+	return tmpl, nil
+}
+
+// Render parses the template text and executes it with data
+func Render(w io.Writer, name, text string, data any) error {
+	tmpl, err := Parse(name, text)
+	if err != nil {
+		return err
+	}
+	if err := tmpl.Execute(w, data); err != nil {
+		return fmt.Errorf("failed to execute template %s: %w", name, err)
+	}
+	return nil
+}
+
+// sortBy returns the elements of a slice sorted by a field, compared as strings, or the keys of a
+// map in order. The slice itself is left unchanged.
+func sortBy(field string, list any) (any, error) {
+	value := reflect.ValueOf(list)
+	switch value.Kind() {
+	case reflect.Map:
+		keys := []string{}
+		for _, key := range value.MapKeys() {
+			keys = append(keys, fmt.Sprint(key.Interface()))
+		}
+		slices.Sort(keys)
+		return keys, nil
+	case reflect.Slice, reflect.Array:
+	default:
+		return nil, fmt.Errorf("sortBy: cannot sort %T", list)
+	}
+
+	keys := make([]string, value.Len())
+	for i := range keys {
+		element := reflect.Indirect(value.Index(i))
+		if element.Kind() == reflect.Interface {
+			element = reflect.Indirect(element.Elem())
+		}
+		if element.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("sortBy: elements of %T are not structs", list)
+		}
+		fieldValue := element.FieldByName(field)
+		if !fieldValue.IsValid() {
+			return nil, fmt.Errorf("sortBy: %s has no field %s", element.Type(), field)
+		}
+		keys[i] = fmt.Sprint(fieldValue.Interface())
+	}
+
+	indexes := make([]int, len(keys))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	slices.SortStableFunc(indexes, func(a, b int) int { return strings.Compare(keys[a], keys[b]) })
+
+	result := reflect.MakeSlice(reflect.SliceOf(value.Type().Elem()), len(keys), len(keys))
+	for i, index := range indexes {
+		result.Index(i).Set(value.Index(index))
+	}
+	return result.Interface(), nil
+}
+
+// defaultValue returns fallback when value is empty; non-nil pointers are dereferenced
+func defaultValue(fallback, value any) any {
+	v := reflect.ValueOf(value)
+	if !v.IsValid() {
+		return fallback
+	}
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return fallback
+		}
+		return v.Elem().Interface()
+	}
+	if v.IsZero() || ((v.Kind() == reflect.Slice || v.Kind() == reflect.Map) && v.Len() == 0) {
+		return fallback
+	}
+	return value
+}
+
+var anchorInvalidChars = regexp.MustCompile(`[^\p{L}\p{N}_ -]`)
+
+// anchor returns the anchor GitHub generates for a heading
+func anchor(heading string) string {
+	slug := anchorInvalidChars.ReplaceAllString(strings.ToLower(strings.TrimSpace(heading)), "")
+	return "#" + strings.ReplaceAll(slug, " ", "-")
+}
+
+func toJSON(value any) (string, error) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+func indent(spaces int, text string) string {
+	padding := strings.Repeat(" ", spaces)
+	return padding + strings.ReplaceAll(text, "\n", "\n"+padding)
+}
+
+// escape keeps text on a single Markdown table row
+func escape(text string) string {
+	text = strings.ReplaceAll(strings.TrimSpace(text), "|", `\|`)
+	return strings.ReplaceAll(strings.ReplaceAll(text, "\r\n", "\n"), "\n", "<br>")
+}
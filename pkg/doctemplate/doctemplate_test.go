@@ -0,0 +1,66 @@
+package doctemplate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/filesystem"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+	"github.com/spf13/afero"
+)
+
+func TestRender(t *testing.T) {
+	memFs := afero.NewMemMapFs()
+	content := `variable "region" {
+  description = "AWS | region"
+  default     = "us-east-1"
+}
+
+variable "name" {
+  type      = string
+  sensitive = true
+}
+
+output "id" {
+  value = aws_s3_bucket.this.id
+}
+`
+	if err := afero.WriteFile(memFs, "main.tf", []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tfconfig, err := parser.NewParser(filesystem.NewAferoAdapter(memFs), parser.Detail).ParseTerraformWorkspace(".")
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	if _, err := Parse("doc.tmpl", `{{ split .Name }}`); err == nil {
+		t.Error("Parse() with an unknown function should fail")
+	}
+
+	text := `## Inputs ({{ anchor "Inputs & Outputs" }})
+{{ range sortBy "Name" .Variables -}}
+{{ .Name }}|{{ default "any" .Type }}|{{ escape (default "-" .Description) }}|{{ json .Default }}|{{ default false .Sensitive }}
+{{ end -}}
+{{ indent 2 (upper "outputs") }}: {{ range .Outputs }}{{ join "," .References }}{{ end }}`
+
+	var sb strings.Builder
+	if err := Render(&sb, "doc.tmpl", text, tfconfig); err != nil {
+		t.Fatalf("Failed to render: %v", err)
+	}
+
+	expected := `## Inputs (#inputs--outputs)
+name|string|-|null|true
+region|any|AWS \| region|"us-east-1"|false
+  OUTPUTS: aws_s3_bucket.this`
+	if sb.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, sb.String())
+	}
+
+	if tfconfig.Variables[0].Name != "region" {
+		t.Error("sortBy should not reorder the configuration")
+	}
+
+	if err := Render(&sb, "doc.tmpl", `{{ sortBy "Missing" .Variables }}`, tfconfig); err == nil {
+		t.Error("Render() sorting by a missing field should fail")
+	}
+}
@@ -0,0 +1,121 @@
+// Package doctor runs self-checks of the binary and its environment and reports each problem
+// with a remediation.
+package doctor
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/config"
+	"github.com/Yunsang-Jeong/terraform-config-parser/version"
+)
+
+// Status of a check
+const (
+	StatusOK      = "ok"
+	StatusWarning = "warning"
+	StatusError   = "error"
+	// StatusSkipped checks could not run, e.g. network checks in offline mode
+	StatusSkipped = "skipped"
+)
+
+// Check is the outcome of a single check
+type Check struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+	// Remediation tells how to fix a failed check
+	Remediation string `json:"remediation,omitempty"`
+}
+
+type Report struct {
+	Checks   []*Check `json:"checks"`
+	Errors   int      `json:"errors"`
+	Warnings int      `json:"warnings"`
+}
+
+// Add records the outcome of a check
+func (r *Report) Add(check *Check) {
+	r.Checks = append(r.Checks, check)
+	switch check.Status {
+	case StatusError:
+		r.Errors++
+	case StatusWarning:
+		r.Warnings++
+	}
+}
+
+// Failed reports whether a check failed; with strict, warnings count as failures
+func (r *Report) Failed(strict bool) bool {
+	return r.Errors > 0 || (strict && r.Warnings > 0)
+}
+
+func ok(name, detail string) *Check {
+	return &Check{Name: name, Status: StatusOK, Detail: detail}
+}
+
+// CheckBuild checks the version metadata of the binary: a release version, the commit it was
+// built from and a valid build date
+func CheckBuild(info version.Info) []*Check {
+	checks := []*Check{}
+
+	if info.Version == "" || info.Version == "dev" {
+		checks = append(checks, &Check{Name: "version", Status: StatusWarning, Detail: "development build without version",
+			Remediation: "build with the release command, or -ldflags \"-X github.com/Yunsang-Jeong/terraform-config-parser/version.Version=<version>\""})
+	} else {
+		checks = append(checks, ok("version", info.Version))
+	}
+
+	switch {
+	case info.Commit == "":
+		checks = append(checks, &Check{Name: "commit", Status: StatusWarning, Detail: "the commit of the build is unknown",
+			Remediation: "build from a git checkout, or set version.Commit with -ldflags"})
+	case info.Modified:
+		checks = append(checks, &Check{Name: "commit", Status: StatusWarning, Detail: info.Commit + " with uncommitted changes",
+			Remediation: "commit or stash the changes before building a release"})
+	default:
+		checks = append(checks, ok("commit", info.Commit))
+	}
+
+	if info.BuildDate == "" {
+		checks = append(checks, &Check{Name: "build_date", Status: StatusWarning, Detail: "the build date is unknown",
+			Remediation: "build from a git checkout, or set version.BuildDate with -ldflags"})
+	} else if _, err := time.Parse(time.RFC3339, info.BuildDate); err != nil {
+		checks = append(checks, &Check{Name: "build_date", Status: StatusError, Detail: fmt.Sprintf("invalid build date %q", info.BuildDate),
+			Remediation: "set version.BuildDate to an RFC 3339 time, e.g. 2006-01-02T15:04:05Z"})
+	} else {
+		checks = append(checks, ok("build_date", info.BuildDate))
+	}
+
+	return append(checks, ok("runtime", info.GoVersion+" "+info.Platform))
+}
+
+// CheckDefaultConfig checks that the embedded default configuration parses
+func CheckDefaultConfig() *Check {
+	if _, err := config.Parse(config.DefaultYAML, "embedded default"); err != nil {
+		return &Check{Name: "default_config", Status: StatusError, Detail: err.Error(),
+			Remediation: "fix pkg/config/default.yaml and rebuild"}
+	}
+	return ok("default_config", fmt.Sprintf("%d bytes", len(config.DefaultYAML)))
+}
+
+// CheckCompletions checks that the completion script of every shell can be generated
+func CheckCompletions(generators map[string]func(w io.Writer) error) *Check {
+	shells := []string{}
+	for shell := range generators {
+		shells = append(shells, shell)
+	}
+	slices.Sort(shells)
+
+	for _, shell := range shells {
+		var buf bytes.Buffer
+		if err := generators[shell](&buf); err != nil || buf.Len() == 0 {
+			return &Check{Name: "completions", Status: StatusError, Detail: fmt.Sprintf("failed to generate the %s completion: %v", shell, err),
+				Remediation: "check the command definitions for invalid flags or arguments"}
+		}
+	}
+	return ok("completions", fmt.Sprintf("%v", shells))
+}
@@ -0,0 +1,167 @@
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// Token is an access token to check, with where it was configured, e.g. GITHUB_TOKEN or the
+// config file
+type Token struct {
+	Host   string
+	Origin string
+	Value  string
+}
+
+// CheckProxy checks the proxy settings of the environment for connections to hosts: the proxy
+// URLs must be valid, and HTTPS_PROXY must be set along with HTTP_PROXY since clones use HTTPS
+func CheckProxy(hosts []string) *Check {
+	cfg := httpproxy.FromEnvironment()
+	if cfg.HTTPProxy == "" && cfg.HTTPSProxy == "" {
+		return ok("proxy", "no proxy configured")
+	}
+
+	// Invalid proxy URLs are ignored by Go's HTTP client, which then connects directly
+	for _, setting := range [][2]string{{"HTTP_PROXY", cfg.HTTPProxy}, {"HTTPS_PROXY", cfg.HTTPSProxy}} {
+		env, value := setting[0], setting[1]
+		if err := validateProxyURL(value); err != nil {
+			return &Check{Name: "proxy", Status: StatusError, Detail: fmt.Sprintf("%s is invalid: %v", env, err),
+				Remediation: fmt.Sprintf("set %s to a URL such as http://proxy.example.com:3128", env)}
+		}
+	}
+
+	routes := []string{}
+	proxyFor := cfg.ProxyFunc()
+	for _, host := range hosts {
+		if proxy, _ := proxyFor(&url.URL{Scheme: "https", Host: host}); proxy == nil {
+			routes = append(routes, host+" direct")
+		} else {
+			routes = append(routes, host+" via "+proxy.Redacted())
+		}
+	}
+	detail := strings.Join(routes, ", ")
+	if cfg.NoProxy != "" {
+		detail += fmt.Sprintf(" (NO_PROXY: %s)", cfg.NoProxy)
+	}
+
+	if cfg.HTTPSProxy == "" {
+		return &Check{Name: "proxy", Status: StatusWarning, Detail: "HTTP_PROXY is set but HTTPS_PROXY is not; " + detail,
+			Remediation: "set HTTPS_PROXY as well, since git repositories are cloned over HTTPS"}
+	}
+	return ok("proxy", detail)
+}
+
+// validateProxyURL checks a proxy setting the way Go's HTTP client reads it: a URL, or a host
+// and port taken as an http URL
+func validateProxyURL(value string) error {
+	if value == "" {
+		return nil
+	}
+	u, err := url.Parse(value)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		if u, err = url.Parse("http://" + value); err != nil {
+			return err
+		}
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+		return nil
+	}
+	return fmt.Errorf("unsupported proxy scheme %q", u.Scheme)
+}
+
+// CheckCacheDir checks that dir can be created and written to
+func CheckCacheDir(dir string) *Check {
+	failed := func(err error) *Check {
+		return &Check{Name: "cache_dir", Status: StatusError, Detail: fmt.Sprintf("%s is not writable: %v", dir, err),
+			Remediation: "fix the permissions of the directory, or set TFPARSER_CACHE_DIR to a writable directory"}
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return failed(err)
+	}
+	f, err := os.CreateTemp(dir, ".doctor-")
+	if err != nil {
+		return failed(err)
+	}
+	_, err = f.WriteString("ok")
+	f.Close()
+	os.Remove(f.Name())
+	if err != nil {
+		return failed(err)
+	}
+	return ok("cache_dir", dir)
+}
+
+// CheckGitHost checks that host answers HTTPS requests, through the proxy of the environment
+// when one is set
+func CheckGitHost(ctx context.Context, client *http.Client, host string) *Check {
+	name := "git_host:" + host
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, "https://"+host+"/", nil)
+	if err != nil {
+		return &Check{Name: name, Status: StatusError, Detail: err.Error(), Remediation: "pass a host name such as github.com"}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return &Check{Name: name, Status: StatusError, Detail: fmt.Sprintf("cannot reach %s: %v", host, err),
+			Remediation: "check the network access to the host (DNS, firewall), or set HTTPS_PROXY when connections must go through a proxy"}
+	}
+	resp.Body.Close()
+	return ok(name, fmt.Sprintf("HTTPS %d", resp.StatusCode))
+}
+
+// CheckToken checks a token against the API of its host: GitHub, GitHub Enterprise (hosts
+// containing "github") and GitLab (hosts containing "gitlab"). Tokens of other hosts are
+// skipped since there is no API to ask.
+func CheckToken(ctx context.Context, client *http.Client, token Token) *Check {
+	name := "token:" + token.Host
+	endpoint, header, value := tokenAPI(token)
+	if endpoint == "" {
+		return &Check{Name: name, Status: StatusSkipped, Detail: fmt.Sprintf("%s is set, but %s has no known API to verify it", token.Origin, token.Host)}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return &Check{Name: name, Status: StatusError, Detail: err.Error(), Remediation: "check the host name of the credential"}
+	}
+	req.Header.Set(header, value)
+	resp, err := client.Do(req)
+	if err != nil {
+		return &Check{Name: name, Status: StatusError, Detail: fmt.Sprintf("cannot verify %s: %v", token.Origin, err),
+			Remediation: "check the network access to the host, or run doctor with --offline to skip network checks"}
+	}
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized:
+		return &Check{Name: name, Status: StatusError, Detail: fmt.Sprintf("%s was rejected by %s", token.Origin, token.Host),
+			Remediation: fmt.Sprintf("replace %s with a valid, unexpired token", token.Origin)}
+	case resp.StatusCode == http.StatusForbidden:
+		return &Check{Name: name, Status: StatusWarning, Detail: fmt.Sprintf("%s is valid but may not read repositories of %s", token.Origin, token.Host),
+			Remediation: "grant the token read access to repository contents"}
+	case resp.StatusCode >= 300:
+		return &Check{Name: name, Status: StatusWarning, Detail: fmt.Sprintf("%s answered HTTP %d when verifying %s", token.Host, resp.StatusCode, token.Origin),
+			Remediation: "check the token by cloning a private repository of the host"}
+	}
+	return ok(name, token.Origin+" is valid")
+}
+
+// tokenAPI returns the endpoint answering 401 for invalid tokens and the header carrying it
+func tokenAPI(token Token) (endpoint, header, value string) {
+	host := strings.ToLower(token.Host)
+	switch {
+	case host == "github.com":
+		return "https://api.github.com/user", "Authorization", "Bearer " + token.Value
+	case strings.Contains(host, "github"):
+		return "https://" + host + "/api/v3/user", "Authorization", "Bearer " + token.Value
+	case strings.Contains(host, "gitlab"):
+		return "https://" + host + "/api/v4/user", "PRIVATE-TOKEN", token.Value
+	}
+	return "", "", ""
+}
@@ -0,0 +1,70 @@
+// Package events reports the progress and results of fetching and parsing as structured events,
+// independently of the logger, for programs that embed the parser and render its progress
+// themselves, e.g. in a web frontend.
+package events
+
+import (
+	"time"
+)
+
+// Kind tells what an Event reports
+type Kind string
+
+const (
+	// FetchStarted is reported before a source is fetched
+	FetchStarted Kind = "fetch_started"
+	// FetchDone is reported once a source is fetched, with Error when the fetch failed
+	FetchDone Kind = "fetch_done"
+	// FileParsed is reported for every configuration file read into a directory's configuration
+	FileParsed Kind = "file_parsed"
+	// Warning is reported for every diagnostic of a parsed directory, e.g. a skipped binary file
+	// or, with block validation, an unknown argument; Severity tells warnings from errors
+	Warning Kind = "warning"
+	// ModuleDone is reported once a directory is parsed, with the number of its blocks by type in
+	// Counts, or with Error when parsing failed
+	ModuleDone Kind = "module_done"
+)
+
+// Event is a step of fetching or parsing. Only the fields of its Kind are set.
+type Event struct {
+	Kind Kind      `json:"kind"`
+	Time time.Time `json:"time"`
+
+	// Source is the kind of the fetched source (local, git or bundle), and Location its path or URL
+	Source   string `json:"source,omitempty"`
+	Location string `json:"location,omitempty"`
+	Ref      string `json:"ref,omitempty"`
+	Commit   string `json:"commit,omitempty"`
+
+	// Directory is the parsed directory, and File and Line what a file or warning event is about
+	Directory string `json:"directory,omitempty"`
+	File      string `json:"file,omitempty"`
+	Line      int    `json:"line,omitempty"`
+
+	Severity string         `json:"severity,omitempty"`
+	Summary  string         `json:"summary,omitempty"`
+	Detail   string         `json:"detail,omitempty"`
+	Counts   map[string]int `json:"counts,omitempty"`
+	Error    string         `json:"error,omitempty"`
+}
+
+// Reporter receives events as they happen, on the goroutine fetching or parsing, so it should
+// return quickly. A nil Reporter is valid and reports nothing.
+type Reporter func(Event)
+
+// Report passes e to r, with the current time when e has none
+func (r Reporter) Report(e Event) {
+	if r == nil {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	r(e)
+}
+
+// Channel returns a Reporter sending every event to ch. Sends block until they are received, so
+// ch should be buffered or drained by another goroutine while fetching and parsing.
+func Channel(ch chan<- Event) Reporter {
+	return func(e Event) { ch <- e }
+}
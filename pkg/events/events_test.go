@@ -0,0 +1,25 @@
+package events
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReporter(t *testing.T) {
+	// A nil Reporter reports nothing
+	var none Reporter
+	none.Report(Event{Kind: FetchStarted})
+
+	ch := make(chan Event, 2)
+	reporter := Channel(ch)
+	reporter.Report(Event{Kind: FileParsed, File: "main.tf"})
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	reporter.Report(Event{Kind: ModuleDone, Time: at})
+
+	if e := <-ch; e.Kind != FileParsed || e.File != "main.tf" || e.Time.IsZero() {
+		t.Errorf("Expected the file event with the current time, got %+v", e)
+	}
+	if e := <-ch; e.Kind != ModuleDone || !e.Time.Equal(at) {
+		t.Errorf("Expected the module event with its own time, got %+v", e)
+	}
+}
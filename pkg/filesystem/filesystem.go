@@ -1,3 +1,5 @@
+// Package filesystem defines the read-only view of files that the parser works on,
+// with adapters for afero and go-billy filesystems.
 package filesystem
 
 import "os"
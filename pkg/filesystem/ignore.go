@@ -0,0 +1,204 @@
+package filesystem
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// IgnoreFileName is the file of a workspace root listing, in gitignore syntax, the files and
+// directories the parser must not see
+const IgnoreFileName = ".tfparserignore"
+
+// IgnoreRules are the patterns of an ignore file
+type IgnoreRules struct {
+	patterns []*ignorePattern
+}
+
+type ignorePattern struct {
+	regex   *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// ParseIgnoreRules reads patterns in gitignore syntax: blank lines and # comments are skipped,
+// ! re-includes, a trailing / only matches directories, a pattern with a / elsewhere is relative
+// to the root while others match at any depth, and *, ?, [...] and ** are wildcards.
+func ParseIgnoreRules(content []byte) (*IgnoreRules, error) {
+	rules := &IgnoreRules{}
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimRight(strings.TrimSuffix(line, "\r"), " ")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		pattern := &ignorePattern{}
+		if rest, ok := strings.CutPrefix(line, "!"); ok {
+			pattern.negate, line = true, rest
+		} else if strings.HasPrefix(line, `\#`) || strings.HasPrefix(line, `\!`) {
+			line = line[1:]
+		}
+		if rest, ok := strings.CutSuffix(line, "/"); ok {
+			pattern.dirOnly, line = true, rest
+		}
+		anchored := strings.Contains(line, "/")
+		line = strings.TrimPrefix(line, "/")
+
+		expr := "^(?:.*/)?"
+		if anchored {
+			expr = "^"
+		}
+		regex, err := regexp.Compile(expr + globToRegex(line) + "$")
+		if err != nil {
+			return nil, err
+		}
+		pattern.regex = regex
+		rules.patterns = append(rules.patterns, pattern)
+	}
+	return rules, nil
+}
+
+func globToRegex(glob string) string {
+	var sb strings.Builder
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			switch {
+			case strings.HasPrefix(glob[i:], "**/"):
+				// Any number of directories, none included
+				sb.WriteString("(?:.*/)?")
+				i += 2
+			case strings.HasPrefix(glob[i:], "**"):
+				sb.WriteString(".*")
+				i++
+			default:
+				sb.WriteString("[^/]*")
+			}
+		case '?':
+			sb.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				sb.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if rest, ok := strings.CutPrefix(class, "!"); ok {
+				class = "^" + rest
+			}
+			sb.WriteString("[" + class + "]")
+			i += end + 1
+		case '\\':
+			if i+1 < len(glob) {
+				i++
+				sb.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+			}
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return sb.String()
+}
+
+// Ignored reports whether the slash-separated path relative to the root is ignored. Like git,
+// nothing inside an ignored directory can be re-included.
+func (r *IgnoreRules) Ignored(rel string, isDir bool) bool {
+	if r == nil || rel == "." || rel == "" {
+		return false
+	}
+
+	parts := strings.Split(rel, "/")
+	for i := 1; i < len(parts); i++ {
+		if r.match(strings.Join(parts[:i], "/"), true) {
+			return true
+		}
+	}
+	return r.match(rel, isDir)
+}
+
+// match applies the patterns in order, the last matching one deciding
+func (r *IgnoreRules) match(rel string, isDir bool) bool {
+	ignored := false
+	for _, pattern := range r.patterns {
+		if pattern.dirOnly && !isDir {
+			continue
+		}
+		if pattern.regex.MatchString(rel) {
+			ignored = !pattern.negate
+		}
+	}
+	return ignored
+}
+
+// IgnoringReader hides the files and directories under root that its rules ignore
+type IgnoringReader struct {
+	FileReader
+	root  string
+	rules *IgnoreRules
+}
+
+// WithIgnoreFile applies the ignore file of root, if any, to fs
+func WithIgnoreFile(fs FileReader, root string) (FileReader, error) {
+	content, err := fs.ReadFile(filepath.Join(root, IgnoreFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return fs, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := ParseIgnoreRules(content)
+	if err != nil {
+		return nil, err
+	}
+	return &IgnoringReader{FileReader: fs, root: root, rules: rules}, nil
+}
+
+// ignored reports whether name is below root and ignored
+func (r *IgnoringReader) ignored(name string, isDir bool) bool {
+	rel, err := filepath.Rel(r.root, name)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, "../") {
+		return false
+	}
+	return r.rules.Ignored(filepath.ToSlash(rel), isDir)
+}
+
+// Ignores reports whether the file or directory name is hidden by the ignore file
+func (r *IgnoringReader) Ignores(name string, isDir bool) bool {
+	return r.ignored(name, isDir)
+}
+
+func (r *IgnoringReader) DirExists(dirname string) (bool, error) {
+	if r.ignored(dirname, true) {
+		return false, nil
+	}
+	return r.FileReader.DirExists(dirname)
+}
+
+func (r *IgnoringReader) ReadDir(dirname string) ([]os.FileInfo, error) {
+	if r.ignored(dirname, true) {
+		return nil, &os.PathError{Op: "readdir", Path: dirname, Err: os.ErrNotExist}
+	}
+
+	entries, err := r.FileReader.ReadDir(dirname)
+	if err != nil {
+		return nil, err
+	}
+
+	visible := make([]os.FileInfo, 0, len(entries))
+	for _, entry := range entries {
+		if !r.ignored(filepath.Join(dirname, entry.Name()), entry.IsDir()) {
+			visible = append(visible, entry)
+		}
+	}
+	return visible, nil
+}
+
+func (r *IgnoringReader) ReadFile(filename string) ([]byte, error) {
+	if r.ignored(filename, false) {
+		return nil, &os.PathError{Op: "open", Path: filename, Err: os.ErrNotExist}
+	}
+	return r.FileReader.ReadFile(filename)
+}
@@ -0,0 +1,162 @@
+package graph
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+)
+
+type NodeKind string
+
+const (
+	NodeVariable   NodeKind = "variable"
+	NodeOutput     NodeKind = "output"
+	NodeResource   NodeKind = "resource"
+	NodeDataSource NodeKind = "data"
+	NodeEphemeral  NodeKind = "ephemeral"
+	NodeModule     NodeKind = "module"
+	NodeLocal      NodeKind = "local"
+)
+
+type EdgeKind string
+
+const (
+	// EdgeExplicit is a dependency declared with depends_on
+	EdgeExplicit EdgeKind = "explicit"
+	// EdgeImplicit is a dependency inferred from a reference in an expression
+	EdgeImplicit EdgeKind = "implicit"
+)
+
+type Node struct {
+	ID   string   `json:"id"`
+	Kind NodeKind `json:"kind"`
+}
+
+// Edge points from a dependent object to the object it depends on
+type Edge struct {
+	From string   `json:"from"`
+	To   string   `json:"to"`
+	Kind EdgeKind `json:"kind"`
+}
+
+type Graph struct {
+	Nodes []Node `json:"nodes"`
+	Edges []Edge `json:"edges"`
+}
+
+// Build creates the dependency graph of a parsed configuration
+func Build(tfconfig *parser.TerraformConfig) *Graph {
+	g := &Graph{Nodes: []Node{}, Edges: []Edge{}}
+	nodes := map[string]bool{}
+
+	addNode := func(id string, kind NodeKind) {
+		if !nodes[id] {
+			nodes[id] = true
+			g.Nodes = append(g.Nodes, Node{ID: id, Kind: kind})
+		}
+	}
+	addEdges := func(from string, targets []string, kind EdgeKind) {
+		for _, to := range targets {
+			g.Edges = append(g.Edges, Edge{From: from, To: to, Kind: kind})
+		}
+	}
+
+	for _, variable := range tfconfig.Variables {
+		addNode(variable.Address(), NodeVariable)
+	}
+	for _, local := range tfconfig.Locals {
+		addNode(local.Address(), NodeLocal)
+		addEdges(local.Address(), local.References, EdgeImplicit)
+	}
+	for _, resource := range tfconfig.Resources {
+		addNode(resource.Address(), NodeResource)
+		addEdges(resource.Address(), resource.DependsOn, EdgeExplicit)
+		addEdges(resource.Address(), resource.References, EdgeImplicit)
+	}
+	for _, data := range tfconfig.DataSources {
+		addNode(data.Address(), NodeDataSource)
+		addEdges(data.Address(), data.DependsOn, EdgeExplicit)
+		addEdges(data.Address(), data.References, EdgeImplicit)
+	}
+	for _, ephemeral := range tfconfig.EphemeralResources {
+		addNode(ephemeral.Address(), NodeEphemeral)
+		addEdges(ephemeral.Address(), ephemeral.DependsOn, EdgeExplicit)
+		addEdges(ephemeral.Address(), ephemeral.References, EdgeImplicit)
+	}
+	for _, module := range tfconfig.Modules {
+		addNode(module.Address(), NodeModule)
+		addEdges(module.Address(), module.DependsOn, EdgeExplicit)
+		addEdges(module.Address(), module.References, EdgeImplicit)
+	}
+	for _, output := range tfconfig.Outputs {
+		addNode(output.Address(), NodeOutput)
+		addEdges(output.Address(), output.DependsOn, EdgeExplicit)
+		addEdges(output.Address(), output.References, EdgeImplicit)
+	}
+
+	// Edges may point at objects outside of the parsed configuration (e.g. typos)
+	for _, edge := range g.Edges {
+		addNode(edge.To, kindOf(edge.To))
+	}
+
+	sort.SliceStable(g.Edges, func(i, j int) bool {
+		if g.Edges[i].From != g.Edges[j].From {
+			return g.Edges[i].From < g.Edges[j].From
+		}
+		if g.Edges[i].To != g.Edges[j].To {
+			return g.Edges[i].To < g.Edges[j].To
+		}
+		return g.Edges[i].Kind < g.Edges[j].Kind
+	})
+
+	return g
+}
+
+// kindOf infers the node kind from an address
+func kindOf(address string) NodeKind {
+	switch {
+	case strings.HasPrefix(address, "var."):
+		return NodeVariable
+	case strings.HasPrefix(address, "output."):
+		return NodeOutput
+	case strings.HasPrefix(address, "local."):
+		return NodeLocal
+	case strings.HasPrefix(address, "data."):
+		return NodeDataSource
+	case strings.HasPrefix(address, "ephemeral."):
+		return NodeEphemeral
+	case strings.HasPrefix(address, "module."):
+		return NodeModule
+	default:
+		return NodeResource
+	}
+}
+
+// DOT renders the graph in Graphviz format
+func (g *Graph) DOT() string {
+	var sb strings.Builder
+
+	sb.WriteString("digraph {\n")
+	for _, node := range g.Nodes {
+		fmt.Fprintf(&sb, "  %q [label=%q, shape=%s];\n", node.ID, node.ID, shapeOf(node.Kind))
+	}
+	for _, edge := range g.Edges {
+		fmt.Fprintf(&sb, "  %q -> %q [label=%q];\n", edge.From, edge.To, edge.Kind)
+	}
+	sb.WriteString("}")
+
+	return sb.String()
+}
+
+func shapeOf(kind NodeKind) string {
+	switch kind {
+	case NodeVariable, NodeOutput, NodeLocal:
+		return "ellipse"
+	case NodeModule:
+		return "component"
+	default:
+		return "box"
+	}
+}
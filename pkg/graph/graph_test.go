@@ -0,0 +1,148 @@
+package graph
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+)
+
+const testConfig = `
+variable "name" {
+  type = string
+}
+
+locals {
+  prefix = "${var.name}-app"
+}
+
+resource "aws_s3_bucket" "logs" {
+  bucket = "${local.prefix}-logs"
+}
+
+resource "aws_instance" "web" {
+  tags = {
+    Name = local.prefix
+    Logs = aws_s3_bucket.logs.id
+  }
+
+  depends_on = [aws_s3_bucket.logs, aws_iam_role.missing]
+}
+
+module "dns" {
+  source = "./dns"
+  target = aws_instance.web.public_ip
+}
+
+output "ip" {
+  value = module.dns.record
+}
+`
+
+func buildGraph(t *testing.T) *Graph {
+	t.Helper()
+
+	tfconfig, err := parser.NewParser(nil, parser.Detail).ParseHCLBytes("main.tf", []byte(testConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Build(tfconfig)
+}
+
+func TestBuild(t *testing.T) {
+	g := buildGraph(t)
+
+	expected := []Edge{
+		{From: "aws_instance.web", To: "aws_iam_role.missing", Kind: EdgeExplicit},
+		{From: "aws_instance.web", To: "aws_s3_bucket.logs", Kind: EdgeExplicit},
+		{From: "aws_instance.web", To: "aws_s3_bucket.logs", Kind: EdgeImplicit},
+		{From: "aws_instance.web", To: "local.prefix", Kind: EdgeImplicit},
+		{From: "aws_s3_bucket.logs", To: "local.prefix", Kind: EdgeImplicit},
+		{From: "local.prefix", To: "var.name", Kind: EdgeImplicit},
+		{From: "module.dns", To: "aws_instance.web", Kind: EdgeImplicit},
+		{From: "output.ip", To: "module.dns", Kind: EdgeImplicit},
+	}
+	if !slices.Equal(g.Edges, expected) {
+		t.Errorf("Expected edges:\n%v\ngot:\n%v", expected, g.Edges)
+	}
+}
+
+func TestBuildNodes(t *testing.T) {
+	g := buildGraph(t)
+
+	kinds := map[string]NodeKind{}
+	for _, node := range g.Nodes {
+		if _, ok := kinds[node.ID]; ok {
+			t.Errorf("Node %s is listed twice", node.ID)
+		}
+		kinds[node.ID] = node.Kind
+	}
+
+	tests := map[string]NodeKind{
+		"var.name":           NodeVariable,
+		"local.prefix":       NodeLocal,
+		"aws_s3_bucket.logs": NodeResource,
+		"aws_instance.web":   NodeResource,
+		"module.dns":         NodeModule,
+		"output.ip":          NodeOutput,
+		// Targets outside of the configuration get a node of the kind of their address
+		"aws_iam_role.missing": NodeResource,
+	}
+	for id, expected := range tests {
+		if kind, ok := kinds[id]; !ok || kind != expected {
+			t.Errorf("Expected node %s of kind %s, got %q", id, expected, kind)
+		}
+	}
+	if len(kinds) != len(tests) {
+		t.Errorf("Expected %d nodes, got %v", len(tests), kinds)
+	}
+}
+
+func TestKindOf(t *testing.T) {
+	tests := map[string]NodeKind{
+		"var.x":              NodeVariable,
+		"output.x":           NodeOutput,
+		"local.x":            NodeLocal,
+		"data.aws_ami.x":     NodeDataSource,
+		"ephemeral.random.x": NodeEphemeral,
+		"module.x":           NodeModule,
+		"aws_instance.x":     NodeResource,
+	}
+
+	for address, expected := range tests {
+		if kind := kindOf(address); kind != expected {
+			t.Errorf("kindOf(%q) = %s, expected %s", address, kind, expected)
+		}
+	}
+}
+
+func TestDOT(t *testing.T) {
+	g := &Graph{
+		Nodes: []Node{
+			{ID: "var.name", Kind: NodeVariable},
+			{ID: "aws_instance.web", Kind: NodeResource},
+			{ID: "module.dns", Kind: NodeModule},
+		},
+		Edges: []Edge{
+			{From: "aws_instance.web", To: "var.name", Kind: EdgeImplicit},
+			{From: "module.dns", To: "aws_instance.web", Kind: EdgeExplicit},
+		},
+	}
+
+	expected := `digraph {
+  "var.name" [label="var.name", shape=ellipse];
+  "aws_instance.web" [label="aws_instance.web", shape=box];
+  "module.dns" [label="module.dns", shape=component];
+  "aws_instance.web" -> "var.name" [label="implicit"];
+  "module.dns" -> "aws_instance.web" [label="explicit"];
+}`
+	if got := g.DOT(); got != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, got)
+	}
+
+	// Addresses with instance keys are quoted
+	keyed := &Graph{Nodes: []Node{{ID: `aws_instance.web["a"]`, Kind: NodeResource}}}
+	if got := keyed.DOT(); got != "digraph {\n  \"aws_instance.web[\\\"a\\\"]\" [label=\"aws_instance.web[\\\"a\\\"]\", shape=box];\n}" {
+		t.Errorf("Unexpected DOT for a keyed address:\n%s", got)
+	}
+}
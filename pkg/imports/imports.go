@@ -0,0 +1,105 @@
+package imports
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+)
+
+// Mapping links an import target address to the ID expression used to import it
+type Mapping struct {
+	Address  string `json:"address"`
+	ID       string `json:"id"`
+	Literal  bool   `json:"literal"`
+	Provider string `json:"provider,omitempty"`
+	// Declared reports whether the target resource block exists in the configuration or, for
+	// targets inside child modules, whether the module call does
+	Declared bool `json:"declared"`
+}
+
+type Report struct {
+	Mappings []Mapping `json:"mappings"`
+	// Unmapped lists resources that no import block targets
+	Unmapped []string `json:"unmapped"`
+}
+
+// BuildReport maps import blocks onto the resources of tfconfig
+func BuildReport(tfconfig *parser.TerraformConfig) *Report {
+	report := &Report{Mappings: []Mapping{}, Unmapped: []string{}}
+
+	declared := map[string]bool{}
+	for _, resource := range tfconfig.Resources {
+		declared[resource.Address()] = true
+	}
+	for _, module := range tfconfig.Modules {
+		declared[module.Address()] = true
+	}
+
+	imported := map[string]bool{}
+	for _, imp := range tfconfig.Imports {
+		base := baseAddress(imp.To)
+		imported[base] = true
+		// The resources of child modules are not in this configuration, only their module call
+		if call, ok := moduleCall(imp.To); ok {
+			base = call
+		}
+
+		report.Mappings = append(report.Mappings, Mapping{
+			Address:  imp.To,
+			ID:       imp.ID,
+			Literal:  imp.IDIsLiteral,
+			Provider: imp.Provider,
+			Declared: declared[base],
+		})
+	}
+
+	for _, resource := range tfconfig.Resources {
+		if !imported[resource.Address()] {
+			report.Unmapped = append(report.Unmapped, resource.Address())
+		}
+	}
+
+	return report
+}
+
+// Commands renders the mappings as terraform import CLI invocations.
+// Mappings with computed IDs are emitted as comments because the ID is only known at plan time.
+func (r *Report) Commands() []string {
+	commands := make([]string, 0, len(r.Mappings))
+
+	for _, mapping := range r.Mappings {
+		if !mapping.Literal {
+			commands = append(commands, fmt.Sprintf("# %s: id is computed from %s", mapping.Address, mapping.ID))
+			continue
+		}
+		commands = append(commands, fmt.Sprintf("terraform import %s %s", shellQuote(mapping.Address), shellQuote(mapping.ID)))
+	}
+
+	return commands
+}
+
+// baseAddress strips instance keys from a resource address, e.g. aws_instance.web["a"] -> aws_instance.web
+func baseAddress(address string) string {
+	if idx := strings.Index(address, "["); idx >= 0 {
+		return address[:idx]
+	}
+	return address
+}
+
+// moduleCall returns the module call of an address inside a child module, e.g.
+// module.vpc["a"].aws_subnet.this -> module.vpc
+func moduleCall(address string) (string, bool) {
+	name, ok := strings.CutPrefix(address, "module.")
+	if !ok {
+		return "", false
+	}
+	if idx := strings.IndexAny(name, ".["); idx >= 0 {
+		name = name[:idx]
+	}
+	return "module." + name, true
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,192 @@
+package imports
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+)
+
+const testConfig = `
+resource "aws_instance" "web" {
+  ami = "ami-123"
+}
+
+resource "aws_s3_bucket" "logs" {
+  bucket = "logs"
+}
+
+resource "aws_iam_role" "ci" {
+  name = "ci"
+}
+
+module "vpc" {
+  source = "./vpc"
+}
+
+import {
+  to = aws_instance.web
+  id = "i-0123456789"
+}
+
+import {
+  to = aws_s3_bucket.logs
+  id = "logs-${var.env}"
+}
+
+import {
+  to = aws_iam_role.missing
+  id = "it's a role"
+}
+
+import {
+  to = module.vpc["a"].aws_vpc.this
+  id = "vpc-123"
+}
+
+import {
+  to = module.network.aws_subnet.this
+  id = "subnet-123"
+}
+`
+
+func parseConfig(t *testing.T, content string) *parser.TerraformConfig {
+	t.Helper()
+
+	tfconfig, err := parser.NewParser(nil, parser.Detail).ParseHCLBytes("main.tf", []byte(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tfconfig
+}
+
+func TestBuildReport(t *testing.T) {
+	report := BuildReport(parseConfig(t, testConfig))
+
+	tests := []struct {
+		address  string
+		id       string
+		literal  bool
+		declared bool
+	}{
+		{address: "aws_instance.web", id: "i-0123456789", literal: true, declared: true},
+		{address: "aws_s3_bucket.logs", id: `"logs-${var.env}"`, literal: false, declared: true},
+		{address: "aws_iam_role.missing", id: "it's a role", literal: true, declared: false},
+		{address: `module.vpc["a"].aws_vpc.this`, id: "vpc-123", literal: true, declared: true},
+		{address: "module.network.aws_subnet.this", id: "subnet-123", literal: true, declared: false},
+	}
+
+	if len(report.Mappings) != len(tests) {
+		t.Fatalf("Expected %d mappings, got %d: %+v", len(tests), len(report.Mappings), report.Mappings)
+	}
+	for i, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			mapping := report.Mappings[i]
+			if mapping.Address != tt.address {
+				t.Errorf("Expected address %s, got %s", tt.address, mapping.Address)
+			}
+			if mapping.ID != tt.id {
+				t.Errorf("Expected id %q, got %q", tt.id, mapping.ID)
+			}
+			if mapping.Literal != tt.literal {
+				t.Errorf("Expected literal %v, got %v", tt.literal, mapping.Literal)
+			}
+			if mapping.Declared != tt.declared {
+				t.Errorf("Expected declared %v, got %v", tt.declared, mapping.Declared)
+			}
+		})
+	}
+
+	if !slices.Equal(report.Unmapped, []string{"aws_iam_role.ci"}) {
+		t.Errorf("Expected aws_iam_role.ci to be unmapped, got %v", report.Unmapped)
+	}
+}
+
+func TestLiteralIDs(t *testing.T) {
+	tests := []struct {
+		name     string
+		id       string
+		literal  bool
+		expected string
+	}{
+		{name: "String", id: `"i-123"`, literal: true, expected: "i-123"},
+		{name: "Template of literals", id: `"i-${"123"}"`, literal: true, expected: "i-123"},
+		{name: "Quotes and spaces", id: `"my \"quoted\" id"`, literal: true, expected: `my "quoted" id`},
+		{name: "Variable", id: `var.id`, literal: false, expected: "var.id"},
+		{name: "Template with a reference", id: `"i-${var.id}"`, literal: false, expected: `"i-${var.id}"`},
+		{name: "Function call", id: `format("i-%s", "123")`, literal: false, expected: `format("i-%s", "123")`},
+		{name: "Null", id: `null`, literal: false, expected: "null"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tfconfig := parseConfig(t, "import {\n  to = aws_instance.web\n  id = "+tt.id+"\n}\n")
+			if len(tfconfig.Imports) != 1 {
+				t.Fatalf("Expected 1 import, got %d", len(tfconfig.Imports))
+			}
+			if got := tfconfig.Imports[0].IDIsLiteral; got != tt.literal {
+				t.Errorf("Expected literal %v for %s, got %v", tt.literal, tt.id, got)
+			}
+			if got := tfconfig.Imports[0].ID; got != tt.expected {
+				t.Errorf("Expected id %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestCommands(t *testing.T) {
+	report := &Report{Mappings: []Mapping{
+		{Address: "aws_instance.web", ID: "i-123", Literal: true},
+		{Address: `aws_instance.web["a b"]`, ID: `my "quoted" id`, Literal: true},
+		{Address: "aws_iam_role.ci", ID: "it's a role", Literal: true},
+		{Address: "aws_s3_bucket.logs", ID: "logs-${var.env}", Literal: false},
+	}}
+
+	expected := []string{
+		`terraform import 'aws_instance.web' 'i-123'`,
+		`terraform import 'aws_instance.web["a b"]' 'my "quoted" id'`,
+		`terraform import 'aws_iam_role.ci' 'it'\''s a role'`,
+		`# aws_s3_bucket.logs: id is computed from logs-${var.env}`,
+	}
+	if got := report.Commands(); !slices.Equal(got, expected) {
+		t.Errorf("Expected commands:\n%v\ngot:\n%v", expected, got)
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	tests := map[string]string{
+		"":             `''`,
+		"plain":        `'plain'`,
+		"with space":   `'with space'`,
+		`"double"`:     `'"double"'`,
+		"it's":         `'it'\''s'`,
+		"$(rm -rf /)":  `'$(rm -rf /)'`,
+		"a'b'c":        `'a'\''b'\''c'`,
+		"line\nbreak":  "'line\nbreak'",
+		"back\\slash":  `'back\slash'`,
+		"`backticks`":  "'`backticks`'",
+		"semi;colon&&": `'semi;colon&&'`,
+	}
+
+	for input, expected := range tests {
+		if got := shellQuote(input); got != expected {
+			t.Errorf("shellQuote(%q) = %s, expected %s", input, got, expected)
+		}
+	}
+}
+
+func TestModuleCall(t *testing.T) {
+	tests := map[string]string{
+		"module.vpc.aws_vpc.this":              "module.vpc",
+		`module.vpc["a"].aws_vpc.this`:         "module.vpc",
+		"module.vpc[0].module.subnets.aws_x.y": "module.vpc",
+		"aws_instance.web":                     "",
+	}
+
+	for address, expected := range tests {
+		call, ok := moduleCall(address)
+		if call != expected || ok != (expected != "") {
+			t.Errorf("moduleCall(%q) = %q, %v, expected %q", address, call, ok, expected)
+		}
+	}
+}
@@ -0,0 +1,318 @@
+package jsonsyntax
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+// ToHCL converts a Terraform JSON syntax file into the native syntax.
+//
+// Nested blocks are recognized by the names Terraform defines for them and, in bodies defined
+// by a provider schema, when written as arrays of objects; strings consisting of a single
+// "${...}" interpolation become bare expressions.
+func ToHCL(src []byte, filename string) ([]byte, error) {
+	return toHCL(src, filename, false)
+}
+
+// ToAlignedHCL converts like ToHCL, but starts every block, attribute and object item on the
+// line of its JSON member, so that line numbers of the translation point into the JSON source.
+// Members sharing a line with the one before them are moved to the next free line.
+func ToAlignedHCL(src []byte, filename string) ([]byte, error) {
+	return toHCL(src, filename, true)
+}
+
+func toHCL(src []byte, filename string, aligned bool) ([]byte, error) {
+	value, err := decodeOrdered(src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode JSON in %s: %w", filename, err)
+	}
+	root, ok := value.(object)
+	if !ok {
+		return nil, fmt.Errorf("%s must contain a JSON object", filename)
+	}
+
+	w := &hclWriter{aligned: aligned, line: 1}
+	for _, m := range root {
+		if m.Key == "//" {
+			continue
+		}
+		labels, ok := topLevelLabels[m.Key]
+		if !ok {
+			return nil, fmt.Errorf("unsupported top-level block type %q", m.Key)
+		}
+		if err := w.blocks(m.Key, m.Value, labels, nil, m.Line, schemaBodies[m.Key]); err != nil {
+			return nil, err
+		}
+	}
+
+	return hclwrite.Format([]byte(w.sb.String())), nil
+}
+
+type hclWriter struct {
+	sb      strings.Builder
+	aligned bool
+	// line is the line the next write starts on
+	line int
+}
+
+func (w *hclWriter) write(s string) {
+	w.sb.WriteString(s)
+	w.line += strings.Count(s, "\n")
+}
+
+// align moves to line, when aligned and line is ahead
+func (w *hclWriter) align(line int) {
+	if w.aligned && line > w.line {
+		w.write(strings.Repeat("\n", line-w.line))
+	}
+}
+
+// blocks writes the blocks of blockType, consuming one object level per remaining label.
+// line is the line of the member the blocks come from, schema whether their body follows a schema.
+func (w *hclWriter) blocks(blockType string, value any, remaining int, labels []string, line int, schema bool) error {
+	switch v := value.(type) {
+	case []any:
+		for _, item := range v {
+			if err := w.blocks(blockType, item, remaining, labels, line, schema); err != nil {
+				return err
+			}
+		}
+		return nil
+	case object:
+		if remaining > 0 {
+			for _, m := range v {
+				if err := w.blocks(blockType, m.Value, remaining-1, append(labels[:len(labels):len(labels)], m.Key), m.Line, schema); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		if !w.aligned && w.sb.Len() > 0 && !strings.HasSuffix(w.sb.String(), "{\n") {
+			w.write("\n")
+		}
+		w.align(line)
+		w.write(blockType)
+		for _, label := range labels {
+			w.write(" " + quote(label))
+		}
+		w.write(" {\n")
+		if err := w.body(blockType, v, schema); err != nil {
+			return err
+		}
+		w.write("}\n")
+		return nil
+	}
+
+	return fmt.Errorf("%s block must be a JSON object or an array of objects", blockType)
+}
+
+func (w *hclWriter) body(blockType string, body object, schema bool) error {
+	for _, m := range body {
+		if m.Key == "//" {
+			continue
+		}
+
+		if labels, ok := nestedBlock(blockType, m.Key, schema); ok && isBlockValue(m.Value, false) {
+			if err := w.blocks(m.Key, m.Value, labels, nil, m.Line, schemaBodies[m.Key]); err != nil {
+				return err
+			}
+			continue
+		}
+		if schema && isBlockValue(m.Value, true) {
+			if err := w.blocks(m.Key, m.Value, 0, nil, m.Line, true); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if !hclsyntax.ValidIdentifier(m.Key) {
+			return fmt.Errorf("invalid attribute name %q in %s block", m.Key, blockType)
+		}
+		w.align(m.Line)
+		w.write(m.Key + " = ")
+		if isStatic(blockType, m.Key) {
+			w.static(m.Value)
+		} else {
+			w.expression(m.Value)
+		}
+		w.write("\n")
+	}
+
+	return nil
+}
+
+// isBlockValue reports whether value has the shape of nested blocks.
+// Unless a bare object is allowed, only non-empty arrays of objects qualify.
+func isBlockValue(value any, arrayOnly bool) bool {
+	switch v := value.(type) {
+	case object:
+		return !arrayOnly
+	case []any:
+		if len(v) == 0 {
+			return false
+		}
+		for _, item := range v {
+			if _, ok := item.(object); !ok {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// static writes bare strings as expressions
+func (w *hclWriter) static(value any) {
+	switch v := value.(type) {
+	case string:
+		w.write(v)
+	case []any:
+		w.write("[")
+		for i, item := range v {
+			if i > 0 {
+				w.write(", ")
+			}
+			w.static(item)
+		}
+		w.write("]")
+	case object:
+		w.write("{\n")
+		for _, m := range v {
+			w.align(m.Line)
+			w.write(objectKeyText(m.Key) + " = ")
+			w.static(m.Value)
+			w.write("\n")
+		}
+		w.write("}")
+	default:
+		w.expression(v)
+	}
+}
+
+func (w *hclWriter) expression(value any) {
+	switch v := value.(type) {
+	case nil:
+		w.write("null")
+	case bool:
+		w.write(fmt.Sprint(v))
+	case json.Number:
+		w.write(v.String())
+	case string:
+		if inner, ok := unwrapInterpolation(v); ok {
+			w.write(inner)
+		} else {
+			w.write(quote(v))
+		}
+	case []any:
+		w.write("[")
+		for i, item := range v {
+			if i > 0 {
+				w.write(", ")
+			}
+			w.expression(item)
+		}
+		w.write("]")
+	case object:
+		w.write("{\n")
+		for _, m := range v {
+			w.align(m.Line)
+			w.write(objectKeyText(m.Key) + " = ")
+			w.expression(m.Value)
+			w.write("\n")
+		}
+		w.write("}")
+	}
+}
+
+// unwrapInterpolation returns the expression of a template that is exactly one "${...}" sequence
+func unwrapInterpolation(template string) (string, bool) {
+	if !strings.HasPrefix(template, "${") || !strings.HasSuffix(template, "}") {
+		return "", false
+	}
+
+	expr, diags := hclsyntax.ParseTemplate([]byte(template), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return "", false
+	}
+	wrap, ok := expr.(*hclsyntax.TemplateWrapExpr)
+	if !ok {
+		return "", false
+	}
+
+	rng := wrap.Wrapped.Range()
+	return template[rng.Start.Byte:rng.End.Byte], true
+}
+
+func objectKeyText(key string) string {
+	if hclsyntax.ValidIdentifier(key) {
+		return key
+	}
+	return quote(key)
+}
+
+// quote writes a native syntax quoted template. Template sequences are kept as is,
+// only the literal text around them is escaped.
+func quote(s string) string {
+	tokens, diags := hclsyntax.LexTemplate([]byte(s), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return `"` + escapeLiteral(s) + `"`
+	}
+
+	var sb strings.Builder
+	sb.WriteByte('"')
+	depth, offset := 0, 0
+	for _, token := range tokens {
+		start, end := token.Range.Start.Byte, token.Range.End.Byte
+		sb.WriteString(s[offset:start])
+		offset = end
+
+		switch token.Type {
+		case hclsyntax.TokenTemplateInterp, hclsyntax.TokenTemplateControl:
+			depth++
+		case hclsyntax.TokenTemplateSeqEnd:
+			depth--
+		case hclsyntax.TokenStringLit:
+			if depth == 0 {
+				sb.WriteString(escapeLiteral(s[start:end]))
+				continue
+			}
+		case hclsyntax.TokenEOF:
+			continue
+		}
+		sb.WriteString(s[start:end])
+	}
+	sb.WriteString(s[offset:])
+	sb.WriteByte('"')
+	return sb.String()
+}
+
+func escapeLiteral(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		switch r {
+		case '\\':
+			sb.WriteString(`\\`)
+		case '"':
+			sb.WriteString(`\"`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\t':
+			sb.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(&sb, `\u%04x`, r)
+			} else {
+				sb.WriteRune(r)
+			}
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,299 @@
+package jsonsyntax
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// ToJSON converts a native syntax file into the Terraform JSON syntax.
+// Comments are not preserved.
+func ToJSON(file *hcl.File) ([]byte, error) {
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return nil, fmt.Errorf("file is not in native syntax")
+	}
+
+	if len(body.Attributes) > 0 {
+		return nil, fmt.Errorf("top-level attributes are not supported")
+	}
+	for _, block := range body.Blocks {
+		labels, ok := topLevelLabels[block.Type]
+		if !ok {
+			return nil, fmt.Errorf("unsupported top-level block type %q", block.Type)
+		}
+		if len(block.Labels) != labels {
+			return nil, fmt.Errorf("%s block must have %d labels", block.Type, labels)
+		}
+	}
+
+	enc := &jsonEncoder{src: file.Bytes}
+	root := object{}
+	for _, group := range groupBlocks(body.Blocks) {
+		root = append(root, member{Key: group[0].Type, Value: enc.blockGroup(group, 0)})
+	}
+
+	var buf bytes.Buffer
+	content, err := marshal(root)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Indent(&buf, content, "", "  "); err != nil {
+		return nil, err
+	}
+	buf.WriteByte('\n')
+
+	return buf.Bytes(), nil
+}
+
+type jsonEncoder struct {
+	src []byte
+}
+
+func (e *jsonEncoder) raw(rng hcl.Range) string {
+	return string(e.src[rng.Start.Byte:rng.End.Byte])
+}
+
+// groupBlocks groups blocks by type in order of first appearance
+func groupBlocks(blocks hclsyntax.Blocks) [][]*hclsyntax.Block {
+	groups := [][]*hclsyntax.Block{}
+	index := map[string]int{}
+
+	for _, block := range blocks {
+		i, ok := index[block.Type]
+		if !ok {
+			i = len(groups)
+			index[block.Type] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], block)
+	}
+
+	return groups
+}
+
+// blockGroup nests blocks of the same type by their labels starting at depth.
+// Blocks sharing all labels are written as an array of bodies.
+func (e *jsonEncoder) blockGroup(blocks []*hclsyntax.Block, depth int) any {
+	if depth == len(blocks[0].Labels) {
+		if len(blocks) == 1 {
+			return e.body(blocks[0].Type, blocks[0].Body)
+		}
+		bodies := []any{}
+		for _, block := range blocks {
+			bodies = append(bodies, e.body(block.Type, block.Body))
+		}
+		return bodies
+	}
+
+	nested := object{}
+	index := map[string]int{}
+	grouped := [][]*hclsyntax.Block{}
+	for _, block := range blocks {
+		label := block.Labels[depth]
+		i, ok := index[label]
+		if !ok {
+			i = len(grouped)
+			index[label] = i
+			grouped = append(grouped, nil)
+			nested = append(nested, member{Key: label})
+		}
+		grouped[i] = append(grouped[i], block)
+	}
+	for i := range nested {
+		nested[i].Value = e.blockGroup(grouped[i], depth+1)
+	}
+
+	return nested
+}
+
+// body writes attributes and nested blocks in source order.
+// Nested blocks are always written as arrays so that they can be told apart from object attributes.
+func (e *jsonEncoder) body(blockType string, body *hclsyntax.Body) object {
+	type item struct {
+		pos   int
+		attr  *hclsyntax.Attribute
+		group []*hclsyntax.Block
+	}
+
+	items := []item{}
+	for _, attr := range body.Attributes {
+		items = append(items, item{pos: attr.SrcRange.Start.Byte, attr: attr})
+	}
+	for _, group := range groupBlocks(body.Blocks) {
+		items = append(items, item{pos: group[0].TypeRange.Start.Byte, group: group})
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].pos < items[j].pos })
+
+	obj := object{}
+	for _, it := range items {
+		if it.attr != nil {
+			obj = append(obj, member{Key: it.attr.Name, Value: e.attribute(blockType, it.attr)})
+			continue
+		}
+
+		var value any
+		if len(it.group[0].Labels) == 0 {
+			bodies := []any{}
+			for _, block := range it.group {
+				bodies = append(bodies, e.body(block.Type, block.Body))
+			}
+			value = bodies
+		} else {
+			value = []any{e.blockGroup(it.group, 0)}
+		}
+		obj = append(obj, member{Key: it.group[0].Type, Value: value})
+	}
+
+	return obj
+}
+
+func (e *jsonEncoder) attribute(blockType string, attr *hclsyntax.Attribute) any {
+	if isStatic(blockType, attr.Name) {
+		return e.static(attr.Expr)
+	}
+
+	// Values that would read back as nested blocks are kept as a single expression
+	switch expr := attr.Expr.(type) {
+	case *hclsyntax.TupleConsExpr:
+		for _, item := range expr.Exprs {
+			if _, ok := item.(*hclsyntax.ObjectConsExpr); ok {
+				return e.wrap(expr)
+			}
+		}
+	case *hclsyntax.ObjectConsExpr:
+		if _, ok := nestedBlock(blockType, attr.Name, true); ok {
+			return e.wrap(expr)
+		}
+	}
+
+	return e.expression(attr.Expr)
+}
+
+// static writes references and type expressions as bare strings
+func (e *jsonEncoder) static(expr hclsyntax.Expression) any {
+	switch expr := expr.(type) {
+	case *hclsyntax.TupleConsExpr:
+		items := []any{}
+		for _, item := range expr.Exprs {
+			items = append(items, e.raw(item.Range()))
+		}
+		return items
+	case *hclsyntax.ObjectConsExpr:
+		obj := object{}
+		for _, item := range expr.Items {
+			key, ok := objectKey(item.KeyExpr)
+			if !ok {
+				key = e.raw(item.KeyExpr.Range())
+			}
+			obj = append(obj, member{Key: key, Value: e.raw(item.ValueExpr.Range())})
+		}
+		return obj
+	}
+	return e.raw(expr.Range())
+}
+
+func (e *jsonEncoder) expression(expr hclsyntax.Expression) any {
+	switch expr := expr.(type) {
+	case *hclsyntax.LiteralValueExpr:
+		return literal(expr.Val)
+
+	case *hclsyntax.TemplateExpr:
+		if hasDirective(e.raw(expr.SrcRange)) {
+			return e.wrap(expr)
+		}
+		var sb strings.Builder
+		for _, part := range expr.Parts {
+			if lit, ok := part.(*hclsyntax.LiteralValueExpr); ok && lit.Val.Type() == cty.String {
+				sb.WriteString(escapeTemplate(lit.Val.AsString()))
+				continue
+			}
+			sb.WriteString("${" + e.raw(part.Range()) + "}")
+		}
+		return sb.String()
+
+	case *hclsyntax.TemplateWrapExpr:
+		return "${" + e.raw(expr.Wrapped.Range()) + "}"
+
+	case *hclsyntax.TupleConsExpr:
+		items := []any{}
+		for _, item := range expr.Exprs {
+			items = append(items, e.expression(item))
+		}
+		return items
+
+	case *hclsyntax.ObjectConsExpr:
+		obj := object{}
+		for _, item := range expr.Items {
+			key, ok := objectKey(item.KeyExpr)
+			if !ok {
+				return e.wrap(expr)
+			}
+			obj = append(obj, member{Key: escapeTemplate(key), Value: e.expression(item.ValueExpr)})
+		}
+		return obj
+	}
+
+	// Constant numbers and bools, such as -1, are written as JSON values instead of templates
+	if len(expr.Variables()) == 0 {
+		if val, diags := expr.Value(nil); !diags.HasErrors() && val.IsWhollyKnown() && (val.Type() == cty.Number || val.Type() == cty.Bool) {
+			return literal(val)
+		}
+	}
+
+	return e.wrap(expr)
+}
+
+// wrap writes an expression as an interpolation of its source text
+func (e *jsonEncoder) wrap(expr hclsyntax.Expression) string {
+	return "${" + e.raw(expr.Range()) + "}"
+}
+
+// objectKey returns the key of an object item written as a bare name or a literal string
+func objectKey(expr hclsyntax.Expression) (string, bool) {
+	keyExpr, ok := expr.(*hclsyntax.ObjectConsKeyExpr)
+	if !ok || keyExpr.ForceNonLiteral {
+		return "", false
+	}
+	if keyword := hcl.ExprAsKeyword(keyExpr.Wrapped); keyword != "" {
+		return keyword, true
+	}
+
+	val, diags := keyExpr.Wrapped.Value(nil)
+	if diags.HasErrors() || !val.IsKnown() || val.IsNull() || val.Type() != cty.String {
+		return "", false
+	}
+	return val.AsString(), true
+}
+
+func literal(val cty.Value) any {
+	if val.IsNull() {
+		return nil
+	}
+	switch val.Type() {
+	case cty.Bool:
+		return val.True()
+	case cty.Number:
+		return json.Number(val.AsBigFloat().Text('f', -1))
+	case cty.String:
+		return escapeTemplate(val.AsString())
+	}
+	return nil
+}
+
+// escapeTemplate escapes template sequences in literal text
+func escapeTemplate(s string) string {
+	s = strings.ReplaceAll(s, "${", "$${")
+	return strings.ReplaceAll(s, "%{", "%%{")
+}
+
+// hasDirective reports whether template source contains %{ } directives
+func hasDirective(src string) bool {
+	return strings.Contains(strings.ReplaceAll(src, "%%{", ""), "%{")
+}
@@ -0,0 +1,140 @@
+package jsonsyntax
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// object is a JSON object that keeps the order of its members
+type object []member
+
+type member struct {
+	Key   string
+	Value any
+	// Line is the line of the key in the decoded source, zero for members built in memory
+	Line int
+}
+
+func (o object) get(key string) (any, bool) {
+	for _, m := range o {
+		if m.Key == key {
+			return m.Value, true
+		}
+	}
+	return nil, false
+}
+
+func (o object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, m := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := marshal(m.Key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := marshal(m.Value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSpace(buf.Bytes()), nil
+}
+
+// decodeOrdered decodes JSON into object, []any, string, json.Number, bool and nil values.
+// Object members record the line of their key.
+func decodeOrdered(src []byte) (any, error) {
+	d := &orderedDecoder{Decoder: json.NewDecoder(bytes.NewReader(src))}
+	d.UseNumber()
+	for i, b := range src {
+		if b == '\n' {
+			d.newlines = append(d.newlines, i)
+		}
+	}
+
+	value, err := d.value()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := d.Token(); err != io.EOF {
+		return nil, fmt.Errorf("unexpected data after top-level value")
+	}
+	return value, nil
+}
+
+type orderedDecoder struct {
+	*json.Decoder
+	// newlines are the offsets of the line breaks in the source
+	newlines []int
+}
+
+// line returns the line of the last byte read
+func (d *orderedDecoder) line() int {
+	return sort.SearchInts(d.newlines, int(d.InputOffset())-1) + 1
+}
+
+func (d *orderedDecoder) value() (any, error) {
+	token, err := d.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := token.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			obj := object{}
+			for d.More() {
+				keyToken, err := d.Token()
+				if err != nil {
+					return nil, err
+				}
+				line := d.line()
+				value, err := d.value()
+				if err != nil {
+					return nil, err
+				}
+				obj = append(obj, member{Key: keyToken.(string), Value: value, Line: line})
+			}
+			if _, err := d.Token(); err != nil {
+				return nil, err
+			}
+			return obj, nil
+		case '[':
+			arr := []any{}
+			for d.More() {
+				value, err := d.value()
+				if err != nil {
+					return nil, err
+				}
+				arr = append(arr, value)
+			}
+			if _, err := d.Token(); err != nil {
+				return nil, err
+			}
+			return arr, nil
+		}
+		return nil, fmt.Errorf("unexpected delimiter %s", t)
+	default:
+		return t, nil
+	}
+}
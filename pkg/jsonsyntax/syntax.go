@@ -0,0 +1,79 @@
+// Package jsonsyntax translates configuration files between the Terraform JSON syntax (.tf.json)
+// and the native syntax (.tf).
+package jsonsyntax
+
+// topLevelLabels is the number of labels of each top-level block type
+var topLevelLabels = map[string]int{
+	"terraform": 0,
+	"locals":    0,
+	"import":    0,
+	"moved":     0,
+	"removed":   0,
+	"tfparser":  0,
+	"metadata":  0,
+	"variable":  1,
+	"output":    1,
+	"module":    1,
+	"provider":  1,
+	"check":     1,
+	"resource":  2,
+	"data":      2,
+	"ephemeral": 2,
+}
+
+// nestedBlocks is the number of labels of the nested block types Terraform defines, by the type
+// of the enclosing block. Other members of these blocks are attributes, whatever their value.
+var nestedBlocks = map[string]map[string]int{
+	"terraform":   {"required_providers": 0, "cloud": 0, "backend": 1, "provider_meta": 1},
+	"cloud":       {"workspaces": 0},
+	"variable":    {"validation": 0},
+	"output":      {"precondition": 0},
+	"resource":    {"lifecycle": 0, "connection": 0, "provisioner": 1},
+	"data":        {"lifecycle": 0},
+	"ephemeral":   {"lifecycle": 0},
+	"lifecycle":   {"precondition": 0, "postcondition": 0},
+	"provisioner": {"connection": 0},
+	"removed":     {"lifecycle": 0, "provisioner": 1},
+	"check":       {"assert": 0, "data": 2},
+	"dynamic":     {"content": 0},
+}
+
+// schemaBodies are the block types whose content is defined by a provider or provisioner
+// schema, which JSON does not carry. Nested blocks in them, and in their own nested blocks,
+// are recognized when written as arrays of objects, and dynamic blocks by name.
+var schemaBodies = map[string]bool{
+	"resource":    true,
+	"data":        true,
+	"ephemeral":   true,
+	"provider":    true,
+	"provisioner": true,
+	"content":     true,
+	"tfparser":    true,
+	"metadata":    true,
+}
+
+// nestedBlock returns the number of labels of a nested block named name in a blockType block,
+// and whether it is one. schema tells whether the enclosing body follows a schema.
+func nestedBlock(blockType, name string, schema bool) (int, bool) {
+	if labels, ok := nestedBlocks[blockType][name]; ok {
+		return labels, true
+	}
+	if schema && name == "dynamic" {
+		return 1, true
+	}
+	return 0, false
+}
+
+// isStatic reports whether an attribute holds references or type expressions that
+// Terraform JSON writes as bare strings instead of "${...}" templates
+func isStatic(blockType, name string) bool {
+	switch name {
+	case "depends_on", "provider", "providers", "ignore_changes", "replace_triggered_by":
+		return true
+	case "type":
+		return blockType == "variable"
+	case "to", "from":
+		return blockType == "import" || blockType == "moved" || blockType == "removed"
+	}
+	return false
+}
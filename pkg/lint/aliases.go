@@ -0,0 +1,102 @@
+package lint
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/config"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+)
+
+func init() {
+	register(&Rule{
+		ID:              "module-provider-wiring",
+		Category:        CategoryLint,
+		Description:     "Module providers maps must reference existing provider configurations and aliases declared by the child module",
+		DefaultSeverity: SeverityError,
+		Check:           checkModuleProviderWiring,
+	})
+}
+
+// availableProviderConfigs returns the provider configuration addresses a module can pass to its children:
+// its own provider blocks, configuration aliases it receives, and default configurations of declared providers
+func availableProviderConfigs(tfconfig *parser.TerraformConfig) map[string]bool {
+	available := map[string]bool{}
+
+	for _, provider := range tfconfig.Providers {
+		available[provider.Address()] = true
+	}
+	for _, terraform := range tfconfig.Terraform {
+		for name, provider := range terraform.RequiredProviders {
+			available[name] = true
+			for _, alias := range provider.ConfigurationAliases {
+				available[alias] = true
+			}
+		}
+	}
+
+	return available
+}
+
+// acceptedProviderConfigs returns the provider addresses a child module expects to receive
+func acceptedProviderConfigs(tfconfig *parser.TerraformConfig) (accepted map[string]bool, aliases []string) {
+	accepted = map[string]bool{}
+
+	for _, terraform := range tfconfig.Terraform {
+		for name, provider := range terraform.RequiredProviders {
+			accepted[name] = true
+			for _, alias := range provider.ConfigurationAliases {
+				accepted[alias] = true
+				aliases = append(aliases, alias)
+			}
+		}
+	}
+
+	slices.Sort(aliases)
+	return accepted, aliases
+}
+
+func checkModuleProviderWiring(ws *Workspace, cfg *config.Config) []Finding {
+	findings := []Finding{}
+	available := availableProviderConfigs(ws.Config)
+
+	for _, module := range ws.Config.Modules {
+		subject := module.Address()
+
+		for _, childAddr := range slices.Sorted(maps.Keys(module.Providers)) {
+			parentAddr := module.Providers[childAddr]
+			if !available[parentAddr] {
+				findings = append(findings, Finding{
+					Subject: subject,
+					Message: fmt.Sprintf("providers map passes %s as %s, but no provider configuration %s exists in the calling module", parentAddr, childAddr, parentAddr),
+				})
+			}
+		}
+
+		child, resolved := ws.Modules[module.Name]
+		if !resolved {
+			continue
+		}
+
+		accepted, aliases := acceptedProviderConfigs(child)
+		for _, childAddr := range slices.Sorted(maps.Keys(module.Providers)) {
+			if !accepted[childAddr] {
+				findings = append(findings, Finding{
+					Subject: subject,
+					Message: fmt.Sprintf("providers map sets %s, but the child module does not declare it in required_providers or configuration_aliases", childAddr),
+				})
+			}
+		}
+		for _, alias := range aliases {
+			if _, ok := module.Providers[alias]; !ok {
+				findings = append(findings, Finding{
+					Subject: subject,
+					Message: fmt.Sprintf("child module requires provider configuration %s, but the providers map does not pass it", alias),
+				})
+			}
+		}
+	}
+
+	return findings
+}
@@ -0,0 +1,70 @@
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Baseline records the findings of a codebase at the time lint was adopted, so that only new
+// findings fail the run. Findings are identified by rule and subject, which survive edits that
+// move blocks around.
+type Baseline struct {
+	Findings []*BaselineEntry `json:"findings"`
+}
+
+type BaselineEntry struct {
+	RuleID  string `json:"rule_id"`
+	Subject string `json:"subject"`
+}
+
+// NewBaseline records the findings of report
+func NewBaseline(report *Report) *Baseline {
+	baseline := &Baseline{Findings: []*BaselineEntry{}}
+	for _, finding := range report.Findings {
+		if !baseline.contains(finding) {
+			baseline.Findings = append(baseline.Findings, &BaselineEntry{RuleID: finding.RuleID, Subject: finding.Subject})
+		}
+	}
+	slices.SortFunc(baseline.Findings, func(a, b *BaselineEntry) int {
+		return strings.Compare(a.RuleID+"\x00"+a.Subject, b.RuleID+"\x00"+b.Subject)
+	})
+	return baseline
+}
+
+// LoadBaseline reads a baseline file written with Baseline.Write
+func LoadBaseline(path string) (*Baseline, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lint baseline %s: %w", path, err)
+	}
+
+	baseline := &Baseline{}
+	if err := json.Unmarshal(content, baseline); err != nil {
+		return nil, fmt.Errorf("failed to parse lint baseline %s: %w", path, err)
+	}
+	return baseline, nil
+}
+
+// Write saves the baseline to path
+func (b *Baseline) Write(path string) error {
+	content, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(content, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write lint baseline %s: %w", path, err)
+	}
+	return nil
+}
+
+func (b *Baseline) contains(finding Finding) bool {
+	if b == nil {
+		return false
+	}
+	return slices.ContainsFunc(b.Findings, func(entry *BaselineEntry) bool {
+		return entry.RuleID == finding.RuleID && entry.Subject == finding.Subject
+	})
+}
@@ -0,0 +1,65 @@
+package lint
+
+import (
+	"encoding/xml"
+	"fmt"
+)
+
+type checkstyleReport struct {
+	XMLName xml.Name          `xml:"checkstyle"`
+	Version string            `xml:"version,attr"`
+	Files   []*checkstyleFile `xml:"file"`
+}
+
+type checkstyleFile struct {
+	Name   string             `xml:"name,attr"`
+	Errors []*checkstyleError `xml:"error"`
+}
+
+type checkstyleError struct {
+	Line     int    `xml:"line,attr"`
+	Column   int    `xml:"column,attr,omitempty"`
+	Severity string `xml:"severity,attr"`
+	Message  string `xml:"message,attr"`
+	Source   string `xml:"source,attr"`
+}
+
+// findingPath is the file a finding is reported on; findings without a location are reported
+// on the workspace directory
+func (r *Report) findingPath(finding Finding) string {
+	if finding.Location != nil {
+		return finding.Location.File
+	}
+	return r.dir
+}
+
+// Checkstyle renders the findings as checkstyle XML, the input format of many review tools
+func (r *Report) Checkstyle() ([]byte, error) {
+	report := &checkstyleReport{Version: "4.3"}
+	files := map[string]*checkstyleFile{}
+
+	for _, finding := range r.Findings {
+		path := r.findingPath(finding)
+		if files[path] == nil {
+			files[path] = &checkstyleFile{Name: path}
+			report.Files = append(report.Files, files[path])
+		}
+
+		checkstyleErr := &checkstyleError{
+			Severity: string(finding.Severity),
+			Message:  fmt.Sprintf("%s: %s", finding.Subject, finding.Message),
+			Source:   "terraform-config-parser." + finding.RuleID,
+		}
+		if finding.Location != nil {
+			checkstyleErr.Line = finding.Location.Line
+			checkstyleErr.Column = finding.Location.Column
+		}
+		files[path].Errors = append(files[path].Errors, checkstyleErr)
+	}
+
+	content, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode checkstyle report: %w", err)
+	}
+	return append([]byte(xml.Header), append(content, '\n')...), nil
+}
@@ -0,0 +1,62 @@
+package lint
+
+import (
+	"regexp"
+	"strings"
+)
+
+var versionConstraintRegex = regexp.MustCompile(`^(=|!=|>=|<=|>|<|~>)?\s*v?\d+(\.\d+){0,2}(-[0-9A-Za-z.-]+)?$`)
+
+// splitConstraint breaks a version constraint string into its operator/version pairs
+func splitConstraint(constraint string) (operators []string, ok bool) {
+	for _, part := range strings.Split(constraint, ",") {
+		part = strings.TrimSpace(part)
+		match := versionConstraintRegex.FindStringSubmatch(part)
+		if match == nil {
+			return nil, false
+		}
+
+		operator := match[1]
+		if operator == "" {
+			operator = "="
+		}
+		operators = append(operators, operator)
+	}
+	return operators, len(operators) > 0
+}
+
+// isExactOrPessimistic reports whether a constraint pins a single version or uses ~>
+func isExactOrPessimistic(constraint string) bool {
+	operators, ok := splitConstraint(constraint)
+	if !ok {
+		return false
+	}
+
+	for _, operator := range operators {
+		if operator == "=" || operator == "~>" {
+			return true
+		}
+	}
+	return false
+}
+
+// hasUpperBound reports whether a constraint excludes arbitrarily new versions
+func hasUpperBound(constraint string) bool {
+	operators, ok := splitConstraint(constraint)
+	if !ok {
+		return false
+	}
+
+	for _, operator := range operators {
+		switch operator {
+		case "=", "~>", "<", "<=":
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeConstraint removes whitespace, which terraform init normalizes in the lock file
+func normalizeConstraint(constraint string) string {
+	return strings.Join(strings.Fields(constraint), "")
+}
@@ -0,0 +1,89 @@
+package lint
+
+import (
+	_ "embed"
+	"fmt"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/config"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed deprecations.yaml
+var builtinDeprecationsYAML []byte
+
+var builtinDeprecations = mustLoadDeprecations(builtinDeprecationsYAML)
+
+func mustLoadDeprecations(content []byte) []*config.Deprecation {
+	deprecations := []*config.Deprecation{}
+	if err := yaml.Unmarshal(content, &deprecations); err != nil {
+		panic(fmt.Sprintf("invalid built-in deprecations: %v", err))
+	}
+	return deprecations
+}
+
+func init() {
+	register(&Rule{
+		ID:              "resource-type-deprecated",
+		Category:        CategoryLint,
+		Description:     "Resource and data source types must not be deprecated or renamed",
+		DefaultSeverity: SeverityWarning,
+		Check:           checkDeprecatedTypes,
+	})
+}
+
+// deprecationIndex merges the built-in knowledge base with configured entries, keyed by kind and type.
+// Configured entries win over built-in ones.
+func deprecationIndex(cfg *config.Config) map[string]*config.Deprecation {
+	index := map[string]*config.Deprecation{}
+
+	add := func(deprecation *config.Deprecation) {
+		switch deprecation.Kind {
+		case "resource", "data":
+			index[deprecation.Kind+"."+deprecation.Type] = deprecation
+		default:
+			index["resource."+deprecation.Type] = deprecation
+			index["data."+deprecation.Type] = deprecation
+		}
+	}
+
+	for _, deprecation := range builtinDeprecations {
+		add(deprecation)
+	}
+	if cfg != nil {
+		for _, deprecation := range cfg.Deprecations {
+			add(deprecation)
+		}
+	}
+
+	return index
+}
+
+func checkDeprecatedTypes(ws *Workspace, cfg *config.Config) []Finding {
+	findings := []Finding{}
+	index := deprecationIndex(cfg)
+
+	report := func(address string, deprecation *config.Deprecation) {
+		message := fmt.Sprintf("type %s is deprecated", deprecation.Type)
+		if deprecation.Replacement != "" {
+			message += fmt.Sprintf("; use %s instead", deprecation.Replacement)
+		}
+		if deprecation.Note != "" {
+			message += fmt.Sprintf(" (%s)", deprecation.Note)
+		}
+		findings = append(findings, Finding{Subject: address, Message: message})
+	}
+
+	for _, resource := range ws.Config.Resources {
+		if deprecation, ok := index["resource."+resource.Type]; ok {
+			report(resource.Address(), deprecation)
+		}
+	}
+	for _, data := range ws.Config.DataSources {
+		if deprecation, ok := index["data."+data.Type]; ok {
+			report(data.Address(), deprecation)
+		}
+	}
+
+	return findings
+}
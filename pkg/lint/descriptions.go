@@ -0,0 +1,148 @@
+package lint
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/config"
+)
+
+// Description rules are off by default; enable them by setting a severity in the config file
+func init() {
+	register(&Rule{
+		ID:              "description-min-length",
+		Category:        CategoryLint,
+		Description:     "Variable and output descriptions must have at least descriptions.min_length characters",
+		DefaultSeverity: SeverityOff,
+		Check:           checkDescriptionMinLength,
+	})
+	register(&Rule{
+		ID:              "description-capitalized",
+		Category:        CategoryLint,
+		Description:     "Variable and output descriptions must start with a capital letter",
+		DefaultSeverity: SeverityOff,
+		Check:           checkDescriptionCapitalized,
+	})
+	register(&Rule{
+		ID:              "description-trailing-period",
+		Category:        CategoryLint,
+		Description:     "Variable and output descriptions must follow the descriptions.trailing_period policy",
+		DefaultSeverity: SeverityOff,
+		Check:           checkDescriptionTrailingPeriod,
+	})
+	register(&Rule{
+		ID:              "description-denylist",
+		Category:        CategoryLint,
+		Description:     "Variable and output descriptions must not contain words from descriptions.denylist",
+		DefaultSeverity: SeverityOff,
+		Check:           checkDescriptionDenylist,
+	})
+}
+
+type describedObject struct {
+	subject     string
+	description string
+}
+
+// describedObjects returns variables and outputs with their descriptions
+func describedObjects(ws *Workspace) []describedObject {
+	objects := []describedObject{}
+	for _, variable := range ws.Config.Variables {
+		objects = append(objects, describedObject{variable.Address(), strings.TrimSpace(variable.Description)})
+	}
+	for _, output := range ws.Config.Outputs {
+		objects = append(objects, describedObject{output.Address(), strings.TrimSpace(output.Description)})
+	}
+	return objects
+}
+
+func checkDescriptionMinLength(ws *Workspace, cfg *config.Config) []Finding {
+	findings := []Finding{}
+	policy := cfg.DescriptionPolicy()
+
+	for _, object := range describedObjects(ws) {
+		switch length := utf8.RuneCountInString(object.description); {
+		case length == 0:
+			findings = append(findings, Finding{
+				Subject: object.subject,
+				Message: "description is missing",
+			})
+		case length < policy.MinLength:
+			findings = append(findings, Finding{
+				Subject: object.subject,
+				Message: fmt.Sprintf("description %q is shorter than %d characters", object.description, policy.MinLength),
+			})
+		}
+	}
+
+	return findings
+}
+
+func checkDescriptionCapitalized(ws *Workspace, cfg *config.Config) []Finding {
+	findings := []Finding{}
+
+	for _, object := range describedObjects(ws) {
+		first, _ := utf8.DecodeRuneInString(object.description)
+		if unicode.IsLower(first) {
+			findings = append(findings, Finding{
+				Subject: object.subject,
+				Message: fmt.Sprintf("description %q does not start with a capital letter", object.description),
+			})
+		}
+	}
+
+	return findings
+}
+
+func checkDescriptionTrailingPeriod(ws *Workspace, cfg *config.Config) []Finding {
+	findings := []Finding{}
+	policy := cfg.DescriptionPolicy()
+
+	for _, object := range describedObjects(ws) {
+		if object.description == "" {
+			continue
+		}
+
+		hasPeriod := strings.HasSuffix(object.description, ".")
+		switch {
+		case policy.TrailingPeriod == "forbid" && hasPeriod:
+			findings = append(findings, Finding{
+				Subject: object.subject,
+				Message: fmt.Sprintf("description %q ends with a period", object.description),
+			})
+		case policy.TrailingPeriod == "require" && !hasPeriod:
+			findings = append(findings, Finding{
+				Subject: object.subject,
+				Message: fmt.Sprintf("description %q does not end with a period", object.description),
+			})
+		}
+	}
+
+	return findings
+}
+
+func checkDescriptionDenylist(ws *Workspace, cfg *config.Config) []Finding {
+	findings := []Finding{}
+	policy := cfg.DescriptionPolicy()
+
+	patterns := map[string]*regexp.Regexp{}
+	for _, word := range policy.Denylist {
+		patterns[word] = regexp.MustCompile(`(?i)(^|\PL)` + regexp.QuoteMeta(word) + `($|\PL)`)
+	}
+
+	for _, object := range describedObjects(ws) {
+		for _, word := range policy.Denylist {
+			if patterns[word].MatchString(object.description) {
+				findings = append(findings, Finding{
+					Subject: object.subject,
+					Message: fmt.Sprintf("description %q contains denied word %q", object.description, word),
+				})
+			}
+		}
+	}
+
+	return findings
+}
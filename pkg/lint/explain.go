@@ -0,0 +1,52 @@
+package lint
+
+import (
+	_ "embed"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed explanations.yaml
+var explanationsYAML []byte
+
+var explanations = mustLoadExplanations(explanationsYAML)
+
+func mustLoadExplanations(content []byte) map[string]*Explanation {
+	explanations := map[string]*Explanation{}
+	if err := yaml.Unmarshal(content, &explanations); err != nil {
+		panic(fmt.Sprintf("invalid rule explanations: %v", err))
+	}
+	return explanations
+}
+
+// Explanation documents a rule: why it exists, what violates it and how to fix findings
+type Explanation struct {
+	ID              string   `yaml:"-" json:"id"`
+	Category        Category `yaml:"-" json:"category"`
+	DefaultSeverity Severity `yaml:"-" json:"default_severity"`
+	Description     string   `yaml:"-" json:"description"`
+	Rationale       string   `yaml:"rationale" json:"rationale"`
+	// Bad and Good are configuration snippets violating and following the rule
+	Bad  string `yaml:"bad" json:"bad"`
+	Good string `yaml:"good" json:"good"`
+	Fix  string `yaml:"fix" json:"fix"`
+}
+
+// Explain returns the explanation of the rule with ID ruleID
+func Explain(ruleID string) (*Explanation, error) {
+	rule, ok := registry[ruleID]
+	if !ok {
+		return nil, fmt.Errorf("unknown rule %q", ruleID)
+	}
+
+	explanation := &Explanation{}
+	if known, ok := explanations[ruleID]; ok {
+		*explanation = *known
+	}
+	explanation.ID = rule.ID
+	explanation.Category = rule.Category
+	explanation.DefaultSeverity = rule.DefaultSeverity
+	explanation.Description = rule.Description
+	return explanation, nil
+}
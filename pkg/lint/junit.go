@@ -0,0 +1,110 @@
+package lint
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name          `xml:"testsuites"`
+	Name     string            `xml:"name,attr"`
+	Tests    int               `xml:"tests,attr"`
+	Failures int               `xml:"failures,attr"`
+	Skipped  int               `xml:"skipped,attr"`
+	Suites   []*junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Cases    []*junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// JUnit renders the report as JUnit XML with a test suite per rule category and a test case per rule.
+// Rules with error findings fail; warnings and infos are listed in the output of their test case,
+// and disabled rules are skipped.
+func (r *Report) JUnit(name string) ([]byte, error) {
+	suites := &junitTestSuites{Name: name}
+	byCategory := map[Category]*junitTestSuite{}
+	suite := func(category Category) *junitTestSuite {
+		if byCategory[category] == nil {
+			byCategory[category] = &junitTestSuite{Name: string(category)}
+			suites.Suites = append(suites.Suites, byCategory[category])
+		}
+		return byCategory[category]
+	}
+
+	for _, rule := range r.checked {
+		testCase := &junitTestCase{Name: rule.ID, ClassName: string(rule.Category)}
+
+		var failures, others []string
+		for _, finding := range r.Findings {
+			if finding.RuleID != rule.ID {
+				continue
+			}
+			line := fmt.Sprintf("%s: %s: %s", finding.Severity, finding.Subject, finding.Message)
+			if finding.Severity == SeverityError {
+				failures = append(failures, line)
+			} else {
+				others = append(others, line)
+			}
+		}
+
+		s := suite(rule.Category)
+		if len(failures) > 0 {
+			testCase.Failure = &junitFailure{
+				Message: fmt.Sprintf("%d finding(s): %s", len(failures), rule.Description),
+				Type:    string(SeverityError),
+				Text:    strings.Join(failures, "\n"),
+			}
+			s.Failures++
+		}
+		testCase.SystemOut = strings.Join(others, "\n")
+		s.Tests++
+		s.Cases = append(s.Cases, testCase)
+	}
+
+	for _, rule := range r.disabled {
+		s := suite(rule.Category)
+		s.Cases = append(s.Cases, &junitTestCase{
+			Name:      rule.ID,
+			ClassName: string(rule.Category),
+			Skipped:   &junitSkipped{Message: "rule is disabled"},
+		})
+		s.Tests++
+		s.Skipped++
+	}
+
+	for _, s := range suites.Suites {
+		suites.Tests += s.Tests
+		suites.Failures += s.Failures
+		suites.Skipped += s.Skipped
+	}
+
+	content, err := xml.MarshalIndent(suites, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode JUnit report: %w", err)
+	}
+	return append([]byte(xml.Header), append(content, '\n')...), nil
+}
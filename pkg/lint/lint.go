@@ -0,0 +1,222 @@
+package lint
+
+import (
+	"fmt"
+	"path"
+	"sort"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/compat"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/config"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/logger"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+)
+
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+	SeverityOff     Severity = "off"
+)
+
+// ParseSeverity validates a severity name coming from user configuration
+func ParseSeverity(s string) (Severity, error) {
+	switch sev := Severity(s); sev {
+	case SeverityError, SeverityWarning, SeverityInfo, SeverityOff:
+		return sev, nil
+	default:
+		return "", fmt.Errorf("unknown severity %q (expected error, warning, info or off)", s)
+	}
+}
+
+type Category string
+
+const (
+	CategoryPolicy Category = "policy"
+	CategoryLint   Category = "lint"
+)
+
+// Finding is a single rule violation
+type Finding struct {
+	RuleID   string   `json:"rule_id"`
+	Category Category `json:"category"`
+	Severity Severity `json:"severity"`
+	// Subject is the address of the offending construct, e.g. module.vpc
+	Subject string `json:"subject"`
+	Message string `json:"message"`
+	// Location is the declaration of the subject, when the workspace carries locations
+	Location *parser.Location `json:"location,omitempty"`
+	// Suppression is the reason of an inline suppression, or "baseline", for suppressed findings
+	Suppression string `json:"suppression,omitempty"`
+}
+
+// Rule is a built-in check evaluated against a parsed configuration
+type Rule struct {
+	ID              string
+	Category        Category
+	Description     string
+	DefaultSeverity Severity
+	Check           func(ws *Workspace, cfg *config.Config) []Finding
+}
+
+// Workspace is the input of a lint run
+type Workspace struct {
+	Config *parser.TerraformConfig
+	// Modules holds resolved child module configurations keyed by module call name
+	Modules map[string]*parser.TerraformConfig
+	// Dir and Locations, both optional, place findings in files for the checkstyle and rdjson formats
+	Dir       string
+	Locations map[string]*parser.Location
+	// Compat is the language feature analysis of the root module, optional
+	Compat *compat.Report
+	// Suppressions are the tfparser:ignore comments of the configuration files, optional
+	Suppressions []*Suppression
+}
+
+var registry = map[string]*Rule{}
+
+func register(rule *Rule) {
+	if _, exists := registry[rule.ID]; exists {
+		panic(fmt.Sprintf("lint rule %s registered twice", rule.ID))
+	}
+	registry[rule.ID] = rule
+}
+
+// Rules returns every built-in rule sorted by ID
+func Rules() []*Rule {
+	rules := make([]*Rule, 0, len(registry))
+	for _, rule := range registry {
+		rules = append(rules, rule)
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+	return rules
+}
+
+// Report is the result of running all rules against a configuration
+type Report struct {
+	// Annotations of the linted module, e.g. its owner to route findings to
+	Annotations map[string]string `json:"annotations,omitempty"`
+	Findings    []Finding         `json:"findings"`
+	Errors      int               `json:"errors"`
+	Warnings    int               `json:"warnings"`
+	Infos       int               `json:"infos"`
+	// Suppressed lists the findings silenced by inline comments or the baseline; they do not count
+	Suppressed []Finding `json:"suppressed,omitempty"`
+
+	// checked and disabled are the rules evaluated and skipped by the run, for the test report formats
+	checked  []*Rule
+	disabled []*Rule
+	dir      string
+}
+
+type Linter struct {
+	config   *config.Config
+	baseline *Baseline
+}
+
+func NewLinter(cfg *config.Config) *Linter {
+	return &Linter{config: cfg}
+}
+
+// WithBaseline suppresses the findings recorded in baseline
+func (l *Linter) WithBaseline(baseline *Baseline) *Linter {
+	l.baseline = baseline
+	return l
+}
+
+// Run evaluates every enabled rule, applying configured severities and exemptions, inline
+// suppressions and the baseline
+func (l *Linter) Run(ws *Workspace) (*Report, error) {
+	report := &Report{Annotations: ws.Config.Annotations, Findings: []Finding{}, dir: ws.Dir}
+
+	namingRules, err := l.config.NamingRules()
+	if err != nil {
+		return nil, fmt.Errorf("invalid naming configuration: %w", err)
+	}
+	for _, rule := range namingRules {
+		if rule.Severity == "" {
+			continue
+		}
+		if _, err := ParseSeverity(rule.Severity); err != nil {
+			return nil, fmt.Errorf("invalid naming rule for %s blocks: %w", rule.Block, err)
+		}
+	}
+
+	for _, rule := range Rules() {
+		severity := rule.DefaultSeverity
+		var exemptions []string
+
+		if override := l.config.Rule(rule.ID); override != nil {
+			if override.Severity != "" {
+				sev, err := ParseSeverity(override.Severity)
+				if err != nil {
+					return nil, fmt.Errorf("invalid configuration for rule %s: %w", rule.ID, err)
+				}
+				severity = sev
+			}
+			exemptions = override.Exemptions
+		}
+
+		if severity == SeverityOff {
+			logger.DebugKV("Skipping disabled rule", "rule", rule.ID)
+			report.disabled = append(report.disabled, rule)
+			continue
+		}
+		report.checked = append(report.checked, rule)
+
+		for _, finding := range rule.Check(ws, l.config) {
+			if isExempt(finding.Subject, exemptions) {
+				logger.DebugKV("Skipping exempted finding", "rule", rule.ID, "subject", finding.Subject)
+				continue
+			}
+
+			finding.RuleID = rule.ID
+			finding.Category = rule.Category
+			// Checks with severities of their own, e.g. naming rules, set it on the finding
+			if finding.Severity == "" {
+				finding.Severity = severity
+			}
+			finding.Location = ws.Locations[finding.Subject]
+
+			if suppression := ws.suppression(&finding); suppression != nil {
+				finding.Suppression = suppression.Reason
+				if finding.Suppression == "" {
+					finding.Suppression = "inline"
+				}
+				report.Suppressed = append(report.Suppressed, finding)
+				continue
+			}
+			if l.baseline.contains(finding) {
+				finding.Suppression = "baseline"
+				report.Suppressed = append(report.Suppressed, finding)
+				continue
+			}
+			report.add(finding)
+		}
+	}
+
+	return report, nil
+}
+
+func (r *Report) add(finding Finding) {
+	r.Findings = append(r.Findings, finding)
+
+	switch finding.Severity {
+	case SeverityError:
+		r.Errors++
+	case SeverityWarning:
+		r.Warnings++
+	case SeverityInfo:
+		r.Infos++
+	}
+}
+
+func isExempt(subject string, exemptions []string) bool {
+	for _, pattern := range exemptions {
+		if matched, err := path.Match(pattern, subject); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,727 @@
+package lint
+
+import (
+	"encoding/xml"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/config"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser/schema"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+func findingsFor(report *Report, ruleID string) []Finding {
+	var findings []Finding
+	for _, f := range report.Findings {
+		if f.RuleID == ruleID {
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
+
+func TestPinningPolicy(t *testing.T) {
+	tfconfig := &parser.TerraformConfig{
+		Modules: []*schema.Module{
+			{Name: "branch", Source: "git::https://example.com/vpc.git?ref=main"},
+			{Name: "tag", Source: "git::https://example.com/vpc.git?ref=v1.2.0"},
+			{Name: "commit", Source: "github.com/owner/repo//modules/vpc?ref=a1b2c3d4e5f6"},
+			{Name: "unpinned", Source: "git@github.com:owner/repo.git"},
+			{Name: "registry_exact", Source: "terraform-aws-modules/vpc/aws", Version: "5.1.0"},
+			{Name: "registry_pessimistic", Source: "terraform-aws-modules/vpc/aws", Version: "~> 5.1"},
+			{Name: "registry_range", Source: "terraform-aws-modules/vpc/aws", Version: ">= 5.0"},
+			{Name: "registry_none", Source: "app.terraform.io/org/vpc/aws"},
+			{Name: "local", Source: "./modules/vpc"},
+		},
+		Terraform: []*schema.Terraform{
+			{
+				RequiredProviders: map[string]*schema.RequiredProvider{
+					"aws":     {Source: "hashicorp/aws", Version: ">= 5.0, < 6.0"},
+					"google":  {Source: "hashicorp/google", Version: ">= 5.0"},
+					"random":  {Source: "hashicorp/random"},
+					"azurerm": {Source: "hashicorp/azurerm", Version: "~> 3.0"},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		ruleID   string
+		subjects []string
+	}{
+		{
+			name:     "Git sources must use tags or commits",
+			ruleID:   "module-git-pinned",
+			subjects: []string{"module.branch", "module.unpinned"},
+		},
+		{
+			name:     "Registry modules must be exact or pessimistic",
+			ruleID:   "module-registry-version",
+			subjects: []string{"module.registry_range", "module.registry_none"},
+		},
+		{
+			name:     "Providers must have an upper bound",
+			ruleID:   "provider-version-upper-bound",
+			subjects: []string{"required_providers.google", "required_providers.random"},
+		},
+	}
+
+	report, err := NewLinter(&config.Config{}).Run(&Workspace{Config: tfconfig})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings := findingsFor(report, tt.ruleID)
+			if len(findings) != len(tt.subjects) {
+				t.Fatalf("Expected %d findings, got %d: %+v", len(tt.subjects), len(findings), findings)
+			}
+			for i, subject := range tt.subjects {
+				if findings[i].Subject != subject {
+					t.Errorf("Expected finding %d subject %s, got %s", i, subject, findings[i].Subject)
+				}
+			}
+		})
+	}
+}
+
+func TestRuleConfiguration(t *testing.T) {
+	tfconfig := &parser.TerraformConfig{
+		Modules: []*schema.Module{
+			{Name: "legacy_vpc", Source: "git::https://example.com/vpc.git?ref=main"},
+			{Name: "vpc", Source: "git::https://example.com/vpc.git?ref=develop"},
+		},
+	}
+
+	cfg := &config.Config{
+		Rules: map[string]*config.RuleConfig{
+			"module-git-pinned": {Severity: "warning", Exemptions: []string{"module.legacy_*"}},
+		},
+	}
+
+	report, err := NewLinter(cfg).Run(&Workspace{Config: tfconfig})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	findings := findingsFor(report, "module-git-pinned")
+	if len(findings) != 1 || findings[0].Subject != "module.vpc" {
+		t.Fatalf("Expected only module.vpc to be reported, got %+v", findings)
+	}
+	if findings[0].Severity != SeverityWarning {
+		t.Errorf("Expected severity warning, got %s", findings[0].Severity)
+	}
+	if report.Errors != 0 || report.Warnings != 1 {
+		t.Errorf("Expected 0 errors and 1 warning, got %d errors and %d warnings", report.Errors, report.Warnings)
+	}
+
+	cfg.Rules["module-git-pinned"].Severity = "fatal"
+	if _, err := NewLinter(cfg).Run(&Workspace{Config: tfconfig}); err == nil {
+		t.Error("Expected error for unknown severity, but got none")
+	}
+}
+
+func TestProviderDeclarations(t *testing.T) {
+	tfconfig := &parser.TerraformConfig{
+		Terraform: []*schema.Terraform{
+			{
+				RequiredProviders: map[string]*schema.RequiredProvider{
+					"aws":    {Source: "hashicorp/aws", Version: "~> 5.0"},
+					"random": {Source: "hashicorp/random", Version: "~> 3.0"},
+				},
+			},
+		},
+		Resources: []*schema.Resource{
+			{Type: "aws_instance", Name: "web"},
+			{Type: "google_compute_instance", Name: "web"},
+			{Type: "terraform_data", Name: "marker"},
+			{Type: "instance", Name: "custom", Provider: "acme.west"},
+		},
+		DataSources: []*schema.DataSource{
+			{Resource: schema.Resource{Type: "google_project", Name: "current"}},
+		},
+	}
+
+	report, err := NewLinter(&config.Config{}).Run(&Workspace{Config: tfconfig})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	undeclared := findingsFor(report, "provider-undeclared")
+	if len(undeclared) != 2 || undeclared[0].Subject != "provider.acme" || undeclared[1].Subject != "provider.google" {
+		t.Errorf("Expected acme and google to be undeclared, got %+v", undeclared)
+	}
+
+	unused := findingsFor(report, "provider-unused")
+	if len(unused) != 1 || unused[0].Subject != "provider.random" {
+		t.Errorf("Expected random to be unused, got %+v", unused)
+	}
+}
+
+func TestProviderUsage(t *testing.T) {
+	tfconfig := &parser.TerraformConfig{
+		Terraform: []*schema.Terraform{
+			{
+				RequiredProviders: map[string]*schema.RequiredProvider{
+					"aws":        {Source: "hashicorp/aws"},
+					"google":     {Source: "hashicorp/google"},
+					"kubernetes": {Source: "hashicorp/kubernetes"},
+					"random":     {Source: "hashicorp/random"},
+					"tls":        {Source: "hashicorp/tls"},
+				},
+			},
+		},
+		// aws is configured for the child modules, which inherit it
+		Providers: []*schema.Provider{
+			{Name: "aws", Alias: "west"},
+		},
+		Modules: []*schema.Module{
+			{Name: "gke", Source: "./gke", Providers: map[string]string{"google": "google.eu"}},
+		},
+		EphemeralResources: []*schema.EphemeralResource{
+			{Resource: schema.Resource{Type: "random_password", Name: "db"}},
+		},
+		Resources: []*schema.Resource{
+			{Type: "kubernetes_namespace", Name: "app"},
+		},
+	}
+
+	report, err := NewLinter(&config.Config{}).Run(&Workspace{Config: tfconfig})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	unused := findingsFor(report, "provider-unused")
+	if len(unused) != 1 || unused[0].Subject != "provider.tls" {
+		t.Errorf("Expected only tls to be unused, got %+v", unused)
+	}
+}
+
+func TestProviderLockDrift(t *testing.T) {
+	tfconfig := &parser.TerraformConfig{
+		Terraform: []*schema.Terraform{
+			{
+				RequiredProviders: map[string]*schema.RequiredProvider{
+					"aws":    {Source: "hashicorp/aws", Version: "~> 5.0"},
+					"random": {Version: ">= 3.0, < 4.0"},
+					"tls":    {Source: "hashicorp/tls", Version: "~> 4.0"},
+				},
+			},
+		},
+		LockedProviders: []*schema.LockedProvider{
+			{Source: "registry.terraform.io/hashicorp/aws", Version: "4.67.0", Constraints: "~> 4.0"},
+			{Source: "registry.terraform.io/hashicorp/random", Version: "3.6.0", Constraints: ">= 3.0,< 4.0"},
+		},
+	}
+
+	report, err := NewLinter(&config.Config{}).Run(&Workspace{Config: tfconfig})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	drift := findingsFor(report, "provider-lock-drift")
+	if len(drift) != 2 || drift[0].Subject != "required_providers.aws" || drift[1].Subject != "required_providers.tls" {
+		t.Fatalf("Expected aws and tls to drift, got %+v", drift)
+	}
+	if !strings.Contains(drift[0].Message, "locked at 4.67.0") || !strings.Contains(drift[1].Message, "missing from the dependency lock file") {
+		t.Errorf("Unexpected messages: %+v", drift)
+	}
+
+	// Without lock file there is nothing to compare
+	tfconfig.LockedProviders = nil
+	report, err = NewLinter(&config.Config{}).Run(&Workspace{Config: tfconfig})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if drift := findingsFor(report, "provider-lock-drift"); len(drift) != 0 {
+		t.Errorf("Expected no findings without lock file, got %+v", drift)
+	}
+}
+
+func TestModuleProviderWiring(t *testing.T) {
+	tfconfig := &parser.TerraformConfig{
+		Terraform: []*schema.Terraform{
+			{
+				RequiredProviders: map[string]*schema.RequiredProvider{
+					"aws": {Source: "hashicorp/aws", Version: "~> 5.0"},
+				},
+			},
+		},
+		Providers: []*schema.Provider{
+			{Name: "aws"},
+			{Name: "aws", Alias: "use1"},
+		},
+		Modules: []*schema.Module{
+			{Name: "ok", Source: "./modules/replica", Providers: map[string]string{"aws.primary": "aws.use1", "aws.replica": "aws"}},
+			{Name: "missing_parent", Source: "./modules/replica", Providers: map[string]string{"aws.primary": "aws.usw2", "aws.replica": "aws"}},
+			{Name: "wrong_child", Source: "./modules/replica", Providers: map[string]string{"aws.secondary": "aws.use1"}},
+			{Name: "unresolved", Source: "git::https://example.com/m.git?ref=v1.0.0", Providers: map[string]string{"aws.any": "aws.use1"}},
+		},
+	}
+
+	replica := &parser.TerraformConfig{
+		Terraform: []*schema.Terraform{
+			{
+				RequiredProviders: map[string]*schema.RequiredProvider{
+					"aws": {Source: "hashicorp/aws", ConfigurationAliases: []string{"aws.primary", "aws.replica"}},
+				},
+			},
+		},
+	}
+
+	ws := &Workspace{
+		Config: tfconfig,
+		Modules: map[string]*parser.TerraformConfig{
+			"ok":             replica,
+			"missing_parent": replica,
+			"wrong_child":    replica,
+		},
+	}
+
+	report, err := NewLinter(&config.Config{}).Run(ws)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	counts := map[string]int{}
+	for _, f := range findingsFor(report, "module-provider-wiring") {
+		counts[f.Subject]++
+	}
+
+	expected := map[string]int{
+		"module.missing_parent": 1,
+		// aws.secondary is unknown to the child, aws.primary and aws.replica are not passed
+		"module.wrong_child": 3,
+	}
+	for subject, count := range expected {
+		if counts[subject] != count {
+			t.Errorf("Expected %d findings for %s, got %d", count, subject, counts[subject])
+		}
+	}
+	if counts["module.ok"] != 0 || counts["module.unresolved"] != 0 {
+		t.Errorf("Expected no findings for module.ok and module.unresolved, got %v", counts)
+	}
+}
+
+func TestDeprecatedTypes(t *testing.T) {
+	tfconfig := &parser.TerraformConfig{
+		Resources: []*schema.Resource{
+			{Type: "aws_s3_bucket_object", Name: "legacy"},
+			{Type: "aws_s3_object", Name: "current"},
+			{Type: "acme_bucket", Name: "custom"},
+		},
+		DataSources: []*schema.DataSource{
+			{Resource: schema.Resource{Type: "aws_s3_bucket_object", Name: "legacy"}},
+			{Resource: schema.Resource{Type: "template_file", Name: "init"}},
+		},
+	}
+
+	cfg := &config.Config{
+		Deprecations: []*config.Deprecation{
+			{Type: "acme_bucket", Kind: "resource", Replacement: "acme_bucket_v2"},
+		},
+	}
+
+	report, err := NewLinter(cfg).Run(&Workspace{Config: tfconfig})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	findings := findingsFor(report, "resource-type-deprecated")
+	expected := []string{"aws_s3_bucket_object.legacy", "acme_bucket.custom", "data.aws_s3_bucket_object.legacy", "data.template_file.init"}
+	if len(findings) != len(expected) {
+		t.Fatalf("Expected %d findings, got %d: %+v", len(expected), len(findings), findings)
+	}
+	for i, subject := range expected {
+		if findings[i].Subject != subject {
+			t.Errorf("Expected finding %d subject %s, got %s", i, subject, findings[i].Subject)
+		}
+	}
+}
+
+func TestDescriptionRules(t *testing.T) {
+	tfconfig := &parser.TerraformConfig{
+		Variables: []*schema.Variable{
+			{Name: "good", Description: "Name of the VPC"},
+			{Name: "missing"},
+			{Name: "short", Description: "Name"},
+			{Name: "lower", Description: "name of the subnet"},
+			{Name: "period", Description: "Name of the route table."},
+			{Name: "todo", Description: "TODO describe this variable"},
+		},
+		Outputs: []*schema.Output{
+			{Name: "id", Description: "ID of the VPC (fixme)"},
+		},
+	}
+
+	rules := map[string]*config.RuleConfig{}
+	for _, id := range []string{"description-min-length", "description-capitalized", "description-trailing-period", "description-denylist"} {
+		rules[id] = &config.RuleConfig{Severity: "warning"}
+	}
+	cfg := &config.Config{
+		Rules:        rules,
+		Descriptions: &config.DescriptionPolicy{Denylist: []string{"todo", "fixme"}},
+	}
+
+	report, err := NewLinter(cfg).Run(&Workspace{Config: tfconfig})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := map[string][]string{
+		"description-min-length":      {"var.missing", "var.short"},
+		"description-capitalized":     {"var.lower"},
+		"description-trailing-period": {"var.period"},
+		"description-denylist":        {"var.todo", "output.id"},
+	}
+	for ruleID, subjects := range expected {
+		var got []string
+		for _, finding := range report.Findings {
+			if finding.RuleID == ruleID {
+				got = append(got, finding.Subject)
+			}
+		}
+		if strings.Join(got, ",") != strings.Join(subjects, ",") {
+			t.Errorf("%s: expected %v, got %v", ruleID, subjects, got)
+		}
+	}
+
+	if report, _ := NewLinter(nil).Run(&Workspace{Config: tfconfig}); len(report.Findings) != 0 {
+		t.Errorf("Expected description rules to be off by default, got %+v", report.Findings)
+	}
+}
+
+func TestNamingRules(t *testing.T) {
+	tfconfig := &parser.TerraformConfig{
+		Annotations: map[string]string{"environment": "prod"},
+		Variables:   []*schema.Variable{{Name: "vpc_cidr"}, {Name: "VpcName"}},
+		Outputs:     []*schema.Output{{Name: "network_vpc_id"}, {Name: "subnet_ids"}},
+		Resources:   []*schema.Resource{{Type: "aws_s3_bucket", Name: "logs_prod"}, {Type: "aws_s3_bucket", Name: "logs"}},
+	}
+	cfg := &config.Config{Naming: &config.NamingPolicy{
+		Profile: "platform",
+		Profiles: map[string][]*config.NamingRule{"platform": {
+			{Block: "variable", Pattern: `^[a-z][a-z0-9_]*$`, Severity: "error"},
+			{Block: "output", Pattern: `^{component}_`, Message: "outputs must be prefixed by the component name"},
+			{Block: "resource", Pattern: `{environment}`, Severity: "info"},
+			{Block: "module", Pattern: `{owner}`},
+		}},
+	}}
+
+	report, err := NewLinter(cfg).Run(&Workspace{Config: tfconfig, Dir: "modules/network"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := map[string]Severity{
+		"var.VpcName":        SeverityError,
+		"output.subnet_ids":  SeverityWarning,
+		"aws_s3_bucket.logs": SeverityInfo,
+	}
+	findings := findingsFor(report, "naming-convention")
+	if len(findings) != len(expected) {
+		t.Fatalf("Expected %d findings, got %+v", len(expected), findings)
+	}
+	for _, finding := range findings {
+		if severity, ok := expected[finding.Subject]; !ok || finding.Severity != severity {
+			t.Errorf("Unexpected finding %+v", finding)
+		}
+	}
+
+	cfg.Naming.Profile = "snake_case"
+	if report, _ := NewLinter(cfg).Run(&Workspace{Config: tfconfig}); len(findingsFor(report, "naming-convention")) != 1 {
+		t.Errorf("Expected the built-in profile to flag var.VpcName, got %+v", report.Findings)
+	}
+
+	cfg.Naming.Profile = "unknown"
+	if _, err := NewLinter(cfg).Run(&Workspace{Config: tfconfig}); err == nil {
+		t.Error("Expected an error for an unknown profile")
+	}
+}
+
+func TestParseSuppressions(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		// expected rule IDs with the line of the comment and of the annotated block
+		expected []string
+	}{
+		{
+			name:     "comment above the block",
+			content:  "# tfparser:ignore module-git-pinned\nmodule \"a\" {}\n",
+			expected: []string{"module-git-pinned 1->2"},
+		},
+		{
+			name:     "blank lines and comments before the block",
+			content:  "# tfparser:ignore module-git-pinned\n\n// see PLAT-12\n\nmodule \"a\" {}\n",
+			expected: []string{"module-git-pinned 1->5"},
+		},
+		{
+			name:     "trailing comment",
+			content:  "module \"a\" { # tfparser:ignore module-git-pinned\n}\n",
+			expected: []string{"module-git-pinned 1->1"},
+		},
+		{
+			name:     "comment inside a block",
+			content:  "module \"a\" {\n  // tfparser:ignore module-git-pinned\n  source = \"x\"\n}\n",
+			expected: []string{"module-git-pinned 2->3"},
+		},
+		{
+			name:     "string",
+			content:  "locals {\n  hint = \"// tfparser:ignore module-git-pinned in comments\"\n}\nmodule \"a\" {}\n",
+			expected: []string{},
+		},
+		{
+			name:     "heredoc",
+			content:  "locals {\n  doc = <<EOT\n# tfparser:ignore module-git-pinned\nEOT\n}\nmodule \"a\" {}\n",
+			expected: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, diags := hclsyntax.ParseConfig([]byte(tt.content), "main.tf", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatal(diags)
+			}
+
+			got := []string{}
+			for _, suppression := range ParseSuppressions([]*hcl.File{file}) {
+				got = append(got, fmt.Sprintf("%s %d->%d", suppression.RuleID, suppression.Line, suppression.target))
+			}
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestSuppressions(t *testing.T) {
+	content := `# tfparser:ignore module-git-pinned tracked in PLAT-12
+# second comment line
+module "commented" {
+  source = "git::https://example.com/a.git?ref=main"
+}
+
+module "trailing" { // tfparser:ignore module-git-pinned
+  source = "git::https://example.com/b.git?ref=main"
+}
+
+# tfparser:ignore provider-unused
+module "other_rule" {
+  source = "git::https://example.com/c.git?ref=main"
+}
+
+module "baselined" {
+  source = "git::https://example.com/d.git?ref=main"
+}
+`
+	file, diags := hclsyntax.ParseConfig([]byte(content), "modules/main.tf", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatal(diags)
+	}
+
+	suppressions := ParseSuppressions([]*hcl.File{file})
+	if len(suppressions) != 3 || suppressions[0].Reason != "tracked in PLAT-12" || suppressions[0].File != "modules/main.tf" {
+		t.Fatalf("Unexpected suppressions %+v", suppressions)
+	}
+
+	ws := &Workspace{
+		Config:       &parser.TerraformConfig{},
+		Suppressions: suppressions,
+		Locations:    map[string]*parser.Location{},
+	}
+	for _, block := range file.Body.(*hclsyntax.Body).Blocks {
+		ws.Config.Modules = append(ws.Config.Modules, &schema.Module{Name: block.Labels[0], Source: block.Body.Attributes["source"].Expr.(*hclsyntax.TemplateExpr).Parts[0].(*hclsyntax.LiteralValueExpr).Val.AsString()})
+		ws.Locations["module."+block.Labels[0]] = &parser.Location{File: "modules/main.tf", Line: block.DefRange().Start.Line}
+	}
+
+	baseline := &Baseline{Findings: []*BaselineEntry{{RuleID: "module-git-pinned", Subject: "module.baselined"}}}
+	report, err := NewLinter(nil).WithBaseline(baseline).Run(ws)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	findings := findingsFor(report, "module-git-pinned")
+	if len(findings) != 1 || findings[0].Subject != "module.other_rule" || report.Errors != 1 {
+		t.Errorf("Expected only module.other_rule to count, got %+v", findings)
+	}
+	suppressed := map[string]string{}
+	for _, finding := range report.Suppressed {
+		suppressed[finding.Subject] = finding.Suppression
+	}
+	expected := map[string]string{"module.commented": "tracked in PLAT-12", "module.trailing": "inline", "module.baselined": "baseline"}
+	if len(suppressed) != len(expected) {
+		t.Errorf("Expected %v suppressed, got %v", expected, suppressed)
+	}
+	for subject, reason := range expected {
+		if suppressed[subject] != reason {
+			t.Errorf("%s: expected suppression %q, got %q", subject, reason, suppressed[subject])
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	if err := NewBaseline(report).Write(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadBaseline(path)
+	if err != nil || len(loaded.Findings) != 1 || loaded.Findings[0].Subject != "module.other_rule" {
+		t.Errorf("Unexpected baseline %+v (%v)", loaded, err)
+	}
+}
+
+func TestExplain(t *testing.T) {
+	for _, rule := range Rules() {
+		explanation, err := Explain(rule.ID)
+		if err != nil {
+			t.Fatalf("%s: %v", rule.ID, err)
+		}
+		if explanation.Rationale == "" || explanation.Bad == "" || explanation.Good == "" || explanation.Fix == "" {
+			t.Errorf("%s: incomplete explanation %+v", rule.ID, explanation)
+		}
+	}
+	for id := range explanations {
+		if _, ok := registry[id]; !ok {
+			t.Errorf("Explanation of unknown rule %s", id)
+		}
+	}
+
+	if _, err := Explain("no-such-rule"); err == nil {
+		t.Error("Expected an error for an unknown rule")
+	}
+}
+
+func TestSensitiveExposure(t *testing.T) {
+	tfconfig := &parser.TerraformConfig{
+		Variables: []*schema.Variable{
+			{Name: "db_password", Sensitive: true},
+			{Name: "region", Sensitive: false},
+		},
+		Locals: []*schema.Local{
+			{Name: "conn", References: []string{"var.db_password", "var.region"}},
+		},
+		Resources: []*schema.Resource{
+			{Type: "random_password", Name: "admin"},
+		},
+		Outputs: []*schema.Output{
+			{Name: "conn", References: []string{"local.conn"}},
+			{Name: "conn_marked", Sensitive: ptr(true), References: []string{"local.conn"}},
+			{Name: "admin", References: []string{"random_password.admin"}},
+			{Name: "region", References: []string{"var.region"}},
+		},
+	}
+
+	report, err := NewLinter(&config.Config{}).Run(&Workspace{Config: tfconfig})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	findings := findingsFor(report, "output-sensitive-exposure")
+	if len(findings) != 2 {
+		t.Fatalf("Expected 2 findings, got %+v", findings)
+	}
+	if findings[0].Subject != "output.conn" || !strings.Contains(findings[0].Message, "var.db_password") {
+		t.Errorf("Unexpected finding: %+v", findings[0])
+	}
+	if findings[1].Subject != "output.admin" || !strings.Contains(findings[1].Message, "random_password.admin") {
+		t.Errorf("Unexpected finding: %+v", findings[1])
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestJUnit(t *testing.T) {
+	tfconfig := &parser.TerraformConfig{
+		Modules: []*schema.Module{
+			{Name: "vpc", Source: "git::https://example.com/vpc.git?ref=main"},
+		},
+	}
+
+	report, err := NewLinter(&config.Config{}).Run(&Workspace{Config: tfconfig})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	content, err := report.JUnit("lint")
+	if err != nil {
+		t.Fatalf("JUnit failed: %v", err)
+	}
+
+	suites := &junitTestSuites{}
+	if err := xml.Unmarshal(content, suites); err != nil {
+		t.Fatalf("Invalid XML: %v\n%s", err, content)
+	}
+	if suites.Tests != len(Rules()) {
+		t.Errorf("Expected a test case per rule (%d), got %d", len(Rules()), suites.Tests)
+	}
+	if suites.Failures != 1 {
+		t.Errorf("Expected 1 failure, got %d", suites.Failures)
+	}
+	if suites.Skipped == 0 {
+		t.Error("Expected the rules that are off by default to be skipped")
+	}
+
+	for _, suite := range suites.Suites {
+		for _, testCase := range suite.Cases {
+			if (testCase.Failure != nil) != (testCase.Name == "module-git-pinned") {
+				t.Errorf("Unexpected failure state of %s: %+v", testCase.Name, testCase.Failure)
+			}
+		}
+	}
+}
+
+func TestReviewFormats(t *testing.T) {
+	tfconfig := &parser.TerraformConfig{
+		Modules: []*schema.Module{
+			{Name: "vpc", Source: "git::https://example.com/vpc.git?ref=main"},
+			{Name: "db", Source: "git::https://example.com/db.git"},
+		},
+	}
+	ws := &Workspace{
+		Config:    tfconfig,
+		Dir:       "infra",
+		Locations: map[string]*parser.Location{"module.vpc": {File: "infra/main.tf", Line: 12, Column: 1}},
+	}
+
+	report, err := NewLinter(&config.Config{}).Run(ws)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	content, err := report.Checkstyle()
+	if err != nil {
+		t.Fatalf("Checkstyle failed: %v", err)
+	}
+	checkstyle := &checkstyleReport{}
+	if err := xml.Unmarshal(content, checkstyle); err != nil {
+		t.Fatalf("Invalid XML: %v\n%s", err, content)
+	}
+	if len(checkstyle.Files) != 2 || checkstyle.Files[0].Name != "infra/main.tf" || checkstyle.Files[1].Name != "infra" {
+		t.Fatalf("Expected findings in infra/main.tf and infra, got %s", content)
+	}
+	if line := checkstyle.Files[0].Errors[0].Line; line != 12 {
+		t.Errorf("Expected line 12, got %d", line)
+	}
+
+	rdjson := report.RDJSON()
+	if len(rdjson.Diagnostics) != 2 {
+		t.Fatalf("Expected 2 diagnostics, got %d", len(rdjson.Diagnostics))
+	}
+	located := rdjson.Diagnostics[0]
+	if located.Severity != "ERROR" || located.Code.Value != "module-git-pinned" || located.Location.Range.Start.Line != 12 {
+		t.Errorf("Unexpected diagnostic %+v", located)
+	}
+	if rdjson.Diagnostics[1].Location.Range != nil {
+		t.Error("Expected no range for a finding without location")
+	}
+}
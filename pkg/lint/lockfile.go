@@ -0,0 +1,51 @@
+package lint
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/config"
+)
+
+func init() {
+	register(&Rule{
+		ID:              "provider-lock-drift",
+		Category:        CategoryLint,
+		Description:     "Required providers must be in the dependency lock file with the constraints of required_providers",
+		DefaultSeverity: SeverityWarning,
+		Check:           checkProviderLockDrift,
+	})
+}
+
+// checkProviderLockDrift compares required_providers with .terraform.lock.hcl. Workspaces without
+// lock file are skipped: committing it is a choice of the team.
+func checkProviderLockDrift(ws *Workspace, cfg *config.Config) []Finding {
+	findings := []Finding{}
+	if len(ws.Config.LockedProviders) == 0 {
+		return findings
+	}
+
+	for _, terraform := range ws.Config.Terraform {
+		for _, name := range slices.Sorted(maps.Keys(terraform.RequiredProviders)) {
+			required := terraform.RequiredProviders[name]
+			subject := "required_providers." + name
+
+			locked := ws.Config.LockedProvider(name, required)
+			switch {
+			case locked == nil:
+				findings = append(findings, Finding{
+					Subject: subject,
+					Message: fmt.Sprintf("provider %s is missing from the dependency lock file; run terraform init", name),
+				})
+			case normalizeConstraint(locked.Constraints) != normalizeConstraint(required.Version):
+				findings = append(findings, Finding{
+					Subject: subject,
+					Message: fmt.Sprintf("provider %s is locked at %s with constraints %q, but required_providers declares %q; run terraform init -upgrade", name, locked.Version, locked.Constraints, required.Version),
+				})
+			}
+		}
+	}
+
+	return findings
+}
@@ -0,0 +1,104 @@
+package lint
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/config"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/logger"
+)
+
+// The naming rule checks the rules of the naming profile selected in the config file
+func init() {
+	register(&Rule{
+		ID:              "naming-convention",
+		Category:        CategoryLint,
+		Description:     "Block names must match the rules of the naming.profile profile",
+		DefaultSeverity: SeverityWarning,
+		Check:           checkNaming,
+	})
+}
+
+var namingPlaceholder = regexp.MustCompile(`\{([a-z_][a-z0-9_]*)\}`)
+
+type namedObject struct {
+	block   string
+	name    string
+	subject string
+}
+
+func namedObjects(ws *Workspace) []namedObject {
+	objects := []namedObject{}
+	for _, variable := range ws.Config.Variables {
+		objects = append(objects, namedObject{"variable", variable.Name, variable.Address()})
+	}
+	for _, output := range ws.Config.Outputs {
+		objects = append(objects, namedObject{"output", output.Name, output.Address()})
+	}
+	for _, resource := range ws.Config.Resources {
+		objects = append(objects, namedObject{"resource", resource.Name, resource.Address()})
+	}
+	for _, data := range ws.Config.DataSources {
+		objects = append(objects, namedObject{"data", data.Name, data.Address()})
+	}
+	for _, module := range ws.Config.Modules {
+		objects = append(objects, namedObject{"module", module.Name, module.Address()})
+	}
+	for _, local := range ws.Config.Locals {
+		objects = append(objects, namedObject{"local", local.Name, local.Address()})
+	}
+	return objects
+}
+
+func checkNaming(ws *Workspace, cfg *config.Config) []Finding {
+	findings := []Finding{}
+
+	// Linter.Run rejects invalid naming profiles before the rules run
+	rules, _ := cfg.NamingRules()
+	for _, rule := range rules {
+		pattern, err := expandNamingPattern(rule.Pattern, ws)
+		if err != nil {
+			logger.DebugKV("Skipping naming rule", "block", rule.Block, "pattern", rule.Pattern, "reason", err)
+			continue
+		}
+
+		severity := Severity(rule.Severity)
+		for _, object := range namedObjects(ws) {
+			if object.block != rule.Block || pattern.MatchString(object.name) {
+				continue
+			}
+
+			message := rule.Message
+			if message == "" {
+				message = fmt.Sprintf("%s name %q does not match %s", object.block, object.name, pattern)
+			}
+			findings = append(findings, Finding{Subject: object.subject, Message: message, Severity: severity})
+		}
+	}
+
+	return findings
+}
+
+// expandNamingPattern replaces the {key} placeholders of pattern with the module annotations
+func expandNamingPattern(pattern string, ws *Workspace) (*regexp.Regexp, error) {
+	var missing []string
+	expanded := namingPlaceholder.ReplaceAllStringFunc(pattern, func(placeholder string) string {
+		key := strings.Trim(placeholder, "{}")
+		value, ok := ws.Config.Annotations[key]
+		if !ok && key == "component" && ws.Dir != "" {
+			if dir, err := filepath.Abs(ws.Dir); err == nil {
+				value, ok = filepath.Base(dir), true
+			}
+		}
+		if !ok {
+			missing = append(missing, key)
+		}
+		return regexp.QuoteMeta(value)
+	})
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("the module has no %s annotation", strings.Join(missing, ", "))
+	}
+	return regexp.Compile(expanded)
+}
@@ -0,0 +1,112 @@
+package lint
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/config"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/source"
+)
+
+func init() {
+	register(&Rule{
+		ID:              "module-git-pinned",
+		Category:        CategoryPolicy,
+		Description:     "Git module sources must reference a tag or commit, not a branch",
+		DefaultSeverity: SeverityError,
+		Check:           checkGitModulePinned,
+	})
+	register(&Rule{
+		ID:              "module-registry-version",
+		Category:        CategoryPolicy,
+		Description:     "Registry modules must use an exact or ~> version constraint",
+		DefaultSeverity: SeverityError,
+		Check:           checkRegistryModuleVersion,
+	})
+	register(&Rule{
+		ID:              "provider-version-upper-bound",
+		Category:        CategoryPolicy,
+		Description:     "Required providers must declare a version constraint with an upper bound",
+		DefaultSeverity: SeverityWarning,
+		Check:           checkProviderUpperBound,
+	})
+}
+
+func checkGitModulePinned(ws *Workspace, cfg *config.Config) []Finding {
+	findings := []Finding{}
+
+	for _, module := range ws.Config.Modules {
+		src := source.ParseModuleSource(module.Source)
+		if src.Kind != source.ModuleSourceGit {
+			continue
+		}
+
+		subject := module.Address()
+		switch {
+		case src.Ref == "":
+			findings = append(findings, Finding{
+				Subject: subject,
+				Message: fmt.Sprintf("git source %q has no ref and follows the default branch", module.Source),
+			})
+		case source.DetectRefType(src.Ref) == source.RefTypeBranch:
+			findings = append(findings, Finding{
+				Subject: subject,
+				Message: fmt.Sprintf("git source %q references branch %q instead of a tag or commit", module.Source, src.Ref),
+			})
+		}
+	}
+
+	return findings
+}
+
+func checkRegistryModuleVersion(ws *Workspace, cfg *config.Config) []Finding {
+	findings := []Finding{}
+
+	for _, module := range ws.Config.Modules {
+		if source.ParseModuleSource(module.Source).Kind != source.ModuleSourceRegistry {
+			continue
+		}
+
+		subject := module.Address()
+		switch {
+		case module.Version == "":
+			findings = append(findings, Finding{
+				Subject: subject,
+				Message: fmt.Sprintf("registry module %q has no version constraint", module.Source),
+			})
+		case !isExactOrPessimistic(module.Version):
+			findings = append(findings, Finding{
+				Subject: subject,
+				Message: fmt.Sprintf("registry module %q uses version constraint %q; use an exact version or ~>", module.Source, module.Version),
+			})
+		}
+	}
+
+	return findings
+}
+
+func checkProviderUpperBound(ws *Workspace, cfg *config.Config) []Finding {
+	findings := []Finding{}
+
+	for _, terraform := range ws.Config.Terraform {
+		for _, name := range slices.Sorted(maps.Keys(terraform.RequiredProviders)) {
+			provider := terraform.RequiredProviders[name]
+			subject := "required_providers." + name
+			switch {
+			case provider.Version == "":
+				findings = append(findings, Finding{
+					Subject: subject,
+					Message: fmt.Sprintf("provider %s has no version constraint", name),
+				})
+			case !hasUpperBound(provider.Version):
+				findings = append(findings, Finding{
+					Subject: subject,
+					Message: fmt.Sprintf("provider %s version constraint %q has no upper bound", name, provider.Version),
+				})
+			}
+		}
+	}
+
+	return findings
+}
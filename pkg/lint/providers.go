@@ -0,0 +1,126 @@
+package lint
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/config"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+)
+
+// builtinProviders are available without a required_providers entry
+var builtinProviders = map[string]bool{
+	"terraform": true,
+}
+
+func init() {
+	register(&Rule{
+		ID:              "provider-undeclared",
+		Category:        CategoryLint,
+		Description:     "Providers used by resources and data sources must be declared in required_providers",
+		DefaultSeverity: SeverityWarning,
+		Check:           checkUndeclaredProviders,
+	})
+	register(&Rule{
+		ID:              "provider-unused",
+		Category:        CategoryLint,
+		Description:     "Providers declared in required_providers should be used by a resource or data source, configured in a provider block or passed to a module",
+		DefaultSeverity: SeverityInfo,
+		Check:           checkUnusedProviders,
+	})
+}
+
+// usedProviders maps each provider local name to the addresses of resources using it
+func usedProviders(tfconfig *parser.TerraformConfig) map[string][]string {
+	used := map[string][]string{}
+
+	for _, resource := range tfconfig.Resources {
+		name := resource.ProviderLocalName()
+		used[name] = append(used[name], resource.Address())
+	}
+	for _, data := range tfconfig.DataSources {
+		name := data.ProviderLocalName()
+		used[name] = append(used[name], data.Address())
+	}
+	for _, ephemeral := range tfconfig.EphemeralResources {
+		name := ephemeral.ProviderLocalName()
+		used[name] = append(used[name], ephemeral.Address())
+	}
+
+	return used
+}
+
+// configuredProviders returns the local names of the providers configured in provider blocks or
+// passed to module calls. Child modules use these configurations, by inheritance or through their
+// providers map, so they count as usage even without resources in this configuration.
+func configuredProviders(tfconfig *parser.TerraformConfig) map[string]bool {
+	configured := map[string]bool{}
+
+	for _, provider := range tfconfig.Providers {
+		configured[provider.Name] = true
+	}
+	for _, module := range tfconfig.Modules {
+		for _, parent := range module.Providers {
+			name, _, _ := strings.Cut(parent, ".")
+			configured[name] = true
+		}
+	}
+
+	return configured
+}
+
+func declaredProviders(tfconfig *parser.TerraformConfig) map[string]bool {
+	declared := map[string]bool{}
+
+	for _, terraform := range tfconfig.Terraform {
+		for name := range terraform.RequiredProviders {
+			declared[name] = true
+		}
+	}
+
+	return declared
+}
+
+func checkUndeclaredProviders(ws *Workspace, cfg *config.Config) []Finding {
+	findings := []Finding{}
+	declared := declaredProviders(ws.Config)
+	used := usedProviders(ws.Config)
+
+	for _, name := range slices.Sorted(maps.Keys(used)) {
+		if declared[name] || builtinProviders[name] {
+			continue
+		}
+
+		findings = append(findings, Finding{
+			Subject: "provider." + name,
+			Message: fmt.Sprintf("provider %s is used by %s but not declared in required_providers", name, strings.Join(used[name], ", ")),
+		})
+	}
+
+	return findings
+}
+
+func checkUnusedProviders(ws *Workspace, cfg *config.Config) []Finding {
+	findings := []Finding{}
+	declared := declaredProviders(ws.Config)
+	used := usedProviders(ws.Config)
+	configured := configuredProviders(ws.Config)
+
+	for _, name := range slices.Sorted(maps.Keys(declared)) {
+		if _, ok := used[name]; ok {
+			continue
+		}
+		if configured[name] {
+			continue
+		}
+
+		findings = append(findings, Finding{
+			Subject: "provider." + name,
+			Message: fmt.Sprintf("provider %s is declared in required_providers but no resource, data source, provider block or module call uses it", name),
+		})
+	}
+
+	return findings
+}
@@ -0,0 +1,66 @@
+package lint
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RDJSON is a diagnostic result in reviewdog's rdjson format
+type RDJSON struct {
+	Source      *RDJSONSource       `json:"source"`
+	Diagnostics []*RDJSONDiagnostic `json:"diagnostics"`
+}
+
+type RDJSONSource struct {
+	Name string `json:"name"`
+	URL  string `json:"url,omitempty"`
+}
+
+type RDJSONDiagnostic struct {
+	Message  string          `json:"message"`
+	Location *RDJSONLocation `json:"location"`
+	// Severity is ERROR, WARNING or INFO
+	Severity string      `json:"severity"`
+	Code     *RDJSONCode `json:"code"`
+}
+
+type RDJSONLocation struct {
+	Path  string       `json:"path"`
+	Range *RDJSONRange `json:"range,omitempty"`
+}
+
+type RDJSONRange struct {
+	Start *RDJSONPosition `json:"start"`
+}
+
+type RDJSONPosition struct {
+	Line   int `json:"line"`
+	Column int `json:"column,omitempty"`
+}
+
+type RDJSONCode struct {
+	Value string `json:"value"`
+}
+
+// RDJSON converts the findings to reviewdog's rdjson format, for reviewdog -f=rdjson
+func (r *Report) RDJSON() *RDJSON {
+	result := &RDJSON{
+		Source:      &RDJSONSource{Name: "terraform-config-parser", URL: "https://github.com/Yunsang-Jeong/terraform-config-parser"},
+		Diagnostics: []*RDJSONDiagnostic{},
+	}
+
+	for _, finding := range r.Findings {
+		diagnostic := &RDJSONDiagnostic{
+			Message:  fmt.Sprintf("%s: %s", finding.Subject, finding.Message),
+			Location: &RDJSONLocation{Path: r.findingPath(finding)},
+			Severity: strings.ToUpper(string(finding.Severity)),
+			Code:     &RDJSONCode{Value: finding.RuleID},
+		}
+		if finding.Location != nil {
+			diagnostic.Location.Range = &RDJSONRange{Start: &RDJSONPosition{Line: finding.Location.Line, Column: finding.Location.Column}}
+		}
+		result.Diagnostics = append(result.Diagnostics, diagnostic)
+	}
+
+	return result
+}
@@ -0,0 +1,32 @@
+package lint
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/config"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/sensitive"
+)
+
+func init() {
+	register(&Rule{
+		ID:              "output-sensitive-exposure",
+		Category:        CategoryLint,
+		Description:     "Outputs whose value depends on sensitive variables or secret resources must set sensitive = true",
+		DefaultSeverity: SeverityWarning,
+		Check:           checkSensitiveExposure,
+	})
+}
+
+func checkSensitiveExposure(ws *Workspace, cfg *config.Config) []Finding {
+	findings := []Finding{}
+
+	for _, exposure := range sensitive.Analyze(ws.Config).Unmarked() {
+		findings = append(findings, Finding{
+			Subject: exposure.Output,
+			Message: fmt.Sprintf("value depends on %s but the output is not marked sensitive", strings.Join(exposure.Sources, ", ")),
+		})
+	}
+
+	return findings
+}
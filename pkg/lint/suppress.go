@@ -0,0 +1,71 @@
+package lint
+
+import (
+	"bytes"
+	"regexp"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// suppressionCommentRegex matches comments like "# tfparser:ignore module-git-pinned pinned by the release branch"
+var suppressionCommentRegex = regexp.MustCompile(`^(?:#|//)\s*tfparser:ignore\s+([A-Za-z0-9_-]+)(?:\s+(.*?))?\s*$`)
+
+// Suppression is an inline comment silencing a rule for the block it annotates
+type Suppression struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	RuleID string `json:"rule_id"`
+	Reason string `json:"reason,omitempty"`
+	// target is the line of the annotated block: the line of a trailing comment, or the line of
+	// the next token after a comment on its own line, past blank lines and other comments
+	target int
+}
+
+// ParseSuppressions collects the tfparser:ignore comments of files, as returned by Parser.Files.
+// Only comment tokens are matched, so the text of strings and heredocs is never a suppression.
+func ParseSuppressions(files []*hcl.File) []*Suppression {
+	suppressions := []*Suppression{}
+	for _, file := range files {
+		filename := file.Body.MissingItemRange().Filename
+		tokens, _ := hclsyntax.LexConfig(file.Bytes, filename, hcl.InitialPos)
+		for i, token := range tokens {
+			if token.Type != hclsyntax.TokenComment {
+				continue
+			}
+			match := suppressionCommentRegex.FindSubmatch(bytes.TrimRight(token.Bytes, "\r\n"))
+			if match == nil {
+				continue
+			}
+
+			line := token.Range.Start.Line
+			suppression := &Suppression{File: filename, Line: line, RuleID: string(match[1]), Reason: string(match[2]), target: line}
+			// A comment on its own line follows a line break, which ends every # and // comment
+			if i == 0 || tokens[i-1].Type == hclsyntax.TokenNewline || tokens[i-1].Type == hclsyntax.TokenComment {
+				for _, next := range tokens[i+1:] {
+					if next.Type != hclsyntax.TokenComment && next.Type != hclsyntax.TokenNewline {
+						if next.Type != hclsyntax.TokenEOF {
+							suppression.target = next.Range.Start.Line
+						}
+						break
+					}
+				}
+			}
+			suppressions = append(suppressions, suppression)
+		}
+	}
+	return suppressions
+}
+
+// suppression returns the inline suppression of finding, or nil
+func (ws *Workspace) suppression(finding *Finding) *Suppression {
+	if finding.Location == nil {
+		return nil
+	}
+	for _, suppression := range ws.Suppressions {
+		if suppression.RuleID == finding.RuleID && suppression.File == finding.Location.File && suppression.target == finding.Location.Line {
+			return suppression
+		}
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package lint
+
+import (
+	"fmt"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/config"
+)
+
+func init() {
+	register(&Rule{
+		ID:              "required-version-too-loose",
+		Category:        CategoryLint,
+		Description:     "required_version must not allow Terraform versions older than the language features used",
+		DefaultSeverity: SeverityWarning,
+		Check:           checkRequiredVersion,
+	})
+}
+
+func checkRequiredVersion(ws *Workspace, cfg *config.Config) []Finding {
+	if ws.Compat == nil || !ws.Compat.TooLoose {
+		return []Finding{}
+	}
+
+	return []Finding{{
+		Subject: "terraform",
+		Message: fmt.Sprintf("required_version %q allows versions older than %s, required by the features used", ws.Compat.RequiredVersion, ws.Compat),
+	}}
+}
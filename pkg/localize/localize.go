@@ -0,0 +1,75 @@
+package localize
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/filesystem"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Translations holds translated descriptions keyed by variable and output name
+type Translations struct {
+	Variables map[string]string `yaml:"variables"`
+	Outputs   map[string]string `yaml:"outputs"`
+}
+
+// ErrNoTranslations is returned by Load when there is no companion file for the language, e.g.
+// because of a typo in its code
+var ErrNoTranslations = errors.New("no translations")
+
+// FileName returns the companion file holding the translations of lang, e.g. descriptions.ko.yaml
+func FileName(lang string) string {
+	return fmt.Sprintf("descriptions.%s.yaml", lang)
+}
+
+// Load reads the translations of lang from the companion file in dir. A missing file is an
+// ErrNoTranslations error.
+func Load(fs filesystem.FileReader, dir, lang string) (*Translations, error) {
+	translations := &Translations{}
+
+	filename := filepath.Join(dir, FileName(lang))
+	entries, err := fs.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory %s: %w", dir, err)
+	}
+	if !slices.ContainsFunc(entries, func(entry os.FileInfo) bool { return entry.Name() == FileName(lang) }) {
+		return nil, fmt.Errorf("%w for language %q: %s not found", ErrNoTranslations, lang, filename)
+	}
+
+	content, err := fs.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read translations %s: %w", filename, err)
+	}
+	if err := yaml.Unmarshal(content, translations); err != nil {
+		return nil, fmt.Errorf("failed to parse translations %s: %w", filename, err)
+	}
+
+	return translations, nil
+}
+
+// Apply replaces variable and output descriptions with their translations and returns
+// the addresses of described variables and outputs without a translation
+func Apply(tfconfig *parser.TerraformConfig, translations *Translations) (untranslated []string) {
+	for _, variable := range tfconfig.Variables {
+		if translated, ok := translations.Variables[variable.Name]; ok {
+			variable.Description = translated
+		} else if variable.Description != "" {
+			untranslated = append(untranslated, variable.Address())
+		}
+	}
+	for _, output := range tfconfig.Outputs {
+		if translated, ok := translations.Outputs[output.Name]; ok {
+			output.Description = translated
+		} else if output.Description != "" {
+			untranslated = append(untranslated, output.Address())
+		}
+	}
+
+	return untranslated
+}
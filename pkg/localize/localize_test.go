@@ -0,0 +1,57 @@
+package localize
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/filesystem"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser/schema"
+
+	"github.com/spf13/afero"
+)
+
+func TestApply(t *testing.T) {
+	memFs := afero.NewMemMapFs()
+	if err := afero.WriteFile(memFs, "/module/descriptions.ko.yaml", []byte(`
+variables:
+  name: 리소스 이름
+outputs:
+  id: 리소스 ID
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	fs := filesystem.NewAferoAdapter(memFs)
+
+	tfconfig := &parser.TerraformConfig{
+		Variables: []*schema.Variable{
+			{Name: "name", Description: "Name of the resource"},
+			{Name: "tags", Description: "Tags to apply"},
+			{Name: "internal"},
+		},
+		Outputs: []*schema.Output{
+			{Name: "id", Description: "ID of the resource"},
+		},
+	}
+
+	translations, err := Load(fs, "/module", "ko")
+	if err != nil {
+		t.Fatalf("Failed to load translations: %v", err)
+	}
+
+	untranslated := Apply(tfconfig, translations)
+	if tfconfig.Variables[0].Description != "리소스 이름" || tfconfig.Outputs[0].Description != "리소스 ID" {
+		t.Errorf("Expected translated descriptions, got %q and %q", tfconfig.Variables[0].Description, tfconfig.Outputs[0].Description)
+	}
+	if tfconfig.Variables[1].Description != "Tags to apply" {
+		t.Errorf("Expected untranslated description to be kept, got %q", tfconfig.Variables[1].Description)
+	}
+	if !reflect.DeepEqual(untranslated, []string{"var.tags"}) {
+		t.Errorf("Expected var.tags to be reported as untranslated, got %v", untranslated)
+	}
+
+	if _, err := Load(fs, "/module", "jp"); !errors.Is(err, ErrNoTranslations) {
+		t.Errorf("Expected ErrNoTranslations for a language without file, got %v", err)
+	}
+}
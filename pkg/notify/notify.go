@@ -0,0 +1,115 @@
+package notify
+
+import (
+	"fmt"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/diff"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/lint"
+)
+
+// Format is a chat notification payload format
+type Format string
+
+const (
+	FormatSlack Format = "slack"
+	FormatTeams Format = "teams"
+)
+
+// ParseFormat validates a notification format name
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(s); f {
+	case FormatSlack, FormatTeams:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unknown notification format %q (expected slack or teams)", s)
+	}
+}
+
+// Message is the content of a notification independent of the chat system
+type Message struct {
+	Title    string
+	Summary  string
+	Sections []*Section
+}
+
+// Section is a titled list of lines, e.g. the breaking changes of a diff
+type Section struct {
+	Title string
+	Lines []string
+}
+
+// maxLines keeps payloads below the size limits of the chat systems
+const maxLines = 50
+
+func (s *Section) add(line string) {
+	s.Lines = append(s.Lines, line)
+}
+
+// Payload renders the message as the JSON document to POST to a webhook of the format
+func (m *Message) Payload(format Format) any {
+	switch format {
+	case FormatTeams:
+		return teamsPayload(m)
+	default:
+		return slackPayload(m)
+	}
+}
+
+// DiffMessage summarizes a diff report; title names what was compared, e.g. "vpc v1.0.0 → v2.0.0"
+func DiffMessage(title string, report *diff.Report) *Message {
+	msg := &Message{
+		Title:   title,
+		Summary: fmt.Sprintf("%d change(s), %d breaking", len(report.Changes), report.Breaking),
+	}
+
+	breaking := &Section{Title: "Breaking changes"}
+	other := &Section{Title: "Other changes"}
+	for _, change := range report.Changes {
+		line := fmt.Sprintf("%s %s: %s", change.Kind, change.Address, change.Reason)
+		if change.Breaking {
+			breaking.add(line)
+		} else {
+			other.add(line)
+		}
+	}
+
+	msg.addSection(breaking)
+	msg.addSection(other)
+	return msg
+}
+
+// LintMessage summarizes a lint report; title names the linted target
+func LintMessage(title string, report *lint.Report) *Message {
+	msg := &Message{
+		Title:   title,
+		Summary: fmt.Sprintf("%d error(s), %d warning(s), %d info(s)", report.Errors, report.Warnings, report.Infos),
+	}
+
+	sections := map[lint.Severity]*Section{
+		lint.SeverityError:   {Title: "Errors"},
+		lint.SeverityWarning: {Title: "Warnings"},
+		lint.SeverityInfo:    {Title: "Infos"},
+	}
+	for _, finding := range report.Findings {
+		if section, ok := sections[finding.Severity]; ok {
+			section.add(fmt.Sprintf("[%s] %s: %s", finding.RuleID, finding.Subject, finding.Message))
+		}
+	}
+
+	msg.addSection(sections[lint.SeverityError])
+	msg.addSection(sections[lint.SeverityWarning])
+	msg.addSection(sections[lint.SeverityInfo])
+	return msg
+}
+
+// addSection appends non-empty sections, truncating long ones
+func (m *Message) addSection(section *Section) {
+	if len(section.Lines) == 0 {
+		return
+	}
+	if len(section.Lines) > maxLines {
+		more := len(section.Lines) - maxLines
+		section.Lines = append(section.Lines[:maxLines], fmt.Sprintf("… and %d more", more))
+	}
+	m.Sections = append(m.Sections, section)
+}
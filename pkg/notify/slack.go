@@ -0,0 +1,43 @@
+package notify
+
+import (
+	"strings"
+)
+
+// slackText escapes the characters Slack mrkdwn treats as control sequences
+var slackText = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// slackPayload renders a Block Kit message for chat.postMessage or an incoming webhook
+func slackPayload(m *Message) map[string]any {
+	blocks := []any{
+		map[string]any{
+			"type": "header",
+			"text": map[string]any{"type": "plain_text", "text": m.Title},
+		},
+		map[string]any{
+			"type": "section",
+			"text": map[string]any{"type": "mrkdwn", "text": slackText.Replace(m.Summary)},
+		},
+	}
+
+	for _, section := range m.Sections {
+		lines := make([]string, 0, len(section.Lines))
+		for _, line := range section.Lines {
+			lines = append(lines, "• "+slackText.Replace(line))
+		}
+
+		blocks = append(blocks,
+			map[string]any{"type": "divider"},
+			map[string]any{
+				"type": "section",
+				"text": map[string]any{"type": "mrkdwn", "text": "*" + section.Title + "*\n" + strings.Join(lines, "\n")},
+			},
+		)
+	}
+
+	return map[string]any{
+		// text is the fallback shown in notifications
+		"text":   m.Title + ": " + m.Summary,
+		"blocks": blocks,
+	}
+}
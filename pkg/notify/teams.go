@@ -0,0 +1,32 @@
+package notify
+
+// teamsPayload renders an Adaptive Card wrapped in the message envelope expected by
+// Teams incoming webhooks and workflows
+func teamsPayload(m *Message) map[string]any {
+	body := []any{
+		map[string]any{"type": "TextBlock", "text": m.Title, "size": "Large", "weight": "Bolder", "wrap": true},
+		map[string]any{"type": "TextBlock", "text": m.Summary, "isSubtle": true, "wrap": true},
+	}
+
+	for _, section := range m.Sections {
+		body = append(body, map[string]any{"type": "TextBlock", "text": section.Title, "weight": "Bolder", "separator": true, "wrap": true})
+		for _, line := range section.Lines {
+			body = append(body, map[string]any{"type": "TextBlock", "text": "- " + line, "spacing": "None", "wrap": true})
+		}
+	}
+
+	return map[string]any{
+		"type": "message",
+		"attachments": []any{
+			map[string]any{
+				"contentType": "application/vnd.microsoft.card.adaptive",
+				"content": map[string]any{
+					"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+					"type":    "AdaptiveCard",
+					"version": "1.4",
+					"body":    body,
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,266 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type Casing string
+
+const (
+	// CasingSnake keeps field names as declared, e.g. data_sources
+	CasingSnake Casing = "snake"
+	// CasingCamel converts field names to camelCase, e.g. dataSources
+	CasingCamel Casing = "camel"
+)
+
+type EmptyCollections string
+
+const (
+	// EmptyDefault follows the omitempty tags of each type
+	EmptyDefault EmptyCollections = ""
+	// EmptyOmit drops every empty list and map field
+	EmptyOmit EmptyCollections = "omit"
+	// EmptyEmit writes every list and map field, using [] and {} when empty
+	EmptyEmit EmptyCollections = "emit"
+)
+
+// Options controls how results are encoded as JSON
+type Options struct {
+	Casing           Casing
+	EmptyCollections EmptyCollections
+}
+
+// ParseOptions validates output options given on the command line
+func ParseOptions(casing, empty string) (Options, error) {
+	opts := Options{Casing: Casing(casing), EmptyCollections: EmptyCollections(empty)}
+
+	switch opts.Casing {
+	case "":
+		opts.Casing = CasingSnake
+	case CasingSnake, CasingCamel:
+	default:
+		return Options{}, fmt.Errorf("unknown JSON casing %q (expected snake or camel)", casing)
+	}
+
+	switch opts.EmptyCollections {
+	case EmptyDefault, EmptyOmit, EmptyEmit:
+	default:
+		return Options{}, fmt.Errorf("unknown empty collections mode %q (expected omit or emit)", empty)
+	}
+
+	return opts, nil
+}
+
+// Marshal encodes v like encoding/json without HTML escaping, applying opts to struct fields.
+// Map keys are data (variable names, addresses, ...) and are never renamed.
+func Marshal(v any, opts Options, pretty bool) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := opts.encode(&buf, reflect.ValueOf(v)); err != nil {
+		return nil, err
+	}
+	if !pretty {
+		return buf.Bytes(), nil
+	}
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, buf.Bytes(), "", "  "); err != nil {
+		return nil, err
+	}
+	return indented.Bytes(), nil
+}
+
+var marshalerType = reflect.TypeFor[json.Marshaler]()
+
+func (o Options) encode(buf *bytes.Buffer, v reflect.Value) error {
+	if !v.IsValid() {
+		buf.WriteString("null")
+		return nil
+	}
+
+	if v.Type().Implements(marshalerType) && (v.Kind() != reflect.Pointer || !v.IsNil()) {
+		return encodeLiteral(buf, v.Interface())
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			buf.WriteString("null")
+			return nil
+		}
+		return o.encode(buf, v.Elem())
+
+	case reflect.Struct:
+		buf.WriteByte('{')
+		first := true
+		err := o.encodeFields(buf, v, &first)
+		buf.WriteByte('}')
+		return err
+
+	case reflect.Map:
+		if v.IsNil() && o.EmptyCollections != EmptyEmit {
+			buf.WriteString("null")
+			return nil
+		}
+		keys := v.MapKeys()
+		names := make([]string, len(keys))
+		for i, key := range keys {
+			names[i] = fmt.Sprint(key.Interface())
+		}
+		order := make([]int, len(keys))
+		for i := range order {
+			order[i] = i
+		}
+		slices.SortFunc(order, func(a, b int) int { return strings.Compare(names[a], names[b]) })
+
+		buf.WriteByte('{')
+		for i, index := range order {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := encodeLiteral(buf, names[index]); err != nil {
+				return err
+			}
+			buf.WriteByte(':')
+			if err := o.encode(buf, v.MapIndex(keys[index])); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+		return nil
+
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() && o.EmptyCollections != EmptyEmit {
+			buf.WriteString("null")
+			return nil
+		}
+		buf.WriteByte('[')
+		for i := range v.Len() {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := o.encode(buf, v.Index(i)); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+		return nil
+	}
+
+	return encodeLiteral(buf, v.Interface())
+}
+
+// encodeFields writes the fields of a struct, flattening embedded structs like encoding/json
+func (o Options) encodeFields(buf *bytes.Buffer, v reflect.Value, first *bool) error {
+	t := v.Type()
+
+	for i := range t.NumField() {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, flags, _ := strings.Cut(tag, ",")
+		omitEmpty := slices.Contains(strings.Split(flags, ","), "omitempty")
+
+		value := v.Field(i)
+		if field.Anonymous && name == "" {
+			embedded := value
+			if embedded.Kind() == reflect.Pointer {
+				if embedded.IsNil() {
+					continue
+				}
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				if err := o.encodeFields(buf, embedded, first); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		if o.skip(value, omitEmpty) {
+			continue
+		}
+
+		if !*first {
+			buf.WriteByte(',')
+		}
+		*first = false
+
+		if err := encodeLiteral(buf, o.fieldName(name)); err != nil {
+			return err
+		}
+		buf.WriteByte(':')
+		if err := o.encode(buf, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (o Options) skip(value reflect.Value, omitEmpty bool) bool {
+	switch value.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Array:
+		switch o.EmptyCollections {
+		case EmptyEmit:
+			return false
+		case EmptyOmit:
+			return value.Len() == 0
+		}
+	}
+	return omitEmpty && isEmptyValue(value)
+}
+
+// isEmptyValue matches the omitempty semantics of encoding/json
+func isEmptyValue(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return value.Len() == 0
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.Interface, reflect.Pointer:
+		return value.IsZero()
+	}
+	return false
+}
+
+func (o Options) fieldName(name string) string {
+	if o.Casing != CasingCamel || !strings.Contains(name, "_") {
+		return name
+	}
+
+	parts := strings.Split(name, "_")
+	for i := 1; i < len(parts); i++ {
+		r, size := utf8.DecodeRuneInString(parts[i])
+		parts[i] = string(unicode.ToUpper(r)) + parts[i][size:]
+	}
+	return strings.Join(parts, "")
+}
+
+func encodeLiteral(buf *bytes.Buffer, v any) error {
+	var literal bytes.Buffer
+	encoder := json.NewEncoder(&literal)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return err
+	}
+	buf.Write(bytes.TrimSpace(literal.Bytes()))
+	return nil
+}
@@ -0,0 +1,106 @@
+package output
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type testResource struct {
+	Name string `json:"name"`
+}
+
+type testEmbedded struct {
+	testResource
+	Mode string `json:"mode,omitempty"`
+}
+
+type testConfig struct {
+	Variables   []string          `json:"variables,omitempty"`
+	DataSources []*testEmbedded   `json:"data_sources"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	Default     any               `json:"default,omitempty"`
+	Sensitive   bool              `json:"sensitive,omitempty"`
+	Internal    string            `json:"-"`
+}
+
+func TestMarshal(t *testing.T) {
+	value := &testConfig{
+		DataSources: []*testEmbedded{{testResource: testResource{Name: "current"}}},
+		Annotations: map[string]string{"owner_team": "network"},
+		Internal:    "hidden",
+	}
+
+	tests := []struct {
+		name     string
+		opts     Options
+		expected string
+	}{
+		{
+			name:     "default matches encoding/json",
+			opts:     Options{Casing: CasingSnake},
+			expected: mustMarshal(t, value),
+		},
+		{
+			name:     "camel case keeps map keys",
+			opts:     Options{Casing: CasingCamel},
+			expected: `{"dataSources":[{"name":"current"}],"annotations":{"owner_team":"network"}}`,
+		},
+		{
+			name:     "emit empty collections",
+			opts:     Options{Casing: CasingSnake, EmptyCollections: EmptyEmit},
+			expected: `{"variables":[],"data_sources":[{"name":"current"}],"annotations":{"owner_team":"network"}}`,
+		},
+		{
+			name:     "omit empty collections",
+			opts:     Options{Casing: CasingSnake, EmptyCollections: EmptyOmit},
+			expected: `{"data_sources":[{"name":"current"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := value
+			if tt.opts.EmptyCollections == EmptyOmit {
+				input = &testConfig{DataSources: value.DataSources, Annotations: map[string]string{}}
+			}
+
+			got, err := Marshal(input, tt.opts, false)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if string(got) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func mustMarshal(t *testing.T, v any) string {
+	t.Helper()
+	content, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+func TestWriteFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "docs", "summary.json")
+	if err := WriteFile(path, []byte("first")); err != nil {
+		t.Fatalf("Failed to write: %v", err)
+	}
+	if err := WriteFile(path, []byte("second")); err != nil {
+		t.Fatalf("Failed to overwrite: %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil || string(content) != "second" {
+		t.Errorf("Expected the file to hold the last content, got %q (%v)", content, err)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("Expected no temporary files to be left, got %d entries", len(entries))
+	}
+}
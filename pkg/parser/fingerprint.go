@@ -0,0 +1,126 @@
+package parser
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// fingerprint hashes the canonical form of all top-level blocks of a workspace.
+// Blocks are sorted so that the order of blocks and their distribution over files do not matter.
+func fingerprint(blocks []string) string {
+	sorted := slices.Clone(blocks)
+	slices.Sort(sorted)
+
+	sum := sha256.Sum256([]byte(strings.Join(sorted, "\n")))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// canonicalBlocks renders every top-level block of file in a form that ignores
+// formatting and comments
+func canonicalBlocks(file *hcl.File) []string {
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return nil
+	}
+
+	blocks := []string{}
+	for _, block := range body.Blocks {
+		var sb strings.Builder
+		writeCanonicalBlock(&sb, file.Bytes, block)
+		blocks = append(blocks, sb.String())
+	}
+	return blocks
+}
+
+func writeCanonicalBlock(sb *strings.Builder, src []byte, block *hclsyntax.Block) {
+	sb.WriteString(block.Type)
+	for _, label := range block.Labels {
+		sb.WriteString(" " + strconv.Quote(label))
+	}
+	sb.WriteString(" {")
+
+	names := []string{}
+	for name := range block.Body.Attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		sb.WriteString(name + "=")
+		writeCanonicalExpr(sb, src, block.Body.Attributes[name].Expr)
+		sb.WriteString(";")
+	}
+
+	// The order of nested blocks is meaningful (e.g. provisioners), so it is kept
+	for _, nested := range block.Body.Blocks {
+		writeCanonicalBlock(sb, src, nested)
+		sb.WriteString(";")
+	}
+
+	sb.WriteString("}")
+}
+
+// writeCanonicalExpr writes constant expressions as typed values, so that equal values written
+// differently (e.g. heredoc and quoted strings) match. Templates are written part by part,
+// other expressions as their tokens without layout.
+func writeCanonicalExpr(sb *strings.Builder, src []byte, expr hclsyntax.Expression) {
+	if val, diags := expr.Value(nil); !diags.HasErrors() && val.IsWhollyKnown() {
+		typeJSON, typeErr := ctyjson.MarshalType(val.Type())
+		valueJSON, valueErr := ctyjson.Marshal(val, val.Type())
+		if typeErr == nil && valueErr == nil {
+			sb.WriteString("v(" + string(typeJSON) + ":" + string(valueJSON) + ")")
+			return
+		}
+	}
+
+	switch expr := expr.(type) {
+	case *hclsyntax.TemplateExpr:
+		sb.WriteString("t(")
+		for _, part := range expr.Parts {
+			writeCanonicalExpr(sb, src, part)
+			sb.WriteString(" ")
+		}
+		sb.WriteString(")")
+		return
+	case *hclsyntax.TupleConsExpr:
+		sb.WriteString("l(")
+		for _, item := range expr.Exprs {
+			writeCanonicalExpr(sb, src, item)
+			sb.WriteString(" ")
+		}
+		sb.WriteString(")")
+		return
+	case *hclsyntax.ObjectConsExpr:
+		sb.WriteString("o(")
+		for _, item := range expr.Items {
+			// Bare keys evaluate to strings, so they match the equivalent quoted keys
+			writeCanonicalExpr(sb, src, item.KeyExpr)
+			sb.WriteString("=")
+			writeCanonicalExpr(sb, src, item.ValueExpr)
+			sb.WriteString(" ")
+		}
+		sb.WriteString(")")
+		return
+	}
+
+	rng := expr.Range()
+	tokens, _ := hclsyntax.LexExpression(src[rng.Start.Byte:rng.End.Byte], "", hcl.InitialPos)
+	sb.WriteString("e(")
+	for _, token := range tokens {
+		switch token.Type {
+		case hclsyntax.TokenNewline, hclsyntax.TokenComment, hclsyntax.TokenComma, hclsyntax.TokenEOF:
+			continue
+		}
+		sb.Write(token.Bytes)
+		sb.WriteString(" ")
+	}
+	sb.WriteString(")")
+}
@@ -0,0 +1,66 @@
+package parser
+
+import (
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// Location is where a block or attribute is declared
+type Location struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+}
+
+// LocateBlocks maps the address of every top-level block in the configuration files of dir to
+// its declaration, e.g. var.region, module.vpc or aws_instance.web. Local values are located by
+// their attribute as local.<name>, required providers as required_providers.<name>, and
+// provider.<name> points to the first provider block or, lacking one, the required provider entry.
+func (p *Parser) LocateBlocks(dir string) (map[string]*Location, error) {
+	files, _, err := p.loadWorkspaceFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	locations := map[string]*Location{}
+	// Nodes merged from override files are located in the override file
+	locate := func(address string, node hclsyntax.Node) {
+		if _, exists := locations[address]; exists {
+			return
+		}
+		rng := node.Range()
+		locations[address] = &Location{File: rng.Filename, Line: rng.Start.Line, Column: rng.Start.Column}
+	}
+
+	providerBlocks := map[string]bool{}
+	for _, wf := range files {
+		for _, block := range wf.file.Body.(*hclsyntax.Body).Blocks {
+			switch block.Type {
+			case "locals":
+				for _, attr := range block.Body.Attributes {
+					locate("local."+attr.Name, attr)
+				}
+			case "terraform":
+				locate("terraform", block)
+				for _, nested := range block.Body.Blocks {
+					if nested.Type != "required_providers" {
+						continue
+					}
+					for _, attr := range nested.Body.Attributes {
+						locate("required_providers."+attr.Name, attr)
+						locate("provider."+attr.Name, attr)
+					}
+				}
+			case "provider":
+				if len(block.Labels) == 1 && !providerBlocks[block.Labels[0]] {
+					providerBlocks[block.Labels[0]] = true
+					delete(locations, "provider."+block.Labels[0])
+					locate("provider."+block.Labels[0], block)
+				}
+			default:
+				locate(blockAddress(block, nil), block)
+			}
+		}
+	}
+
+	return locations, nil
+}
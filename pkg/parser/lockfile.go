@@ -0,0 +1,73 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/logger"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser/schema"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// loadLockFile parses the dependency lock file of dir. It returns nil when dir has none.
+func (p *Parser) loadLockFile(dir string) ([]*schema.LockedProvider, error) {
+	entries, err := p.fs.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory %s: %w", dir, err)
+	}
+	if !slices.ContainsFunc(entries, func(entry os.FileInfo) bool { return entry.Name() == schema.LockFileName }) {
+		return nil, nil
+	}
+
+	file, err := p.loadHcl(filepath.Join(dir, schema.LockFileName))
+	if err != nil {
+		return nil, err
+	}
+
+	locked := []*schema.LockedProvider{}
+	for _, block := range file.Body.(*hclsyntax.Body).Blocks {
+		if block.Type != "provider" {
+			continue
+		}
+
+		provider := &schema.LockedProvider{}
+		if err := provider.Parse(file, block); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", schema.LockFileName, err)
+		}
+		locked = append(locked, provider)
+	}
+
+	logger.DebugKV("Loaded dependency lock file", "directory", dir, "providers", len(locked))
+	return locked, nil
+}
+
+// applyLockedVersions sets the locked version of every required provider found in the lock file
+func applyLockedVersions(tfconfig *TerraformConfig) {
+	for _, terraform := range tfconfig.Terraform {
+		for name, required := range terraform.RequiredProviders {
+			if locked := tfconfig.LockedProvider(name, required); locked != nil {
+				required.LockedVersion = locked.Version
+			}
+		}
+	}
+}
+
+// LockedProvider returns the lock file entry of the required provider with local name name,
+// or nil when the lock file has none
+func (t *TerraformConfig) LockedProvider(name string, required *schema.RequiredProvider) *schema.LockedProvider {
+	source := required.Source
+	if source == "" {
+		// Providers without source default to the hashicorp namespace
+		source = "hashicorp/" + name
+	}
+
+	for _, locked := range t.LockedProviders {
+		if locked.Matches(source) {
+			return locked
+		}
+	}
+	return nil
+}
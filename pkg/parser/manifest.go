@@ -0,0 +1,177 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/logger"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/source"
+)
+
+// ModuleManifestPath is where terraform init records the installed modules, relative to the root module
+var ModuleManifestPath = filepath.Join(".terraform", "modules", "modules.json")
+
+// ModuleManifest is the content of .terraform/modules/modules.json
+type ModuleManifest struct {
+	Modules []*ManifestEntry `json:"Modules"`
+}
+
+// ManifestEntry is an installed module call
+type ManifestEntry struct {
+	// Key is the dotted path of module call names, e.g. vpc.subnets; the root module has an empty key
+	Key     string `json:"Key"`
+	Source  string `json:"Source"`
+	Version string `json:"Version,omitempty"`
+	// Dir is the module directory relative to the root module
+	Dir string `json:"Dir"`
+}
+
+// Lookup returns the entry of a module call key, or nil
+func (m *ModuleManifest) Lookup(key string) *ManifestEntry {
+	if m == nil {
+		return nil
+	}
+	for _, entry := range m.Modules {
+		if entry.Key == key {
+			return entry
+		}
+	}
+	return nil
+}
+
+// ResolvedModule is a module call together with the parsed configuration of its directory
+type ResolvedModule struct {
+	// Key is the dotted path of module call names, e.g. vpc.subnets
+	Key     string `json:"key"`
+	Source  string `json:"source"`
+	Version string `json:"version,omitempty"`
+	// Dir is empty when the module could not be resolved to a directory
+	Dir string `json:"dir,omitempty"`
+	// Skipped tells why the module in Dir was not parsed: "max_depth" or "pruned" (see
+	// Parser.WithPruning), or "cycle" when Dir is the directory of an enclosing module
+	Skipped string `json:"skipped,omitempty"`
+	// CycleOf is the key of the enclosing module of a cycle, "." for the root module
+	CycleOf  string            `json:"cycle_of,omitempty"`
+	Config   *TerraformConfig  `json:"config,omitempty"`
+	Children []*ResolvedModule `json:"children,omitempty"`
+}
+
+// LoadModuleManifest reads the module manifest of the root module in dir. It returns nil
+// when the workspace has not been initialized.
+func (p *Parser) LoadModuleManifest(dir string) (*ModuleManifest, error) {
+	manifestDir := filepath.Dir(filepath.Join(dir, ModuleManifestPath))
+	exist, err := p.fs.DirExists(manifestDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check module manifest directory: %w", err)
+	}
+	if !exist {
+		return nil, nil
+	}
+
+	entries, err := p.fs.ReadDir(manifestDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory %s: %w", manifestDir, err)
+	}
+	if !slices.ContainsFunc(entries, func(entry os.FileInfo) bool { return entry.Name() == filepath.Base(ModuleManifestPath) }) {
+		return nil, nil
+	}
+
+	content, err := p.fs.ReadFile(filepath.Join(dir, ModuleManifestPath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read module manifest: %w", err)
+	}
+
+	manifest := &ModuleManifest{}
+	if err := json.Unmarshal(content, manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse module manifest: %w", err)
+	}
+
+	logger.DebugKV("Loaded module manifest", "directory", dir, "modules", len(manifest.Modules))
+	return manifest, nil
+}
+
+// ResolveModuleTree parses the module calls of tfconfig recursively. Calls are resolved through
+// manifest (which may be nil) first, so that downloaded registry and git modules are parsed without
+// network access, and otherwise through local source paths. Unresolved calls are kept without Dir.
+// Calls that lead back to the directory of an enclosing module, and calls left out by the pruning
+// of the parser, are kept with their Dir but not parsed, and their Skipped reason.
+func (p *Parser) ResolveModuleTree(dir string, tfconfig *TerraformConfig, manifest *ModuleManifest) ([]*ResolvedModule, error) {
+	return p.resolveModules(dir, dir, "", tfconfig, manifest, map[string]string{filepath.Clean(dir): "."})
+}
+
+// resolveModules resolves the calls of the module in dir; ancestors maps the directories of the
+// enclosing modules to their keys
+func (p *Parser) resolveModules(rootDir, dir, parentKey string, tfconfig *TerraformConfig, manifest *ModuleManifest, ancestors map[string]string) ([]*ResolvedModule, error) {
+	resolved := []*ResolvedModule{}
+
+	for _, module := range tfconfig.Modules {
+		key := module.Name
+		if parentKey != "" {
+			key = parentKey + "." + module.Name
+		}
+
+		node := &ResolvedModule{Key: key, Source: module.Source, Version: module.Version}
+		resolved = append(resolved, node)
+
+		childDir := ""
+		if entry := manifest.Lookup(key); entry != nil {
+			childDir = filepath.Join(rootDir, entry.Dir)
+			node.Version = entry.Version
+		} else if source.ParseModuleSource(module.Source).Kind == source.ModuleSourceLocal {
+			childDir = filepath.Join(dir, module.Source)
+		}
+
+		if childDir == "" {
+			logger.DebugKV("Skipping module without installed directory", "module", key, "source", module.Source)
+			continue
+		}
+
+		exist, err := p.fs.DirExists(childDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check module %s directory: %w", key, err)
+		}
+		if !exist {
+			logger.DebugKV("Skipping unresolved module", "module", key, "directory", childDir)
+			continue
+		}
+		node.Dir = childDir
+
+		// Module calls are pruned by key, with dots as path separators, and by directory
+		rel, err := filepath.Rel(rootDir, childDir)
+		if err != nil {
+			rel = childDir
+		}
+		depth := strings.Count(key, ".") + 1
+		switch {
+		case ancestors[filepath.Clean(childDir)] != "":
+			node.Skipped, node.CycleOf = "cycle", ancestors[filepath.Clean(childDir)]
+			logger.InfoKV("Skipping module call cycle", "module", key, "directory", childDir, "cycle_of", node.CycleOf)
+			continue
+		case p.pruning != nil && p.pruning.MaxDepth > 0 && depth > p.pruning.MaxDepth:
+			node.Skipped = "max_depth"
+			continue
+		case p.pruning.Prunes(strings.ReplaceAll(key, ".", "/"), 0), p.pruning.Prunes(filepath.ToSlash(rel), 0):
+			node.Skipped = "pruned"
+			continue
+		}
+
+		child, err := p.ParseTerraformWorkspace(childDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse module %s: %w", key, err)
+		}
+		node.Config = child
+
+		childAncestors := maps.Clone(ancestors)
+		childAncestors[filepath.Clean(childDir)] = key
+		if node.Children, err = p.resolveModules(rootDir, childDir, key, child, manifest, childAncestors); err != nil {
+			return nil, err
+		}
+	}
+
+	return resolved, nil
+}
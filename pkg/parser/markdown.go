@@ -0,0 +1,190 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser/schema"
+)
+
+// Markdown renders the interface of the configuration as Markdown tables in the style of
+// terraform-docs: requirements, providers, modules, resources, inputs and outputs. Rows are
+// sorted by name.
+func (t *TerraformConfig) Markdown() string {
+	var sb strings.Builder
+
+	requirements, providerRequirements := [][]string{}, [][]string{}
+	providers := map[string]*markdownProvider{}
+	for _, terraform := range t.Terraform {
+		if terraform.RequiredVersion != "" {
+			requirements = append(requirements, []string{"terraform", code(terraform.RequiredVersion)})
+		}
+		for name, required := range terraform.RequiredProviders {
+			providerRequirements = append(providerRequirements, []string{name, orNA(code(required.Version))})
+			provider := providerRow(providers, name)
+			provider.source, provider.locked = required.Source, required.LockedVersion
+			provider.aliases = append(provider.aliases, required.ConfigurationAliases...)
+		}
+	}
+	for _, block := range t.Providers {
+		provider := providerRow(providers, block.Name)
+		if block.Alias != "" {
+			provider.aliases = append(provider.aliases, block.Alias)
+		}
+	}
+	slices.SortFunc(providerRequirements, func(a, b []string) int { return strings.Compare(a[0], b[0]) })
+	requirements = append(requirements, providerRequirements...)
+	writeTable(&sb, "Requirements", []string{"Name", "Version"}, requirements)
+
+	providerRows := [][]string{}
+	for _, name := range sortedKeys(providers) {
+		provider := providers[name]
+		slices.Sort(provider.aliases)
+		aliases := []string{}
+		for _, alias := range slices.Compact(provider.aliases) {
+			aliases = append(aliases, code(alias))
+		}
+		providerRows = append(providerRows, []string{name, orNA(code(provider.source)), orNA(code(provider.locked)), strings.Join(aliases, ", ")})
+	}
+	writeTable(&sb, "Providers", []string{"Name", "Source", "Locked Version", "Aliases"}, providerRows)
+
+	modules := slices.Clone(t.Modules)
+	slices.SortFunc(modules, func(a, b *schema.Module) int { return strings.Compare(a.Name, b.Name) })
+	moduleRows := [][]string{}
+	for _, module := range modules {
+		moduleRows = append(moduleRows, []string{module.Name, code(module.Source), orNA(code(module.Version))})
+	}
+	writeTable(&sb, "Modules", []string{"Name", "Source", "Version"}, moduleRows)
+
+	resourceRows := [][]string{}
+	for _, resource := range t.Resources {
+		resourceRows = append(resourceRows, []string{code(resource.Type + "." + resource.Name), "resource"})
+	}
+	for _, data := range t.DataSources {
+		resourceRows = append(resourceRows, []string{code("data." + data.Type + "." + data.Name), "data source"})
+	}
+	for _, ephemeral := range t.EphemeralResources {
+		resourceRows = append(resourceRows, []string{code("ephemeral." + ephemeral.Type + "." + ephemeral.Name), "ephemeral resource"})
+	}
+	slices.SortFunc(resourceRows, func(a, b []string) int { return strings.Compare(a[0], b[0]) })
+	writeTable(&sb, "Resources", []string{"Name", "Type"}, resourceRows)
+
+	variables := slices.Clone(t.Variables)
+	slices.SortFunc(variables, func(a, b *schema.Variable) int { return strings.Compare(a.Name, b.Name) })
+	inputRows := [][]string{}
+	for _, variable := range variables {
+		typ, value, required := code(variable.Type), "n/a", "yes"
+		if typ == "" {
+			typ = code("any")
+		}
+		if !variable.Required {
+			value, required = code(defaultValue(variable.Default)), "no"
+		}
+		inputRows = append(inputRows, []string{variable.Name, variable.Description, typ, value, required})
+	}
+	writeTable(&sb, "Inputs", []string{"Name", "Description", "Type", "Default", "Required"}, inputRows)
+
+	outputs := slices.Clone(t.Outputs)
+	slices.SortFunc(outputs, func(a, b *schema.Output) int { return strings.Compare(a.Name, b.Name) })
+	outputRows := [][]string{}
+	for _, output := range outputs {
+		sensitive := "no"
+		if output.Sensitive != nil && *output.Sensitive {
+			sensitive = "yes"
+		}
+		outputRows = append(outputRows, []string{output.Name, output.Description, sensitive})
+	}
+	writeTable(&sb, "Outputs", []string{"Name", "Description", "Sensitive"}, outputRows)
+
+	return strings.TrimSpace(sb.String()) + "\n"
+}
+
+type markdownProvider struct {
+	source  string
+	locked  string
+	aliases []string
+}
+
+func providerRow(providers map[string]*markdownProvider, name string) *markdownProvider {
+	if _, ok := providers[name]; !ok {
+		providers[name] = &markdownProvider{}
+	}
+	return providers[name]
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// writeTable writes a section with a table, or a "No <title>." line without rows
+func writeTable(sb *strings.Builder, title string, header []string, rows [][]string) {
+	fmt.Fprintf(sb, "## %s\n\n", title)
+	if len(rows) == 0 {
+		fmt.Fprintf(sb, "No %s.\n\n", strings.ToLower(title))
+		return
+	}
+
+	separators := make([]string, len(header))
+	for i := range header {
+		separators[i] = "------"
+	}
+	fmt.Fprintf(sb, "| %s |\n|%s|\n", strings.Join(header, " | "), strings.Join(separators, "|"))
+	for _, row := range rows {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			cells[i] = escapeCell(cell)
+		}
+		fmt.Fprintf(sb, "| %s |\n", strings.Join(cells, " | "))
+	}
+	sb.WriteString("\n")
+}
+
+// escapeCell keeps a value on one table row: pipes are escaped and line breaks become <br>
+func escapeCell(cell string) string {
+	cell = strings.ReplaceAll(cell, "|", `\|`)
+	cell = strings.ReplaceAll(strings.TrimSpace(cell), "\r\n", "\n")
+	return strings.ReplaceAll(cell, "\n", "<br>")
+}
+
+// code wraps a non-empty value in backticks
+func code(value string) string {
+	if value == "" {
+		return ""
+	}
+	return "`" + strings.Join(strings.Fields(value), " ") + "`"
+}
+
+func orNA(value string) string {
+	if value == "" {
+		return "n/a"
+	}
+	return value
+}
+
+// defaultValue renders a default as HCL: strings are quoted, while lists and maps, which the
+// parser keeps as source text, are printed as they are
+func defaultValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		if strings.HasPrefix(v, "[") || strings.HasPrefix(v, "{") {
+			return v
+		}
+		quoted, _ := json.Marshal(v)
+		return string(quoted)
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return string(encoded)
+	}
+}
@@ -0,0 +1,117 @@
+package parser
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser/schema"
+)
+
+// MergePolicy selects how Merge handles blocks declared in both configurations
+type MergePolicy int
+
+const (
+	// MergeError fails on the first block declared in both configurations
+	MergeError MergePolicy = iota
+	// MergePreferLeft keeps the block of the configuration merged into and drops the other
+	MergePreferLeft
+	// MergeAppend keeps both blocks, as when the same block is declared in two files
+	MergeAppend
+)
+
+// Merge adds the blocks of other to the configuration, e.g. to compose configurations parsed
+// from several directories or to overlay generated blocks onto parsed ones. Blocks are matched
+// by address (variables, outputs, modules, resources, data sources, ephemeral resources,
+// providers and locals), import target, lock file source and annotation key; policy decides
+// what happens to the matches. Terraform blocks and diagnostics are always appended. The
+// fingerprint is cleared since it no longer describes parsed content. On error the
+// configuration is left unchanged.
+func (t *TerraformConfig) Merge(other *TerraformConfig, policy MergePolicy) error {
+	if other == nil {
+		return nil
+	}
+
+	merged := *t
+	var err error
+	if merged.Variables, err = mergeBlocks("variable", t.Variables, other.Variables, (*schema.Variable).Address, policy); err != nil {
+		return err
+	}
+	if merged.Outputs, err = mergeBlocks("output", t.Outputs, other.Outputs, (*schema.Output).Address, policy); err != nil {
+		return err
+	}
+	if merged.Modules, err = mergeBlocks("module", t.Modules, other.Modules, (*schema.Module).Address, policy); err != nil {
+		return err
+	}
+	if merged.Resources, err = mergeBlocks("resource", t.Resources, other.Resources, (*schema.Resource).Address, policy); err != nil {
+		return err
+	}
+	if merged.DataSources, err = mergeBlocks("data", t.DataSources, other.DataSources, (*schema.DataSource).Address, policy); err != nil {
+		return err
+	}
+	if merged.EphemeralResources, err = mergeBlocks("ephemeral", t.EphemeralResources, other.EphemeralResources, (*schema.EphemeralResource).Address, policy); err != nil {
+		return err
+	}
+	if merged.Providers, err = mergeBlocks("provider", t.Providers, other.Providers, (*schema.Provider).Address, policy); err != nil {
+		return err
+	}
+	if merged.Locals, err = mergeBlocks("local value", t.Locals, other.Locals, (*schema.Local).Address, policy); err != nil {
+		return err
+	}
+	if merged.Imports, err = mergeBlocks("import", t.Imports, other.Imports, func(b *schema.Import) string { return b.To }, policy); err != nil {
+		return err
+	}
+	if merged.LockedProviders, err = mergeBlocks("locked provider", t.LockedProviders, other.LockedProviders, func(b *schema.LockedProvider) string { return b.Source }, policy); err != nil {
+		return err
+	}
+
+	if len(other.Annotations) > 0 {
+		merged.Annotations = make(map[string]string, len(t.Annotations)+len(other.Annotations))
+		maps.Copy(merged.Annotations, t.Annotations)
+		for _, key := range sortedKeys(other.Annotations) {
+			if _, ok := t.Annotations[key]; ok {
+				switch policy {
+				case MergeError:
+					return fmt.Errorf("annotation %q is declared in both configurations", key)
+				case MergePreferLeft:
+					continue
+				}
+			}
+			merged.Annotations[key] = other.Annotations[key]
+		}
+	}
+
+	merged.Terraform = append(slices.Clone(t.Terraform), other.Terraform...)
+	merged.Diagnostics = append(slices.Clone(t.Diagnostics), other.Diagnostics...)
+	merged.Fingerprint = ""
+
+	*t = merged
+	return nil
+}
+
+// mergeBlocks appends the blocks of right to those of left, handling the blocks whose key is
+// already in left according to policy. kind names the blocks in errors.
+func mergeBlocks[T any](kind string, left, right []T, key func(T) string, policy MergePolicy) ([]T, error) {
+	if len(right) == 0 {
+		return left, nil
+	}
+
+	keys := make(map[string]bool, len(left))
+	for _, block := range left {
+		keys[key(block)] = true
+	}
+
+	merged := slices.Clone(left)
+	for _, block := range right {
+		if keys[key(block)] {
+			switch policy {
+			case MergeError:
+				return nil, fmt.Errorf("%s %q is declared in both configurations", kind, key(block))
+			case MergePreferLeft:
+				continue
+			}
+		}
+		merged = append(merged, block)
+	}
+	return merged, nil
+}
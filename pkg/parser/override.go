@@ -0,0 +1,203 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/logger"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// sourceFile is the native syntax content of a configuration file, before parsing
+type sourceFile struct {
+	name    string
+	path    string
+	content []byte
+}
+
+// mergedNestedBlocks are nested block types that override blocks merge argument by argument
+// instead of replacing
+var mergedNestedBlocks = map[string]bool{
+	"lifecycle":          true,
+	"required_providers": true,
+}
+
+// isOverrideFile reports whether name is an override file: override.tf, *_override.tf or
+// their .tf.json forms
+func isOverrideFile(name string) bool {
+	base := strings.TrimSuffix(strings.TrimSuffix(name, ".json"), ".tf")
+	return base == "override" || strings.HasSuffix(base, "_override")
+}
+
+// applyOverrides merges the blocks of the override files, in order, into the parsed primary files
+// the way Terraform does: the arguments of an override block replace those of the block it
+// matches, its nested blocks replace every nested block of the same type (lifecycle and
+// required_providers are merged argument by argument instead), and local values replace the
+// local value of the same name.
+//
+// Merged blocks keep their ranges, and the arguments and nested blocks merged into them keep the
+// ranges of the override file, so that positions point into the files as written.
+func applyOverrides(primary []*workspaceFile, overrides []*sourceFile) error {
+	for _, override := range overrides {
+		file, diags := hclsyntax.ParseConfig(override.content, override.path, hcl.InitialPos)
+		if diags.HasErrors() {
+			return fmt.Errorf("failed to parse HCL syntax in %s: %w", override.path, errors.Join(diags.Errs()...))
+		}
+
+		o := &overrideFile{sourceFile: override, placed: map[*hcl.File][]*hclsyntax.Block{}}
+		for j, block := range file.Body.(*hclsyntax.Body).Blocks {
+			if err := o.apply(primary, j, block); err != nil {
+				return fmt.Errorf("failed to apply override file %s: %w", override.name, err)
+			}
+		}
+		logger.DebugKV("Applied override file", "file", override.path)
+	}
+	return nil
+}
+
+// overrideFile is an override file being merged. Its nodes slice their source text out of the
+// file they are merged into, so the file is parsed again at the end of the bytes of each primary
+// file it merges into, with its ranges still naming the override file and its lines.
+type overrideFile struct {
+	*sourceFile
+	placed map[*hcl.File][]*hclsyntax.Block
+}
+
+// in returns the top-level blocks of the override file parsed at the end of file's bytes.
+// Comments are blanked out of the appended bytes, so that they neither document nor annotate
+// the blocks of file.
+func (o *overrideFile) in(file *hcl.File) []*hclsyntax.Block {
+	if blocks, ok := o.placed[file]; ok {
+		return blocks
+	}
+
+	start := hcl.Pos{Line: 1, Column: 1, Byte: len(file.Bytes)}
+	file.Bytes = append(slices.Clip(file.Bytes), blankComments(o.content)...)
+	parsed, _ := hclsyntax.ParseConfig(o.content, o.path, start)
+	blocks := parsed.Body.(*hclsyntax.Body).Blocks
+	o.placed[file] = blocks
+	return blocks
+}
+
+// apply merges block, the j-th block of the override file, into the primary files
+func (o *overrideFile) apply(files []*workspaceFile, j int, block *hclsyntax.Block) error {
+	switch block.Type {
+	case "locals":
+		for _, name := range slices.Sorted(maps.Keys(block.Body.Attributes)) {
+			wf, target := findBlock(files, func(block *hclsyntax.Block) bool {
+				_, ok := block.Body.Attributes[name]
+				return block.Type == "locals" && ok
+			})
+			if target == nil {
+				return fmt.Errorf("missing base local value local.%s to override", name)
+			}
+			target.Body.Attributes[name] = o.in(wf.file)[j].Body.Attributes[name]
+		}
+		return nil
+	case "import", "moved", "removed":
+		return fmt.Errorf("%s blocks are not allowed in override files", block.Type)
+	}
+
+	key := blockKey(block)
+	wf, target := findBlock(files, func(block *hclsyntax.Block) bool { return blockKey(block) == key })
+	if target == nil {
+		// Terraform settings have no base to match; they apply to the module as a whole
+		if block.Type != "terraform" || len(files) == 0 {
+			return fmt.Errorf("missing base %s block to override", key)
+		}
+		body := files[0].file.Body.(*hclsyntax.Body)
+		body.Blocks = append(body.Blocks, o.in(files[0].file)[j])
+		return nil
+	}
+
+	mergeBlock(target, o.in(wf.file)[j])
+	return nil
+}
+
+// findBlock returns the first top-level block of files that match accepts, with its file
+func findBlock(files []*workspaceFile, match func(*hclsyntax.Block) bool) (*workspaceFile, *hclsyntax.Block) {
+	for _, wf := range files {
+		for _, block := range wf.file.Body.(*hclsyntax.Body).Blocks {
+			if match(block) {
+				return wf, block
+			}
+		}
+	}
+	return nil, nil
+}
+
+// blockKey identifies the blocks an override block matches: its type and labels, and the alias
+// of provider configurations
+func blockKey(block *hclsyntax.Block) string {
+	key := strings.Join(append([]string{block.Type}, block.Labels...), ".")
+	if alias, ok := block.Body.Attributes["alias"]; ok && block.Type == "provider" {
+		if value, diags := alias.Expr.Value(nil); !diags.HasErrors() && value.Type() == cty.String && value.IsKnown() && !value.IsNull() {
+			key += "." + value.AsString()
+		}
+	}
+	return key
+}
+
+// mergeBlock merges the arguments and nested blocks of override into target
+func mergeBlock(target, override *hclsyntax.Block) {
+	body := target.Body
+	maps.Copy(body.Attributes, override.Body.Attributes)
+
+	replaced := map[string]bool{}
+	for _, nested := range override.Body.Blocks {
+		if mergedNestedBlocks[nested.Type] {
+			if existing := firstBlock(body, nested.Type); existing != nil {
+				mergeBlock(existing, nested)
+				continue
+			}
+			body.Blocks = append(body.Blocks, nested)
+			continue
+		}
+
+		if !replaced[nested.Type] {
+			replaced[nested.Type] = true
+
+			removed := []string{nested.Type}
+			if target.Type == "terraform" && (nested.Type == "backend" || nested.Type == "cloud") {
+				// A backend and a cloud block exclude each other
+				removed = []string{"backend", "cloud"}
+			}
+			body.Blocks = slices.DeleteFunc(body.Blocks, func(existing *hclsyntax.Block) bool {
+				return slices.Contains(removed, existing.Type)
+			})
+		}
+		body.Blocks = append(body.Blocks, nested)
+	}
+}
+
+func firstBlock(body *hclsyntax.Body, blockType string) *hclsyntax.Block {
+	for _, block := range body.Blocks {
+		if block.Type == blockType {
+			return block
+		}
+	}
+	return nil
+}
+
+// blankComments returns a copy of content with the comments replaced by spaces, line breaks kept
+func blankComments(content []byte) []byte {
+	blanked := slices.Clone(content)
+	tokens, _ := hclsyntax.LexConfig(content, "", hcl.InitialPos)
+	for _, token := range tokens {
+		if token.Type != hclsyntax.TokenComment {
+			continue
+		}
+		for i := token.Range.Start.Byte; i < token.Range.End.Byte; i++ {
+			if blanked[i] != '\n' && blanked[i] != '\r' {
+				blanked[i] = ' '
+			}
+		}
+	}
+	return blanked
+}
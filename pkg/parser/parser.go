@@ -1,96 +1,489 @@
+// Package parser reads the Terraform configuration files of a directory and collects
+// their blocks into a TerraformConfig.
 package parser
 
 import (
+	"bytes"
+	"cmp"
+	"context"
 	"errors"
 	"fmt"
 	"path/filepath"
+	"slices"
+	"strings"
+	"unicode/utf8"
 
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/events"
 	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/filesystem"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/jsonsyntax"
 	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/logger"
 	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser/schema"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/source"
 
 	"github.com/hashicorp/hcl/v2"
-	"github.com/hashicorp/hcl/v2/hclparse"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
+	hcljson "github.com/hashicorp/hcl/v2/json"
 )
 
+// Mode selects how much of each block the parser keeps
 type Mode int
 
 const (
+	// Simple keeps the interface of a module: variables, outputs and terraform settings
 	Simple Mode = iota
+	// Detail also keeps module calls, resources, data sources, providers, imports and locals
 	Detail
 )
 
+// Parser parses Terraform workspaces read from a filesystem.FileReader
 type Parser struct {
-	fs   filesystem.FileReader
-	hcl  *hclparse.Parser
-	mode Mode
+	fs      filesystem.FileReader
+	mode    Mode
+	withAST bool
+	// lazyThreshold is the expression size in bytes above which ASTs are deferred
+	lazyThreshold int
+	flatten       bool
+	withKinds     bool
+	withPositions bool
+	validate      bool
+	lenient       bool
+	ctx           context.Context
+	pruning       *source.Pruning
+	reporter      events.Reporter
 }
 
+// NewParser creates a parser reading from fs
 func NewParser(fs filesystem.FileReader, mode Mode) *Parser {
 	return &Parser{
 		fs:   fs,
-		hcl:  hclparse.NewParser(),
 		mode: mode,
 	}
 }
 
+// WithAST makes the parser attach the expression AST of every attribute to the parsed blocks
+func (p *Parser) WithAST(enabled bool) *Parser {
+	p.withAST = enabled
+	return p
+}
+
+// WithLazyThreshold defers the AST conversion of attribute expressions longer than bytes until
+// schema.Node.Resolve is called, keeping pathological files such as multi-thousand-line jsonencode
+// documents fast to summarize. 0, the default, converts every expression.
+func (p *Parser) WithLazyThreshold(bytes int) *Parser {
+	p.lazyThreshold = bytes
+	return p
+}
+
+// WithValidateBlocks makes the parser check the blocks against Terraform's block schemas and
+// report unknown arguments, missing required arguments, unexpected nested blocks and invalid label
+// counts as TerraformConfig.Diagnostics instead of failing. Blocks with invalid labels are skipped.
+func (p *Parser) WithValidateBlocks(enabled bool) *Parser {
+	p.validate = enabled
+	return p
+}
+
+// WithLenient makes the parser collect the problems of broken files as TerraformConfig.Diagnostics
+// instead of failing on the first one: files with syntax errors contribute the blocks that could be
+// read, override files that cannot be applied are ignored, and blocks or lock files that fail to
+// parse are skipped. Each diagnostic locates its problem by file, line and column.
+func (p *Parser) WithLenient(enabled bool) *Parser {
+	p.lenient = enabled
+	return p
+}
+
+// WithContext makes the parser stop with the cause of the cancellation of ctx, e.g. a timeout,
+// between files and between the directories of ParseTerraformWorkspaces
+func (p *Parser) WithContext(ctx context.Context) *Parser {
+	p.ctx = ctx
+	return p
+}
+
+// cancelled returns the cause of the cancellation of the context of the parser, if any
+func (p *Parser) cancelled() error {
+	if p.ctx == nil {
+		return nil
+	}
+	return context.Cause(p.ctx)
+}
 
-func (p *Parser) ParseTerraformWorkspace(dir string) (*TerraformConfig, error) {
+// WithPruning limits the directories of ParseTerraformWorkspaces and the module calls of
+// ResolveModuleTree to pruning's depth, and leaves out those matching its paths
+func (p *Parser) WithPruning(pruning *source.Pruning) *Parser {
+	p.pruning = pruning
+	return p
+}
+
+// WithReporter makes the parser report every configuration file it reads, the diagnostics of
+// every directory and the end of parsing each directory to reporter
+func (p *Parser) WithReporter(reporter events.Reporter) *Parser {
+	p.reporter = reporter
+	return p
+}
+
+// WithKinds makes the parser tag the attributes of every block with the kind of their value:
+// literal, reference, function, conditional or complex
+func (p *Parser) WithKinds(enabled bool) *Parser {
+	p.withKinds = enabled
+	return p
+}
+
+// WithPositions makes the parser record where every block is declared: the file and the first
+// and last lines, e.g. for linters annotating pull requests. Each local value gets the lines of
+// its attribute. Lines of .tf.json files are those of the JSON members.
+func (p *Parser) WithPositions(enabled bool) *Parser {
+	p.withPositions = enabled
+	return p
+}
+
+// WithFlatten makes the parser add a flattened form of nested values, mapping dotted key paths
+// such as tags.Name to leaf values: flat_default of variables with an object or list default,
+// and flat_config of backends. The nested values are kept.
+func (p *Parser) WithFlatten(enabled bool) *Parser {
+	p.flatten = enabled
+	return p
+}
+
+// ParseTerraformWorkspace parses the configuration files in dir
+func (p *Parser) ParseTerraformWorkspace(dir string) (tfConfig *TerraformConfig, err error) {
 	logger.InfoKV("Starting terraform workspace parsing", "directory", dir)
+	defer func() { p.reportWorkspace(dir, tfConfig, err) }()
+
+	files, diagnostics, err := p.loadWorkspaceFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	tfConfig, err = p.buildConfig(files, diagnostics)
+	if err != nil {
+		logger.ErrorKV("Failed to parse terraform blocks", "directory", dir, "mode", p.getModeString(), "error", err)
+		return nil, err
+	}
+
+	if tfConfig.LockedProviders, err = p.loadLockFile(dir); err != nil {
+		logger.ErrorKV("Failed to load dependency lock file", "directory", dir, "error", err)
+		if !p.lenient {
+			return nil, fmt.Errorf("failed to load dependency lock file: %w", err)
+		}
+		tfConfig.Diagnostics = append(tfConfig.Diagnostics, errorDiagnostics(filepath.Join(dir, schema.LockFileName), "Invalid dependency lock file", err)...)
+	}
+	applyLockedVersions(tfConfig)
+
+	logger.InfoKV("Successfully parsed terraform workspace",
+		"directory", dir,
+		"variables", len(tfConfig.Variables),
+		"outputs", len(tfConfig.Outputs),
+		"terraform_blocks", len(tfConfig.Terraform),
+		"modules", len(tfConfig.Modules),
+		"resources", len(tfConfig.Resources),
+		"data_sources", len(tfConfig.DataSources),
+		"ephemeral_resources", len(tfConfig.EphemeralResources),
+		"providers", len(tfConfig.Providers),
+		"imports", len(tfConfig.Imports),
+		"locals", len(tfConfig.Locals),
+		"fingerprint", tfConfig.Fingerprint)
+
+	return tfConfig, nil
+}
+
+// reportWorkspace reports the diagnostics of a parsed directory and the end of parsing it
+func (p *Parser) reportWorkspace(dir string, tfConfig *TerraformConfig, err error) {
+	if p.reporter == nil {
+		return
+	}
+	if err != nil {
+		p.reporter.Report(events.Event{Kind: events.ModuleDone, Directory: dir, Error: err.Error()})
+		return
+	}
+
+	for _, diagnostic := range tfConfig.Diagnostics {
+		event := events.Event{
+			Kind:      events.Warning,
+			Directory: dir,
+			Severity:  diagnostic.Severity,
+			Summary:   diagnostic.Summary,
+			Detail:    diagnostic.Detail,
+		}
+		if diagnostic.Location != nil {
+			event.File, event.Line = diagnostic.Location.File, diagnostic.Location.Line
+		}
+		p.reporter.Report(event)
+	}
+	p.reporter.Report(events.Event{
+		Kind:      events.ModuleDone,
+		Directory: dir,
+		Counts: map[string]int{
+			"variables":           len(tfConfig.Variables),
+			"outputs":             len(tfConfig.Outputs),
+			"terraform_blocks":    len(tfConfig.Terraform),
+			"modules":             len(tfConfig.Modules),
+			"resources":           len(tfConfig.Resources),
+			"data_sources":        len(tfConfig.DataSources),
+			"ephemeral_resources": len(tfConfig.EphemeralResources),
+			"providers":           len(tfConfig.Providers),
+			"imports":             len(tfConfig.Imports),
+			"locals":              len(tfConfig.Locals),
+		},
+	})
+}
+
+// ParseHCLBytes parses a single configuration file whose content is already in memory, such as
+// an upload or an editor buffer, without going through the FileReader. filename names the file
+// in errors and ranges; a .tf.json suffix selects the JSON syntax.
+func (p *Parser) ParseHCLBytes(filename string, src []byte) (*TerraformConfig, error) {
+	if diagnostic := binaryDiagnostic(filename, src); diagnostic != nil {
+		return p.buildConfig(nil, []*Diagnostic{diagnostic})
+	}
+
+	file, err := p.parseHcl(src, filename)
+	if err != nil {
+		if !p.lenient {
+			return nil, err
+		}
+		return p.buildConfig(nil, errorDiagnostics(filename, "Invalid configuration file", err))
+	}
+
+	return p.buildConfig([]*workspaceFile{{name: filepath.Base(filename), path: filename, file: file}}, nil)
+}
+
+// buildConfig collects the blocks of the parsed files into a configuration, after the diagnostics
+// of loading them
+func (p *Parser) buildConfig(files []*workspaceFile, diagnostics []*Diagnostic) (*TerraformConfig, error) {
+	aggBlocks := []schema.Block{}
+	canonical := []string{}
+	diagnostics = slices.Clone(diagnostics)
+
+	for _, wf := range files {
+		canonical = append(canonical, canonicalBlocks(wf.file)...)
+		aggBlocks = append(aggBlocks, schema.ParseAnnotationComments(wf.file))
+
+		var fileDiagnostics []*Diagnostic
+		var flagged map[*hclsyntax.Block]bool
+		if p.validate {
+			fileDiagnostics, flagged = validateBlocks(wf.file)
+		}
+
+		blocks, parseDiagnostics, err := p.parseBlocks(wf, flagged)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse terraform blocks in %s: %w", wf.name, err)
+		}
+
+		fileDiagnostics = append(fileDiagnostics, parseDiagnostics...)
+		slices.SortStableFunc(fileDiagnostics, func(a, b *Diagnostic) int {
+			return cmp.Or(a.Location.Line-b.Location.Line, a.Location.Column-b.Location.Column)
+		})
+		diagnostics = append(diagnostics, fileDiagnostics...)
+
+		logger.DebugKV("Successfully parsed blocks", "file", wf.name, "block_count", len(blocks), "mode", p.getModeString())
+		aggBlocks = append(aggBlocks, blocks...)
+	}
+
+	tfConfig := generateTerraformConfig(aggBlocks)
+	tfConfig.Fingerprint = fingerprint(canonical)
+	if len(diagnostics) > 0 {
+		tfConfig.Diagnostics = diagnostics
+	}
+	return tfConfig, nil
+}
+
+// Files parses the configuration files in dir without interpreting their blocks, for analyses
+// of the raw syntax. The ranges of each file carry its path.
+func (p *Parser) Files(dir string) ([]*hcl.File, error) {
+	files, _, err := p.loadWorkspaceFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	hclFiles := make([]*hcl.File, 0, len(files))
+	for _, wf := range files {
+		hclFiles = append(hclFiles, wf.file)
+	}
+	return hclFiles, nil
+}
+
+// workspaceFile is a parsed configuration file of a workspace
+type workspaceFile struct {
+	name string
+	path string
+	file *hcl.File
+}
 
+// loadWorkspaceFiles parses every .tf and .tf.json file directly inside dir, with the override
+// files merged into the others. In lenient mode, the problems of broken files are returned as
+// diagnostics: files with syntax errors are parsed as far as possible and broken override files
+// are ignored.
+func (p *Parser) loadWorkspaceFiles(dir string) ([]*workspaceFile, []*Diagnostic, error) {
 	exist, err := p.fs.DirExists(dir)
 	if err != nil {
 		logger.ErrorKV("Failed to check terraform workspace directory", "directory", dir, "error", err)
-		return nil, fmt.Errorf("failed to check terraform workspace directory: %w", err)
+		return nil, nil, fmt.Errorf("failed to check terraform workspace directory: %w", err)
 	}
 	if !exist {
 		logger.ErrorKV("Terraform workspace directory not found", "directory", dir)
-		return nil, fmt.Errorf("terraform workspace directory not found: %s", dir)
+		return nil, nil, fmt.Errorf("terraform workspace directory not found: %s", dir)
 	}
 
 	dirFiles, err := p.fs.ReadDir(dir)
 	if err != nil {
 		logger.ErrorKV("Failed to read terraform workspace directory", "directory", dir, "error", err)
-		return nil, fmt.Errorf("failed to read terraform workspace directory %s: %w", dir, err)
+		return nil, nil, fmt.Errorf("failed to read terraform workspace directory %s: %w", dir, err)
 	}
 
 	logger.DebugKV("Found files in directory", "directory", dir, "file_count", len(dirFiles))
 
-	aggBlocks := []schema.Block{}
-
+	diagnostics := []*Diagnostic{}
+	primary, overrides := []*sourceFile{}, []*sourceFile{}
 	for _, dirFile := range dirFiles {
-		if dirFile.IsDir() || filepath.Ext(dirFile.Name()) != ".tf" {
+		if err := p.cancelled(); err != nil {
+			return nil, nil, err
+		}
+		if dirFile.IsDir() || !source.IsConfigFile(dirFile.Name()) {
 			logger.DebugKV("Skipping non-terraform file", "file", dirFile.Name())
 			continue
 		}
 
 		logger.DebugKV("Processing terraform file", "file", dirFile.Name())
 
-		hclFile, err := p.loadHcl(filepath.Join(dir, dirFile.Name()))
+		path := filepath.Join(dir, dirFile.Name())
+		content, err := p.fs.ReadFile(path)
 		if err != nil {
-			logger.ErrorKV("Failed to load terraform file", "directory", dir, "file", dirFile.Name(), "error", err)
-			return nil, fmt.Errorf("failed to load terraform file %s: %w", dirFile.Name(), err)
+			return nil, nil, fmt.Errorf("failed to read terraform file %s: %w", path, err)
 		}
+		if diagnostic := binaryDiagnostic(path, content); diagnostic != nil {
+			logger.InfoKV("Skipping binary terraform file", "directory", dir, "file", dirFile.Name(), "reason", diagnostic.Detail)
+			diagnostics = append(diagnostics, diagnostic)
+			continue
+		}
+		if strings.HasSuffix(path, ".tf.json") {
+			if content, err = jsonToNative(content, path); err != nil {
+				logger.ErrorKV("Failed to load terraform file", "directory", dir, "file", dirFile.Name(), "error", err)
+				if !p.lenient {
+					return nil, nil, fmt.Errorf("failed to load terraform file %s: %w", dirFile.Name(), err)
+				}
+				diagnostics = append(diagnostics, errorDiagnostics(path, "Invalid configuration file", err)...)
+				continue
+			}
+		}
+
+		sf := &sourceFile{name: dirFile.Name(), path: path, content: content}
+		if isOverrideFile(dirFile.Name()) {
+			overrides = append(overrides, sf)
+		} else {
+			primary = append(primary, sf)
+		}
+	}
 
-		blocks, err := p.parseBlocks(hclFile)
+	// Broken files are left out of the overrides; broken primary files are still read in part
+	broken := map[string]bool{}
+	if p.lenient {
+		for _, sf := range slices.Concat(primary, overrides) {
+			if _, diags := hclsyntax.ParseConfig(sf.content, sf.path, hcl.InitialPos); diags.HasErrors() {
+				broken[sf.path] = true
+				diagnostics = append(diagnostics, hclDiagnostics(diags)...)
+			}
+		}
+	}
+
+	parse := func() ([]*workspaceFile, error) {
+		files := []*workspaceFile{}
+		for _, sf := range primary {
+			hclFile, diags := hclsyntax.ParseConfig(sf.content, sf.path, hcl.InitialPos)
+			if hclFile == nil || hclFile.Body == nil || (diags.HasErrors() && !p.lenient) {
+				err := fmt.Errorf("failed to parse HCL syntax in %s: %w", sf.path, errors.Join(diags.Errs()...))
+				logger.ErrorKV("Failed to load terraform file", "directory", dir, "file", sf.name, "error", err)
+				if p.lenient {
+					continue
+				}
+				return nil, fmt.Errorf("failed to load terraform file %s: %w", sf.name, err)
+			}
+			files = append(files, &workspaceFile{name: sf.name, path: sf.path, file: hclFile})
+		}
+		return files, nil
+	}
+
+	files, err := parse()
+	if err != nil {
+		return nil, nil, err
+	}
+	applied := slices.DeleteFunc(slices.Clone(overrides), func(sf *sourceFile) bool { return broken[sf.path] })
+	merged := slices.DeleteFunc(slices.Clone(files), func(wf *workspaceFile) bool { return broken[wf.path] })
+	if err := applyOverrides(merged, applied); err != nil {
+		if !p.lenient {
+			return nil, nil, err
+		}
+		diagnostics = append(diagnostics, errorDiagnostics(dir, "Invalid override file", err)...)
+		applied = nil
+		// Parse again to drop the overrides merged before the error
+		if files, err = parse(); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	for _, wf := range files {
+		p.reporter.Report(events.Event{Kind: events.FileParsed, Directory: dir, File: wf.path})
+	}
+	for _, sf := range applied {
+		p.reporter.Report(events.Event{Kind: events.FileParsed, Directory: dir, File: sf.path})
+	}
+
+	return files, diagnostics, nil
+}
+
+// ParseTerraformWorkspaces parses every directory under root, root included, that contains
+// configuration files. The result is keyed by the slash-separated path relative to root, "." for root itself.
+func (p *Parser) ParseTerraformWorkspaces(root string) (map[string]*TerraformConfig, error) {
+	dirs, err := source.PrunedConfigDirs(p.fs, root, p.pruning)
+	if err != nil {
+		return nil, err
+	}
+
+	workspaces := map[string]*TerraformConfig{}
+	for _, dir := range dirs {
+		if err := p.cancelled(); err != nil {
+			return nil, err
+		}
+		rel, err := filepath.Rel(root, dir)
 		if err != nil {
-			logger.ErrorKV("Failed to parse terraform blocks", "directory", dir, "file", dirFile.Name(), "mode", p.getModeString(), "error", err)
-			return nil, fmt.Errorf("failed to parse terraform blocks in %s: %w", dirFile.Name(), err)
+			return nil, err
 		}
 
-		logger.DebugKV("Successfully parsed blocks", "directory", dir, "file", dirFile.Name(), "block_count", len(blocks), "mode", p.getModeString())
-		aggBlocks = append(aggBlocks, blocks...)
+		tfconfig, err := p.ParseTerraformWorkspace(dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", filepath.ToSlash(rel), err)
+		}
+		workspaces[filepath.ToSlash(rel)] = tfconfig
 	}
 
-	tfConfig := generateTerraformConfig(aggBlocks)
-	logger.InfoKV("Successfully parsed terraform workspace",
-		"directory", dir,
-		"variables", len(tfConfig.Variables),
-		"outputs", len(tfConfig.Outputs),
-		"terraform_blocks", len(tfConfig.Terraform))
+	return workspaces, nil
+}
 
-	return tfConfig, nil
+// ParseLocalModules parses the child modules of tfconfig whose sources are local paths.
+// The result is keyed by module call name; calls whose directory does not exist are skipped.
+func (p *Parser) ParseLocalModules(dir string, tfconfig *TerraformConfig) (map[string]*TerraformConfig, error) {
+	children := map[string]*TerraformConfig{}
+
+	for _, module := range tfconfig.Modules {
+		if source.ParseModuleSource(module.Source).Kind != source.ModuleSourceLocal {
+			continue
+		}
+
+		childDir := filepath.Join(dir, module.Source)
+		exist, err := p.fs.DirExists(childDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check module %s directory: %w", module.Name, err)
+		}
+		if !exist {
+			logger.DebugKV("Skipping unresolved local module", "module", module.Name, "directory", childDir)
+			continue
+		}
+
+		child, err := p.ParseTerraformWorkspace(childDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse module %s: %w", module.Name, err)
+		}
+		children[module.Name] = child
+	}
+
+	return children, nil
 }
 
 func (p *Parser) loadHcl(filename string) (*hcl.File, error) {
@@ -99,7 +492,19 @@ func (p *Parser) loadHcl(filename string) (*hcl.File, error) {
 		return nil, fmt.Errorf("failed to read terraform file %s: %w", filename, err)
 	}
 
-	file, diags := p.hcl.ParseHCL(content, filename)
+	return p.parseHcl(content, filename)
+}
+
+// parseHcl parses content in the syntax its file name selects. Native syntax content is not copied.
+func (p *Parser) parseHcl(content []byte, filename string) (*hcl.File, error) {
+	if strings.HasSuffix(filename, ".tf.json") {
+		var err error
+		if content, err = jsonToNative(content, filename); err != nil {
+			return nil, err
+		}
+	}
+
+	file, diags := hclsyntax.ParseConfig(content, filename, hcl.InitialPos)
 	if file == nil || file.Body == nil || diags.HasErrors() {
 		return nil, fmt.Errorf("failed to parse HCL syntax in %s: %w", filename, errors.Join(diags.Errs()...))
 	}
@@ -107,40 +512,84 @@ func (p *Parser) loadHcl(filename string) (*hcl.File, error) {
 	return file, nil
 }
 
-func (p *Parser) parseBlocks(file *hcl.File) ([]schema.Block, error) {
+// jsonToNative translates a JSON syntax file into the equivalent native syntax, so that blocks are
+// read the same way whatever the syntax. The translation keeps the lines of the JSON members, so
+// that line numbers point into the JSON file; columns refer to the translation.
+func jsonToNative(content []byte, filename string) ([]byte, error) {
+	if _, diags := hcljson.Parse(content, filename); diags.HasErrors() {
+		return nil, fmt.Errorf("failed to parse JSON syntax in %s: %w", filename, errors.Join(diags.Errs()...))
+	}
+
+	return jsonsyntax.ToAlignedHCL(content, filename)
+}
+
+// parseBlocks parses the top-level blocks of a file. flagged are the blocks with validation
+// diagnostics; those mapped to true have invalid labels and are skipped. With block validation,
+// other blocks that fail to parse are skipped too, with a diagnostic unless already flagged.
+func (p *Parser) parseBlocks(wf *workspaceFile, flagged map[*hclsyntax.Block]bool) ([]schema.Block, []*Diagnostic, error) {
+	file := wf.file
 	rootBody := file.Body.(*hclsyntax.Body)
+	diagnostics := []*Diagnostic{}
+	comments := schema.LeadingComments(file)
 
 	blocks := []schema.Block{}
 	for _, block := range rootBody.Blocks {
 		var parsedBlock schema.Block = nil
+		if flagged[block] {
+			continue
+		}
 
-		switch block.Type {
-		case "variable":
-			parsedBlock = &schema.Variable{}
-		case "output":
-			parsedBlock = &schema.Output{}
-		case "terraform":
-			parsedBlock = &schema.Terraform{}
+		spec, ok := schema.Lookup(block.Type)
+		if !ok || spec.New == nil || (spec.Detail && p.mode != Detail) {
+			continue
+		}
 
-		case "resource", "data", "module", "provider", "locals":
-			if p.mode != Detail {
+		err := spec.CheckLabels(block)
+		if err == nil {
+			parsedBlock = spec.New()
+			err = parsedBlock.Parse(file, block)
+		}
+		if err != nil {
+			if _, reported := flagged[block]; p.validate && reported {
 				continue
 			}
-			// TODO: Implement parsing for these block types when needed
-			continue
+			if p.validate || p.lenient {
+				start := block.TypeRange.Start
+				diagnostics = append(diagnostics, &Diagnostic{
+					Severity: "error",
+					Summary:  "Invalid " + block.Type + " block",
+					Detail:   err.Error(),
+					Location: &Location{File: block.TypeRange.Filename, Line: start.Line, Column: start.Column},
+				})
+				continue
+			}
+			return nil, nil, fmt.Errorf("failed to parse %s block: %w", block.Type, err)
+		}
 
-		default:
-			continue
+		if carrier, ok := parsedBlock.(schema.ASTCarrier); ok && p.withAST {
+			carrier.SetAST(schema.NewLazyBodyAST(block.Body, p.lazyThreshold))
 		}
 
-		if err := parsedBlock.Parse(file, block); err != nil {
-			return nil, fmt.Errorf("failed to parse %s block: %w", block.Type, err)
+		if carrier, ok := parsedBlock.(schema.KindCarrier); ok && p.withKinds {
+			carrier.SetKinds(schema.NewKinds(block.Body))
+		}
+
+		if carrier, ok := parsedBlock.(schema.CommentCarrier); ok {
+			carrier.SetComment(comments[block.TypeRange.Start.Line])
+		}
+
+		if carrier, ok := parsedBlock.(schema.PositionCarrier); ok && p.withPositions {
+			carrier.SetPosition(block)
+		}
+
+		if flattener, ok := parsedBlock.(schema.Flattener); ok && p.flatten {
+			flattener.Flatten(file, block)
 		}
 
 		blocks = append(blocks, parsedBlock)
 	}
 
-	return blocks, nil
+	return blocks, diagnostics, nil
 }
 
 func (p *Parser) getModeString() string {
@@ -153,3 +602,71 @@ func (p *Parser) getModeString() string {
 		return "Unknown"
 	}
 }
+
+// hclDiagnostics converts HCL diagnostics into diagnostics located at their subject
+func hclDiagnostics(diags hcl.Diagnostics) []*Diagnostic {
+	diagnostics := []*Diagnostic{}
+	for _, diag := range diags {
+		severity := "error"
+		if diag.Severity == hcl.DiagWarning {
+			severity = "warning"
+		}
+		diagnostic := &Diagnostic{Severity: severity, Summary: diag.Summary, Detail: diag.Detail, Location: &Location{}}
+		if diag.Subject != nil {
+			diagnostic.Location = &Location{File: diag.Subject.Filename, Line: diag.Subject.Start.Line, Column: diag.Subject.Start.Column}
+		}
+		diagnostics = append(diagnostics, diagnostic)
+	}
+	return diagnostics
+}
+
+// binaryDiagnostic returns a warning when content is not text, such as vendored binaries or
+// corrupted files with a .tf extension, which are skipped rather than reported as syntax errors:
+// content with NUL bytes or invalid UTF-8. It returns nil for text.
+func binaryDiagnostic(path string, content []byte) *Diagnostic {
+	detail := ""
+	offset := bytes.IndexByte(content, 0)
+	if offset >= 0 {
+		detail = "The file contains NUL bytes and does not look like a text file."
+	} else {
+		for i := 0; i < len(content); {
+			r, size := utf8.DecodeRune(content[i:])
+			if r == utf8.RuneError && size == 1 {
+				offset, detail = i, "The file is not valid UTF-8, the encoding of Terraform configuration files."
+				break
+			}
+			i += size
+		}
+	}
+	if offset < 0 {
+		return nil
+	}
+
+	line := bytes.Count(content[:offset], []byte("\n")) + 1
+	return &Diagnostic{Severity: "warning", Summary: "Binary configuration file skipped", Detail: detail, Location: &Location{File: path, Line: line}}
+}
+
+// errorDiagnostics converts an error about the file at path into diagnostics: the HCL diagnostics
+// it wraps or, lacking any, a single diagnostic with summary located at the file
+func errorDiagnostics(path, summary string, err error) []*Diagnostic {
+	var diags hcl.Diagnostics
+	var collect func(err error)
+	collect = func(err error) {
+		switch e := err.(type) {
+		case *hcl.Diagnostic:
+			diags = append(diags, e)
+		case interface{ Unwrap() []error }:
+			for _, wrapped := range e.Unwrap() {
+				collect(wrapped)
+			}
+		case interface{ Unwrap() error }:
+			collect(e.Unwrap())
+		}
+	}
+	collect(err)
+
+	if len(diags) > 0 {
+		return hclDiagnostics(diags)
+	}
+	return []*Diagnostic{{Severity: "error", Summary: summary, Detail: err.Error(), Location: &Location{File: path}}}
+}
@@ -0,0 +1,187 @@
+package parser
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// PassThrough is a value passed unchanged between modules: a variable given as is to a child
+// module input, or a child module output re-exported as is
+type PassThrough struct {
+	// From and To are absolute addresses, e.g. var.region and module.vpc.var.region
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// InterfaceMap is the end-to-end interface of a module tree
+type InterfaceMap struct {
+	// Edges are the pass-throughs found at every level of the tree
+	Edges []*PassThrough `json:"edges"`
+	// Inputs maps the variables of the root module to every child module variable they reach
+	Inputs map[string][]string `json:"inputs"`
+	// Outputs maps the outputs of the root module to the child module output they originate from
+	Outputs map[string]string `json:"outputs"`
+}
+
+// TracePassThrough finds the pass-throughs of the root module in dir and of every resolved
+// module of tree, and flattens them into the root module's end-to-end interface
+func (p *Parser) TracePassThrough(dir string, tree []*ResolvedModule) (*InterfaceMap, error) {
+	m := &InterfaceMap{Edges: []*PassThrough{}, Inputs: map[string][]string{}, Outputs: map[string]string{}}
+
+	if err := p.tracePassThrough(m, dir, ""); err != nil {
+		return nil, err
+	}
+
+	var walk func(modules []*ResolvedModule) error
+	walk = func(modules []*ResolvedModule) error {
+		for _, module := range modules {
+			if module.Dir == "" || module.Skipped != "" {
+				continue
+			}
+			if err := p.tracePassThrough(m, module.Dir, moduleAddressPrefix(module.Key)); err != nil {
+				return err
+			}
+			if err := walk(module.Children); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(tree); err != nil {
+		return nil, err
+	}
+
+	next := map[string][]string{}
+	prev := map[string]string{}
+	for _, edge := range m.Edges {
+		next[edge.From] = append(next[edge.From], edge.To)
+		prev[edge.To] = edge.From
+	}
+
+	for _, edge := range m.Edges {
+		if strings.HasPrefix(edge.From, "var.") && m.Inputs[edge.From] == nil {
+			m.Inputs[edge.From] = reachable(edge.From, next)
+		}
+		if strings.HasPrefix(edge.To, "output.") {
+			origin := edge.From
+			for seen := map[string]bool{}; prev[origin] != "" && !seen[origin]; origin = prev[origin] {
+				seen[origin] = true
+			}
+			m.Outputs[edge.To] = origin
+		}
+	}
+
+	return m, nil
+}
+
+var moduleMetaArguments = []string{"source", "version", "count", "for_each", "providers", "depends_on"}
+
+// tracePassThrough adds the pass-throughs of the module in dir, whose addresses start with prefix
+func (p *Parser) tracePassThrough(m *InterfaceMap, dir, prefix string) error {
+	files, _, err := p.loadWorkspaceFiles(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, wf := range files {
+		for _, block := range wf.file.Body.(*hclsyntax.Body).Blocks {
+			switch {
+			case block.Type == "module" && len(block.Labels) == 1:
+				for _, name := range sortedAttributeNames(block.Body.Attributes) {
+					if slices.Contains(moduleMetaArguments, name) {
+						continue
+					}
+					if variable := directReference(block.Body.Attributes[name].Expr, "var"); variable != "" {
+						m.Edges = append(m.Edges, &PassThrough{
+							From: prefix + variable,
+							To:   prefix + "module." + block.Labels[0] + ".var." + name,
+						})
+					}
+				}
+			case block.Type == "output" && len(block.Labels) == 1:
+				if value, ok := block.Body.Attributes["value"]; ok {
+					if output := directReference(value.Expr, "module"); output != "" {
+						parts := strings.SplitN(output, ".", 3)
+						m.Edges = append(m.Edges, &PassThrough{
+							From: prefix + "module." + parts[1] + ".output." + parts[2],
+							To:   prefix + "output." + block.Labels[0],
+						})
+					}
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// directReference returns the traversal of expr when expr is nothing but a reference of the form
+// var.x (root "var") or module.x.y (root "module")
+func directReference(expr hclsyntax.Expression, root string) string {
+	if wrap, ok := expr.(*hclsyntax.TemplateWrapExpr); ok {
+		expr = wrap.Wrapped
+	}
+	scope, ok := expr.(*hclsyntax.ScopeTraversalExpr)
+	if !ok || scope.Traversal.RootName() != root {
+		return ""
+	}
+
+	want := 2
+	if root == "module" {
+		want = 3
+	}
+	if len(scope.Traversal) != want {
+		return ""
+	}
+
+	names := []string{root}
+	for _, step := range scope.Traversal[1:] {
+		attr, ok := step.(hcl.TraverseAttr)
+		if !ok {
+			return ""
+		}
+		names = append(names, attr.Name)
+	}
+	return strings.Join(names, ".")
+}
+
+// moduleAddressPrefix turns a module key such as vpc.subnets into module.vpc.module.subnets.
+func moduleAddressPrefix(key string) string {
+	prefix := ""
+	for _, name := range strings.Split(key, ".") {
+		prefix += "module." + name + "."
+	}
+	return prefix
+}
+
+func reachable(from string, next map[string][]string) []string {
+	found := []string{}
+	seen := map[string]bool{from: true}
+	queue := []string{from}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, to := range next[current] {
+			if seen[to] {
+				continue
+			}
+			seen[to] = true
+			found = append(found, to)
+			queue = append(queue, to)
+		}
+	}
+	slices.Sort(found)
+	return found
+}
+
+func sortedAttributeNames(attrs hclsyntax.Attributes) []string {
+	names := make([]string, 0, len(attrs))
+	for name := range attrs {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
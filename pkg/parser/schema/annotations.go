@@ -0,0 +1,52 @@
+package schema
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// Annotations holds author-declared metadata of a module such as owner, tier, lifecycle or links.
+// It is read from tfparser (or metadata) blocks and from structured comments.
+type Annotations struct {
+	Values map[string]string
+}
+
+// annotationCommentRegex matches comments like "# tfparser:owner = platform-team"
+var annotationCommentRegex = regexp.MustCompile(`^(?:#|//)\s*tfparser:([A-Za-z0-9_.-]+)\s*=\s*(.*?)\s*$`)
+
+func (b *Annotations) Parse(file *hcl.File, block *hclsyntax.Block) error {
+	b.Values = map[string]string{}
+	for name, attr := range block.Body.Attributes {
+		if _, ok := attr.Expr.(*hclsyntax.TupleConsExpr); ok {
+			b.Values[name] = strings.Join(parseAttributeToStringList(file, attr), ", ")
+			continue
+		}
+		b.Values[name] = parseAttributeToString(file, attr)
+	}
+
+	return nil
+}
+
+// ParseAnnotationComments collects "tfparser:<key> = <value>" line comments of a file.
+// Values may be quoted.
+func ParseAnnotationComments(file *hcl.File) *Annotations {
+	b := &Annotations{Values: map[string]string{}}
+
+	tokens, _ := hclsyntax.LexConfig(file.Bytes, "", hcl.InitialPos)
+	for _, token := range tokens {
+		if token.Type != hclsyntax.TokenComment {
+			continue
+		}
+
+		match := annotationCommentRegex.FindStringSubmatch(strings.TrimSpace(string(token.Bytes)))
+		if match == nil {
+			continue
+		}
+		b.Values[match[1]] = strings.Trim(match[2], `"`)
+	}
+
+	return b
+}
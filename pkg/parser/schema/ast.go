@@ -0,0 +1,342 @@
+package schema
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Node kinds of an expression AST
+const (
+	NodeLiteral           = "literal"
+	NodeTemplate          = "template"
+	NodeTemplateWrap      = "template_wrap"
+	NodeTraversal         = "traversal"
+	NodeRelativeTraversal = "relative_traversal"
+	NodeFunctionCall      = "function_call"
+	NodeTuple             = "tuple"
+	NodeObject            = "object"
+	NodeConditional       = "conditional"
+	NodeBinaryOp          = "binary_op"
+	NodeUnaryOp           = "unary_op"
+	NodeIndex             = "index"
+	NodeSplat             = "splat"
+	NodeSplatItem         = "splat_item"
+	NodeFor               = "for"
+	NodeParentheses       = "parentheses"
+	NodeUnknown           = "unknown"
+	// NodeDeferred is an expression too large to convert up front; see Node.Resolve
+	NodeDeferred = "deferred"
+)
+
+// Node is one expression of an attribute. Only the fields of its kind are set.
+type Node struct {
+	Kind string `json:"kind"`
+	// Value is the value of a literal
+	Value interface{} `json:"value,omitempty"`
+	// Traversal is the steps of a (relative) traversal, e.g. ["var", "list", "[0]"]
+	Traversal []string `json:"traversal,omitempty"`
+	// Function is the name of a called function
+	Function string `json:"function,omitempty"`
+	// ExpandFinal is set when the last function argument is expanded with ...
+	ExpandFinal bool `json:"expand_final,omitempty"`
+	// Operator is the operator of a binary or unary operation, e.g. "==" or "!"
+	Operator string  `json:"operator,omitempty"`
+	Args     []*Node `json:"args,omitempty"`
+	// Parts are the literal and interpolated parts of a template
+	Parts []*Node       `json:"parts,omitempty"`
+	Items []*Node       `json:"items,omitempty"`
+	Attrs []*ObjectItem `json:"attrs,omitempty"`
+	// Source is the expression a relative traversal, index or splat applies to,
+	// or the expression wrapped in parentheses or an interpolation
+	Source    *Node `json:"source,omitempty"`
+	Condition *Node `json:"condition,omitempty"`
+	True      *Node `json:"true,omitempty"`
+	False     *Node `json:"false,omitempty"`
+	LHS       *Node `json:"lhs,omitempty"`
+	RHS       *Node `json:"rhs,omitempty"`
+	Operand   *Node `json:"operand,omitempty"`
+	Key       *Node `json:"key,omitempty"`
+	Each      *Node `json:"each,omitempty"`
+	// Collection, KeyVar, ValueVar, Key, ValueExpr, Condition and Group describe a for expression
+	Collection *Node  `json:"collection,omitempty"`
+	KeyVar     string `json:"key_var,omitempty"`
+	ValueVar   string `json:"value_var,omitempty"`
+	ValueExpr  *Node  `json:"value_expr,omitempty"`
+	Group      bool   `json:"group,omitempty"`
+	// Range and Size locate a deferred expression and give its source length in bytes
+	Range *SourceRange `json:"range,omitempty"`
+	Size  int          `json:"size,omitempty"`
+
+	lazy *lazyExpression
+}
+
+// SourceRange is the span of an expression in its file
+type SourceRange struct {
+	File      string `json:"file"`
+	StartLine int    `json:"start_line"`
+	EndLine   int    `json:"end_line"`
+}
+
+// lazyExpression converts the expression of a deferred node once, on first use
+type lazyExpression struct {
+	once sync.Once
+	expr hclsyntax.Expression
+	node *Node
+}
+
+// Resolve returns the AST of a deferred node, converting its expression on first use.
+// Other nodes are returned as is.
+func (n *Node) Resolve() *Node {
+	if n == nil || n.lazy == nil {
+		return n
+	}
+
+	n.lazy.once.Do(func() {
+		n.lazy.node = NewNode(n.lazy.expr)
+	})
+	return n.lazy.node
+}
+
+// ObjectItem is a key/value pair of an object constructor
+type ObjectItem struct {
+	Key   *Node `json:"key"`
+	Value *Node `json:"value"`
+}
+
+// BodyAST holds the expression ASTs of a block body
+type BodyAST struct {
+	Attributes map[string]*Node `json:"attributes,omitempty"`
+	Blocks     []*BlockAST      `json:"blocks,omitempty"`
+}
+
+// BlockAST is a nested block inside a body, e.g. lifecycle or a dynamic block
+type BlockAST struct {
+	Type   string   `json:"type"`
+	Labels []string `json:"labels,omitempty"`
+	BodyAST
+}
+
+// Syntax is embedded by blocks that can carry the AST of their body (see parser WithAST), the
+// value kinds of their attributes, their position and the comment documenting them
+type Syntax struct {
+	// Comment is the text of the comment right above the block, without comment markers
+	Comment string   `json:"comment,omitempty"`
+	AST     *BodyAST `json:"ast,omitempty"`
+	// Kinds maps the attributes of the block to their value kind (see parser WithKinds)
+	Kinds map[string]ValueKind `json:"kinds,omitempty"`
+	// SourceRange is the position of the block, set when the parser runs WithPositions
+	*SourceRange
+}
+
+// SetAST attaches the AST of the block body
+func (s *Syntax) SetAST(ast *BodyAST) {
+	s.AST = ast
+}
+
+// ASTCarrier is implemented by blocks that can carry the AST of their body
+type ASTCarrier interface {
+	SetAST(ast *BodyAST)
+}
+
+// SetAST gives each local value the AST of its own expression
+func (b *Locals) SetAST(ast *BodyAST) {
+	for _, local := range b.Values {
+		local.AST = ast.Attributes[local.Name]
+	}
+}
+
+// NewBodyAST converts the attributes and nested blocks of body into ASTs
+func NewBodyAST(body *hclsyntax.Body) *BodyAST {
+	return NewLazyBodyAST(body, 0)
+}
+
+// NewLazyBodyAST converts body like NewBodyAST, except for attribute expressions whose source is
+// longer than threshold bytes, e.g. large jsonencode documents: these become deferred nodes
+// converted by Node.Resolve. A threshold of 0 converts every expression.
+func NewLazyBodyAST(body *hclsyntax.Body, threshold int) *BodyAST {
+	ast := &BodyAST{}
+
+	if len(body.Attributes) > 0 {
+		ast.Attributes = make(map[string]*Node, len(body.Attributes))
+		for name, attr := range body.Attributes {
+			ast.Attributes[name] = newAttributeNode(attr.Expr, threshold)
+		}
+	}
+
+	for _, block := range body.Blocks {
+		ast.Blocks = append(ast.Blocks, &BlockAST{
+			Type:    block.Type,
+			Labels:  block.Labels,
+			BodyAST: *NewLazyBodyAST(block.Body, threshold),
+		})
+	}
+
+	return ast
+}
+
+func newAttributeNode(expr hclsyntax.Expression, threshold int) *Node {
+	rng := expr.Range()
+	size := rng.End.Byte - rng.Start.Byte
+	if threshold <= 0 || size <= threshold {
+		return NewNode(expr)
+	}
+
+	return &Node{
+		Kind:  NodeDeferred,
+		Range: newSourceRange(rng),
+		Size:  size,
+		lazy:  &lazyExpression{expr: expr},
+	}
+}
+
+// NewNode converts an expression into its AST
+func NewNode(expr hclsyntax.Expression) *Node {
+	switch e := expr.(type) {
+	case *hclsyntax.LiteralValueExpr:
+		return &Node{Kind: NodeLiteral, Value: literalValue(e.Val)}
+	case *hclsyntax.TemplateExpr:
+		node := &Node{Kind: NodeTemplate}
+		for _, part := range e.Parts {
+			node.Parts = append(node.Parts, NewNode(part))
+		}
+		return node
+	case *hclsyntax.TemplateWrapExpr:
+		return &Node{Kind: NodeTemplateWrap, Source: NewNode(e.Wrapped)}
+	case *hclsyntax.ScopeTraversalExpr:
+		return &Node{Kind: NodeTraversal, Traversal: traversalSteps(e.Traversal)}
+	case *hclsyntax.RelativeTraversalExpr:
+		return &Node{Kind: NodeRelativeTraversal, Source: NewNode(e.Source), Traversal: traversalSteps(e.Traversal)}
+	case *hclsyntax.FunctionCallExpr:
+		node := &Node{Kind: NodeFunctionCall, Function: e.Name, ExpandFinal: e.ExpandFinal}
+		for _, arg := range e.Args {
+			node.Args = append(node.Args, NewNode(arg))
+		}
+		return node
+	case *hclsyntax.TupleConsExpr:
+		node := &Node{Kind: NodeTuple}
+		for _, item := range e.Exprs {
+			node.Items = append(node.Items, NewNode(item))
+		}
+		return node
+	case *hclsyntax.ObjectConsExpr:
+		node := &Node{Kind: NodeObject}
+		for _, item := range e.Items {
+			node.Attrs = append(node.Attrs, &ObjectItem{Key: NewNode(item.KeyExpr), Value: NewNode(item.ValueExpr)})
+		}
+		return node
+	case *hclsyntax.ObjectConsKeyExpr:
+		// A bare identifier key is a literal name, not a reference
+		if keyword := hcl.ExprAsKeyword(e.Wrapped); keyword != "" && !e.ForceNonLiteral {
+			return &Node{Kind: NodeLiteral, Value: keyword}
+		}
+		return NewNode(e.Wrapped)
+	case *hclsyntax.ConditionalExpr:
+		return &Node{
+			Kind:      NodeConditional,
+			Condition: NewNode(e.Condition),
+			True:      NewNode(e.TrueResult),
+			False:     NewNode(e.FalseResult),
+		}
+	case *hclsyntax.BinaryOpExpr:
+		return &Node{Kind: NodeBinaryOp, Operator: operatorSymbol(e.Op), LHS: NewNode(e.LHS), RHS: NewNode(e.RHS)}
+	case *hclsyntax.UnaryOpExpr:
+		return &Node{Kind: NodeUnaryOp, Operator: operatorSymbol(e.Op), Operand: NewNode(e.Val)}
+	case *hclsyntax.IndexExpr:
+		return &Node{Kind: NodeIndex, Source: NewNode(e.Collection), Key: NewNode(e.Key)}
+	case *hclsyntax.SplatExpr:
+		return &Node{Kind: NodeSplat, Source: NewNode(e.Source), Each: NewNode(e.Each)}
+	case *hclsyntax.AnonSymbolExpr:
+		return &Node{Kind: NodeSplatItem}
+	case *hclsyntax.ForExpr:
+		node := &Node{
+			Kind:       NodeFor,
+			Collection: NewNode(e.CollExpr),
+			KeyVar:     e.KeyVar,
+			ValueVar:   e.ValVar,
+			ValueExpr:  NewNode(e.ValExpr),
+			Group:      e.Group,
+		}
+		if e.KeyExpr != nil {
+			node.Key = NewNode(e.KeyExpr)
+		}
+		if e.CondExpr != nil {
+			node.Condition = NewNode(e.CondExpr)
+		}
+		return node
+	case *hclsyntax.ParenthesesExpr:
+		return &Node{Kind: NodeParentheses, Source: NewNode(e.Expression)}
+	default:
+		return &Node{Kind: NodeUnknown}
+	}
+}
+
+// literalValue converts a literal cty value into the Go value used in the JSON output
+func literalValue(val cty.Value) interface{} {
+	if val.IsNull() || !val.IsKnown() {
+		return nil
+	}
+
+	switch val.Type() {
+	case cty.String:
+		return val.AsString()
+	case cty.Number:
+		return numberValue(val)
+	case cty.Bool:
+		return val.True()
+	}
+
+	return nil
+}
+
+func traversalSteps(traversal hcl.Traversal) []string {
+	steps := make([]string, 0, len(traversal))
+	for _, step := range traversal {
+		switch s := step.(type) {
+		case hcl.TraverseRoot:
+			steps = append(steps, s.Name)
+		case hcl.TraverseAttr:
+			steps = append(steps, s.Name)
+		case hcl.TraverseIndex:
+			steps = append(steps, indexStep(s.Key))
+		case hcl.TraverseSplat:
+			steps = append(steps, "[*]")
+		}
+	}
+	return steps
+}
+
+func indexStep(key cty.Value) string {
+	if key.Type() == cty.String && key.IsKnown() && !key.IsNull() {
+		return fmt.Sprintf("[%q]", key.AsString())
+	}
+	return fmt.Sprintf("[%v]", literalValue(key))
+}
+
+var operatorSymbols = map[*hclsyntax.Operation]string{
+	hclsyntax.OpLogicalOr:          "||",
+	hclsyntax.OpLogicalAnd:         "&&",
+	hclsyntax.OpLogicalNot:         "!",
+	hclsyntax.OpEqual:              "==",
+	hclsyntax.OpNotEqual:           "!=",
+	hclsyntax.OpGreaterThan:        ">",
+	hclsyntax.OpGreaterThanOrEqual: ">=",
+	hclsyntax.OpLessThan:           "<",
+	hclsyntax.OpLessThanOrEqual:    "<=",
+	hclsyntax.OpAdd:                "+",
+	hclsyntax.OpSubtract:           "-",
+	hclsyntax.OpMultiply:           "*",
+	hclsyntax.OpDivide:             "/",
+	hclsyntax.OpModulo:             "%",
+	hclsyntax.OpNegate:             "-",
+}
+
+func operatorSymbol(op *hclsyntax.Operation) string {
+	if symbol, ok := operatorSymbols[op]; ok {
+		return symbol
+	}
+	return "?"
+}
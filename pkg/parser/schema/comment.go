@@ -0,0 +1,93 @@
+package schema
+
+import (
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// CommentCarrier is implemented by blocks that can carry the comment documenting them
+type CommentCarrier interface {
+	SetComment(comment string)
+}
+
+// SetComment attaches the comment right above the block
+func (s *Syntax) SetComment(comment string) {
+	s.Comment = comment
+}
+
+// LeadingComments maps the line of every statement of file that directly follows a comment on its
+// own lines to the text of that comment, e.g. the line of a variable block to the "# ..." lines
+// above it. A blank line ends a comment, and tfparser: directives are left out of the text.
+func LeadingComments(file *hcl.File) map[int]string {
+	comments := map[int]string{}
+	tokens, _ := hclsyntax.LexConfig(file.Bytes, "", hcl.InitialPos)
+
+	var group []string
+	lastLine := 0     // last line of the comment group
+	lineStart := true // no token precedes the current one on its line
+	for _, token := range tokens {
+		switch token.Type {
+		case hclsyntax.TokenComment:
+			// Line comments end with their newline
+			endsLine := token.Range.End.Column == 1
+			switch {
+			case !lineStart:
+				// A trailing comment does not document the next statement
+				group = nil
+			case len(group) > 0 && token.Range.Start.Line != lastLine+1:
+				group = commentText(token.Bytes)
+			default:
+				group = append(group, commentText(token.Bytes)...)
+			}
+			lastLine = token.Range.End.Line
+			if endsLine {
+				lastLine--
+			}
+			lineStart = endsLine
+		case hclsyntax.TokenNewline:
+			if len(group) > 0 && token.Range.Start.Line > lastLine {
+				group = nil
+			}
+			lineStart = true
+		default:
+			if lineStart && len(group) > 0 && token.Range.Start.Line == lastLine+1 {
+				if text := strings.TrimSpace(strings.Join(group, "\n")); text != "" {
+					comments[token.Range.Start.Line] = text
+				}
+			}
+			group = nil
+			lineStart = false
+		}
+	}
+	return comments
+}
+
+// commentText strips the comment markers of a comment token and returns its lines
+func commentText(comment []byte) []string {
+	text := strings.TrimRight(string(comment), "\r\n")
+	block := strings.HasPrefix(text, "/*")
+	switch {
+	case strings.HasPrefix(text, "#"):
+		text = strings.TrimPrefix(text, "#")
+	case strings.HasPrefix(text, "//"):
+		text = strings.TrimPrefix(text, "//")
+	default:
+		text = strings.TrimSuffix(strings.TrimPrefix(text, "/*"), "*/")
+	}
+
+	lines := []string{}
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if block {
+			// Continuation lines of block comments often start with " * "
+			line = strings.TrimSpace(strings.TrimPrefix(line, "*"))
+		}
+		if strings.HasPrefix(line, "tfparser:") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
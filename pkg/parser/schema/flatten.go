@@ -0,0 +1,151 @@
+package schema
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Flattener is implemented by blocks that can add a flattened form of their nested values
+type Flattener interface {
+	Flatten(file *hcl.File, block *hclsyntax.Block)
+}
+
+// FlattenExpression flattens an object or tuple expression into dotted key paths, e.g.
+// tags.Name or subnets[0].cidr, mapping to the leaf values. Keys that are not identifiers are
+// quoted (tags["kubernetes.io/role"]). Leaves are literal values, or the source text of
+// expressions that cannot be evaluated without context; empty collections are leaves too.
+// It returns nil for other expressions.
+func FlattenExpression(file *hcl.File, expr hclsyntax.Expression) map[string]interface{} {
+	switch expr.(type) {
+	case *hclsyntax.ObjectConsExpr, *hclsyntax.TupleConsExpr:
+		flat := map[string]interface{}{}
+		flattenExpression(file, "", expr, flat)
+		return flat
+	}
+	return nil
+}
+
+// FlattenBody flattens the attributes and nested blocks of a body like FlattenExpression. Nested
+// blocks are keyed by their type, with an index when the type repeats.
+func FlattenBody(file *hcl.File, body *hclsyntax.Body) map[string]interface{} {
+	if len(body.Attributes) == 0 && len(body.Blocks) == 0 {
+		return nil
+	}
+
+	flat := map[string]interface{}{}
+	flattenBody(file, "", body, flat)
+	return flat
+}
+
+func flattenBody(file *hcl.File, prefix string, body *hclsyntax.Body, flat map[string]interface{}) {
+	for name, attr := range body.Attributes {
+		flattenExpression(file, joinKey(prefix, name), attr.Expr, flat)
+	}
+
+	counts := map[string]int{}
+	for _, nested := range body.Blocks {
+		counts[nested.Type]++
+	}
+	indexes := map[string]int{}
+	for _, nested := range body.Blocks {
+		key := joinKey(prefix, nested.Type)
+		if counts[nested.Type] > 1 {
+			key = fmt.Sprintf("%s[%d]", key, indexes[nested.Type])
+			indexes[nested.Type]++
+		}
+		if len(nested.Body.Attributes) == 0 && len(nested.Body.Blocks) == 0 {
+			flat[key] = map[string]interface{}{}
+			continue
+		}
+		flattenBody(file, key, nested.Body, flat)
+	}
+}
+
+func flattenExpression(file *hcl.File, prefix string, expr hclsyntax.Expression, flat map[string]interface{}) {
+	switch e := expr.(type) {
+	case *hclsyntax.ObjectConsExpr:
+		if len(e.Items) == 0 {
+			flat[prefix] = map[string]interface{}{}
+			return
+		}
+		for _, item := range e.Items {
+			if _, ok := objectKey(item.KeyExpr); !ok {
+				// Computed keys can only be shown as the source text of the whole object
+				flat[prefix] = expressionSource(file, expr)
+				return
+			}
+		}
+		for _, item := range e.Items {
+			key, _ := objectKey(item.KeyExpr)
+			flattenExpression(file, joinKey(prefix, key), item.ValueExpr, flat)
+		}
+	case *hclsyntax.TupleConsExpr:
+		if len(e.Exprs) == 0 {
+			flat[prefix] = []interface{}{}
+			return
+		}
+		for i, element := range e.Exprs {
+			flattenExpression(file, prefix+"["+strconv.Itoa(i)+"]", element, flat)
+		}
+	default:
+		flat[prefix] = leafValue(file, expr)
+	}
+}
+
+// objectKey returns the constant key of an object item: a bare identifier or a literal string
+func objectKey(expr hclsyntax.Expression) (string, bool) {
+	if keyExpr, ok := expr.(*hclsyntax.ObjectConsKeyExpr); ok {
+		if name := hcl.ExprAsKeyword(keyExpr.Wrapped); name != "" && !keyExpr.ForceNonLiteral {
+			return name, true
+		}
+		expr = keyExpr.Wrapped
+	}
+
+	val, diags := expr.Value(nil)
+	if diags.HasErrors() || !val.IsKnown() || val.IsNull() {
+		return "", false
+	}
+	if val.Type() == cty.Number {
+		return formatNumber(val), true
+	}
+	if val.Type() != cty.String {
+		return "", false
+	}
+	return val.AsString(), true
+}
+
+// leafValue evaluates a constant expression, or returns its source text
+func leafValue(file *hcl.File, expr hclsyntax.Expression) interface{} {
+	if len(expr.Variables()) == 0 {
+		if val, diags := expr.Value(nil); !diags.HasErrors() && val.IsWhollyKnown() {
+			if val.IsNull() {
+				return nil
+			}
+			switch val.Type() {
+			case cty.String, cty.Number, cty.Bool:
+				return literalValue(val)
+			}
+		}
+	}
+	return expressionSource(file, expr)
+}
+
+func expressionSource(file *hcl.File, expr hclsyntax.Expression) string {
+	return strings.TrimSpace(string(expr.Range().SliceBytes(file.Bytes)))
+}
+
+// joinKey appends a key to a path, quoting keys that are not identifiers
+func joinKey(prefix, key string) string {
+	if !hclsyntax.ValidIdentifier(key) {
+		return prefix + "[" + strconv.Quote(key) + "]"
+	}
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
@@ -0,0 +1,46 @@
+package schema
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+type Import struct {
+	To       string `json:"to"`
+	ID       string `json:"id"`
+	Provider string `json:"provider,omitempty"`
+	// IDIsLiteral is false when the id is computed from an expression (references, functions)
+	IDIsLiteral bool `json:"id_is_literal"`
+	Syntax
+}
+
+func (b *Import) Parse(file *hcl.File, block *hclsyntax.Block) error {
+	attrs := block.Body.Attributes
+
+	if toAttr, ok := attrs["to"]; ok {
+		b.To = parseAttributeToString(file, toAttr)
+	} else {
+		return fmt.Errorf("import block is missing to attribute")
+	}
+
+	if idAttr, ok := attrs["id"]; ok {
+		b.ID = parseAttributeToString(file, idAttr)
+		val, diags := idAttr.Expr.Value(nil)
+		b.IDIsLiteral = !diags.HasErrors() && val.IsKnown() && !val.IsNull() && val.Type() == cty.String
+		if b.IDIsLiteral {
+			// Templates of literals evaluate to the ID itself
+			b.ID = val.AsString()
+		}
+	} else {
+		return fmt.Errorf("import block for %s is missing id attribute", b.To)
+	}
+
+	if providerAttr, ok := attrs["provider"]; ok {
+		b.Provider = parseAttributeToString(file, providerAttr)
+	}
+
+	return nil
+}
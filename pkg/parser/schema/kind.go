@@ -0,0 +1,104 @@
+package schema
+
+import (
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// ValueKind tells how an attribute value is computed, inferred from its expression
+type ValueKind string
+
+const (
+	// KindLiteral is a constant: a number, bool, null, a string without interpolation, or a
+	// list or object of constants
+	KindLiteral ValueKind = "literal"
+	// KindReference refers to other objects, e.g. var.name, or interpolates references into a string
+	KindReference ValueKind = "reference"
+	// KindFunction is a function call, e.g. jsonencode(...)
+	KindFunction ValueKind = "function"
+	// KindConditional is a conditional expression (a ? b : c)
+	KindConditional ValueKind = "conditional"
+	// KindComplex is any other expression: operators, for expressions, collections with
+	// computed elements, ...
+	KindComplex ValueKind = "complex"
+)
+
+// KindCarrier is implemented by blocks that can carry the value kinds of their attributes
+type KindCarrier interface {
+	SetKinds(kinds map[string]ValueKind)
+}
+
+// SetKinds attaches the value kinds of the block attributes
+func (s *Syntax) SetKinds(kinds map[string]ValueKind) {
+	s.Kinds = kinds
+}
+
+// SetKinds gives each local value the kind of its own expression
+func (b *Locals) SetKinds(kinds map[string]ValueKind) {
+	for _, local := range b.Values {
+		local.Kind = kinds[local.Name]
+	}
+}
+
+// NewKinds infers the value kind of every attribute of body
+func NewKinds(body *hclsyntax.Body) map[string]ValueKind {
+	if len(body.Attributes) == 0 {
+		return nil
+	}
+
+	kinds := make(map[string]ValueKind, len(body.Attributes))
+	for name, attr := range body.Attributes {
+		kinds[name] = ExpressionKind(attr.Expr)
+	}
+	return kinds
+}
+
+// ExpressionKind infers the value kind of an expression
+func ExpressionKind(expr hclsyntax.Expression) ValueKind {
+	switch e := expr.(type) {
+	case *hclsyntax.ParenthesesExpr:
+		return ExpressionKind(e.Expression)
+	case *hclsyntax.LiteralValueExpr:
+		return KindLiteral
+	case *hclsyntax.UnaryOpExpr:
+		// A negative number is a unary operation on a literal
+		if ExpressionKind(e.Val) == KindLiteral {
+			return KindLiteral
+		}
+		return KindComplex
+	case *hclsyntax.ScopeTraversalExpr, *hclsyntax.RelativeTraversalExpr, *hclsyntax.IndexExpr, *hclsyntax.SplatExpr:
+		return KindReference
+	case *hclsyntax.TemplateWrapExpr:
+		return ExpressionKind(e.Wrapped)
+	case *hclsyntax.TemplateExpr:
+		kind := KindLiteral
+		for _, part := range e.Parts {
+			switch ExpressionKind(part) {
+			case KindLiteral:
+			case KindReference:
+				kind = KindReference
+			default:
+				return KindComplex
+			}
+		}
+		return kind
+	case *hclsyntax.FunctionCallExpr:
+		return KindFunction
+	case *hclsyntax.ConditionalExpr:
+		return KindConditional
+	case *hclsyntax.TupleConsExpr:
+		for _, element := range e.Exprs {
+			if ExpressionKind(element) != KindLiteral {
+				return KindComplex
+			}
+		}
+		return KindLiteral
+	case *hclsyntax.ObjectConsExpr:
+		for _, item := range e.Items {
+			if _, ok := objectKey(item.KeyExpr); !ok || ExpressionKind(item.ValueExpr) != KindLiteral {
+				return KindComplex
+			}
+		}
+		return KindLiteral
+	}
+	return KindComplex
+}
@@ -0,0 +1,42 @@
+package schema
+
+import (
+	"sort"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// Locals is a locals block; its values are flattened into TerraformConfig.Locals
+type Locals struct {
+	Values []*Local
+}
+
+type Local struct {
+	Name string `json:"name"`
+	// References lists the objects referenced by the local value expression
+	References []string `json:"references,omitempty"`
+	// AST is the expression of the local value, set when the parser runs WithAST
+	AST *Node `json:"ast,omitempty"`
+	// Kind is the value kind of the expression, set when the parser runs WithKinds
+	Kind ValueKind `json:"kind,omitempty"`
+	// SourceRange is the position of the attribute, set when the parser runs WithPositions
+	*SourceRange
+}
+
+func (b *Locals) Parse(file *hcl.File, block *hclsyntax.Block) error {
+	for name, attr := range block.Body.Attributes {
+		b.Values = append(b.Values, &Local{
+			Name:       name,
+			References: collectReferences(&hclsyntax.Body{Attributes: hclsyntax.Attributes{name: attr}}),
+		})
+	}
+
+	sort.Slice(b.Values, func(i, j int) bool { return b.Values[i].Name < b.Values[j].Name })
+	return nil
+}
+
+// Address returns the local value reference address, e.g. local.name_prefix
+func (b *Local) Address() string {
+	return "local." + b.Name
+}
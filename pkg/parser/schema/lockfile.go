@@ -0,0 +1,63 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// LockFileName is the dependency lock file written by terraform init and tofu init
+const LockFileName = ".terraform.lock.hcl"
+
+// defaultRegistries are the hosts a provider source without hostname resolves to
+var defaultRegistries = []string{"registry.terraform.io", "registry.opentofu.org"}
+
+// LockedProvider is a provider block of the dependency lock file
+type LockedProvider struct {
+	// Source is the fully qualified provider address, e.g. registry.terraform.io/hashicorp/aws
+	Source      string   `json:"source"`
+	Version     string   `json:"version"`
+	Constraints string   `json:"constraints,omitempty"`
+	Hashes      []string `json:"hashes,omitempty"`
+}
+
+func (b *LockedProvider) Parse(file *hcl.File, block *hclsyntax.Block) error {
+	if len(block.Labels) != 1 {
+		return fmt.Errorf("provider block of the lock file must have one label")
+	}
+	b.Source = strings.ToLower(block.Labels[0])
+
+	attrs := block.Body.Attributes
+
+	if versionAttr, ok := attrs["version"]; ok {
+		b.Version = parseAttributeToString(file, versionAttr)
+	}
+
+	if constraintsAttr, ok := attrs["constraints"]; ok {
+		b.Constraints = parseAttributeToString(file, constraintsAttr)
+	}
+
+	if hashesAttr, ok := attrs["hashes"]; ok {
+		b.Hashes = parseAttributeToStringList(file, hashesAttr)
+	}
+
+	return nil
+}
+
+// Matches reports whether the locked provider is the one a required_providers source refers to.
+// A source without hostname matches the lock entry of either default registry.
+func (b *LockedProvider) Matches(source string) bool {
+	source = strings.ToLower(source)
+	if strings.Count(source, "/") == 2 {
+		return b.Source == source
+	}
+
+	for _, host := range defaultRegistries {
+		if b.Source == host+"/"+source {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package schema
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+type Module struct {
+	Name    string `json:"name"`
+	Source  string `json:"source"`
+	Version string `json:"version,omitempty"`
+	// Providers maps provider addresses in the child module to configurations in the calling module
+	Providers map[string]string `json:"providers,omitempty"`
+	DependsOn []string          `json:"depends_on,omitempty"`
+	// References lists the objects referenced by the module call's arguments
+	References []string `json:"references,omitempty"`
+	// Arguments lists the input variables the call sets, without meta-arguments
+	Arguments []string `json:"arguments,omitempty"`
+	Syntax
+}
+
+// moduleMetaArguments are the arguments of a module call that are not input variables
+var moduleMetaArguments = []string{"source", "version", "providers", "depends_on", "count", "for_each"}
+
+func (b *Module) Parse(file *hcl.File, block *hclsyntax.Block) error {
+	b.Name = block.Labels[0]
+
+	attrs := block.Body.Attributes
+
+	if sourceAttr, ok := attrs["source"]; ok {
+		b.Source = parseAttributeToString(file, sourceAttr)
+	} else {
+		return fmt.Errorf("module %s is missing source attribute", b.Name)
+	}
+
+	if versionAttr, ok := attrs["version"]; ok {
+		b.Version = parseAttributeToString(file, versionAttr)
+	}
+
+	if providersAttr, ok := attrs["providers"]; ok {
+		b.Providers = parseAttributeToTraversalMap(file, providersAttr)
+	}
+
+	if dependsOnAttr, ok := attrs["depends_on"]; ok {
+		b.DependsOn = parseAttributeToStringList(file, dependsOnAttr)
+	}
+
+	b.References = collectReferences(block.Body, "source", "version", "providers", "depends_on")
+
+	for name := range attrs {
+		if !slices.Contains(moduleMetaArguments, name) {
+			b.Arguments = append(b.Arguments, name)
+		}
+	}
+	slices.Sort(b.Arguments)
+
+	return nil
+}
+
+// Address returns the module call address, e.g. module.vpc
+func (b *Module) Address() string {
+	return "module." + b.Name
+}
@@ -2,6 +2,7 @@ package schema
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
@@ -10,30 +11,102 @@ import (
 type Output struct {
 	Name        string `json:"name"`
 	Description string `json:"description,omitempty"`
-	Sensitive   bool   `json:"sensitive,omitempty"`
-	// Value       string `json:"value"`
+	// Value is the value expression as written, e.g. aws_vpc.this.id
+	Value     string `json:"value"`
+	Sensitive *bool  `json:"sensitive,omitempty"`
+	// Ephemeral outputs (Terraform 1.10+) pass ephemeral values to the calling module; emitted
+	// only when set, like Sensitive
+	Ephemeral *bool    `json:"ephemeral,omitempty"`
+	DependsOn []string `json:"depends_on,omitempty"`
+	// References lists the objects referenced by the output's expressions
+	References []string `json:"references,omitempty"`
+	// Preconditions are checked before the value is computed, like the validations of a variable
+	Preconditions []*OutputPrecondition `json:"precondition,omitempty"`
+	Syntax
+}
+
+type OutputPrecondition struct {
+	Condition    string `json:"condition"`
+	ErrorMessage string `json:"error_message"`
+	// References lists the objects the condition refers to
+	References []string `json:"references,omitempty"`
+	// Functions lists the functions the condition calls, e.g. can or length
+	Functions []string `json:"functions,omitempty"`
 }
 
 func (b *Output) Parse(file *hcl.File, block *hclsyntax.Block) error {
-	if len(block.Labels) != 1 {
-		return fmt.Errorf("variable block must have one label")
-	}
 	b.Name = block.Labels[0]
 
 	attrs := block.Body.Attributes
 
-	// if valueAttr, ok := attrs["value"]; ok {
-	// 	b.Value = parseAttributeToString(file, valueAttr)
-	// } else {
-	// 	return fmt.Errorf("variable %s is missing Value attribute", b.Name)
-	// }
+	// The value is required, but a missing one is reported by block validation
+	if valueAttr, ok := attrs["value"]; ok {
+		b.Value = strings.TrimSpace(string(valueAttr.Expr.Range().SliceBytes(file.Bytes)))
+	}
 
 	if descriptionAttr, ok := attrs["description"]; ok {
 		b.Description = parseAttributeToString(file, descriptionAttr)
 	}
 
 	if sensitiveAttr, ok := attrs["sensitive"]; ok {
-		b.Sensitive = parseAttributeToBool(file, sensitiveAttr)
+		b.Sensitive = parseAttributeToOptionalBool(file, sensitiveAttr)
+	}
+
+	if ephemeralAttr, ok := attrs["ephemeral"]; ok {
+		b.Ephemeral = parseAttributeToOptionalBool(file, ephemeralAttr)
+	}
+
+	if dependsOnAttr, ok := attrs["depends_on"]; ok {
+		b.DependsOn = parseAttributeToStringList(file, dependsOnAttr)
+	}
+
+	b.References = collectReferences(block.Body, "description", "sensitive", "ephemeral", "depends_on")
+
+	for _, blockInBlock := range block.Body.Blocks {
+		switch blockInBlock.Type {
+		case "precondition":
+			precondition := &OutputPrecondition{}
+			if err := precondition.Parse(file, blockInBlock); err != nil {
+				return fmt.Errorf("error parsing precondition for output %s: %w", b.Name, err)
+			}
+
+			b.Preconditions = append(b.Preconditions, precondition)
+		}
+	}
+
+	return nil
+}
+
+// IsSensitive reports whether the output is marked sensitive
+func (b *Output) IsSensitive() bool {
+	return b.Sensitive != nil && *b.Sensitive
+}
+
+// IsEphemeral reports whether the output is ephemeral
+func (b *Output) IsEphemeral() bool {
+	return b.Ephemeral != nil && *b.Ephemeral
+}
+
+// Address returns the output address, e.g. output.vpc_id
+func (b *Output) Address() string {
+	return "output." + b.Name
+}
+
+func (b *OutputPrecondition) Parse(file *hcl.File, block *hclsyntax.Block) error {
+	attrs := block.Body.Attributes
+
+	if conditionAttr, ok := attrs["condition"]; ok {
+		b.Condition = parseAttributeToString(file, conditionAttr)
+		b.References = collectReferences(&hclsyntax.Body{Attributes: pickAttributes(block.Body, "condition")})
+		b.Functions = collectFunctions(conditionAttr.Expr)
+	} else {
+		return fmt.Errorf("condition is missing in precondition block")
+	}
+
+	if errorMessageAttr, ok := attrs["error_message"]; ok {
+		b.ErrorMessage = parseAttributeToString(file, errorMessageAttr)
+	} else {
+		return fmt.Errorf("error_message is missing in precondition block")
 	}
 
 	return nil
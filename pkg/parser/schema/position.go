@@ -0,0 +1,29 @@
+package schema
+
+import (
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// PositionCarrier is implemented by blocks that can carry the position of their declaration
+type PositionCarrier interface {
+	SetPosition(block *hclsyntax.Block)
+}
+
+// SetPosition records the file and lines of block
+func (s *Syntax) SetPosition(block *hclsyntax.Block) {
+	s.SourceRange = newSourceRange(block.Range())
+}
+
+// SetPosition gives each local value the lines of its own attribute
+func (b *Locals) SetPosition(block *hclsyntax.Block) {
+	for _, local := range b.Values {
+		if attr, ok := block.Body.Attributes[local.Name]; ok {
+			local.SourceRange = newSourceRange(attr.Range())
+		}
+	}
+}
+
+func newSourceRange(rng hcl.Range) *SourceRange {
+	return &SourceRange{File: rng.Filename, StartLine: rng.Start.Line, EndLine: rng.End.Line}
+}
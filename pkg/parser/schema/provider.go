@@ -0,0 +1,32 @@
+package schema
+
+import (
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+type Provider struct {
+	Name  string `json:"name"`
+	Alias string `json:"alias,omitempty"`
+	Syntax
+}
+
+func (b *Provider) Parse(file *hcl.File, block *hclsyntax.Block) error {
+	b.Name = block.Labels[0]
+
+	attrs := block.Body.Attributes
+
+	if aliasAttr, ok := attrs["alias"]; ok {
+		b.Alias = parseAttributeToString(file, aliasAttr)
+	}
+
+	return nil
+}
+
+// Address returns the provider configuration address, e.g. aws or aws.west
+func (b *Provider) Address() string {
+	if b.Alias == "" {
+		return b.Name
+	}
+	return b.Name + "." + b.Alias
+}
@@ -0,0 +1,151 @@
+package schema
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// Spec describes a block type as Terraform specifies it: its label count, arguments and nested
+// blocks, and for top-level types the Block reading it
+type Spec struct {
+	Labels     int
+	Attributes []string
+	Required   []string
+	Blocks     map[string]*Spec
+	// AnyAttributes and AnyBlocks accept contents defined elsewhere, e.g. by a provider schema
+	// or the variables of a child module
+	AnyAttributes bool
+	AnyBlocks     bool
+	// New creates the Block reading a top-level block of this type; nil for types that are
+	// validated only
+	New func() Block
+	// Detail types are read in the parser's Detail mode only
+	Detail bool
+}
+
+var (
+	conditionSpec = &Spec{Attributes: []string{"condition", "error_message"}, Required: []string{"condition", "error_message"}}
+	lifecycleSpec = &Spec{
+		Attributes: []string{"create_before_destroy", "prevent_destroy", "ignore_changes", "replace_triggered_by"},
+		Blocks:     map[string]*Spec{"precondition": conditionSpec, "postcondition": conditionSpec},
+	}
+	resourceBlocks = map[string]*Spec{"lifecycle": lifecycleSpec}
+	openSpec       = &Spec{AnyAttributes: true, AnyBlocks: true}
+)
+
+// registry holds the top-level block types of a configuration file
+var registry = map[string]*Spec{
+	"variable": {
+		Labels:     1,
+		Attributes: []string{"description", "default", "type", "sensitive", "nullable", "ephemeral"},
+		Blocks:     map[string]*Spec{"validation": conditionSpec},
+		New:        func() Block { return &Variable{} },
+	},
+	"output": {
+		Labels:     1,
+		Attributes: []string{"description", "value", "sensitive", "depends_on", "ephemeral"},
+		Required:   []string{"value"},
+		Blocks:     map[string]*Spec{"precondition": conditionSpec},
+		New:        func() Block { return &Output{} },
+	},
+	"terraform": {
+		Attributes: []string{"required_version", "experiments"},
+		Blocks: map[string]*Spec{
+			"required_providers": {AnyAttributes: true},
+			"backend":            {Labels: 1, AnyAttributes: true, AnyBlocks: true},
+			"cloud": {
+				Attributes: []string{"organization", "hostname", "token"},
+				Blocks:     map[string]*Spec{"workspaces": {Attributes: []string{"name", "tags", "project"}}},
+			},
+			"provider_meta": {Labels: 1, AnyAttributes: true},
+		},
+		New: func() Block { return &Terraform{} },
+	},
+	"tfparser": {AnyAttributes: true, AnyBlocks: true, New: func() Block { return &Annotations{} }},
+	"metadata": {AnyAttributes: true, AnyBlocks: true, New: func() Block { return &Annotations{} }},
+	"locals":   {AnyAttributes: true, New: func() Block { return &Locals{} }, Detail: true},
+	"module": {
+		Labels:        1,
+		Attributes:    []string{"source", "version", "providers", "depends_on", "count", "for_each"},
+		Required:      []string{"source"},
+		AnyAttributes: true,
+		New:           func() Block { return &Module{} },
+		Detail:        true,
+	},
+	"provider": {Labels: 1, AnyAttributes: true, AnyBlocks: true, New: func() Block { return &Provider{} }, Detail: true},
+	"resource": {
+		Labels: 2, AnyAttributes: true, AnyBlocks: true, Blocks: resourceBlocks,
+		New: func() Block { return &Resource{} }, Detail: true,
+	},
+	"data": {
+		Labels: 2, AnyAttributes: true, AnyBlocks: true, Blocks: resourceBlocks,
+		New: func() Block { return &DataSource{} }, Detail: true,
+	},
+	"ephemeral": {
+		Labels: 2, AnyAttributes: true, AnyBlocks: true, Blocks: resourceBlocks,
+		New: func() Block { return &EphemeralResource{} }, Detail: true,
+	},
+	"import": {
+		Attributes: []string{"to", "id", "identity", "provider", "for_each"},
+		Required:   []string{"to"},
+		New:        func() Block { return &Import{} },
+		Detail:     true,
+	},
+	"moved": {Attributes: []string{"from", "to"}, Required: []string{"from", "to"}},
+	"removed": {
+		Attributes: []string{"from"},
+		Required:   []string{"from"},
+		Blocks: map[string]*Spec{
+			"lifecycle":   {Attributes: []string{"destroy"}},
+			"provisioner": {Labels: 1, AnyAttributes: true, AnyBlocks: true},
+			"connection":  openSpec,
+		},
+	},
+	"check": {
+		Labels: 1,
+		Blocks: map[string]*Spec{
+			"data":   {Labels: 2, AnyAttributes: true, AnyBlocks: true},
+			"assert": conditionSpec,
+		},
+	},
+}
+
+// Lookup returns the spec of a top-level block type
+func Lookup(blockType string) (*Spec, bool) {
+	spec, ok := registry[blockType]
+	return spec, ok
+}
+
+// TopLevel is the spec of a configuration file body, whose blocks are the registered types
+func TopLevel() *Spec {
+	return &Spec{Blocks: registry}
+}
+
+// BlockTypes lists the nested block types of the spec in order
+func (s *Spec) BlockTypes() []string {
+	types := make([]string, 0, len(s.Blocks))
+	for blockType := range s.Blocks {
+		types = append(types, blockType)
+	}
+	slices.Sort(types)
+	return types
+}
+
+// CheckLabels checks the label count of block and of its nested blocks of known types, so that
+// Block.Parse can rely on them
+func (s *Spec) CheckLabels(block *hclsyntax.Block) error {
+	if len(block.Labels) != s.Labels {
+		return fmt.Errorf("%s block must have %d label(s), but has %d", block.Type, s.Labels, len(block.Labels))
+	}
+
+	for _, nested := range block.Body.Blocks {
+		if spec, ok := s.Blocks[nested.Type]; ok {
+			if err := spec.CheckLabels(nested); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package schema
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+type Resource struct {
+	Type      string   `json:"type"`
+	Name      string   `json:"name"`
+	Provider  string   `json:"provider,omitempty"`
+	DependsOn []string `json:"depends_on,omitempty"`
+	// References lists the objects referenced by the block's expressions
+	References []string `json:"references,omitempty"`
+	// WriteOnly lists the write-only arguments set by the block (name ending in _wo, Terraform 1.11+)
+	WriteOnly []string `json:"write_only,omitempty"`
+	Syntax
+}
+
+// DataSource is a data block; it shares the shape of a managed resource
+type DataSource struct {
+	Resource
+}
+
+// EphemeralResource is an ephemeral block (Terraform 1.10+); its values are never persisted
+type EphemeralResource struct {
+	Resource
+}
+
+func (b *Resource) Parse(file *hcl.File, block *hclsyntax.Block) error {
+	b.Type = block.Labels[0]
+	b.Name = block.Labels[1]
+
+	attrs := block.Body.Attributes
+
+	if providerAttr, ok := attrs["provider"]; ok {
+		b.Provider = parseAttributeToString(file, providerAttr)
+	}
+
+	if dependsOnAttr, ok := attrs["depends_on"]; ok {
+		b.DependsOn = parseAttributeToStringList(file, dependsOnAttr)
+	}
+
+	b.References = collectReferences(block.Body, "provider", "depends_on")
+	b.WriteOnly = writeOnlyArguments(block.Body)
+
+	return nil
+}
+
+// Address returns the resource address, e.g. aws_instance.web
+func (b *Resource) Address() string {
+	return b.Type + "." + b.Name
+}
+
+// Address returns the data source address, e.g. data.aws_ami.ubuntu
+func (b *DataSource) Address() string {
+	return "data." + b.Type + "." + b.Name
+}
+
+// Address returns the ephemeral resource address, e.g. ephemeral.random_password.db
+func (b *EphemeralResource) Address() string {
+	return "ephemeral." + b.Type + "." + b.Name
+}
+
+// writeOnlyArguments returns the sorted names of write-only arguments in body and its nested blocks.
+// Nested arguments are prefixed with the block type, e.g. master_user.password_wo.
+func writeOnlyArguments(body *hclsyntax.Body) []string {
+	names := []string{}
+	for name := range body.Attributes {
+		if strings.HasSuffix(name, "_wo") {
+			names = append(names, name)
+		}
+	}
+	for _, block := range body.Blocks {
+		for _, name := range writeOnlyArguments(block.Body) {
+			names = append(names, block.Type+"."+name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ProviderLocalName returns the local name of the provider managing the resource.
+// An explicit provider meta-argument wins over the resource type prefix.
+func (b *Resource) ProviderLocalName() string {
+	if b.Provider != "" {
+		name, _, _ := strings.Cut(b.Provider, ".")
+		return name
+	}
+	name, _, _ := strings.Cut(b.Type, "_")
+	return name
+}
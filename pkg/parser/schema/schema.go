@@ -1,7 +1,15 @@
+// Package schema defines the blocks a TerraformConfig is made of (variables, outputs,
+// resources, ...) and how each one is read from HCL.
 package schema
 
 import (
+	"encoding/json"
 	"fmt"
+	"maps"
+	"math/big"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/hashicorp/hcl/v2"
@@ -63,6 +71,8 @@ variable "example" {
 }
 */
 
+// Block is a parsed top-level block. Parse is given blocks whose labels passed the
+// Spec.CheckLabels of their type.
 type Block interface {
 	Parse(file *hcl.File, block *hclsyntax.Block) error
 }
@@ -79,15 +89,8 @@ func parseAttributeToInterface(file *hcl.File, attr *hclsyntax.Attribute) interf
 			// Convert HCL string to Go string
 			return lv.Val.AsString()
 		case cty.Number:
-			if lv.Val.AsBigFloat().IsInt() {
-				// Convert HCL integer to Go int64
-				i, _ := lv.Val.AsBigFloat().Int64()
-				return i
-			}
-
-			// Convert HCL non-integer number to Go float64
-			f, _ := lv.Val.AsBigFloat().Float64()
-			return f
+			// Convert HCL number to Go int64, float64 or, beyond their precision, json.Number
+			return numberValue(lv.Val)
 		case cty.Bool:
 			// Convert HCL bool to Go bool
 			return lv.Val.True()
@@ -113,6 +116,11 @@ func parseAttributeToInterface(file *hcl.File, attr *hclsyntax.Attribute) interf
 }
 
 func parseAttributeToString(file *hcl.File, attr *hclsyntax.Attribute) string {
+	// Render numbers from their exact value rather than a float64 approximation
+	if lv, ok := attr.Expr.(*hclsyntax.LiteralValueExpr); ok && lv.Val.Type() == cty.Number && lv.Val.IsKnown() && !lv.Val.IsNull() {
+		return formatNumber(lv.Val)
+	}
+
 	value := parseAttributeToInterface(file, attr)
 	if str, ok := value.(string); ok {
 		return str
@@ -120,14 +128,11 @@ func parseAttributeToString(file *hcl.File, attr *hclsyntax.Attribute) string {
 
 	switch v := value.(type) {
 	case bool:
-		if v {
-			return "true"
-		}
-		return "false"
+		return strconv.FormatBool(v)
 	case int64:
-		return fmt.Sprintf("%d", v)
+		return strconv.FormatInt(v, 10)
 	case float64:
-		return fmt.Sprintf("%g", v)
+		return strconv.FormatFloat(v, 'f', -1, 64)
 	case nil:
 		return "null"
 	default:
@@ -135,6 +140,51 @@ func parseAttributeToString(file *hcl.File, attr *hclsyntax.Attribute) string {
 	}
 }
 
+// formatNumber renders a number the way Terraform does: in full, without exponent
+func formatNumber(val cty.Value) string {
+	return val.AsBigFloat().Text('f', -1)
+}
+
+// numberValue converts a number into an int64 or a float64 when they hold it exactly, and into
+// a json.Number otherwise, so that large integers keep every digit in the JSON output
+func numberValue(val cty.Value) interface{} {
+	bf := val.AsBigFloat()
+	if bf.IsInt() {
+		if i, accuracy := bf.Int64(); accuracy == big.Exact {
+			return i
+		}
+		return json.Number(formatNumber(val))
+	}
+
+	if f, _ := bf.Float64(); strconv.FormatFloat(f, 'f', -1, 64) == formatNumber(val) {
+		return f
+	}
+	return json.Number(formatNumber(val))
+}
+
+// JSONValue converts the numbers of a value decoded from JSON with json.Decoder.UseNumber the way
+// the parser converts numbers, so that values read back from a saved summary equal parsed ones.
+// Lists and objects are converted in place.
+func JSONValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		val, err := cty.ParseNumberVal(string(v))
+		if err != nil {
+			return v
+		}
+		return numberValue(val)
+	case []interface{}:
+		for i, element := range v {
+			v[i] = JSONValue(element)
+		}
+	case map[string]interface{}:
+		for key, element := range v {
+			v[key] = JSONValue(element)
+		}
+	}
+	return value
+}
+
 func parseAttributeToBool(file *hcl.File, attr *hclsyntax.Attribute) bool {
 	value := parseAttributeToInterface(file, attr)
 	if boolVal, ok := value.(bool); ok {
@@ -156,6 +206,30 @@ func parseAttributeToBool(file *hcl.File, attr *hclsyntax.Attribute) bool {
 	return false
 }
 
+// parseAttributeToOptionalBool returns nil when the value is not a boolean literal (e.g. a reference),
+// so that an explicit false can be told apart from an unknown value
+func parseAttributeToOptionalBool(file *hcl.File, attr *hclsyntax.Attribute) *bool {
+	value := parseAttributeToInterface(file, attr)
+	if boolVal, ok := value.(bool); ok {
+		return &boolVal
+	}
+
+	if str, ok := value.(string); ok {
+		switch strings.ToLower(strings.TrimSpace(str)) {
+		case "true":
+			return ptr(true)
+		case "false":
+			return ptr(false)
+		}
+	}
+
+	return nil
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
 // Parse array attributes to string slice
 func parseAttributeToStringList(file *hcl.File, attr *hclsyntax.Attribute) []string {
 	// Handle tuple expressions (HCL arrays)
@@ -209,6 +283,157 @@ func parseAttributeToStringMap(file *hcl.File, attr *hclsyntax.Attribute) map[st
 	return result
 }
 
+// Parse object attributes whose keys and values are references (e.g. providers = { aws.west = aws.usw2 })
+func parseAttributeToTraversalMap(file *hcl.File, attr *hclsyntax.Attribute) map[string]string {
+	result := make(map[string]string)
+
+	if objExpr, ok := attr.Expr.(*hclsyntax.ObjectConsExpr); ok {
+		for _, item := range objExpr.Items {
+			key := strings.TrimSpace(string(item.KeyExpr.Range().SliceBytes(file.Bytes)))
+			value := strings.TrimSpace(string(item.ValueExpr.Range().SliceBytes(file.Bytes)))
+			if key != "" {
+				result[key] = value
+			}
+		}
+	}
+
+	return result
+}
+
+// Helper function to find an item of an object expression by key
+func findObjectItem(expr hclsyntax.Expression, key string) hclsyntax.Expression {
+	if objExpr, ok := expr.(*hclsyntax.ObjectConsExpr); ok {
+		for _, item := range objExpr.Items {
+			if extractObjectKey(item.KeyExpr) == key {
+				return item.ValueExpr
+			}
+		}
+	}
+	return nil
+}
+
+// collectReferences returns the sorted addresses (var.x, local.y, module.z, data.a.b, a.b) referenced
+// by the expressions in body. Attributes listed in skip are ignored at the top level.
+func collectReferences(body *hclsyntax.Body, skip ...string) []string {
+	seen := map[string]bool{}
+	walkTraversals(body, skip, map[string]bool{}, func(_ *hclsyntax.Attribute, traversal hcl.Traversal) {
+		if ref := ReferenceAddress(traversal); ref != "" {
+			seen[ref] = true
+		}
+	})
+
+	refs := make([]string, 0, len(seen))
+	for ref := range seen {
+		refs = append(refs, ref)
+	}
+	sort.Strings(refs)
+	return refs
+}
+
+// collectFunctions returns the sorted names of the functions called in expr
+func collectFunctions(expr hclsyntax.Expression) []string {
+	functions := []string{}
+	hclsyntax.VisitAll(expr, func(node hclsyntax.Node) hcl.Diagnostics {
+		if call, ok := node.(*hclsyntax.FunctionCallExpr); ok && !slices.Contains(functions, call.Name) {
+			functions = append(functions, call.Name)
+		}
+		return nil
+	})
+	sort.Strings(functions)
+	return functions
+}
+
+// WalkTraversals calls visit for every traversal in the expressions of body and its nested blocks.
+// Iterator symbols of dynamic blocks and the attribute names listed in ignore_changes are not visited.
+func WalkTraversals(body *hclsyntax.Body, visit func(attr *hclsyntax.Attribute, traversal hcl.Traversal)) {
+	walkTraversals(body, nil, map[string]bool{}, visit)
+}
+
+func walkTraversals(body *hclsyntax.Body, skip []string, iterators map[string]bool, visit func(*hclsyntax.Attribute, hcl.Traversal)) {
+	for name, attr := range body.Attributes {
+		if slices.Contains(skip, name) {
+			continue
+		}
+		for _, traversal := range attr.Expr.Variables() {
+			if iterators[traversal.RootName()] {
+				continue
+			}
+			visit(attr, traversal)
+		}
+	}
+
+	for _, block := range body.Blocks {
+		switch block.Type {
+		case "lifecycle":
+			walkTraversals(block.Body, []string{"ignore_changes"}, iterators, visit)
+		case "dynamic":
+			// The iterator of a dynamic block is named after its label unless overridden
+			iterator := ""
+			if len(block.Labels) > 0 {
+				iterator = block.Labels[0]
+			}
+			if iteratorAttr, ok := block.Body.Attributes["iterator"]; ok {
+				iterator = hcl.ExprAsKeyword(iteratorAttr.Expr)
+			}
+
+			scoped := maps.Clone(iterators)
+			scoped[iterator] = true
+			walkTraversals(&hclsyntax.Body{Attributes: pickAttributes(block.Body, "for_each")}, nil, iterators, visit)
+			walkTraversals(block.Body, []string{"for_each", "iterator"}, scoped, visit)
+		default:
+			walkTraversals(block.Body, nil, iterators, visit)
+		}
+	}
+}
+
+func pickAttributes(body *hclsyntax.Body, names ...string) hclsyntax.Attributes {
+	picked := hclsyntax.Attributes{}
+	for _, name := range names {
+		if attr, ok := body.Attributes[name]; ok {
+			picked[name] = attr
+		}
+	}
+	return picked
+}
+
+// ReferenceAddress converts a traversal into the address of the referenced object.
+// It is empty for symbols that do not name an object, e.g. each.value or path.module.
+func ReferenceAddress(traversal hcl.Traversal) string {
+	names := []string{}
+	for _, step := range traversal {
+		switch s := step.(type) {
+		case hcl.TraverseRoot:
+			names = append(names, s.Name)
+		case hcl.TraverseAttr:
+			names = append(names, s.Name)
+		default:
+			// Stop at the first index step, e.g. aws_instance.web[0].id
+			return joinReference(names)
+		}
+	}
+	return joinReference(names)
+}
+
+func joinReference(names []string) string {
+	if len(names) < 2 {
+		return ""
+	}
+
+	switch names[0] {
+	case "var", "local", "module":
+		return names[0] + "." + names[1]
+	case "data", "ephemeral":
+		if len(names) < 3 {
+			return ""
+		}
+		return names[0] + "." + names[1] + "." + names[2]
+	case "each", "count", "self", "path", "terraform":
+		return ""
+	default:
+		return names[0] + "." + names[1]
+	}
+}
+
 // Helper function to extract object keys
 func extractObjectKey(keyExpr hclsyntax.Expression) string {
 	switch key := keyExpr.(type) {
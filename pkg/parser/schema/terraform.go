@@ -1,7 +1,7 @@
 package schema
 
 import (
-	"fmt"
+	"sort"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
@@ -11,18 +11,59 @@ type Terraform struct {
 	RequiredVersion   string                       `json:"required_version,omitempty"`
 	Experiments       []string                     `json:"experiments,omitempty"`
 	RequiredProviders map[string]*RequiredProvider `json:"required_providers,omitempty"`
+	Backend           *Backend                     `json:"backend,omitempty"`
+	Cloud             *Cloud                       `json:"cloud,omitempty"`
+	Syntax
+}
+
+// Backend is the backend block of a terraform block
+type Backend struct {
+	Type string `json:"type"`
+	// Config holds the backend settings, e.g. bucket and key of s3. Nested blocks such as
+	// assume_role or workspaces are maps; expressions keep their source text.
+	Config map[string]interface{} `json:"config,omitempty"`
+	// FlatConfig maps the dotted key paths of the settings to their leaf values, e.g.
+	// assume_role.role_arn, set when the parser runs WithFlatten
+	FlatConfig map[string]interface{} `json:"flat_config,omitempty"`
+}
+
+// Cloud is the cloud block of a terraform block, binding the configuration to HCP Terraform
+// or Terraform Enterprise workspaces
+type Cloud struct {
+	Organization string           `json:"organization,omitempty"`
+	Hostname     string           `json:"hostname,omitempty"`
+	Workspaces   *CloudWorkspaces `json:"workspaces,omitempty"`
+}
+
+// CloudWorkspaces selects a single workspace by name, or every workspace with the tags.
+// Key-value tags are rendered as key:value.
+type CloudWorkspaces struct {
+	Name    string   `json:"name,omitempty"`
+	Tags    []string `json:"tags,omitempty"`
+	Project string   `json:"project,omitempty"`
 }
 
 type RequiredProvider struct {
-	Source  string `json:"source,omitempty"`
-	Version string `json:"version,omitempty"`
+	Source               string   `json:"source,omitempty"`
+	Version              string   `json:"version,omitempty"`
+	ConfigurationAliases []string `json:"configuration_aliases,omitempty"`
+	// LockedVersion is the version selected in the dependency lock file, if any
+	LockedVersion string `json:"locked_version,omitempty"`
 }
 
-func (b *Terraform) Parse(file *hcl.File, block *hclsyntax.Block) error {
-	if len(block.Labels) != 0 {
-		return fmt.Errorf("terraform block must not have labels")
+// Flatten sets the FlatConfig of the backend
+func (b *Terraform) Flatten(file *hcl.File, block *hclsyntax.Block) {
+	if b.Backend == nil {
+		return
+	}
+	for _, nested := range block.Body.Blocks {
+		if nested.Type == "backend" {
+			b.Backend.FlatConfig = FlattenBody(file, nested.Body)
+		}
 	}
+}
 
+func (b *Terraform) Parse(file *hcl.File, block *hclsyntax.Block) error {
 	attrs := block.Body.Attributes
 
 	if requiredVersionAttr, ok := attrs["required_version"]; ok {
@@ -36,6 +77,10 @@ func (b *Terraform) Parse(file *hcl.File, block *hclsyntax.Block) error {
 	b.RequiredProviders = make(map[string]*RequiredProvider)
 	for _, blockInBlock := range block.Body.Blocks {
 		switch blockInBlock.Type {
+		case "backend":
+			b.Backend = &Backend{Type: blockInBlock.Labels[0], Config: parseBodyToMap(file, blockInBlock.Body)}
+		case "cloud":
+			b.Cloud = parseCloud(file, blockInBlock.Body)
 		case "required_providers":
 			// Parse each provider within the required_providers block
 			for providerName, attr := range blockInBlock.Body.Attributes {
@@ -47,6 +92,10 @@ func (b *Terraform) Parse(file *hcl.File, block *hclsyntax.Block) error {
 					Version: providerConfig["version"],
 				}
 
+				if aliasesExpr := findObjectItem(attr.Expr, "configuration_aliases"); aliasesExpr != nil {
+					provider.ConfigurationAliases = parseAttributeToStringList(file, &hclsyntax.Attribute{Expr: aliasesExpr})
+				}
+
 				b.RequiredProviders[providerName] = provider
 			}
 		}
@@ -54,3 +103,68 @@ func (b *Terraform) Parse(file *hcl.File, block *hclsyntax.Block) error {
 
 	return nil
 }
+
+func parseCloud(file *hcl.File, body *hclsyntax.Body) *Cloud {
+	cloud := &Cloud{}
+	if organizationAttr, ok := body.Attributes["organization"]; ok {
+		cloud.Organization = parseAttributeToString(file, organizationAttr)
+	}
+	if hostnameAttr, ok := body.Attributes["hostname"]; ok {
+		cloud.Hostname = parseAttributeToString(file, hostnameAttr)
+	}
+
+	for _, blockInBlock := range body.Blocks {
+		if blockInBlock.Type != "workspaces" {
+			continue
+		}
+		workspaces := &CloudWorkspaces{}
+		attrs := blockInBlock.Body.Attributes
+		if nameAttr, ok := attrs["name"]; ok {
+			workspaces.Name = parseAttributeToString(file, nameAttr)
+		}
+		if tagsAttr, ok := attrs["tags"]; ok {
+			// Tags are a list of names or, since Terraform 1.10, a map of key-value tags
+			if tags := parseAttributeToStringMap(file, tagsAttr); len(tags) > 0 {
+				for key, value := range tags {
+					workspaces.Tags = append(workspaces.Tags, key+":"+value)
+				}
+				sort.Strings(workspaces.Tags)
+			} else {
+				workspaces.Tags = parseAttributeToStringList(file, tagsAttr)
+			}
+		}
+		if projectAttr, ok := attrs["project"]; ok {
+			workspaces.Project = parseAttributeToString(file, projectAttr)
+		}
+		cloud.Workspaces = workspaces
+	}
+	return cloud
+}
+
+// parseBodyToMap turns the attributes and nested blocks of a settings body into a map.
+// A nested block type used more than once becomes a list of maps.
+func parseBodyToMap(file *hcl.File, body *hclsyntax.Body) map[string]interface{} {
+	if len(body.Attributes) == 0 && len(body.Blocks) == 0 {
+		return nil
+	}
+
+	settings := map[string]interface{}{}
+	for name, attr := range body.Attributes {
+		settings[name] = parseAttributeToInterface(file, attr)
+	}
+	for _, nested := range body.Blocks {
+		value := parseBodyToMap(file, nested.Body)
+		if value == nil {
+			value = map[string]interface{}{}
+		}
+		switch existing := settings[nested.Type].(type) {
+		case nil:
+			settings[nested.Type] = value
+		case map[string]interface{}:
+			settings[nested.Type] = []interface{}{existing, value}
+		case []interface{}:
+			settings[nested.Type] = append(existing, value)
+		}
+	}
+	return settings
+}
@@ -2,30 +2,53 @@ package schema
 
 import (
 	"fmt"
+	"slices"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
 )
 
 type Variable struct {
-	Name        string                `json:"name"`
-	Description string                `json:"description,omitempty"`
-	Type        string                `json:"type,omitempty"`
-	Default     interface{}           `json:"default,omitempty"`
-	Required    bool                  `json:"required"`
-	Sensitive   bool                  `json:"sensitive"`
-	Validation  []*VariableValidation `json:"validation,omitempty"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Type        string `json:"type,omitempty"`
+	// TypeConstraint is the evaluated type, with element types, object attributes and the
+	// defaults of optional attributes
+	TypeConstraint *TypeConstraint `json:"type_constraint,omitempty"`
+	Default        interface{}     `json:"default,omitempty"`
+	// FlatDefault maps the dotted key paths of an object or list default to its leaf values,
+	// set when the parser runs WithFlatten
+	FlatDefault map[string]interface{} `json:"flat_default,omitempty"`
+	// Required is set when callers must set the variable: it has no default, or a null default
+	// while nullable is false
+	Required bool `json:"required"`
+	// Sensitive is always emitted, unlike the optional flag of outputs
+	Sensitive bool `json:"sensitive"`
+	// Nullable and Ephemeral are emitted only when set
+	Nullable   *bool                 `json:"nullable,omitempty"`
+	Ephemeral  *bool                 `json:"ephemeral,omitempty"`
+	Validation []*VariableValidation `json:"validation,omitempty"`
+	Syntax
 }
 
 type VariableValidation struct {
 	Condition    string `json:"condition"`
 	ErrorMessage string `json:"error_message"`
+	// References lists the objects the condition refers to: the variable itself and, since
+	// Terraform 1.9, other variables, locals and resources
+	References []string `json:"references,omitempty"`
+	// Functions lists the functions the condition calls, e.g. can or contains
+	Functions []string `json:"functions,omitempty"`
 }
 
-func (b *Variable) Parse(file *hcl.File, block *hclsyntax.Block) error {
-	if len(block.Labels) != 1 {
-		return fmt.Errorf("variable block must have one label")
+// Flatten sets FlatDefault from an object or list default
+func (b *Variable) Flatten(file *hcl.File, block *hclsyntax.Block) {
+	if defaultAttr, ok := block.Body.Attributes["default"]; ok {
+		b.FlatDefault = FlattenExpression(file, defaultAttr.Expr)
 	}
+}
+
+func (b *Variable) Parse(file *hcl.File, block *hclsyntax.Block) error {
 	b.Name = block.Labels[0]
 
 	attrs := block.Body.Attributes
@@ -36,18 +59,29 @@ func (b *Variable) Parse(file *hcl.File, block *hclsyntax.Block) error {
 
 	if typeAttr, ok := attrs["type"]; ok {
 		b.Type = parseAttributeToString(file, typeAttr)
+		b.TypeConstraint = ParseTypeConstraint(typeAttr.Expr)
+	}
+
+	if sensitiveAttr, ok := attrs["sensitive"]; ok {
+		b.Sensitive = parseAttributeToBool(file, sensitiveAttr)
+	}
+
+	if nullableAttr, ok := attrs["nullable"]; ok {
+		b.Nullable = parseAttributeToOptionalBool(file, nullableAttr)
 	}
 
+	if ephemeralAttr, ok := attrs["ephemeral"]; ok {
+		b.Ephemeral = parseAttributeToOptionalBool(file, ephemeralAttr)
+	}
+
+	// Terraform treats a null default of a non-nullable variable as no default
 	if defaultAttr, ok := attrs["default"]; ok {
 		b.Default = parseAttributeToInterface(file, defaultAttr)
+		b.Required = !b.IsNullable() && isNullLiteral(defaultAttr.Expr)
 	} else {
 		b.Required = true
 	}
 
-	if sensitiveAttr, ok := attrs["sensitive"]; ok {
-		b.Sensitive = parseAttributeToBool(file, sensitiveAttr)
-	}
-
 	for _, blockInBlock := range block.Body.Blocks {
 		switch blockInBlock.Type {
 		case "validation":
@@ -63,11 +97,35 @@ func (b *Variable) Parse(file *hcl.File, block *hclsyntax.Block) error {
 	return nil
 }
 
+// IsSensitive reports whether the variable is marked sensitive
+func (b *Variable) IsSensitive() bool {
+	return b.Sensitive
+}
+
+// IsNullable reports whether callers may set the variable to null, which Terraform allows unless
+// nullable is false
+func (b *Variable) IsNullable() bool {
+	return b.Nullable == nil || *b.Nullable
+}
+
+// IsEphemeral reports whether the variable is ephemeral (Terraform 1.10+): its value is never
+// persisted in the plan or state
+func (b *Variable) IsEphemeral() bool {
+	return b.Ephemeral != nil && *b.Ephemeral
+}
+
+// Address returns the variable reference address, e.g. var.region
+func (b *Variable) Address() string {
+	return "var." + b.Name
+}
+
 func (b *VariableValidation) Parse(file *hcl.File, block *hclsyntax.Block) error {
 	attrs := block.Body.Attributes
 
 	if conditionAttr, ok := attrs["condition"]; ok {
 		b.Condition = parseAttributeToString(file, conditionAttr)
+		b.References = collectReferences(&hclsyntax.Body{Attributes: pickAttributes(block.Body, "condition")})
+		b.Functions = collectFunctions(conditionAttr.Expr)
 	} else {
 		return fmt.Errorf("condition is missing in validation block")
 	}
@@ -80,3 +138,14 @@ func (b *VariableValidation) Parse(file *hcl.File, block *hclsyntax.Block) error
 
 	return nil
 }
+
+// Validates reports whether the condition refers to the variable named name
+func (b *VariableValidation) Validates(name string) bool {
+	return slices.Contains(b.References, "var."+name)
+}
+
+// isNullLiteral reports whether expr is the literal null
+func isNullLiteral(expr hclsyntax.Expression) bool {
+	lv, ok := expr.(*hclsyntax.LiteralValueExpr)
+	return ok && lv.Val.IsNull()
+}
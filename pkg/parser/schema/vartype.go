@@ -0,0 +1,123 @@
+package schema
+
+import (
+	"strconv"
+
+	"github.com/hashicorp/hcl/v2/ext/typeexpr"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Kinds of a type constraint
+const (
+	TypeString = "string"
+	TypeNumber = "number"
+	TypeBool   = "bool"
+	TypeAny    = "any"
+	TypeList   = "list"
+	TypeSet    = "set"
+	TypeMap    = "map"
+	TypeObject = "object"
+	TypeTuple  = "tuple"
+)
+
+// TypeConstraint is the structured form of a variable type, as Terraform evaluates it. Only the
+// fields of its kind are set.
+type TypeConstraint struct {
+	Kind string `json:"kind"`
+	// Element is the element type of a list, set or map
+	Element *TypeConstraint `json:"element,omitempty"`
+	// Attributes are the attributes of an object
+	Attributes map[string]*TypeAttribute `json:"attributes,omitempty"`
+	// Elements are the element types of a tuple
+	Elements []*TypeConstraint `json:"elements,omitempty"`
+}
+
+// TypeAttribute is an object attribute; optional attributes may declare a default
+type TypeAttribute struct {
+	TypeConstraint
+	Optional bool        `json:"optional,omitempty"`
+	Default  interface{} `json:"default,omitempty"`
+}
+
+// ParseTypeConstraint evaluates a type expression such as
+// list(object({ name = string, port = optional(number, 80) })). It returns nil when the
+// expression is not a valid type.
+func ParseTypeConstraint(expr hclsyntax.Expression) *TypeConstraint {
+	ty, defaults, diags := typeexpr.TypeConstraintWithDefaults(expr)
+	if diags.HasErrors() {
+		return nil
+	}
+	return newTypeConstraint(ty, defaults)
+}
+
+func newTypeConstraint(ty cty.Type, defaults *typeexpr.Defaults) *TypeConstraint {
+	child := func(key string) *typeexpr.Defaults {
+		if defaults == nil {
+			return nil
+		}
+		return defaults.Children[key]
+	}
+
+	switch {
+	case ty == cty.String:
+		return &TypeConstraint{Kind: TypeString}
+	case ty == cty.Number:
+		return &TypeConstraint{Kind: TypeNumber}
+	case ty == cty.Bool:
+		return &TypeConstraint{Kind: TypeBool}
+	case ty.IsListType():
+		return &TypeConstraint{Kind: TypeList, Element: newTypeConstraint(ty.ElementType(), child(""))}
+	case ty.IsSetType():
+		return &TypeConstraint{Kind: TypeSet, Element: newTypeConstraint(ty.ElementType(), child(""))}
+	case ty.IsMapType():
+		return &TypeConstraint{Kind: TypeMap, Element: newTypeConstraint(ty.ElementType(), child(""))}
+	case ty.IsObjectType():
+		tc := &TypeConstraint{Kind: TypeObject, Attributes: map[string]*TypeAttribute{}}
+		for name, attrType := range ty.AttributeTypes() {
+			attr := &TypeAttribute{TypeConstraint: *newTypeConstraint(attrType, child(name)), Optional: ty.AttributeOptional(name)}
+			if defaults != nil {
+				if val, ok := defaults.DefaultValues[name]; ok {
+					attr.Default = goValue(val)
+				}
+			}
+			tc.Attributes[name] = attr
+		}
+		return tc
+	case ty.IsTupleType():
+		tc := &TypeConstraint{Kind: TypeTuple, Elements: []*TypeConstraint{}}
+		for i, elementType := range ty.TupleElementTypes() {
+			tc.Elements = append(tc.Elements, newTypeConstraint(elementType, child(strconv.Itoa(i))))
+		}
+		return tc
+	default:
+		return &TypeConstraint{Kind: TypeAny}
+	}
+}
+
+// goValue converts a known cty value into the Go value used in the JSON output, with lists,
+// sets and tuples as slices and maps and objects as maps
+func goValue(val cty.Value) interface{} {
+	if val.IsNull() || !val.IsKnown() {
+		return nil
+	}
+
+	ty := val.Type()
+	switch {
+	case ty.IsListType(), ty.IsSetType(), ty.IsTupleType():
+		list := []interface{}{}
+		for it := val.ElementIterator(); it.Next(); {
+			_, element := it.Element()
+			list = append(list, goValue(element))
+		}
+		return list
+	case ty.IsMapType(), ty.IsObjectType():
+		m := map[string]interface{}{}
+		for it := val.ElementIterator(); it.Next(); {
+			key, element := it.Element()
+			m[key.AsString()] = goValue(element)
+		}
+		return m
+	}
+	return literalValue(val)
+}
@@ -3,21 +3,50 @@ package parser
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"strings"
 
 	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser/schema"
 )
 
 type TerraformConfig struct {
-	Variables []*schema.Variable  `json:"variables,omitempty"`
-	Outputs   []*schema.Output    `json:"outputs,omitempty"`
-	Terraform []*schema.Terraform `json:"terraform,omitempty"`
+	// Fingerprint is a hash of the configuration content that does not change with
+	// formatting, comments or the way blocks are spread over files
+	Fingerprint string `json:"fingerprint,omitempty"`
+	// Annotations is the module metadata declared in tfparser blocks or comments (owner, tier, ...)
+	Annotations map[string]string    `json:"annotations,omitempty"`
+	Variables   []*schema.Variable   `json:"variables,omitempty"`
+	Outputs     []*schema.Output     `json:"outputs,omitempty"`
+	Terraform   []*schema.Terraform  `json:"terraform,omitempty"`
+	Modules     []*schema.Module     `json:"modules,omitempty"`
+	Resources   []*schema.Resource   `json:"resources,omitempty"`
+	DataSources []*schema.DataSource `json:"data_sources,omitempty"`
+	// EphemeralResources are kept apart from resources since their values are never persisted
+	EphemeralResources []*schema.EphemeralResource `json:"ephemeral_resources,omitempty"`
+	Providers          []*schema.Provider          `json:"providers,omitempty"`
+	Imports            []*schema.Import            `json:"imports,omitempty"`
+	Locals             []*schema.Local             `json:"locals,omitempty"`
+	// LockedProviders are the providers of the dependency lock file (.terraform.lock.hcl)
+	LockedProviders []*schema.LockedProvider `json:"locked_providers,omitempty"`
+	// Diagnostics are the problems found by block validation (see Parser.WithValidateBlocks)
+	Diagnostics []*Diagnostic `json:"diagnostics,omitempty"`
 }
 
 func generateTerraformConfig(blocks []schema.Block) *TerraformConfig {
 	tfconfig := TerraformConfig{
-		Variables: make([]*schema.Variable, 0),
-		Outputs:   make([]*schema.Output, 0),
-		Terraform: make([]*schema.Terraform, 0),
+		Variables:          make([]*schema.Variable, 0),
+		Outputs:            make([]*schema.Output, 0),
+		Terraform:          make([]*schema.Terraform, 0),
+		Modules:            make([]*schema.Module, 0),
+		Resources:          make([]*schema.Resource, 0),
+		DataSources:        make([]*schema.DataSource, 0),
+		EphemeralResources: make([]*schema.EphemeralResource, 0),
+		Providers:          make([]*schema.Provider, 0),
+		Imports:            make([]*schema.Import, 0),
+		Locals:             make([]*schema.Local, 0),
+		Annotations:        make(map[string]string),
 	}
 
 	for _, block := range blocks {
@@ -28,6 +57,22 @@ func generateTerraformConfig(blocks []schema.Block) *TerraformConfig {
 			tfconfig.Outputs = append(tfconfig.Outputs, b)
 		case *schema.Terraform:
 			tfconfig.Terraform = append(tfconfig.Terraform, b)
+		case *schema.Module:
+			tfconfig.Modules = append(tfconfig.Modules, b)
+		case *schema.Resource:
+			tfconfig.Resources = append(tfconfig.Resources, b)
+		case *schema.DataSource:
+			tfconfig.DataSources = append(tfconfig.DataSources, b)
+		case *schema.EphemeralResource:
+			tfconfig.EphemeralResources = append(tfconfig.EphemeralResources, b)
+		case *schema.Provider:
+			tfconfig.Providers = append(tfconfig.Providers, b)
+		case *schema.Import:
+			tfconfig.Imports = append(tfconfig.Imports, b)
+		case *schema.Locals:
+			tfconfig.Locals = append(tfconfig.Locals, b.Values...)
+		case *schema.Annotations:
+			maps.Copy(tfconfig.Annotations, b.Values)
 		}
 	}
 
@@ -49,3 +94,66 @@ func (t *TerraformConfig) Summary(pretty bool) ([]byte, error) {
 
 	return bytes.TrimSpace(buf.Bytes()), nil
 }
+
+// LoadSummary reads a configuration back from the JSON written by Summary, e.g. to compare a
+// saved summary with a new version without parsing the old one again. Numbers are restored
+// to the types the parser produces, so values equal the parsed ones. Summaries written with
+// camelCase field names are rejected since their fields would be silently lost.
+func LoadSummary(data []byte) (*TerraformConfig, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("failed to read summary: %w", err)
+	}
+	for name := range fields {
+		if strings.ToLower(name) != name {
+			return nil, fmt.Errorf("failed to read summary: field %q is not snake_case; save summaries with --json-casing snake", name)
+		}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	// Start from an empty configuration so that omitted collections are empty like parsed ones
+	tfconfig := generateTerraformConfig(nil)
+	if err := decoder.Decode(tfconfig); err != nil {
+		return nil, fmt.Errorf("failed to read summary: %w", err)
+	}
+	restoreNumbers(reflect.ValueOf(tfconfig))
+	return tfconfig, nil
+}
+
+// restoreNumbers applies schema.JSONValue to every interface value reachable from v
+func restoreNumbers(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if !v.IsNil() {
+			restoreNumbers(v.Elem())
+		}
+	case reflect.Struct:
+		for i := range v.NumField() {
+			if v.Type().Field(i).IsExported() {
+				restoreNumbers(v.Field(i))
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := range v.Len() {
+			restoreNumbers(v.Index(i))
+		}
+	case reflect.Map:
+		if v.Type().Elem().Kind() == reflect.Interface {
+			for _, key := range v.MapKeys() {
+				// nil values stay as they are; a zero reflect.Value would delete the key
+				if value := schema.JSONValue(v.MapIndex(key).Interface()); value != nil {
+					v.SetMapIndex(key, reflect.ValueOf(value))
+				}
+			}
+			return
+		}
+		for _, key := range v.MapKeys() {
+			restoreNumbers(v.MapIndex(key))
+		}
+	case reflect.Interface:
+		if !v.IsNil() && v.CanSet() {
+			v.Set(reflect.ValueOf(schema.JSONValue(v.Interface())))
+		}
+	}
+}
@@ -1,14 +1,24 @@
 package parser
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io/fs"
+	"maps"
 	"os"
+	"reflect"
+	"slices"
 	"strings"
 	"testing"
 	"testing/fstest"
 
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/events"
 	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/filesystem"
 	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser/schema"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/source"
 )
 
 // testFileSystem wraps fstest.MapFS to implement filesystem.FileReader interface
@@ -73,6 +83,9 @@ type TestExpectations struct {
 	VariableCount     *int
 	OutputCount       *int
 	TerraformCount    *int
+	ModuleCount       *int
+	ResourceCount     *int
+	DataSourceCount   *int
 	Variables         map[string]*VariableExpectation
 	Outputs           map[string]*OutputExpectation
 	TerraformSettings *TerraformExpectation
@@ -87,7 +100,10 @@ type VariableExpectation struct {
 }
 
 type OutputExpectation struct {
-	Sensitive *bool
+	Sensitive  *bool
+	Ephemeral  *bool
+	Value      *string
+	References []string
 }
 
 type TerraformExpectation struct {
@@ -123,6 +139,15 @@ func validateExpectations(t *testing.T, config *TerraformConfig, expectations Te
 	if expectations.TerraformCount != nil {
 		validateCount(t, config.Terraform, *expectations.TerraformCount, "terraform blocks")
 	}
+	if expectations.ModuleCount != nil {
+		validateCount(t, config.Modules, *expectations.ModuleCount, "modules")
+	}
+	if expectations.ResourceCount != nil {
+		validateCount(t, config.Resources, *expectations.ResourceCount, "resources")
+	}
+	if expectations.DataSourceCount != nil {
+		validateCount(t, config.DataSources, *expectations.DataSourceCount, "data sources")
+	}
 
 	// Validate specific variables
 	for name, expectation := range expectations.Variables {
@@ -192,8 +217,17 @@ func validateVariableExpectation(t *testing.T, variable *schema.Variable, expect
 
 func validateOutputExpectation(t *testing.T, output *schema.Output, expectation *OutputExpectation) {
 	t.Helper()
-	if expectation.Sensitive != nil && output.Sensitive != *expectation.Sensitive {
-		t.Errorf("Output %s: expected sensitive=%t, got %t", output.Name, *expectation.Sensitive, output.Sensitive)
+	if expectation.Sensitive != nil && (output.Sensitive == nil || *output.Sensitive != *expectation.Sensitive) {
+		t.Errorf("Output %s: expected sensitive=%t, got %v", output.Name, *expectation.Sensitive, output.Sensitive)
+	}
+	if expectation.Ephemeral != nil && (output.Ephemeral == nil || *output.Ephemeral != *expectation.Ephemeral) {
+		t.Errorf("Output %s: expected ephemeral=%t, got %v", output.Name, *expectation.Ephemeral, output.Ephemeral)
+	}
+	if expectation.Value != nil && output.Value != *expectation.Value {
+		t.Errorf("Output %s: expected value %s, got %s", output.Name, *expectation.Value, output.Value)
+	}
+	if expectation.References != nil && !reflect.DeepEqual(output.References, expectation.References) {
+		t.Errorf("Output %s: expected references %v, got %v", output.Name, expectation.References, output.References)
 	}
 }
 
@@ -455,10 +489,18 @@ output "map_access" {
 			expectations: TestExpectations{
 				OutputCount: ptr(3),
 				Outputs: map[string]*OutputExpectation{
-					"computed":           {},
-					"complex_expression": {},
+					"computed": {
+						Value:      ptr(`"prefix-${var.string}-suffix"`),
+						References: []string{"var.string"},
+					},
+					"complex_expression": {
+						Value:      ptr(`length(var.list) > 0 ? var.list[0] : "default"`),
+						References: []string{"var.list"},
+					},
 					"map_access": {
-						Sensitive: ptr(false),
+						Sensitive:  ptr(false),
+						Value:      ptr(`var.map["key"]`),
+						References: []string{"var.map"},
 					},
 				},
 			},
@@ -496,6 +538,38 @@ output "non_sensitive" {
 				},
 			},
 		},
+		{
+			name: "Ephemeral outputs with dependencies",
+			files: map[string]string{
+				"outputs.tf": `
+output "token" {
+  value      = ephemeral.random_password.db.result
+  ephemeral  = true
+  depends_on = [module.network]
+}
+
+output "endpoint" {
+  value = {
+    host = aws_db_instance.this.address
+    port = module.db.port
+  }
+}`,
+			},
+			expectations: TestExpectations{
+				OutputCount: ptr(2),
+				Outputs: map[string]*OutputExpectation{
+					"token": {
+						Ephemeral:  ptr(true),
+						Value:      ptr("ephemeral.random_password.db.result"),
+						References: []string{"ephemeral.random_password.db"},
+					},
+					"endpoint": {
+						Value:      ptr("{\n    host = aws_db_instance.this.address\n    port = module.db.port\n  }"),
+						References: []string{"aws_db_instance.this", "module.db"},
+					},
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -592,6 +666,621 @@ terraform {
 	}
 }
 
+func TestBackend(t *testing.T) {
+	testFS := newTestFileSystem(map[string]string{
+		"backend.tf": `
+terraform {
+  backend "s3" {
+    bucket       = "tfstate-prod"
+    key          = "network/terraform.tfstate"
+    region       = "eu-west-1"
+    encrypt      = true
+    use_lockfile = true
+    max_retries  = 5
+
+    assume_role {
+      role_arn = "arn:aws:iam::123456789012:role/tfstate"
+    }
+  }
+}`,
+	})
+
+	config, err := NewParser(testFS, Simple).ParseTerraformWorkspace(".")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	backend := config.Terraform[0].Backend
+	if backend == nil || backend.Type != "s3" {
+		t.Fatalf("Expected an s3 backend, got %+v", backend)
+	}
+	expected := map[string]interface{}{
+		"bucket":       "tfstate-prod",
+		"key":          "network/terraform.tfstate",
+		"region":       "eu-west-1",
+		"encrypt":      true,
+		"use_lockfile": true,
+		"max_retries":  int64(5),
+		"assume_role":  map[string]interface{}{"role_arn": "arn:aws:iam::123456789012:role/tfstate"},
+	}
+	if !reflect.DeepEqual(backend.Config, expected) {
+		t.Errorf("Expected config %v, got %v", expected, backend.Config)
+	}
+}
+
+func TestJSONSyntax(t *testing.T) {
+	testFS := newTestFileSystem(map[string]string{
+		"main.tf": `
+variable "name" {
+  type = string
+}`,
+		"generated.tf.json": `{
+  "//": "Generated by a CDK synthesizer",
+  "variable": {
+    "tags": {
+      "type": "map(string)",
+      "default": {"team": "platform"},
+      "description": "Tags of the resources"
+    }
+  },
+  "module": {
+    "vpc": {
+      "source": "terraform-aws-modules/vpc/aws",
+      "version": "~> 5.0",
+      "name": "${var.name}"
+    }
+  },
+  "resource": {
+    "aws_s3_bucket": {
+      "logs": {"bucket": "${var.name}-logs", "count": 2}
+    }
+  },
+  "output": {
+    "bucket": {"value": "${aws_s3_bucket.logs[0].id}", "depends_on": ["module.vpc"]}
+  }
+}`,
+	})
+
+	config, err := NewParser(testFS, Detail).ParseTerraformWorkspace(".")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(config.Variables) != 2 {
+		t.Fatalf("Expected the variables of both files, got %d", len(config.Variables))
+	}
+	var tags *schema.Variable
+	for _, variable := range config.Variables {
+		if variable.Name == "tags" {
+			tags = variable
+		}
+	}
+	if tags == nil || tags.Type != "map(string)" || tags.Description != "Tags of the resources" {
+		t.Errorf("Unexpected variable %+v", tags)
+	}
+
+	if len(config.Modules) != 1 || config.Modules[0].Source != "terraform-aws-modules/vpc/aws" || config.Modules[0].Version != "~> 5.0" {
+		t.Errorf("Unexpected modules %+v", config.Modules)
+	}
+	if len(config.Resources) != 1 || config.Resources[0].Address() != "aws_s3_bucket.logs" {
+		t.Errorf("Unexpected resources %+v", config.Resources)
+	}
+	if len(config.Outputs) != 1 || !slices.Contains(config.Outputs[0].References, "aws_s3_bucket.logs") {
+		t.Errorf("Unexpected outputs %+v", config.Outputs)
+	}
+
+	invalid := newTestFileSystem(map[string]string{"broken.tf.json": `{"variable": {"x": `})
+	if _, err := NewParser(invalid, Simple).ParseTerraformWorkspace("."); err == nil || !strings.Contains(err.Error(), "JSON syntax") {
+		t.Errorf("Expected a JSON syntax error, got %v", err)
+	}
+}
+
+func TestJSONSyntaxListsOfObjects(t *testing.T) {
+	testFS := newTestFileSystem(map[string]string{
+		"main.tf.json": `{
+  "variable": {
+    "rules": {
+      "default": [
+        {"port": 443},
+        {"port": 80}
+      ]
+    }
+  },
+  "locals": {
+    "subnets": [{"cidr": "10.0.0.0/24"}]
+  },
+  "resource": {
+    "aws_security_group": {
+      "web": {
+        "name": "web",
+        "ingress": [
+          {"from_port": 443}
+        ]
+      }
+    }
+  },
+  "output": {
+    "rules": {
+      "value": [{"port": "${var.rules[0].port}"}]
+    }
+  }
+}`,
+	})
+
+	config, err := NewParser(testFS, Detail).WithPositions(true).ParseTerraformWorkspace(".")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(config.Variables) != 1 {
+		t.Fatalf("Expected one variable, got %d", len(config.Variables))
+	}
+	if def, ok := config.Variables[0].Default.(string); !ok || !strings.Contains(def, "port = 443") || !strings.Contains(def, "port = 80") {
+		t.Errorf("Expected the list of objects as default, got %#v", config.Variables[0].Default)
+	}
+	if len(config.Locals) != 1 || config.Locals[0].Name != "subnets" {
+		t.Errorf("Expected the subnets local value, got %+v", config.Locals)
+	}
+	if len(config.Outputs) != 1 || !slices.Contains(config.Outputs[0].References, "var.rules") {
+		t.Errorf("Expected the output value to be kept, got %+v", config.Outputs)
+	}
+
+	positions := map[string]*schema.SourceRange{
+		config.Variables[0].Address(): config.Variables[0].SourceRange,
+		config.Locals[0].Address():    config.Locals[0].SourceRange,
+		config.Resources[0].Address(): config.Resources[0].SourceRange,
+		config.Outputs[0].Address():   config.Outputs[0].SourceRange,
+	}
+	for address, want := range map[string]int{"var.rules": 3, "local.subnets": 11, "aws_security_group.web": 15, "output.rules": 24} {
+		if got := positions[address]; got == nil || got.StartLine != want {
+			t.Errorf("Expected %s to start on line %d, got %+v", address, want, got)
+		}
+	}
+}
+
+func TestParseHCLBytes(t *testing.T) {
+	p := NewParser(nil, Simple)
+
+	config, err := p.ParseHCLBytes("buffer.tf", []byte(`
+variable "name" {
+  type = string
+}`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(config.Variables) != 1 || config.Variables[0].Name != "name" {
+		t.Errorf("Unexpected variables %+v", config.Variables)
+	}
+
+	// The same file name with new content, as an editor buffer being edited
+	config, err = p.ParseHCLBytes("buffer.tf", []byte(`output "id" { value = "x" }`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(config.Variables) != 0 || len(config.Outputs) != 1 {
+		t.Errorf("Expected the new content to be parsed, got %+v", config)
+	}
+
+	config, err = p.ParseHCLBytes("buffer.tf.json", []byte(`{"output": {"id": {"value": "x"}}}`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(config.Outputs) != 1 || config.Outputs[0].Name != "id" {
+		t.Errorf("Unexpected outputs %+v", config.Outputs)
+	}
+
+	if _, err := p.ParseHCLBytes("broken.tf", []byte(`variable {`)); err == nil {
+		t.Error("Expected a syntax error")
+	}
+}
+
+func TestOverrideFiles(t *testing.T) {
+	testFS := newTestFileSystem(map[string]string{
+		"main.tf": `
+terraform {
+  required_version = ">= 1.5"
+
+  required_providers {
+    aws    = { source = "hashicorp/aws", version = "~> 5.0" }
+    random = { source = "hashicorp/random", version = "~> 3.0" }
+  }
+
+  backend "s3" {
+    bucket = "state"
+  }
+}
+
+variable "region" {
+  type    = string
+  default = "us-east-1"
+}
+
+locals {
+  name = "app"
+  env  = "dev"
+}
+
+resource "aws_instance" "web" {
+  ami           = "ami-123"
+  instance_type = "t3.micro"
+
+  ebs_block_device {
+    device_name = "/dev/sda"
+  }
+  ebs_block_device {
+    device_name = "/dev/sdb"
+  }
+
+  lifecycle {
+    create_before_destroy = true
+  }
+}
+`,
+		"override.tf": `
+variable "region" {
+  default = "eu-west-1"
+}
+
+locals {
+  env = "prod"
+}
+
+resource "aws_instance" "web" {
+  instance_type = "m5.large"
+
+  ebs_block_device {
+    device_name = "/dev/sdc"
+  }
+
+  lifecycle {
+    ignore_changes = [tags]
+  }
+}
+`,
+		"backend_override.tf.json": `{
+  "terraform": {
+    "required_providers": {"aws": {"source": "hashicorp/aws", "version": "~> 6.0"}},
+    "cloud": {"organization": "acme"}
+  }
+}`,
+	})
+
+	config, err := NewParser(testFS, Detail).WithAST(true).ParseTerraformWorkspace(".")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(config.Variables) != 1 || config.Variables[0].Type != "string" || config.Variables[0].Default != "eu-west-1" {
+		t.Errorf("Expected the overridden default with the base type, got %+v", config.Variables)
+	}
+	for _, local := range config.Locals {
+		if local.Name == "env" && local.AST.Parts[0].Value != "prod" {
+			t.Errorf("Expected local.env to be overridden, got %+v", local.AST)
+		}
+	}
+	if len(config.Resources) != 1 {
+		t.Fatalf("Expected the resource once, got %d", len(config.Resources))
+	}
+
+	ast := config.Resources[0].AST
+	if ast.Attributes["ami"] == nil || ast.Attributes["instance_type"].Parts[0].Value != "m5.large" {
+		t.Errorf("Unexpected merged arguments %+v", ast.Attributes)
+	}
+	devices, lifecycle := 0, 0
+	for _, block := range ast.Blocks {
+		switch block.Type {
+		case "ebs_block_device":
+			devices++
+			if block.Attributes["device_name"].Parts[0].Value != "/dev/sdc" {
+				t.Errorf("Expected the override device to replace the base ones, got %+v", block.Attributes)
+			}
+		case "lifecycle":
+			lifecycle++
+			if block.Attributes["create_before_destroy"] == nil || block.Attributes["ignore_changes"] == nil {
+				t.Errorf("Expected the lifecycle arguments to be merged, got %+v", block.Attributes)
+			}
+		}
+	}
+	if devices != 1 || lifecycle != 1 {
+		t.Errorf("Expected one device and one lifecycle block, got %d and %d", devices, lifecycle)
+	}
+
+	terraform := config.Terraform[0]
+	if terraform.RequiredVersion != ">= 1.5" || terraform.RequiredProviders["aws"].Version != "~> 6.0" || terraform.RequiredProviders["random"] == nil {
+		t.Errorf("Unexpected merged terraform settings %+v", terraform)
+	}
+	if terraform.Backend != nil || terraform.Cloud == nil || terraform.Cloud.Organization != "acme" {
+		t.Errorf("Expected the cloud block to replace the backend, got %+v and %+v", terraform.Backend, terraform.Cloud)
+	}
+
+	missing := newTestFileSystem(map[string]string{
+		"main.tf":     `variable "a" {}`,
+		"override.tf": `variable "b" { default = 1 }`,
+	})
+	if _, err := NewParser(missing, Simple).ParseTerraformWorkspace("."); err == nil || !strings.Contains(err.Error(), "missing base variable.b block") {
+		t.Errorf("Expected a missing base block error, got %v", err)
+	}
+}
+
+func TestOverridePositions(t *testing.T) {
+	testFS := newTestFileSystem(map[string]string{
+		"main.tf": `variable "region" {
+  type = string
+}
+
+resource "aws_instance" "web" {
+  ami = "ami-123"
+}
+`,
+		"override.tf": `# Pin the region
+variable "region" {
+  type = string
+  default = "eu-west-1"
+  description = "Region of ${local.env}"
+}
+`,
+	})
+
+	p := NewParser(testFS, Detail).WithPositions(true)
+	config, err := p.ParseTerraformWorkspace(".")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Arguments merged into the variable must not move the blocks below it
+	if got := config.Resources[0].SourceRange; got == nil || *got != (schema.SourceRange{File: "main.tf", StartLine: 5, EndLine: 7}) {
+		t.Errorf("Expected the resource at main.tf:5-7, got %+v", got)
+	}
+	if got := config.Variables[0].SourceRange; got == nil || *got != (schema.SourceRange{File: "main.tf", StartLine: 1, EndLine: 3}) {
+		t.Errorf("Expected the variable at main.tf:1-3, got %+v", got)
+	}
+	if config.Variables[0].Default != "eu-west-1" || config.Variables[0].Description != `"Region of ${local.env}"` {
+		t.Errorf("Expected the overridden arguments, got %+v", config.Variables[0])
+	}
+	if config.Variables[0].Comment != "" {
+		t.Errorf("Expected no comment from the override file, got %q", config.Variables[0].Comment)
+	}
+
+	idx, err := p.IndexTraversals(".")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	usages := idx.Lookup("local.env")
+	if len(usages) != 1 || usages[0].File != "override.tf" || usages[0].Line != 5 || usages[0].Traversal != "local.env" {
+		t.Errorf("Expected local.env used at override.tf:5, got %+v", usages)
+	}
+}
+
+func TestCloud(t *testing.T) {
+	testFS := newTestFileSystem(map[string]string{
+		"cloud.tf": `
+terraform {
+  cloud {
+    organization = "acme"
+    hostname     = "tfe.acme.internal"
+
+    workspaces {
+      tags    = ["networking", "prod"]
+      project = "platform"
+    }
+  }
+}`,
+		"other/cloud.tf": `
+terraform {
+  cloud {
+    organization = "acme"
+
+    workspaces {
+      name = "network-prod"
+    }
+  }
+}`,
+	})
+
+	p := NewParser(testFS, Simple)
+	config, err := p.ParseTerraformWorkspace(".")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	cloud := config.Terraform[0].Cloud
+	if cloud == nil || cloud.Organization != "acme" || cloud.Hostname != "tfe.acme.internal" {
+		t.Fatalf("Unexpected cloud block %+v", cloud)
+	}
+	if cloud.Workspaces == nil || !slices.Equal(cloud.Workspaces.Tags, []string{"networking", "prod"}) || cloud.Workspaces.Project != "platform" {
+		t.Errorf("Unexpected workspaces %+v", cloud.Workspaces)
+	}
+
+	config, err = p.ParseTerraformWorkspace("other")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if workspaces := config.Terraform[0].Cloud.Workspaces; workspaces == nil || workspaces.Name != "network-prod" {
+		t.Errorf("Unexpected workspaces %+v", workspaces)
+	}
+}
+
+func TestValidationReferences(t *testing.T) {
+	testFS := newTestFileSystem(map[string]string{
+		"variables.tf": `
+variable "min_size" {
+  type = number
+}
+
+variable "max_size" {
+  type = number
+
+  validation {
+    condition     = var.max_size >= var.min_size && can(tonumber(var.max_size))
+    error_message = "max_size must be at least min_size."
+  }
+
+  validation {
+    condition     = alltrue([for size in [var.max_size] : size <= local.limit])
+    error_message = "max_size is above the limit."
+  }
+}`,
+	})
+
+	config, err := NewParser(testFS, Simple).ParseTerraformWorkspace(".")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	i := slices.IndexFunc(config.Variables, func(v *schema.Variable) bool { return v.Name == "max_size" })
+	if i < 0 || len(config.Variables[i].Validation) != 2 {
+		t.Fatalf("Expected max_size with 2 validations, got %+v", config.Variables)
+	}
+	first, second := config.Variables[i].Validation[0], config.Variables[i].Validation[1]
+	if !slices.Equal(first.References, []string{"var.max_size", "var.min_size"}) || !slices.Equal(first.Functions, []string{"can", "tonumber"}) {
+		t.Errorf("Unexpected references %v and functions %v", first.References, first.Functions)
+	}
+	if !slices.Equal(second.References, []string{"local.limit", "var.max_size"}) || !slices.Equal(second.Functions, []string{"alltrue"}) {
+		t.Errorf("Unexpected references %v and functions %v", second.References, second.Functions)
+	}
+	if !first.Validates("min_size") || second.Validates("min_size") {
+		t.Error("Expected only the first validation to validate min_size")
+	}
+}
+
+func TestOutputPreconditions(t *testing.T) {
+	testFS := newTestFileSystem(map[string]string{
+		"outputs.tf": `
+output "endpoint" {
+  value = aws_lb.this.dns_name
+
+  precondition {
+    condition     = length(aws_lb.this.dns_name) > 0 && var.public
+    error_message = "The load balancer has no DNS name."
+  }
+}
+
+output "name" {
+  value = var.name
+}`,
+	})
+
+	config, err := NewParser(testFS, Simple).ParseTerraformWorkspace(".")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	outputs := map[string]*schema.Output{}
+	for _, output := range config.Outputs {
+		outputs[output.Name] = output
+	}
+	if len(outputs["name"].Preconditions) != 0 {
+		t.Errorf("Expected no preconditions for output name, got %+v", outputs["name"].Preconditions)
+	}
+	if len(outputs["endpoint"].Preconditions) != 1 {
+		t.Fatalf("Expected 1 precondition for output endpoint, got %+v", outputs["endpoint"].Preconditions)
+	}
+	precondition := outputs["endpoint"].Preconditions[0]
+	if precondition.Condition != "length(aws_lb.this.dns_name) > 0 && var.public" || precondition.ErrorMessage != "The load balancer has no DNS name." {
+		t.Errorf("Unexpected precondition %+v", precondition)
+	}
+	if !slices.Equal(precondition.References, []string{"aws_lb.this", "var.public"}) || !slices.Equal(precondition.Functions, []string{"length"}) {
+		t.Errorf("Unexpected references %v and functions %v", precondition.References, precondition.Functions)
+	}
+
+	_, err = NewParser(newTestFileSystem(map[string]string{
+		"outputs.tf": `
+output "endpoint" {
+  value = "x"
+
+  precondition {
+    condition = true
+  }
+}`,
+	}), Simple).ParseTerraformWorkspace(".")
+	if err == nil || !strings.Contains(err.Error(), "error parsing precondition for output endpoint") {
+		t.Errorf("Expected a precondition error, got %v", err)
+	}
+}
+
+func TestParseTerraformWorkspaces(t *testing.T) {
+	testFS := newTestFileSystem(map[string]string{
+		"main.tf":                      `variable "root" {}`,
+		"envs/prod/main.tf":            `variable "env" {}`,
+		"envs/prod/README.md":          `# prod`,
+		"modules/vpc/variables.tf":     `variable "cidr" {}`,
+		"modules/vpc/outputs.tf":       `output "vpc_id" { value = "id" }`,
+		".terraform/modules/x/main.tf": `variable "ignored" {}`,
+		"docs/index.md":                `# docs`,
+	})
+
+	workspaces, err := NewParser(testFS, Simple).ParseTerraformWorkspaces(".")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	paths := []string{}
+	for path := range workspaces {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+	if !slices.Equal(paths, []string{".", "envs/prod", "modules/vpc"}) {
+		t.Fatalf("Unexpected workspaces %v", paths)
+	}
+	if vpc := workspaces["modules/vpc"]; len(vpc.Variables) != 1 || len(vpc.Outputs) != 1 {
+		t.Errorf("Unexpected modules/vpc configuration %+v", vpc)
+	}
+}
+
+func TestIgnoreFile(t *testing.T) {
+	testFS, err := filesystem.WithIgnoreFile(newTestFileSystem(map[string]string{
+		".tfparserignore": `
+# Examples are not deployed
+examples/
+*.generated.tf
+!keep.generated.tf
+/legacy
+**/fixtures
+`,
+		"main.tf":                      `variable "root" {}`,
+		"data.generated.tf":            `variable "generated" {}`,
+		"keep.generated.tf":            `variable "kept" {}`,
+		"examples/basic/main.tf":       `variable "example" {}`,
+		"legacy/main.tf":               `variable "legacy" {}`,
+		"modules/legacy/main.tf":       `variable "nested_legacy" {}`,
+		"modules/vpc/fixtures/a.tf":    `variable "fixture" {}`,
+		"modules/vpc/main.tf":          `variable "cidr" {}`,
+		"modules/vpc/examples/x.tf":    `variable "module_example" {}`,
+		"modules/vpc/vpc.generated.tf": `variable "vpc_generated" {}`,
+	}), ".")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	workspaces, err := NewParser(testFS, Simple).ParseTerraformWorkspaces(".")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	paths := []string{}
+	for path := range workspaces {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+	if !slices.Equal(paths, []string{".", "modules/legacy", "modules/vpc"}) {
+		t.Fatalf("Unexpected workspaces %v", paths)
+	}
+
+	names := func(config *TerraformConfig) []string {
+		names := []string{}
+		for _, variable := range config.Variables {
+			names = append(names, variable.Name)
+		}
+		slices.Sort(names)
+		return names
+	}
+	if got := names(workspaces["."]); !slices.Equal(got, []string{"kept", "root"}) {
+		t.Errorf("Unexpected root variables %v", got)
+	}
+	if got := names(workspaces["modules/vpc"]); !slices.Equal(got, []string{"cidr"}) {
+		t.Errorf("Unexpected modules/vpc variables %v", got)
+	}
+}
+
 func TestMixedBlocks(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -733,13 +1422,16 @@ module "vpc" {
 			},
 			mode: Simple,
 			expectations: TestExpectations{
-				VariableCount:  ptr(1),
-				OutputCount:    ptr(1),
-				TerraformCount: ptr(1),
+				VariableCount:   ptr(1),
+				OutputCount:     ptr(1),
+				TerraformCount:  ptr(1),
+				ModuleCount:     ptr(0),
+				ResourceCount:   ptr(0),
+				DataSourceCount: ptr(0),
 			},
 		},
 		{
-			name: "Detail level - all blocks (when implemented)",
+			name: "Detail level - all blocks",
 			files: map[string]string{
 				"main.tf": `
 variable "test_var" {
@@ -769,10 +1461,12 @@ module "vpc" {
 			},
 			mode: Detail,
 			expectations: TestExpectations{
-				VariableCount:  ptr(1),
-				OutputCount:    ptr(1),
-				TerraformCount: ptr(1),
-				// Note: Resource, data, module parsing not implemented yet
+				VariableCount:   ptr(1),
+				OutputCount:     ptr(1),
+				TerraformCount:  ptr(1),
+				ModuleCount:     ptr(1),
+				ResourceCount:   ptr(1),
+				DataSourceCount: ptr(1),
 			},
 		},
 		{
@@ -838,3 +1532,1717 @@ terraform {
 		})
 	}
 }
+
+func TestModuleProviderWiring(t *testing.T) {
+	testFS := newTestFileSystem(map[string]string{
+		"main.tf": `
+terraform {
+  required_providers {
+    aws = {
+      source                = "hashicorp/aws"
+      configuration_aliases = [aws.primary, aws.replica]
+    }
+  }
+}
+
+provider "aws" {
+  alias  = "use1"
+  region = "us-east-1"
+}
+
+module "replica" {
+  source = "./modules/replica"
+  providers = {
+    aws.primary = aws.use1
+    aws.replica = aws
+  }
+}`,
+	})
+
+	config, err := NewParser(testFS, Detail).ParseTerraformWorkspace(".")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(config.Providers) != 1 || config.Providers[0].Address() != "aws.use1" {
+		t.Errorf("Expected provider configuration aws.use1, got %+v", config.Providers)
+	}
+
+	aliases := config.Terraform[0].RequiredProviders["aws"].ConfigurationAliases
+	if len(aliases) != 2 || aliases[0] != "aws.primary" || aliases[1] != "aws.replica" {
+		t.Errorf("Expected configuration aliases [aws.primary aws.replica], got %v", aliases)
+	}
+
+	providers := config.Modules[0].Providers
+	if providers["aws.primary"] != "aws.use1" || providers["aws.replica"] != "aws" {
+		t.Errorf("Unexpected module providers map: %v", providers)
+	}
+}
+
+func TestDependsOn(t *testing.T) {
+	testFS := newTestFileSystem(map[string]string{
+		"main.tf": `
+resource "aws_s3_bucket" "logs" {
+  bucket     = "logs"
+  depends_on = [aws_iam_role.writer, module.network]
+}
+
+data "aws_iam_policy_document" "read" {
+  depends_on = [aws_s3_bucket.logs]
+}
+
+module "app" {
+  source     = "./modules/app"
+  depends_on = [data.aws_iam_policy_document.read]
+}
+
+output "bucket" {
+  value      = aws_s3_bucket.logs.id
+  depends_on = [module.app]
+}`,
+	})
+
+	config, err := NewParser(testFS, Detail).ParseTerraformWorkspace(".")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := map[string][]string{
+		"aws_s3_bucket.logs":                {"aws_iam_role.writer", "module.network"},
+		"data.aws_iam_policy_document.read": {"aws_s3_bucket.logs"},
+		"module.app":                        {"data.aws_iam_policy_document.read"},
+		"output.bucket":                     {"module.app"},
+	}
+
+	got := map[string][]string{
+		config.Resources[0].Address():   config.Resources[0].DependsOn,
+		config.DataSources[0].Address(): config.DataSources[0].DependsOn,
+		config.Modules[0].Address():     config.Modules[0].DependsOn,
+		config.Outputs[0].Address():     config.Outputs[0].DependsOn,
+	}
+
+	for address, dependsOn := range expected {
+		if strings.Join(got[address], ",") != strings.Join(dependsOn, ",") {
+			t.Errorf("%s: expected depends_on %v, got %v", address, dependsOn, got[address])
+		}
+	}
+}
+
+func TestReferences(t *testing.T) {
+	testFS := newTestFileSystem(map[string]string{
+		"main.tf": `
+locals {
+  name = "${var.prefix}-${var.environment}"
+  tags = merge(var.tags, { Name = local.name })
+}
+
+resource "aws_security_group" "web" {
+  name   = local.name
+  vpc_id = module.network.vpc_id
+
+  dynamic "ingress" {
+    for_each = var.ports
+    content {
+      from_port = ingress.value
+      to_port   = ingress.value
+    }
+  }
+
+  lifecycle {
+    ignore_changes       = [tags]
+    replace_triggered_by  = [aws_instance.web[0].id]
+  }
+}
+
+data "aws_subnets" "private" {
+  filter {
+    name   = "tag:Tier"
+    values = [for tier in var.tiers : upper(tier)]
+  }
+}
+
+module "network" {
+  source = "./modules/network"
+  cidr   = data.aws_subnets.private.ids[0]
+  count  = var.enabled ? 1 : 0
+}
+
+output "group_id" {
+  value = aws_security_group.web.id
+}`,
+	})
+
+	config, err := NewParser(testFS, Detail).ParseTerraformWorkspace(".")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	got := map[string][]string{
+		config.Resources[0].Address():   config.Resources[0].References,
+		config.DataSources[0].Address(): config.DataSources[0].References,
+		config.Modules[0].Address():     config.Modules[0].References,
+		config.Outputs[0].Address():     config.Outputs[0].References,
+	}
+	for _, local := range config.Locals {
+		got[local.Address()] = local.References
+	}
+
+	expected := map[string][]string{
+		"local.name":               {"var.environment", "var.prefix"},
+		"local.tags":               {"local.name", "var.tags"},
+		"aws_security_group.web":   {"aws_instance.web", "local.name", "module.network", "var.ports"},
+		"data.aws_subnets.private": {"var.tiers"},
+		"module.network":           {"data.aws_subnets.private", "var.enabled"},
+		"output.group_id":          {"aws_security_group.web"},
+	}
+
+	for address, refs := range expected {
+		if strings.Join(got[address], ",") != strings.Join(refs, ",") {
+			t.Errorf("%s: expected references %v, got %v", address, refs, got[address])
+		}
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	fingerprintOf := func(files map[string]string, mode Mode) string {
+		t.Helper()
+		config, err := NewParser(newTestFileSystem(files), mode).ParseTerraformWorkspace(".")
+		if err != nil {
+			t.Fatalf("Failed to parse: %v", err)
+		}
+		return config.Fingerprint
+	}
+
+	base := fingerprintOf(map[string]string{
+		"main.tf": `
+variable "name" {
+  type    = string
+  default = "web"
+}
+
+resource "aws_instance" "web" {
+  ami  = "ami-123"
+  tags = { Name = var.name, Env = "dev" }
+  user_data = "#!/bin/sh\necho ${var.name}\n"
+}
+`,
+	}, Detail)
+
+	if !strings.HasPrefix(base, "sha256:") {
+		t.Fatalf("Expected sha256 fingerprint, got %q", base)
+	}
+
+	reformatted := fingerprintOf(map[string]string{
+		"variables.tf": `
+# Name of the instance
+variable "name" {
+  default = "web"
+  type = string
+}
+`,
+		"main.tf": `
+resource "aws_instance" "web" {
+  tags = {
+    "Name" = var.name
+    Env    = "dev"
+  }
+  user_data = <<EOT
+#!/bin/sh
+echo ${var.name}
+EOT
+  ami = "ami-123" // pinned
+}
+`,
+	}, Simple)
+
+	if reformatted != base {
+		t.Errorf("Expected formatting, comments and file layout not to change the fingerprint: %s != %s", reformatted, base)
+	}
+
+	changed := fingerprintOf(map[string]string{
+		"main.tf": `
+variable "name" {
+  type    = string
+  default = "web"
+}
+
+resource "aws_instance" "web" {
+  ami  = "ami-456"
+  tags = { Name = var.name, Env = "dev" }
+  user_data = "#!/bin/sh\necho ${var.name}\n"
+}
+`,
+	}, Detail)
+
+	if changed == base {
+		t.Error("Expected a changed value to change the fingerprint")
+	}
+}
+
+func TestAnnotations(t *testing.T) {
+	testFS := newTestFileSystem(map[string]string{
+		"main.tf": `
+# tfparser:owner = "team-network"
+# tfparser:tier = gold
+// tfparser:lifecycle = deprecated
+
+variable "name" {
+  # tfparser:ignored comment without a value
+  type = string
+}
+`,
+		"metadata.tf": `
+tfparser {
+  lifecycle = "active"
+  links     = ["https://wiki/vpc", "https://runbook/vpc"]
+}
+`,
+	})
+
+	config, err := NewParser(testFS, Simple).ParseTerraformWorkspace(".")
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	expected := map[string]string{
+		"owner":     "team-network",
+		"tier":      "gold",
+		"lifecycle": "active",
+		"links":     "https://wiki/vpc, https://runbook/vpc",
+	}
+	if len(config.Annotations) != len(expected) {
+		t.Errorf("Expected %d annotations, got %v", len(expected), config.Annotations)
+	}
+	for key, value := range expected {
+		if config.Annotations[key] != value {
+			t.Errorf("Expected annotation %s=%q, got %q", key, value, config.Annotations[key])
+		}
+	}
+}
+
+func TestNumberRendering(t *testing.T) {
+	testFS := newTestFileSystem(map[string]string{
+		"main.tf": `
+tfparser {
+  ten_million = 1e7
+  big_int     = 12345678901234567890
+  fraction    = 0.1
+  small       = 1.5e-7
+  precise     = 3.14159265358979323846
+  enabled     = true
+}
+
+variable "ten_million" {
+  default = 1e7
+}
+
+variable "big_int" {
+  default = 12345678901234567890
+}
+
+variable "fraction" {
+  default = 0.25
+}
+
+variable "precise" {
+  default = 3.14159265358979323846
+}
+`,
+	})
+
+	config, err := NewParser(testFS, Simple).ParseTerraformWorkspace(".")
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	expected := map[string]string{
+		"ten_million": "10000000",
+		"big_int":     "12345678901234567890",
+		"fraction":    "0.1",
+		"small":       "0.00000015",
+		"precise":     "3.14159265358979323846",
+		"enabled":     "true",
+	}
+	for key, value := range expected {
+		if config.Annotations[key] != value {
+			t.Errorf("Expected annotation %s=%q, got %q", key, value, config.Annotations[key])
+		}
+	}
+
+	summary, err := config.Summary(false)
+	if err != nil {
+		t.Fatalf("Failed to generate summary: %v", err)
+	}
+
+	for _, expected := range []string{
+		`{"name":"ten_million","default":10000000,"required":false,"sensitive":false}`,
+		`{"name":"big_int","default":12345678901234567890,"required":false,"sensitive":false}`,
+		`{"name":"fraction","default":0.25,"required":false,"sensitive":false}`,
+		`{"name":"precise","default":3.14159265358979323846,"required":false,"sensitive":false}`,
+	} {
+		if !strings.Contains(string(summary), expected) {
+			t.Errorf("Expected summary to contain %s, got %s", expected, summary)
+		}
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	testFS := newTestFileSystem(map[string]string{
+		"main.tf": `
+terraform {
+  required_version = ">= 1.5"
+  required_providers {
+    aws = { source = "hashicorp/aws", version = "~> 5.0" }
+  }
+}
+
+variable "name" {
+  description = "Name | of the VPC"
+  type        = string
+}
+
+variable "cidrs" {
+  type    = list(string)
+  default = ["10.0.0.0/16"]
+}
+
+variable "region" {
+  default = "us-east-1"
+}
+
+module "vpc" {
+  source  = "terraform-aws-modules/vpc/aws"
+  version = "5.1.0"
+}
+
+data "aws_region" "current" {}
+
+output "id" {
+  description = "ID of the VPC"
+  value       = module.vpc.vpc_id
+}
+`,
+	})
+
+	config, err := NewParser(testFS, Detail).ParseTerraformWorkspace(".")
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	markdown := config.Markdown()
+	for _, expected := range []string{
+		"| terraform | `>= 1.5` |\n| aws | `~> 5.0` |",
+		"| aws | `hashicorp/aws` | n/a |  |",
+		"| vpc | `terraform-aws-modules/vpc/aws` | `5.1.0` |",
+		"| `data.aws_region.current` | data source |",
+		"| cidrs |  | `list(string)` | `[\"10.0.0.0/16\"]` | no |\n| name | Name \\| of the VPC | `string` | n/a | yes |\n| region |  | `any` | `\"us-east-1\"` | no |",
+		"| id | ID of the VPC | no |",
+	} {
+		if !strings.Contains(markdown, expected) {
+			t.Errorf("Expected markdown to contain %q, got:\n%s", expected, markdown)
+		}
+	}
+	if !strings.HasPrefix(markdown, "## Requirements\n") {
+		t.Errorf("Expected markdown to start with the requirements, got:\n%s", markdown)
+	}
+}
+
+func TestFlatten(t *testing.T) {
+	testFS := newTestFileSystem(map[string]string{
+		"main.tf": `
+terraform {
+  backend "s3" {
+    bucket = "state"
+    assume_role {
+      role_arn = "arn:aws:iam::123456789012:role/state"
+    }
+  }
+}
+
+variable "settings" {
+  default = {
+    tags    = { Name = "test", "kubernetes.io/role" = "elb" }
+    subnets = [{ cidr = "10.0.0.0/24", public = true }, { cidr = var.cidr }]
+    empty   = []
+  }
+}
+
+variable "name" {
+  default = "app"
+}
+`,
+	})
+
+	config, err := NewParser(testFS, Simple).ParseTerraformWorkspace(".")
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	if config.Variables[0].FlatDefault != nil {
+		t.Errorf("Expected no flat default without WithFlatten, got %v", config.Variables[0].FlatDefault)
+	}
+
+	config, err = NewParser(testFS, Simple).WithFlatten(true).ParseTerraformWorkspace(".")
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"tags.Name":                  "test",
+		`tags["kubernetes.io/role"]`: "elb",
+		"subnets[0].cidr":            "10.0.0.0/24",
+		"subnets[0].public":          true,
+		"subnets[1].cidr":            "var.cidr",
+		"empty":                      []interface{}{},
+	}
+	if !reflect.DeepEqual(config.Variables[0].FlatDefault, expected) {
+		t.Errorf("Expected flat default %v, got %v", expected, config.Variables[0].FlatDefault)
+	}
+	if config.Variables[1].FlatDefault != nil {
+		t.Errorf("Expected no flat default for a string, got %v", config.Variables[1].FlatDefault)
+	}
+
+	expected = map[string]interface{}{
+		"bucket":               "state",
+		"assume_role.role_arn": "arn:aws:iam::123456789012:role/state",
+	}
+	if backend := config.Terraform[0].Backend; !reflect.DeepEqual(backend.FlatConfig, expected) {
+		t.Errorf("Expected flat backend config %v, got %v", expected, backend.FlatConfig)
+	}
+}
+
+func TestExplicitFalseSensitive(t *testing.T) {
+	testFS := newTestFileSystem(map[string]string{
+		"main.tf": `
+variable "unset" {}
+
+variable "public" {
+  sensitive = false
+}
+
+output "unset" {
+  value = var.unset
+}
+
+output "public" {
+  value     = var.public
+  sensitive = false
+}
+`,
+	})
+
+	config, err := NewParser(testFS, Simple).ParseTerraformWorkspace(".")
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	summary, err := config.Summary(false)
+	if err != nil {
+		t.Fatalf("Failed to generate summary: %v", err)
+	}
+
+	for _, expected := range []string{
+		`{"name":"unset","required":true,"sensitive":false}`,
+		`{"name":"public","required":true,"sensitive":false}`,
+		`{"name":"unset","value":"var.unset","references":["var.unset"]}`,
+		`{"name":"public","value":"var.public","sensitive":false,"references":["var.public"]}`,
+	} {
+		if !strings.Contains(string(summary), expected) {
+			t.Errorf("Expected summary to contain %s, got %s", expected, summary)
+		}
+	}
+}
+
+func TestWithAST(t *testing.T) {
+	testFS := newTestFileSystem(map[string]string{
+		"main.tf": `
+locals {
+  names = [for n in var.names : upper(n)]
+}
+
+resource "aws_instance" "web" {
+  ami  = data.aws_ami.ubuntu.id
+  tags = { Name = "web-${var.env}" }
+
+  lifecycle {
+    ignore_changes = [tags]
+  }
+}
+`,
+	})
+
+	config, err := NewParser(testFS, Detail).WithAST(true).ParseTerraformWorkspace(".")
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	local := config.Locals[0].AST
+	if local == nil || local.Kind != schema.NodeFor || local.ValueExpr.Function != "upper" {
+		t.Errorf("Unexpected AST for local.names: %+v", local)
+	}
+
+	ast := config.Resources[0].AST
+	if ast == nil {
+		t.Fatal("Expected resource AST")
+	}
+	if ami := ast.Attributes["ami"]; ami.Kind != schema.NodeTraversal || strings.Join(ami.Traversal, ".") != "data.aws_ami.ubuntu.id" {
+		t.Errorf("Unexpected AST for ami: %+v", ami)
+	}
+
+	tags := ast.Attributes["tags"]
+	if tags.Kind != schema.NodeObject || tags.Attrs[0].Key.Value != "Name" || tags.Attrs[0].Value.Kind != schema.NodeTemplate {
+		t.Errorf("Unexpected AST for tags: %+v", tags)
+	}
+	if len(ast.Blocks) != 1 || ast.Blocks[0].Type != "lifecycle" || ast.Blocks[0].Attributes["ignore_changes"].Kind != schema.NodeTuple {
+		t.Errorf("Unexpected nested block ASTs: %+v", ast.Blocks)
+	}
+
+	plain, err := NewParser(testFS, Detail).ParseTerraformWorkspace(".")
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	if plain.Resources[0].AST != nil || plain.Locals[0].AST != nil {
+		t.Error("Expected no AST without WithAST")
+	}
+}
+
+func TestValidateBlocks(t *testing.T) {
+	testFS := newTestFileSystem(map[string]string{
+		"main.tf": `
+variable "name" {
+  sensative = true
+}
+
+variable {
+}
+
+output "id" {
+  description = "ID"
+}
+
+module "vpc" {
+  source = "./vpc"
+  cidr   = "10.0.0.0/16"
+
+  lifecycle {}
+}
+
+resource "aws_instance" "web" {
+  ami = "ami-123"
+
+  lifecycle {
+    prevent_destory = true
+  }
+}
+
+terraform {
+  requried_providers {}
+}
+`,
+	})
+
+	if _, err := NewParser(testFS, Simple).ParseTerraformWorkspace("."); err == nil {
+		t.Fatal("Expected the variable block without label to fail without validation")
+	}
+
+	config, err := NewParser(testFS, Detail).WithValidateBlocks(true).ParseTerraformWorkspace(".")
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	expected := []string{
+		`3 Unsupported argument: An argument named "sensative" is not expected in variable "name" block. Did you mean "sensitive"?`,
+		`6 Invalid number of labels: A variable block must have 1 label(s), but has 0.`,
+		`9 Missing required argument: The argument "value" is required in output "id" block, but no definition was found.`,
+		`17 Unsupported block type: Blocks of type "lifecycle" are not expected in module "vpc" block.`,
+		`24 Unsupported argument: An argument named "prevent_destory" is not expected in lifecycle block. Did you mean "prevent_destroy"?`,
+		`29 Unsupported block type: Blocks of type "requried_providers" are not expected in terraform block. Did you mean "required_providers"?`,
+	}
+	actual := []string{}
+	for _, diagnostic := range config.Diagnostics {
+		actual = append(actual, fmt.Sprintf("%d %s: %s", diagnostic.Location.Line, diagnostic.Summary, diagnostic.Detail))
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected diagnostics:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(actual, "\n"))
+	}
+
+	if len(config.Variables) != 1 || len(config.Modules) != 1 || len(config.Resources) != 1 {
+		t.Errorf("Expected the blocks with valid labels to be parsed, got %d variables, %d modules, %d resources",
+			len(config.Variables), len(config.Modules), len(config.Resources))
+	}
+}
+
+func TestLenient(t *testing.T) {
+	testFS := newTestFileSystem(map[string]string{
+		"main.tf": `variable "name" {}
+
+variable "size" {
+  validation {
+    condition = var.size > 0
+  }
+}
+`,
+		"broken.tf": `variable "region" {}
+
+output "id" {
+  value = 
+}
+`,
+		"bad.tf.json":        `{"variable": {"json": `,
+		"broken_override.tf": `variable "name" {`,
+		"outputs.tf":         `output "name" { value = var.name }`,
+	})
+
+	if _, err := NewParser(testFS, Simple).ParseTerraformWorkspace("."); err == nil {
+		t.Fatal("Expected broken files to fail without lenient mode")
+	}
+
+	config, err := NewParser(testFS, Simple).WithLenient(true).ParseTerraformWorkspace(".")
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	names := []string{}
+	for _, variable := range config.Variables {
+		names = append(names, variable.Name)
+	}
+	if !slices.Equal(names, []string{"region", "name"}) || len(config.Outputs) != 2 {
+		t.Errorf("Expected the readable blocks of every file, got variables %v and %d outputs", names, len(config.Outputs))
+	}
+
+	locations := []string{}
+	for _, diagnostic := range config.Diagnostics {
+		if diagnostic.Severity != "error" || diagnostic.Summary == "" {
+			t.Errorf("Unexpected diagnostic %+v", diagnostic)
+		}
+		locations = append(locations, fmt.Sprintf("%s:%d", diagnostic.Location.File, diagnostic.Location.Line))
+	}
+	for _, expected := range []string{"bad.tf.json:1", "broken.tf:4", "broken_override.tf:1", "main.tf:3"} {
+		if !slices.Contains(locations, expected) {
+			t.Errorf("Expected a diagnostic at %s, got %v", expected, locations)
+		}
+	}
+
+	if _, err := NewParser(nil, Simple).WithLenient(true).ParseHCLBytes("stdin.tf", []byte(`variable "x" {`)); err != nil {
+		t.Errorf("Expected lenient ParseHCLBytes to report diagnostics, got %v", err)
+	}
+}
+
+func TestWithKinds(t *testing.T) {
+	testFS := newTestFileSystem(map[string]string{
+		"main.tf": `
+variable "name" {
+  description = "Name"
+  default     = "app"
+}
+
+variable "tags" {
+  default = { Team = "platform", Size = -1 }
+}
+
+variable "region" {
+  default = var.fallback_region
+}
+
+locals {
+  prefix  = "${var.env}-${var.name}"
+  payload = jsonencode({ a = 1 })
+  size    = var.large ? 10 : 1
+  names   = [for n in var.names : upper(n)]
+  items   = [var.a, "b"]
+  total   = (var.a + 1)
+}
+`,
+	})
+
+	config, err := NewParser(testFS, Detail).WithKinds(true).ParseTerraformWorkspace(".")
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	expected := map[string]map[string]schema.ValueKind{
+		"name":   {"description": schema.KindLiteral, "default": schema.KindLiteral},
+		"tags":   {"default": schema.KindLiteral},
+		"region": {"default": schema.KindReference},
+	}
+	for _, variable := range config.Variables {
+		if !reflect.DeepEqual(variable.Kinds, expected[variable.Name]) {
+			t.Errorf("Expected kinds %v for var.%s, got %v", expected[variable.Name], variable.Name, variable.Kinds)
+		}
+	}
+
+	locals := map[string]schema.ValueKind{
+		"prefix":  schema.KindReference,
+		"payload": schema.KindFunction,
+		"size":    schema.KindConditional,
+		"names":   schema.KindComplex,
+		"items":   schema.KindComplex,
+		"total":   schema.KindComplex,
+	}
+	for _, local := range config.Locals {
+		if local.Kind != locals[local.Name] {
+			t.Errorf("Expected kind %s for local.%s, got %s", locals[local.Name], local.Name, local.Kind)
+		}
+	}
+
+	plain, err := NewParser(testFS, Detail).ParseTerraformWorkspace(".")
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	if plain.Variables[0].Kinds != nil || plain.Locals[0].Kind != "" {
+		t.Error("Expected no kinds without WithKinds")
+	}
+}
+
+func TestWithPositions(t *testing.T) {
+	testFS := newTestFileSystem(map[string]string{
+		"main.tf": `variable "name" {
+  default = "app"
+}
+
+resource "aws_s3_bucket" "this" {
+  bucket = var.name
+
+  tags = {
+    Name = var.name
+  }
+}
+
+locals {
+  prefix = "app"
+  suffix = upper(
+    var.name
+  )
+}
+`,
+		"outputs.tf": `output "bucket" {
+  value = aws_s3_bucket.this.id
+}
+`,
+	})
+
+	config, err := NewParser(testFS, Detail).WithPositions(true).ParseTerraformWorkspace(".")
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	expected := map[string]schema.SourceRange{
+		"var.name":           {File: "main.tf", StartLine: 1, EndLine: 3},
+		"aws_s3_bucket.this": {File: "main.tf", StartLine: 5, EndLine: 11},
+		"output.bucket":      {File: "outputs.tf", StartLine: 1, EndLine: 3},
+		"local.prefix":       {File: "main.tf", StartLine: 14, EndLine: 14},
+		"local.suffix":       {File: "main.tf", StartLine: 15, EndLine: 17},
+	}
+	positions := map[string]*schema.SourceRange{
+		config.Variables[0].Address(): config.Variables[0].SourceRange,
+		config.Resources[0].Address(): config.Resources[0].SourceRange,
+		config.Outputs[0].Address():   config.Outputs[0].SourceRange,
+	}
+	for _, local := range config.Locals {
+		positions[local.Address()] = local.SourceRange
+	}
+	for address, want := range expected {
+		if got := positions[address]; got == nil || *got != want {
+			t.Errorf("Expected position %+v for %s, got %+v", want, address, got)
+		}
+	}
+
+	plain, err := NewParser(testFS, Detail).ParseTerraformWorkspace(".")
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	if plain.Variables[0].SourceRange != nil || plain.Locals[0].SourceRange != nil {
+		t.Error("Expected no positions without WithPositions")
+	}
+	out, err := json.Marshal(plain.Variables[0])
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+	if strings.Contains(string(out), "start_line") {
+		t.Errorf("Expected no position fields without WithPositions, got %s", out)
+	}
+}
+
+func TestLeadingComments(t *testing.T) {
+	testFS := newTestFileSystem(map[string]string{
+		"main.tf": `# Name of the deployment.
+# Used as prefix of every resource.
+variable "name" {
+  type = string # trailing
+}
+
+// Detached comment
+
+variable "region" {}
+
+/*
+ * Bucket of the state files,
+ * shared by every environment
+ */
+resource "aws_s3_bucket" "this" {
+  bucket = var.name
+}
+
+# tfparser:ignore description-min-length
+output "bucket" {
+  value = aws_s3_bucket.this.id
+}
+
+variable "tags" { # not a leading comment
+  default = {}
+}
+# Deprecated: use tags.
+variable "labels" {}
+`,
+	})
+
+	config, err := NewParser(testFS, Detail).ParseTerraformWorkspace(".")
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	expected := map[string]string{
+		"name":   "Name of the deployment.\nUsed as prefix of every resource.",
+		"region": "",
+		"tags":   "",
+		"labels": "Deprecated: use tags.",
+	}
+	for _, variable := range config.Variables {
+		if variable.Comment != expected[variable.Name] {
+			t.Errorf("Expected comment %q for var.%s, got %q", expected[variable.Name], variable.Name, variable.Comment)
+		}
+	}
+	if comment := config.Resources[0].Comment; comment != "Bucket of the state files,\nshared by every environment" {
+		t.Errorf("Unexpected resource comment %q", comment)
+	}
+	if comment := config.Outputs[0].Comment; comment != "" {
+		t.Errorf("Expected directives to be left out, got %q", comment)
+	}
+}
+
+func TestLazyThreshold(t *testing.T) {
+	testFS := newTestFileSystem(map[string]string{
+		"main.tf": `
+resource "aws_iam_policy" "this" {
+  name = "deploy"
+  policy = jsonencode({
+    Version   = "2012-10-17"
+    Statement = [{ Effect = "Allow", Action = ["s3:GetObject"], Resource = "*" }]
+  })
+}
+`,
+	})
+
+	config, err := NewParser(testFS, Detail).WithAST(true).WithLazyThreshold(40).ParseTerraformWorkspace(".")
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	ast := config.Resources[0].AST
+	if name := ast.Attributes["name"]; name.Kind != schema.NodeTemplate || name.Resolve() != name {
+		t.Errorf("Expected name to be converted up front, got %+v", name)
+	}
+
+	policy := ast.Attributes["policy"]
+	if policy.Kind != schema.NodeDeferred || policy.Range == nil || policy.Range.StartLine != 4 || policy.Range.EndLine != 7 || policy.Size <= 40 {
+		t.Fatalf("Expected policy to be deferred, got %+v", policy)
+	}
+	if policy.Function != "" || policy.Args != nil {
+		t.Errorf("Expected no AST for the deferred policy, got %+v", policy)
+	}
+
+	resolved := policy.Resolve()
+	if resolved.Kind != schema.NodeFunctionCall || resolved.Function != "jsonencode" || resolved.Args[0].Kind != schema.NodeObject {
+		t.Errorf("Unexpected resolved AST %+v", resolved)
+	}
+	if policy.Resolve() != resolved {
+		t.Error("Expected the resolved AST to be memoized")
+	}
+}
+
+func TestIndexTraversals(t *testing.T) {
+	testFS := newTestFileSystem(map[string]string{
+		"main.tf": `variable "names" {
+  type = list(string)
+}
+
+locals {
+  count = length(var.names)
+}
+
+resource "null_resource" "this" {
+  count    = local.count
+  triggers = { name = var.names[count.index] }
+
+  dynamic "item" {
+    for_each = var.names
+    content {
+      value = item.value
+    }
+  }
+}
+`,
+	})
+
+	idx, err := NewParser(testFS, Detail).IndexTraversals(".")
+	if err != nil {
+		t.Fatalf("Failed to index traversals: %v", err)
+	}
+
+	usages := idx.Lookup("var.names")
+	if len(usages) != 3 {
+		t.Fatalf("Expected 3 usages of var.names, got %d", len(usages))
+	}
+	if usages[0].Block != "local.count" || usages[0].Line != 6 || usages[0].Column != 18 {
+		t.Errorf("Unexpected first usage: %+v", usages[0])
+	}
+	if usages[1].Traversal != "var.names" || usages[1].Block != "null_resource.this" || usages[1].Attribute != "triggers" {
+		t.Errorf("Unexpected second usage: %+v", usages[1])
+	}
+
+	if len(idx.Lookup("local.count")) != 1 || len(idx.Lookup("count.index")) != 1 {
+		t.Errorf("Expected local.count and count.index to be indexed: %+v", idx.Roots)
+	}
+	if _, ok := idx.Roots["item"]; ok {
+		t.Error("Dynamic block iterator must not be indexed")
+	}
+	if _, ok := idx.Roots["string"]; ok {
+		t.Error("Variable type constraints must not be indexed")
+	}
+	if len(idx.Lookup("var.missing")) != 0 {
+		t.Error("Expected no usages of an unknown address")
+	}
+}
+
+func TestEphemeralAndWriteOnly(t *testing.T) {
+	testFS := newTestFileSystem(map[string]string{
+		"main.tf": `
+ephemeral "random_password" "db" {
+  length = 16
+}
+
+resource "aws_db_instance" "this" {
+  password_wo         = ephemeral.random_password.db.result
+  password_wo_version = 1
+
+  master_user {
+    secret_wo = "x"
+  }
+}
+`,
+	})
+
+	config, err := NewParser(testFS, Detail).ParseTerraformWorkspace(".")
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	if len(config.EphemeralResources) != 1 || config.EphemeralResources[0].Address() != "ephemeral.random_password.db" {
+		t.Fatalf("Unexpected ephemeral resources: %+v", config.EphemeralResources)
+	}
+	if len(config.Resources) != 1 {
+		t.Fatalf("Expected ephemeral blocks to be kept apart from resources, got %d resources", len(config.Resources))
+	}
+
+	resource := config.Resources[0]
+	if strings.Join(resource.WriteOnly, ",") != "master_user.secret_wo,password_wo" {
+		t.Errorf("Unexpected write-only arguments: %v", resource.WriteOnly)
+	}
+	if strings.Join(resource.References, ",") != "ephemeral.random_password.db" {
+		t.Errorf("Unexpected references: %v", resource.References)
+	}
+}
+
+func TestLockFile(t *testing.T) {
+	testFS := newTestFileSystem(map[string]string{
+		"main.tf": `
+terraform {
+  required_providers {
+    aws = {
+      source  = "hashicorp/aws"
+      version = "~> 5.0"
+    }
+    random = {
+      version = ">= 3.0"
+    }
+    custom = {
+      source = "example.com/acme/custom"
+    }
+  }
+}
+`,
+		".terraform.lock.hcl": `
+provider "registry.terraform.io/hashicorp/aws" {
+  version     = "5.31.0"
+  constraints = "~> 5.0"
+  hashes = [
+    "h1:abc=",
+    "zh:def",
+  ]
+}
+
+provider "registry.opentofu.org/hashicorp/random" {
+  version = "3.6.0"
+}
+`,
+	})
+
+	config, err := NewParser(testFS, Simple).ParseTerraformWorkspace(".")
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	if len(config.LockedProviders) != 2 {
+		t.Fatalf("Expected 2 locked providers, got %d", len(config.LockedProviders))
+	}
+	aws := config.LockedProviders[0]
+	if aws.Source != "registry.terraform.io/hashicorp/aws" || aws.Constraints != "~> 5.0" || strings.Join(aws.Hashes, ",") != "h1:abc=,zh:def" {
+		t.Errorf("Unexpected locked provider: %+v", aws)
+	}
+
+	required := config.Terraform[0].RequiredProviders
+	if required["aws"].LockedVersion != "5.31.0" {
+		t.Errorf("Expected aws locked at 5.31.0, got %q", required["aws"].LockedVersion)
+	}
+	if required["random"].LockedVersion != "3.6.0" {
+		t.Errorf("Expected random locked at 3.6.0, got %q", required["random"].LockedVersion)
+	}
+	if required["custom"].LockedVersion != "" {
+		t.Errorf("Expected custom not to be locked, got %q", required["custom"].LockedVersion)
+	}
+}
+
+func TestResolveModuleTree(t *testing.T) {
+	testFS := newTestFileSystem(map[string]string{
+		"main.tf": `
+module "vpc" {
+  source  = "terraform-aws-modules/vpc/aws"
+  version = "~> 5.0"
+}
+
+module "app" {
+  source = "./modules/app"
+}
+
+module "missing" {
+  source = "git::https://example.com/missing.git"
+}
+`,
+		"modules/app/main.tf": `variable "name" {}`,
+		".terraform/modules/modules.json": `{"Modules":[
+  {"Key":"","Source":"","Dir":"."},
+  {"Key":"vpc","Source":"registry.terraform.io/terraform-aws-modules/vpc/aws","Version":"5.1.2","Dir":".terraform/modules/vpc"},
+  {"Key":"vpc.subnets","Source":"./modules/subnets","Dir":".terraform/modules/vpc/modules/subnets"}
+]}`,
+		".terraform/modules/vpc/main.tf": `
+module "subnets" {
+  source = "./modules/subnets"
+}
+`,
+		".terraform/modules/vpc/modules/subnets/main.tf": `output "ids" { value = [] }`,
+	})
+
+	p := NewParser(testFS, Detail)
+	config, err := p.ParseTerraformWorkspace(".")
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	manifest, err := p.LoadModuleManifest(".")
+	if err != nil || manifest == nil {
+		t.Fatalf("Failed to load manifest: %v", err)
+	}
+
+	tree, err := p.ResolveModuleTree(".", config, manifest)
+	if err != nil {
+		t.Fatalf("Failed to resolve module tree: %v", err)
+	}
+	if len(tree) != 3 {
+		t.Fatalf("Expected 3 module calls, got %d", len(tree))
+	}
+
+	vpc := tree[0]
+	if vpc.Version != "5.1.2" || vpc.Config == nil || len(vpc.Children) != 1 {
+		t.Fatalf("Unexpected vpc module: %+v", vpc)
+	}
+	if subnets := vpc.Children[0]; subnets.Key != "vpc.subnets" || subnets.Config == nil || len(subnets.Config.Outputs) != 1 {
+		t.Errorf("Unexpected nested module: %+v", subnets)
+	}
+	if app := tree[1]; app.Config == nil || len(app.Config.Variables) != 1 {
+		t.Errorf("Expected local module to be resolved without manifest entry: %+v", app)
+	}
+	if missing := tree[2]; missing.Dir != "" || missing.Config != nil {
+		t.Errorf("Expected missing module to stay unresolved: %+v", missing)
+	}
+
+	withoutManifest, err := p.ResolveModuleTree(".", config, nil)
+	if err != nil {
+		t.Fatalf("Failed to resolve module tree: %v", err)
+	}
+	if withoutManifest[0].Config != nil || withoutManifest[1].Config == nil {
+		t.Errorf("Expected only local modules without manifest")
+	}
+}
+
+func TestLocateBlocks(t *testing.T) {
+	testFS := newTestFileSystem(map[string]string{
+		"main.tf": `terraform {
+  required_providers {
+    aws = {
+      source = "hashicorp/aws"
+    }
+  }
+}
+
+locals {
+  name = "app"
+}
+
+resource "aws_s3_bucket" "this" {
+  bucket = local.name
+}
+`,
+		"providers.tf": `provider "aws" {
+  region = "us-east-1"
+}
+`,
+	})
+
+	locations, err := NewParser(testFS, Detail).LocateBlocks(".")
+	if err != nil {
+		t.Fatalf("Failed to locate blocks: %v", err)
+	}
+
+	expected := map[string]Location{
+		"required_providers.aws": {File: "main.tf", Line: 3, Column: 5},
+		"local.name":             {File: "main.tf", Line: 10, Column: 3},
+		"aws_s3_bucket.this":     {File: "main.tf", Line: 13, Column: 1},
+		"provider.aws":           {File: "providers.tf", Line: 1, Column: 1},
+	}
+	for address, want := range expected {
+		got, ok := locations[address]
+		if !ok {
+			t.Errorf("Expected %s to be located", address)
+			continue
+		}
+		if *got != want {
+			t.Errorf("%s: expected %+v, got %+v", address, want, *got)
+		}
+	}
+}
+
+func TestTracePassThrough(t *testing.T) {
+	testFS := newTestFileSystem(map[string]string{
+		"main.tf": `
+variable "region" {}
+variable "name" {}
+
+module "network" {
+  source = "./network"
+  count  = var.enabled ? 1 : 0
+  region = var.region
+  label  = "${var.name}-net"
+}
+
+output "subnet_ids" {
+  value = module.network.subnet_ids
+}
+`,
+		"network/main.tf": `
+variable "region" {}
+variable "label" {}
+
+module "subnets" {
+  source = "./subnets"
+  region = var.region
+}
+
+output "subnet_ids" {
+  value = module.subnets.ids
+}
+`,
+		"network/subnets/main.tf": `
+variable "region" {}
+
+output "ids" {
+  value = []
+}
+`,
+	})
+
+	p := NewParser(testFS, Detail)
+	config, err := p.ParseTerraformWorkspace(".")
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	tree, err := p.ResolveModuleTree(".", config, nil)
+	if err != nil {
+		t.Fatalf("Failed to resolve module tree: %v", err)
+	}
+
+	m, err := p.TracePassThrough(".", tree)
+	if err != nil {
+		t.Fatalf("Failed to trace pass-through: %v", err)
+	}
+
+	if len(m.Edges) != 4 {
+		t.Errorf("Expected 4 edges, got %d: %+v", len(m.Edges), m.Edges)
+	}
+
+	expectedInputs := []string{"module.network.module.subnets.var.region", "module.network.var.region"}
+	if !slices.Equal(m.Inputs["var.region"], expectedInputs) {
+		t.Errorf("Expected var.region to reach %v, got %v", expectedInputs, m.Inputs["var.region"])
+	}
+	if _, ok := m.Inputs["var.name"]; ok {
+		t.Error("Expected var.name not to be a pass-through, it is interpolated")
+	}
+
+	if origin := m.Outputs["output.subnet_ids"]; origin != "module.network.module.subnets.output.ids" {
+		t.Errorf("Expected output.subnet_ids to originate from the subnets module, got %q", origin)
+	}
+}
+
+func TestBlockLabels(t *testing.T) {
+	tests := map[string]string{
+		"resource with one label": `resource "aws_instance" {}`,
+		"backend without label":   "terraform {\n  backend {}\n}\n",
+		"labeled locals":          "locals \"extra\" {\n  a = 1\n}\n",
+		"lifecycle with label":    "resource \"aws_instance\" \"web\" {\n  lifecycle \"x\" {}\n}\n",
+	}
+
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			testFS := newTestFileSystem(map[string]string{"main.tf": content})
+			_, err := NewParser(testFS, Detail).ParseTerraformWorkspace(".")
+			if err == nil || !strings.Contains(err.Error(), "label(s)") {
+				t.Errorf("Expected a label count error, got %v", err)
+			}
+		})
+	}
+
+	testFS := newTestFileSystem(map[string]string{"main.tf": `resource "aws_instance" {}`})
+	config, err := NewParser(testFS, Simple).ParseTerraformWorkspace(".")
+	if err != nil {
+		t.Fatalf("Expected Detail-only blocks to be skipped in Simple mode, got %v", err)
+	}
+	if len(config.Resources) != 0 {
+		t.Errorf("Expected no resources in Simple mode, got %d", len(config.Resources))
+	}
+}
+
+func TestSchemaRegistry(t *testing.T) {
+	for _, blockType := range []string{"variable", "output", "terraform", "locals", "module", "provider", "resource", "data", "ephemeral", "import"} {
+		spec, ok := schema.Lookup(blockType)
+		if !ok || spec.New == nil {
+			t.Errorf("Expected %s blocks to be parsed", blockType)
+		}
+	}
+	for _, blockType := range []string{"moved", "removed", "check"} {
+		if spec, ok := schema.Lookup(blockType); !ok || spec.New != nil {
+			t.Errorf("Expected %s blocks to be validated only", blockType)
+		}
+	}
+	if _, ok := schema.Lookup("resources"); ok {
+		t.Error("Expected unknown block types not to be registered")
+	}
+}
+
+func TestMerge(t *testing.T) {
+	parse := func(content string) *TerraformConfig {
+		t.Helper()
+		config, err := NewParser(newTestFileSystem(map[string]string{"main.tf": content}), Detail).ParseTerraformWorkspace(".")
+		if err != nil {
+			t.Fatalf("Failed to parse: %v", err)
+		}
+		return config
+	}
+	left := `
+variable "region" {
+  default = "us-east-1"
+}
+
+resource "aws_vpc" "main" {}
+`
+	right := `
+variable "region" {
+  default = "eu-west-1"
+}
+
+variable "name" {}
+
+resource "aws_subnet" "a" {}
+`
+
+	config := parse(left)
+	if err := config.Merge(parse(right), MergeError); err == nil || !strings.Contains(err.Error(), `variable "var.region"`) {
+		t.Errorf("Expected a conflict on var.region, got %v", err)
+	}
+	if len(config.Variables) != 1 || len(config.Resources) != 1 || config.Fingerprint == "" {
+		t.Error("Expected a failed merge to leave the configuration unchanged")
+	}
+
+	config = parse(left)
+	if err := config.Merge(parse(right), MergePreferLeft); err != nil {
+		t.Fatalf("Failed to merge: %v", err)
+	}
+	names := []string{}
+	for _, variable := range config.Variables {
+		names = append(names, fmt.Sprintf("%s=%v", variable.Name, variable.Default))
+	}
+	if !slices.Equal(names, []string{"region=us-east-1", "name=<nil>"}) {
+		t.Errorf("Expected the left region variable to be kept, got %v", names)
+	}
+	if len(config.Resources) != 2 || config.Fingerprint != "" {
+		t.Errorf("Expected 2 resources and no fingerprint, got %d resources and %q", len(config.Resources), config.Fingerprint)
+	}
+
+	config = parse(left)
+	if err := config.Merge(parse(right), MergeAppend); err != nil {
+		t.Fatalf("Failed to merge: %v", err)
+	}
+	if len(config.Variables) != 3 {
+		t.Errorf("Expected both region variables to be kept, got %d variables", len(config.Variables))
+	}
+}
+
+func TestLoadSummary(t *testing.T) {
+	testFS := newTestFileSystem(map[string]string{
+		"main.tf": `
+terraform {
+  required_version = ">= 1.5"
+  backend "s3" {
+    bucket = "state"
+    retries = 3
+  }
+}
+
+variable "count" {
+  type    = number
+  default = 2
+}
+
+variable "ratio" {
+  default = 0.5
+}
+
+variable "big" {
+  default = 12345678901234567890
+}
+
+variable "tags" {
+  default = { Name = "test", Size = 3 }
+}
+
+output "id" {
+  value     = aws_vpc.main.id
+  sensitive = true
+}
+
+resource "aws_vpc" "main" {
+  cidr_block = "10.0.0.0/16"
+}
+`,
+	})
+
+	config, err := NewParser(testFS, Detail).WithAST(true).WithFlatten(true).WithKinds(true).ParseTerraformWorkspace(".")
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	summary, err := config.Summary(false)
+	if err != nil {
+		t.Fatalf("Failed to generate summary: %v", err)
+	}
+
+	loaded, err := LoadSummary(summary)
+	if err != nil {
+		t.Fatalf("Failed to load summary: %v", err)
+	}
+	reloaded, err := loaded.Summary(false)
+	if err != nil || !bytes.Equal(reloaded, summary) {
+		t.Errorf("Expected the loaded configuration to give the same summary\nparsed: %s\nloaded: %s", summary, reloaded)
+	}
+	for i, variable := range config.Variables {
+		if !reflect.DeepEqual(loaded.Variables[i].Default, variable.Default) || !reflect.DeepEqual(loaded.Variables[i].FlatDefault, variable.FlatDefault) {
+			t.Errorf("Expected the default of %s to be %#v, got %#v", variable.Name, variable.Default, loaded.Variables[i].Default)
+		}
+	}
+	if !reflect.DeepEqual(loaded.Terraform[0].Backend, config.Terraform[0].Backend) {
+		t.Errorf("Expected the backend %#v, got %#v", config.Terraform[0].Backend, loaded.Terraform[0].Backend)
+	}
+
+	if _, err := LoadSummary([]byte(`{"dataSources": []}`)); err == nil {
+		t.Error("Expected a camelCase summary to be rejected")
+	}
+	if _, err := LoadSummary([]byte(`[]`)); err == nil {
+		t.Error("Expected a summary that is not an object to be rejected")
+	}
+}
+
+func TestTypeConstraint(t *testing.T) {
+	testFS := newTestFileSystem(map[string]string{
+		"main.tf": `
+variable "services" {
+  type = map(object({
+    name = string
+    port = optional(number, 80)
+    tags = optional(map(string), { env = "dev" })
+    pair = tuple([string, bool])
+  }))
+}
+
+variable "anything" {
+  type = any
+}
+
+variable "invalid" {
+  type = lsit(string)
+}
+`,
+	})
+
+	config, err := NewParser(testFS, Simple).ParseTerraformWorkspace(".")
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	services := config.Variables[0].TypeConstraint
+	if services == nil || services.Kind != schema.TypeMap || services.Element.Kind != schema.TypeObject {
+		t.Fatalf("Expected a map of objects, got %+v", services)
+	}
+	attrs := services.Element.Attributes
+	if attrs["name"].Kind != schema.TypeString || attrs["name"].Optional {
+		t.Errorf("Expected name to be a required string, got %+v", attrs["name"])
+	}
+	if attrs["port"].Kind != schema.TypeNumber || !attrs["port"].Optional || attrs["port"].Default != int64(80) {
+		t.Errorf("Expected port to be an optional number defaulting to 80, got %+v", attrs["port"])
+	}
+	if !reflect.DeepEqual(attrs["tags"].Default, map[string]interface{}{"env": "dev"}) || attrs["tags"].Element.Kind != schema.TypeString {
+		t.Errorf("Expected tags to be a map of strings with a default, got %+v", attrs["tags"])
+	}
+	if pair := attrs["pair"]; pair.Kind != schema.TypeTuple || len(pair.Elements) != 2 || pair.Elements[1].Kind != schema.TypeBool {
+		t.Errorf("Expected pair to be a tuple of string and bool, got %+v", pair)
+	}
+
+	if anything := config.Variables[1].TypeConstraint; anything == nil || anything.Kind != schema.TypeAny {
+		t.Errorf("Expected any, got %+v", anything)
+	}
+	if config.Variables[2].TypeConstraint != nil || config.Variables[2].Type != "lsit(string)" {
+		t.Errorf("Expected an invalid type to keep its source text only, got %+v", config.Variables[2])
+	}
+}
+
+func TestNullableAndEphemeral(t *testing.T) {
+	testFS := newTestFileSystem(map[string]string{
+		"main.tf": `
+variable "missing" {}
+
+variable "null_default" {
+  default = null
+}
+
+variable "non_nullable_null" {
+  default  = null
+  nullable = false
+}
+
+variable "non_nullable" {
+  default  = "x"
+  nullable = false
+}
+
+variable "token" {
+  ephemeral = true
+}
+`,
+	})
+
+	config, err := NewParser(testFS, Simple).ParseTerraformWorkspace(".")
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	expected := map[string]struct {
+		required, nullable, ephemeral bool
+	}{
+		"missing":           {true, true, false},
+		"null_default":      {false, true, false},
+		"non_nullable_null": {true, false, false},
+		"non_nullable":      {false, false, false},
+		"token":             {true, true, true},
+	}
+	for _, variable := range config.Variables {
+		want := expected[variable.Name]
+		if variable.Required != want.required || variable.IsNullable() != want.nullable || variable.IsEphemeral() != want.ephemeral {
+			t.Errorf("%s: expected required %v, nullable %v, ephemeral %v, got %v, %v, %v", variable.Name,
+				want.required, want.nullable, want.ephemeral, variable.Required, variable.IsNullable(), variable.IsEphemeral())
+		}
+	}
+
+	summary, err := config.Summary(false)
+	if err != nil {
+		t.Fatalf("Failed to generate summary: %v", err)
+	}
+	for _, expected := range []string{
+		`{"name":"null_default","required":false,"sensitive":false}`,
+		`{"name":"non_nullable_null","required":true,"sensitive":false,"nullable":false}`,
+		`{"name":"token","required":true,"sensitive":false,"ephemeral":true}`,
+	} {
+		if !strings.Contains(string(summary), expected) {
+			t.Errorf("Expected summary to contain %s, got %s", expected, summary)
+		}
+	}
+}
+
+func TestWithContext(t *testing.T) {
+	testFS := newTestFileSystem(map[string]string{
+		"main.tf":         `variable "name" {}`,
+		"network/main.tf": `variable "cidr" {}`,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if _, err := NewParser(testFS, Simple).WithContext(ctx).ParseTerraformWorkspaces("."); err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	cancel()
+	if _, err := NewParser(testFS, Simple).WithContext(ctx).ParseTerraformWorkspace("."); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the parse to be cancelled, got %v", err)
+	}
+
+	timeout := errors.New("timed out")
+	ctx, cancelCause := context.WithCancelCause(context.Background())
+	cancelCause(timeout)
+	if _, err := NewParser(testFS, Simple).WithContext(ctx).ParseTerraformWorkspaces("."); !errors.Is(err, timeout) {
+		t.Errorf("Expected the cause of the cancellation, got %v", err)
+	}
+}
+
+func TestBinaryFiles(t *testing.T) {
+	testFS := newTestFileSystem(map[string]string{
+		"main.tf":     `variable "name" {}`,
+		"vendored.tf": "\x7fELF\x02\x01\x01\x00\x00",
+		"latin1.tf":   "variable \"city\" {\n  default = \"Z\xfcrich\"\n}\n",
+	})
+
+	config, err := NewParser(testFS, Simple).ParseTerraformWorkspace(".")
+	if err != nil {
+		t.Fatalf("Expected binary files to be skipped, got %v", err)
+	}
+	if len(config.Variables) != 1 || config.Variables[0].Name != "name" {
+		t.Errorf("Expected only the variable of main.tf, got %d variables", len(config.Variables))
+	}
+
+	lines := map[string]int{}
+	for _, diagnostic := range config.Diagnostics {
+		if diagnostic.Severity != "warning" {
+			t.Errorf("Expected a warning, got %+v", diagnostic)
+		}
+		lines[diagnostic.Location.File] = diagnostic.Location.Line
+	}
+	if !reflect.DeepEqual(lines, map[string]int{"vendored.tf": 1, "latin1.tf": 2}) {
+		t.Errorf("Expected a warning per binary file with its line, got %v", lines)
+	}
+
+	config, err = NewParser(nil, Simple).ParseHCLBytes("stdin.tf", []byte("\x00\x01"))
+	if err != nil || len(config.Diagnostics) != 1 {
+		t.Errorf("Expected a warning for binary input, got %v (%v)", config, err)
+	}
+}
+
+func TestPruning(t *testing.T) {
+	testFS := newTestFileSystem(map[string]string{
+		"main.tf":                      `module "network" { source = "./modules/network" }`,
+		"modules/network/main.tf":      `module "subnets" { source = "../subnets" }`,
+		"modules/subnets/main.tf":      `module "loop" { source = "../network" }`,
+		"legacy/main.tf":               `variable "old" {}`,
+		"envs/prod/main.tf":            `variable "env" {}`,
+		"envs/prod/regions/eu/main.tf": `variable "region" {}`,
+	})
+
+	p := NewParser(testFS, Detail)
+	config, err := p.ParseTerraformWorkspace(".")
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	// The loop back to modules/network is reported instead of followed
+	tree, err := p.ResolveModuleTree(".", config, nil)
+	if err != nil {
+		t.Fatalf("Failed to resolve modules: %v", err)
+	}
+	loop := tree[0].Children[0].Children[0]
+	if loop.Key != "network.subnets.loop" || loop.Skipped != "cycle" || loop.CycleOf != "network" || loop.Config != nil {
+		t.Errorf("Expected the cycle back to network, got %+v", loop)
+	}
+
+	p.WithPruning(&source.Pruning{MaxDepth: 1})
+	tree, err = p.ResolveModuleTree(".", config, nil)
+	if err != nil {
+		t.Fatalf("Failed to resolve modules: %v", err)
+	}
+	subnets := tree[0].Children[0]
+	if tree[0].Config == nil || subnets.Skipped != "max_depth" || subnets.Dir == "" || subnets.Config != nil {
+		t.Errorf("Expected the calls below depth 1 to be listed but not parsed, got %+v", subnets)
+	}
+
+	p.WithPruning(&source.Pruning{Paths: []string{"network/*"}})
+	tree, err = p.ResolveModuleTree(".", config, nil)
+	if err != nil {
+		t.Fatalf("Failed to resolve modules: %v", err)
+	}
+	if subnets := tree[0].Children[0]; subnets.Skipped != "pruned" {
+		t.Errorf("Expected network.subnets to be pruned by key, got %+v", subnets)
+	}
+
+	p.WithPruning(&source.Pruning{MaxDepth: 2, Paths: []string{"legacy", "modules/*"}})
+	workspaces, err := p.ParseTerraformWorkspaces(".")
+	if err != nil {
+		t.Fatalf("Failed to parse workspaces: %v", err)
+	}
+	if paths := slices.Sorted(maps.Keys(workspaces)); !reflect.DeepEqual(paths, []string{".", "envs/prod"}) {
+		t.Errorf("Expected the pruned and deep directories to be left out, got %v", paths)
+	}
+}
+
+func TestReporter(t *testing.T) {
+	testFS := newTestFileSystem(map[string]string{
+		"main.tf":          "variable \"name\" {\n}\n",
+		"main_override.tf": "variable \"name\" {\n  default = \"web\"\n}\n",
+		"vendored.tf":      "\x00\x01",
+		"broken/main.tf":   `variable "name" {`,
+	})
+
+	var reported []events.Event
+	p := NewParser(testFS, Simple).WithReporter(func(e events.Event) { reported = append(reported, e) })
+	if _, err := p.ParseTerraformWorkspace("."); err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	kinds := []events.Kind{}
+	for _, e := range reported {
+		kinds = append(kinds, e.Kind)
+		if e.Time.IsZero() || e.Directory != "." {
+			t.Errorf("Expected the time and directory of %+v", e)
+		}
+	}
+	expected := []events.Kind{events.FileParsed, events.FileParsed, events.Warning, events.ModuleDone}
+	if !reflect.DeepEqual(kinds, expected) {
+		t.Fatalf("Expected events %v, got %v", expected, kinds)
+	}
+	if reported[0].File != "main.tf" || reported[1].File != "main_override.tf" {
+		t.Errorf("Expected the primary file before the override, got %s and %s", reported[0].File, reported[1].File)
+	}
+	if reported[2].File != "vendored.tf" || reported[2].Severity != "warning" {
+		t.Errorf("Expected a warning for the binary file, got %+v", reported[2])
+	}
+	if reported[3].Counts["variables"] != 1 || reported[3].Error != "" {
+		t.Errorf("Expected the block counts of the directory, got %+v", reported[3])
+	}
+
+	reported = nil
+	if _, err := p.ParseTerraformWorkspace("broken"); err == nil {
+		t.Fatal("Expected the broken directory to fail")
+	}
+	if len(reported) != 1 || reported[0].Kind != events.ModuleDone || reported[0].Error == "" {
+		t.Errorf("Expected a failed module event, got %+v", reported)
+	}
+}
@@ -0,0 +1,143 @@
+package parser
+
+import (
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser/schema"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// TraversalIndex lists every traversal of a workspace, grouped by root name (var, local, module,
+// data, each, a resource type, ...) and then by the address of the referenced object
+type TraversalIndex struct {
+	Directory string                                  `json:"directory"`
+	Roots     map[string]map[string][]*TraversalUsage `json:"roots"`
+}
+
+// TraversalUsage is one occurrence of a traversal
+type TraversalUsage struct {
+	// Traversal is the full traversal as written, e.g. module.vpc.private_subnets[0]
+	Traversal string `json:"traversal"`
+	File      string `json:"file"`
+	Line      int    `json:"line"`
+	Column    int    `json:"column"`
+	// Block is the address of the top-level block containing the traversal, e.g. aws_instance.web
+	Block     string `json:"block"`
+	Attribute string `json:"attribute"`
+}
+
+// Lookup returns the usages of the object at address, e.g. var.region or module.vpc
+func (idx *TraversalIndex) Lookup(address string) []*TraversalUsage {
+	root, _, _ := strings.Cut(address, ".")
+	if usages, ok := idx.Roots[root][address]; ok {
+		return usages
+	}
+	return []*TraversalUsage{}
+}
+
+// IndexTraversals collects the traversals of every expression in the configuration files of dir
+func (p *Parser) IndexTraversals(dir string) (*TraversalIndex, error) {
+	files, _, err := p.loadWorkspaceFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	idx := &TraversalIndex{
+		Directory: dir,
+		Roots:     map[string]map[string][]*TraversalUsage{},
+	}
+
+	for _, wf := range files {
+		for _, block := range wf.file.Body.(*hclsyntax.Body).Blocks {
+			body := block.Body
+			if block.Type == "variable" {
+				// Type constraints are keywords, not references
+				body = &hclsyntax.Body{Attributes: omitAttributes(block.Body.Attributes, "type"), Blocks: block.Body.Blocks}
+			}
+
+			schema.WalkTraversals(body, func(attr *hclsyntax.Attribute, traversal hcl.Traversal) {
+				address := traversalAddress(traversal)
+				if address == "" {
+					return
+				}
+
+				root := traversal.RootName()
+				if idx.Roots[root] == nil {
+					idx.Roots[root] = map[string][]*TraversalUsage{}
+				}
+
+				rng := traversal.SourceRange()
+				idx.Roots[root][address] = append(idx.Roots[root][address], &TraversalUsage{
+					Traversal: strings.TrimSpace(string(rng.SliceBytes(wf.file.Bytes))),
+					File:      rng.Filename,
+					Line:      rng.Start.Line,
+					Column:    rng.Start.Column,
+					Block:     blockAddress(block, attr),
+					Attribute: attr.Name,
+				})
+			})
+		}
+	}
+
+	for _, addresses := range idx.Roots {
+		for _, usages := range addresses {
+			sort.Slice(usages, func(i, j int) bool {
+				if usages[i].File != usages[j].File {
+					return usages[i].File < usages[j].File
+				}
+				if usages[i].Line != usages[j].Line {
+					return usages[i].Line < usages[j].Line
+				}
+				return usages[i].Column < usages[j].Column
+			})
+		}
+	}
+
+	return idx, nil
+}
+
+// traversalAddress is the object address of a traversal, or its first two names for
+// symbols such as each.value and path.module. Lone names (e.g. for expression symbols) are skipped.
+func traversalAddress(traversal hcl.Traversal) string {
+	if address := schema.ReferenceAddress(traversal); address != "" {
+		return address
+	}
+
+	if len(traversal) < 2 {
+		return ""
+	}
+	attr, ok := traversal[1].(hcl.TraverseAttr)
+	if !ok {
+		return ""
+	}
+	return traversal.RootName() + "." + attr.Name
+}
+
+// blockAddress is the address of a top-level block. For locals blocks it is the address of
+// the local value that holds the attribute.
+func blockAddress(block *hclsyntax.Block, attr *hclsyntax.Attribute) string {
+	switch block.Type {
+	case "locals":
+		return "local." + attr.Name
+	case "variable":
+		return "var." + strings.Join(block.Labels, ".")
+	case "resource":
+		return strings.Join(block.Labels, ".")
+	default:
+		return strings.Join(append([]string{block.Type}, block.Labels...), ".")
+	}
+}
+
+func omitAttributes(attrs hclsyntax.Attributes, names ...string) hclsyntax.Attributes {
+	kept := hclsyntax.Attributes{}
+	for name, attr := range attrs {
+		if !slices.Contains(names, name) {
+			kept[name] = attr
+		}
+	}
+	return kept
+}
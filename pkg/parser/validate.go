@@ -0,0 +1,122 @@
+package parser
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser/schema"
+	"github.com/agext/levenshtein"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// Diagnostic is a problem found by block validation, e.g. an argument Terraform does not accept
+type Diagnostic struct {
+	Severity string    `json:"severity"`
+	Summary  string    `json:"summary"`
+	Detail   string    `json:"detail"`
+	Location *Location `json:"location"`
+}
+
+// validateBlocks checks the blocks of a file against the block specs of the schema registry. It returns the
+// diagnostics and the top-level blocks they concern, mapped to true when the labels of the block
+// are invalid and it cannot be parsed.
+func validateBlocks(file *hcl.File) ([]*Diagnostic, map[*hclsyntax.Block]bool) {
+	v := &blockValidator{flagged: map[*hclsyntax.Block]bool{}}
+	v.validateBody(file.Body.(*hclsyntax.Body), schema.TopLevel(), nil, "")
+	return v.diagnostics, v.flagged
+}
+
+type blockValidator struct {
+	diagnostics []*Diagnostic
+	flagged     map[*hclsyntax.Block]bool
+}
+
+// report adds a diagnostic about the top-level block top at the start of rng, whose file is the
+// override file for merged arguments and blocks
+func (v *blockValidator) report(top *hclsyntax.Block, summary, detail string, rng hcl.Range) {
+	pos := rng.Start
+	v.diagnostics = append(v.diagnostics, &Diagnostic{
+		Severity: "error",
+		Summary:  summary,
+		Detail:   detail,
+		Location: &Location{File: rng.Filename, Line: pos.Line, Column: pos.Column},
+	})
+	if _, ok := v.flagged[top]; !ok {
+		v.flagged[top] = false
+	}
+}
+
+// validateBody checks a body of the top-level block top against spec; context names the
+// enclosing block in messages, e.g. `variable "region" block`
+func (v *blockValidator) validateBody(body *hclsyntax.Body, spec *schema.Spec, top *hclsyntax.Block, context string) {
+	where, in := "here", ""
+	if context != "" {
+		where, in = "in "+context, " in "+context
+	}
+
+	for _, name := range sortedAttributeNames(body.Attributes) {
+		attr := body.Attributes[name]
+		if spec.AnyAttributes || slices.Contains(spec.Attributes, name) {
+			continue
+		}
+		detail := fmt.Sprintf("An argument named %q is not expected %s.", name, where)
+		if suggestion := suggest(name, spec.Attributes); suggestion != "" {
+			detail += fmt.Sprintf(" Did you mean %q?", suggestion)
+		}
+		v.report(top, "Unsupported argument", detail, attr.NameRange)
+	}
+
+	for _, name := range spec.Required {
+		if _, ok := body.Attributes[name]; !ok {
+			v.report(top, "Missing required argument", fmt.Sprintf("The argument %q is required %s, but no definition was found.", name, where), body.SrcRange)
+		}
+	}
+
+	for _, block := range body.Blocks {
+		blockTop := top
+		if top == nil {
+			blockTop = block
+		}
+
+		nested, known := spec.Blocks[block.Type]
+		if !known {
+			if !spec.AnyBlocks {
+				detail := fmt.Sprintf("Blocks of type %q are not expected %s.", block.Type, where)
+				if suggestion := suggest(block.Type, spec.BlockTypes()); suggestion != "" {
+					detail += fmt.Sprintf(" Did you mean %q?", suggestion)
+				}
+				v.report(blockTop, "Unsupported block type", detail, block.TypeRange)
+				v.flagged[blockTop] = v.flagged[blockTop] || top == nil
+			}
+			continue
+		}
+
+		if len(block.Labels) != nested.Labels {
+			v.report(blockTop, "Invalid number of labels", fmt.Sprintf("A %s block%s must have %d label(s), but has %d.", block.Type, in, nested.Labels, len(block.Labels)), block.TypeRange)
+			v.flagged[blockTop] = v.flagged[blockTop] || top == nil
+			continue
+		}
+
+		v.validateBody(block.Body, nested, blockTop, blockContext(block))
+	}
+}
+
+func blockContext(block *hclsyntax.Block) string {
+	context := block.Type
+	for _, label := range block.Labels {
+		context += fmt.Sprintf(" %q", label)
+	}
+	return context + " block"
+}
+
+// suggest returns the candidate closest to name when it is likely a typo of it
+func suggest(name string, candidates []string) string {
+	best, bestDistance := "", 3
+	for _, candidate := range candidates {
+		if distance := levenshtein.Distance(name, candidate, nil); distance < bestDistance {
+			best, bestDistance = candidate, distance
+		}
+	}
+	return best
+}
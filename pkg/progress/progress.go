@@ -0,0 +1,135 @@
+package progress
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Mode string
+
+const (
+	// ModeBar redraws a progress bar on a terminal and falls back to ModePlain otherwise
+	ModeBar Mode = "bar"
+	// ModePlain writes a progress line at most every Interval
+	ModePlain Mode = "plain"
+	ModeNone  Mode = "none"
+)
+
+// Interval is the minimum time between two plain progress lines
+const Interval = 2 * time.Second
+
+const barWidth = 30
+
+// ParseMode validates a progress mode given on the command line
+func ParseMode(s string) (Mode, error) {
+	switch mode := Mode(s); mode {
+	case ModeBar, ModePlain, ModeNone:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown progress mode %q (expected plain, bar or none)", s)
+	}
+}
+
+// Tracker reports the progress of a counted task, e.g. files parsed or repositories fetched.
+// A nil Tracker is valid and reports nothing.
+type Tracker struct {
+	mu       sync.Mutex
+	mode     Mode
+	w        io.Writer
+	label    string
+	total    int
+	done     int
+	lastLine time.Time
+}
+
+// New creates a tracker writing to stderr. label names the counted items, e.g. "modules parsed"
+func New(mode Mode, label string) *Tracker {
+	if mode == ModeNone {
+		return nil
+	}
+	if mode == ModeBar && !isTerminal(os.Stderr) {
+		mode = ModePlain
+	}
+	return &Tracker{mode: mode, w: os.Stderr, label: label}
+}
+
+// SetTotal sets the number of items once it is known
+func (t *Tracker) SetTotal(total int) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.total = total
+	t.render(false, "")
+}
+
+// Increment marks one more item as done. item is shown next to the counter
+func (t *Tracker) Increment(item string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.done++
+	t.render(false, item)
+}
+
+// Finish writes the final state
+func (t *Tracker) Finish() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.render(true, "")
+	if t.mode == ModeBar {
+		fmt.Fprintln(t.w)
+	}
+}
+
+func (t *Tracker) render(final bool, item string) {
+	counter := fmt.Sprintf("%d", t.done)
+	if t.total > 0 {
+		counter = fmt.Sprintf("%d/%d", t.done, t.total)
+	}
+
+	switch t.mode {
+	case ModeBar:
+		filled := 0
+		if t.total > 0 {
+			filled = min(barWidth, t.done*barWidth/t.total)
+		}
+		line := fmt.Sprintf("[%s%s] %s %s", strings.Repeat("=", filled), strings.Repeat(" ", barWidth-filled), counter, t.label)
+		if item != "" {
+			line += " " + item
+		}
+		// Clear the rest of the previous, possibly longer, line
+		fmt.Fprintf(t.w, "\r%s\033[K", line)
+
+	case ModePlain:
+		now := time.Now()
+		if !final && now.Sub(t.lastLine) < Interval {
+			return
+		}
+		t.lastLine = now
+
+		line := fmt.Sprintf("progress: %s %s", counter, t.label)
+		if item != "" {
+			line += " (" + item + ")"
+		}
+		fmt.Fprintln(t.w, line)
+	}
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,100 @@
+package progress
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPlainThrottling(t *testing.T) {
+	var buf bytes.Buffer
+	tracker := &Tracker{mode: ModePlain, w: &buf, label: "modules parsed"}
+
+	tracker.SetTotal(3)
+	tracker.Increment("a")
+	tracker.Increment("b")
+	// Pretend the last line is older than Interval
+	tracker.lastLine = time.Now().Add(-Interval)
+	tracker.Increment("c")
+	tracker.Finish()
+
+	expected := []string{
+		"progress: 0/3 modules parsed",
+		"progress: 3/3 modules parsed (c)",
+		"progress: 3/3 modules parsed",
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if strings.Join(lines, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected lines %q, got %q", expected, lines)
+	}
+}
+
+func TestBarRendering(t *testing.T) {
+	tests := []struct {
+		name     string
+		total    int
+		done     int
+		item     string
+		expected string
+	}{
+		{"unknown total", 0, 2, "", "\r[" + strings.Repeat(" ", barWidth) + "] 2 repos fetched\033[K"},
+		{"half", 4, 2, "", "\r[" + strings.Repeat("=", 15) + strings.Repeat(" ", 15) + "] 2/4 repos fetched\033[K"},
+		{"complete with item", 2, 2, "app", "\r[" + strings.Repeat("=", barWidth) + "] 2/2 repos fetched app\033[K"},
+		{"more than total", 1, 3, "", "\r[" + strings.Repeat("=", barWidth) + "] 3/1 repos fetched\033[K"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			tracker := &Tracker{mode: ModeBar, w: &buf, label: "repos fetched", total: tt.total, done: tt.done}
+			tracker.render(false, tt.item)
+			if buf.String() != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, buf.String())
+			}
+		})
+	}
+}
+
+func TestBarFinish(t *testing.T) {
+	var buf bytes.Buffer
+	tracker := &Tracker{mode: ModeBar, w: &buf, label: "files", total: 1}
+	tracker.Increment("main.tf")
+	tracker.Finish()
+
+	if !strings.HasSuffix(buf.String(), "] 1/1 files\033[K\n") {
+		t.Errorf("Expected the final bar to end the line, got %q", buf.String())
+	}
+}
+
+func TestNilTracker(t *testing.T) {
+	tracker := New(ModeNone, "modules parsed")
+	if tracker != nil {
+		t.Fatalf("Expected no tracker for mode none, got %+v", tracker)
+	}
+
+	// None of these may panic
+	tracker.SetTotal(2)
+	tracker.Increment("a")
+	tracker.Finish()
+}
+
+func TestParseMode(t *testing.T) {
+	tests := []struct {
+		input string
+		mode  Mode
+		err   bool
+	}{
+		{"bar", ModeBar, false},
+		{"plain", ModePlain, false},
+		{"none", ModeNone, false},
+		{"fancy", "", true},
+	}
+
+	for _, tt := range tests {
+		mode, err := ParseMode(tt.input)
+		if (err != nil) != tt.err || mode != tt.mode {
+			t.Errorf("%q: expected %q (error %v), got %q (%v)", tt.input, tt.mode, tt.err, mode, err)
+		}
+	}
+}
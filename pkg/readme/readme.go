@@ -0,0 +1,240 @@
+// Package readme checks that the documentation embedded in module READMEs, between the markers
+// terraform-docs also uses, matches the Markdown rendered from the current configuration.
+package readme
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/filesystem"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/logger"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/progress"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/source"
+)
+
+const (
+	BeginMarker = "<!-- BEGIN_TF_DOCS -->"
+	EndMarker   = "<!-- END_TF_DOCS -->"
+)
+
+type Status string
+
+const (
+	// StatusFresh is a README whose embedded docs match the configuration
+	StatusFresh Status = "fresh"
+	// StatusStale is a README whose embedded docs differ from the configuration
+	StatusStale Status = "stale"
+	// StatusError is a README with broken markers, or a module that fails to parse
+	StatusError Status = "error"
+)
+
+// Module is the check of the README of a single module directory
+type Module struct {
+	// Path is relative to the checked root
+	Path   string `json:"path"`
+	README string `json:"readme"`
+	Status Status `json:"status"`
+	// Line is the first line of the README that differs from the current docs
+	Line  int    `json:"line,omitempty"`
+	Error string `json:"error,omitempty"`
+	// Updated is the README content with the current docs embedded, set when stale
+	Updated []byte `json:"-"`
+}
+
+type Report struct {
+	Modules []*Module `json:"modules"`
+	Fresh   int       `json:"fresh"`
+	Stale   int       `json:"stale"`
+	Errors  int       `json:"errors"`
+	// Unchecked lists the module paths without a README with docs markers
+	Unchecked []string `json:"unchecked,omitempty"`
+}
+
+// HasStale reports whether a README is stale or could not be checked
+func (r *Report) HasStale() bool {
+	return r.Stale > 0 || r.Errors > 0
+}
+
+// Section returns the content between the docs markers of a README, and false when the README
+// has no markers
+func Section(content string) (string, bool, error) {
+	begin := strings.Index(content, BeginMarker)
+	end := strings.Index(content, EndMarker)
+	switch {
+	case begin < 0 && end < 0:
+		return "", false, nil
+	case begin < 0:
+		return "", false, fmt.Errorf("%s without %s", EndMarker, BeginMarker)
+	case end < 0:
+		return "", false, fmt.Errorf("%s without %s", BeginMarker, EndMarker)
+	case end < begin:
+		return "", false, fmt.Errorf("%s before %s", EndMarker, BeginMarker)
+	}
+	return content[begin+len(BeginMarker) : end], true, nil
+}
+
+// Embed replaces the content between the docs markers of a README with docs
+func Embed(content, docs string) (string, error) {
+	_, ok, err := Section(content)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", fmt.Errorf("no %s marker", BeginMarker)
+	}
+	begin := strings.Index(content, BeginMarker) + len(BeginMarker)
+	end := strings.Index(content, EndMarker)
+	return content[:begin] + "\n" + strings.TrimRight(docs, "\n") + "\n" + content[end:], nil
+}
+
+// Compare compares the docs embedded in a README with docs and returns the first line of the
+// README that differs, or 0 when they match. Line endings and trailing spaces are ignored, as
+// well as blank lines around the docs.
+func Compare(content, docs string) (int, error) {
+	section, ok, err := Section(content)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, fmt.Errorf("no %s marker", BeginMarker)
+	}
+
+	// The section starts on the line of the begin marker
+	line := strings.Count(content[:strings.Index(content, BeginMarker)], "\n") + 1
+	embedded, skipped := normalize(section)
+	expected, _ := normalize(docs)
+	line += skipped
+
+	for i := range max(len(embedded), len(expected)) {
+		if i >= len(embedded) || i >= len(expected) || embedded[i] != expected[i] {
+			return line + i, nil
+		}
+	}
+	return 0, nil
+}
+
+// normalize splits text into lines without trailing spaces or surrounding blank lines, and
+// returns the number of lines removed before the first one
+func normalize(text string) ([]string, int) {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	first := slices.IndexFunc(lines, func(line string) bool { return line != "" })
+	if first < 0 {
+		return nil, 0
+	}
+	last := len(lines) - 1
+	for lines[last] == "" {
+		last--
+	}
+	return lines[first : last+1], first
+}
+
+// findREADME returns the path of the README.md of dir, matched case-insensitively, or "" when
+// there is none
+func findREADME(fs filesystem.FileReader, dir string) (string, error) {
+	entries, err := fs.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.EqualFold(entry.Name(), "README.md") {
+			return filepath.Join(dir, entry.Name()), nil
+		}
+	}
+	return "", nil
+}
+
+// Check checks the README of every directory under root that contains .tf files (see
+// source.ConfigDirs). Only READMEs with docs markers are checked, against the Markdown of the
+// module (see parser.TerraformConfig.Markdown); each directory is reported to tracker (which
+// may be nil).
+func Check(fs filesystem.FileReader, root string, tracker *progress.Tracker) (*Report, error) {
+	report := &Report{Modules: []*Module{}}
+	p := parser.NewParser(fs, parser.Detail)
+
+	dirs, err := source.ConfigDirs(fs, root)
+	if err != nil {
+		return nil, err
+	}
+	tracker.SetTotal(len(dirs))
+	defer tracker.Finish()
+
+	for _, dir := range dirs {
+		rel, err := filepath.Rel(root, dir)
+		if err != nil {
+			rel = dir
+		}
+
+		module, err := checkModule(fs, p, dir, rel)
+		tracker.Increment(rel)
+		if err != nil {
+			return nil, err
+		}
+		if module == nil {
+			report.Unchecked = append(report.Unchecked, rel)
+			continue
+		}
+
+		switch module.Status {
+		case StatusFresh:
+			report.Fresh++
+		case StatusStale:
+			report.Stale++
+		default:
+			report.Errors++
+		}
+		report.Modules = append(report.Modules, module)
+	}
+
+	slices.SortFunc(report.Modules, func(a, b *Module) int { return strings.Compare(a.Path, b.Path) })
+	return report, nil
+}
+
+// checkModule checks the README of dir, or returns nil when it has no README with docs markers
+func checkModule(fs filesystem.FileReader, p *parser.Parser, dir, rel string) (*Module, error) {
+	path, err := findREADME(fs, dir)
+	if err != nil || path == "" {
+		return nil, err
+	}
+	content, err := fs.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	module := &Module{Path: rel, README: path}
+	if _, ok, err := Section(string(content)); err != nil {
+		module.Status, module.Error = StatusError, err.Error()
+		return module, nil
+	} else if !ok {
+		return nil, nil
+	}
+
+	tfconfig, err := p.ParseTerraformWorkspace(dir)
+	if err != nil {
+		logger.InfoKV("Module with docs markers failed to parse", "path", rel, "error", err)
+		module.Status, module.Error = StatusError, err.Error()
+		return module, nil
+	}
+
+	docs := tfconfig.Markdown()
+	line, err := Compare(string(content), docs)
+	if err != nil {
+		return nil, err
+	}
+	if line == 0 {
+		module.Status = StatusFresh
+		return module, nil
+	}
+
+	updated, err := Embed(string(content), docs)
+	if err != nil {
+		return nil, err
+	}
+	module.Status, module.Line, module.Updated = StatusStale, line, []byte(updated)
+	return module, nil
+}
@@ -0,0 +1,84 @@
+package readme
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/filesystem"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+
+	"github.com/spf13/afero"
+)
+
+func TestCheck(t *testing.T) {
+	memFs := afero.NewMemMapFs()
+	fs := filesystem.NewAferoAdapter(memFs)
+
+	variables := `variable "name" {
+  type        = string
+  description = "Name of the bucket"
+}
+`
+	modules := []string{"fresh", "stale", "plain", "broken"}
+	for _, module := range modules {
+		if err := afero.WriteFile(memFs, "repo/"+module+"/variables.tf", []byte(variables), 0644); err != nil {
+			t.Fatalf("Failed to write variables.tf: %v", err)
+		}
+	}
+
+	tfconfig, err := parser.NewParser(fs, parser.Detail).ParseTerraformWorkspace("repo/fresh")
+	if err != nil {
+		t.Fatalf("Failed to parse module: %v", err)
+	}
+	docs := tfconfig.Markdown()
+
+	readmes := map[string]string{
+		"repo/fresh/README.md":  "# Fresh\n\n" + BeginMarker + "\r\n" + docs + "\n\n" + EndMarker + "\n",
+		"repo/stale/readme.md":  "# Stale\n\n" + BeginMarker + "\n" + strings.Replace(docs, "Name of the bucket", "Bucket name", 1) + EndMarker + "\n",
+		"repo/plain/README.md":  "# Plain\n",
+		"repo/broken/README.md": "# Broken\n\n" + BeginMarker + "\n",
+	}
+	for path, content := range readmes {
+		if err := afero.WriteFile(memFs, path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+
+	report, err := Check(fs, "repo", nil)
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+
+	if report.Fresh != 1 || report.Stale != 1 || report.Errors != 1 || !report.HasStale() {
+		t.Errorf("Expected 1 fresh, 1 stale and 1 error, got %+v", report)
+	}
+	if len(report.Unchecked) != 1 || report.Unchecked[0] != "plain" {
+		t.Errorf("Expected plain to be unchecked, got %v", report.Unchecked)
+	}
+
+	statuses := map[string]*Module{}
+	for _, module := range report.Modules {
+		statuses[module.Path] = module
+	}
+	if statuses["fresh"].Status != StatusFresh {
+		t.Errorf("Expected fresh README, got %+v", statuses["fresh"])
+	}
+	if statuses["broken"].Status != StatusError || statuses["broken"].Error == "" {
+		t.Errorf("Expected broken markers to be an error, got %+v", statuses["broken"])
+	}
+
+	stale := statuses["stale"]
+	if stale.Status != StatusStale || stale.README != "repo/stale/readme.md" {
+		t.Fatalf("Expected stale README, got %+v", stale)
+	}
+	staleLines := strings.Split(readmes["repo/stale/readme.md"], "\n")
+	if stale.Line == 0 || !strings.Contains(staleLines[stale.Line-1], "Bucket name") {
+		t.Errorf("Expected the differing line to hold the old description, got line %d", stale.Line)
+	}
+	if line, err := Compare(string(stale.Updated), docs); err != nil || line != 0 {
+		t.Errorf("Expected the updated README to be fresh, got line %d (%v)", line, err)
+	}
+	if !strings.HasPrefix(string(stale.Updated), "# Stale\n") {
+		t.Errorf("Expected the content outside the markers to be kept, got %q", stale.Updated)
+	}
+}
@@ -0,0 +1,218 @@
+// Package release builds the release archives of the CLI: reproducible binaries for several
+// platforms, packaged with their completions and default configuration, and their checksums.
+package release
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/logger"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/output"
+)
+
+const versionPackage = "github.com/Yunsang-Jeong/terraform-config-parser/version"
+
+// Target is a platform to build for, e.g. linux/amd64
+type Target struct {
+	OS   string
+	Arch string
+}
+
+func (t Target) String() string {
+	return t.OS + "/" + t.Arch
+}
+
+// DefaultTargets are the platforms of a release
+var DefaultTargets = []Target{
+	{"linux", "amd64"}, {"linux", "arm64"},
+	{"darwin", "amd64"}, {"darwin", "arm64"},
+	{"windows", "amd64"}, {"windows", "arm64"},
+}
+
+// ParseTarget parses an os/arch pair
+func ParseTarget(s string) (Target, error) {
+	goos, goarch, ok := strings.Cut(s, "/")
+	if !ok || goos == "" || goarch == "" {
+		return Target{}, fmt.Errorf("invalid target %q (expected os/arch, e.g. linux/amd64)", s)
+	}
+	return Target{OS: goos, Arch: goarch}, nil
+}
+
+type Options struct {
+	Version string
+	Commit  string
+	// BuildDate is recorded in the binaries and used as the time of every archive entry; a
+	// fixed date, such as the commit time, makes the archives reproducible
+	BuildDate time.Time
+	Targets   []Target
+	// Dist is the output directory
+	Dist string
+	// Binary is the name of the executable, without .exe
+	Binary string
+	// Package is the main package to build, e.g. "."
+	Package string
+	// Files are added to every archive next to the binary, keyed by slash-separated path
+	Files map[string][]byte
+	// SelfTest runs a binary built for the host platform and fails the release when it fails
+	SelfTest func(ctx context.Context, binary string) error
+}
+
+// Artifact is the archive of one target
+type Artifact struct {
+	Target  string `json:"target"`
+	Archive string `json:"archive"`
+	SHA256  string `json:"sha256"`
+	// SelfTested is set when the binary passed the self-test
+	SelfTested bool `json:"self_tested,omitempty"`
+}
+
+type Result struct {
+	Version   string      `json:"version"`
+	Commit    string      `json:"commit"`
+	BuildDate string      `json:"build_date"`
+	Artifacts []*Artifact `json:"artifacts"`
+	// Checksums is the checksum file listing the SHA-256 of every archive
+	Checksums string `json:"checksums"`
+}
+
+// Build builds and packages every target into opts.Dist. Binaries are built without cgo,
+// with trimmed paths and without build IDs, so that the same sources, toolchain and options
+// give identical archives.
+func Build(ctx context.Context, opts Options) (*Result, error) {
+	if opts.Version == "" {
+		return nil, fmt.Errorf("a release needs a version")
+	}
+
+	result := &Result{Version: opts.Version, Commit: opts.Commit, BuildDate: opts.BuildDate.UTC().Format(time.RFC3339), Artifacts: []*Artifact{}}
+	work, err := os.MkdirTemp("", "release-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(work)
+
+	checksums := strings.Builder{}
+	for _, target := range opts.Targets {
+		binary := opts.Binary
+		if target.OS == "windows" {
+			binary += ".exe"
+		}
+		binaryPath := filepath.Join(work, target.OS+"_"+target.Arch, binary)
+
+		logger.InfoKV("Building release binary", "target", target.String(), "version", opts.Version)
+		if err := buildBinary(ctx, opts, target, binaryPath); err != nil {
+			return nil, err
+		}
+
+		artifact := &Artifact{Target: target.String()}
+		if opts.SelfTest != nil && target.OS == runtime.GOOS && target.Arch == runtime.GOARCH {
+			if err := opts.SelfTest(ctx, binaryPath); err != nil {
+				return nil, fmt.Errorf("self-test of %s failed: %w", target, err)
+			}
+			artifact.SelfTested = true
+		}
+
+		content, err := os.ReadFile(binaryPath)
+		if err != nil {
+			return nil, err
+		}
+		files := maps.Clone(opts.Files)
+		if files == nil {
+			files = map[string][]byte{}
+		}
+		files[binary] = content
+
+		var buf bytes.Buffer
+		if err := writeArchive(&buf, files, binary, opts.BuildDate); err != nil {
+			return nil, err
+		}
+		name := fmt.Sprintf("%s_%s_%s_%s.tar.gz", opts.Binary, strings.TrimPrefix(opts.Version, "v"), target.OS, target.Arch)
+		artifact.Archive = filepath.Join(opts.Dist, name)
+		if err := output.WriteFile(artifact.Archive, buf.Bytes()); err != nil {
+			return nil, err
+		}
+
+		sum := sha256.Sum256(buf.Bytes())
+		artifact.SHA256 = hex.EncodeToString(sum[:])
+		fmt.Fprintf(&checksums, "%s  %s\n", artifact.SHA256, name)
+		result.Artifacts = append(result.Artifacts, artifact)
+	}
+
+	result.Checksums = filepath.Join(opts.Dist, "checksums.txt")
+	if err := output.WriteFile(result.Checksums, []byte(checksums.String())); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// LDFlags are the linker flags setting the version metadata and stripping the binary
+func LDFlags(opts Options) string {
+	return strings.Join([]string{
+		"-s", "-w", "-buildid=",
+		"-X " + versionPackage + ".Version=" + opts.Version,
+		"-X " + versionPackage + ".Commit=" + opts.Commit,
+		"-X " + versionPackage + ".BuildDate=" + opts.BuildDate.UTC().Format(time.RFC3339),
+	}, " ")
+}
+
+func buildBinary(ctx context.Context, opts Options, target Target, path string) error {
+	cmd := exec.CommandContext(ctx, "go", "build", "-trimpath", "-buildvcs=false", "-ldflags", LDFlags(opts), "-o", path, opts.Package)
+	cmd.Env = append(os.Environ(), "CGO_ENABLED=0", "GOOS="+target.OS, "GOARCH="+target.Arch, "GOFLAGS=")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to build %s: %w\n%s", target, err, out)
+	}
+	return nil
+}
+
+// writeArchive writes files as a gzipped tar in path order, with fixed owners and times so that
+// the archive only depends on its content. executable is written with mode 0755.
+func writeArchive(w *bytes.Buffer, files map[string][]byte, executable string, mtime time.Time) error {
+	gz, err := gzip.NewWriterLevel(w, gzip.BestCompression)
+	if err != nil {
+		return err
+	}
+	tw := tar.NewWriter(gz)
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		mode := int64(0o644)
+		if name == executable {
+			mode = 0o755
+		}
+		header := &tar.Header{
+			Name:    name,
+			Mode:    mode,
+			Size:    int64(len(files[name])),
+			ModTime: mtime.UTC().Truncate(time.Second),
+			Format:  tar.FormatPAX,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return fmt.Errorf("failed to write archive entry %s: %w", name, err)
+		}
+		if _, err := tw.Write(files[name]); err != nil {
+			return fmt.Errorf("failed to write archive entry %s: %w", name, err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
@@ -0,0 +1,94 @@
+package release
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseTarget(t *testing.T) {
+	target, err := ParseTarget("darwin/arm64")
+	if err != nil {
+		t.Fatalf("Failed to parse target: %v", err)
+	}
+	if target != (Target{OS: "darwin", Arch: "arm64"}) || target.String() != "darwin/arm64" {
+		t.Errorf("Unexpected target %+v", target)
+	}
+
+	for _, invalid := range []string{"linux", "linux/", "/amd64", ""} {
+		if _, err := ParseTarget(invalid); err == nil {
+			t.Errorf("Expected %q to be rejected", invalid)
+		}
+	}
+}
+
+func TestLDFlags(t *testing.T) {
+	flags := LDFlags(Options{Version: "v1.2.3", Commit: "0123abcd", BuildDate: time.Date(2024, 5, 1, 19, 0, 0, 0, time.FixedZone("KST", 9*3600))})
+
+	for _, expected := range []string{
+		"-s -w -buildid=",
+		"-X " + versionPackage + ".Version=v1.2.3",
+		"-X " + versionPackage + ".Commit=0123abcd",
+		"-X " + versionPackage + ".BuildDate=2024-05-01T10:00:00Z",
+	} {
+		if !strings.Contains(flags, expected) {
+			t.Errorf("Expected %q in %q", expected, flags)
+		}
+	}
+}
+
+func TestWriteArchive(t *testing.T) {
+	mtime := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	files := map[string][]byte{
+		"tfcp":                  []byte("binary"),
+		"completions/tfcp.bash": []byte("complete"),
+		"README.md":             []byte("# tfcp"),
+	}
+
+	var first, second bytes.Buffer
+	if err := writeArchive(&first, files, "tfcp", mtime); err != nil {
+		t.Fatalf("Failed to write archive: %v", err)
+	}
+	if err := writeArchive(&second, files, "tfcp", mtime.Add(500*time.Millisecond)); err != nil {
+		t.Fatalf("Failed to write archive: %v", err)
+	}
+	if !bytes.Equal(first.Bytes(), second.Bytes()) {
+		t.Error("Expected identical archives for the same files and build date")
+	}
+
+	gz, err := gzip.NewReader(&first)
+	if err != nil {
+		t.Fatalf("Failed to read archive: %v", err)
+	}
+	tr := tar.NewReader(gz)
+	names := []string{}
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Failed to read archive: %v", err)
+		}
+		names = append(names, header.Name)
+
+		expectedMode := int64(0o644)
+		if header.Name == "tfcp" {
+			expectedMode = 0o755
+		}
+		if header.Mode != expectedMode {
+			t.Errorf("Expected mode %o for %s, got %o", expectedMode, header.Name, header.Mode)
+		}
+		if !header.ModTime.Equal(mtime) {
+			t.Errorf("Expected time %v for %s, got %v", mtime, header.Name, header.ModTime)
+		}
+	}
+
+	if strings.Join(names, ",") != "README.md,completions/tfcp.bash,tfcp" {
+		t.Errorf("Expected entries in path order, got %v", names)
+	}
+}
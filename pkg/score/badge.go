@@ -0,0 +1,46 @@
+package score
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// badgeColor maps the score to the usual shields.io colors
+func badgeColor(score float64) string {
+	switch {
+	case score >= 90:
+		return "#4c1"
+	case score >= 75:
+		return "#97ca00"
+	case score >= 60:
+		return "#dfb317"
+	case score >= 40:
+		return "#fe7d37"
+	default:
+		return "#e05d44"
+	}
+}
+
+// Badge renders the score as a flat SVG badge, e.g. for a README or a dashboard
+func (r *Report) Badge(label string) string {
+	value := fmt.Sprintf("%.0f%%", r.Score)
+	escaped := html.EscapeString(label)
+
+	// Approximate text widths of the 11px Verdana used by shields.io badges
+	labelWidth := 10 + 7*len(label)
+	valueWidth := 10 + 7*len(value)
+	width := labelWidth + valueWidth
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="20" role="img" aria-label="%s: %s">`, width, escaped, value)
+	fmt.Fprintf(&sb, `<rect width="%d" height="20" rx="3" fill="#555"/>`, width)
+	fmt.Fprintf(&sb, `<rect x="%d" width="%d" height="20" rx="3" fill="%s"/>`, labelWidth, valueWidth, badgeColor(r.Score))
+	fmt.Fprintf(&sb, `<rect x="%d" width="4" height="20" fill="%s"/>`, labelWidth, badgeColor(r.Score))
+	sb.WriteString(`<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,sans-serif" font-size="11">`)
+	fmt.Fprintf(&sb, `<text x="%d" y="14">%s</text>`, labelWidth/2, escaped)
+	fmt.Fprintf(&sb, `<text x="%d" y="14">%s</text>`, labelWidth+valueWidth/2, value)
+	sb.WriteString(`</g></svg>`)
+
+	return sb.String()
+}
@@ -0,0 +1,226 @@
+package score
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/config"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/filesystem"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/lint"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/source"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+// Metric names, as used in the weights of the config file
+const (
+	MetricDescriptions = "descriptions"
+	MetricPinned       = "pinned_versions"
+	MetricValidations  = "validations"
+	MetricBackend      = "backend"
+	MetricFormatted    = "formatted"
+	MetricTests        = "tests"
+)
+
+// DefaultWeights are the metric weights used when the config file does not set them
+var DefaultWeights = map[string]float64{
+	MetricDescriptions: 25,
+	MetricPinned:       25,
+	MetricValidations:  10,
+	MetricBackend:      10,
+	MetricFormatted:    15,
+	MetricTests:        15,
+}
+
+// Metric is one input of the score. Value ranges from 0 to 1.
+type Metric struct {
+	Name   string  `json:"name"`
+	Value  float64 `json:"value"`
+	Weight float64 `json:"weight"`
+	// Applicable is false when the workspace has nothing to measure, e.g. no variables for validations;
+	// such metrics do not count towards the score
+	Applicable bool   `json:"applicable"`
+	Detail     string `json:"detail"`
+}
+
+type Report struct {
+	// Score is the weighted average of the applicable metrics, from 0 to 100
+	Score   float64   `json:"score"`
+	Metrics []*Metric `json:"metrics"`
+}
+
+// Compute scores the workspace in dir, parsed in detail mode as tfconfig
+func Compute(fs filesystem.FileReader, dir string, tfconfig *parser.TerraformConfig, cfg *config.Config) (*Report, error) {
+	formatted, err := formattedMetric(fs, dir)
+	if err != nil {
+		return nil, err
+	}
+	tests, err := testsMetric(fs, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{Metrics: []*Metric{
+		descriptionsMetric(tfconfig),
+		pinnedMetric(tfconfig),
+		validationsMetric(tfconfig),
+		backendMetric(tfconfig),
+		formatted,
+		tests,
+	}}
+
+	weights := cfg.ScoreWeights(DefaultWeights)
+	total, sum := 0.0, 0.0
+	for _, metric := range report.Metrics {
+		metric.Weight = weights[metric.Name]
+		if !metric.Applicable {
+			continue
+		}
+		total += metric.Weight
+		sum += metric.Weight * metric.Value
+	}
+	if total > 0 {
+		report.Score = math.Round(sum/total*1000) / 10
+	}
+
+	return report, nil
+}
+
+// ratio builds a metric from the count of items (e.g. "variables") that are ok (e.g. "validated")
+func ratio(name string, ok, all int, items, state string) *Metric {
+	if all == 0 {
+		return &Metric{Name: name, Detail: "no " + items}
+	}
+	return &Metric{
+		Name:       name,
+		Value:      float64(ok) / float64(all),
+		Applicable: true,
+		Detail:     fmt.Sprintf("%d of %d %s %s", ok, all, items, state),
+	}
+}
+
+func descriptionsMetric(tfconfig *parser.TerraformConfig) *Metric {
+	described, all := 0, 0
+	for _, variable := range tfconfig.Variables {
+		all++
+		if strings.TrimSpace(variable.Description) != "" {
+			described++
+		}
+	}
+	for _, output := range tfconfig.Outputs {
+		all++
+		if strings.TrimSpace(output.Description) != "" {
+			described++
+		}
+	}
+	return ratio(MetricDescriptions, described, all, "variables and outputs", "described")
+}
+
+// pinnedMetric counts remote module calls and required providers that pass the policy lint rules
+func pinnedMetric(tfconfig *parser.TerraformConfig) *Metric {
+	all := 0
+	for _, module := range tfconfig.Modules {
+		if source.ParseModuleSource(module.Source).Kind != source.ModuleSourceLocal {
+			all++
+		}
+	}
+	for _, terraform := range tfconfig.Terraform {
+		all += len(terraform.RequiredProviders)
+	}
+
+	unpinned := map[string]bool{}
+	ws := &lint.Workspace{Config: tfconfig}
+	for _, rule := range lint.Rules() {
+		if rule.Category != lint.CategoryPolicy {
+			continue
+		}
+		for _, finding := range rule.Check(ws, &config.Config{}) {
+			unpinned[finding.Subject] = true
+		}
+	}
+
+	return ratio(MetricPinned, all-len(unpinned), all, "remote modules and providers", "pinned")
+}
+
+func validationsMetric(tfconfig *parser.TerraformConfig) *Metric {
+	validated := 0
+	for _, variable := range tfconfig.Variables {
+		if len(variable.Validation) > 0 {
+			validated++
+		}
+	}
+	return ratio(MetricValidations, validated, len(tfconfig.Variables), "variables", "validated")
+}
+
+func backendMetric(tfconfig *parser.TerraformConfig) *Metric {
+	for _, terraform := range tfconfig.Terraform {
+		if terraform.Backend != nil {
+			return &Metric{Name: MetricBackend, Value: 1, Applicable: true, Detail: terraform.Backend.Type + " backend configured"}
+		}
+		if terraform.Cloud != nil {
+			return &Metric{Name: MetricBackend, Value: 1, Applicable: true, Detail: "cloud block configured"}
+		}
+	}
+	return &Metric{Name: MetricBackend, Applicable: true, Detail: "no backend configured"}
+}
+
+// formattedMetric compares every .tf file with its canonical formatting
+func formattedMetric(fs filesystem.FileReader, dir string) (*Metric, error) {
+	entries, err := fs.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory %s: %w", dir, err)
+	}
+
+	formatted, all := 0, 0
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".tf" {
+			continue
+		}
+		content, err := fs.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", entry.Name(), err)
+		}
+
+		all++
+		if bytes.Equal(hclwrite.Format(content), content) {
+			formatted++
+		}
+	}
+
+	return ratio(MetricFormatted, formatted, all, ".tf files", "formatted"), nil
+}
+
+// testsMetric checks for terraform test files in dir or its tests directory
+func testsMetric(fs filesystem.FileReader, dir string) (*Metric, error) {
+	for _, testDir := range []string{dir, filepath.Join(dir, "tests")} {
+		exist, err := fs.DirExists(testDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check directory %s: %w", testDir, err)
+		}
+		if !exist {
+			continue
+		}
+
+		entries, err := fs.ReadDir(testDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read directory %s: %w", testDir, err)
+		}
+		names := []string{}
+		for _, entry := range entries {
+			if strings.HasSuffix(entry.Name(), ".tftest.hcl") || strings.HasSuffix(entry.Name(), ".tftest.json") {
+				names = append(names, entry.Name())
+			}
+		}
+		if len(names) > 0 {
+			slices.Sort(names)
+			return &Metric{Name: MetricTests, Value: 1, Applicable: true, Detail: strings.Join(names, ", ")}, nil
+		}
+	}
+
+	return &Metric{Name: MetricTests, Applicable: true, Detail: "no terraform test files"}, nil
+}
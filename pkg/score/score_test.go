@@ -0,0 +1,94 @@
+package score
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/config"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/filesystem"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+	"github.com/spf13/afero"
+)
+
+func TestCompute(t *testing.T) {
+	memFs := afero.NewMemMapFs()
+	files := map[string]string{
+		"main.tf": `terraform {
+  backend "s3" {}
+
+  required_providers {
+    aws = {
+      source  = "hashicorp/aws"
+      version = "~> 5.0"
+    }
+  }
+}
+
+variable "name" {
+  description = "Name of the bucket"
+
+  validation {
+    condition     = length(var.name) > 3
+    error_message = "Name is too short."
+  }
+}
+
+module "vpc" {
+  source = "terraform-aws-modules/vpc/aws"
+}
+`,
+		"outputs.tf":            "output \"id\" {\n  value =   var.name\n}\n",
+		"tests/main.tftest.hcl": "run \"plan\" {}\n",
+	}
+	for name, content := range files {
+		if err := afero.WriteFile(memFs, name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fs := filesystem.NewAferoAdapter(memFs)
+
+	tfconfig, err := parser.NewParser(fs, parser.Detail).ParseTerraformWorkspace(".")
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	report, err := Compute(fs, ".", tfconfig, &config.Config{})
+	if err != nil {
+		t.Fatalf("Compute failed: %v", err)
+	}
+
+	values := map[string]float64{}
+	for _, metric := range report.Metrics {
+		values[metric.Name] = metric.Value
+	}
+	expected := map[string]float64{
+		MetricDescriptions: 0.5,
+		MetricPinned:       0.5,
+		MetricValidations:  1,
+		MetricBackend:      1,
+		MetricFormatted:    0.5,
+		MetricTests:        1,
+	}
+	for name, value := range expected {
+		if values[name] != value {
+			t.Errorf("Expected %s = %v, got %v", name, value, values[name])
+		}
+	}
+
+	// (25*0.5 + 25*0.5 + 10 + 10 + 15*0.5 + 15) / 100
+	if report.Score != 67.5 {
+		t.Errorf("Expected score 67.5, got %v", report.Score)
+	}
+
+	weighted, err := Compute(fs, ".", tfconfig, &config.Config{Score: &config.ScorePolicy{Weights: map[string]float64{MetricDescriptions: 0, MetricPinned: 0, MetricFormatted: 0}}})
+	if err != nil {
+		t.Fatalf("Compute failed: %v", err)
+	}
+	if weighted.Score != 100 {
+		t.Errorf("Expected score 100 with only fully satisfied metrics weighted, got %v", weighted.Score)
+	}
+
+	if badge := report.Badge("health"); !strings.Contains(badge, "68%") || !strings.Contains(badge, "#dfb317") {
+		t.Errorf("Unexpected badge: %s", badge)
+	}
+}
@@ -0,0 +1,126 @@
+package sensitive
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+)
+
+// SecretTypes are resource and data source types whose main purpose is to produce a secret value,
+// so any reference to them is treated as sensitive
+var SecretTypes = map[string]bool{
+	"random_password":                      true,
+	"tls_private_key":                      true,
+	"aws_iam_access_key":                   true,
+	"aws_secretsmanager_secret_version":    true,
+	"aws_ssm_parameter":                    true,
+	"azurerm_key_vault_secret":             true,
+	"google_secret_manager_secret_version": true,
+	"vault_generic_secret":                 true,
+	"vault_kv_secret_v2":                   true,
+}
+
+// Exposure is an output whose value depends on sensitive values
+type Exposure struct {
+	Output string `json:"output"`
+	// Sources lists the sensitive variables, resources and data sources behind the output value
+	Sources []string `json:"sources"`
+	// Marked is true when the output sets sensitive = true
+	Marked bool `json:"marked"`
+}
+
+type Report struct {
+	// Sensitive lists every sensitive source of the configuration
+	Sensitive []string    `json:"sensitive"`
+	Outputs   []*Exposure `json:"outputs"`
+}
+
+// Unmarked returns the exposures of outputs that do not set sensitive = true
+func (r *Report) Unmarked() []*Exposure {
+	unmarked := []*Exposure{}
+	for _, exposure := range r.Outputs {
+		if !exposure.Marked {
+			unmarked = append(unmarked, exposure)
+		}
+	}
+	return unmarked
+}
+
+// Analyze traces every output value through locals and reports the outputs
+// that depend on sensitive variables or secret resources
+func Analyze(tfconfig *parser.TerraformConfig) *Report {
+	sources := map[string]bool{}
+	for _, variable := range tfconfig.Variables {
+		if variable.IsSensitive() {
+			sources[variable.Address()] = true
+		}
+	}
+	for _, resource := range tfconfig.Resources {
+		if SecretTypes[resource.Type] {
+			sources[resource.Address()] = true
+		}
+	}
+	for _, data := range tfconfig.DataSources {
+		if SecretTypes[data.Type] {
+			sources[data.Address()] = true
+		}
+	}
+
+	locals := map[string][]string{}
+	for _, local := range tfconfig.Locals {
+		locals[local.Address()] = local.References
+	}
+
+	report := &Report{Sensitive: sortedKeys(sources), Outputs: []*Exposure{}}
+	for _, output := range tfconfig.Outputs {
+		found := resolveSources(output.References, locals, sources)
+		if len(found) == 0 {
+			continue
+		}
+		report.Outputs = append(report.Outputs, &Exposure{
+			Output:  output.Address(),
+			Sources: found,
+			Marked:  output.IsSensitive(),
+		})
+	}
+
+	return report
+}
+
+// resolveSources expands local values transitively and returns the sensitive sources behind refs
+func resolveSources(refs []string, locals map[string][]string, sources map[string]bool) []string {
+	found := map[string]bool{}
+	visited := map[string]bool{}
+
+	var visit func(ref string)
+	visit = func(ref string) {
+		if visited[ref] {
+			return
+		}
+		visited[ref] = true
+
+		if sources[ref] {
+			found[ref] = true
+		}
+		if strings.HasPrefix(ref, "local.") {
+			for _, next := range locals[ref] {
+				visit(next)
+			}
+		}
+	}
+
+	for _, ref := range refs {
+		visit(ref)
+	}
+	return sortedKeys(found)
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,131 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/source"
+)
+
+// ErrLocalTarget is returned for requests parsing a path on the server when local targets are not allowed
+var ErrLocalTarget = errors.New("local targets are not allowed")
+
+// ErrGitHost is returned for requests cloning from a host that is not allowed, or over plain http or git
+var ErrGitHost = errors.New("git host is not allowed")
+
+// maxRequestBody bounds the request bodies the server decodes
+const maxRequestBody = 1 << 20
+
+// WithGitHosts lets requests clone repositories from hosts, matched exactly; git targets are
+// refused on other hosts, and on every host without WithGitHosts, so that clients cannot make the
+// server reach arbitrary hosts or send them its credentials
+func (s *Server) WithGitHosts(hosts ...string) *Server {
+	s.gitHosts = nil
+	for _, host := range hosts {
+		s.gitHosts = append(s.gitHosts, strings.ToLower(host))
+	}
+	return s
+}
+
+// WithLocalTargets lets requests parse paths on the server, which are refused by default: any
+// path without roots, or only paths under one of roots. Bundles are local targets too.
+func (s *Server) WithLocalTargets(roots ...string) *Server {
+	s.localTargets = true
+	s.localRoots = roots
+	return s
+}
+
+// WithToken makes every endpoint require an "Authorization: Bearer <token>" header
+func (s *Server) WithToken(token string) *Server {
+	s.token = token
+	return s
+}
+
+// checkTarget refuses the local targets that the server does not allow
+func (s *Server) checkTarget(req *ParseRequest) error {
+	if source.IsGitURL(req.Target) {
+		if strings.HasPrefix(req.Target, "http://") || strings.HasPrefix(req.Target, "git://") {
+			return fmt.Errorf("%w: %s is not encrypted, use https or ssh", ErrGitHost, req.Target)
+		}
+		if host := source.GitHost(req.Target); host == "" || !slices.Contains(s.gitHosts, host) {
+			return fmt.Errorf("%w: %s", ErrGitHost, req.Target)
+		}
+		return nil
+	}
+	if !s.localTargets {
+		return fmt.Errorf("%w: %s", ErrLocalTarget, req.Target)
+	}
+	if len(s.localRoots) == 0 {
+		return nil
+	}
+
+	path, err := resolvePath(filepath.Join(req.Target, req.SubDir))
+	if err != nil {
+		return err
+	}
+	for _, root := range s.localRoots {
+		root, err := resolvePath(root)
+		if err != nil {
+			continue
+		}
+		if rel, err := filepath.Rel(root, path); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s is outside the allowed roots", ErrLocalTarget, req.Target)
+}
+
+// resolvePath returns the absolute path with symbolic links resolved, so that a link cannot point
+// out of an allowed root. Paths that do not exist are only cleaned.
+func resolvePath(path string) (string, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved, nil
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+	return path, nil
+}
+
+// decodeRequest decodes the JSON body of r into v, reading at most maxRequestBody bytes. It answers
+// the client itself and returns false when the body is too large or invalid.
+func (s *Server) decodeRequest(w http.ResponseWriter, r *http.Request, v any) bool {
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBody)).Decode(v)
+	if err == nil {
+		return true
+	}
+
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		s.writeError(w, r, http.StatusRequestEntityTooLarge, fmt.Errorf("request body exceeds %d bytes", tooLarge.Limit))
+	} else {
+		s.writeError(w, r, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+	}
+	return false
+}
+
+// authenticate answers 401 to requests without the bearer token of the server, if it has one
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	if s.token == "" {
+		return next
+	}
+	expected := []byte("Bearer " + s.token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="terraform-config-parser"`)
+			s.writeError(w, r, http.StatusUnauthorized, errors.New("missing or invalid bearer token"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,262 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/diff"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/logger"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/notify"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/output"
+)
+
+const (
+	// streamKeepalive is the interval of the comments keeping idle event streams open through proxies
+	streamKeepalive = 15 * time.Second
+	// webhookAttempts is the number of deliveries of an event before a webhook skips it
+	webhookAttempts = 3
+)
+
+// webhookBackoff is the delay before the second delivery of an event, doubled for each further attempt
+var webhookBackoff = time.Second
+
+// EventFilter selects the events of a stream or a webhook
+type EventFilter struct {
+	// Repository keeps the events of one repository ID, empty for all
+	Repository string
+	// BreakingOnly keeps the events with at least one breaking change
+	BreakingOnly bool
+}
+
+func (f *EventFilter) match(event *Event) bool {
+	if f.Repository != "" && event.Repository != f.Repository {
+		return false
+	}
+	return !f.BreakingOnly || event.Breaking > 0
+}
+
+// Webhook receives every event matching its filter as a POST request
+type Webhook struct {
+	URL string
+	// Format is "json" for the event itself, or a chat format of pkg/notify
+	Format string
+	// Secret signs the bodies with an X-Tfparser-Signature: sha256=<hex HMAC> header
+	Secret string
+	EventFilter
+}
+
+// WithWebhook delivers events to hook while RunWebhooks runs
+func (s *Server) WithWebhook(hook *Webhook) *Server {
+	s.webhooks = append(s.webhooks, hook)
+	return s
+}
+
+// RunWebhooks delivers the events published from now on to the webhooks until ctx is done.
+// Events are delivered in order; an event failing every attempt is logged and skipped.
+func (s *Server) RunWebhooks(ctx context.Context) {
+	since, published := s.repos.watch()
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-published:
+		}
+		_, published = s.repos.watch()
+
+		for _, event := range s.repos.eventsSince(since) {
+			since = event.Seq
+			for _, hook := range s.webhooks {
+				if hook.match(event) {
+					s.deliver(ctx, client, hook, event)
+				}
+			}
+		}
+	}
+}
+
+func (s *Server) deliver(ctx context.Context, client *http.Client, hook *Webhook, event *Event) {
+	body, err := s.webhookBody(hook, event)
+	if err != nil {
+		logger.ErrorKV("Failed to encode webhook payload", "url", hook.URL, "event", event.Seq, "error", err)
+		return
+	}
+
+	backoff := webhookBackoff
+	for attempt := 1; ; attempt++ {
+		err = postWebhook(ctx, client, hook, body)
+		if err == nil {
+			logger.DebugKV("Delivered event", "url", hook.URL, "event", event.Seq)
+			return
+		}
+		if attempt == webhookAttempts || ctx.Err() != nil {
+			break
+		}
+		select {
+		case <-time.After(backoff):
+			backoff *= 2
+		case <-ctx.Done():
+		}
+	}
+	logger.ErrorKV("Failed to deliver event", "url", hook.URL, "event", event.Seq, "error", err)
+}
+
+func (s *Server) webhookBody(hook *Webhook, event *Event) ([]byte, error) {
+	if hook.Format == "" || hook.Format == "json" {
+		return output.Marshal(event, s.output, false)
+	}
+	format, err := notify.ParseFormat(hook.Format)
+	if err != nil {
+		return nil, err
+	}
+	return output.Marshal(eventMessage(event).Payload(format), output.Options{}, false)
+}
+
+func postWebhook(ctx context.Context, client *http.Client, hook *Webhook, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if hook.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(hook.Secret))
+		mac.Write(body)
+		req.Header.Set("X-Tfparser-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook answered %s", resp.Status)
+	}
+	return nil
+}
+
+// eventMessage summarizes an event for chat systems
+func eventMessage(event *Event) *notify.Message {
+	title := event.Target
+	if event.Module != "." {
+		title += "//" + event.Module
+	}
+	if event.Commit != "" {
+		title += " @ " + event.Commit[:min(len(event.Commit), 7)]
+	}
+
+	switch event.Kind {
+	case EventModuleAdded:
+		return &notify.Message{Title: title, Summary: "module added"}
+	case EventModuleRemoved:
+		return &notify.Message{Title: title, Summary: "module removed; callers will fail"}
+	default:
+		return notify.DiffMessage(title, &diff.Report{Changes: event.Changes, Breaking: event.Breaking})
+	}
+}
+
+func (s *Server) listEvents(w http.ResponseWriter, r *http.Request) {
+	filter, err := eventFilter(r.URL.Query())
+	if err != nil {
+		s.writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	since := int64(0)
+	if value := r.URL.Query().Get("since"); value != "" {
+		if since, err = strconv.ParseInt(value, 10, 64); err != nil {
+			s.writeError(w, r, http.StatusBadRequest, fmt.Errorf("invalid since %q", value))
+			return
+		}
+	}
+
+	events := []*Event{}
+	for _, event := range s.repos.eventsSince(since) {
+		if filter.match(event) {
+			events = append(events, event)
+		}
+	}
+	s.writeJSON(w, r, http.StatusOK, events)
+}
+
+// streamEvents sends events as server-sent events, from the Last-Event-ID header or the
+// since parameter when given, otherwise from now on
+func (s *Server) streamEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		s.writeError(w, r, http.StatusInternalServerError, fmt.Errorf("streaming is not supported"))
+		return
+	}
+
+	filter, err := eventFilter(r.URL.Query())
+	if err != nil {
+		s.writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	since, published := s.repos.watch()
+	from := r.Header.Get("Last-Event-ID")
+	if from == "" {
+		from = r.URL.Query().Get("since")
+	}
+	if from != "" {
+		if since, err = strconv.ParseInt(from, 10, 64); err != nil {
+			s.writeError(w, r, http.StatusBadRequest, fmt.Errorf("invalid event ID %q", from))
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepalive := time.NewTicker(streamKeepalive)
+	defer keepalive.Stop()
+
+	for {
+		for _, event := range s.repos.eventsSince(since) {
+			since = event.Seq
+			if !filter.match(event) {
+				continue
+			}
+			data, err := output.Marshal(event, s.output, false)
+			if err != nil {
+				logger.ErrorKV("Failed to encode event", "event", event.Seq, "error", err)
+				continue
+			}
+			fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.Seq, event.Kind, data)
+		}
+		flusher.Flush()
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-published:
+			_, published = s.repos.watch()
+		case <-keepalive.C:
+			fmt.Fprint(w, ": keepalive\n\n")
+		}
+	}
+}
+
+func eventFilter(query url.Values) (*EventFilter, error) {
+	filter := &EventFilter{Repository: query.Get("repository")}
+	if value := query.Get("breaking"); value != "" {
+		breaking, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid breaking %q", value)
+		}
+		filter.BreakingOnly = breaking
+	}
+	return filter, nil
+}
@@ -0,0 +1,120 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/logger"
+)
+
+// RunWorkers processes queued jobs with a pool of workers until ctx is done
+func (s *Server) RunWorkers(ctx context.Context, workers int) {
+	var wg sync.WaitGroup
+	for i := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.work(ctx, i)
+		}()
+	}
+	wg.Wait()
+}
+
+func (s *Server) work(ctx context.Context, worker int) {
+	for {
+		job, err := s.queue.Dequeue(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			logger.ErrorKV("Failed to dequeue job", "worker", worker, "error", err)
+			// Back off on queue errors, e.g. while redis is unreachable
+			select {
+			case <-time.After(time.Second):
+			case <-ctx.Done():
+				return
+			}
+			continue
+		}
+
+		s.runJob(ctx, worker, job)
+	}
+}
+
+func (s *Server) runJob(ctx context.Context, worker int, job *Job) {
+	started := time.Now().UTC()
+	job.Status = JobRunning
+	job.StartedAt = &started
+	if err := s.queue.Update(ctx, job); err != nil {
+		logger.ErrorKV("Failed to update job", "job", job.ID, "error", err)
+	}
+
+	logger.InfoKV("Running job", "worker", worker, "job", job.ID, "target", job.Request.Target)
+	ws, err := Parse(ctx, job.Request)
+
+	finished := time.Now().UTC()
+	job.FinishedAt = &finished
+	if err == nil {
+		err = s.store.Put(ctx, ws)
+	}
+	if err != nil {
+		logger.ErrorKV("Job failed", "job", job.ID, "error", err)
+		job.Status = JobFailed
+		job.Error = err.Error()
+	} else {
+		job.Status = JobSucceeded
+		job.WorkspaceID = ws.ID
+	}
+
+	if err := s.queue.Update(ctx, job); err != nil {
+		logger.ErrorKV("Failed to update job", "job", job.ID, "error", err)
+	}
+}
+
+func (s *Server) createJob(w http.ResponseWriter, r *http.Request) {
+	req := &ParseRequest{}
+	if !s.decodeRequest(w, r, req) {
+		return
+	}
+	if req.Target == "" {
+		s.writeError(w, r, http.StatusBadRequest, fmt.Errorf("target is required"))
+		return
+	}
+	if err := s.checkTarget(req); err != nil {
+		s.writeError(w, r, http.StatusForbidden, err)
+		return
+	}
+
+	job := &Job{ID: newID(), Status: JobQueued, Request: req, CreatedAt: time.Now().UTC()}
+	if err := s.queue.Enqueue(r.Context(), job); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, ErrQueueFull) {
+			status = http.StatusServiceUnavailable
+		}
+		s.writeError(w, r, status, err)
+		return
+	}
+
+	logger.InfoKV("Queued job", "job", job.ID, "target", req.Target)
+	w.Header().Set("Location", "/jobs/"+job.ID)
+	s.writeJSON(w, r, http.StatusAccepted, job)
+}
+
+func (s *Server) getJob(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	job, err := s.queue.Get(r.Context(), id)
+	if err != nil {
+		s.writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	if job == nil {
+		s.writeError(w, r, http.StatusNotFound, fmt.Errorf("job %s not found", id))
+		return
+	}
+	s.writeJSON(w, r, http.StatusOK, job)
+}
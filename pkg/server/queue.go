@@ -0,0 +1,135 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+type JobStatus string
+
+const (
+	JobQueued    JobStatus = "queued"
+	JobRunning   JobStatus = "running"
+	JobSucceeded JobStatus = "succeeded"
+	JobFailed    JobStatus = "failed"
+)
+
+// Job is an asynchronous parse of a source
+type Job struct {
+	ID      string        `json:"id"`
+	Status  JobStatus     `json:"status"`
+	Request *ParseRequest `json:"request"`
+	// WorkspaceID is set once the job succeeded
+	WorkspaceID string     `json:"workspace_id,omitempty"`
+	Error       string     `json:"error,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+}
+
+// ErrQueueFull is returned by Enqueue when the queue cannot take more jobs
+var ErrQueueFull = errors.New("job queue is full")
+
+// Queue holds pending jobs and the state of every job
+type Queue interface {
+	// Enqueue records job and makes it available to Dequeue
+	Enqueue(ctx context.Context, job *Job) error
+	// Dequeue blocks until a job is available or ctx is done
+	Dequeue(ctx context.Context) (*Job, error)
+	// Update records the new state of job
+	Update(ctx context.Context, job *Job) error
+	// Get returns the job with id, or nil
+	Get(ctx context.Context, id string) (*Job, error)
+}
+
+const (
+	// jobRetention is how long finished jobs are kept, by every queue
+	jobRetention = 7 * 24 * time.Hour
+	// maxFinishedJobs bounds the finished jobs the memory queue keeps; the oldest go first
+	maxFinishedJobs = 10000
+)
+
+// memoryQueue is a bounded in-process queue. Finished jobs are dropped after jobRetention, or
+// sooner once more than maxFinished of them are kept.
+type memoryQueue struct {
+	pending chan string
+
+	mu   sync.RWMutex
+	jobs map[string]*Job
+	// finished are the IDs of finished jobs, oldest first
+	finished    []string
+	maxFinished int
+	now         func() time.Time
+}
+
+// NewMemoryQueue creates an in-process queue holding up to size pending jobs
+func NewMemoryQueue(size int) Queue {
+	return &memoryQueue{pending: make(chan string, size), jobs: map[string]*Job{}, maxFinished: maxFinishedJobs, now: time.Now}
+}
+
+func (q *memoryQueue) Enqueue(ctx context.Context, job *Job) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.expire()
+	select {
+	case q.pending <- job.ID:
+		copied := *job
+		q.jobs[job.ID] = &copied
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+func (q *memoryQueue) Dequeue(ctx context.Context) (*Job, error) {
+	select {
+	case id := <-q.pending:
+		return q.Get(ctx, id)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (q *memoryQueue) Update(ctx context.Context, job *Job) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	copied := *job
+	previous, known := q.jobs[job.ID]
+	q.jobs[job.ID] = &copied
+	if job.FinishedAt != nil && (!known || previous.FinishedAt == nil) {
+		q.finished = append(q.finished, job.ID)
+		q.expire()
+	}
+	return nil
+}
+
+// expire drops the finished jobs past their retention, and the oldest beyond maxFinished
+func (q *memoryQueue) expire() {
+	cutoff := q.now().Add(-jobRetention)
+	dropped := 0
+	for _, id := range q.finished {
+		job, ok := q.jobs[id]
+		if ok && len(q.finished)-dropped <= q.maxFinished && job.FinishedAt.After(cutoff) {
+			break
+		}
+		delete(q.jobs, id)
+		dropped++
+	}
+	q.finished = q.finished[dropped:]
+}
+
+func (q *memoryQueue) Get(ctx context.Context, id string) (*Job, error) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	job, ok := q.jobs[id]
+	if !ok || job.FinishedAt != nil && job.FinishedAt.Before(q.now().Add(-jobRetention)) {
+		return nil, nil
+	}
+	copied := *job
+	return &copied, nil
+}
@@ -0,0 +1,389 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/logger"
+)
+
+const (
+	redisPendingKey    = "tfparser:jobs:pending"
+	redisProcessingKey = "tfparser:jobs:processing"
+	redisJobPrefix     = "tfparser:job:"
+	redisLeasePrefix   = "tfparser:lease:"
+	redisOrphanPrefix  = "tfparser:orphan:"
+	// redisPollTimeout bounds each blocking move so that Dequeue notices cancellation
+	redisPollTimeout = 2
+	redisJobTTL      = jobRetention
+	// redisLease is how long a claimed job stays with its worker without a heartbeat; the lease is
+	// renewed every third of it while the job runs
+	redisLease = 30 * time.Second
+)
+
+// redisEnqueueScript records the job and pushes its ID in one step, unless KEYS[1] already holds
+// ARGV[1] (if positive) pending jobs
+const redisEnqueueScript = `
+local max = tonumber(ARGV[1])
+if max > 0 and redis.call('LLEN', KEYS[1]) >= max then
+  return 0
+end
+redis.call('SET', KEYS[2], ARGV[2], 'PX', ARGV[3])
+redis.call('LPUSH', KEYS[1], ARGV[4])
+return 1
+`
+
+// redisFinishScript records the final state of the job and releases its claim
+const redisFinishScript = `
+redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+redis.call('LREM', KEYS[2], 0, ARGV[3])
+redis.call('DEL', KEYS[3])
+return 1
+`
+
+// redisRecoverScript moves the claimed jobs of KEYS[1] back to the pending list KEYS[2] once their
+// lease is gone for a whole lease period (ARGV[4] ms), i.e. their worker died. The grace period
+// covers the instant between the claim and its first lease, when the lease is missing too.
+const redisRecoverScript = `
+local now = tonumber(ARGV[3])
+local requeued = 0
+for _, id in ipairs(redis.call('LRANGE', KEYS[1], 0, -1)) do
+  if redis.call('EXISTS', ARGV[1] .. id) == 0 then
+    local seen = redis.call('GET', ARGV[2] .. id)
+    if not seen then
+      redis.call('SET', ARGV[2] .. id, ARGV[3], 'PX', 2 * tonumber(ARGV[4]))
+    elseif now - tonumber(seen) >= tonumber(ARGV[4]) then
+      redis.call('DEL', ARGV[2] .. id)
+      redis.call('LREM', KEYS[1], 1, id)
+      redis.call('RPUSH', KEYS[2], id)
+      requeued = requeued + 1
+    end
+  end
+end
+return requeued
+`
+
+// redisQueue keeps jobs in Redis (6.2 or later), so that several servers share one queue: pending
+// job IDs in a list and each job as JSON under its own key, expiring a week after its last update.
+// Dequeue moves IDs to a processing list and leases them to the worker; jobs whose worker died
+// without finishing them go back to the pending list once their lease expired.
+type redisQueue struct {
+	addr     string
+	password string
+	db       int
+	maxSize  int
+	conns    chan *redisConn
+
+	mu          sync.Mutex
+	leases      map[string]context.CancelFunc
+	lastRecover time.Time
+}
+
+// NewRedisQueue creates a queue in the Redis server of rawURL (redis://[:password@]host:port[/db])
+// holding up to size pending jobs
+func NewRedisQueue(rawURL string, size int) (Queue, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "redis" || u.Host == "" {
+		return nil, fmt.Errorf("invalid redis URL %q (expected redis://[:password@]host:port[/db])", rawURL)
+	}
+
+	q := &redisQueue{addr: u.Host, maxSize: size, conns: make(chan *redisConn, 16), leases: map[string]context.CancelFunc{}}
+	if password, ok := u.User.Password(); ok {
+		q.password = password
+	}
+	if db := strings.TrimPrefix(u.Path, "/"); db != "" {
+		if q.db, err = strconv.Atoi(db); err != nil {
+			return nil, fmt.Errorf("invalid redis database %q", db)
+		}
+	}
+
+	// Fail early on unreachable servers and bad credentials
+	conn, err := q.get()
+	if err != nil {
+		return nil, err
+	}
+	q.put(conn)
+	return q, nil
+}
+
+func (q *redisQueue) Enqueue(ctx context.Context, job *Job) error {
+	content, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to encode job: %w", err)
+	}
+
+	// The length check and the push are one script, so that concurrent servers cannot overfill the queue
+	pushed, err := q.eval(redisEnqueueScript, []string{redisPendingKey, redisJobPrefix + job.ID},
+		strconv.Itoa(q.maxSize), string(content), strconv.FormatInt(redisJobTTL.Milliseconds(), 10), job.ID)
+	if err != nil {
+		return err
+	}
+	if n, _ := pushed.(int64); n == 0 {
+		return ErrQueueFull
+	}
+	return nil
+}
+
+func (q *redisQueue) Dequeue(ctx context.Context) (*Job, error) {
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err := q.recover(); err != nil {
+			return nil, err
+		}
+
+		reply, err := q.do("BLMOVE", redisPendingKey, redisProcessingKey, "RIGHT", "LEFT", strconv.Itoa(redisPollTimeout))
+		if err != nil {
+			return nil, err
+		}
+		id, ok := reply.(string)
+		if !ok {
+			continue
+		}
+
+		job, err := q.Get(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if job == nil {
+			// The job expired; drop its ID
+			if _, err := q.do("LREM", redisProcessingKey, "0", id); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err := q.lease(id); err != nil {
+			return nil, err
+		}
+		return job, nil
+	}
+}
+
+func (q *redisQueue) Update(ctx context.Context, job *Job) error {
+	content, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to encode job: %w", err)
+	}
+	ttl := strconv.FormatInt(redisJobTTL.Milliseconds(), 10)
+
+	if job.Status != JobSucceeded && job.Status != JobFailed {
+		_, err = q.do("SET", redisJobPrefix+job.ID, string(content), "PX", ttl)
+		return err
+	}
+
+	q.mu.Lock()
+	if release, ok := q.leases[job.ID]; ok {
+		release()
+		delete(q.leases, job.ID)
+	}
+	q.mu.Unlock()
+	_, err = q.eval(redisFinishScript, []string{redisJobPrefix + job.ID, redisProcessingKey, redisLeasePrefix + job.ID},
+		string(content), ttl, job.ID)
+	return err
+}
+
+// lease marks job id as claimed by this server and renews the claim until the job finishes
+func (q *redisQueue) lease(id string) error {
+	ttl := strconv.FormatInt(redisLease.Milliseconds(), 10)
+	if _, err := q.do("SET", redisLeasePrefix+id, "1", "PX", ttl); err != nil {
+		return err
+	}
+
+	ctx, release := context.WithCancel(context.Background())
+	q.mu.Lock()
+	q.leases[id] = release
+	q.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(redisLease / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := q.do("SET", redisLeasePrefix+id, "1", "PX", ttl); err != nil {
+					logger.ErrorKV("Failed to renew job lease", "job", id, "error", err)
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// recover re-queues the jobs of dead workers, at most once per lease period on each server
+func (q *redisQueue) recover() error {
+	q.mu.Lock()
+	if time.Since(q.lastRecover) < redisLease {
+		q.mu.Unlock()
+		return nil
+	}
+	q.lastRecover = time.Now()
+	q.mu.Unlock()
+
+	requeued, err := q.eval(redisRecoverScript, []string{redisProcessingKey, redisPendingKey},
+		redisLeasePrefix, redisOrphanPrefix, strconv.FormatInt(time.Now().UnixMilli(), 10), strconv.FormatInt(redisLease.Milliseconds(), 10))
+	if err != nil {
+		return err
+	}
+	if n, _ := requeued.(int64); n > 0 {
+		logger.InfoKV("Re-queued jobs of stopped workers", "jobs", n)
+	}
+	return nil
+}
+
+func (q *redisQueue) Get(ctx context.Context, id string) (*Job, error) {
+	reply, err := q.do("GET", redisJobPrefix+id)
+	if err != nil || reply == nil {
+		return nil, err
+	}
+
+	job := &Job{}
+	if err := json.Unmarshal([]byte(reply.(string)), job); err != nil {
+		return nil, fmt.Errorf("failed to decode job %s: %w", id, err)
+	}
+	return job, nil
+}
+
+// eval runs a Lua script, which Redis executes atomically
+func (q *redisQueue) eval(script string, keys []string, args ...string) (any, error) {
+	command := append([]string{"EVAL", script, strconv.Itoa(len(keys))}, keys...)
+	return q.do(append(command, args...)...)
+}
+
+// do runs a command on a pooled connection
+func (q *redisQueue) do(args ...string) (any, error) {
+	conn, err := q.get()
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := conn.do(args...)
+	var redisErr redisError
+	if err != nil && !errors.As(err, &redisErr) {
+		// The connection state is unknown after I/O errors
+		conn.Close()
+		return nil, err
+	}
+	q.put(conn)
+	return reply, err
+}
+
+func (q *redisQueue) get() (*redisConn, error) {
+	select {
+	case conn := <-q.conns:
+		return conn, nil
+	default:
+	}
+
+	c, err := net.DialTimeout("tcp", q.addr, 5*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to redis at %s: %w", q.addr, err)
+	}
+	conn := &redisConn{Conn: c, r: bufio.NewReader(c)}
+
+	if q.password != "" {
+		if _, err := conn.do("AUTH", q.password); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to authenticate to redis: %w", err)
+		}
+	}
+	if q.db != 0 {
+		if _, err := conn.do("SELECT", strconv.Itoa(q.db)); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to select redis database %d: %w", q.db, err)
+		}
+	}
+	return conn, nil
+}
+
+func (q *redisQueue) put(conn *redisConn) {
+	select {
+	case q.conns <- conn:
+	default:
+		conn.Close()
+	}
+}
+
+// redisError is an error reply of the server
+type redisError string
+
+func (e redisError) Error() string {
+	return "redis: " + string(e)
+}
+
+// redisConn speaks the RESP protocol on a single connection. The queue needs a handful of commands
+// on a single server, which this minimal client covers without adding a Redis library to the binary.
+type redisConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *redisConn) do(args ...string) (any, error) {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&sb, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := io.WriteString(c.Conn, sb.String()); err != nil {
+		return nil, err
+	}
+	return c.read()
+}
+
+// read decodes a reply: simple strings and bulk strings as string, integers as int64,
+// arrays as []any and null replies as nil
+func (c *redisConn) read() (any, error) {
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, fmt.Errorf("empty redis reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, redisError(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil || size < 0 {
+			return nil, err
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(c.r, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:size]), nil
+	case '*':
+		count, err := strconv.Atoi(line[1:])
+		if err != nil || count < 0 {
+			return nil, err
+		}
+		items := make([]any, count)
+		for i := range items {
+			if items[i], err = c.read(); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("unexpected redis reply %q", line)
+	}
+}
@@ -0,0 +1,99 @@
+package server
+
+import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/logger"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/output"
+)
+
+const (
+	defaultPageSize = 100
+	maxPageSize     = 1000
+	// gzipThreshold is the body size from which responses are compressed
+	gzipThreshold = 1024
+)
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// writeJSON encodes v, answers conditional requests through an ETag of the body and
+// compresses large bodies for clients accepting gzip
+func (s *Server) writeJSON(w http.ResponseWriter, r *http.Request, status int, v any) {
+	body, err := output.Marshal(v, s.output, false)
+	if err != nil {
+		logger.ErrorKV("Failed to encode response", "path", r.URL.Path, "error", err)
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:8]) + `"`
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Vary", "Accept-Encoding")
+	if status == http.StatusOK && r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if len(body) < gzipThreshold || !acceptsGzip(r) {
+		w.WriteHeader(status)
+		w.Write(body)
+		return
+	}
+
+	w.Header().Set("Content-Encoding", "gzip")
+	w.WriteHeader(status)
+	gz := gzip.NewWriter(w)
+	gz.Write(body)
+	gz.Close()
+}
+
+func (s *Server) writeError(w http.ResponseWriter, r *http.Request, status int, err error) {
+	s.writeJSON(w, r, status, &errorResponse{Error: err.Error()})
+}
+
+func acceptsGzip(r *http.Request) bool {
+	for _, encoding := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		if name, _, _ := strings.Cut(strings.TrimSpace(encoding), ";"); name == "gzip" {
+			return true
+		}
+	}
+	return false
+}
+
+// page returns the items of a sorted key list after the opaque cursor, and the cursor of the next page
+func page(keys []string, cursor string, limit int) ([]string, string) {
+	start := 0
+	if after, err := base64.RawURLEncoding.DecodeString(cursor); err == nil && cursor != "" {
+		start, _ = slices.BinarySearch(keys, string(after))
+		if start < len(keys) && keys[start] == string(after) {
+			start++
+		}
+	}
+
+	end := min(start+limit, len(keys))
+	next := ""
+	if end < len(keys) {
+		next = base64.RawURLEncoding.EncodeToString([]byte(keys[end-1]))
+	}
+	return keys[start:end], next
+}
+
+// pageSize reads the limit query parameter
+func pageSize(r *http.Request) int {
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 {
+		return defaultPageSize
+	}
+	return min(limit, maxPageSize)
+}
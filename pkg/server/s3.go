@@ -0,0 +1,124 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/awsv4"
+)
+
+type s3Store struct {
+	bucket string
+	prefix string
+	region string
+	// endpoint is set for S3-compatible services such as MinIO, addressed in path style
+	endpoint string
+	creds    awsv4.Provider
+	client   *http.Client
+}
+
+// NewS3Store creates a store writing each workspace to s3://bucket/prefix/<id>.json. The region
+// and endpoint query parameters override AWS_REGION and the AWS endpoint; credentials are read
+// from the environment, as static keys or a web identity role.
+func NewS3Store(rawURL string) (Store, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid S3 store URL %q (expected s3://bucket/prefix)", rawURL)
+	}
+
+	creds, err := awsv4.ProviderFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	store := &s3Store{
+		bucket:   u.Host,
+		prefix:   strings.Trim(u.Path, "/"),
+		region:   u.Query().Get("region"),
+		endpoint: strings.TrimSuffix(u.Query().Get("endpoint"), "/"),
+		creds:    creds,
+		client:   &http.Client{Timeout: 30 * time.Second},
+	}
+	if store.region == "" {
+		store.region = awsv4.RegionFromEnv()
+	}
+	return store, nil
+}
+
+func (s *s3Store) objectURL(id string) string {
+	key := id + ".json"
+	if s.prefix != "" {
+		key = s.prefix + "/" + key
+	}
+	if s.endpoint != "" {
+		return fmt.Sprintf("%s/%s/%s", s.endpoint, s.bucket, key)
+	}
+	return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", s.bucket, s.region, key)
+}
+
+func (s *s3Store) do(ctx context.Context, method, id string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, s.objectURL(id), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	creds, err := s.creds.Retrieve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	awsv4.Sign(req, body, "s3", s.region, creds, time.Now())
+
+	return s.client.Do(req)
+}
+
+func (s *s3Store) Put(ctx context.Context, ws *Workspace) error {
+	content, err := json.Marshal(ws)
+	if err != nil {
+		return fmt.Errorf("failed to encode workspace %s: %w", ws.ID, err)
+	}
+
+	resp, err := s.do(ctx, http.MethodPut, ws.ID, content)
+	if err != nil {
+		return fmt.Errorf("failed to store workspace %s: %w", ws.ID, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("failed to store workspace %s: S3 returned %s: %s", ws.ID, resp.Status, message)
+	}
+	return nil
+}
+
+func (s *s3Store) Get(ctx context.Context, id string) (*Workspace, error) {
+	if !validID.MatchString(id) {
+		return nil, nil
+	}
+
+	resp, err := s.do(ctx, http.MethodGet, id, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workspace %s: %w", id, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workspace %s: %w", id, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to read workspace %s: S3 returned %s: %s", id, resp.Status, content)
+	}
+	return decodeWorkspace(id, content)
+}
@@ -0,0 +1,369 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/cron"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/diff"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/logger"
+)
+
+// maxEvents is the number of change events the server keeps
+const maxEvents = 1000
+
+// RepositoryRequest is the body of POST /repositories
+type RepositoryRequest struct {
+	ParseRequest
+	// Schedule is a cron expression such as "0 * * * *", or "@every 30m"
+	Schedule string `json:"schedule"`
+}
+
+// Repository is a source the server re-parses on a schedule
+type Repository struct {
+	ID       string        `json:"id"`
+	Request  *ParseRequest `json:"request"`
+	Schedule string        `json:"schedule"`
+	NextRun  time.Time     `json:"next_run"`
+	LastRun  *time.Time    `json:"last_run,omitempty"`
+	// WorkspaceID is the workspace of the last successful parse
+	WorkspaceID string `json:"workspace_id,omitempty"`
+	Commit      string `json:"commit,omitempty"`
+	// Error is the error of the last parse, empty when it succeeded
+	Error string `json:"error,omitempty"`
+
+	schedule cron.Schedule
+	running  bool
+}
+
+type EventKind string
+
+const (
+	EventModuleAdded      EventKind = "module_added"
+	EventModuleRemoved    EventKind = "module_removed"
+	EventInterfaceChanged EventKind = "interface_changed"
+)
+
+// Event is a module interface drift found by re-parsing a repository
+type Event struct {
+	// Seq increases with every event, for GET /events?since=
+	Seq         int64     `json:"seq"`
+	Time        time.Time `json:"time"`
+	Repository  string    `json:"repository"`
+	Target      string    `json:"target"`
+	Commit      string    `json:"commit,omitempty"`
+	WorkspaceID string    `json:"workspace_id"`
+	// Module is the module path relative to the target, "." for the target itself
+	Module string    `json:"module"`
+	Kind   EventKind `json:"kind"`
+	// Changes are the variable and output changes of interface_changed events
+	Changes  []*diff.Change `json:"changes,omitempty"`
+	Breaking int            `json:"breaking"`
+}
+
+// registry holds the scheduled repositories and the events they produced
+type registry struct {
+	mu           sync.Mutex
+	repositories map[string]*Repository
+	events       []*Event
+	seq          int64
+	// published is closed and replaced when events are published
+	published chan struct{}
+}
+
+func newRegistry() *registry {
+	return &registry{repositories: map[string]*Repository{}, published: make(chan struct{})}
+}
+
+// snapshot copies a repository so that it can be encoded outside of the lock
+func (r *registry) snapshot(repo *Repository) *Repository {
+	copied := *repo
+	return &copied
+}
+
+func (r *registry) list() []*Repository {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	repos := make([]*Repository, 0, len(r.repositories))
+	for _, repo := range r.repositories {
+		repos = append(repos, r.snapshot(repo))
+	}
+	slices.SortFunc(repos, func(a, b *Repository) int { return strings.Compare(a.ID, b.ID) })
+	return repos
+}
+
+func (r *registry) get(id string) *Repository {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	repo, ok := r.repositories[id]
+	if !ok {
+		return nil
+	}
+	return r.snapshot(repo)
+}
+
+func (r *registry) publish(events []*Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, event := range events {
+		r.seq++
+		event.Seq = r.seq
+	}
+	r.events = append(r.events, events...)
+	if len(r.events) > maxEvents {
+		r.events = slices.Clone(r.events[len(r.events)-maxEvents:])
+	}
+
+	close(r.published)
+	r.published = make(chan struct{})
+}
+
+// watch returns the latest sequence number and a channel closed when later events are published
+func (r *registry) watch() (int64, <-chan struct{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.seq, r.published
+}
+
+func (r *registry) eventsSince(seq int64) []*Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i, _ := slices.BinarySearchFunc(r.events, seq+1, func(event *Event, seq int64) int { return int(event.Seq - seq) })
+	return slices.Clone(r.events[i:])
+}
+
+// RunScheduler re-parses the registered repositories when they are due, checking every interval until ctx is done
+func (s *Server) RunScheduler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			for _, id := range s.dueRepositories(now) {
+				go s.Refresh(ctx, id)
+			}
+		}
+	}
+}
+
+func (s *Server) dueRepositories(now time.Time) []string {
+	s.repos.mu.Lock()
+	defer s.repos.mu.Unlock()
+
+	due := []string{}
+	for id, repo := range s.repos.repositories {
+		if !repo.running && !repo.NextRun.After(now) {
+			due = append(due, id)
+		}
+	}
+	return due
+}
+
+// Refresh re-parses a repository, stores the new workspace and publishes an event for every
+// module whose interface differs from the previous parse. The first parse only sets the baseline.
+func (s *Server) Refresh(ctx context.Context, id string) (*Repository, error) {
+	s.repos.mu.Lock()
+	repo, ok := s.repos.repositories[id]
+	if !ok {
+		s.repos.mu.Unlock()
+		return nil, fmt.Errorf("repository %s not found", id)
+	}
+	if repo.running {
+		s.repos.mu.Unlock()
+		return nil, fmt.Errorf("repository %s is already being parsed", id)
+	}
+	repo.running = true
+	req, previousID := repo.Request, repo.WorkspaceID
+	s.repos.mu.Unlock()
+
+	logger.InfoKV("Refreshing repository", "repository", id, "target", req.Target, "ref", req.Ref)
+	ws, err := Parse(ctx, req)
+	if err == nil {
+		err = s.store.Put(ctx, ws)
+	}
+
+	var events []*Event
+	if err == nil && previousID != "" {
+		previous, getErr := s.store.Get(ctx, previousID)
+		if getErr != nil {
+			logger.ErrorKV("Failed to read previous workspace", "repository", id, "workspace", previousID, "error", getErr)
+		} else if previous != nil {
+			events = interfaceEvents(id, previous, ws)
+		}
+	}
+
+	s.repos.mu.Lock()
+	now := time.Now().UTC()
+	repo.running = false
+	repo.LastRun = &now
+	repo.NextRun = repo.schedule.Next(now)
+	if err != nil {
+		repo.Error = err.Error()
+	} else {
+		repo.Error = ""
+		repo.WorkspaceID = ws.ID
+		repo.Commit = ws.Commit
+	}
+	refreshed := s.repos.snapshot(repo)
+	s.repos.mu.Unlock()
+
+	if err != nil {
+		logger.ErrorKV("Failed to refresh repository", "repository", id, "error", err)
+		return refreshed, err
+	}
+	if len(events) > 0 {
+		logger.InfoKV("Module interfaces changed", "repository", id, "events", len(events))
+		s.repos.publish(events)
+	}
+	return refreshed, nil
+}
+
+// interfaceEvents compares the modules of two parses of a repository
+func interfaceEvents(repository string, before, after *Workspace) []*Event {
+	events := []*Event{}
+	newEvent := func(module string, kind EventKind) *Event {
+		return &Event{
+			Time:        after.ParsedAt,
+			Repository:  repository,
+			Target:      after.Request.Target,
+			Commit:      after.Commit,
+			WorkspaceID: after.ID,
+			Module:      module,
+			Kind:        kind,
+		}
+	}
+
+	for _, path := range after.Paths() {
+		prev, existed := before.Modules[path]
+		if !existed {
+			// A module that failed to parse before is not new
+			if _, failed := before.Errors[path]; !failed {
+				events = append(events, newEvent(path, EventModuleAdded))
+			}
+			continue
+		}
+
+		event := newEvent(path, EventInterfaceChanged)
+		for _, change := range diff.Compare(prev, after.Modules[path]).Changes {
+			if !strings.HasPrefix(change.Address, "var.") && !strings.HasPrefix(change.Address, "output.") {
+				continue
+			}
+			event.Changes = append(event.Changes, change)
+			if change.Breaking {
+				event.Breaking++
+			}
+		}
+		if len(event.Changes) > 0 {
+			events = append(events, event)
+		}
+	}
+
+	for _, path := range before.Paths() {
+		_, exists := after.Modules[path]
+		_, failed := after.Errors[path]
+		if !exists && !failed {
+			event := newEvent(path, EventModuleRemoved)
+			event.Breaking = 1
+			events = append(events, event)
+		}
+	}
+
+	return events
+}
+
+func (s *Server) createRepository(w http.ResponseWriter, r *http.Request) {
+	req := &RepositoryRequest{}
+	if !s.decodeRequest(w, r, req) {
+		return
+	}
+	if req.Target == "" {
+		s.writeError(w, r, http.StatusBadRequest, fmt.Errorf("target is required"))
+		return
+	}
+	if err := s.checkTarget(&req.ParseRequest); err != nil {
+		s.writeError(w, r, http.StatusForbidden, err)
+		return
+	}
+	schedule, err := cron.Parse(req.Schedule)
+	if err != nil {
+		s.writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	parseReq := req.ParseRequest
+	repo := &Repository{
+		ID:       newID(),
+		Request:  &parseReq,
+		Schedule: req.Schedule,
+		// Parse right away to set the baseline
+		NextRun:  time.Now().UTC().Truncate(time.Second),
+		schedule: schedule,
+	}
+
+	s.repos.mu.Lock()
+	s.repos.repositories[repo.ID] = repo
+	created := s.repos.snapshot(repo)
+	s.repos.mu.Unlock()
+
+	logger.InfoKV("Registered repository", "repository", repo.ID, "target", req.Target, "schedule", req.Schedule)
+	w.Header().Set("Location", "/repositories/"+repo.ID)
+	s.writeJSON(w, r, http.StatusCreated, created)
+}
+
+func (s *Server) listRepositories(w http.ResponseWriter, r *http.Request) {
+	s.writeJSON(w, r, http.StatusOK, s.repos.list())
+}
+
+func (s *Server) getRepository(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	repo := s.repos.get(id)
+	if repo == nil {
+		s.writeError(w, r, http.StatusNotFound, fmt.Errorf("repository %s not found", id))
+		return
+	}
+	s.writeJSON(w, r, http.StatusOK, repo)
+}
+
+func (s *Server) deleteRepository(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	s.repos.mu.Lock()
+	_, ok := s.repos.repositories[id]
+	delete(s.repos.repositories, id)
+	s.repos.mu.Unlock()
+
+	if !ok {
+		s.writeError(w, r, http.StatusNotFound, fmt.Errorf("repository %s not found", id))
+		return
+	}
+	logger.InfoKV("Unregistered repository", "repository", id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) refreshRepository(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if s.repos.get(id) == nil {
+		s.writeError(w, r, http.StatusNotFound, fmt.Errorf("repository %s not found", id))
+		return
+	}
+
+	repo, err := s.Refresh(r.Context(), id)
+	if err != nil && repo == nil {
+		s.writeError(w, r, http.StatusConflict, err)
+		return
+	}
+	// A failed parse is reported in the error field of the repository
+	s.writeJSON(w, r, http.StatusOK, repo)
+}
@@ -0,0 +1,187 @@
+// Package server exposes the parser over HTTP.
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/catalog"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/logger"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/output"
+)
+
+// DefaultQueueSize is the number of pending jobs the default in-process queue holds
+const DefaultQueueSize = 100
+
+// Server parses sources on request and serves the workspaces kept in its store
+type Server struct {
+	output output.Options
+	queue  Queue
+	store  Store
+	repos  *registry
+	// webhooks receive the events published by scheduled re-parses
+	webhooks []*Webhook
+	// localTargets allows parsing paths on the server, under localRoots when there are any
+	localTargets bool
+	localRoots   []string
+	// gitHosts are the hosts git targets may clone from
+	gitHosts []string
+	token    string
+}
+
+// New creates a server encoding its responses with opts. It keeps jobs and workspaces in memory
+// until WithQueue and WithStore replace them, only parses git URLs of the hosts of WithGitHosts
+// until WithLocalTargets, and serves every client until WithToken.
+func New(opts output.Options) *Server {
+	return &Server{output: opts, queue: NewMemoryQueue(DefaultQueueSize), store: NewMemoryStore(), repos: newRegistry()}
+}
+
+// WithQueue replaces the in-process job queue, e.g. with a queue shared by several servers
+func (s *Server) WithQueue(queue Queue) *Server {
+	s.queue = queue
+	return s
+}
+
+// WithStore replaces the in-memory workspace store, e.g. with a store that survives restarts
+func (s *Server) WithStore(store Store) *Server {
+	s.store = store
+	return s
+}
+
+// Handler routes the server endpoints:
+//
+//	POST /workspaces                           parse a source, answering with its summary
+//	GET  /workspaces/{id}?cursor=&limit=       summary with a page of module entries
+//	GET  /workspaces/{id}/modules/{path...}    full configuration of a module, empty or "." for the root
+//	POST /jobs                                 queue a parse, answering with the job
+//	GET  /jobs/{id}                            state of a job and, once succeeded, its workspace ID
+//	POST /repositories                         register a source to re-parse on a schedule
+//	GET  /repositories                         registered repositories
+//	GET  /repositories/{id}                    state of a repository and its latest workspace ID
+//	DEL  /repositories/{id}                    stop re-parsing a repository
+//	POST /repositories/{id}/refresh            re-parse a repository now
+//	GET  /events?since=&repository=&breaking=  interface changes found by re-parsing, after a sequence number
+//	GET  /events/stream                        the same events as server-sent events
+//
+// With WithToken, every endpoint requires the bearer token.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /jobs", s.createJob)
+	mux.HandleFunc("GET /jobs/{id}", s.getJob)
+	mux.HandleFunc("POST /workspaces", s.createWorkspace)
+	mux.HandleFunc("GET /workspaces/{id}", s.getWorkspace)
+	mux.HandleFunc("GET /workspaces/{id}/modules/{path...}", s.getModule)
+	mux.HandleFunc("POST /repositories", s.createRepository)
+	mux.HandleFunc("GET /repositories", s.listRepositories)
+	mux.HandleFunc("GET /repositories/{id}", s.getRepository)
+	mux.HandleFunc("DELETE /repositories/{id}", s.deleteRepository)
+	mux.HandleFunc("POST /repositories/{id}/refresh", s.refreshRepository)
+	mux.HandleFunc("GET /events", s.listEvents)
+	mux.HandleFunc("GET /events/stream", s.streamEvents)
+	return s.authenticate(mux)
+}
+
+// WorkspacePage is a workspace summary with a page of its modules
+type WorkspacePage struct {
+	ID       string            `json:"id"`
+	Request  *ParseRequest     `json:"request"`
+	ParsedAt time.Time         `json:"parsed_at"`
+	Commit   string            `json:"commit,omitempty"`
+	Total    int               `json:"total"`
+	Modules  []*catalog.Module `json:"modules"`
+	Errors   map[string]string `json:"errors,omitempty"`
+	// NextCursor is the cursor of the next page, empty on the last page
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+func (s *Server) createWorkspace(w http.ResponseWriter, r *http.Request) {
+	req := &ParseRequest{}
+	if !s.decodeRequest(w, r, req) {
+		return
+	}
+	if err := s.checkTarget(req); err != nil {
+		s.writeError(w, r, http.StatusForbidden, err)
+		return
+	}
+
+	logger.InfoKV("Parsing workspace", "target", req.Target, "ref", req.Ref, "subdir", req.SubDir, "recursive", req.Recursive)
+	ws, err := Parse(r.Context(), req)
+	if err != nil {
+		logger.ErrorKV("Failed to parse workspace", "target", req.Target, "error", err)
+		s.writeError(w, r, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	if err := s.store.Put(r.Context(), ws); err != nil {
+		logger.ErrorKV("Failed to store workspace", "workspace", ws.ID, "error", err)
+		s.writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Set("Location", "/workspaces/"+ws.ID)
+	s.writeJSON(w, r, http.StatusCreated, s.workspacePage(ws, "", defaultPageSize))
+}
+
+func (s *Server) getWorkspace(w http.ResponseWriter, r *http.Request) {
+	ws, ok := s.workspace(w, r)
+	if !ok {
+		return
+	}
+	s.writeJSON(w, r, http.StatusOK, s.workspacePage(ws, r.URL.Query().Get("cursor"), pageSize(r)))
+}
+
+func (s *Server) getModule(w http.ResponseWriter, r *http.Request) {
+	ws, ok := s.workspace(w, r)
+	if !ok {
+		return
+	}
+
+	path := r.PathValue("path")
+	if path == "" {
+		// The root module, as /modules/. is cleaned to /modules/
+		path = "."
+	}
+	tfconfig, ok := ws.Modules[path]
+	if !ok {
+		s.writeError(w, r, http.StatusNotFound, fmt.Errorf("module %s not found in workspace %s", path, ws.ID))
+		return
+	}
+	s.writeJSON(w, r, http.StatusOK, tfconfig)
+}
+
+func (s *Server) workspace(w http.ResponseWriter, r *http.Request) (*Workspace, bool) {
+	id := r.PathValue("id")
+
+	ws, err := s.store.Get(r.Context(), id)
+	if err != nil {
+		logger.ErrorKV("Failed to read workspace", "workspace", id, "error", err)
+		s.writeError(w, r, http.StatusInternalServerError, err)
+		return nil, false
+	}
+	if ws == nil {
+		s.writeError(w, r, http.StatusNotFound, fmt.Errorf("workspace %s not found", id))
+		return nil, false
+	}
+	return ws, true
+}
+
+func (s *Server) workspacePage(ws *Workspace, cursor string, limit int) *WorkspacePage {
+	paths := ws.Paths()
+	keys, next := page(paths, cursor, limit)
+
+	result := &WorkspacePage{
+		ID:         ws.ID,
+		Request:    ws.Request,
+		ParsedAt:   ws.ParsedAt,
+		Commit:     ws.Commit,
+		Total:      len(paths),
+		Modules:    []*catalog.Module{},
+		Errors:     ws.Errors,
+		NextCursor: next,
+	}
+	for _, path := range keys {
+		result.Modules = append(result.Modules, catalog.NewModule(path, ws.Modules[path]))
+	}
+	return result
+}
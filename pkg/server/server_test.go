@@ -0,0 +1,736 @@
+package server
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/diff"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/output"
+)
+
+func writeModules(t *testing.T, count int) string {
+	t.Helper()
+
+	root := t.TempDir()
+	for i := range count {
+		dir := filepath.Join(root, "modules", fmt.Sprintf("m%02d", i))
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		content := fmt.Sprintf("variable \"name_%d\" {\n  description = %q\n}\n", i, strings.Repeat("x", 200))
+		if err := os.WriteFile(filepath.Join(dir, "main.tf"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestWorkspacePagination(t *testing.T) {
+	ts := httptest.NewServer(New(output.Options{Casing: output.CasingSnake}).WithLocalTargets().Handler())
+	defer ts.Close()
+
+	root := writeModules(t, 5)
+	resp, err := http.Post(ts.URL+"/workspaces", "application/json", strings.NewReader(fmt.Sprintf(`{"target": %q, "recursive": true}`, root)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	created := &WorkspacePage{}
+	json.NewDecoder(resp.Body).Decode(created)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated || created.Total != 5 {
+		t.Fatalf("Expected 201 with 5 modules, got %d with %+v", resp.StatusCode, created)
+	}
+
+	paths := []string{}
+	cursor := ""
+	for {
+		resp, err := http.Get(ts.URL + "/workspaces/" + created.ID + "?limit=2&cursor=" + cursor)
+		if err != nil {
+			t.Fatal(err)
+		}
+		page := &WorkspacePage{}
+		json.NewDecoder(resp.Body).Decode(page)
+		resp.Body.Close()
+
+		for _, module := range page.Modules {
+			paths = append(paths, module.Path)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+	if len(paths) != 5 || paths[0] != "modules/m00" || paths[4] != "modules/m04" {
+		t.Errorf("Expected every module once in order, got %v", paths)
+	}
+
+	resp, err = http.Get(ts.URL + "/workspaces/" + created.ID + "/modules/modules/m03")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), `"name_3"`) {
+		t.Errorf("Unexpected module response %d: %s", resp.StatusCode, body)
+	}
+
+	resp, err = http.Get(ts.URL + "/workspaces/" + created.ID + "/modules/missing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown module, got %d", resp.StatusCode)
+	}
+}
+
+func TestConditionalAndCompressedResponses(t *testing.T) {
+	ts := httptest.NewServer(New(output.Options{Casing: output.CasingSnake}).WithLocalTargets().Handler())
+	defer ts.Close()
+
+	root := writeModules(t, 10)
+	resp, err := http.Post(ts.URL+"/workspaces", "application/json", strings.NewReader(fmt.Sprintf(`{"target": %q, "recursive": true}`, root)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	created := &WorkspacePage{}
+	json.NewDecoder(resp.Body).Decode(created)
+	resp.Body.Close()
+
+	// The default transport decompresses transparently unless Accept-Encoding is set explicitly
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/workspaces/"+created.ID, nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Header.Get("Content-Encoding") != "gzip" {
+		t.Fatalf("Expected a gzip response, got %q", resp.Header.Get("Content-Encoding"))
+	}
+	gz, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		t.Fatalf("Invalid gzip body: %v", err)
+	}
+	page := &WorkspacePage{}
+	if err := json.NewDecoder(gz).Decode(page); err != nil || page.Total != 10 {
+		t.Errorf("Unexpected decompressed body: %+v (%v)", page, err)
+	}
+	resp.Body.Close()
+
+	etag := resp.Header.Get("ETag")
+	req, _ = http.NewRequest(http.MethodGet, ts.URL+"/workspaces/"+created.ID, nil)
+	req.Header.Set("If-None-Match", etag)
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if etag == "" || resp.StatusCode != http.StatusNotModified {
+		t.Errorf("Expected 304 for ETag %q, got %d", etag, resp.StatusCode)
+	}
+}
+
+func TestJobs(t *testing.T) {
+	srv := New(output.Options{Casing: output.CasingSnake}).WithLocalTargets()
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		srv.RunWorkers(ctx, 2)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	submit := func(target string) *Job {
+		resp, err := http.Post(ts.URL+"/jobs", "application/json", strings.NewReader(fmt.Sprintf(`{"target": %q, "recursive": true}`, target)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusAccepted {
+			t.Fatalf("Expected 202, got %d", resp.StatusCode)
+		}
+		job := &Job{}
+		json.NewDecoder(resp.Body).Decode(job)
+		return job
+	}
+	wait := func(id string) *Job {
+		for range 100 {
+			resp, err := http.Get(ts.URL + "/jobs/" + id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			job := &Job{}
+			json.NewDecoder(resp.Body).Decode(job)
+			resp.Body.Close()
+			if job.Status == JobSucceeded || job.Status == JobFailed {
+				return job
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+		t.Fatalf("Job %s did not finish", id)
+		return nil
+	}
+
+	succeeded := wait(submit(writeModules(t, 3)).ID)
+	if succeeded.Status != JobSucceeded || succeeded.WorkspaceID == "" || succeeded.FinishedAt == nil {
+		t.Fatalf("Unexpected job %+v", succeeded)
+	}
+	resp, err := http.Get(ts.URL + "/workspaces/" + succeeded.WorkspaceID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected the job's workspace to be served, got %d", resp.StatusCode)
+	}
+
+	failed := wait(submit(filepath.Join(t.TempDir(), "missing")).ID)
+	if failed.Status != JobFailed || failed.Error == "" {
+		t.Errorf("Expected the job to fail, got %+v", failed)
+	}
+}
+
+func TestAccess(t *testing.T) {
+	root := writeModules(t, 1)
+	post := func(srv *Server, path, body, token string) int {
+		ts := httptest.NewServer(srv.Handler())
+		defer ts.Close()
+
+		req, err := http.NewRequest(http.MethodPost, ts.URL+path, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	local := fmt.Sprintf(`{"target": %q, "recursive": true}`, root)
+
+	// Local targets are refused by default, on every endpoint taking a target
+	for _, path := range []string{"/workspaces", "/jobs", "/repositories"} {
+		if status := post(New(output.Options{}), path, local, ""); status != http.StatusForbidden {
+			t.Errorf("Expected 403 for a local target on %s, got %d", path, status)
+		}
+	}
+	if status := post(New(output.Options{}), "/jobs", `{"target": "backup.tfbundle"}`, ""); status != http.StatusForbidden {
+		t.Errorf("Expected 403 for a bundle target, got %d", status)
+	}
+
+	// Roots allow the paths under them only
+	allowed := New(output.Options{}).WithLocalTargets(filepath.Dir(root))
+	if status := post(allowed, "/workspaces", local, ""); status != http.StatusCreated {
+		t.Errorf("Expected 201 for a target under an allowed root, got %d", status)
+	}
+	outside := fmt.Sprintf(`{"target": %q, "subdir": "../.."}`, root)
+	if status := post(allowed, "/workspaces", outside, ""); status != http.StatusForbidden {
+		t.Errorf("Expected 403 for a subdir out of the allowed root, got %d", status)
+	}
+
+	// Git targets need an allowed host and an encrypted transport
+	for target, expected := range map[string]int{
+		"https://github.com/owner/repo.git":        http.StatusAccepted,
+		"git@github.com:owner/repo.git":            http.StatusAccepted,
+		"https://github.com.evil.example/repo.git": http.StatusForbidden,
+		"https://attacker.example/repo.git":        http.StatusForbidden,
+		"http://github.com/owner/repo.git":         http.StatusForbidden,
+		"git://github.com/owner/repo.git":          http.StatusForbidden,
+	} {
+		srv := New(output.Options{}).WithGitHosts("GitHub.com")
+		if status := post(srv, "/jobs", fmt.Sprintf(`{"target": %q}`, target), ""); status != expected {
+			t.Errorf("Expected %d for %s, got %d", expected, target, status)
+		}
+	}
+	if status := post(New(output.Options{}), "/jobs", `{"target": "https://github.com/owner/repo.git"}`, ""); status != http.StatusForbidden {
+		t.Errorf("Expected 403 for a git target without allowed hosts, got %d", status)
+	}
+
+	// Request bodies are bounded
+	large := fmt.Sprintf(`{"target": "https://github.com/owner/repo.git", "ref": %q}`, strings.Repeat("a", maxRequestBody))
+	for _, path := range []string{"/workspaces", "/jobs", "/repositories"} {
+		if status := post(New(output.Options{}).WithGitHosts("github.com"), path, large, ""); status != http.StatusRequestEntityTooLarge {
+			t.Errorf("Expected 413 for a large body on %s, got %d", path, status)
+		}
+	}
+
+	// A token is required on every endpoint once set
+	secured := New(output.Options{}).WithLocalTargets().WithToken("s3cret")
+	for token, expected := range map[string]int{"": http.StatusUnauthorized, "wrong": http.StatusUnauthorized, "s3cret": http.StatusCreated} {
+		if status := post(secured, "/workspaces", local, token); status != expected {
+			t.Errorf("Expected %d with token %q, got %d", expected, token, status)
+		}
+	}
+}
+
+func TestParseCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := Parse(ctx, &ParseRequest{Target: writeModules(t, 2), Recursive: true}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the cancellation of the request context, got %v", err)
+	}
+}
+
+func TestQueueFull(t *testing.T) {
+	queue := NewMemoryQueue(1)
+	ctx := context.Background()
+
+	if err := queue.Enqueue(ctx, &Job{ID: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := queue.Enqueue(ctx, &Job{ID: "b"}); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Expected ErrQueueFull, got %v", err)
+	}
+	if job, _ := queue.Dequeue(ctx); job == nil || job.ID != "a" {
+		t.Errorf("Expected job a, got %+v", job)
+	}
+}
+
+func TestMemoryRetention(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	queue := NewMemoryQueue(10).(*memoryQueue)
+	queue.now, queue.maxFinished = clock, 2
+	for _, id := range []string{"a", "b", "c", "running"} {
+		if err := queue.Enqueue(ctx, &Job{ID: id, Status: JobQueued}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, id := range []string{"a", "b", "c"} {
+		finished := now
+		if err := queue.Update(ctx, &Job{ID: id, Status: JobSucceeded, FinishedAt: &finished}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if job, _ := queue.Get(ctx, "a"); job != nil {
+		t.Errorf("Expected the oldest finished job to be dropped beyond the cap, got %+v", job)
+	}
+	if job, _ := queue.Get(ctx, "c"); job == nil {
+		t.Error("Expected the last finished job to be kept")
+	}
+
+	now = now.Add(jobRetention + time.Minute)
+	if job, _ := queue.Get(ctx, "c"); job != nil {
+		t.Errorf("Expected a job finished before the retention to expire, got %+v", job)
+	}
+	if job, _ := queue.Get(ctx, "running"); job == nil {
+		t.Error("Expected an unfinished job to be kept")
+	}
+	queue.Enqueue(ctx, &Job{ID: "d"})
+	if len(queue.jobs) != 2 || len(queue.finished) != 0 {
+		t.Errorf("Expected expired jobs to be removed, got %d jobs and %v", len(queue.jobs), queue.finished)
+	}
+
+	store := NewMemoryStore().(*memoryStore)
+	store.now, store.max = clock, 2
+	for _, id := range []string{"a", "b", "a", "c"} {
+		if err := store.Put(ctx, &Workspace{ID: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for id, kept := range map[string]bool{"a": true, "b": false, "c": true} {
+		if ws, _ := store.Get(ctx, id); (ws != nil) != kept {
+			t.Errorf("Expected workspace %s kept %v, got %+v", id, kept, ws)
+		}
+	}
+	now = now.Add(workspaceRetention + time.Minute)
+	if ws, _ := store.Get(ctx, "c"); ws != nil {
+		t.Errorf("Expected the workspace to expire, got %+v", ws)
+	}
+}
+
+func TestRedisReplies(t *testing.T) {
+	conn := &redisConn{r: bufio.NewReader(strings.NewReader("+OK\r\n:3\r\n$5\r\nhello\r\n$-1\r\n*2\r\n$4\r\nlist\r\n$2\r\nid\r\n*-1\r\n-ERR wrong type\r\n"))}
+
+	expected := []any{"OK", int64(3), "hello", nil, []any{"list", "id"}, nil}
+	for _, want := range expected {
+		got, err := conn.read()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("Expected %v, got %v", want, got)
+		}
+	}
+
+	var redisErr redisError
+	if _, err := conn.read(); !errors.As(err, &redisErr) {
+		t.Errorf("Expected a redis error reply, got %v", err)
+	}
+}
+
+func TestRedisQueue(t *testing.T) {
+	// A fake server answering each command with the reply queued for it, recording the commands
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	var mu sync.Mutex
+	var commands [][]string
+	replies := map[string][]string{}
+	go func() {
+		for {
+			c, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer c.Close()
+				conn := &redisConn{Conn: c, r: bufio.NewReader(c)}
+				for {
+					request, err := conn.read()
+					if err != nil {
+						return
+					}
+					var args []string
+					for _, arg := range request.([]any) {
+						args = append(args, arg.(string))
+					}
+
+					mu.Lock()
+					commands = append(commands, args)
+					reply := "+OK\r\n"
+					if queued := replies[args[0]]; len(queued) > 0 {
+						reply, replies[args[0]] = queued[0], queued[1:]
+					}
+					mu.Unlock()
+					io.WriteString(c, reply)
+				}
+			}()
+		}
+	}()
+	sent := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		var names []string
+		for _, command := range commands {
+			names = append(names, command[0])
+		}
+		commands = nil
+		return names
+	}
+
+	queue, err := NewRedisQueue("redis://"+listener.Addr().String(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	sent()
+
+	// Enqueue checks the length and pushes in one script
+	replies["EVAL"] = []string{":0\r\n", ":1\r\n"}
+	if err := queue.Enqueue(ctx, &Job{ID: "a"}); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Expected ErrQueueFull, got %v", err)
+	}
+	if err := queue.Enqueue(ctx, &Job{ID: "a"}); err != nil {
+		t.Errorf("Enqueue failed: %v", err)
+	}
+	if names := sent(); fmt.Sprint(names) != "[EVAL EVAL]" {
+		t.Errorf("Expected the enqueue script only, got %v", names)
+	}
+
+	// Dequeue re-queues the jobs of dead workers, then moves a job to the processing list and leases it
+	replies["EVAL"] = []string{":1\r\n"}
+	replies["BLMOVE"] = []string{"$1\r\na\r\n"}
+	content := `{"id":"a","status":"queued"}`
+	replies["GET"] = []string{fmt.Sprintf("$%d\r\n%s\r\n", len(content), content)}
+	job, err := queue.Dequeue(ctx)
+	if err != nil || job == nil || job.ID != "a" {
+		t.Fatalf("Expected job a, got %+v, %v", job, err)
+	}
+	if names := sent(); fmt.Sprint(names) != "[EVAL BLMOVE GET SET]" {
+		t.Errorf("Expected recovery, move, read and lease, got %v", names)
+	}
+
+	// Running jobs keep their claim; finished ones release it with their final state
+	job.Status = JobRunning
+	if err := queue.Update(ctx, job); err != nil {
+		t.Fatal(err)
+	}
+	job.Status = JobSucceeded
+	if err := queue.Update(ctx, job); err != nil {
+		t.Fatal(err)
+	}
+	if names := sent(); fmt.Sprint(names) != "[SET EVAL]" {
+		t.Errorf("Expected an update then the finish script, got %v", names)
+	}
+	if leases := len(queue.(*redisQueue).leases); leases != 0 {
+		t.Errorf("Expected the lease to be released, %d left", leases)
+	}
+}
+
+func TestStores(t *testing.T) {
+	// A minimal S3 endpoint keeping objects in memory
+	objects := map[string][]byte{}
+	s3 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.Method {
+		case http.MethodPut:
+			objects[r.URL.Path], _ = io.ReadAll(r.Body)
+		case http.MethodGet:
+			content, ok := objects[r.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write(content)
+		}
+	}))
+	defer s3.Close()
+
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKID")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+
+	ctx := context.Background()
+	fileStore, err := OpenStore(ctx, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s3Store, err := OpenStore(ctx, "s3://results/tfparser?region=eu-west-1&endpoint="+s3.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	target := writeModules(t, 2)
+	defaults := "variable \"replicas\" {\n  default = 3\n}\n\nvariable \"limits\" {\n  default = { cpu = 2, ratio = 0.5 }\n}\n"
+	if err := os.WriteFile(filepath.Join(target, "modules", "m00", "defaults.tf"), []byte(defaults), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ws, err := Parse(ctx, &ParseRequest{Target: target, Recursive: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, store := range map[string]Store{"memory": NewMemoryStore(), "file": fileStore, "s3": s3Store} {
+		t.Run(name, func(t *testing.T) {
+			if err := store.Put(ctx, ws); err != nil {
+				t.Fatalf("Put failed: %v", err)
+			}
+
+			loaded, err := store.Get(ctx, ws.ID)
+			if err != nil || loaded == nil {
+				t.Fatalf("Get failed: %v", err)
+			}
+			if len(loaded.Modules) != 2 || loaded.Modules["modules/m01"].Variables[0].Name != "name_1" {
+				t.Errorf("Unexpected workspace after round trip: %+v", loaded)
+			}
+			// Numbers read back must compare equal to a fresh parse, or every refresh reports drift
+			if events := interfaceEvents("repo", loaded, ws); len(events) != 0 {
+				t.Errorf("Expected no interface change after round trip, got %+v", events[0])
+			}
+
+			if missing, err := store.Get(ctx, "missing"); err != nil || missing != nil {
+				t.Errorf("Expected no workspace, got %+v (%v)", missing, err)
+			}
+		})
+	}
+
+	if _, ok := objects["/results/tfparser/"+ws.ID+".json"]; !ok {
+		t.Errorf("Expected the workspace under the bucket prefix, got %v", objects)
+	}
+
+	if _, err := OpenStore(ctx, "postgres://localhost/tfparser"); err == nil {
+		t.Error("Expected an error without a registered PostgreSQL driver")
+	}
+}
+
+func TestScheduledRepositories(t *testing.T) {
+	srv := New(output.Options{Casing: output.CasingSnake}).WithLocalTargets()
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	root := t.TempDir()
+	write := func(rel, content string) {
+		path := filepath.Join(root, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("modules/vpc/main.tf", "variable \"cidr\" {\n  default = \"10.0.0.0/16\"\n}\n\noutput \"id\" {\n  value = \"x\"\n}\n")
+	write("modules/dns/main.tf", "variable \"zone\" {}\n")
+
+	resp, err := http.Post(ts.URL+"/repositories", "application/json", strings.NewReader(fmt.Sprintf(`{"target": %q, "recursive": true, "schedule": "0 * * * *"}`, root)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := &Repository{}
+	json.NewDecoder(resp.Body).Decode(repo)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated || repo.ID == "" {
+		t.Fatalf("Expected 201 with a repository, got %d with %+v", resp.StatusCode, repo)
+	}
+
+	// Registered repositories are due right away to set the baseline
+	if due := srv.dueRepositories(time.Now().Add(time.Second)); len(due) != 1 {
+		t.Fatalf("Expected the new repository to be due, got %v", due)
+	}
+	baseline, err := srv.Refresh(context.Background(), repo.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if baseline.WorkspaceID == "" || !baseline.NextRun.After(*baseline.LastRun) || len(srv.repos.eventsSince(0)) != 0 {
+		t.Fatalf("Expected a baseline without events, got %+v", baseline)
+	}
+
+	// Make cidr required, drop the output and the dns module, add a module
+	write("modules/vpc/main.tf", "variable \"cidr\" {}\n")
+	os.RemoveAll(filepath.Join(root, "modules", "dns"))
+	write("modules/iam/main.tf", "variable \"role\" {}\n")
+
+	resp, err = http.Post(ts.URL+"/repositories/"+repo.ID+"/refresh", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	refreshed := &Repository{}
+	json.NewDecoder(resp.Body).Decode(refreshed)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || refreshed.WorkspaceID == baseline.WorkspaceID {
+		t.Fatalf("Expected a new workspace, got %d with %+v", resp.StatusCode, refreshed)
+	}
+
+	resp, err = http.Get(ts.URL + "/events?since=0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := []*Event{}
+	json.NewDecoder(resp.Body).Decode(&events)
+	resp.Body.Close()
+
+	got := []string{}
+	for _, event := range events {
+		got = append(got, fmt.Sprintf("%s %s %d", event.Module, event.Kind, event.Breaking))
+	}
+	expected := []string{"modules/iam module_added 0", "modules/vpc interface_changed 2", "modules/dns module_removed 1"}
+	if strings.Join(got, ", ") != strings.Join(expected, ", ") {
+		t.Fatalf("Expected events %v, got %v", expected, got)
+	}
+	if len(srv.repos.eventsSince(events[1].Seq)) != 1 {
+		t.Errorf("Expected one event after seq %d", events[1].Seq)
+	}
+
+	req, _ := http.NewRequest(http.MethodDelete, ts.URL+"/repositories/"+repo.ID, nil)
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent || len(srv.repos.list()) != 0 {
+		t.Errorf("Expected the repository to be removed, got %d", resp.StatusCode)
+	}
+}
+
+func TestEventStream(t *testing.T) {
+	received := make(chan *http.Request, 10)
+	bodies := make(chan string, 10)
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- r
+		bodies <- string(body)
+	}))
+	defer hook.Close()
+
+	srv := New(output.Options{Casing: output.CasingSnake}).
+		WithWebhook(&Webhook{URL: hook.URL, Secret: "s3cret", EventFilter: EventFilter{BreakingOnly: true}})
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go srv.RunWebhooks(ctx)
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+"/events/stream?repository=r1", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("Expected an event stream, got %q", resp.Header.Get("Content-Type"))
+	}
+
+	// Give RunWebhooks time to start watching
+	time.Sleep(50 * time.Millisecond)
+	srv.repos.publish([]*Event{
+		{Repository: "r2", Module: ".", Kind: EventModuleAdded},
+		{Repository: "r1", Module: "modules/vpc", Kind: EventModuleAdded},
+		{Repository: "r1", Module: "modules/vpc", Kind: EventInterfaceChanged, Commit: "abc123", Breaking: 1,
+			Changes: []*diff.Change{{Address: "var.cidr", Kind: diff.ChangeAdded, Breaking: true, Reason: "new required variable"}}},
+	})
+
+	reader := bufio.NewReader(resp.Body)
+	lines := []string{}
+	for len(lines) < 6 {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "data:") {
+			lines = append(lines, line)
+		}
+		if strings.HasPrefix(line, "data:") {
+			lines = append(lines, "data")
+		}
+	}
+	expected := "id: 2, event: module_added, data, id: 3, event: interface_changed, data"
+	if strings.Join(lines, ", ") != expected {
+		t.Fatalf("Expected %s, got %s", expected, strings.Join(lines, ", "))
+	}
+
+	select {
+	case r := <-received:
+		body := <-bodies
+		mac := hmac.New(sha256.New, []byte("s3cret"))
+		mac.Write([]byte(body))
+		if r.Header.Get("X-Tfparser-Signature") != "sha256="+hex.EncodeToString(mac.Sum(nil)) {
+			t.Errorf("Expected a valid signature, got %q", r.Header.Get("X-Tfparser-Signature"))
+		}
+		if !strings.Contains(body, `"seq":3`) || !strings.Contains(body, `"commit":"abc123"`) {
+			t.Errorf("Expected the breaking event, got %s", body)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected a webhook delivery")
+	}
+	select {
+	case <-bodies:
+		t.Error("Expected only the breaking event to be delivered")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
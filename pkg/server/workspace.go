@@ -0,0 +1,109 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/logger"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/source"
+)
+
+// ParseRequest is the body of POST /workspaces
+type ParseRequest struct {
+	// Target is a local path on the server or a git URL
+	Target string `json:"target"`
+	Ref    string `json:"ref,omitempty"`
+	SubDir string `json:"subdir,omitempty"`
+	// Recursive parses every directory containing .tf files under the target
+	Recursive bool `json:"recursive,omitempty"`
+}
+
+// Workspace is a parsed source kept by the server
+type Workspace struct {
+	ID       string        `json:"id"`
+	Request  *ParseRequest `json:"request"`
+	ParsedAt time.Time     `json:"parsed_at"`
+	// Commit is the commit checked out for git targets
+	Commit string `json:"commit,omitempty"`
+	// Modules maps module paths, relative to the target ("." for the target itself), to their configuration
+	Modules map[string]*parser.TerraformConfig `json:"modules"`
+	// Errors maps module paths that could not be parsed to the parse error
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// Paths returns the module paths in order
+func (w *Workspace) Paths() []string {
+	paths := make([]string, 0, len(w.Modules))
+	for path := range w.Modules {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+	return paths
+}
+
+// Parse fetches and parses the source of req. Cancelling ctx stops the clone and the parser with
+// the cause of the cancellation.
+func Parse(ctx context.Context, req *ParseRequest) (*Workspace, error) {
+	if req.Target == "" {
+		return nil, fmt.Errorf("target is required")
+	}
+
+	src := source.New(req.Target, source.SourceConfig{Ref: req.Ref, SubDir: req.SubDir})
+	fs, rootPath, err := source.Fetch(ctx, src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch source: %w", err)
+	}
+	defer src.Cleanup()
+
+	dirs := []string{rootPath}
+	if req.Recursive {
+		if dirs, err = source.ConfigDirs(fs, rootPath); err != nil {
+			return nil, err
+		}
+	}
+
+	ws := &Workspace{
+		ID:       newID(),
+		Request:  req,
+		ParsedAt: time.Now().UTC().Truncate(time.Second),
+		Modules:  map[string]*parser.TerraformConfig{},
+		Errors:   map[string]string{},
+	}
+	if git, ok := src.(*source.GitSource); ok {
+		ws.Commit = git.Commit
+	}
+
+	p := parser.NewParser(fs, parser.Detail).WithContext(ctx)
+	for _, dir := range dirs {
+		rel, err := filepath.Rel(rootPath, dir)
+		if err != nil {
+			rel = dir
+		}
+		rel = filepath.ToSlash(rel)
+
+		tfconfig, err := p.ParseTerraformWorkspace(dir)
+		if err != nil {
+			if !req.Recursive || ctx.Err() != nil {
+				return nil, err
+			}
+			logger.InfoKV("Skipping module that failed to parse", "path", rel, "error", err)
+			ws.Errors[rel] = err.Error()
+			continue
+		}
+		ws.Modules[rel] = tfconfig
+	}
+
+	return ws, nil
+}
+
+func newID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,308 @@
+// Package sops decrypts YAML documents encrypted with SOPS (https://github.com/getsops/sops)
+// using age keys. The data key is decrypted with filippo.io/age; the values are opened with the
+// AES-256-GCM scheme of the SOPS format.
+package sops
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"gopkg.in/yaml.v3"
+)
+
+// MetadataKey is the top-level key holding the SOPS metadata of an encrypted document
+const MetadataKey = "sops"
+
+var encryptedValue = regexp.MustCompile(`^ENC\[AES256_GCM,data:(.*),iv:(.+),tag:(.+),type:(.+)\]$`)
+
+// IsEncrypted reports whether the YAML document has SOPS metadata
+func IsEncrypted(doc *yaml.Node) bool {
+	return metadata(doc) != nil
+}
+
+// metadata returns the value of the sops key of the document, or nil
+func metadata(doc *yaml.Node) *yaml.Node {
+	root := doc
+	if root.Kind == yaml.DocumentNode && len(root.Content) == 1 {
+		root = root.Content[0]
+	}
+	if root.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == MetadataKey {
+			return root.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// IdentitiesFromEnv reads the age keys SOPS itself uses: SOPS_AGE_KEY, SOPS_AGE_KEY_FILE or
+// sops/age/keys.txt in the user config directory
+func IdentitiesFromEnv() ([]age.Identity, error) {
+	if keys := os.Getenv("SOPS_AGE_KEY"); keys != "" {
+		return age.ParseIdentities(strings.NewReader(keys))
+	}
+
+	path := os.Getenv("SOPS_AGE_KEY_FILE")
+	if path == "" {
+		dir := os.Getenv("XDG_CONFIG_HOME")
+		if dir == "" {
+			var err error
+			if dir, err = os.UserConfigDir(); err != nil {
+				return nil, errors.New("no age key: set SOPS_AGE_KEY or SOPS_AGE_KEY_FILE")
+			}
+		}
+		path = filepath.Join(dir, "sops", "age", "keys.txt")
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("no age key: set SOPS_AGE_KEY or SOPS_AGE_KEY_FILE (%w)", err)
+	}
+	identities, err := age.ParseIdentities(bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read age keys from %s: %w", path, err)
+	}
+	return identities, nil
+}
+
+// Decrypt replaces every encrypted value of the YAML document with its plaintext and removes the
+// SOPS metadata. Each value is authenticated together with its path, and the document as a whole
+// against the MAC of its metadata, which detects removed, added and reordered values. The
+// document is left untouched when any check fails.
+func Decrypt(doc *yaml.Node, identities []age.Identity) error {
+	meta := metadata(doc)
+	if meta == nil {
+		return errors.New("document is not encrypted with SOPS")
+	}
+
+	key, err := dataKey(meta, identities)
+	if err != nil {
+		return err
+	}
+	settings := struct {
+		MAC              string `yaml:"mac"`
+		LastModified     string `yaml:"lastmodified"`
+		MACOnlyEncrypted bool   `yaml:"mac_only_encrypted"`
+	}{}
+	if err := meta.Decode(&settings); err != nil {
+		return fmt.Errorf("invalid SOPS metadata: %w", err)
+	}
+
+	root := doc
+	if root.Kind == yaml.DocumentNode {
+		root = root.Content[0]
+	}
+	d := &decrypter{key: key, hash: sha512.New(), macOnlyEncrypted: settings.MACOnlyEncrypted}
+	content := []*yaml.Node{}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == MetadataKey {
+			continue
+		}
+		if err := d.walk(root.Content[i+1], []string{root.Content[i].Value}); err != nil {
+			return err
+		}
+		content = append(content, root.Content[i], root.Content[i+1])
+	}
+	if err := verifyMAC(fmt.Sprintf("%X", d.hash.Sum(nil)), settings.MAC, settings.LastModified, key); err != nil {
+		return err
+	}
+
+	for _, value := range d.values {
+		value.node.Value, value.node.Tag, value.node.Style = value.plaintext, value.tag, 0
+	}
+	root.Content = content
+	return nil
+}
+
+// verifyMAC checks the MAC of the document, encrypted in the metadata with the last modification
+// time as additional data, against mac computed over its values
+func verifyMAC(mac, encrypted, lastModified string, key []byte) error {
+	if encrypted == "" {
+		return errors.New("the SOPS document has no MAC")
+	}
+	match := encryptedValue.FindStringSubmatch(encrypted)
+	if match == nil {
+		return errors.New("invalid SOPS MAC")
+	}
+	// SOPS authenticates the MAC with the time as it formats it
+	if modified, err := time.Parse(time.RFC3339, lastModified); err == nil {
+		lastModified = modified.Format(time.RFC3339)
+	}
+	expected, err := decryptValue(match, key, lastModified)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt SOPS MAC: %w", err)
+	}
+	if subtle.ConstantTimeCompare([]byte(mac), []byte(expected)) != 1 {
+		return errors.New("MAC mismatch; the document was altered: values were removed, added or reordered")
+	}
+	return nil
+}
+
+// dataKey decrypts the data key from the first age recipient entry one of the identities matches
+func dataKey(meta *yaml.Node, identities []age.Identity) ([]byte, error) {
+	entries := struct {
+		Age []struct {
+			Recipient string `yaml:"recipient"`
+			Enc       string `yaml:"enc"`
+		} `yaml:"age"`
+	}{}
+	if err := meta.Decode(&entries); err != nil {
+		return nil, fmt.Errorf("invalid SOPS metadata: %w", err)
+	}
+	if len(entries.Age) == 0 {
+		return nil, errors.New("the SOPS document has no age recipients; only age keys are supported")
+	}
+
+	recipients := []string{}
+	for _, entry := range entries.Age {
+		key, err := decryptKey(entry.Enc, identities)
+		var noMatch *age.NoIdentityMatchError
+		if errors.As(err, &noMatch) {
+			recipients = append(recipients, entry.Recipient)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt SOPS data key for %s: %w", entry.Recipient, err)
+		}
+		return key, nil
+	}
+	return nil, fmt.Errorf("none of the age keys can decrypt the document, encrypted for %s", strings.Join(recipients, ", "))
+}
+
+// decryptKey decrypts an armored age file holding a data key
+func decryptKey(enc string, identities []age.Identity) ([]byte, error) {
+	r, err := age.Decrypt(armor.NewReader(strings.NewReader(strings.TrimSpace(enc))), identities...)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+// decrypter decrypts the values of a document in order and hashes them like SOPS does for the MAC:
+// the plaintext of every value, or of encrypted values only with mac_only_encrypted
+type decrypter struct {
+	key              []byte
+	hash             hash.Hash
+	macOnlyEncrypted bool
+	// values are applied once the MAC is verified
+	values []*decryptedValue
+}
+
+type decryptedValue struct {
+	node      *yaml.Node
+	plaintext string
+	tag       string
+}
+
+func (d *decrypter) walk(node *yaml.Node, path []string) error {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if err := d.walk(node.Content[i+1], append(path[:len(path):len(path)], node.Content[i].Value)); err != nil {
+				return err
+			}
+		}
+	case yaml.SequenceNode:
+		// Items share the path of their sequence
+		for _, item := range node.Content {
+			if err := d.walk(item, path); err != nil {
+				return err
+			}
+		}
+	case yaml.ScalarNode:
+		match := encryptedValue.FindStringSubmatch(node.Value)
+		if match == nil {
+			if !d.macOnlyEncrypted {
+				d.hash.Write(macBytes(node.Value, node.ShortTag()))
+			}
+			return nil
+		}
+		plaintext, err := decryptValue(match, d.key, strings.Join(path, ":")+":")
+		if err != nil {
+			return fmt.Errorf("failed to decrypt %s: %w", strings.Join(path, "."), err)
+		}
+
+		tag := "!!str"
+		switch match[4] {
+		case "int":
+			tag = "!!int"
+		case "float":
+			tag = "!!float"
+		case "bool":
+			tag = "!!bool"
+		}
+		d.hash.Write(macBytes(plaintext, tag))
+		d.values = append(d.values, &decryptedValue{node: node, plaintext: plaintext, tag: tag})
+	}
+	return nil
+}
+
+// macBytes returns what SOPS hashes for a value of the YAML type tag: numbers in their canonical
+// form, booleans as True or False and strings as they are
+func macBytes(value, tag string) []byte {
+	switch tag {
+	case "!!int":
+		if n, err := strconv.ParseInt(value, 0, 64); err == nil {
+			return []byte(strconv.FormatInt(n, 10))
+		}
+	case "!!float":
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return []byte(strconv.FormatFloat(f, 'f', -1, 64))
+		}
+	case "!!bool":
+		if b, err := strconv.ParseBool(strings.ToLower(value)); err == nil {
+			if b {
+				return []byte("True")
+			}
+			return []byte("False")
+		}
+	case "!!null":
+		return nil
+	}
+	return []byte(value)
+}
+
+// decryptValue opens an AES-256-GCM value with its path as additional data
+func decryptValue(match []string, key []byte, aad string) (string, error) {
+	var parts [3][]byte
+	for i, encoded := range match[1:4] {
+		decoded, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return "", fmt.Errorf("invalid encrypted value: %w", err)
+		}
+		parts[i] = decoded
+	}
+	data, iv, tag := parts[0], parts[1], parts[2]
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := cipher.NewGCMWithNonceSize(block, len(iv))
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := gcm.Open(nil, iv, append(data, tag...), []byte(aad))
+	if err != nil {
+		return "", errors.New("authentication failed; the value was altered or moved")
+	}
+	return string(plaintext), nil
+}
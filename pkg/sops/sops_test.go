@@ -0,0 +1,150 @@
+package sops
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"gopkg.in/yaml.v3"
+)
+
+// encryptValue encrypts a value like SOPS does, with its path as additional data
+func encryptValue(t *testing.T, key []byte, value, valueType, path string) string {
+	t.Helper()
+
+	iv := make([]byte, 32)
+	rand.Read(iv)
+	block, _ := aes.NewCipher(key)
+	gcm, _ := cipher.NewGCMWithNonceSize(block, len(iv))
+	sealed := gcm.Seal(nil, iv, []byte(value), []byte(path))
+	data, tag := sealed[:len(sealed)-gcm.Overhead()], sealed[len(sealed)-gcm.Overhead():]
+
+	enc := base64.StdEncoding.EncodeToString
+	return fmt.Sprintf("ENC[AES256_GCM,data:%s,iv:%s,tag:%s,type:%s]", enc(data), enc(iv), enc(tag), valueType)
+}
+
+// encryptMAC computes the MAC of the plaintext values like SOPS does and encrypts it with the
+// last modification time as additional data
+func encryptMAC(t *testing.T, key []byte, lastModified string, values ...string) string {
+	t.Helper()
+
+	hash := sha512.New()
+	for _, value := range values {
+		hash.Write([]byte(value))
+	}
+	return encryptValue(t, key, fmt.Sprintf("%X", hash.Sum(nil)), "str", lastModified)
+}
+
+// encryptKey encrypts a data key to an armored age file, as SOPS stores it
+func encryptKey(t *testing.T, key []byte, recipient age.Recipient) string {
+	t.Helper()
+
+	var sb strings.Builder
+	armored := armor.NewWriter(&sb)
+	w, err := age.Encrypt(armored, recipient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write(key); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := armored.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return sb.String()
+}
+
+func TestDecrypt(t *testing.T) {
+	identity, _ := age.GenerateX25519Identity()
+	key := make([]byte, 32)
+	rand.Read(key)
+	encryptedKey := encryptKey(t, key, identity.Recipient())
+
+	token := encryptValue(t, key, "ghp_secret", "str", "credentials:github.com:token:")
+	document := fmt.Sprintf(`credentials:
+  github.com:
+    username: bot
+    token: %s
+retries: %s
+hosts:
+  - %s
+sops:
+  age:
+    - recipient: %s
+      enc: |
+%s
+  lastmodified: "2024-05-01T10:00:00Z"
+  mac: %s
+  version: 3.9.0
+`,
+		token,
+		encryptValue(t, key, "3", "int", "retries:"),
+		encryptValue(t, key, "gitlab.com", "str", "hosts:"),
+		identity.Recipient(),
+		"        "+strings.ReplaceAll(strings.TrimSpace(encryptedKey), "\n", "\n        "),
+		encryptMAC(t, key, "2024-05-01T10:00:00Z", "bot", "ghp_secret", "3", "gitlab.com"))
+
+	doc := &yaml.Node{}
+	if err := yaml.Unmarshal([]byte(document), doc); err != nil {
+		t.Fatal(err)
+	}
+	if !IsEncrypted(doc) {
+		t.Fatal("Expected the document to be encrypted")
+	}
+	if err := Decrypt(doc, []age.Identity{identity}); err != nil {
+		t.Fatal(err)
+	}
+
+	decoded := map[string]any{}
+	if err := doc.Decode(&decoded); err != nil {
+		t.Fatal(err)
+	}
+	expected := "map[credentials:map[github.com:map[token:ghp_secret username:bot]] hosts:[gitlab.com] retries:3]"
+	if got := fmt.Sprint(decoded); got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+
+	// A value moved to another path fails authentication
+	moved := strings.Replace(document, "retries: ", "retries: "+token+"\nunused: ", 1)
+	doc = &yaml.Node{}
+	yaml.Unmarshal([]byte(moved), doc)
+	if err := Decrypt(doc, []age.Identity{identity}); err == nil || !strings.Contains(err.Error(), "retries") {
+		t.Errorf("Expected the moved value to be rejected, got %v", err)
+	}
+
+	// Removing or reordering values fails the MAC, and leaves the document encrypted
+	for name, tampered := range map[string]string{
+		"removed":   document[:strings.Index(document, "hosts:")] + document[strings.Index(document, "sops:"):],
+		"reordered": strings.Replace(document, "    username: bot\n    token: "+token, "    token: "+token+"\n    username: bot", 1),
+		"added":     strings.Replace(document, "retries: ", "region: eu-west-1\nretries: ", 1),
+	} {
+		if tampered == document {
+			t.Fatalf("Failed to tamper with the document (%s)", name)
+		}
+		doc = &yaml.Node{}
+		yaml.Unmarshal([]byte(tampered), doc)
+		if err := Decrypt(doc, []age.Identity{identity}); err == nil || !strings.Contains(err.Error(), "MAC mismatch") {
+			t.Errorf("Expected a MAC mismatch for a %s value, got %v", name, err)
+		}
+		if !IsEncrypted(doc) || strings.Contains(fmt.Sprint(doc.Content[0].Content), "ghp_secret") {
+			t.Errorf("Expected the %s document to be left encrypted", name)
+		}
+	}
+
+	other, _ := age.GenerateX25519Identity()
+	doc = &yaml.Node{}
+	yaml.Unmarshal([]byte(document), doc)
+	if err := Decrypt(doc, []age.Identity{other}); err == nil {
+		t.Error("Expected an error without a matching key")
+	}
+}
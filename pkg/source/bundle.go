@@ -0,0 +1,307 @@
+package source
+
+import (
+	"archive/tar"
+	"bytes"
+	"cmp"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/filesystem"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/logger"
+
+	"github.com/go-git/go-billy/v5/memfs"
+)
+
+// BundleExtension is the file extension of bundles; targets ending with it are read as bundles
+const BundleExtension = ".tfbundle"
+
+const (
+	bundleFormat       = 1
+	bundleManifestName = "bundle.json"
+	bundleSummaryName  = "summary.json"
+	bundleFilesDir     = "files/"
+)
+
+// BundleManifest describes where the files of a bundle were fetched from
+type BundleManifest struct {
+	// Format is the version of the bundle layout
+	Format int `json:"format"`
+	// Tool is the version of the tool that wrote the bundle
+	Tool string `json:"tool,omitempty"`
+	// Source is "local" or "git"
+	Source   string `json:"source"`
+	Location string `json:"location"`
+	Ref      string `json:"ref,omitempty"`
+	// Commit is the commit checked out for git sources
+	Commit string `json:"commit,omitempty"`
+	// SubDir is the configuration directory within the files
+	SubDir    string         `json:"subdir,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+	Files     []*BundledFile `json:"files"`
+}
+
+type BundledFile struct {
+	Path   string `json:"path"`
+	Size   int    `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Bundle is a fetched source kept in a single archive, to be parsed where the source cannot be
+// reached: a gzipped tar with the manifest (bundle.json), the parse result (summary.json) and the
+// fetched files under files/
+type Bundle struct {
+	Manifest *BundleManifest
+	// Summary is the parse result recorded when the bundle was written
+	Summary []byte
+	// Files maps slash-separated paths to their content
+	Files map[string][]byte
+}
+
+// IsBundle reports whether target names a bundle file
+func IsBundle(target string) bool {
+	return strings.HasSuffix(target, BundleExtension)
+}
+
+// NewBundle collects the files of a fetched source: every file under the directory src was
+// fetched into, hidden directories such as .git and .terraform excepted. fs and rootPath are
+// the results of src.Fetch, and summary the parse result to keep with them.
+func NewBundle(src Source, fs filesystem.FileReader, rootPath string, summary []byte) (*Bundle, error) {
+	manifest := &BundleManifest{Format: bundleFormat, CreatedAt: time.Now().UTC(), Files: []*BundledFile{}}
+	base := "."
+	switch s := src.(type) {
+	case *GitSource:
+		manifest.Source, manifest.Location, manifest.Ref, manifest.Commit = "git", s.URL, s.Config.Ref, s.Commit
+		manifest.SubDir = s.Config.SubDir
+	case *LocalSource:
+		manifest.Source, manifest.Location = "local", s.Path
+		manifest.SubDir = s.Config.SubDir
+		base = s.Path
+	case *BundleSource:
+		// Rebundling keeps the original origin
+		manifest.Source, manifest.Location, manifest.Ref, manifest.Commit = s.manifest.Source, s.manifest.Location, s.manifest.Ref, s.manifest.Commit
+		manifest.SubDir = strings.TrimPrefix(filepath.ToSlash(rootPath), "./")
+	default:
+		return nil, fmt.Errorf("cannot bundle source %T", src)
+	}
+
+	bundle := &Bundle{Manifest: manifest, Summary: summary, Files: map[string][]byte{}}
+
+	var walk func(dir string) error
+	walk = func(dir string) error {
+		entries, err := fs.ReadDir(dir)
+		if err != nil {
+			return fmt.Errorf("failed to read directory %s: %w", dir, err)
+		}
+		for _, entry := range entries {
+			name := filepath.Join(dir, entry.Name())
+			if entry.IsDir() {
+				if !strings.HasPrefix(entry.Name(), ".") {
+					if err := walk(name); err != nil {
+						return err
+					}
+				}
+				continue
+			}
+			if !entry.Mode().IsRegular() {
+				continue
+			}
+
+			content, err := fs.ReadFile(name)
+			if err != nil {
+				return fmt.Errorf("failed to read file %s: %w", name, err)
+			}
+			rel, err := filepath.Rel(base, name)
+			if err != nil {
+				return err
+			}
+			bundle.add(filepath.ToSlash(rel), content)
+		}
+		return nil
+	}
+	if err := walk(base); err != nil {
+		return nil, err
+	}
+
+	logger.DebugKV("Collected bundle files", "location", manifest.Location, "files", len(manifest.Files), "root_path", rootPath)
+	return bundle, nil
+}
+
+func (b *Bundle) add(name string, content []byte) {
+	sum := sha256.Sum256(content)
+	b.Files[name] = content
+	b.Manifest.Files = append(b.Manifest.Files, &BundledFile{Path: name, Size: len(content), SHA256: hex.EncodeToString(sum[:])})
+}
+
+// Write writes the bundle archive to w
+func (b *Bundle) Write(w io.Writer) error {
+	manifest, err := json.MarshalIndent(b.Manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	write := func(name string, content []byte) error {
+		header := &tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), ModTime: b.Manifest.CreatedAt}
+		if err := tw.WriteHeader(header); err != nil {
+			return fmt.Errorf("failed to write bundle: %w", err)
+		}
+		if _, err := tw.Write(content); err != nil {
+			return fmt.Errorf("failed to write bundle: %w", err)
+		}
+		return nil
+	}
+
+	if err := write(bundleManifestName, manifest); err != nil {
+		return err
+	}
+	if b.Summary != nil {
+		if err := write(bundleSummaryName, b.Summary); err != nil {
+			return err
+		}
+	}
+	for _, file := range b.Manifest.Files {
+		if err := write(bundleFilesDir+file.Path, b.Files[file.Path]); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	return gz.Close()
+}
+
+// ReadBundle reads a bundle archive and checks its files against the hashes of the manifest
+func ReadBundle(r io.Reader) (*Bundle, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle: %w", err)
+	}
+	defer gz.Close()
+
+	bundle := &Bundle{Files: map[string][]byte{}}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read bundle: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read bundle entry %s: %w", header.Name, err)
+		}
+		switch name := path.Clean(header.Name); {
+		case name == bundleManifestName:
+			bundle.Manifest = &BundleManifest{}
+			if err := json.Unmarshal(content, bundle.Manifest); err != nil {
+				return nil, fmt.Errorf("failed to read bundle manifest: %w", err)
+			}
+		case name == bundleSummaryName:
+			bundle.Summary = content
+		case strings.HasPrefix(name, bundleFilesDir):
+			bundle.Files[strings.TrimPrefix(name, bundleFilesDir)] = content
+		}
+	}
+
+	if bundle.Manifest == nil {
+		return nil, fmt.Errorf("failed to read bundle: no %s", bundleManifestName)
+	}
+	if bundle.Manifest.Format > bundleFormat {
+		return nil, fmt.Errorf("bundle format %d is newer than the supported format %d", bundle.Manifest.Format, bundleFormat)
+	}
+	for _, file := range bundle.Manifest.Files {
+		if !filepath.IsLocal(filepath.FromSlash(file.Path)) {
+			return nil, fmt.Errorf("bundle file %s is outside the bundle", file.Path)
+		}
+		content, ok := bundle.Files[file.Path]
+		if !ok {
+			return nil, fmt.Errorf("bundle file %s is missing", file.Path)
+		}
+		if sum := sha256.Sum256(content); hex.EncodeToString(sum[:]) != file.SHA256 {
+			return nil, fmt.Errorf("bundle file %s does not match its hash", file.Path)
+		}
+	}
+	return bundle, nil
+}
+
+// BundleSource reads the files of a bundle written by the bundle command, without network access
+type BundleSource struct {
+	Path   string
+	Config SourceConfig
+
+	manifest *BundleManifest
+}
+
+func NewBundleSource(path string, config SourceConfig) *BundleSource {
+	return &BundleSource{Path: path, Config: config}
+}
+
+// Manifest returns the manifest of the bundle, set by Fetch
+func (s *BundleSource) Manifest() *BundleManifest {
+	return s.manifest
+}
+
+// Fetch extracts the bundle into memory. The root is the subdirectory of the configuration, as
+// recorded in the bundle, unless Config.SubDir selects another directory of the bundled files.
+func (s *BundleSource) Fetch() (filesystem.FileReader, string, error) {
+	content, err := os.ReadFile(s.Path)
+	if err != nil {
+		return nil, "", err
+	}
+	bundle, err := ReadBundle(bytes.NewReader(content))
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", s.Path, err)
+	}
+	s.manifest = bundle.Manifest
+
+	billyFs := memfs.New()
+	for _, file := range bundle.Manifest.Files {
+		f, err := billyFs.Create(file.Path)
+		if err != nil {
+			return nil, "", err
+		}
+		if _, err := f.Write(bundle.Files[file.Path]); err != nil {
+			f.Close()
+			return nil, "", err
+		}
+		if err := f.Close(); err != nil {
+			return nil, "", err
+		}
+	}
+
+	rootPath := "."
+	if subDir := cmp.Or(s.Config.SubDir, bundle.Manifest.SubDir); subDir != "" {
+		rootPath = subDir
+	}
+
+	fs, err := filesystem.WithIgnoreFile(filesystem.NewBillyAdapter(billyFs), rootPath)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read %s: %w", filesystem.IgnoreFileName, err)
+	}
+
+	logger.InfoKV("Read bundle", "path", s.Path, "location", bundle.Manifest.Location, "commit", bundle.Manifest.Commit, "root_path", rootPath)
+	return fs, rootPath, nil
+}
+
+func (s *BundleSource) Cleanup() error {
+	return nil
+}
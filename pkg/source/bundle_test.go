@@ -0,0 +1,85 @@
+package source
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBundle(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"main.tf":                      `module "vpc" { source = "./modules/vpc" }`,
+		"modules/vpc/main.tf":          `variable "cidr" {}`,
+		".terraform.lock.hcl":          "# lock",
+		".terraform/modules/x/main.tf": "# downloaded",
+		".git/HEAD":                    "ref: refs/heads/main",
+		"modules/vpc/README.md":        "# VPC",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	src := NewLocalSource(dir, SourceConfig{SubDir: "modules/vpc"})
+	fs, rootPath, err := src.Fetch()
+	if err != nil {
+		t.Fatalf("Failed to fetch: %v", err)
+	}
+	bundle, err := NewBundle(src, fs, rootPath, []byte(`{"variables":[]}`))
+	if err != nil {
+		t.Fatalf("Failed to bundle: %v", err)
+	}
+
+	paths := []string{}
+	for _, file := range bundle.Manifest.Files {
+		paths = append(paths, file.Path)
+	}
+	if strings.Join(paths, ",") != ".terraform.lock.hcl,main.tf,modules/vpc/README.md,modules/vpc/main.tf" {
+		t.Errorf("Expected the files outside hidden directories, got %v", paths)
+	}
+
+	var buf bytes.Buffer
+	if err := bundle.Write(&buf); err != nil {
+		t.Fatalf("Failed to write bundle: %v", err)
+	}
+	archive := filepath.Join(t.TempDir(), "vpc"+BundleExtension)
+	if err := os.WriteFile(archive, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	bundleSrc, ok := New(archive, SourceConfig{}).(*BundleSource)
+	if !ok {
+		t.Fatalf("Expected a bundle source for %s", archive)
+	}
+	bundleFS, bundleRoot, err := bundleSrc.Fetch()
+	if err != nil {
+		t.Fatalf("Failed to read bundle: %v", err)
+	}
+	if bundleRoot != "modules/vpc" || bundleSrc.Manifest().Location != dir {
+		t.Errorf("Expected the recorded subdirectory and location, got %q and %q", bundleRoot, bundleSrc.Manifest().Location)
+	}
+	if content, err := bundleFS.ReadFile("modules/vpc/main.tf"); err != nil || string(content) != `variable "cidr" {}` {
+		t.Errorf("Expected the bundled file, got %q (%v)", content, err)
+	}
+
+	read, err := ReadBundle(bytes.NewReader(buf.Bytes()))
+	if err != nil || string(read.Summary) != `{"variables":[]}` {
+		t.Errorf("Expected the summary to be kept, got %q (%v)", read.Summary, err)
+	}
+
+	bundle.Files["main.tf"] = []byte("tampered")
+	buf.Reset()
+	if err := bundle.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadBundle(&buf); err == nil || !strings.Contains(err.Error(), "does not match its hash") {
+		t.Errorf("Expected a modified file to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,114 @@
+package source
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/filesystem"
+)
+
+// FileStatus tells whether a run reads a file
+type FileStatus string
+
+const (
+	// FileConsidered is a configuration, lock or ignore file that a run reads
+	FileConsidered FileStatus = "considered"
+	// FileSkipped is another file of a configuration directory
+	FileSkipped FileStatus = "skipped"
+	// FileExcluded is a file or directory hidden by the ignore file
+	FileExcluded FileStatus = "excluded"
+)
+
+// FileList is the inventory of the files of the configuration directories of a source, to audit
+// which inputs produced a summary
+type FileList struct {
+	RootPath string `json:"root_path"`
+	// Commit is the commit checked out for git sources and bundles
+	Commit string `json:"commit,omitempty"`
+	// Digest is the SHA-256 of the sorted "<sha256>  <path>" lines of the considered files, which
+	// changes whenever an input of the run does
+	Digest string        `json:"digest"`
+	Files  []*ListedFile `json:"files"`
+}
+
+type ListedFile struct {
+	// Path is slash-separated and relative to the root path
+	Path   string     `json:"path"`
+	Status FileStatus `json:"status"`
+	// Directory is set for excluded directories, whose content is not listed
+	Directory bool   `json:"directory,omitempty"`
+	Size      int    `json:"size,omitempty"`
+	SHA256    string `json:"sha256,omitempty"`
+}
+
+// ListFiles lists the files of rootPath or, with recursive, of every configuration directory
+// under it (see ConfigDirs), with the size and SHA-256 of the files that are not excluded. fs and
+// rootPath are the results of Fetch.
+func ListFiles(fs filesystem.FileReader, rootPath string, recursive bool) (*FileList, error) {
+	dirs := []string{rootPath}
+	if recursive {
+		var err error
+		if dirs, err = ConfigDirs(fs, rootPath); err != nil {
+			return nil, err
+		}
+	}
+
+	// The ignore file hides entries from fs; they are listed from the reader it wraps
+	raw, ignoring := fs, (*filesystem.IgnoringReader)(nil)
+	if r, ok := fs.(*filesystem.IgnoringReader); ok {
+		raw, ignoring = r.FileReader, r
+	}
+
+	list := &FileList{RootPath: rootPath, Files: []*ListedFile{}}
+	for _, dir := range dirs {
+		entries, err := raw.ReadDir(dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read directory %s: %w", dir, err)
+		}
+
+		for _, entry := range entries {
+			name := filepath.Join(dir, entry.Name())
+			rel, err := filepath.Rel(rootPath, name)
+			if err != nil {
+				return nil, err
+			}
+			file := &ListedFile{Path: filepath.ToSlash(rel), Directory: entry.IsDir()}
+
+			switch {
+			case ignoring != nil && ignoring.Ignores(name, entry.IsDir()):
+				file.Status = FileExcluded
+			case entry.IsDir() || !entry.Mode().IsRegular():
+				continue
+			case IsConfigFile(entry.Name()), entry.Name() == ".terraform.lock.hcl", entry.Name() == filesystem.IgnoreFileName:
+				file.Status = FileConsidered
+			default:
+				file.Status = FileSkipped
+			}
+
+			if file.Status != FileExcluded {
+				content, err := fs.ReadFile(name)
+				if err != nil {
+					return nil, fmt.Errorf("failed to read file %s: %w", name, err)
+				}
+				sum := sha256.Sum256(content)
+				file.Size, file.SHA256 = len(content), hex.EncodeToString(sum[:])
+			}
+			list.Files = append(list.Files, file)
+		}
+	}
+
+	considered := []string{}
+	for _, file := range list.Files {
+		if file.Status == FileConsidered {
+			considered = append(considered, file.SHA256+"  "+file.Path+"\n")
+		}
+	}
+	slices.Sort(considered)
+	sum := sha256.Sum256([]byte(strings.Join(considered, "")))
+	list.Digest = "sha256:" + hex.EncodeToString(sum[:])
+	return list, nil
+}
@@ -0,0 +1,89 @@
+package source
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestListFiles(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"main.tf":               `module "vpc" { source = "./modules/vpc" }`,
+		".terraform.lock.hcl":   "# lock",
+		".tfparserignore":       "generated.tf\nvendor/\n",
+		"generated.tf":          `variable "generated" {}`,
+		"vendor/main.tf":        `variable "vendored" {}`,
+		"notes.txt":             "notes",
+		"modules/vpc/main.tf":   `variable "cidr" {}`,
+		"modules/vpc/README.md": "# VPC",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fs, rootPath, err := NewLocalSource(dir, SourceConfig{}).Fetch()
+	if err != nil {
+		t.Fatalf("Failed to fetch: %v", err)
+	}
+	list, err := ListFiles(fs, rootPath, true)
+	if err != nil {
+		t.Fatalf("Failed to list files: %v", err)
+	}
+
+	expected := map[string]FileStatus{
+		"main.tf":               FileConsidered,
+		".terraform.lock.hcl":   FileConsidered,
+		".tfparserignore":       FileConsidered,
+		"generated.tf":          FileExcluded,
+		"vendor":                FileExcluded,
+		"notes.txt":             FileSkipped,
+		"modules/vpc/main.tf":   FileConsidered,
+		"modules/vpc/README.md": FileSkipped,
+	}
+	if len(list.Files) != len(expected) {
+		t.Errorf("Expected %d files, got %d", len(expected), len(list.Files))
+	}
+	for _, file := range list.Files {
+		if status, ok := expected[file.Path]; !ok || file.Status != status {
+			t.Errorf("Unexpected status %q for %s", file.Status, file.Path)
+		}
+		switch {
+		case file.Status == FileExcluded && file.SHA256 != "":
+			t.Errorf("Expected no hash for excluded %s", file.Path)
+		case file.Status != FileExcluded && (file.SHA256 == "" || file.Size == 0):
+			t.Errorf("Expected size and hash for %s, got %+v", file.Path, file)
+		}
+		if file.Path == "vendor" && !file.Directory {
+			t.Error("Expected vendor to be listed as a directory")
+		}
+	}
+
+	// The digest only follows the considered files
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("changed"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	same, err := ListFiles(fs, rootPath, true)
+	if err != nil {
+		t.Fatalf("Failed to list files: %v", err)
+	}
+	if same.Digest != list.Digest {
+		t.Error("Expected the digest to ignore skipped files")
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "modules/vpc/main.tf"), []byte(`variable "name" {}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	changed, err := ListFiles(fs, rootPath, true)
+	if err != nil {
+		t.Fatalf("Failed to list files: %v", err)
+	}
+	if changed.Digest == list.Digest {
+		t.Error("Expected the digest to change with a configuration file")
+	}
+}
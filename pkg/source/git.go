@@ -1,20 +1,33 @@
 package source
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"net/url"
 	"os"
+	"path"
+	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/filesystem"
 	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/logger"
 
+	"github.com/go-git/go-billy/v5"
 	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-billy/v5/util"
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/go-git/go-git/v5/plumbing/transport/http"
 	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	hcljson "github.com/hashicorp/hcl/v2/json"
+	"github.com/zclconf/go-cty/cty"
 	"go.uber.org/zap"
 )
 
@@ -22,6 +35,8 @@ import (
 type GitSource struct {
 	URL    string
 	Config SourceConfig
+	// Commit is the hash of the checked out commit, set by Fetch
+	Commit string
 }
 
 func NewGitSource(url string, config SourceConfig) *GitSource {
@@ -32,10 +47,12 @@ func NewGitSource(url string, config SourceConfig) *GitSource {
 }
 
 func (s *GitSource) Fetch() (filesystem.FileReader, string, error) {
-	logger.Info("Starting git repository clone", zap.String("url", s.URL), zap.String("ref", s.Config.Ref), zap.String("subdir", s.Config.SubDir))
+	return s.FetchContext(context.Background())
+}
 
-	// Create in-memory filesystem for Git operations
-	billyFs := memfs.New()
+// FetchContext clones the repository like Fetch, and stops when ctx is cancelled
+func (s *GitSource) FetchContext(ctx context.Context) (filesystem.FileReader, string, error) {
+	logger.Info("Starting git repository clone", zap.String("url", s.URL), zap.String("ref", s.Config.Ref), zap.String("subdir", s.Config.SubDir))
 
 	// Clone options
 	cloneOptions := &git.CloneOptions{
@@ -53,7 +70,7 @@ func (s *GitSource) Fetch() (filesystem.FileReader, string, error) {
 
 	// Set reference (branch, tag, or commit) if specified
 	if s.Config.Ref != "" {
-		refType := detectRefType(s.Config.Ref)
+		refType := DetectRefType(s.Config.Ref)
 		logger.Debug("Cloning specific reference", zap.String("ref", s.Config.Ref), zap.String("type", getRefTypeName(refType)))
 
 		switch refType {
@@ -64,16 +81,42 @@ func (s *GitSource) Fetch() (filesystem.FileReader, string, error) {
 			cloneOptions.ReferenceName = plumbing.ReferenceName("refs/tags/" + s.Config.Ref)
 			cloneOptions.SingleBranch = true
 		case RefTypeCommit:
-			// For commits, we'll clone and then checkout the specific commit
+			// Commits are checked out after cloning every branch, whose history holds them
 			logger.Debug("Will checkout commit after clone", zap.String("commit", s.Config.Ref))
+			cloneOptions.Depth = 0
 		}
 	} else {
 		logger.Debug("Cloning default branch")
 	}
 
-	// Clone repository directly to in-memory storage
-	_, err := git.Clone(memory.NewStorage(), billyFs, cloneOptions)
+	// With a subdirectory and sparse checkout enabled, only that directory is checked out
+	sparse := s.sparseDir()
+	cloneOptions.NoCheckout = sparse != "" || s.isCommitRef()
+
+	if s.Config.Limiter != nil {
+		release, err := s.Config.Limiter.Acquire(ctx, GitHost(s.URL))
+		if err != nil {
+			return nil, "", err
+		}
+		defer release()
+	}
+
+	// Clone repository to the cache directory, or directly to in-memory storage
+	var repo *git.Repository
+	var billyFs billy.Filesystem
+	var err error
+	if gitCacheDir != "" {
+		repo, billyFs, err = s.cachedClone(ctx, gitCacheDir, sparse, cloneOptions)
+	} else {
+		billyFs = memfs.New()
+		if repo, err = git.CloneContext(ctx, memory.NewStorage(), billyFs, cloneOptions); err == nil {
+			err = s.checkout(ctx, repo, sparse, cloneOptions)
+		}
+	}
 	if err != nil {
+		if cause := context.Cause(ctx); cause != nil {
+			err = cause
+		}
 		ref := "default"
 		if s.Config.Ref != "" {
 			ref = s.Config.Ref
@@ -82,6 +125,10 @@ func (s *GitSource) Fetch() (filesystem.FileReader, string, error) {
 		return nil, "", fmt.Errorf("failed to clone repository %s (ref: %s): %w", s.URL, ref, err)
 	}
 
+	if head, err := repo.Head(); err == nil {
+		s.Commit = head.Hash().String()
+	}
+
 	// Create Billy adapter
 	billyAdapter := filesystem.NewBillyAdapter(billyFs)
 
@@ -92,21 +139,285 @@ func (s *GitSource) Fetch() (filesystem.FileReader, string, error) {
 		logger.Debug("Using subdirectory", zap.String("subdir", s.Config.SubDir))
 	}
 
-	logger.Info("Successfully cloned git repository", zap.String("url", s.URL), zap.String("root_path", rootPath))
-	return billyAdapter, rootPath, nil
+	fs, err := filesystem.WithIgnoreFile(billyAdapter, rootPath)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read %s: %w", filesystem.IgnoreFileName, err)
+	}
+
+	logger.Info("Successfully cloned git repository", zap.String("url", s.URL), zap.String("commit", s.Commit), zap.String("root_path", rootPath))
+	return fs, rootPath, nil
 }
 
-func (s *GitSource) getAuthentication() *http.BasicAuth {
-	// Parse URL to determine provider
-	parsedURL, err := url.Parse(s.URL)
+// sparseCheckout is set by SetSparseCheckout(true)
+var sparseCheckout = false
+
+// SetSparseCheckout selects whether git sources with a subdirectory check out that directory
+// alone, or the whole repository (the default). Only the checkout is reduced: the whole commit is
+// still fetched. A subdirectory with local module sources outside it, such as ../shared, is
+// checked out whole anyway.
+func SetSparseCheckout(enabled bool) {
+	sparseCheckout = enabled
+}
+
+// sparseDir returns the slash-separated subdirectory to check out alone, or "" for a full checkout
+func (s *GitSource) sparseDir() string {
+	dir := path.Clean(filepath.ToSlash(s.Config.SubDir))
+	if !sparseCheckout || dir == "." || dir == "/" || strings.HasPrefix(dir, "../") || dir == ".." {
+		return ""
+	}
+	return strings.TrimPrefix(dir, "/")
+}
+
+// isCommitRef reports whether the ref of the source is a full or abbreviated commit hash
+func (s *GitSource) isCommitRef() bool {
+	return s.Config.Ref != "" && DetectRefType(s.Config.Ref) == RefTypeCommit
+}
+
+// checkout checks out what a clone without checkout left out: the commit of a commit ref, or the
+// subdirectory of the source alone. A subdirectory whose local module sources leave it is then
+// checked out whole.
+func (s *GitSource) checkout(ctx context.Context, repo *git.Repository, sparse string, options *git.CloneOptions) error {
+	var err error
+	switch {
+	case s.isCommitRef():
+		err = checkoutCommit(ctx, repo, s.Config.Ref, sparse, options)
+	case sparse != "":
+		err = checkoutSparse(repo, sparse)
+	}
+	if err != nil || sparse == "" {
+		return err
+	}
+	return widenCheckout(repo, sparse)
+}
+
+// checkoutCommit checks out the commit whose hash starts with ref, with only dir when it is not
+// empty. A commit missing from the cloned branches, e.g. of a pull request, is fetched alone when
+// ref is a full hash and the server allows it.
+func checkoutCommit(ctx context.Context, repo *git.Repository, ref, dir string, options *git.CloneOptions) error {
+	hash, err := resolveCommit(repo, ref)
+	if errors.Is(err, plumbing.ErrObjectNotFound) && len(ref) == len(plumbing.ZeroHash.String()) {
+		logger.DebugKV("Fetching commit missing from the cloned branches", "commit", ref)
+		fetchErr := repo.FetchContext(ctx, &git.FetchOptions{
+			RemoteName: git.DefaultRemoteName,
+			RefSpecs:   []config.RefSpec{config.RefSpec(ref + ":refs/tfparser/commit")},
+			Depth:      1,
+			Auth:       options.Auth,
+		})
+		if fetchErr != nil && !errors.Is(fetchErr, git.NoErrAlreadyUpToDate) {
+			return fmt.Errorf("commit %s not found: %w", ref, fetchErr)
+		}
+		hash, err = resolveCommit(repo, ref)
+	}
+	if err != nil {
+		return err
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+	checkoutOptions := &git.CheckoutOptions{Hash: hash}
+	if dir != "" {
+		checkoutOptions.SparseCheckoutDirectories = []string{dir}
+	}
+	if err := worktree.Checkout(checkoutOptions); err != nil {
+		return fmt.Errorf("failed to check out commit %s: %w", hash, err)
+	}
+	logger.DebugKV("Checked out commit", "ref", ref, "commit", hash.String(), "subdir", dir)
+	return nil
+}
+
+// resolveCommit finds the commit whose hash starts with ref, failing when several do
+func resolveCommit(repo *git.Repository, ref string) (plumbing.Hash, error) {
+	if len(ref) == len(plumbing.ZeroHash.String()) {
+		hash := plumbing.NewHash(ref)
+		if _, err := repo.CommitObject(hash); err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("commit %s: %w", ref, err)
+		}
+		return hash, nil
+	}
+
+	commits, err := repo.CommitObjects()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	var found []plumbing.Hash
+	err = commits.ForEach(func(commit *object.Commit) error {
+		if strings.HasPrefix(commit.Hash.String(), ref) {
+			found = append(found, commit.Hash)
+		}
+		return nil
+	})
+	switch {
+	case err != nil:
+		return plumbing.ZeroHash, err
+	case len(found) == 0:
+		return plumbing.ZeroHash, fmt.Errorf("commit %s: %w", ref, plumbing.ErrObjectNotFound)
+	case len(found) > 1:
+		return plumbing.ZeroHash, fmt.Errorf("commit %s is ambiguous: %d commits start with it", ref, len(found))
+	}
+	return found[0], nil
+}
+
+// checkoutSparse checks out dir alone from the HEAD of a repository cloned without checkout. The
+// whole commit is still fetched, but only the files under dir are written to the worktree.
+func checkoutSparse(repo *git.Repository, dir string) error {
+	head, err := repo.Head()
+	if err != nil {
+		return fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+	worktree, err := repo.Worktree()
 	if err != nil {
+		return err
+	}
+
+	options := &git.CheckoutOptions{SparseCheckoutDirectories: []string{dir}}
+	if head.Name().IsBranch() {
+		options.Branch = head.Name()
+	} else {
+		options.Hash = head.Hash()
+	}
+	if err := worktree.Checkout(options); err != nil {
+		return fmt.Errorf("failed to check out %s: %w", dir, err)
+	}
+	logger.DebugKV("Checked out subdirectory alone", "subdir", dir, "commit", head.Hash().String())
+	return nil
+}
+
+// widenCheckout checks out the whole HEAD commit when a module call under the sparsely checked
+// out dir has a local source outside it
+func widenCheckout(repo *git.Repository, dir string) error {
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+	outside, err := localModuleOutside(worktree.Filesystem, dir)
+	if err != nil || outside == "" {
+		return err
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+	// The index of a sparse checkout already lists the files left out, so a reset alone would not
+	// write them: it starts from an empty index instead
+	if err := repo.Storer.SetIndex(&index.Index{Version: 2}); err != nil {
+		return err
+	}
+	if err := worktree.Reset(&git.ResetOptions{Commit: head.Hash(), Mode: git.HardReset}); err != nil {
+		return fmt.Errorf("failed to check out the whole repository: %w", err)
+	}
+	logger.InfoKV("Checked out the whole repository for a local module outside the subdirectory", "subdir", dir, "module", outside)
+	return nil
+}
+
+// moduleSchema reads the source argument of module blocks
+var moduleSchema = &hcl.BodySchema{Blocks: []hcl.BlockHeaderSchema{{Type: "module", LabelNames: []string{"name"}}}}
+
+// localModuleOutside returns the first local module source of the configuration files under dir,
+// slash-separated and relative to the root of fs, that points outside dir, or ""
+func localModuleOutside(fs billy.Filesystem, dir string) (string, error) {
+	outside := ""
+	err := util.Walk(fs, dir, func(name string, info os.FileInfo, err error) error {
+		if err != nil || outside != "" || info.IsDir() || !IsConfigFile(info.Name()) {
+			return err
+		}
+		content, err := util.ReadFile(fs, name)
+		if err != nil {
+			return err
+		}
+
+		var file *hcl.File
+		if strings.HasSuffix(name, ".tf.json") {
+			file, _ = hcljson.Parse(content, name)
+		} else {
+			file, _ = hclsyntax.ParseConfig(content, name, hcl.InitialPos)
+		}
+		if file == nil || file.Body == nil {
+			return nil
+		}
+		body, _, _ := file.Body.PartialContent(moduleSchema)
+		for _, block := range body.Blocks {
+			attrs, _, _ := block.Body.PartialContent(&hcl.BodySchema{Attributes: []hcl.AttributeSchema{{Name: "source"}}})
+			attr, ok := attrs.Attributes["source"]
+			if !ok {
+				continue
+			}
+			value, diags := attr.Expr.Value(nil)
+			if diags.HasErrors() || value.Type() != cty.String || value.IsNull() || ParseModuleSource(value.AsString()).Kind != ModuleSourceLocal {
+				continue
+			}
+			target := path.Join(path.Dir(filepath.ToSlash(name)), value.AsString())
+			if target != dir && !strings.HasPrefix(target, dir+"/") {
+				outside = target
+				return nil
+			}
+		}
+		return nil
+	})
+	return outside, err
+}
+
+// Credential authenticates clones from a git host
+type Credential struct {
+	Username string
+	Token    string
+}
+
+// hostCredentials maps lowercase host names to their credentials
+var hostCredentials = map[string]Credential{}
+
+// SetCredentials sets the credentials of git hosts, e.g. from the config file. They take
+// precedence over GITHUB_TOKEN, GITLAB_TOKEN and GIT_TOKEN.
+func SetCredentials(credentials map[string]Credential) {
+	hostCredentials = map[string]Credential{}
+	for host, cred := range credentials {
+		hostCredentials[strings.ToLower(host)] = cred
+	}
+}
+
+// tokenUsername is the username hosts expect with an access token
+func tokenUsername(hostname, token string) string {
+	switch {
+	case strings.HasPrefix(token, "github_pat_"):
+		return "x-access-token"
+	case strings.Contains(hostname, "gitlab"):
+		return "oauth2"
+	default:
+		return "token"
+	}
+}
+
+// tokenHosts restricts GIT_TOKEN to these hosts when not nil
+var tokenHosts []string
+
+// SetTokenHosts sends GIT_TOKEN only to hosts, e.g. the git hosts a server allows. Until it is
+// called, GIT_TOKEN goes to every host, which suits command line runs on targets the user picked.
+func SetTokenHosts(hosts []string) {
+	tokenHosts = make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		tokenHosts = append(tokenHosts, strings.ToLower(host))
+	}
+}
+
+func (s *GitSource) getAuthentication() *http.BasicAuth {
+	hostname := GitHost(s.URL)
+	if hostname == "" {
 		return nil
 	}
 
-	hostname := strings.ToLower(parsedURL.Hostname())
+	// Credentials of the config file take precedence over the environment
+	if cred, ok := hostCredentials[hostname]; ok && cred.Token != "" {
+		username := cred.Username
+		if username == "" {
+			username = tokenUsername(hostname, cred.Token)
+		}
+		return &http.BasicAuth{Username: username, Password: cred.Token}
+	}
 
-	// GitHub
-	if strings.Contains(hostname, "github.com") {
+	// GitHub and GitLab tokens only go to their own hosts; other instances take per-host credentials
+	if hostname == "github.com" {
 		if token := os.Getenv("GITHUB_TOKEN"); token != "" {
 			// Fine-grained tokens start with "github_pat_"
 			username := "token"
@@ -120,8 +431,7 @@ func (s *GitSource) getAuthentication() *http.BasicAuth {
 		}
 	}
 
-	// GitLab
-	if strings.Contains(hostname, "gitlab.com") || strings.Contains(hostname, "gitlab") {
+	if hostname == "gitlab.com" {
 		if token := os.Getenv("GITLAB_TOKEN"); token != "" {
 			return &http.BasicAuth{
 				Username: "gitlab-ci-token",
@@ -131,6 +441,9 @@ func (s *GitSource) getAuthentication() *http.BasicAuth {
 	}
 
 	// GIT_TOKEN (generic)
+	if tokenHosts != nil && !slices.Contains(tokenHosts, hostname) {
+		return nil
+	}
 	if token := os.Getenv("GIT_TOKEN"); token != "" {
 		// Fine-grained GitHub tokens start with "github_pat_"
 		username := "token"
@@ -159,21 +472,24 @@ const (
 	RefTypeCommit
 )
 
-// detectRefType determines if the ref is a branch, tag, or commit hash
-func detectRefType(ref string) RefType {
+var (
+	// commitRefRegex matches abbreviated and full commit hashes (SHA-1: 40 hex chars, or SHA-256: 64 hex chars)
+	commitRefRegex = regexp.MustCompile(`^[a-f0-9]{7,64}$`)
+	// tagRefRegex matches common tag patterns (v1.0.0, 1.0.0, release-1.0, etc.)
+	tagRefRegex = regexp.MustCompile(`^(v?\d+\.\d+(\.\d+)?|.*-\d+\.\d+(\.\d+)?|release-.+)$`)
+)
+
+// DetectRefType determines if the ref is a branch, tag, or commit hash
+func DetectRefType(ref string) RefType {
 	if ref == "" {
 		return RefTypeBranch // default branch
 	}
 
-	// Check if it's a commit hash (SHA-1: 40 hex chars, or SHA-256: 64 hex chars)
-	commitRegex := regexp.MustCompile(`^[a-f0-9]{7,64}$`)
-	if commitRegex.MatchString(ref) {
+	if commitRefRegex.MatchString(ref) {
 		return RefTypeCommit
 	}
 
-	// Check common tag patterns (v1.0.0, 1.0.0, release-1.0, etc.)
-	tagRegex := regexp.MustCompile(`^(v?\d+\.\d+(\.\d+)?|.*-\d+\.\d+(\.\d+)?|release-.+)$`)
-	if tagRegex.MatchString(ref) {
+	if tagRefRegex.MatchString(ref) {
 		return RefTypeTag
 	}
 
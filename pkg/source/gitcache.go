@@ -0,0 +1,128 @@
+package source
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/logger"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/osfs"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+// gitCacheDir is where GitSource keeps its clones between runs; empty clones in memory
+var gitCacheDir string
+
+// SetGitCache sets the directory where GitSource keeps a clone per repository URL and ref
+// between runs, e.g. git under CacheDir. Clones of tags and commits are reused without network
+// access; clones of branches are reused while the branch still points to the same commit. An
+// empty dir clones every repository in memory on each fetch.
+func SetGitCache(dir string) {
+	gitCacheDir = dir
+}
+
+// GitCachePath returns the directory of the cached clone of url at ref under dir. sparse is the
+// only directory checked out, or "" for a full checkout.
+func GitCachePath(dir, url, ref, sparse string) string {
+	key := url + "\x00" + ref
+	if sparse != "" {
+		key += "\x00" + sparse
+	}
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(dir, hex.EncodeToString(sum[:]))
+}
+
+// cachedClone returns the cached clone of the repository, cloning it to the cache when it is
+// missing or its branch has moved. The clone is written next to the cache entry and renamed into
+// place, so that concurrent runs never see a partial clone.
+func (s *GitSource) cachedClone(ctx context.Context, dir, sparse string, options *git.CloneOptions) (*git.Repository, billy.Filesystem, error) {
+	path := GitCachePath(dir, s.URL, s.Config.Ref, sparse)
+
+	if repo, err := git.PlainOpen(path); err == nil {
+		if head, err := repo.Head(); err == nil && s.isCurrent(ctx, head.Hash(), options) {
+			logger.InfoKV("Using cached git clone", "url", s.URL, "ref", s.Config.Ref, "commit", head.Hash().String(), "path", path)
+			return repo, osfs.New(path), nil
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, nil, fmt.Errorf("failed to create git cache directory: %w", err)
+	}
+	tmp, err := os.MkdirTemp(dir, ".clone-")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create git cache directory: %w", err)
+	}
+	repo, err := git.PlainCloneContext(ctx, tmp, false, options)
+	if err == nil {
+		err = s.checkout(ctx, repo, sparse, options)
+	}
+	if err != nil {
+		os.RemoveAll(tmp)
+		return nil, nil, err
+	}
+
+	if err := os.RemoveAll(path); err != nil {
+		os.RemoveAll(tmp)
+		return nil, nil, fmt.Errorf("failed to replace cached clone: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		// Another run stored the same clone first
+		os.RemoveAll(tmp)
+	}
+
+	if repo, err = git.PlainOpen(path); err != nil {
+		return nil, nil, fmt.Errorf("failed to open cached clone: %w", err)
+	}
+	logger.InfoKV("Cached git clone", "url", s.URL, "ref", s.Config.Ref, "path", path)
+	return repo, osfs.New(path), nil
+}
+
+// isCurrent reports whether a cached clone at commit is still what a clone would check out: for
+// commit refs when commit is the one they name, always for tags, and for branches when the remote
+// branch points to commit. A branch whose remote cannot be reached keeps its cached clone.
+func (s *GitSource) isCurrent(ctx context.Context, commit plumbing.Hash, options *git.CloneOptions) bool {
+	if s.isCommitRef() {
+		return strings.HasPrefix(commit.String(), s.Config.Ref)
+	}
+	if s.Config.Ref != "" && DetectRefType(s.Config.Ref) == RefTypeTag {
+		return true
+	}
+
+	remote := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{Name: "origin", URLs: []string{s.URL}})
+	refs, err := remote.ListContext(ctx, &git.ListOptions{Auth: options.Auth})
+	if err != nil {
+		logger.InfoKV("Cannot check cached git clone, using it as is", "url", s.URL, "error", err)
+		return ctx.Err() == nil
+	}
+
+	name := options.ReferenceName
+	if name == "" {
+		name = plumbing.HEAD
+	}
+	for _, ref := range refs {
+		if ref.Name() != name {
+			continue
+		}
+		if ref.Type() == plumbing.SymbolicReference {
+			// HEAD names the default branch
+			name = ref.Target()
+			for _, target := range refs {
+				if target.Name() == name {
+					return target.Hash() == commit
+				}
+			}
+			return false
+		}
+		return ref.Hash() == commit
+	}
+	return false
+}
@@ -0,0 +1,220 @@
+package source
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestGitCache(t *testing.T) {
+	remoteDir := filepath.Join(t.TempDir(), "remote")
+	repo, err := git.PlainInit(remoteDir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	commit := func(content string) plumbing.Hash {
+		if err := os.WriteFile(filepath.Join(remoteDir, "main.tf"), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := worktree.Add("main.tf"); err != nil {
+			t.Fatal(err)
+		}
+		hash, err := worktree.Commit("update", &git.CommitOptions{Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return hash
+	}
+
+	first := commit(`variable "name" {}`)
+	if _, err := repo.CreateTag("v1.0.0", first, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	cacheDir := t.TempDir()
+	SetGitCache(cacheDir)
+	defer SetGitCache("")
+
+	fetch := func(ref string) (*GitSource, string) {
+		src := NewGitSource("file://"+remoteDir, SourceConfig{Ref: ref})
+		fs, rootPath, err := src.Fetch()
+		if err != nil {
+			t.Fatalf("Failed to fetch %q: %v", ref, err)
+		}
+		content, err := fs.ReadFile(filepath.Join(rootPath, "main.tf"))
+		if err != nil {
+			t.Fatalf("Failed to read main.tf: %v", err)
+		}
+		return src, string(content)
+	}
+
+	if src, _ := fetch(""); src.Commit != first.String() {
+		t.Errorf("Expected commit %s, got %s", first, src.Commit)
+	}
+	if _, err := os.Stat(GitCachePath(cacheDir, "file://"+remoteDir, "", "")); err != nil {
+		t.Errorf("Expected the clone in the cache: %v", err)
+	}
+
+	// A moved branch is cloned again
+	second := commit(`variable "region" {}`)
+	if src, content := fetch(""); src.Commit != second.String() || content != `variable "region" {}` {
+		t.Errorf("Expected the new commit %s, got %s with %q", second, src.Commit, content)
+	}
+
+	// Tags are reused without reaching the remote
+	fetch("v1.0.0")
+	if err := os.RemoveAll(remoteDir); err != nil {
+		t.Fatal(err)
+	}
+	if src, content := fetch("v1.0.0"); src.Commit != first.String() || content != `variable "name" {}` {
+		t.Errorf("Expected the cached tag at %s, got %s with %q", first, src.Commit, content)
+	}
+}
+
+func TestSparseCheckout(t *testing.T) {
+	remoteDir := filepath.Join(t.TempDir(), "remote")
+	repo, err := git.PlainInit(remoteDir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"modules/vpc/main.tf":    `variable "name" {}`,
+		"modules/eks/main.tf":    `variable "name" {}`,
+		"main.tf":                `variable "name" {}`,
+		"stacks/prod/main.tf":    `module "shared" { source = "../../shared" }`,
+		"stacks/prod/local.tf":   `module "local" { source = "./local" }`,
+		"stacks/dev/main.tf":     `variable "name" {}`,
+		"stacks/dev/vpc.tf.json": `{"module": {"vpc": {"source": "./modules/vpc"}}}`,
+		"shared/main.tf":         `variable "name" {}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(remoteDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := worktree.Add(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := worktree.Commit("init", &git.CommitOptions{Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()}}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		subdir string
+		sparse bool
+		// other is a file outside subdir, relative to it
+		other string
+		whole bool
+	}{
+		{subdir: "modules/vpc", sparse: true, other: "../eks/main.tf", whole: false},
+		{subdir: "modules/vpc", sparse: false, other: "../eks/main.tf", whole: true},
+		{subdir: "stacks/dev", sparse: true, other: "../../main.tf", whole: false},
+		// A local module source outside the subdirectory needs the whole repository
+		{subdir: "stacks/prod", sparse: true, other: "../../shared/main.tf", whole: true},
+	}
+
+	for _, cache := range []string{"", t.TempDir()} {
+		SetGitCache(cache)
+		for _, tt := range tests {
+			SetSparseCheckout(tt.sparse)
+			fs, rootPath, err := NewGitSource("file://"+remoteDir, SourceConfig{SubDir: tt.subdir}).Fetch()
+			if err != nil {
+				t.Fatalf("Failed to fetch %s (cache %q, sparse %v): %v", tt.subdir, cache, tt.sparse, err)
+			}
+			if _, err := fs.ReadFile(filepath.Join(rootPath, "main.tf")); err != nil {
+				t.Errorf("Expected the subdirectory %s (cache %q, sparse %v): %v", tt.subdir, cache, tt.sparse, err)
+			}
+			_, err = fs.ReadFile(filepath.Join(rootPath, filepath.FromSlash(tt.other)))
+			if tt.whole && err != nil {
+				t.Errorf("Expected the whole repository for %s (cache %q, sparse %v): %v", tt.subdir, cache, tt.sparse, err)
+			} else if !tt.whole && err == nil {
+				t.Errorf("Expected only %s to be checked out (cache %q)", tt.subdir, cache)
+			}
+		}
+	}
+	SetGitCache("")
+	SetSparseCheckout(false)
+}
+
+func TestCommitRef(t *testing.T) {
+	remoteDir := filepath.Join(t.TempDir(), "remote")
+	repo, err := git.PlainInit(remoteDir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	commit := func(content string) plumbing.Hash {
+		if err := os.WriteFile(filepath.Join(remoteDir, "main.tf"), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := worktree.Add("main.tf"); err != nil {
+			t.Fatal(err)
+		}
+		hash, err := worktree.Commit("update", &git.CommitOptions{Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return hash
+	}
+	first := commit(`variable "name" {}`)
+	commit(`variable "region" {}`)
+
+	fetch := func(ref string) (*GitSource, string) {
+		src := NewGitSource("file://"+remoteDir, SourceConfig{Ref: ref})
+		fs, rootPath, err := src.Fetch()
+		if err != nil {
+			t.Fatalf("Failed to fetch %q: %v", ref, err)
+		}
+		content, err := fs.ReadFile(filepath.Join(rootPath, "main.tf"))
+		if err != nil {
+			t.Fatalf("Failed to read main.tf: %v", err)
+		}
+		return src, string(content)
+	}
+
+	// The commit is checked out rather than the head of the default branch, in memory and cached
+	for _, cache := range []string{"", t.TempDir()} {
+		SetGitCache(cache)
+		for _, ref := range []string{first.String(), first.String()[:7]} {
+			if src, content := fetch(ref); src.Commit != first.String() || content != `variable "name" {}` {
+				t.Errorf("Expected commit %s for %s (cache %q), got %s with %q", first, ref, cache, src.Commit, content)
+			}
+		}
+	}
+	SetGitCache("")
+
+	if _, _, err := NewGitSource("file://"+remoteDir, SourceConfig{Ref: "0123456789abcdef"}).Fetch(); err == nil {
+		t.Error("Expected an unknown commit to fail")
+	}
+
+	// A cached clone is only reused for the commit it holds
+	src := NewGitSource("file://"+remoteDir, SourceConfig{Ref: first.String()[:7]})
+	if !src.isCurrent(context.Background(), first, &git.CloneOptions{}) {
+		t.Error("Expected the cached clone of the commit to be current")
+	}
+	head, _ := repo.Head()
+	if src.isCurrent(context.Background(), head.Hash(), &git.CloneOptions{}) {
+		t.Error("Expected a cached clone of another commit not to be current")
+	}
+}
@@ -0,0 +1,123 @@
+package source
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/logger"
+)
+
+// HostLimit is the fetch budget of a remote host
+type HostLimit struct {
+	// Concurrency is the maximum number of simultaneous fetches; 0 means no limit
+	Concurrency int
+	// Interval is the minimum delay between the starts of two fetches; 0 means no pacing
+	Interval time.Duration
+}
+
+// Limiter caps and paces fetches per remote host, so that runs over many repositories of
+// the same host stay below its abuse detection thresholds
+type Limiter struct {
+	defaults HostLimit
+	limits   map[string]HostLimit
+
+	mu    sync.Mutex
+	hosts map[string]*hostState
+}
+
+type hostState struct {
+	// slots holds a token per running fetch, nil without a concurrency limit
+	slots chan struct{}
+
+	mu sync.Mutex
+	// next is the earliest start of the next fetch
+	next time.Time
+}
+
+// NewLimiter creates a limiter applying limits to the hosts it lists (lowercase host names)
+// and defaults to every other host
+func NewLimiter(defaults HostLimit, limits map[string]HostLimit) *Limiter {
+	return &Limiter{defaults: defaults, limits: limits, hosts: map[string]*hostState{}}
+}
+
+func (l *Limiter) state(host string) (*hostState, HostLimit) {
+	limit, ok := l.limits[host]
+	if !ok {
+		limit = l.defaults
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	state, ok := l.hosts[host]
+	if !ok {
+		state = &hostState{}
+		if limit.Concurrency > 0 {
+			state.slots = make(chan struct{}, limit.Concurrency)
+		}
+		l.hosts[host] = state
+	}
+	return state, limit
+}
+
+// Acquire waits until a fetch from host may start and returns the function to call when it is done
+func (l *Limiter) Acquire(ctx context.Context, host string) (func(), error) {
+	state, limit := l.state(host)
+
+	if state.slots != nil {
+		select {
+		case state.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	release := func() {
+		if state.slots != nil {
+			<-state.slots
+		}
+	}
+
+	if limit.Interval > 0 {
+		// Reserve the next start under the lock, then wait outside of it
+		state.mu.Lock()
+		now := time.Now()
+		start := later(state.next, now)
+		state.next = start.Add(limit.Interval)
+		state.mu.Unlock()
+
+		if wait := start.Sub(now); wait > 0 {
+			logger.DebugKV("Pacing fetch", "host", host, "wait", wait)
+			select {
+			case <-time.After(wait):
+			case <-ctx.Done():
+				release()
+				return nil, ctx.Err()
+			}
+		}
+	}
+
+	return release, nil
+}
+
+func later(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+// GitHost returns the lowercase host of a git URL, including scp-like URLs such as git@github.com:owner/repo
+func GitHost(rawURL string) string {
+	if rest, ok := strings.CutPrefix(rawURL, "git@"); ok {
+		host, _, _ := strings.Cut(rest, ":")
+		return strings.ToLower(host)
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(parsed.Hostname())
+}
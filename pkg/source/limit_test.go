@@ -0,0 +1,64 @@
+package source
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLimiter(t *testing.T) {
+	limiter := NewLimiter(HostLimit{}, map[string]HostLimit{
+		"github.com": {Concurrency: 2},
+		"gitlab.com": {Interval: 20 * time.Millisecond},
+	})
+
+	var running, peak atomic.Int32
+	var wg sync.WaitGroup
+	for range 6 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release, err := limiter.Acquire(context.Background(), GitHost("https://github.com/owner/repo"))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer release()
+
+			n := running.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			running.Add(-1)
+		}()
+	}
+	wg.Wait()
+	if peak.Load() != 2 {
+		t.Errorf("Expected at most 2 concurrent fetches from github.com, got %d", peak.Load())
+	}
+
+	start := time.Now()
+	for range 3 {
+		release, err := limiter.Acquire(context.Background(), GitHost("git@gitlab.com:owner/repo.git"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		release()
+	}
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("Expected fetches from gitlab.com to be paced, 3 took %s", elapsed)
+	}
+
+	// Hosts without a budget are not limited
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := limiter.Acquire(ctx, "example.com"); err != nil {
+		t.Errorf("Expected example.com to be unlimited, got %v", err)
+	}
+}
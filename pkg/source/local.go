@@ -1,6 +1,7 @@
 package source
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 
@@ -35,7 +36,12 @@ func (s *LocalSource) Fetch() (filesystem.FileReader, string, error) {
 
 	// Create Afero adapter for OS filesystem
 	aferoAdapter := filesystem.NewAferoAdapter(afero.NewOsFs())
-	return aferoAdapter, rootPath, nil
+
+	fs, err := filesystem.WithIgnoreFile(aferoAdapter, rootPath)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read %s: %w", filesystem.IgnoreFileName, err)
+	}
+	return fs, rootPath, nil
 }
 
 func (s *LocalSource) Cleanup() error {
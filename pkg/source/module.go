@@ -0,0 +1,183 @@
+package source
+
+import (
+	"container/list"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// ModuleSourceKind represents the installer Terraform uses for a module source address
+type ModuleSourceKind int
+
+const (
+	ModuleSourceUnknown ModuleSourceKind = iota
+	ModuleSourceLocal
+	ModuleSourceRegistry
+	ModuleSourceGit
+	ModuleSourceHTTP
+	ModuleSourceS3
+	ModuleSourceGCS
+)
+
+// String returns the string representation of ModuleSourceKind
+func (k ModuleSourceKind) String() string {
+	switch k {
+	case ModuleSourceLocal:
+		return "local"
+	case ModuleSourceRegistry:
+		return "registry"
+	case ModuleSourceGit:
+		return "git"
+	case ModuleSourceHTTP:
+		return "http"
+	case ModuleSourceS3:
+		return "s3"
+	case ModuleSourceGCS:
+		return "gcs"
+	default:
+		return "unknown"
+	}
+}
+
+// ModuleSource is a decomposed module "source" argument
+type ModuleSource struct {
+	Kind ModuleSourceKind
+	// Address is the package address without the forced getter prefix, query and subdirectory
+	Address string
+	// Ref is the "ref" query parameter of git sources
+	Ref string
+	// SubDir is the "//subdir" part of the source address
+	SubDir string
+}
+
+var registrySourceRegex = regexp.MustCompile(`^([0-9A-Za-z.-]+\.[A-Za-z]+/)?[0-9A-Za-z_-]+/[0-9A-Za-z_-]+/[0-9a-z]+$`)
+
+// parsedSources caches ParseModuleSource results by raw address. Large workspaces and manifests
+// repeat the same few sources thousands of times; the least recently used ones are dropped so that
+// long-running servers do not keep every source they have seen.
+var parsedSources = newSourceCache(4096)
+
+// ParseModuleSource classifies a module source address the same way Terraform's module installer does
+func ParseModuleSource(raw string) ModuleSource {
+	if src, ok := parsedSources.get(raw); ok {
+		return src
+	}
+
+	src := parseModuleSource(raw)
+	parsedSources.add(raw, src)
+	return src
+}
+
+// sourceCache is a least recently used cache of parsed module sources
+type sourceCache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type sourceCacheEntry struct {
+	raw string
+	src ModuleSource
+}
+
+func newSourceCache(size int) *sourceCache {
+	return &sourceCache{size: size, order: list.New(), entries: map[string]*list.Element{}}
+}
+
+func (c *sourceCache) get(raw string) (ModuleSource, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[raw]
+	if !ok {
+		return ModuleSource{}, false
+	}
+	c.order.MoveToFront(elem)
+	return elem.Value.(*sourceCacheEntry).src, true
+}
+
+func (c *sourceCache) add(raw string, src ModuleSource) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[raw]; ok {
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.entries[raw] = c.order.PushFront(&sourceCacheEntry{raw: raw, src: src})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*sourceCacheEntry).raw)
+	}
+}
+
+func parseModuleSource(raw string) ModuleSource {
+	src := ModuleSource{Address: raw}
+
+	if strings.HasPrefix(raw, "./") || strings.HasPrefix(raw, "../") {
+		src.Kind = ModuleSourceLocal
+		return src
+	}
+
+	// Forced getter prefix, e.g. git::https://example.com/repo.git
+	forced := ""
+	if idx := strings.Index(raw, "::"); idx > 0 && !strings.Contains(raw[:idx], "/") {
+		forced = raw[:idx]
+		raw = raw[idx+2:]
+	}
+
+	address, query, _ := strings.Cut(raw, "?")
+	address, src.SubDir = splitSubDir(address)
+	src.Address = address
+
+	if values, err := url.ParseQuery(query); err == nil {
+		src.Ref = values.Get("ref")
+	}
+
+	switch {
+	case forced == "git",
+		strings.HasPrefix(address, "git@"),
+		strings.HasPrefix(address, "github.com/"),
+		strings.HasPrefix(address, "bitbucket.org/"),
+		strings.HasSuffix(address, ".git"):
+		src.Kind = ModuleSourceGit
+	case forced == "s3", strings.Contains(address, ".amazonaws.com/"):
+		src.Kind = ModuleSourceS3
+	case forced == "gcs", strings.HasPrefix(address, "www.googleapis.com/storage/"):
+		src.Kind = ModuleSourceGCS
+	case forced == "http", forced == "https",
+		strings.HasPrefix(address, "http://"), strings.HasPrefix(address, "https://"):
+		src.Kind = ModuleSourceHTTP
+	case forced == "" && registrySourceRegex.MatchString(address):
+		src.Kind = ModuleSourceRegistry
+	}
+
+	return src
+}
+
+// splitSubDir separates "//subdir" from a source address while keeping the scheme separator intact
+func splitSubDir(address string) (string, string) {
+	offset := 0
+	if idx := strings.Index(address, "://"); idx >= 0 {
+		offset = idx + 3
+	}
+
+	if idx := strings.Index(address[offset:], "//"); idx >= 0 {
+		return address[:offset+idx], address[offset+idx+2:]
+	}
+
+	return address, ""
+}
+
+// RepositoryURL returns the URL git sources are cloned from, expanding the github.com/owner/repo
+// and bitbucket.org/owner/repo shorthands
+func (s ModuleSource) RepositoryURL() string {
+	if s.Kind != ModuleSourceGit || IsGitURL(s.Address) {
+		return s.Address
+	}
+	return "https://" + s.Address
+}
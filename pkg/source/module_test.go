@@ -0,0 +1,89 @@
+package source
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestDetectRefType(t *testing.T) {
+	tests := map[string]RefType{
+		"":             RefTypeBranch,
+		"main":         RefTypeBranch,
+		"feature/x":    RefTypeBranch,
+		"v1.2.3":       RefTypeTag,
+		"1.2":          RefTypeTag,
+		"vpc-2.0.1":    RefTypeTag,
+		"release-next": RefTypeTag,
+		"a1b2c3d":      RefTypeCommit,
+	}
+
+	for ref, expected := range tests {
+		if got := DetectRefType(ref); got != expected {
+			t.Errorf("DetectRefType(%q) = %s, expected %s", ref, getRefTypeName(got), getRefTypeName(expected))
+		}
+	}
+}
+
+func TestParseModuleSourceCached(t *testing.T) {
+	raw := "git::https://example.com/modules.git//vpc?ref=v1.0.0"
+
+	src := ParseModuleSource(raw)
+	if src.Kind != ModuleSourceGit || src.Address != "https://example.com/modules.git" || src.SubDir != "vpc" || src.Ref != "v1.0.0" {
+		t.Errorf("Unexpected source %+v", src)
+	}
+	if cached, ok := parsedSources.get(raw); !ok || cached != src {
+		t.Errorf("Expected the source to be cached, got %+v", cached)
+	}
+
+	// The cache keeps the most recently used sources only
+	cache := newSourceCache(2)
+	cache.add("a", ModuleSource{Address: "a"})
+	cache.add("b", ModuleSource{Address: "b"})
+	cache.get("a")
+	cache.add("c", ModuleSource{Address: "c"})
+	if _, ok := cache.get("b"); ok {
+		t.Error("Expected the least recently used source to be dropped")
+	}
+	for _, raw := range []string{"a", "c"} {
+		if src, ok := cache.get(raw); !ok || src.Address != raw {
+			t.Errorf("Expected %s to be cached, got %+v", raw, src)
+		}
+	}
+	if n := cache.order.Len(); n != 2 {
+		t.Errorf("Expected 2 cached sources, got %d", n)
+	}
+}
+
+// BenchmarkParseModuleSource parses the sources of a manifest with thousands of module calls to a
+// few hundred modules
+func BenchmarkParseModuleSource(b *testing.B) {
+	sources := make([]string, 5000)
+	for i := range sources {
+		sources[i] = fmt.Sprintf("git::https://github.com/acme/module-%d.git//modules/x?ref=v1.%d.0", i%300, i%7)
+	}
+
+	b.ResetTimer()
+	for range b.N {
+		for _, raw := range sources {
+			src := ParseModuleSource(raw)
+			DetectRefType(src.Ref)
+		}
+	}
+}
+
+// BenchmarkParseModuleSourceUncached parses the same sources without the cache, as the baseline
+// the cache is measured against
+func BenchmarkParseModuleSourceUncached(b *testing.B) {
+	sources := make([]string, 5000)
+	for i := range sources {
+		sources[i] = fmt.Sprintf("git::https://github.com/acme/module-%d.git//modules/x?ref=v1.%d.0", i%300, i%7)
+	}
+
+	b.ResetTimer()
+	for range b.N {
+		for _, raw := range sources {
+			src := parseModuleSource(raw)
+			DetectRefType(src.Ref)
+		}
+	}
+}
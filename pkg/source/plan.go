@@ -0,0 +1,173 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/filesystem"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/logger"
+)
+
+// Plan describes what a run would fetch and parse
+type Plan struct {
+	// Source is "local", "git" or "bundle"
+	Source   string `json:"source"`
+	Location string `json:"location"`
+	Ref      string `json:"ref,omitempty"`
+	RefType  string `json:"ref_type,omitempty"`
+	// Commit is the commit checked out for git sources
+	Commit      string              `json:"commit,omitempty"`
+	RootPath    string              `json:"root_path"`
+	Directories []*PlannedDirectory `json:"directories"`
+}
+
+type PlannedDirectory struct {
+	Path string `json:"path"`
+	// Files are the configuration files that would be parsed
+	Files []string `json:"files"`
+	// Skipped are the other files of the directory
+	Skipped []string `json:"skipped,omitempty"`
+}
+
+// DryRun fetches src like Fetch and describes the directories and files a run would parse without
+// parsing them. With recursive, every configuration directory under the root that pruning (which
+// may be nil) keeps is listed, as in PrunedConfigDirs.
+func DryRun(ctx context.Context, src Source, recursive bool, pruning *Pruning) (*Plan, error) {
+	fs, rootPath, err := Fetch(ctx, src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch source: %w", err)
+	}
+	defer src.Cleanup()
+
+	plan := &Plan{RootPath: rootPath, Directories: []*PlannedDirectory{}}
+	switch s := src.(type) {
+	case *GitSource:
+		plan.Source = "git"
+		plan.Location = s.URL
+		plan.Ref = s.Config.Ref
+		plan.RefType = getRefTypeName(DetectRefType(s.Config.Ref))
+		plan.Commit = s.Commit
+	case *LocalSource:
+		plan.Source = "local"
+		plan.Location = s.Path
+	case *BundleSource:
+		plan.Source = "bundle"
+		plan.Location = s.Path
+		plan.Ref = s.manifest.Ref
+		plan.Commit = s.manifest.Commit
+	}
+
+	dirs := []string{rootPath}
+	if recursive {
+		if dirs, err = PrunedConfigDirs(fs, rootPath, pruning); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, dir := range dirs {
+		entries, err := fs.ReadDir(dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read directory %s: %w", dir, err)
+		}
+
+		planned := &PlannedDirectory{Path: dir, Files: []string{}}
+		for _, entry := range entries {
+			switch {
+			case entry.IsDir():
+				continue
+			case IsConfigFile(entry.Name()), entry.Name() == ".terraform.lock.hcl":
+				planned.Files = append(planned.Files, entry.Name())
+			default:
+				planned.Skipped = append(planned.Skipped, entry.Name())
+			}
+		}
+		plan.Directories = append(plan.Directories, planned)
+	}
+
+	return plan, nil
+}
+
+// ConfigDirs returns every directory under root, root included, that contains configuration files.
+// Hidden directories such as .git and .terraform are skipped.
+func ConfigDirs(fs filesystem.FileReader, root string) ([]string, error) {
+	return PrunedConfigDirs(fs, root, nil)
+}
+
+// Pruning limits how far a walk over directories or module calls goes, to explore large trees
+// incrementally
+type Pruning struct {
+	// MaxDepth is the number of levels below the root to visit; 0 means no limit
+	MaxDepth int
+	// Paths are path.Match globs of slash-separated paths relative to the root, e.g. legacy/* or
+	// examples; a glob without a slash matches the last element at any depth. Matched
+	// directories are skipped with everything under them.
+	Paths []string
+}
+
+// Prunes reports whether the path rel, depth levels below the root, is left out
+func (p *Pruning) Prunes(rel string, depth int) bool {
+	if p == nil || rel == "." {
+		return false
+	}
+	if p.MaxDepth > 0 && depth > p.MaxDepth {
+		return true
+	}
+	for _, pattern := range p.Paths {
+		pattern = strings.Trim(pattern, "/")
+		name := rel
+		if !strings.Contains(pattern, "/") {
+			name = path.Base(rel)
+		}
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// PrunedConfigDirs returns the directories of ConfigDirs that pruning (which may be nil) keeps
+func PrunedConfigDirs(fs filesystem.FileReader, root string, pruning *Pruning) ([]string, error) {
+	dirs := []string{}
+
+	var walk func(dir string, depth int) error
+	walk = func(dir string, depth int) error {
+		entries, err := fs.ReadDir(dir)
+		if err != nil {
+			return fmt.Errorf("failed to read directory %s: %w", dir, err)
+		}
+
+		hasConfig := false
+		subDirs := []string{}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				if !strings.HasPrefix(entry.Name(), ".") {
+					subDirs = append(subDirs, filepath.Join(dir, entry.Name()))
+				}
+			} else if IsConfigFile(entry.Name()) {
+				hasConfig = true
+			}
+		}
+		if hasConfig {
+			dirs = append(dirs, dir)
+		}
+
+		for _, subDir := range subDirs {
+			if rel, err := filepath.Rel(root, subDir); err == nil && pruning.Prunes(filepath.ToSlash(rel), depth+1) {
+				logger.DebugKV("Pruning directory", "directory", subDir)
+				continue
+			}
+			if err := walk(subDir, depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := walk(root, 0); err != nil {
+		return nil, err
+	}
+	return dirs, nil
+}
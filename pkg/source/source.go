@@ -1,6 +1,16 @@
+// Package source resolves where a configuration comes from (a local directory, a git
+// repository or a bundle) and exposes it as a filesystem.FileReader.
 package source
 
-import "github.com/Yunsang-Jeong/terraform-config-parser/pkg/filesystem"
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/events"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/filesystem"
+)
 
 // Source represents different sources of Terraform configurations
 type Source interface {
@@ -10,10 +20,125 @@ type Source interface {
 	Cleanup() error
 }
 
+// ContextFetcher is implemented by sources whose fetch can be cancelled, such as git clones
+type ContextFetcher interface {
+	FetchContext(ctx context.Context) (filesystem.FileReader, string, error)
+}
+
+// Fetch fetches src, stopping with the cause of the cancellation of ctx when src is a
+// ContextFetcher. Other sources are fetched unless ctx is already cancelled. The fetch is reported
+// to the Reporter of the source's config, if any.
+func Fetch(ctx context.Context, src Source) (filesystem.FileReader, string, error) {
+	event, reporter := fetchEvent(src)
+	event.Kind = events.FetchStarted
+	reporter.Report(event)
+
+	fs, rootPath, err := fetch(ctx, src)
+
+	done, _ := fetchEvent(src)
+	done.Kind = events.FetchDone
+	if err != nil {
+		done.Error = err.Error()
+	}
+	reporter.Report(done)
+	return fs, rootPath, err
+}
+
+func fetch(ctx context.Context, src Source) (filesystem.FileReader, string, error) {
+	if f, ok := src.(ContextFetcher); ok {
+		return f.FetchContext(ctx)
+	}
+	if err := context.Cause(ctx); err != nil {
+		return nil, "", err
+	}
+	return src.Fetch()
+}
+
+// fetchEvent describes src for fetch events, with the commit once it is fetched
+func fetchEvent(src Source) (events.Event, events.Reporter) {
+	event := events.Event{Source: Kind(src)}
+	switch s := src.(type) {
+	case *GitSource:
+		event.Location, event.Ref, event.Commit = s.URL, s.Config.Ref, s.Commit
+		return event, s.Config.Reporter
+	case *LocalSource:
+		event.Location = s.Path
+		return event, s.Config.Reporter
+	case *BundleSource:
+		event.Location = s.Path
+		if s.manifest != nil {
+			event.Ref, event.Commit = s.manifest.Ref, s.manifest.Commit
+		}
+		return event, s.Config.Reporter
+	}
+	return event, nil
+}
+
 // SourceConfig holds common configuration for all sources
 type SourceConfig struct {
 	// Ref specifies the git reference to use (branch, tag, or commit hash)
 	Ref string
 	// Subdirectory within the source
 	SubDir string
+	// Limiter caps and paces remote fetches per host; nil fetches without limits
+	Limiter *Limiter
+	// Reporter receives the fetch events of Fetch; nil reports nothing
+	Reporter events.Reporter
+}
+
+// New returns a GitSource when target looks like a git URL, a BundleSource for bundle files
+// and a LocalSource otherwise
+func New(target string, config SourceConfig) Source {
+	if IsGitURL(target) {
+		return NewGitSource(target, config)
+	}
+	if IsBundle(target) {
+		return NewBundleSource(target, config)
+	}
+	return NewLocalSource(target, config)
+}
+
+// Kind names the kind of src: local, git or bundle
+func Kind(src Source) string {
+	switch src.(type) {
+	case *GitSource:
+		return "git"
+	case *BundleSource:
+		return "bundle"
+	case *LocalSource:
+		return "local"
+	}
+	return ""
+}
+
+// IsConfigFile reports whether name is a Terraform configuration file, in the native syntax (.tf)
+// or the JSON syntax (.tf.json)
+func IsConfigFile(name string) bool {
+	return filepath.Ext(name) == ".tf" || strings.HasSuffix(name, ".tf.json")
+}
+
+// IsGitURL reports whether target is a remote git repository URL
+func IsGitURL(target string) bool {
+	for _, prefix := range []string{"https://", "http://", "ssh://", "git://", "git@"} {
+		if strings.HasPrefix(target, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// CacheDirEnv overrides the directory of CacheDir
+const CacheDirEnv = "TFPARSER_CACHE_DIR"
+
+// CacheDir returns the directory where data is kept between runs: $TFPARSER_CACHE_DIR, or
+// terraform-config-parser in the user cache directory (e.g. ~/.cache on Linux)
+func CacheDir() (string, error) {
+	if dir := os.Getenv(CacheDirEnv); dir != "" {
+		return dir, nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "terraform-config-parser"), nil
 }
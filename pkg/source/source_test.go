@@ -0,0 +1,138 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/events"
+)
+
+func TestFetchEvents(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "main.tf"), []byte(`variable "name" {}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var reported []events.Event
+	reporter := events.Reporter(func(e events.Event) { reported = append(reported, e) })
+
+	if _, _, err := Fetch(context.Background(), NewLocalSource(dir, SourceConfig{Reporter: reporter})); err != nil {
+		t.Fatalf("Failed to fetch: %v", err)
+	}
+	if len(reported) != 2 || reported[0].Kind != events.FetchStarted || reported[1].Kind != events.FetchDone {
+		t.Fatalf("Expected fetch started and done, got %+v", reported)
+	}
+	if reported[1].Source != "local" || reported[1].Location != dir || reported[1].Error != "" {
+		t.Errorf("Expected the local source, got %+v", reported[1])
+	}
+
+	reported = nil
+	if _, _, err := Fetch(context.Background(), NewLocalSource(filepath.Join(dir, "missing"), SourceConfig{Reporter: reporter})); err == nil {
+		t.Fatal("Expected a missing directory to fail")
+	}
+	if len(reported) != 2 || reported[1].Error == "" {
+		t.Errorf("Expected the error of the failed fetch, got %+v", reported)
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"main.tf":                         `variable "name" {}`,
+		"README.md":                       "# Example",
+		"modules/vpc/main.tf":             `variable "cidr" {}`,
+		"examples/basic/main.tf":          `module "vpc" { source = "../../modules/vpc" }`,
+		"modules/vpc/.terraform.lock.hcl": "# lock",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		recursive bool
+		pruning   *Pruning
+		expected  map[string][]string
+	}{
+		{name: "root only", expected: map[string][]string{".": {"main.tf"}}},
+		{name: "recursive", recursive: true, expected: map[string][]string{".": {"main.tf"}, "examples/basic": {"main.tf"}, "modules/vpc": {".terraform.lock.hcl", "main.tf"}}},
+		{name: "pruned", recursive: true, pruning: &Pruning{Paths: []string{"examples"}}, expected: map[string][]string{".": {"main.tf"}, "modules/vpc": {".terraform.lock.hcl", "main.tf"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var reported []events.Event
+			reporter := events.Reporter(func(e events.Event) { reported = append(reported, e) })
+
+			plan, err := DryRun(context.Background(), NewLocalSource(dir, SourceConfig{Reporter: reporter}), tt.recursive, tt.pruning)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if plan.Source != "local" || plan.Location != dir {
+				t.Errorf("Expected the local source, got %s %s", plan.Source, plan.Location)
+			}
+			if len(reported) != 2 {
+				t.Errorf("Expected the fetch to be reported, got %+v", reported)
+			}
+
+			got := map[string][]string{}
+			for _, planned := range plan.Directories {
+				rel, _ := filepath.Rel(plan.RootPath, planned.Path)
+				got[filepath.ToSlash(rel)] = planned.Files
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected directories %v, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := DryRun(ctx, NewLocalSource(dir, SourceConfig{}), true, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected a cancelled dry run to fail with context.Canceled, got %v", err)
+	}
+}
+
+func TestGitAuthentication(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "github")
+	t.Setenv("GITLAB_TOKEN", "gitlab")
+	t.Setenv("GIT_TOKEN", "generic")
+	defer SetTokenHosts(nil)
+	tokenHosts = nil
+
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		{url: "https://github.com/owner/repo.git", expected: "github"},
+		{url: "git@github.com:owner/repo.git", expected: "github"},
+		{url: "https://gitlab.com/group/repo.git", expected: "gitlab"},
+		// Look-alike hosts only get the generic token
+		{url: "https://github.com.evil.example/owner/repo.git", expected: "generic"},
+		{url: "https://gitlab.evil.example/group/repo.git", expected: "generic"},
+	}
+	for _, tt := range tests {
+		auth := NewGitSource(tt.url, SourceConfig{}).getAuthentication()
+		if auth == nil || auth.Password != tt.expected {
+			t.Errorf("Expected token %q for %s, got %+v", tt.expected, tt.url, auth)
+		}
+	}
+
+	// Once restricted, GIT_TOKEN only goes to the listed hosts
+	SetTokenHosts([]string{"Git.Example.com"})
+	if auth := NewGitSource("https://git.example.com/repo.git", SourceConfig{}).getAuthentication(); auth == nil || auth.Password != "generic" {
+		t.Errorf("Expected GIT_TOKEN for an allowed host, got %+v", auth)
+	}
+	if auth := NewGitSource("https://evil.example/repo.git", SourceConfig{}).getAuthentication(); auth != nil {
+		t.Errorf("Expected no credentials for another host, got %+v", auth)
+	}
+}
@@ -0,0 +1,159 @@
+// Package telemetry sends anonymous usage metrics of commands to a collector chosen by the
+// user. It is disabled unless the user opts in, and events carry no names, paths or values of
+// the configurations read, only counts rounded to buckets.
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// Error classes
+const (
+	ErrorNone    = ""
+	ErrorUsage   = "usage"
+	ErrorConfig  = "config"
+	ErrorFetch   = "fetch"
+	ErrorNetwork = "network"
+	ErrorParse   = "parse"
+	ErrorIO      = "io"
+	ErrorOther   = "other"
+)
+
+// Event describes a single command run
+type Event struct {
+	Command    string `json:"command"`
+	Version    string `json:"version"`
+	Platform   string `json:"platform"`
+	DurationMS int64  `json:"duration_ms"`
+	// Source is local, git or bundle, for commands reading a workspace
+	Source string `json:"source,omitempty"`
+	// Files and Dirs are the buckets of the number of configuration files and directories
+	Files string `json:"files,omitempty"`
+	Dirs  string `json:"dirs,omitempty"`
+	// Error is the class of the error the command failed with, e.g. fetch or parse
+	Error    string `json:"error,omitempty"`
+	ExitCode int    `json:"exit_code"`
+}
+
+// Bucket rounds a count to its order of magnitude, e.g. 42 to "10-99"
+func Bucket(n int) string {
+	switch {
+	case n <= 0:
+		return "0"
+	case n < 10:
+		return "1-9"
+	case n < 100:
+		return "10-99"
+	case n < 1000:
+		return "100-999"
+	}
+	return "1000+"
+}
+
+// Classify returns the class of err from its type or, for errors reported only as text, from
+// the wording of the messages of this tool
+func Classify(err error) string {
+	if err == nil {
+		return ErrorNone
+	}
+
+	var netErr net.Error
+	var pathErr *fs.PathError
+	switch {
+	case errors.As(err, &netErr):
+		return ErrorNetwork
+	case errors.As(err, &pathErr):
+		return ErrorIO
+	}
+
+	message := strings.ToLower(err.Error())
+	for _, class := range []struct {
+		name     string
+		keywords []string
+	}{
+		{ErrorConfig, []string{"config file", "naming profile", "naming rule"}},
+		{ErrorFetch, []string{"failed to clone", "failed to fetch", "authentication", "repository not found"}},
+		{ErrorNetwork, []string{"dial tcp", "no such host", "timeout", "connection refused"}},
+		{ErrorParse, []string{"failed to parse", "parse error", "diagnostics"}},
+		{ErrorIO, []string{"no such file", "permission denied", "is a directory", "not a directory"}},
+		{ErrorUsage, []string{"unknown flag", "unknown command", "accepts", "requires", "invalid argument", "must be"}},
+	} {
+		for _, keyword := range class.keywords {
+			if strings.Contains(message, keyword) {
+				return class.name
+			}
+		}
+	}
+	return ErrorOther
+}
+
+// Enabled reports whether events should be sent: the user opted in and set an endpoint, and
+// DO_NOT_TRACK is not set
+func Enabled(optedIn bool, endpoint string) bool {
+	if !optedIn || endpoint == "" {
+		return false
+	}
+	if value := os.Getenv("DO_NOT_TRACK"); value != "" && value != "0" {
+		return false
+	}
+	return true
+}
+
+// ValidateEndpoint checks that endpoint is an http or https URL
+func ValidateEndpoint(endpoint string) error {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return fmt.Errorf("invalid telemetry endpoint: %w", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid telemetry endpoint %q: expected an http or https URL", endpoint)
+	}
+	return nil
+}
+
+// Client sends events to an endpoint
+type Client struct {
+	Endpoint string
+	HTTP     *http.Client
+}
+
+// NewClient returns a client giving up after timeout, so that an unreachable collector delays
+// commands by at most that long
+func NewClient(endpoint string, timeout time.Duration) *Client {
+	return &Client{Endpoint: endpoint, HTTP: &http.Client{Timeout: timeout}}
+}
+
+// Send posts event as JSON
+func (c *Client) Send(ctx context.Context, event *Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send telemetry: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("failed to send telemetry: %s answered HTTP %d", c.Endpoint, resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestBucket(t *testing.T) {
+	for n, expected := range map[int]string{0: "0", 1: "1-9", 9: "1-9", 10: "10-99", 999: "100-999", 1000: "1000+", 25000: "1000+"} {
+		if got := Bucket(n); got != expected {
+			t.Errorf("Bucket(%d): expected %s, got %s", n, expected, got)
+		}
+	}
+}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected string
+	}{
+		{nil, ErrorNone},
+		{&fs.PathError{Op: "open", Path: "main.tf", Err: fs.ErrPermission}, ErrorIO},
+		{fmt.Errorf("failed to fetch source: %w", errors.New("failed to clone repository")), ErrorFetch},
+		{errors.New("failed to parse config file .tfparser.yaml: yaml: line 2"), ErrorConfig},
+		{errors.New("main.tf:3,1-2: Argument or block definition required; failed to parse"), ErrorParse},
+		{errors.New(`unknown flag: --fromm`), ErrorUsage},
+		{errors.New("something unexpected"), ErrorOther},
+	}
+
+	for _, tt := range tests {
+		if got := Classify(tt.err); got != tt.expected {
+			t.Errorf("Classify(%v): expected %q, got %q", tt.err, tt.expected, got)
+		}
+	}
+}
+
+func TestEnabled(t *testing.T) {
+	t.Setenv("DO_NOT_TRACK", "")
+
+	if Enabled(false, "https://metrics.example.com") {
+		t.Error("Expected telemetry to be disabled without opt-in")
+	}
+	if Enabled(true, "") {
+		t.Error("Expected telemetry to be disabled without endpoint")
+	}
+	if !Enabled(true, "https://metrics.example.com") {
+		t.Error("Expected telemetry to be enabled with opt-in and endpoint")
+	}
+
+	t.Setenv("DO_NOT_TRACK", "1")
+	if Enabled(true, "https://metrics.example.com") {
+		t.Error("Expected DO_NOT_TRACK to disable telemetry")
+	}
+}
+
+func TestValidateEndpoint(t *testing.T) {
+	for endpoint, valid := range map[string]bool{
+		"https://metrics.example.com/tfparser": true,
+		"http://localhost:8080":                true,
+		"metrics.example.com":                  false,
+		"ftp://metrics.example.com":            false,
+	} {
+		if err := ValidateEndpoint(endpoint); (err == nil) != valid {
+			t.Errorf("ValidateEndpoint(%s): expected valid %v, got %v", endpoint, valid, err)
+		}
+	}
+}
+
+func TestSend(t *testing.T) {
+	received := make(chan *Event, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		event := &Event{}
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(event); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		received <- event
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	event := &Event{Command: "lint", Version: "v1.2.3", Platform: "linux/amd64", DurationMS: 120, Source: "git", Files: "10-99", Dirs: "1-9", Error: ErrorParse, ExitCode: 1}
+	if err := NewClient(server.URL, time.Second).Send(context.Background(), event); err != nil {
+		t.Fatalf("Failed to send: %v", err)
+	}
+	if got := <-received; *got != *event {
+		t.Errorf("Expected %+v, got %+v", event, got)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+	if err := NewClient(failing.URL, time.Second).Send(context.Background(), event); err == nil {
+		t.Error("Expected an error status to fail")
+	}
+}
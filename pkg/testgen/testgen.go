@@ -0,0 +1,139 @@
+package testgen
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/ext/typeexpr"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Options controls the generated test file
+type Options struct {
+	// MockProviders adds a mock_provider block for every required provider
+	// and an apply run asserting that outputs are set
+	MockProviders bool
+}
+
+// Generate scaffolds a .tftest.hcl file for the module described by tfconfig.
+// Required variables are filled with placeholders derived from their types.
+func Generate(tfconfig *parser.TerraformConfig, opts Options) []byte {
+	file := hclwrite.NewEmptyFile()
+	body := file.Body()
+
+	if opts.MockProviders {
+		for _, name := range requiredProviderNames(tfconfig) {
+			body.AppendNewBlock("mock_provider", []string{name})
+			body.AppendNewline()
+		}
+	}
+
+	variables := body.AppendNewBlock("variables", nil).Body()
+	for _, variable := range tfconfig.Variables {
+		if !variable.Required {
+			continue
+		}
+		variables.SetAttributeValue(variable.Name, placeholder(variable.Type))
+	}
+	body.AppendNewline()
+
+	plan := body.AppendNewBlock("run", []string{"plan"}).Body()
+	plan.SetAttributeTraversal("command", hcl.Traversal{hcl.TraverseRoot{Name: "plan"}})
+
+	if opts.MockProviders && len(tfconfig.Outputs) > 0 {
+		body.AppendNewline()
+		apply := body.AppendNewBlock("run", []string{"apply"}).Body()
+		apply.SetAttributeTraversal("command", hcl.Traversal{hcl.TraverseRoot{Name: "apply"}})
+
+		for _, output := range tfconfig.Outputs {
+			apply.AppendNewline()
+			assert := apply.AppendNewBlock("assert", nil).Body()
+			assert.SetAttributeRaw("condition", notNullTokens(hcl.Traversal{
+				hcl.TraverseRoot{Name: "output"},
+				hcl.TraverseAttr{Name: output.Name},
+			}))
+			assert.SetAttributeValue("error_message", cty.StringVal(fmt.Sprintf("Output %s must be set", output.Name)))
+		}
+	}
+
+	return hclwrite.Format(file.Bytes())
+}
+
+// notNullTokens renders "<traversal> != null"
+func notNullTokens(traversal hcl.Traversal) hclwrite.Tokens {
+	tokens := hclwrite.TokensForTraversal(traversal)
+	tokens = append(tokens,
+		&hclwrite.Token{Type: hclsyntax.TokenNotEqual, Bytes: []byte("!=")},
+		&hclwrite.Token{Type: hclsyntax.TokenIdent, Bytes: []byte("null")},
+	)
+	return tokens
+}
+
+func requiredProviderNames(tfconfig *parser.TerraformConfig) []string {
+	names := map[string]bool{}
+	for _, terraform := range tfconfig.Terraform {
+		for name := range terraform.RequiredProviders {
+			names[name] = true
+		}
+	}
+	return slices.Sorted(maps.Keys(names))
+}
+
+// placeholder returns a value of the variable's type constraint that makes the module plannable
+func placeholder(typeExpr string) cty.Value {
+	if strings.TrimSpace(typeExpr) == "" {
+		return zeroValue(cty.DynamicPseudoType)
+	}
+	expr, diags := hclsyntax.ParseExpression([]byte(typeExpr), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return zeroValue(cty.DynamicPseudoType)
+	}
+	ty, _, diags := typeexpr.TypeConstraintWithDefaults(expr)
+	if diags.HasErrors() {
+		return zeroValue(cty.DynamicPseudoType)
+	}
+	return zeroValue(ty)
+}
+
+// zeroValue returns a non-null value of ty: empty collections, objects with every required
+// attribute set and tuples with every element set. Optional attributes are left to their defaults,
+// and any is a string.
+func zeroValue(ty cty.Type) cty.Value {
+	switch {
+	case ty == cty.String, ty == cty.DynamicPseudoType:
+		return cty.StringVal("example")
+	case ty == cty.Number:
+		return cty.NumberIntVal(0)
+	case ty == cty.Bool:
+		return cty.False
+	case ty.IsListType():
+		return cty.ListValEmpty(ty.ElementType())
+	case ty.IsSetType():
+		return cty.SetValEmpty(ty.ElementType())
+	case ty.IsMapType():
+		return cty.MapValEmpty(ty.ElementType())
+	case ty.IsObjectType():
+		attrs := map[string]cty.Value{}
+		for name, attrType := range ty.AttributeTypes() {
+			if !ty.AttributeOptional(name) {
+				attrs[name] = zeroValue(attrType)
+			}
+		}
+		return cty.ObjectVal(attrs)
+	case ty.IsTupleType():
+		elems := make([]cty.Value, len(ty.TupleElementTypes()))
+		for i, elemType := range ty.TupleElementTypes() {
+			elems[i] = zeroValue(elemType)
+		}
+		return cty.TupleVal(elems)
+	default:
+		return cty.StringVal("example")
+	}
+}
@@ -0,0 +1,68 @@
+package testgen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser/schema"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+func TestPlaceholder(t *testing.T) {
+	tests := []struct {
+		name     string
+		typeExpr string
+		expected string
+	}{
+		{name: "No type", typeExpr: "", expected: `"example"`},
+		{name: "Any", typeExpr: "any", expected: `"example"`},
+		{name: "String", typeExpr: "string", expected: `"example"`},
+		{name: "Number", typeExpr: "number", expected: "0"},
+		{name: "Bool", typeExpr: "bool", expected: "false"},
+		{name: "List", typeExpr: "list(string)", expected: "[]"},
+		{name: "Set", typeExpr: "set(number)", expected: "[]"},
+		{name: "Map", typeExpr: "map(string)", expected: "{}"},
+		{name: "Object", typeExpr: "object({ name = string, port = number })", expected: `{ name = "example" port = 0 }`},
+		{name: "Object with optional attributes", typeExpr: "object({ name = string, port = optional(number, 80) })", expected: `{ name = "example" }`},
+		{name: "Nested object", typeExpr: "object({ tags = map(string), rule = object({ enabled = bool }) })", expected: `{ rule = { enabled = false } tags = {} }`},
+		{name: "Tuple", typeExpr: "tuple([string, number, list(bool)])", expected: `["example", 0, []]`},
+		{name: "Invalid type", typeExpr: "list(", expected: `"example"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value := placeholder(tt.typeExpr)
+			if value.IsNull() {
+				t.Fatalf("Expected a non-null placeholder for %q", tt.typeExpr)
+			}
+			got := strings.Join(strings.Fields(string(hclwrite.TokensForValue(value).Bytes())), " ")
+			if got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	tfconfig := &parser.TerraformConfig{
+		Variables: []*schema.Variable{
+			{Name: "listener", Type: "object({ port = number, protocol = optional(string) })", Required: true},
+			{Name: "region", Default: "us-east-1"},
+		},
+	}
+
+	got := string(Generate(tfconfig, Options{}))
+	if !strings.Contains(got, "listener = {\n    port = 0\n  }") {
+		t.Errorf("Expected an object placeholder for listener:\n%s", got)
+	}
+	if strings.Contains(got, "region") {
+		t.Errorf("Expected optional variables to be left out:\n%s", got)
+	}
+	if _, diags := hclsyntax.ParseConfig([]byte(got), "main.tftest.hcl", hcl.InitialPos); diags.HasErrors() {
+		t.Errorf("Generated file does not parse: %s", diags.Error())
+	}
+}
@@ -0,0 +1,188 @@
+// Package tfparser is the entry point of the library for other Go projects: it parses the
+// Terraform configuration of a directory into a TerraformConfig without going through the CLI.
+//
+//	config, err := tfparser.ParseDir(tfparser.OSFileReader(), "./infra",
+//		tfparser.WithMode(tfparser.Detail),
+//		tfparser.WithLenient(true),
+//	)
+//
+// New behavior comes as new options, and options keep their defaults. The module has no tagged
+// release yet, so this is not a compatibility promise.
+package tfparser
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/events"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/filesystem"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+
+	"github.com/spf13/afero"
+)
+
+type (
+	// TerraformConfig is the parsed configuration of a directory
+	TerraformConfig = parser.TerraformConfig
+	// Diagnostic is a problem found while parsing, see WithBlockValidation and WithLenient
+	Diagnostic = parser.Diagnostic
+	// FileReader is the filesystem configurations are read from
+	FileReader = filesystem.FileReader
+	// Mode selects how much of each block is kept
+	Mode = parser.Mode
+	// MergePolicy decides how blocks declared in several directories are combined, see WithRecursive
+	MergePolicy = parser.MergePolicy
+	// Event is a step of parsing, see WithReporter
+	Event = events.Event
+	// Reporter receives events while parsing
+	Reporter = events.Reporter
+)
+
+const (
+	// Simple keeps the interface of a module: variables, outputs and terraform settings
+	Simple = parser.Simple
+	// Detail also keeps module calls, resources, data sources, providers, imports and locals
+	Detail = parser.Detail
+)
+
+const (
+	// MergeError fails when two directories declare a block with the same address
+	MergeError = parser.MergeError
+	// MergePreferLeft keeps the block of the directory parsed first
+	MergePreferLeft = parser.MergePreferLeft
+	// MergeAppend keeps both blocks
+	MergeAppend = parser.MergeAppend
+)
+
+// Option configures ParseDir and ParseDirs
+type Option func(*options)
+
+type options struct {
+	mode      Mode
+	recursive bool
+	policy    MergePolicy
+	validate  bool
+	lenient   bool
+	ast       bool
+	kinds     bool
+	positions bool
+	flatten   bool
+	ctx       context.Context
+	reporter  events.Reporter
+}
+
+// WithMode selects how much of each block is kept; Simple by default
+func WithMode(mode Mode) Option {
+	return func(o *options) { o.mode = mode }
+}
+
+// WithRecursive makes ParseDir parse every directory under the path, hidden directories such as
+// .terraform excepted, and merge their configurations in path order with policy
+func WithRecursive(policy MergePolicy) Option {
+	return func(o *options) { o.recursive, o.policy = true, policy }
+}
+
+// WithBlockValidation reports unknown arguments, missing required arguments and misplaced blocks
+// as TerraformConfig.Diagnostics instead of failing
+func WithBlockValidation(enabled bool) Option {
+	return func(o *options) { o.validate = enabled }
+}
+
+// WithLenient reports syntax errors and blocks that fail to parse as TerraformConfig.Diagnostics
+// and keeps the blocks that can be read, instead of failing
+func WithLenient(enabled bool) Option {
+	return func(o *options) { o.lenient = enabled }
+}
+
+// WithAST attaches the expression AST of every attribute to the blocks
+func WithAST(enabled bool) Option {
+	return func(o *options) { o.ast = enabled }
+}
+
+// WithKinds tags the attributes of every block with the kind of their value
+func WithKinds(enabled bool) Option {
+	return func(o *options) { o.kinds = enabled }
+}
+
+// WithPositions records the file and lines where every block is declared
+func WithPositions(enabled bool) Option {
+	return func(o *options) { o.positions = enabled }
+}
+
+// WithFlatten adds the flattened form of nested values, keyed by dotted paths
+func WithFlatten(enabled bool) Option {
+	return func(o *options) { o.flatten = enabled }
+}
+
+// WithContext stops parsing with the cause of the cancellation of ctx, between files and
+// directories
+func WithContext(ctx context.Context) Option {
+	return func(o *options) { o.ctx = ctx }
+}
+
+// WithReporter reports every file parsed, the diagnostics of every directory and the end of
+// parsing each directory to reporter, e.g. events.Channel to render progress in a UI
+func WithReporter(reporter Reporter) Option {
+	return func(o *options) { o.reporter = reporter }
+}
+
+// OSFileReader reads from the operating system's filesystem
+func OSFileReader() FileReader {
+	return filesystem.NewAferoAdapter(afero.NewOsFs())
+}
+
+func newParser(fs FileReader, opts []Option) (*parser.Parser, *options) {
+	o := &options{mode: Simple}
+	for _, opt := range opts {
+		opt(o)
+	}
+	p := parser.NewParser(fs, o.mode).
+		WithAST(o.ast).
+		WithKinds(o.kinds).
+		WithPositions(o.positions).
+		WithFlatten(o.flatten).
+		WithValidateBlocks(o.validate).
+		WithLenient(o.lenient).
+		WithContext(o.ctx).
+		WithReporter(o.reporter)
+	return p, o
+}
+
+// ParseDir parses the configuration files of the directory at path
+func ParseDir(fs FileReader, path string, opts ...Option) (*TerraformConfig, error) {
+	p, o := newParser(fs, opts)
+	if !o.recursive {
+		return p.ParseTerraformWorkspace(path)
+	}
+
+	workspaces, err := p.ParseTerraformWorkspaces(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(workspaces) == 0 {
+		return nil, fmt.Errorf("no Terraform configuration under %s", path)
+	}
+
+	var merged *TerraformConfig
+	for _, rel := range slices.Sorted(maps.Keys(workspaces)) {
+		if merged == nil {
+			merged = workspaces[rel]
+			continue
+		}
+		if err := merged.Merge(workspaces[rel], o.policy); err != nil {
+			return nil, fmt.Errorf("failed to merge %s: %w", filepath.Join(path, rel), err)
+		}
+	}
+	return merged, nil
+}
+
+// ParseDirs parses every directory under root, root included, that contains configuration files.
+// The result is keyed by the slash-separated path relative to root, "." for root itself.
+// WithRecursive has no effect.
+func ParseDirs(fs FileReader, root string, opts ...Option) (map[string]*TerraformConfig, error) {
+	p, _ := newParser(fs, opts)
+	return p.ParseTerraformWorkspaces(root)
+}
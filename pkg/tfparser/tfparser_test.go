@@ -0,0 +1,68 @@
+package tfparser
+
+import (
+	"testing"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/filesystem"
+
+	"github.com/spf13/afero"
+)
+
+func TestParseDir(t *testing.T) {
+	memFs := afero.NewMemMapFs()
+	files := map[string]string{
+		"infra/main.tf":         "variable \"region\" {\n  type = string\n}\n\nresource \"aws_vpc\" \"this\" {}\n",
+		"infra/network/main.tf": "variable \"region\" {\n  type = string\n}\n\noutput \"vpc_id\" {\n  value = \"vpc\"\n}\n",
+		"infra/broken/main.tf":  "variable \"name\" {\n",
+	}
+	for path, content := range files {
+		if err := afero.WriteFile(memFs, path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+	fs := filesystem.NewAferoAdapter(memFs)
+
+	config, err := ParseDir(fs, "infra")
+	if err != nil {
+		t.Fatalf("ParseDir failed: %v", err)
+	}
+	if len(config.Variables) != 1 || len(config.Resources) != 0 {
+		t.Errorf("Expected the variable only in Simple mode, got %d variables and %d resources", len(config.Variables), len(config.Resources))
+	}
+
+	config, err = ParseDir(fs, "infra", WithMode(Detail))
+	if err != nil {
+		t.Fatalf("ParseDir failed: %v", err)
+	}
+	if len(config.Resources) != 1 {
+		t.Errorf("Expected the resource in Detail mode, got %d", len(config.Resources))
+	}
+
+	if _, err := ParseDir(fs, "infra", WithRecursive(MergeAppend)); err == nil {
+		t.Error("Expected the broken directory to fail without WithLenient")
+	}
+
+	config, err = ParseDir(fs, "infra", WithRecursive(MergePreferLeft), WithLenient(true))
+	if err != nil {
+		t.Fatalf("Recursive ParseDir failed: %v", err)
+	}
+	// region is declared twice and kept once; name is read from the broken file
+	if len(config.Variables) != 2 || len(config.Outputs) != 1 {
+		t.Errorf("Expected the merged variables and output, got %d variables and %d outputs", len(config.Variables), len(config.Outputs))
+	}
+	if len(config.Diagnostics) == 0 {
+		t.Error("Expected the syntax error of the broken directory as a diagnostic")
+	}
+
+	if _, err := ParseDir(fs, "infra", WithRecursive(MergeError), WithLenient(true)); err == nil {
+		t.Error("Expected MergeError to fail on the variable declared twice")
+	}
+
+	workspaces, err := ParseDirs(fs, "infra", WithLenient(true))
+	if err != nil {
+		t.Fatalf("ParseDirs failed: %v", err)
+	}
+	if len(workspaces) != 3 || workspaces["network"] == nil {
+		t.Errorf("Expected 3 directories keyed by path, got %v", workspaces)
+	}
+}
@@ -0,0 +1,76 @@
+package tfstate
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+)
+
+// CrossReport compares the blocks of a configuration with the resources recorded in state
+type CrossReport struct {
+	// InSync lists configured blocks that have instances in state
+	InSync []string `json:"in_sync"`
+	// ToCreate lists configured resources, data sources and module calls without instances in state
+	ToCreate []string `json:"to_create"`
+	// Orphaned lists state instances whose configuration block no longer exists
+	Orphaned []string `json:"orphaned"`
+}
+
+// CrossReference reports differences between the root module of tfconfig and the state of doc
+func CrossReference(tfconfig *parser.TerraformConfig, doc *Document) *CrossReport {
+	report := &CrossReport{InSync: []string{}, ToCreate: []string{}, Orphaned: []string{}}
+
+	configured := map[string]bool{}
+	for _, resource := range tfconfig.Resources {
+		configured[resource.Address()] = true
+	}
+	for _, data := range tfconfig.DataSources {
+		configured[data.Address()] = true
+	}
+	for _, module := range tfconfig.Modules {
+		configured[module.Address()] = true
+	}
+
+	inState := map[string]bool{}
+	for _, resource := range doc.State().Resources() {
+		address := resource.ConfigAddress()
+		if resource.ModuleAddress != "" {
+			address = moduleCallAddress(resource.ModuleAddress)
+		}
+
+		inState[address] = true
+		if !configured[address] {
+			report.Orphaned = append(report.Orphaned, resource.Address)
+		}
+	}
+
+	for address := range configured {
+		if inState[address] {
+			report.InSync = append(report.InSync, address)
+		} else {
+			report.ToCreate = append(report.ToCreate, address)
+		}
+	}
+
+	sort.Strings(report.InSync)
+	sort.Strings(report.ToCreate)
+	sort.Strings(report.Orphaned)
+
+	return report
+}
+
+// moduleCallAddress returns the root module call of a module instance address,
+// e.g. module.vpc for module.vpc["a"].module.subnets
+func moduleCallAddress(moduleAddress string) string {
+	parts := strings.SplitN(moduleAddress, ".", 3)
+	if len(parts) < 2 {
+		return moduleAddress
+	}
+
+	name := parts[1]
+	if idx := strings.Index(name, "["); idx >= 0 {
+		name = name[:idx]
+	}
+	return parts[0] + "." + name
+}
@@ -0,0 +1,78 @@
+package tfstate
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser/schema"
+)
+
+func TestCrossReference(t *testing.T) {
+	tfconfig := &parser.TerraformConfig{
+		Resources: []*schema.Resource{
+			{Type: "aws_s3_bucket", Name: "logs"},
+			{Type: "aws_s3_bucket", Name: "new"},
+		},
+		DataSources: []*schema.DataSource{
+			{Resource: schema.Resource{Type: "aws_caller_identity", Name: "current"}},
+		},
+		Modules: []*schema.Module{
+			{Name: "vpc", Source: "./modules/vpc"},
+			{Name: "dns", Source: "./modules/dns"},
+		},
+	}
+
+	plan := `{
+  "format_version": "1.2",
+  "prior_state": {
+    "values": {
+      "root_module": {
+        "resources": [
+          {"address": "aws_s3_bucket.logs", "mode": "managed", "type": "aws_s3_bucket", "name": "logs"},
+          {"address": "aws_s3_bucket.old[0]", "mode": "managed", "type": "aws_s3_bucket", "name": "old", "index": 0},
+          {"address": "data.aws_caller_identity.current", "mode": "data", "type": "aws_caller_identity", "name": "current"}
+        ],
+        "child_modules": [
+          {
+            "address": "module.vpc[\"a\"]",
+            "resources": [
+              {"address": "module.vpc[\"a\"].aws_vpc.this", "mode": "managed", "type": "aws_vpc", "name": "this"}
+            ]
+          },
+          {
+            "address": "module.legacy",
+            "resources": [
+              {"address": "module.legacy.aws_vpc.this", "mode": "managed", "type": "aws_vpc", "name": "this"}
+            ]
+          }
+        ]
+      }
+    }
+  }
+}`
+
+	doc := &Document{}
+	if err := json.Unmarshal([]byte(plan), doc); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	report := CrossReference(tfconfig, doc)
+
+	tests := []struct {
+		name     string
+		got      []string
+		expected []string
+	}{
+		{"in_sync", report.InSync, []string{"aws_s3_bucket.logs", "data.aws_caller_identity.current", "module.vpc"}},
+		{"to_create", report.ToCreate, []string{"aws_s3_bucket.new", "module.dns"}},
+		{"orphaned", report.Orphaned, []string{"aws_s3_bucket.old[0]", "module.legacy.aws_vpc.this"}},
+	}
+
+	for _, tt := range tests {
+		if strings.Join(tt.got, ",") != strings.Join(tt.expected, ",") {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, tt.got)
+		}
+	}
+}
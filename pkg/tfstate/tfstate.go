@@ -0,0 +1,106 @@
+package tfstate
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Document is the subset of `terraform show -json` output shared by plan and state files
+type Document struct {
+	FormatVersion string `json:"format_version"`
+	// Values is set for state files
+	Values *Values `json:"values,omitempty"`
+	// PriorState is set for plan files
+	PriorState *struct {
+		Values *Values `json:"values,omitempty"`
+	} `json:"prior_state,omitempty"`
+	// PlannedValues is set for plan files
+	PlannedValues *Values `json:"planned_values,omitempty"`
+}
+
+type Values struct {
+	Outputs    map[string]*Output `json:"outputs,omitempty"`
+	RootModule *Module            `json:"root_module,omitempty"`
+}
+
+type Output struct {
+	Sensitive bool `json:"sensitive"`
+	Value     any  `json:"value"`
+}
+
+type Module struct {
+	Address      string      `json:"address,omitempty"`
+	Resources    []*Resource `json:"resources,omitempty"`
+	ChildModules []*Module   `json:"child_modules,omitempty"`
+}
+
+type Resource struct {
+	Address string         `json:"address"`
+	Mode    string         `json:"mode"`
+	Type    string         `json:"type"`
+	Name    string         `json:"name"`
+	Index   any            `json:"index,omitempty"`
+	Values  map[string]any `json:"values,omitempty"`
+	// ModuleAddress is the address of the module instance containing the resource, empty for the root module
+	ModuleAddress string `json:"-"`
+}
+
+// Load reads a JSON plan or state file produced by `terraform show -json`
+func Load(path string) (*Document, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	doc := &Document{}
+	if err := json.Unmarshal(content, doc); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if doc.FormatVersion == "" {
+		return nil, fmt.Errorf("%s is not a `terraform show -json` document", path)
+	}
+
+	return doc, nil
+}
+
+// State returns the current state values: the prior state of a plan, or the values of a state file
+func (d *Document) State() *Values {
+	if d.PriorState != nil && d.PriorState.Values != nil {
+		return d.PriorState.Values
+	}
+	if d.Values != nil {
+		return d.Values
+	}
+	return &Values{RootModule: &Module{}}
+}
+
+// Resources returns every resource of the module tree, depth first
+func (v *Values) Resources() []*Resource {
+	resources := []*Resource{}
+	if v.RootModule == nil {
+		return resources
+	}
+
+	var walk func(module *Module)
+	walk = func(module *Module) {
+		for _, resource := range module.Resources {
+			resource.ModuleAddress = module.Address
+			resources = append(resources, resource)
+		}
+		for _, child := range module.ChildModules {
+			walk(child)
+		}
+	}
+	walk(v.RootModule)
+
+	return resources
+}
+
+// ConfigAddress returns the address of the resource block the instance belongs to, relative to its module
+func (r *Resource) ConfigAddress() string {
+	if r.Mode == "data" {
+		return "data." + r.Type + "." + r.Name
+	}
+	return r.Type + "." + r.Name
+}
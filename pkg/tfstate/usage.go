@@ -0,0 +1,131 @@
+package tfstate
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+)
+
+type VariableStatus string
+
+const (
+	// VariableUsed influences at least one resource, module call or output persisted in state
+	VariableUsed VariableStatus = "used"
+	// VariableUnpersisted is only referenced by blocks that have nothing in state
+	VariableUnpersisted VariableStatus = "unpersisted"
+	// VariableUnreferenced is never referenced and can be removed
+	VariableUnreferenced VariableStatus = "unreferenced"
+)
+
+type VariableUsage struct {
+	Name   string         `json:"name"`
+	Status VariableStatus `json:"status"`
+	// Influences lists the persisted objects whose configuration depends on the variable
+	Influences []string `json:"influences,omitempty"`
+}
+
+type UsageReport struct {
+	Variables []*VariableUsage `json:"variables"`
+}
+
+// AnalyzeVariableUsage traces every variable through locals into resources, data sources,
+// module calls and outputs, and checks which of those consumers are persisted in state
+func AnalyzeVariableUsage(tfconfig *parser.TerraformConfig, doc *Document) *UsageReport {
+	locals := map[string][]string{}
+	for _, local := range tfconfig.Locals {
+		locals[local.Address()] = local.References
+	}
+
+	consumers := map[string][]string{}
+	for _, resource := range tfconfig.Resources {
+		consumers[resource.Address()] = resource.References
+	}
+	for _, data := range tfconfig.DataSources {
+		consumers[data.Address()] = data.References
+	}
+	for _, module := range tfconfig.Modules {
+		consumers[module.Address()] = module.References
+	}
+	for _, output := range tfconfig.Outputs {
+		consumers[output.Address()] = output.References
+	}
+
+	persisted := persistedAddresses(doc)
+
+	referencedBy := map[string][]string{}
+	referenced := map[string]bool{}
+	for address, refs := range consumers {
+		for _, variable := range resolveVariables(refs, locals) {
+			referenced[variable] = true
+			if persisted[address] {
+				referencedBy[variable] = append(referencedBy[variable], address)
+			}
+		}
+	}
+
+	report := &UsageReport{Variables: []*VariableUsage{}}
+	for _, variable := range tfconfig.Variables {
+		usage := &VariableUsage{Name: variable.Name, Status: VariableUnreferenced}
+
+		switch address := variable.Address(); {
+		case len(referencedBy[address]) > 0:
+			usage.Status = VariableUsed
+			usage.Influences = referencedBy[address]
+			sort.Strings(usage.Influences)
+		case referenced[address]:
+			usage.Status = VariableUnpersisted
+		}
+
+		report.Variables = append(report.Variables, usage)
+	}
+
+	return report
+}
+
+// resolveVariables expands local values transitively and returns the variables behind refs
+func resolveVariables(refs []string, locals map[string][]string) []string {
+	variables := []string{}
+	visited := map[string]bool{}
+
+	var visit func(ref string)
+	visit = func(ref string) {
+		if visited[ref] {
+			return
+		}
+		visited[ref] = true
+
+		switch {
+		case strings.HasPrefix(ref, "var."):
+			variables = append(variables, ref)
+		case strings.HasPrefix(ref, "local."):
+			for _, next := range locals[ref] {
+				visit(next)
+			}
+		}
+	}
+
+	for _, ref := range refs {
+		visit(ref)
+	}
+	return variables
+}
+
+// persistedAddresses returns the root module addresses of everything recorded in state
+func persistedAddresses(doc *Document) map[string]bool {
+	persisted := map[string]bool{}
+	state := doc.State()
+
+	for _, resource := range state.Resources() {
+		if resource.ModuleAddress != "" {
+			persisted[moduleCallAddress(resource.ModuleAddress)] = true
+		} else {
+			persisted[resource.ConfigAddress()] = true
+		}
+	}
+	for name := range state.Outputs {
+		persisted["output."+name] = true
+	}
+
+	return persisted
+}
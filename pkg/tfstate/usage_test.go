@@ -0,0 +1,69 @@
+package tfstate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser/schema"
+)
+
+func TestAnalyzeVariableUsage(t *testing.T) {
+	tfconfig := &parser.TerraformConfig{
+		Variables: []*schema.Variable{
+			{Name: "prefix"},
+			{Name: "cidr"},
+			{Name: "debug"},
+			{Name: "legacy"},
+			{Name: "region"},
+		},
+		Locals: []*schema.Local{
+			{Name: "name", References: []string{"var.prefix"}},
+		},
+		Resources: []*schema.Resource{
+			{Type: "aws_s3_bucket", Name: "logs", References: []string{"local.name"}},
+			{Type: "aws_instance", Name: "debug", References: []string{"var.debug"}},
+		},
+		Modules: []*schema.Module{
+			{Name: "vpc", Source: "./modules/vpc", References: []string{"var.cidr"}},
+		},
+		Outputs: []*schema.Output{
+			{Name: "region", References: []string{"var.region"}},
+		},
+	}
+
+	doc := &Document{
+		FormatVersion: "1.0",
+		Values: &Values{
+			Outputs: map[string]*Output{"region": {Value: "us-east-1"}},
+			RootModule: &Module{
+				Resources: []*Resource{
+					{Address: "aws_s3_bucket.logs", Mode: "managed", Type: "aws_s3_bucket", Name: "logs"},
+				},
+				ChildModules: []*Module{
+					{
+						Address: "module.vpc",
+						Resources: []*Resource{
+							{Address: "module.vpc.aws_vpc.this", Mode: "managed", Type: "aws_vpc", Name: "this"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	expected := map[string]string{
+		"prefix": "used:aws_s3_bucket.logs",
+		"cidr":   "used:module.vpc",
+		"debug":  "unpersisted:",
+		"legacy": "unreferenced:",
+		"region": "used:output.region",
+	}
+
+	for _, usage := range AnalyzeVariableUsage(tfconfig, doc).Variables {
+		got := string(usage.Status) + ":" + strings.Join(usage.Influences, ",")
+		if got != expected[usage.Name] {
+			t.Errorf("Variable %s: expected %s, got %s", usage.Name, expected[usage.Name], got)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+// Package tfvars reads the variable values a workspace assigns in its tfvars files and checks
+// them against the declared variables.
+package tfvars
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/filesystem"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	hcljson "github.com/hashicorp/hcl/v2/json"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// File is a parsed tfvars file
+type File struct {
+	Path        string
+	Assignments []*Assignment
+}
+
+// Assignment is a variable value set by a tfvars file
+type Assignment struct {
+	Name string `json:"name"`
+	File string `json:"file"`
+	Line int    `json:"line"`
+	// Value is the assigned value, or the expression source when it cannot be evaluated
+	Value interface{} `json:"value,omitempty"`
+}
+
+// IsAutoLoaded reports whether Terraform loads the file named name without -var-file:
+// terraform.tfvars, terraform.tfvars.json, *.auto.tfvars and *.auto.tfvars.json
+func IsAutoLoaded(name string) bool {
+	name = strings.TrimSuffix(name, ".json")
+	return name == "terraform.tfvars" || strings.HasSuffix(name, ".auto.tfvars")
+}
+
+// Load parses the tfvars files of dir that Terraform loads automatically, followed by extra
+// files such as those given with -var-file, in the order Terraform applies them: terraform.tfvars,
+// terraform.tfvars.json, then *.auto.tfvars and *.auto.tfvars.json in lexical order.
+func Load(fs filesystem.FileReader, dir string, extra ...string) ([]*File, error) {
+	entries, err := fs.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory %s: %w", dir, err)
+	}
+
+	present := map[string]bool{}
+	auto := []string{}
+	for _, entry := range entries {
+		if entry.IsDir() || !IsAutoLoaded(entry.Name()) {
+			continue
+		}
+		present[entry.Name()] = true
+		if !strings.HasPrefix(entry.Name(), "terraform.tfvars") {
+			auto = append(auto, entry.Name())
+		}
+	}
+	slices.Sort(auto)
+
+	paths := []string{}
+	for _, name := range append([]string{"terraform.tfvars", "terraform.tfvars.json"}, auto...) {
+		if present[name] {
+			paths = append(paths, filepath.Join(dir, name))
+		}
+	}
+	paths = append(paths, extra...)
+
+	files := make([]*File, 0, len(paths))
+	for _, path := range paths {
+		content, err := fs.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		file, err := Parse(path, content)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// Parse reads the assignments of a tfvars file; a .json suffix selects the JSON syntax
+func Parse(path string, content []byte) (*File, error) {
+	var hclFile *hcl.File
+	var diags hcl.Diagnostics
+	if strings.HasSuffix(path, ".json") {
+		hclFile, diags = hcljson.Parse(content, path)
+	} else {
+		hclFile, diags = hclsyntax.ParseConfig(content, path, hcl.InitialPos)
+	}
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, errors.Join(diags.Errs()...))
+	}
+
+	attrs, diags := hclFile.Body.JustAttributes()
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, errors.Join(diags.Errs()...))
+	}
+
+	file := &File{Path: path, Assignments: []*Assignment{}}
+	for name, attr := range attrs {
+		file.Assignments = append(file.Assignments, &Assignment{
+			Name:  name,
+			File:  path,
+			Line:  attr.Range.Start.Line,
+			Value: value(attr.Expr, content),
+		})
+	}
+	slices.SortFunc(file.Assignments, func(a, b *Assignment) int { return a.Line - b.Line })
+	return file, nil
+}
+
+// value evaluates a tfvars expression, which Terraform allows to be a constant only
+func value(expr hcl.Expression, content []byte) interface{} {
+	val, diags := expr.Value(nil)
+	if !diags.HasErrors() && val.IsWhollyKnown() {
+		if encoded, err := ctyjson.Marshal(val, val.Type()); err == nil {
+			var decoded interface{}
+			if json.Unmarshal(encoded, &decoded) == nil {
+				return decoded
+			}
+		}
+	}
+	return strings.TrimSpace(string(expr.Range().SliceBytes(content)))
+}
+
+// Value is the effective value of a variable after every tfvars file is applied
+type Value struct {
+	*Assignment
+	// Overrides are the earlier files whose assignment this one replaces
+	Overrides []string `json:"overrides,omitempty"`
+	// Sensitive values are left out
+	Sensitive bool `json:"sensitive,omitempty"`
+}
+
+// Requirement tells whether a tfvars file sets a required variable
+type Requirement struct {
+	Name      string `json:"name"`
+	Satisfied bool   `json:"satisfied"`
+	// File is the tfvars file the value comes from
+	File string `json:"file,omitempty"`
+}
+
+type Report struct {
+	// Files are the tfvars files in the order they apply; later files win
+	Files  []string `json:"files"`
+	Values []*Value `json:"values"`
+	// Undeclared are assigned variables the configuration does not declare, which Terraform warns about
+	Undeclared []string       `json:"undeclared,omitempty"`
+	Required   []*Requirement `json:"required"`
+	// Missing are the required variables no tfvars file sets; they must come from -var,
+	// TF_VAR_ environment variables or a prompt
+	Missing []string `json:"missing"`
+	// Redundant are the effective assignments of optional variables that set their default
+	// value; removing them changes nothing
+	Redundant []*Assignment `json:"redundant"`
+}
+
+// Analyze associates the assignments of files, in order, with the variables of tfconfig
+func Analyze(tfconfig *parser.TerraformConfig, files []*File) *Report {
+	report := &Report{Files: []string{}, Values: []*Value{}, Required: []*Requirement{}, Missing: []string{}, Redundant: []*Assignment{}}
+
+	effective := map[string]*Value{}
+	for _, file := range files {
+		report.Files = append(report.Files, file.Path)
+		for _, assignment := range file.Assignments {
+			current := &Value{Assignment: assignment}
+			if previous, ok := effective[assignment.Name]; ok {
+				current.Overrides = append(previous.Overrides, previous.File)
+			}
+			effective[assignment.Name] = current
+		}
+	}
+
+	declared := map[string]bool{}
+	for _, variable := range tfconfig.Variables {
+		declared[variable.Name] = true
+		value, set := effective[variable.Name]
+		if set && !variable.Required && sameValue(value.Value, variable.Default) {
+			redundant := *value.Assignment
+			redundant.Value = nil
+			report.Redundant = append(report.Redundant, &redundant)
+		}
+		if set && variable.Sensitive {
+			redacted := *value.Assignment
+			redacted.Value = nil
+			value.Assignment, value.Sensitive = &redacted, true
+		}
+
+		if !variable.Required {
+			continue
+		}
+		requirement := &Requirement{Name: variable.Name, Satisfied: set}
+		if set {
+			requirement.File = value.File
+		} else {
+			report.Missing = append(report.Missing, variable.Name)
+		}
+		report.Required = append(report.Required, requirement)
+	}
+
+	for name, value := range effective {
+		report.Values = append(report.Values, value)
+		if !declared[name] {
+			report.Undeclared = append(report.Undeclared, name)
+		}
+	}
+	slices.SortFunc(report.Values, func(a, b *Value) int { return strings.Compare(a.Name, b.Name) })
+	slices.SortFunc(report.Required, func(a, b *Requirement) int { return strings.Compare(a.Name, b.Name) })
+	slices.SortFunc(report.Redundant, func(a, b *Assignment) int { return strings.Compare(a.Name, b.Name) })
+	slices.Sort(report.Undeclared)
+	slices.Sort(report.Missing)
+	return report
+}
+
+// sameValue reports whether a tfvars value equals a variable default, comparing their JSON forms
+// so that e.g. the number 2 of a default and the float64 2 of a tfvars file are equal. Complex
+// defaults, which the parser keeps as source text, are evaluated first.
+func sameValue(assigned, defaultValue interface{}) bool {
+	if source, ok := defaultValue.(string); ok {
+		if _, isString := assigned.(string); !isString {
+			expr, diags := hclsyntax.ParseExpression([]byte(source), "default", hcl.InitialPos)
+			if diags.HasErrors() {
+				return false
+			}
+			defaultValue = value(expr, []byte(source))
+		}
+	}
+
+	normalize := func(v interface{}) (interface{}, bool) {
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return nil, false
+		}
+		var decoded interface{}
+		return decoded, json.Unmarshal(encoded, &decoded) == nil
+	}
+	a, ok := normalize(assigned)
+	if !ok {
+		return false
+	}
+	b, ok := normalize(defaultValue)
+	return ok && reflect.DeepEqual(a, b)
+}
@@ -0,0 +1,143 @@
+package tfvars
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/filesystem"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+	"github.com/spf13/afero"
+)
+
+func TestAnalyze(t *testing.T) {
+	memFs := afero.NewMemMapFs()
+	files := map[string]string{
+		"main.tf": `variable "region" {}
+
+variable "password" {
+  sensitive = true
+}
+
+variable "tags" {
+  type = map(string)
+}
+
+variable "replicas" {
+  default = 1
+}
+`,
+		"terraform.tfvars": `region   = "us-east-1"
+replicas = 2
+unused   = true
+`,
+		"b.auto.tfvars.json": `{"region": "eu-west-1", "tags": {"env": "prod"}}`,
+		"a.auto.tfvars":      `password = "secret"`,
+		"extra.tfvars":       `replicas = 3`,
+		"example.tfvars":     `region = "ignored"`,
+	}
+	for name, content := range files {
+		if err := afero.WriteFile(memFs, name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fs := filesystem.NewAferoAdapter(memFs)
+
+	tfconfig, err := parser.NewParser(fs, parser.Simple).ParseTerraformWorkspace(".")
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	loaded, err := Load(fs, ".", "extra.tfvars")
+	if err != nil {
+		t.Fatalf("Failed to load tfvars: %v", err)
+	}
+	report := Analyze(tfconfig, loaded)
+
+	if want := []string{"terraform.tfvars", "a.auto.tfvars", "b.auto.tfvars.json", "extra.tfvars"}; !reflect.DeepEqual(report.Files, want) {
+		t.Errorf("Files = %v, want %v", report.Files, want)
+	}
+
+	values := map[string]*Value{}
+	for _, value := range report.Values {
+		values[value.Name] = value
+	}
+	if region := values["region"]; region.Value != "eu-west-1" || region.File != "b.auto.tfvars.json" || !reflect.DeepEqual(region.Overrides, []string{"terraform.tfvars"}) {
+		t.Errorf("region = %+v, want eu-west-1 from b.auto.tfvars.json overriding terraform.tfvars", region)
+	}
+	if replicas := values["replicas"]; replicas.Value != float64(3) || replicas.File != "extra.tfvars" || replicas.Line != 1 {
+		t.Errorf("replicas = %+v, want 3 from extra.tfvars", replicas)
+	}
+	if tags := values["tags"]; !reflect.DeepEqual(tags.Value, map[string]interface{}{"env": "prod"}) {
+		t.Errorf("tags = %v, want map[env:prod]", tags.Value)
+	}
+	if password := values["password"]; password.Value != nil || !password.Sensitive {
+		t.Errorf("password = %+v, want a redacted sensitive value", password)
+	}
+
+	if want := []string{"unused"}; !reflect.DeepEqual(report.Undeclared, want) {
+		t.Errorf("Undeclared = %v, want %v", report.Undeclared, want)
+	}
+	if len(report.Required) != 3 || len(report.Missing) != 0 {
+		t.Errorf("Required = %+v, Missing = %v, want 3 satisfied variables", report.Required, report.Missing)
+	}
+
+	report = Analyze(tfconfig, loaded[:1])
+	if want := []string{"password", "tags"}; !reflect.DeepEqual(report.Missing, want) {
+		t.Errorf("Missing = %v, want %v", report.Missing, want)
+	}
+
+	if _, err := Parse("bad.tfvars", []byte(`region {}`)); err == nil {
+		t.Error("Parse() of a block should fail")
+	}
+}
+
+func TestRedundant(t *testing.T) {
+	memFs := afero.NewMemMapFs()
+	files := map[string]string{
+		"main.tf": `variable "region" {
+  default = "us-east-1"
+}
+
+variable "replicas" {
+  default = 2
+}
+
+variable "tags" {
+  default = { env = "prod" }
+}
+
+variable "name" {}
+`,
+		"terraform.tfvars": `region   = "us-east-1"
+replicas = 3
+name     = "app"
+`,
+		"prod.auto.tfvars": `replicas = 2
+tags     = { env = "prod" }
+`,
+	}
+	for name, content := range files {
+		if err := afero.WriteFile(memFs, name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fs := filesystem.NewAferoAdapter(memFs)
+
+	tfconfig, err := parser.NewParser(fs, parser.Simple).ParseTerraformWorkspace(".")
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	loaded, err := Load(fs, ".")
+	if err != nil {
+		t.Fatalf("Failed to load tfvars: %v", err)
+	}
+	report := Analyze(tfconfig, loaded)
+
+	redundant := []string{}
+	for _, assignment := range report.Redundant {
+		redundant = append(redundant, assignment.Name+"@"+assignment.File)
+	}
+	if want := []string{"region@terraform.tfvars", "replicas@prod.auto.tfvars", "tags@prod.auto.tfvars"}; !reflect.DeepEqual(redundant, want) {
+		t.Errorf("Redundant = %v, want %v", redundant, want)
+	}
+}
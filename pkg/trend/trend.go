@@ -0,0 +1,150 @@
+package trend
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+)
+
+// Stats are the object counts of a module at the time of a snapshot
+type Stats struct {
+	Variables   int `json:"variables"`
+	Outputs     int `json:"outputs"`
+	Resources   int `json:"resources"`
+	DataSources int `json:"data_sources"`
+	Modules     int `json:"modules"`
+}
+
+// Snapshot is one line of the history file
+type Snapshot struct {
+	Time time.Time `json:"time"`
+	// Module identifies the measured module, e.g. its path or repository URL
+	Module string  `json:"module"`
+	Score  float64 `json:"score"`
+	Stats  Stats   `json:"stats"`
+}
+
+// NewStats counts the objects of a parsed configuration
+func NewStats(tfconfig *parser.TerraformConfig) Stats {
+	return Stats{
+		Variables:   len(tfconfig.Variables),
+		Outputs:     len(tfconfig.Outputs),
+		Resources:   len(tfconfig.Resources),
+		DataSources: len(tfconfig.DataSources),
+		Modules:     len(tfconfig.Modules),
+	}
+}
+
+// Append adds a snapshot to the history file at path, creating it if needed.
+// The file holds one JSON snapshot per line so that appends never rewrite it.
+func Append(path string, snapshot *Snapshot) error {
+	line, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open history file %s: %w", path, err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write history file %s: %w", path, err)
+	}
+	return nil
+}
+
+// Load reads every snapshot of the history file at path, oldest first
+func Load(path string) ([]*Snapshot, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []*Snapshot{}, nil
+		}
+		return nil, fmt.Errorf("failed to read history file %s: %w", path, err)
+	}
+
+	snapshots := []*Snapshot{}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		snapshot := &Snapshot{}
+		if err := json.Unmarshal(scanner.Bytes(), snapshot); err != nil {
+			return nil, fmt.Errorf("failed to parse history file %s line %d: %w", path, line, err)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history file %s: %w", path, err)
+	}
+
+	slices.SortStableFunc(snapshots, func(a, b *Snapshot) int { return a.Time.Compare(b.Time) })
+	return snapshots, nil
+}
+
+// ModuleTrend is the change of a module between its first and last snapshot in the reported period
+type ModuleTrend struct {
+	Module    string    `json:"module"`
+	From      time.Time `json:"from"`
+	To        time.Time `json:"to"`
+	Snapshots int       `json:"snapshots"`
+	Score     float64   `json:"score"`
+	// ScoreDelta and StatsDelta are the last values minus the first ones
+	ScoreDelta float64 `json:"score_delta"`
+	Stats      Stats   `json:"stats"`
+	StatsDelta Stats   `json:"stats_delta"`
+}
+
+type Report struct {
+	Modules []*ModuleTrend `json:"modules"`
+}
+
+// Analyze reports the trend of every module over the snapshots taken at or after since
+// (the zero time includes all of them)
+func Analyze(snapshots []*Snapshot, since time.Time) *Report {
+	byModule := map[string][]*Snapshot{}
+	for _, snapshot := range snapshots {
+		if snapshot.Time.Before(since) {
+			continue
+		}
+		byModule[snapshot.Module] = append(byModule[snapshot.Module], snapshot)
+	}
+
+	report := &Report{Modules: []*ModuleTrend{}}
+	for _, module := range slices.Sorted(maps.Keys(byModule)) {
+		series := byModule[module]
+		first, last := series[0], series[len(series)-1]
+
+		report.Modules = append(report.Modules, &ModuleTrend{
+			Module:     module,
+			From:       first.Time,
+			To:         last.Time,
+			Snapshots:  len(series),
+			Score:      last.Score,
+			ScoreDelta: math.Round((last.Score-first.Score)*10) / 10,
+			Stats:      last.Stats,
+			StatsDelta: Stats{
+				Variables:   last.Stats.Variables - first.Stats.Variables,
+				Outputs:     last.Stats.Outputs - first.Stats.Outputs,
+				Resources:   last.Stats.Resources - first.Stats.Resources,
+				DataSources: last.Stats.DataSources - first.Stats.DataSources,
+				Modules:     last.Stats.Modules - first.Stats.Modules,
+			},
+		})
+	}
+
+	return report
+}
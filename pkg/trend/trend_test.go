@@ -0,0 +1,54 @@
+package trend
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	day := func(d int) time.Time { return time.Date(2025, 1, d, 0, 0, 0, 0, time.UTC) }
+
+	for _, snapshot := range []*Snapshot{
+		{Time: day(3), Module: "vpc", Score: 72.5, Stats: Stats{Variables: 6, Resources: 4}},
+		{Time: day(1), Module: "vpc", Score: 60, Stats: Stats{Variables: 5, Resources: 4}},
+		{Time: day(2), Module: "app", Score: 90, Stats: Stats{Outputs: 2}},
+		{Time: day(5), Module: "vpc", Score: 80.1, Stats: Stats{Variables: 8, Resources: 3}},
+	} {
+		if err := Append(path, snapshot); err != nil {
+			t.Fatalf("Append failed: %v", err)
+		}
+	}
+
+	snapshots, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(snapshots) != 4 || !snapshots[0].Time.Equal(day(1)) {
+		t.Fatalf("Expected 4 snapshots sorted by time, got %+v", snapshots)
+	}
+
+	report := Analyze(snapshots, time.Time{})
+	if len(report.Modules) != 2 || report.Modules[0].Module != "app" {
+		t.Fatalf("Unexpected modules: %+v", report.Modules)
+	}
+
+	vpc := report.Modules[1]
+	if vpc.Snapshots != 3 || vpc.Score != 80.1 || vpc.ScoreDelta != 20.1 {
+		t.Errorf("Unexpected vpc trend: %+v", vpc)
+	}
+	if vpc.StatsDelta.Variables != 3 || vpc.StatsDelta.Resources != -1 {
+		t.Errorf("Unexpected vpc stats delta: %+v", vpc.StatsDelta)
+	}
+
+	recent := Analyze(snapshots, day(3))
+	if len(recent.Modules) != 1 || recent.Modules[0].ScoreDelta != 7.6 {
+		t.Errorf("Unexpected trend since day 3: %+v", recent.Modules)
+	}
+
+	missing, err := Load(filepath.Join(t.TempDir(), "missing.jsonl"))
+	if err != nil || len(missing) != 0 {
+		t.Errorf("Expected an empty history for a missing file, got %v, %v", missing, err)
+	}
+}
@@ -0,0 +1,91 @@
+package usage
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/diff"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser/schema"
+)
+
+// Affected is a consumer that has to change its module call to upgrade
+type Affected struct {
+	*Consumer
+	// Changes are the breaking changes that apply to the call
+	Changes []*diff.Change `json:"changes"`
+}
+
+// Impact is the effect of upgrading a module from one version to another on the consumers
+// of the old version
+type Impact struct {
+	Module string `json:"module"`
+	From   string `json:"from"`
+	To     string `json:"to"`
+	// Changes are the interface changes between the versions
+	Changes  []*diff.Change `json:"changes"`
+	Breaking []*Affected    `json:"breaking"`
+	// NonBreaking lists the consumers that can upgrade without changing their call
+	NonBreaking []*Consumer `json:"non_breaking"`
+}
+
+// Analyze compares the before and after versions of module and sorts the consumers of from by
+// whether a breaking change applies to their call. Removed variables and type changes only break
+// calls setting the variable, and variables without default only calls not setting it. Output
+// changes break every consumer, as calls are not resolved to the outputs they read.
+func Analyze(module *Module, from, to string, before, after *parser.TerraformConfig) *Impact {
+	impact := &Impact{Module: module.Source, From: from, To: to, Changes: []*diff.Change{}, Breaking: []*Affected{}, NonBreaking: []*Consumer{}}
+
+	for _, change := range diff.Compare(before, after).Changes {
+		if strings.HasPrefix(change.Address, "var.") || strings.HasPrefix(change.Address, "output.") {
+			impact.Changes = append(impact.Changes, change)
+		}
+	}
+
+	version := module.Version(from)
+	if version == nil {
+		return impact
+	}
+
+	for _, consumer := range version.Consumers {
+		affected := &Affected{Consumer: consumer}
+		for _, change := range impact.Changes {
+			if change.Breaking && breaks(change, consumer, before, after) {
+				affected.Changes = append(affected.Changes, change)
+			}
+		}
+
+		if len(affected.Changes) > 0 {
+			impact.Breaking = append(impact.Breaking, affected)
+		} else {
+			impact.NonBreaking = append(impact.NonBreaking, consumer)
+		}
+	}
+	return impact
+}
+
+// breaks reports whether a breaking change applies to the module call of consumer
+func breaks(change *diff.Change, consumer *Consumer, before, after *parser.TerraformConfig) bool {
+	name, ok := strings.CutPrefix(change.Address, "var.")
+	if !ok {
+		return true
+	}
+	set := slices.Contains(consumer.arguments, name)
+
+	switch change.Kind {
+	case diff.ChangeRemoved:
+		return set
+	case diff.ChangeAdded:
+		return !set
+	}
+
+	i := slices.IndexFunc(after.Variables, func(v *schema.Variable) bool { return v.Name == name })
+	j := slices.IndexFunc(before.Variables, func(v *schema.Variable) bool { return v.Name == name })
+	if i < 0 || j < 0 {
+		return true
+	}
+	if set {
+		return before.Variables[j].Type != after.Variables[i].Type
+	}
+	return after.Variables[i].Required
+}
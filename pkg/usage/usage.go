@@ -0,0 +1,182 @@
+// Package usage aggregates which shared modules, and which versions of them, a set of root
+// configurations consume.
+package usage
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/source"
+)
+
+// Unversioned is the version of calls that do not pin the module
+const Unversioned = "unversioned"
+
+// Consumer is a module call of a root configuration
+type Consumer struct {
+	// Root is the name of the root configuration, e.g. its manifest entry
+	Root string `json:"root"`
+	// Path is the directory of the call relative to the root, "." for the root itself
+	Path string `json:"path"`
+	// Call is the address of the module call, e.g. module.vpc
+	Call string `json:"call"`
+
+	arguments []string
+}
+
+// Version groups the consumers of one version of a module: the version constraint of registry
+// modules, the ref of git modules
+type Version struct {
+	Version   string      `json:"version"`
+	Consumers []*Consumer `json:"consumers"`
+}
+
+// Module is a shared module and its consumers
+type Module struct {
+	// Source is the normalized source address, without the version
+	Source    string     `json:"source"`
+	Kind      string     `json:"kind"`
+	Consumers int        `json:"consumers"`
+	Versions  []*Version `json:"versions"`
+}
+
+type Report struct {
+	// Roots lists the root configurations that were analyzed
+	Roots   []string  `json:"roots"`
+	Modules []*Module `json:"modules"`
+	// Errors maps roots and paths that could not be parsed to the error
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// Builder collects the module calls of root configurations
+type Builder struct {
+	roots   []string
+	modules map[string]*Module
+	errors  map[string]string
+}
+
+func NewBuilder() *Builder {
+	return &Builder{modules: map[string]*Module{}, errors: map[string]string{}}
+}
+
+// AddError records a root, or a path of a root, that could not be parsed
+func (b *Builder) AddError(root, path string, err error) {
+	b.addRoot(root)
+	key := root
+	if path != "" && path != "." {
+		key += "//" + path
+	}
+	b.errors[key] = err.Error()
+}
+
+func (b *Builder) addRoot(root string) {
+	if !slices.Contains(b.roots, root) {
+		b.roots = append(b.roots, root)
+	}
+}
+
+// Add records the remote module calls of the configuration at path of root
+func (b *Builder) Add(root, path string, tfconfig *parser.TerraformConfig) {
+	b.addRoot(root)
+
+	for _, call := range tfconfig.Modules {
+		src := source.ParseModuleSource(call.Source)
+		if src.Kind == source.ModuleSourceLocal {
+			continue
+		}
+
+		address := NormalizeSource(src)
+		module, ok := b.modules[address]
+		if !ok {
+			module = &Module{Source: address, Kind: src.Kind.String()}
+			b.modules[address] = module
+		}
+
+		version := call.Version
+		if src.Kind == source.ModuleSourceGit {
+			version = src.Ref
+		}
+		if version == "" {
+			version = Unversioned
+		}
+
+		i := slices.IndexFunc(module.Versions, func(v *Version) bool { return v.Version == version })
+		if i < 0 {
+			module.Versions = append(module.Versions, &Version{Version: version})
+			i = len(module.Versions) - 1
+		}
+		module.Versions[i].Consumers = append(module.Versions[i].Consumers, &Consumer{Root: root, Path: path, Call: "module." + call.Name, arguments: call.Arguments})
+		module.Consumers++
+	}
+}
+
+// Report returns the modules by source, their versions in order and their consumers by root
+func (b *Builder) Report() *Report {
+	report := &Report{Roots: slices.Clone(b.roots), Modules: []*Module{}, Errors: b.errors}
+
+	for _, module := range b.modules {
+		slices.SortFunc(module.Versions, func(a, b *Version) int { return strings.Compare(a.Version, b.Version) })
+		for _, version := range module.Versions {
+			slices.SortStableFunc(version.Consumers, func(a, b *Consumer) int {
+				return strings.Compare(a.Root+"\x00"+a.Path+"\x00"+a.Call, b.Root+"\x00"+b.Path+"\x00"+b.Call)
+			})
+		}
+		report.Modules = append(report.Modules, module)
+	}
+	slices.SortFunc(report.Modules, func(a, b *Module) int { return strings.Compare(a.Source, b.Source) })
+
+	return report
+}
+
+// Find returns the module with the normalized source address, or nil
+func (r *Report) Find(address string) *Module {
+	i := slices.IndexFunc(r.Modules, func(module *Module) bool { return module.Source == address })
+	if i < 0 {
+		return nil
+	}
+	return r.Modules[i]
+}
+
+// Version returns the consumers of version, or nil when no root uses it
+func (m *Module) Version(version string) *Version {
+	i := slices.IndexFunc(m.Versions, func(v *Version) bool { return v.Version == version })
+	if i < 0 {
+		return nil
+	}
+	return m.Versions[i]
+}
+
+// Filter keeps the modules whose source contains query, e.g. a repository or registry name
+func (r *Report) Filter(query string) {
+	r.Modules = slices.DeleteFunc(r.Modules, func(module *Module) bool {
+		return !strings.Contains(module.Source, strings.ToLower(query))
+	})
+}
+
+// NormalizeSource returns a source address that is the same for every spelling of a module:
+// git URLs lose their scheme, user and .git suffix, and registry addresses the default registry host
+func NormalizeSource(src source.ModuleSource) string {
+	address := strings.ToLower(src.Address)
+
+	if src.Kind == source.ModuleSourceGit {
+		if rest, ok := strings.CutPrefix(address, "git@"); ok {
+			address = strings.Replace(rest, ":", "/", 1)
+		}
+		if _, rest, ok := strings.Cut(address, "://"); ok {
+			address = rest
+		}
+		if user, rest, ok := strings.Cut(address, "@"); ok && !strings.Contains(user, "/") {
+			address = rest
+		}
+		address = strings.TrimSuffix(address, ".git")
+	}
+	if src.Kind == source.ModuleSourceRegistry {
+		address = strings.TrimPrefix(address, "registry.terraform.io/")
+	}
+
+	if src.SubDir != "" {
+		address += "//" + src.SubDir
+	}
+	return address
+}
@@ -0,0 +1,113 @@
+package usage
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/parser/schema"
+	"github.com/Yunsang-Jeong/terraform-config-parser/pkg/source"
+)
+
+func TestNormalizeSource(t *testing.T) {
+	for _, raw := range []string{
+		"git::https://github.com/owner/terraform-modules.git//vpc?ref=v1.0.0",
+		"github.com/owner/terraform-modules//vpc",
+		"git@github.com:owner/terraform-modules.git//vpc",
+		"git::ssh://git@github.com/Owner/terraform-modules.git//vpc",
+	} {
+		if got := NormalizeSource(source.ParseModuleSource(raw)); got != "github.com/owner/terraform-modules//vpc" {
+			t.Errorf("%s: unexpected source %s", raw, got)
+		}
+	}
+
+	if got := NormalizeSource(source.ParseModuleSource("registry.terraform.io/terraform-aws-modules/vpc/aws")); got != "terraform-aws-modules/vpc/aws" {
+		t.Errorf("Unexpected registry source %s", got)
+	}
+}
+
+func TestReport(t *testing.T) {
+	builder := NewBuilder()
+	builder.Add("payments", ".", &parser.TerraformConfig{Modules: []*schema.Module{
+		{Name: "vpc", Source: "terraform-aws-modules/vpc/aws", Version: "5.1.0"},
+		{Name: "network", Source: "git::https://github.com/owner/modules.git//network?ref=v1"},
+		{Name: "local", Source: "./modules/local"},
+	}})
+	builder.Add("search", "envs/prod", &parser.TerraformConfig{Modules: []*schema.Module{
+		{Name: "vpc", Source: "terraform-aws-modules/vpc/aws", Version: "~> 4.0"},
+		{Name: "net", Source: "github.com/owner/modules//network?ref=v1"},
+	}})
+	builder.Add("search", ".", &parser.TerraformConfig{Modules: []*schema.Module{
+		{Name: "vpc", Source: "terraform-aws-modules/vpc/aws"},
+	}})
+	builder.AddError("legacy", ".", errors.New("cannot clone"))
+
+	report := builder.Report()
+	if len(report.Roots) != 3 || report.Errors["legacy"] != "cannot clone" {
+		t.Errorf("Unexpected roots %v and errors %v", report.Roots, report.Errors)
+	}
+	if len(report.Modules) != 2 {
+		t.Fatalf("Expected 2 shared modules, got %d", len(report.Modules))
+	}
+
+	network, vpc := report.Modules[0], report.Modules[1]
+	if network.Source != "github.com/owner/modules//network" || network.Consumers != 2 || len(network.Versions) != 1 {
+		t.Errorf("Unexpected network module %+v", network)
+	}
+	if vpc.Consumers != 3 || len(vpc.Versions) != 3 {
+		t.Fatalf("Unexpected vpc module %+v", vpc)
+	}
+	versions := []string{vpc.Versions[0].Version, vpc.Versions[1].Version, vpc.Versions[2].Version}
+	if !slices.Equal(versions, []string{"5.1.0", Unversioned, "~> 4.0"}) {
+		t.Errorf("Unexpected versions %v", versions)
+	}
+	if consumer := vpc.Versions[2].Consumers[0]; consumer.Root != "search" || consumer.Path != "envs/prod" || consumer.Call != "module.vpc" {
+		t.Errorf("Unexpected consumer %+v", consumer)
+	}
+
+	report.Filter("VPC")
+	if len(report.Modules) != 1 {
+		t.Errorf("Expected the filter to keep the vpc module, got %d modules", len(report.Modules))
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	builder := NewBuilder()
+	builder.Add("payments", ".", &parser.TerraformConfig{Modules: []*schema.Module{
+		{Name: "vpc", Source: "git::https://github.com/owner/modules.git//vpc?ref=v1", Arguments: []string{"cidr", "legacy_dns"}},
+	}})
+	builder.Add("search", ".", &parser.TerraformConfig{Modules: []*schema.Module{
+		{Name: "vpc", Source: "github.com/owner/modules//vpc?ref=v1", Arguments: []string{"cidr", "name"}},
+	}})
+	builder.Add("billing", ".", &parser.TerraformConfig{Modules: []*schema.Module{
+		{Name: "vpc", Source: "github.com/owner/modules//vpc?ref=v2", Arguments: []string{"cidr"}},
+	}})
+	module := builder.Report().Find("github.com/owner/modules//vpc")
+	if module == nil {
+		t.Fatal("Expected the vpc module in the report")
+	}
+
+	before := &parser.TerraformConfig{Variables: []*schema.Variable{
+		{Name: "cidr", Type: "string", Required: true},
+		{Name: "legacy_dns", Type: "bool", Default: false},
+		{Name: "name", Type: "string", Default: "main"},
+	}}
+	after := &parser.TerraformConfig{Variables: []*schema.Variable{
+		{Name: "cidr", Type: "string", Required: true},
+		{Name: "name", Type: "string", Required: true},
+		{Name: "tags", Type: "map(string)", Default: map[string]interface{}{}},
+	}}
+
+	impact := Analyze(module, "v1", "v2", before, after)
+	if len(impact.Changes) != 3 {
+		t.Errorf("Expected 3 interface changes, got %d", len(impact.Changes))
+	}
+	// payments sets the removed legacy_dns and leaves out the now required name; search sets name
+	if len(impact.Breaking) != 1 || impact.Breaking[0].Root != "payments" || len(impact.Breaking[0].Changes) != 2 {
+		t.Fatalf("Expected payments to be broken by 2 changes, got %+v", impact.Breaking)
+	}
+	if len(impact.NonBreaking) != 1 || impact.NonBreaking[0].Root != "search" {
+		t.Errorf("Expected search to upgrade without changes, got %+v", impact.NonBreaking)
+	}
+}
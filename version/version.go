@@ -4,12 +4,17 @@ import (
 	"fmt"
 	"os"
 	"runtime"
+	"runtime/debug"
 	"strings"
 )
 
 // Version information - can be overridden at build time with -ldflags
 var (
 	Version = "" // Will be set by build or read from file
+	// Commit and BuildDate (RFC 3339) are set by release builds; other builds read them from
+	// the VCS information Go embeds
+	Commit    = ""
+	BuildDate = ""
 )
 
 // init runs at package initialization and sets the version if not provided at build time
@@ -42,7 +47,64 @@ func GetVersion() string {
 	return Version
 }
 
+// Info is the build metadata of the binary
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	BuildDate string `json:"build_date,omitempty"`
+	GoVersion string `json:"go_version"`
+	Platform  string `json:"platform"`
+	// Modified is set when the binary was built from a working tree with uncommitted changes
+	Modified bool `json:"modified,omitempty"`
+}
+
+// GetInfo returns the build metadata, completed with the VCS information embedded by Go when
+// it was not set at build time
+func GetInfo() Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildDate: BuildDate,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+
+	if build, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range build.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = setting.Value
+				}
+			case "vcs.time":
+				if info.BuildDate == "" {
+					info.BuildDate = setting.Value
+				}
+			case "vcs.modified":
+				info.Modified = setting.Value == "true"
+			}
+		}
+	}
+	return info
+}
+
 // GetFullVersion returns detailed version information  
 func GetFullVersion() string {
-	return fmt.Sprintf("%s (go: %s)", Version, runtime.Version())
+	info := GetInfo()
+	details := []string{}
+	if info.Commit != "" {
+		commit := info.Commit
+		if len(commit) > 12 {
+			commit = commit[:12]
+		}
+		if info.Modified {
+			commit += "-dirty"
+		}
+		details = append(details, "commit: "+commit)
+	}
+	if info.BuildDate != "" {
+		details = append(details, "built: "+info.BuildDate)
+	}
+	details = append(details, "go: "+info.GoVersion, "platform: "+info.Platform)
+	return fmt.Sprintf("%s (%s)", info.Version, strings.Join(details, ", "))
 }